@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"moxapp/internal/metrics"
+)
+
+var (
+	// compare command flags
+	latencyThreshold float64
+	errorThreshold   float64
+	failOnRegression bool
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <baseline.json> <current.json>",
+	Short: "Compare two exported metrics snapshots",
+	Long: `Compare two metrics snapshots exported from /api/metrics/outgoing (or /api/metrics)
+and report per-endpoint latency regression and error-rate change.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runCompare,
+}
+
+func init() {
+	compareCmd.Flags().Float64Var(&latencyThreshold, "latency-threshold", metrics.DefaultLatencyRegressionPct, "p95 latency increase (%) that marks an endpoint as regressed")
+	compareCmd.Flags().Float64Var(&errorThreshold, "error-threshold", metrics.DefaultErrorRateRegression, "Error rate increase (percentage points) that marks an endpoint as regressed")
+	compareCmd.Flags().BoolVar(&failOnRegression, "fail-on-regression", false, "Exit with status 1 if any endpoint regressed")
+
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) {
+	baseline, err := metrics.LoadSnapshot(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load baseline %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	current, err := metrics.LoadSnapshot(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load snapshot %s: %v\n", args[1], err)
+		os.Exit(1)
+	}
+
+	cmp := metrics.Compare(baseline, current, metrics.CompareOptions{
+		LatencyThresholdPct: latencyThreshold,
+		ErrorRateThreshold:  errorThreshold,
+	})
+	printComparison(cmp)
+
+	if failOnRegression && len(cmp.Summary.RegressedEndpoints) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printComparison(cmp *metrics.Comparison) {
+	fmt.Println("Comparison:")
+	fmt.Println("=============================================================")
+	fmt.Printf("  Baseline Collected:         %s\n", cmp.BaselineCollectedAt)
+	fmt.Printf("  Current Collected:          %s\n", cmp.CurrentCollectedAt)
+	fmt.Printf("  Requests:                   %d -> %d\n", cmp.Summary.BaselineRequests, cmp.Summary.CurrentRequests)
+	fmt.Printf("  Error Rate:                 %.2f%% -> %.2f%% (%+.2f pts)\n",
+		cmp.Summary.BaselineErrorRate, cmp.Summary.CurrentErrorRate, cmp.Summary.ErrorRateChange)
+	fmt.Printf("  Compared Endpoints:         %d\n", cmp.Summary.ComparedEndpoints)
+	fmt.Printf("  Regressed Endpoints:        %d\n", len(cmp.Summary.RegressedEndpoints))
+	fmt.Println("=============================================================")
+	fmt.Println()
+
+	names := make([]string, 0, len(cmp.Endpoints))
+	for name := range cmp.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("  %-30s %12s %12s %9s %12s\n", "ENDPOINT", "BASE P95", "CUR P95", "CHANGE", "ERR CHANGE")
+	for _, name := range names {
+		ec := cmp.Endpoints[name]
+		marker := ""
+		if ec.Regressed {
+			marker = "  REGRESSED"
+		}
+		fmt.Printf("  %-30s %10.2fms %10.2fms %+8.1f%% %+10.2fpt%s\n",
+			name, ec.BaselineP95Ms, ec.CurrentP95Ms, ec.P95LatencyChangePct, ec.ErrorRateChange, marker)
+	}
+	fmt.Println()
+
+	if len(cmp.OnlyInBaseline) > 0 {
+		fmt.Printf("  Only in baseline: %v\n", cmp.OnlyInBaseline)
+	}
+	if len(cmp.OnlyInCurrent) > 0 {
+		fmt.Printf("  Only in current:  %v\n", cmp.OnlyInCurrent)
+	}
+}
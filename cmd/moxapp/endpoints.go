@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"moxapp/internal/config"
+)
+
+var (
+	// endpoints command flags
+	endpointsConfig     string
+	endpointURL         string
+	endpointMethod      string
+	endpointFrequency   float64
+	endpointAuth        string
+	endpointTimeout     int
+	endpointTags        []string
+	endpointHeaders     []string
+	endpointDisabled    bool
+	endpointMaxInFlight int
+	endpointPriority    int
+	endpointExpected    []string
+	endpointRedirects   int
+	endpointUsers       int
+	endpointThinkTime   int
+)
+
+var endpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "List and manage outgoing endpoints",
+	Long: `List, add, delete, enable and disable outgoing endpoints of a running instance
+via its API (see --addr), or of a config file with --config.
+
+Changes made via the API apply live but are not written to the config file;
+changes made with --config are saved to the file and apply on the next start or
+SIGHUP reload.`,
+}
+
+func init() {
+	addRemoteFlags(endpointsCmd)
+	endpointsCmd.PersistentFlags().StringVar(&endpointsConfig, "config", "", "Edit this config file instead of a running instance")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List outgoing endpoints",
+		Args:  cobra.NoArgs,
+		Run:   runEndpointsList,
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add an outgoing endpoint",
+		Args:  cobra.ExactArgs(1),
+		Run:   runEndpointsAdd,
+	}
+	addCmd.Flags().StringVar(&endpointURL, "url", "", "URL template (required)")
+	addCmd.Flags().StringVar(&endpointMethod, "method", "GET", "HTTP method")
+	addCmd.Flags().Float64Var(&endpointFrequency, "frequency", 1, "Requests per minute")
+	addCmd.Flags().StringVar(&endpointAuth, "auth", "none", "Auth config name")
+	addCmd.Flags().IntVar(&endpointTimeout, "timeout", 0, "Request timeout in seconds (default 30)")
+	addCmd.Flags().StringSliceVar(&endpointTags, "tag", nil, "Tag (repeatable or comma-separated)")
+	addCmd.Flags().StringArrayVar(&endpointHeaders, "header", nil, "Header as 'Name: value' (repeatable)")
+	addCmd.Flags().IntVar(&endpointMaxInFlight, "max-in-flight", 0, "Cap on queued and running requests (0 = unlimited)")
+	addCmd.Flags().IntVar(&endpointPriority, "priority", 0, "Higher priorities get workers first when all are busy")
+	addCmd.Flags().StringSliceVar(&endpointExpected, "expected-status", nil, "Status codes counted as success, such as 404, 4xx or 500-503 (default 2xx and 3xx)")
+	addCmd.Flags().IntVar(&endpointRedirects, "follow-redirects", 0, "Follow up to this many redirects (0 = report the redirect response)")
+	addCmd.Flags().IntVar(&endpointUsers, "virtual-users", 0, "Closed-loop users sending requests one after another, instead of --frequency")
+	addCmd.Flags().IntVar(&endpointThinkTime, "think-time-ms", 0, "Wait of a virtual user between a response and its next request")
+	addCmd.Flags().BoolVar(&endpointDisabled, "disabled", false, "Add the endpoint disabled")
+	_ = addCmd.MarkFlagRequired("url")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete an outgoing endpoint",
+		Args:  cobra.ExactArgs(1),
+		Run:   runEndpointsDelete,
+	}
+
+	enableCmd := &cobra.Command{
+		Use:   "enable <name>",
+		Short: "Enable an outgoing endpoint",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { runEndpointsSetEnabled(args[0], true) },
+	}
+
+	disableCmd := &cobra.Command{
+		Use:   "disable <name>",
+		Short: "Disable an outgoing endpoint",
+		Args:  cobra.ExactArgs(1),
+		Run:   func(cmd *cobra.Command, args []string) { runEndpointsSetEnabled(args[0], false) },
+	}
+
+	endpointsCmd.AddCommand(listCmd, addCmd, deleteCmd, enableCmd, disableCmd)
+	rootCmd.AddCommand(endpointsCmd)
+}
+
+func runEndpointsList(cmd *cobra.Command, args []string) {
+	var endpoints []config.Endpoint
+	if endpointsConfig != "" {
+		manager := config.NewManager()
+		if err := manager.LoadFromFile(endpointsConfig); err != nil {
+			exitf("Failed to load %s: %v", endpointsConfig, err)
+		}
+		endpoints = manager.GetEndpoints()
+	} else {
+		var resp struct {
+			Endpoints []config.Endpoint `json:"endpoints"`
+		}
+		if err := callAPI(http.MethodGet, "/api/outgoing/endpoints", nil, &resp); err != nil {
+			exitf("Failed to list endpoints: %v", err)
+		}
+		endpoints = resp.Endpoints
+	}
+
+	if len(endpoints) == 0 {
+		fmt.Println("No endpoints configured.")
+		return
+	}
+	fmt.Printf("%-30s %-7s %9s %-8s %-16s %s\n", "NAME", "METHOD", "FREQ/MIN", "ENABLED", "AUTH", "URL")
+	for _, ep := range endpoints {
+		fmt.Printf("%-30s %-7s %9.2f %-8t %-16s %s\n",
+			ep.Name, ep.Method, ep.FrequencyPerMin, ep.Enabled, endpointAuthName(ep.Auth), ep.URLTemplate)
+	}
+}
+
+func runEndpointsAdd(cmd *cobra.Command, args []string) {
+	headers, err := parseHeaderFlags(endpointHeaders)
+	if err != nil {
+		exitf("%v", err)
+	}
+	req := config.EndpointRequest{
+		Name:            args[0],
+		Method:          strings.ToUpper(endpointMethod),
+		URLTemplate:     endpointURL,
+		FrequencyPerMin: endpointFrequency,
+		Auth:            endpointAuth,
+		Headers:         headers,
+		Timeout:         endpointTimeout,
+		Tags:            endpointTags,
+		MaxInFlight:     endpointMaxInFlight,
+		Priority:        endpointPriority,
+		ExpectedStatus:  endpointExpected,
+		FollowRedirects: endpointRedirects > 0,
+		MaxRedirects:    endpointRedirects,
+		VirtualUsers:    endpointUsers,
+		ThinkTimeMs:     endpointThinkTime,
+		Enabled:         !endpointDisabled,
+	}
+
+	if endpointsConfig != "" {
+		endpoint := req.ToEndpoint()
+		// Enabled is the default; only write it for --disabled
+		endpoint.EnabledSet = endpointDisabled
+		editEndpointsFile(func(f *config.EndpointFile) error { return f.Add(endpoint) })
+	} else if err := callAPI(http.MethodPost, "/api/outgoing/endpoints", req, nil); err != nil {
+		exitf("Failed to add endpoint: %v", err)
+	}
+	fmt.Printf("Added endpoint %s\n", req.Name)
+}
+
+func runEndpointsDelete(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if endpointsConfig != "" {
+		editEndpointsFile(func(f *config.EndpointFile) error { return f.Delete(name) })
+	} else if err := callAPI(http.MethodDelete, "/api/outgoing/endpoints/"+url.PathEscape(name), nil, nil); err != nil {
+		exitf("Failed to delete endpoint: %v", err)
+	}
+	fmt.Printf("Deleted endpoint %s\n", name)
+}
+
+func runEndpointsSetEnabled(name string, enabled bool) {
+	action := "Disabled"
+	if enabled {
+		action = "Enabled"
+	}
+
+	if endpointsConfig != "" {
+		editEndpointsFile(func(f *config.EndpointFile) error { return f.SetEnabled(name, enabled) })
+	} else {
+		body := map[string]interface{}{"name": name, "enabled": enabled}
+		if err := callAPI(http.MethodPost, "/api/outgoing/control/endpoint", body, nil); err != nil {
+			exitf("Failed to update endpoint: %v", err)
+		}
+	}
+	fmt.Printf("%s endpoint %s\n", action, name)
+}
+
+// editEndpointsFile applies an edit to the --config file and saves it
+func editEndpointsFile(edit func(*config.EndpointFile) error) {
+	f, err := config.OpenEndpointFile(endpointsConfig)
+	if err != nil {
+		exitf("Failed to open %s: %v", endpointsConfig, err)
+	}
+	if err := edit(f); err != nil {
+		exitf("%v", err)
+	}
+	if err := f.Save(); err != nil {
+		exitf("Failed to save %s: %v", endpointsConfig, err)
+	}
+}
+
+// parseHeaderFlags parses 'Name: value' header flags
+func parseHeaderFlags(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected 'Name: value'", value)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+// endpointAuthName describes an endpoint's auth: the config name, or
+// "inline" for an inline auth object
+func endpointAuthName(auth interface{}) string {
+	switch a := auth.(type) {
+	case nil:
+		return "none"
+	case string:
+		return a
+	default:
+		return "inline"
+	}
+}
+
+// exitf prints an error and exits with status 1
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
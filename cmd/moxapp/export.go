@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"moxapp/internal/config"
+	"moxapp/internal/importer"
+)
+
+var (
+	// export command flags
+	exportConfig string
+	exportOutput string
+	exportName   string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Convert outgoing endpoints to other formats",
+}
+
+func init() {
+	postmanCmd := &cobra.Command{
+		Use:   "postman",
+		Short: "Export outgoing endpoints as a Postman collection",
+		Long: `Export the outgoing endpoints of a running instance (see --addr), or of a
+config file with --config, as a Postman v2.1 collection.
+
+Endpoint groups become folders and auth configs become Postman auth. Environment
+variables such as {{ .Env.BASE_URL }} become {{BASE_URL}} collection variables,
+left empty so no secrets are exported.`,
+		Args: cobra.NoArgs,
+		Run:  runExportPostman,
+	}
+	addRemoteFlags(postmanCmd)
+	postmanCmd.Flags().StringVar(&exportConfig, "config", "", "Export this config file instead of a running instance")
+	postmanCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the collection to this file (default: stdout)")
+	postmanCmd.Flags().StringVar(&exportName, "name", "MoxApp endpoints", "Collection name")
+
+	exportCmd.AddCommand(postmanCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportPostman(cmd *cobra.Command, args []string) {
+	manager := config.NewManager()
+	if exportConfig != "" {
+		if err := manager.LoadFromFile(exportConfig); err != nil {
+			exitf("Failed to load %s: %v", exportConfig, err)
+		}
+	} else {
+		data, err := fetchAPI(http.MethodGet, "/api/config/export", nil)
+		if err != nil {
+			exitf("Failed to get config: %v", err)
+		}
+		var cfg config.Config
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			exitf("Invalid config from %s: %v", remoteAddr, err)
+		}
+		if err := manager.ReplaceConfig(&cfg); err != nil {
+			exitf("Invalid config from %s: %v", remoteAddr, err)
+		}
+	}
+
+	data, warnings, err := importer.ToPostman(exportName, manager.GetEndpoints())
+	if err != nil {
+		exitf("Failed to export: %v", err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if exportOutput == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(exportOutput, data, 0o644); err != nil {
+		exitf("Failed to write %s: %v", exportOutput, err)
+	}
+	fmt.Printf("Exported %d endpoints to %s\n", len(manager.GetEndpoints()), exportOutput)
+}
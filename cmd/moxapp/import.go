@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"moxapp/internal/config"
+	"moxapp/internal/importer"
+)
+
+var (
+	// import command flags
+	importOpts   importer.Options
+	importConfig string
+	importPrefix string
+	importEnv    string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Convert recorded requests and Postman collections into outgoing endpoints",
+	Long: `Convert a browser HAR file, curl commands or a Postman collection into
+outgoing endpoints.
+
+The endpoints are printed as YAML, or appended to a config file with --config.
+Requests with the same method, URL and body become one endpoint whose
+frequency adds up; headers bound to the recorded connection and, unless
+--keep-cookies is given, cookies are dropped.`,
+}
+
+func init() {
+	importCmd.PersistentFlags().Float64Var(&importOpts.Frequency, "frequency", 0, "Requests per minute of each recorded request (default: recorded rate for HAR, 1 for curl)")
+	importCmd.PersistentFlags().StringSliceVar(&importOpts.Hosts, "host", nil, "Only import requests to this hostname (repeatable)")
+	importCmd.PersistentFlags().BoolVar(&importOpts.KeepCookies, "keep-cookies", false, "Keep Cookie headers")
+	importCmd.PersistentFlags().StringSliceVar(&importOpts.Tags, "tag", nil, "Tag added to every endpoint (repeatable)")
+	importCmd.PersistentFlags().StringVar(&importConfig, "config", "", "Append the endpoints to this config file instead of printing them")
+	importCmd.PersistentFlags().StringVar(&importPrefix, "prefix", "", "Prefix for endpoint names")
+
+	harCmd := &cobra.Command{
+		Use:   "har <file.har>",
+		Short: "Import the requests of a browser HAR file",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runImport(args[0], importer.FromHAR)
+		},
+	}
+	harCmd.Flags().BoolVar(&importOpts.IncludeStatic, "include-static", false, "Also import images, stylesheets, scripts, fonts and media")
+
+	curlCmd := &cobra.Command{
+		Use:   "curl <file|->",
+		Short: "Import curl commands, one per line (- reads stdin)",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runImport(args[0], importer.FromCurl)
+		},
+	}
+
+	postmanCmd := &cobra.Command{
+		Use:   "postman <collection.json>",
+		Short: "Import the requests of a Postman collection",
+		Long: `Import the requests of a Postman collection (v2.0 or v2.1). Folders become
+tags and Postman variables such as {{baseUrl}} become {{ env "baseUrl" }};
+the variables to set are printed with their collection or --environment values.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var environment map[string]string
+			if importEnv != "" {
+				f, err := os.Open(importEnv)
+				if err != nil {
+					exitf("Failed to open %s: %v", importEnv, err)
+				}
+				environment, err = importer.PostmanEnvironment(f)
+				f.Close()
+				if err != nil {
+					exitf("%v", err)
+				}
+			}
+			runImport(args[0], func(r io.Reader, opts importer.Options) (*importer.Result, error) {
+				return importer.FromPostman(r, opts, environment)
+			})
+		},
+	}
+	postmanCmd.Flags().StringVar(&importEnv, "environment", "", "Postman environment file with variable values")
+
+	importCmd.AddCommand(harCmd, curlCmd, postmanCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+// runImport converts a file with an importer and prints or saves the result
+func runImport(path string, convert func(io.Reader, importer.Options) (*importer.Result, error)) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			exitf("Failed to open %s: %v", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	result, err := convert(r, importOpts)
+	if err != nil {
+		exitf("Failed to import %s: %v", path, err)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if len(result.Variables) > 0 {
+		names := make([]string, 0, len(result.Variables))
+		for name := range result.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(os.Stderr, "The endpoints use these environment variables:")
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "  export %s=%s\n", name, shellQuote(result.Variables[name]))
+		}
+	}
+	if len(result.Endpoints) == 0 {
+		exitf("No requests to import from %s", path)
+	}
+	for i := range result.Endpoints {
+		result.Endpoints[i].Name = importPrefix + result.Endpoints[i].Name
+	}
+
+	if importConfig != "" {
+		endpointsConfig = importConfig
+		editEndpointsFile(func(f *config.EndpointFile) error {
+			for _, endpoint := range result.Endpoints {
+				if err := f.Add(endpoint); err != nil {
+					return fmt.Errorf("endpoint %s: %w", endpoint.Name, err)
+				}
+			}
+			return nil
+		})
+		fmt.Printf("Added %d endpoints to %s\n", len(result.Endpoints), importConfig)
+		return
+	}
+
+	printEndpointsYAML(result.Endpoints)
+}
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// printEndpointsYAML prints endpoints as an outgoing_endpoints list
+func printEndpointsYAML(endpoints []config.Endpoint) {
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	doc := struct {
+		OutgoingEndpoints []config.Endpoint `yaml:"outgoing_endpoints"`
+	}{endpoints}
+	if err := encoder.Encode(doc); err != nil {
+		exitf("Failed to encode endpoints: %v", err)
+	}
+}
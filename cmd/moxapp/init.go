@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"moxapp/internal/config"
+)
+
+var (
+	// init command flags
+	initOpts  = config.DefaultScaffoldOptions()
+	initForce bool
+	initYes   bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [path]",
+	Short: "Generate a starter config file",
+	Long: `Generate a starter config (default configs/endpoints.yaml) with example auth
+configs, outgoing endpoints and incoming routes.
+
+On a terminal, values not given as flags are asked for; with --yes, or when
+stdin is not a terminal, the defaults are used.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runInit,
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initOpts.BaseURL, "base-url", initOpts.BaseURL, "Base URL of the system under test")
+	initCmd.Flags().StringVar(&initOpts.Auth, "auth", initOpts.Auth, "Auth type: "+strings.Join(config.ScaffoldAuthTypes, ", "))
+	initCmd.Flags().Float64Var(&initOpts.Frequency, "frequency", initOpts.Frequency, "Requests per minute of each endpoint")
+	initCmd.Flags().IntVar(&initOpts.APIPort, "port", initOpts.APIPort, "API server port")
+	initCmd.Flags().BoolVar(&initOpts.IncomingRoutes, "incoming", initOpts.IncomingRoutes, "Include example incoming routes")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing file")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Don't ask, use flags and defaults")
+
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) {
+	path := "configs/endpoints.yaml"
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if _, err := os.Stat(path); err == nil && !initForce {
+		exitf("%s already exists (use --force to overwrite)", path)
+	}
+
+	if !initYes && stdinIsTerminal() {
+		askInitOptions(cmd)
+	}
+
+	data, err := config.Scaffold(initOpts)
+	if err != nil {
+		exitf("%v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		exitf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		exitf("Failed to write %s: %v", path, err)
+	}
+
+	fmt.Printf("Wrote %s\n", path)
+	fmt.Println()
+	fmt.Println("Next steps:")
+	switch initOpts.Auth {
+	case "api_key":
+		fmt.Println("  export MOXAPP_API_KEY=...")
+	case "bearer":
+		fmt.Println("  export MOXAPP_BEARER_TOKEN=...")
+	case "basic":
+		fmt.Println("  export MOXAPP_BASIC_USER=... MOXAPP_BASIC_PASS=...")
+	}
+	fmt.Printf("  moxapp --config %s --dry-run\n", path)
+	fmt.Printf("  moxapp --config %s\n", path)
+}
+
+// askInitOptions prompts for the options not given as flags, keeping the
+// current value on empty input
+func askInitOptions(cmd *cobra.Command) {
+	reader := bufio.NewReader(os.Stdin)
+	ask := func(flag, question, current string, valid func(string) bool) string {
+		if cmd.Flags().Changed(flag) {
+			return current
+		}
+		for {
+			fmt.Printf("%s [%s]: ", question, current)
+			answer, err := reader.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "" || err != nil {
+				return current
+			}
+			if valid(answer) {
+				return answer
+			}
+			fmt.Println("  Invalid value, try again.")
+		}
+	}
+
+	initOpts.BaseURL = ask("base-url", "Base URL of the system under test", initOpts.BaseURL, func(v string) bool {
+		opts := initOpts
+		opts.BaseURL = v
+		return len(opts.Validate()) == 0
+	})
+	initOpts.Auth = ask("auth", "Auth type ("+strings.Join(config.ScaffoldAuthTypes, ", ")+")", initOpts.Auth, func(v string) bool {
+		opts := initOpts
+		opts.Auth = v
+		return len(opts.Validate()) == 0
+	})
+	frequency := ask("frequency", "Requests per minute per endpoint", formatFloat(initOpts.Frequency), func(v string) bool {
+		f, err := strconv.ParseFloat(v, 64)
+		return err == nil && f > 0
+	})
+	initOpts.Frequency, _ = strconv.ParseFloat(frequency, 64)
+	port := ask("port", "API server port", strconv.Itoa(initOpts.APIPort), func(v string) bool {
+		p, err := strconv.Atoi(v)
+		return err == nil && p > 0 && p <= 65535
+	})
+	initOpts.APIPort, _ = strconv.Atoi(port)
+	incoming := ask("incoming", "Include example incoming routes? (yes/no)", yesNo(initOpts.IncomingRoutes), func(v string) bool {
+		v = strings.ToLower(v)
+		return v == "yes" || v == "y" || v == "no" || v == "n"
+	})
+	initOpts.IncomingRoutes = strings.HasPrefix(strings.ToLower(incoming), "y")
+	fmt.Println()
+}
+
+// formatFloat formats a float without trailing zeros
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -11,7 +11,6 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -19,11 +18,18 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"moxapp/internal/alerts"
 	"moxapp/internal/api"
 	"moxapp/internal/client"
 	"moxapp/internal/config"
+	"moxapp/internal/dnsprobe"
 	"moxapp/internal/metrics"
+	"moxapp/internal/notify"
+	"moxapp/internal/plugins"
+	"moxapp/internal/resources"
+	"moxapp/internal/runs"
 	"moxapp/internal/scheduler"
+	"moxapp/internal/sinks"
 )
 
 var (
@@ -36,7 +42,29 @@ var (
 	configFile  string
 	apiPort     int
 	logRequests bool
+	resultsFile string
+	accessLog   string
+	labelFlags  []string
 	noConfirm   bool
+	nonInteract bool
+	idle        bool
+	ipFamily    string
+	baseline    string
+	runLabel    string
+	adaptive    bool
+	heartbeat   int
+	environment string
+	percentiles string
+	pluginDir   string
+	prewarm     bool
+	showSecrets bool
+	tlsCert     string
+	tlsKey      string
+	selfSigned  bool
+	tui         bool
+	quiet       bool
+
+	outputFormat string
 
 	// Version info
 	version   = "1.0.2"
@@ -57,13 +85,34 @@ and API endpoint performance under load.`,
 func init() {
 	rootCmd.Flags().Float64VarP(&multiplier, "multiplier", "m", 1.0, "Global load multiplier (e.g., 0.5 for 50% load)")
 	rootCmd.Flags().IntVarP(&concurrent, "concurrent", "c", 30, "Number of concurrent requests")
-	rootCmd.Flags().StringVarP(&filter, "filter", "f", "", "Comma-separated endpoint name filters")
+	rootCmd.Flags().StringVarP(&filter, "filter", "f", "", "Comma-separated endpoint filters: name substring, glob (checkout-*), re:<regexp> or tag:<tag>")
 	rootCmd.Flags().BoolVar(&validate, "validate", false, "Validate config and exit")
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show configuration without running")
 	rootCmd.Flags().StringVar(&configFile, "config", "configs/endpoints.yaml", "Configuration file path")
 	rootCmd.Flags().IntVar(&apiPort, "port", 8080, "API server port")
 	rootCmd.Flags().BoolVar(&logRequests, "log-requests", false, "Log all individual requests")
+	rootCmd.Flags().StringArrayVar(&labelFlags, "label", nil, "Run label as key=value, attached to metrics, reports and result records (repeatable)")
+	rootCmd.Flags().StringVar(&resultsFile, "results-file", "", "Write all individual requests as NDJSON to this file (see result_sinks.file for rotation)")
+	rootCmd.Flags().StringVar(&accessLog, "access-log", "", "Log API requests to this file, - for stderr (see api_access_log for format, exclusions and sampling)")
 	rootCmd.Flags().BoolVarP(&noConfirm, "yes", "y", false, "Skip confirmation prompt")
+	rootCmd.Flags().BoolVar(&nonInteract, "non-interactive", false, "Never prompt (implied when stdin is not a terminal)")
+	rootCmd.Flags().BoolVar(&idle, "idle", false, "Start armed but idle; kick off the test via the API")
+	rootCmd.Flags().BoolVar(&adaptive, "adaptive", false, "Raise the multiplier until adaptive thresholds are crossed, then back off")
+	rootCmd.Flags().StringVar(&environment, "env", "", "Active environment from the environments section, referenced by templates as {{.BaseURL}} and {{.Vars.name}}")
+	rootCmd.Flags().StringVar(&percentiles, "percentiles", "", "Comma-separated latency percentiles to report, e.g. 50,90,99.9 (default 95,99)")
+	rootCmd.Flags().IntVar(&heartbeat, "heartbeat-timeout", 0, "Pause the scheduler after this many minutes without a heartbeat (see /api/outgoing/control/heartbeat)")
+	rootCmd.Flags().StringVar(&runLabel, "run-label", "", "Label for the run started at launch (see /api/runs)")
+	rootCmd.Flags().StringVar(&baseline, "baseline", "", "Metrics snapshot JSON to compare against (see /api/metrics/compare)")
+	rootCmd.Flags().StringVar(&ipFamily, "ip-family", config.IPFamilyDual, "Address family for outgoing connections (dual, ipv4, ipv6)")
+	rootCmd.Flags().StringVar(&pluginDir, "template-plugins", "", "Directory of Go plugins (.so) exporting custom template functions")
+	rootCmd.Flags().BoolVar(&showSecrets, "include-secrets", false, "Show secrets (credential env vars, sensitive headers and body fields) in API output and config export")
+	rootCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Serve the API over HTTPS with this certificate file (requires --tls-key)")
+	rootCmd.Flags().StringVar(&tlsKey, "tls-key", "", "Private key file for --tls-cert")
+	rootCmd.Flags().BoolVar(&selfSigned, "tls-self-signed", false, "Serve the API over HTTPS with a generated self-signed certificate")
+	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText, "Output format: text, or json for JSON lines on stdout")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress the banner, config summary and live display")
+	rootCmd.Flags().BoolVar(&tui, "tui", false, "Show a full-screen live dashboard instead of the periodic status line")
+	rootCmd.Flags().BoolVar(&prewarm, "prewarm-tokens", false, "Fetch all token endpoint tokens before starting and exit if any fails")
 
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "version",
@@ -82,7 +131,23 @@ func main() {
 }
 
 func runLoadTest(cmd *cobra.Command, args []string) {
-	printBanner()
+	if err := setupOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if textOutput() && !quiet {
+		printBanner()
+	}
+
+	// Register custom template functions before any template is evaluated
+	if pluginDir != "" {
+		names, err := plugins.LoadTemplatePlugins(pluginDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load template plugins: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded %d template functions from %s: %s\n", len(names), pluginDir, strings.Join(names, ", "))
+	}
 
 	// Create configuration manager
 	configManager := config.NewManager()
@@ -104,12 +169,25 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 		fmt.Printf("Loaded %d incoming routes from config\n", len(incomingRoutes))
 	}
 
-	// Override with CLI flags (only if explicitly set)
-	if cmd.Flags().Changed("multiplier") {
-		configManager.SetGlobalMultiplier(multiplier)
+	// Check run labels before applying them
+	flagLabels := make(map[string]string, len(labelFlags))
+	for _, label := range labelFlags {
+		key, value, err := config.ParseLabel(label)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --label: %v\n", err)
+			os.Exit(1)
+		}
+		flagLabels[key] = value
 	}
-	if cmd.Flags().Changed("concurrent") {
-		configManager.SetConcurrentRequests(concurrent)
+	if errs := config.ValidateLabels(flagLabels); len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --label: %s\n", strings.Join(errs, "; "))
+		os.Exit(1)
+	}
+
+	// Override with CLI flags (only if explicitly set)
+	if err := applyFlagOverrides(cmd, configManager); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	// Handle API port: CLI flag takes priority, then env var, then default
@@ -119,7 +197,15 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 		configManager.SetAPIPort(configManager.GetAPIPortFromEnv()) // Use env or default
 	}
 
-	configManager.SetLogAllRequests(logRequests)
+	if tlsCert != "" || tlsKey != "" || selfSigned {
+		tlsCfg := configManager.GetAPITLSConfig()
+		tlsCfg.Enabled = true
+		tlsCfg.CertFile, tlsCfg.KeyFile, tlsCfg.SelfSigned = tlsCert, tlsKey, selfSigned
+		if err := configManager.SetAPITLSConfig(tlsCfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid TLS options: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Get config snapshot for validation and display
 	cfg := configManager.GetConfig()
@@ -127,7 +213,12 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 	// Apply endpoint filter (this creates a filtered snapshot, not modifying manager)
 	var filteredEndpoints []config.Endpoint
 	if filter != "" {
-		filteredEndpoints = configManager.FilterEndpoints(filter)
+		var err error
+		filteredEndpoints, err = configManager.FilterEndpoints(filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid filter: %v\n", err)
+			os.Exit(1)
+		}
 		if len(filteredEndpoints) == 0 {
 			fmt.Fprintf(os.Stderr, "No endpoints matched filter: %s\n", filter)
 			os.Exit(1)
@@ -149,10 +240,42 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 	}
 
 	// Show configuration summary
-	showConfigSummary(configManager, cfg)
+	if textOutput() && !quiet {
+		showConfigSummary(configManager, cfg)
+	}
+	emit("config", map[string]interface{}{
+		"version":                   version,
+		"config_file":               configFile,
+		"endpoints":                 len(cfg.Endpoints),
+		"global_multiplier":         cfg.GlobalMultiplier,
+		"concurrent_requests":       cfg.ConcurrentRequests,
+		"adjusted_requests_per_min": configManager.GetAdjustedRequestsPerMin(),
+		"api_port":                  cfg.APIPort,
+		"labels":                    cfg.Labels,
+		"config_source":             configManager.GetProvenance(),
+	})
+
+	// Initialize token manager for auth configs
+	authMetrics := metrics.NewAuthCollector()
+	tokenManager := client.NewTokenManager(cfg.AuthConfigs, configManager)
+	tokenManager.SetFetchObserver(authMetrics.Record)
+
+	// Surface auth failures now rather than mid-test
+	if prewarm {
+		fmt.Println("Pre-warming auth tokens...")
+		if err := tokenManager.Prewarm(context.Background(), cfg.Endpoints); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to obtain auth tokens:\n%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("All auth tokens obtained.")
+	}
 
 	// Confirm start
-	if !noConfirm {
+	if !noConfirm && !nonInteract && !idle && !stdinIsTerminal() {
+		fmt.Println("stdin is not a terminal, starting without confirmation")
+		nonInteract = true
+	}
+	if !noConfirm && !nonInteract && !idle {
 		if !confirmStart() {
 			fmt.Println("Aborted.")
 			return
@@ -160,15 +283,30 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Println()
-	fmt.Println("Starting load test... (Press Ctrl+C to stop gracefully)")
+	if idle {
+		fmt.Println("Armed and idle - start the test via POST /api/runs or POST /api/outgoing/control {\"action\":\"start\"}")
+	} else {
+		fmt.Println("Starting load test... (Press Ctrl+C to stop gracefully)")
+	}
 	fmt.Println()
 
 	// Initialize components
 	metricsCollector := metrics.NewCollector()
+	if baseline != "" {
+		baselineSnapshot, err := metrics.LoadSnapshot(baseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load baseline: %v\n", err)
+			os.Exit(1)
+		}
+		metricsCollector.SetBaseline(baselineSnapshot)
+		fmt.Printf("Loaded baseline from %s (%d endpoints)\n", baseline, len(baselineSnapshot.Endpoints))
+	}
 	incomingMetrics := metrics.NewIncomingCollector()
-
-	// Initialize token manager for auth configs
-	tokenManager := client.NewTokenManager(cfg.AuthConfigs, configManager)
+	metricsCollector.SetLabelSource(configManager.GetLabels)
+	incomingMetrics.SetLabelSource(configManager.GetLabels)
+	metricsCollector.SetPercentileSource(configManager.GetPercentiles)
+	metricsCollector.SetTLSExpiryWarningSource(configManager.GetTLSExpiryWarningDays)
+	incomingMetrics.SetPercentileSource(configManager.GetPercentiles)
 
 	clientOpts := client.DefaultOptions()
 	clientOpts.Timeout = 30 * time.Second
@@ -177,33 +315,96 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 	clientOpts.EnvGetter = configManager
 	clientOpts.AuthConfigs = cfg.AuthConfigs
 	clientOpts.TokenManager = tokenManager
+	clientOpts.IPFamilySource = configManager.GetIPFamily
 	httpClient := client.New(clientOpts)
-
-	// Create scheduler with config manager for live updates
-	sched := scheduler.New(configManager, httpClient, func(result *client.RequestResult) {
-		metricsCollector.Record(result)
+	captureStore := client.NewCaptureStore(configManager)
+	httpClient.SetCaptureStore(captureStore)
+	cookieJars := client.NewCookieJars(configManager)
+	httpClient.SetCookieJars(cookieJars)
+
+	// Every request result goes to each registered sink
+	resultSinks := sinks.NewRegistry()
+	resultSinks.SetLabelSource(configManager.GetLabels)
+	resultSinks.Add("metrics", metricsCollector)
+	resultSinks.Add("console", sinks.SinkFunc(func(result *client.RequestResult) {
 		if configManager.GetConfig().LogAllRequests {
 			logResult(result)
 		}
-	})
+	}))
+	if err := resultSinks.AddConfigured(configManager.GetResultSinksConfig()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up result sinks: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create scheduler with config manager for live updates
+	sched := scheduler.New(configManager, httpClient, resultSinks.Record)
 
 	// Create API server with config manager for CRUD operations
 	apiAddr := fmt.Sprintf(":%d", cfg.APIPort)
 	apiServer := api.NewServerWithManager(apiAddr, metricsCollector, configManager)
 	apiServer.SetScheduler(sched)
 	apiServer.SetTokenManager(tokenManager)
+	apiServer.SetHTTPClient(httpClient)
+	apiServer.SetCaptureStore(captureStore)
+	apiServer.SetCookieJars(cookieJars)
 	apiServer.SetIncomingMetrics(incomingMetrics)
+	apiServer.SetAuthMetrics(authMetrics)
+	resourceMonitor := resources.NewMonitor()
+	apiServer.SetResourceMonitor(resourceMonitor)
+	apiServer.SetIncludeSecrets(showSecrets)
+	apiServer.SetRequireTokens(prewarm)
+	apiServer.SetCORSConfig(configManager.GetAPICORSConfig())
+	if err := apiServer.ConfigureTLS(configManager.GetAPITLSConfig()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to configure TLS: %v\n", err)
+		os.Exit(1)
+	}
+	if err := apiServer.ConfigureAccessLog(configManager.GetAPIAccessLogConfig()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up access log: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Every launch starts a run unless idle; later runs are started via the API
+	runStore := runs.NewStore(metricsCollector, runs.DefaultMaxRuns)
+	runStore.SetConfigSource(configManager.GetProvenance)
+
+	// Post run lifecycle events to Slack/Teams when notifications are configured
+	notifier := notify.New(configManager)
+	apiServer.SetNotifier(notifier)
+	runStore.SetListener(func(event string, run *runs.Run) {
+		if event == runs.StatusRunning {
+			notifier.RunStarted(run)
+		} else {
+			notifier.RunCompleted(run)
+		}
+	})
+	if !idle {
+		run := runStore.Start(runLabel, configManager.GetConfig())
+		fmt.Printf("Started run %s (%s)\n", run.ID, run.Label)
+		emit("run_started", map[string]interface{}{"run_id": run.ID, "label": run.Label})
+	}
+	apiServer.SetRunStore(runStore)
+
+	alertEvaluator := alerts.New(configManager, metricsCollector)
+	apiServer.SetAlerts(alertEvaluator)
+	alertEvaluator.SetListener(func(status alerts.Status, message string) {
+		notifier.Alert(status.State == alerts.StateFiring, message, metricsCollector.Snapshot())
+	})
 
 	// Start API server in background
 	go func() {
-		fmt.Printf("API server listening on http://localhost:%d\n", cfg.APIPort)
-		fmt.Printf("  - Web UI:    http://localhost:%d/\n", cfg.APIPort)
-		fmt.Printf("  - API Docs:  http://localhost:%d/api/docs/swagger\n", cfg.APIPort)
-		fmt.Printf("  - Metrics:   http://localhost:%d/api/metrics\n", cfg.APIPort)
-		fmt.Printf("  - Outgoing:  http://localhost:%d/api/outgoing/endpoints\n", cfg.APIPort)
-		fmt.Printf("  - Incoming:  http://localhost:%d/api/incoming/routes\n", cfg.APIPort)
-		fmt.Printf("  - Health:    http://localhost:%d/health\n", cfg.APIPort)
-		fmt.Println()
+		baseURL := apiServer.GetListenAddr()
+		fmt.Printf("API server listening on %s\n", baseURL)
+		emit("api_listening", map[string]interface{}{"url": baseURL})
+		if !quiet {
+			fmt.Printf("  - Web UI:    %s/\n", baseURL)
+			fmt.Printf("  - API Docs:  %s/api/docs/swagger\n", baseURL)
+			fmt.Printf("  - Metrics:   %s/api/metrics\n", baseURL)
+			fmt.Printf("  - Outgoing:  %s/api/outgoing/endpoints\n", baseURL)
+			fmt.Printf("  - Incoming:  %s/api/incoming/routes\n", baseURL)
+			fmt.Printf("  - Health:    %s/health\n", baseURL)
+			fmt.Printf("  - Probes:    %s/healthz, %s/readyz\n", baseURL, baseURL)
+			fmt.Println()
+		}
 		if err := apiServer.Start(); err != nil && err != http.ErrServerClosed {
 			fmt.Fprintf(os.Stderr, "API server error: %v\n", err)
 		}
@@ -216,27 +417,76 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 	// Start token manager background refresh
 	tokenManager.StartBackgroundRefresh(ctx)
 
+	// Sample the generator's own resource usage for /health and metrics
+	resourceMonitor.Start(ctx)
+
+	// Start standalone DNS probe (idles while dns_probe.enabled is false)
+	dnsprobe.New(configManager, metricsCollector).Start(ctx)
+
+	// Evaluate alert rules (idles while alerts.rules is empty)
+	alertEvaluator.Start(ctx)
+
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	go func() {
-		<-sigChan
-		fmt.Println()
-		fmt.Println("Received shutdown signal, stopping gracefully...")
-		cancel()
+		for sig := range sigChan {
+			if sig == syscall.SIGHUP {
+				reloadConfig(cmd, configManager, tokenManager, cookieJars)
+				continue
+			}
+			fmt.Println()
+			fmt.Println("Received shutdown signal, stopping gracefully...")
+			emit("stopping", map[string]interface{}{"signal": sig.String()})
+			cancel()
+			return
+		}
 	}()
 
 	// Start live metrics display
+	if tui && !isTerminal(os.Stdout) {
+		fmt.Println("stdout is not a terminal, ignoring --tui")
+		tui = false
+	}
+	var dash *dashboard
 	stopDisplay := make(chan struct{})
-	go displayLiveMetrics(metricsCollector, stopDisplay)
+	if tui {
+		dash = newDashboard(metricsCollector, sched)
+		if err := dash.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start dashboard: %v\n", err)
+			dash = nil
+		}
+	}
+	if dash == nil && !quiet {
+		go displayLiveMetrics(metricsCollector, stopDisplay)
+	}
 
 	// Run scheduler (blocks until context is cancelled)
-	if err := sched.Start(ctx); err != nil {
+	if err := sched.Run(ctx, idle); err != nil {
 		fmt.Fprintf(os.Stderr, "Scheduler error: %v\n", err)
 	}
 
 	// Stop live display
 	close(stopDisplay)
+	if dash != nil {
+		dash.Stop()
+	}
+
+	// Finalize the run in progress, if any
+	if finished, err := runStore.Finish(); err == nil {
+		fmt.Printf("Finalized run %s (%.0fs)\n", finished.ID, finished.DurationSeconds)
+		emit("run_finished", map[string]interface{}{"run_id": finished.ID, "duration_seconds": finished.DurationSeconds})
+	}
+
+	// Flush and close the result sinks
+	if err := resultSinks.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to close result sinks: %v\n", err)
+	}
+
+	// Let the last notifications go out
+	notifyCtx, notifyCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	notifier.Wait(notifyCtx)
+	notifyCancel()
 
 	// Shutdown API server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -248,7 +498,28 @@ func runLoadTest(cmd *cobra.Command, args []string) {
 
 	fmt.Println()
 	fmt.Println("Load test stopped.")
-	showFinalStats(metricsCollector, incomingMetrics)
+	if textOutput() {
+		showFinalStats(metricsCollector, incomingMetrics)
+	} else {
+		emit("final", map[string]interface{}{
+			"outgoing":   metricsCollector.Snapshot(),
+			"incoming":   incomingMetrics.Snapshot(),
+			"dns_probes": metricsCollector.DNSProbeSnapshot(),
+		})
+	}
+}
+
+// describeProvenance summarizes a config file revision, e.g.
+// "sha256 1a2b3c4d5e6f, git 0123abc (uncommitted changes)"
+func describeProvenance(source *config.Provenance) string {
+	text := "sha256 " + source.SHA256[:12]
+	if source.GitCommit != "" {
+		text += ", git " + source.GitCommit[:min(len(source.GitCommit), 12)]
+		if source.GitDirty {
+			text += " (uncommitted changes)"
+		}
+	}
+	return text
 }
 
 func printBanner() {
@@ -261,6 +532,7 @@ func printBanner() {
 
 func validateAndShowConfig(manager *config.Manager, cfg *config.Config) {
 	errors := manager.Validate()
+	emit("validation", map[string]interface{}{"valid": len(errors) == 0, "errors": append([]string{}, errors...)})
 
 	if len(errors) > 0 {
 		fmt.Println("Configuration Errors:")
@@ -283,14 +555,26 @@ func showConfigSummary(manager *config.Manager, cfg *config.Config) {
 	fmt.Println("Configuration Summary:")
 	fmt.Println("-------------------------------------------------------------")
 	fmt.Printf("  Config File:                %s\n", configFile)
+	if source := manager.GetProvenance(); source != nil {
+		fmt.Printf("  Config Revision:            %s\n", describeProvenance(source))
+	}
+	if env := cfg.ActiveEnvironment(); env != nil {
+		fmt.Printf("  Environment:                %s (%s)\n", cfg.Environment, env.BaseURL)
+	}
 	fmt.Printf("  Global Multiplier:          %.2f\n", cfg.GlobalMultiplier)
 	fmt.Printf("  Concurrent Requests:        %d\n", cfg.ConcurrentRequests)
+	fmt.Printf("  IP Family:                  %s\n", cfg.IPFamily)
+	fmt.Printf("  DNS Probe:                  %v\n", cfg.DNSProbe.Enabled)
+	fmt.Printf("  Adaptive Mode:              %v\n", cfg.Adaptive.Enabled)
 	fmt.Printf("  Total Endpoints:            %d\n", len(cfg.Endpoints))
 	fmt.Printf("  Base Requests/min:          %.2f\n", baseReqPerMin)
 	fmt.Printf("  Adjusted Requests/min:      %.2f\n", adjustedReqPerMin)
 	fmt.Printf("  Estimated Requests/sec:     %.2f\n", adjustedReqPerMin/60)
 	fmt.Printf("  API Port:                   %d\n", cfg.APIPort)
 	fmt.Printf("  Log All Requests:           %v\n", cfg.LogAllRequests)
+	if len(cfg.Labels) > 0 {
+		fmt.Printf("  Labels:                     %s\n", metrics.FormatLabels(cfg.Labels))
+	}
 	fmt.Println("-------------------------------------------------------------")
 	fmt.Println()
 
@@ -311,6 +595,107 @@ func showConfigSummary(manager *config.Manager, cfg *config.Config) {
 	fmt.Println()
 }
 
+// applyFlagOverrides applies explicitly set CLI flags on top of the config
+// file. A multiplier or environment that can't be applied, such as one the
+// guardrails don't allow, or invalid percentiles are returned as errors and
+// the file's are kept.
+func applyFlagOverrides(cmd *cobra.Command, configManager *config.Manager) error {
+	var errs []error
+	if cmd.Flags().Changed("multiplier") {
+		if err := configManager.SetGlobalMultiplier(multiplier); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --multiplier: %w", err))
+		}
+	}
+	if cmd.Flags().Changed("env") {
+		if err := configManager.SetEnvironment(environment); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --env: %w", err))
+		}
+	}
+	if cmd.Flags().Changed("percentiles") {
+		parsed, err := config.ParsePercentiles(percentiles)
+		if err == nil {
+			err = configManager.SetPercentiles(parsed)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid --percentiles: %w", err))
+		}
+	}
+	if cmd.Flags().Changed("concurrent") {
+		configManager.SetConcurrentRequests(concurrent)
+	}
+	if cmd.Flags().Changed("ip-family") {
+		if err := configManager.SetIPFamily(ipFamily); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --ip-family: %w", err))
+		}
+	}
+	if cmd.Flags().Changed("adaptive") {
+		configManager.SetAdaptiveEnabled(adaptive)
+	}
+	if cmd.Flags().Changed("heartbeat-timeout") {
+		configManager.SetHeartbeatTimeout(heartbeat)
+	}
+	for _, label := range labelFlags {
+		if key, value, err := config.ParseLabel(label); err == nil {
+			configManager.SetLabel(key, value)
+		}
+	}
+	if cmd.Flags().Changed("results-file") {
+		configManager.SetResultsFile(resultsFile)
+	}
+	if cmd.Flags().Changed("access-log") {
+		configManager.SetAPIAccessLogFile(accessLog)
+	}
+	configManager.SetLogAllRequests(logRequests)
+	return errors.Join(errs...)
+}
+
+// reloadConfig reloads the config file on SIGHUP and applies endpoint, auth
+// and incoming route changes live. A file that fails to load or validate is
+// reported and the running config is kept.
+func reloadConfig(cmd *cobra.Command, configManager *config.Manager, tokenManager *client.TokenManager, cookieJars *client.CookieJars) {
+	path := configManager.GetConfigPath()
+	if err := configManager.ReloadFromFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "\n[reload] Failed to reload %s, keeping the current config: %v\n", path, err)
+		emit("reload_failed", map[string]interface{}{"config_file": path, "error": err.Error()})
+		return
+	}
+
+	// The file replaces the config; flags still take priority over it
+	if err := applyFlagOverrides(cmd, configManager); err != nil {
+		fmt.Fprintf(os.Stderr, "\n[reload] %v\n", err)
+	}
+	cfg := configManager.GetConfig()
+	tokenManager.UpdateAuthConfigs(cfg.AuthConfigs)
+	cookieJars.ResetAll()
+
+	fmt.Printf("\n[reload] Reloaded %s (version %d): %d endpoints, %d auth configs, %d incoming routes\n",
+		path, configManager.CurrentVersion(), len(cfg.Endpoints), len(cfg.AuthConfigs), len(cfg.IncomingRoutes))
+	emit("reloaded", map[string]interface{}{
+		"config_file": path,
+		"version":     configManager.CurrentVersion(),
+		"endpoints":   len(cfg.Endpoints),
+	})
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal, which it
+// isn't under Kubernetes, Docker without -t, CI or with piped input
+func stdinIsTerminal() bool {
+	return isTerminal(os.Stdin)
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null is a character device too; containers often get it as stdin
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
 func confirmStart() bool {
 	fmt.Print("Start load test? (yes/no) [yes]: ")
 	reader := bufio.NewReader(os.Stdin)
@@ -320,17 +705,35 @@ func confirmStart() bool {
 }
 
 func logResult(result *client.RequestResult) {
+	if !textOutput() {
+		emit("request", map[string]interface{}{
+			"endpoint":      result.EndpointName,
+			"method":        result.Method,
+			"hostname":      result.Hostname,
+			"success":       result.Success,
+			"status_code":   result.StatusCode,
+			"dns_time_ms":   result.DNSTimeMs,
+			"total_time_ms": result.TotalTimeMs,
+			"redirects":     result.Redirects,
+			"request_id":    result.RequestID,
+			"error":         result.Error,
+			"labels":        result.Labels,
+		})
+		return
+	}
+
 	status := "OK"
 	if !result.Success {
 		status = "FAIL"
 	}
-	fmt.Printf("\r[%s] %s %s %s (dns:%.1fms total:%.1fms)\n",
+	fmt.Printf("\r[%s] %s %s %s (dns:%.1fms total:%.1fms id:%s)\n",
 		status,
 		result.Method,
 		result.EndpointName,
 		result.Hostname,
 		result.DNSTimeMs,
 		result.TotalTimeMs,
+		result.RequestID,
 	)
 }
 
@@ -346,6 +749,14 @@ func displayLiveMetrics(collector *metrics.Collector, stop chan struct{}) {
 			total := collector.GetTotalRequests()
 			rate := collector.GetRequestsPerSecond()
 			successRate := collector.GetSuccessRate()
+			if !textOutput() {
+				emit("progress", map[string]interface{}{
+					"total_requests":      total,
+					"requests_per_second": rate,
+					"success_rate":        successRate,
+				})
+				continue
+			}
 			fmt.Printf("\r[LIVE] Requests: %d | Rate: %.1f req/s | Success: %.1f%%     ",
 				total, rate, successRate)
 		}
@@ -366,38 +777,22 @@ func showFinalStats(collector *metrics.Collector, incomingCollector *metrics.Inc
 	fmt.Println()
 
 	// Show top failures
-	type failureInfo struct {
-		name  string
-		count int64
-		error string
-	}
-	var failures []failureInfo
-
-	for name, ep := range snapshot.Endpoints {
-		if ep.Failed > 0 {
-			failures = append(failures, failureInfo{
-				name:  name,
-				count: ep.Failed,
-				error: ep.LastError,
-			})
+	if failures := metrics.TopEndpoints(snapshot.Endpoints, "errors", metrics.DefaultTopLimit); len(failures) > 0 {
+		fmt.Println("Endpoints with Failures (top 10):")
+		for _, f := range failures {
+			fmt.Printf("  %s: %d failures\n", f.Name, f.Failed)
+			if f.LastError != "" {
+				fmt.Printf("    Last error: %s\n", f.LastError)
+			}
 		}
+		fmt.Println()
 	}
 
-	if len(failures) > 0 {
-		// Sort by failure count descending
-		sort.Slice(failures, func(i, j int) bool {
-			return failures[i].count > failures[j].count
-		})
-
-		fmt.Println("Endpoints with Failures (top 10):")
-		for i, f := range failures {
-			if i >= 10 {
-				break
-			}
-			fmt.Printf("  %s: %d failures\n", f.name, f.count)
-			if f.error != "" {
-				fmt.Printf("    Last error: %s\n", f.error)
-			}
+	// Show certificates expiring soon
+	if len(snapshot.TLSWarnings) > 0 {
+		fmt.Println("TLS Certificate Warnings:")
+		for _, warning := range snapshot.TLSWarnings {
+			fmt.Printf("  %s (not after %s)\n", warning.Message, warning.CertNotAfter)
 		}
 		fmt.Println()
 	}
@@ -409,10 +804,37 @@ func showFinalStats(collector *metrics.Collector, incomingCollector *metrics.Inc
 			if stats.FailedLookups > 0 {
 				fmt.Printf("  %s: %d failed lookups (avg: %.2fms, p95: %.2fms)\n",
 					hostname, stats.FailedLookups, stats.AvgResolutionMs, stats.P95ResolutionMs)
+				for kind, count := range stats.ErrorsByType {
+					fmt.Printf("    %s: %d\n", kind, count)
+				}
 			} else if stats.SuccessfulLookups > 0 {
 				fmt.Printf("  %s: avg %.2fms, p95 %.2fms (total: %d lookups)\n",
 					hostname, stats.AvgResolutionMs, stats.P95ResolutionMs, stats.TotalLookups)
 			}
+			for family, fs := range stats.ByFamily {
+				fmt.Printf("    %s: avg %.2fms, p95 %.2fms (%d lookups)\n",
+					family, fs.AvgResolutionMs, fs.P95ResolutionMs, fs.Lookups)
+			}
+			for ip, is := range stats.ByIP {
+				if is.Requests == 0 {
+					continue
+				}
+				fmt.Printf("    %s: %d requests (%.1f%%), %.1f%% errors\n",
+					ip, is.Requests, is.RequestShare, is.ErrorRate)
+			}
+		}
+		fmt.Println()
+	}
+
+	// Show standalone DNS probe results
+	if probes := collector.DNSProbeSnapshot(); len(probes) > 0 {
+		fmt.Println("DNS Probe Results:")
+		for hostname, probe := range probes {
+			for recordType, rs := range probe.Records {
+				fmt.Printf("  %s %s: %d probes, %d failures, avg %.2fms, TTL %d-%ds, %d answer changes\n",
+					hostname, recordType, rs.Probes, rs.Failures, rs.AvgResolutionMs,
+					rs.MinTTLSeconds, rs.MaxTTLSeconds, rs.AnswerChanges)
+			}
 		}
 		fmt.Println()
 	}
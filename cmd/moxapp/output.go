@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	// jsonOut writes JSON lines to the original stdout with --output=json
+	jsonOut   *json.Encoder
+	jsonOutMu sync.Mutex
+)
+
+// setupOutput applies --output. With json, stdout carries only JSON lines
+// (see emit); anything else printed, by moxapp or its packages, goes to
+// stderr instead.
+func setupOutput() error {
+	switch outputFormat {
+	case outputText:
+	case outputJSON:
+		jsonOut = json.NewEncoder(os.Stdout)
+		os.Stdout = os.Stderr
+	default:
+		return fmt.Errorf("invalid --output %q: must be %s or %s", outputFormat, outputText, outputJSON)
+	}
+	if tui && (quiet || jsonOut != nil) {
+		return fmt.Errorf("--tui can't be combined with --quiet or --output=json")
+	}
+	return nil
+}
+
+// textOutput reports whether human-readable progress goes to stdout
+func textOutput() bool {
+	return jsonOut == nil
+}
+
+// emit writes an event as a JSON line with --output=json. Every line has
+// "event" and "time"; fields are added as is.
+func emit(event string, fields map[string]interface{}) {
+	if jsonOut == nil {
+		return
+	}
+	line := map[string]interface{}{
+		"event": event,
+		"time":  time.Now().Format(time.RFC3339Nano),
+	}
+	for k, v := range fields {
+		line[k] = v
+	}
+
+	jsonOutMu.Lock()
+	defer jsonOutMu.Unlock()
+	_ = jsonOut.Encode(line)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Flags of subcommands that talk to a running instance
+	remoteAddr     string
+	remoteInsecure bool
+)
+
+// addRemoteFlags adds the flags selecting the running instance to talk to
+func addRemoteFlags(cmd *cobra.Command) {
+	addr := os.Getenv("MOXAPP_ADDR")
+	if addr == "" {
+		addr = "http://localhost:8080"
+	}
+	cmd.PersistentFlags().StringVar(&remoteAddr, "addr", addr, "Base URL of a running moxapp API (or set MOXAPP_ADDR)")
+	cmd.PersistentFlags().BoolVar(&remoteInsecure, "insecure", false, "Skip TLS certificate verification (for --tls-self-signed instances)")
+}
+
+// callAPI sends a request to the running instance, decoding the JSON
+// response into out unless it is nil. Error responses return their message.
+func callAPI(method, path string, body, out interface{}) error {
+	data, err := fetchAPI(method, path, body)
+	if err != nil || out == nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", strings.TrimSuffix(remoteAddr, "/")+path, err)
+	}
+	return nil
+}
+
+// fetchAPI sends a request to the running instance and returns the raw
+// response body. Error responses return their message.
+func fetchAPI(method, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	url := strings.TrimSuffix(remoteAddr, "/") + path
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	if remoteInsecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach moxapp at %s: %w", remoteAddr, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", url, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("%s (HTTP %d, %s)", apiErr.Error.Message, resp.StatusCode, apiErr.Error.Code)
+		}
+		return nil, fmt.Errorf("%s %s: HTTP %d", method, path, resp.StatusCode)
+	}
+	return data, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"moxapp/internal/client"
+	"moxapp/internal/metrics"
+	"moxapp/internal/replay"
+)
+
+var (
+	// replay command flags
+	replayTarget      string
+	replaySpeed       float64
+	replayFormat      string
+	replayMaxInFlight int
+	replayTimeout     time.Duration
+	replayHeaders     []string
+	replaySnapshot    string
+	replayYes         bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <access.log>",
+	Short: "Replay the requests of an access log with their original timing",
+	Long: `Replay the requests of an access log against a target, such as staging,
+keeping the time between requests so production traffic shapes are reproduced.
+--speed 2 replays twice as fast, 0.5 at half speed.
+
+Each line needs a timestamp, method and path. Supported formats (--format):
+  combined  Apache/nginx combined or common log format
+  json      JSON lines with time, method, path and query, as written by the API access log
+  simple    <RFC 3339 or Unix timestamp> <METHOD> <path>
+auto detects the format per line. Lines that can't be parsed are skipped.
+
+Paths are appended to --target. Metrics are recorded per method and path, with
+numeric and UUID segments grouped (GET /users/42 becomes get_users_id). Use
+--snapshot to save them for 'moxapp report' or --baseline.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replayTarget, "target", "", "Base URL requests are sent to, e.g. https://staging.example.com (required)")
+	replayCmd.Flags().Float64Var(&replaySpeed, "speed", 1, "Speed multiplier of the recorded timing")
+	replayCmd.Flags().StringVar(&replayFormat, "format", replay.FormatAuto, "Log format: "+strings.Join(replay.Formats, ", "))
+	replayCmd.Flags().IntVar(&replayMaxInFlight, "max-in-flight", replay.DefaultMaxInFlight, "Cap on concurrent requests; requests due beyond it are sent late")
+	replayCmd.Flags().DurationVar(&replayTimeout, "timeout", 30*time.Second, "Request timeout")
+	replayCmd.Flags().StringArrayVar(&replayHeaders, "header", nil, "Header sent with every request as 'Name: value' (repeatable)")
+	replayCmd.Flags().StringVar(&replaySnapshot, "snapshot", "", "Write the metrics snapshot as JSON to this file")
+	replayCmd.Flags().BoolVarP(&replayYes, "yes", "y", false, "Skip confirmation prompt")
+	replayCmd.MarkFlagRequired("target")
+
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) {
+	if replaySpeed <= 0 {
+		exitf("Invalid speed %v: must be positive", replaySpeed)
+	}
+	headers, err := parseHeaderFlags(replayHeaders)
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		exitf("Failed to open %s: %v", args[0], err)
+	}
+	accessLog, err := replay.Parse(file, replayFormat)
+	file.Close()
+	if err != nil {
+		exitf("Failed to parse %s: %v", args[0], err)
+	}
+	if accessLog.Skipped > 0 {
+		fmt.Printf("Skipped %d unparseable lines (first: %s)\n", accessLog.Skipped, accessLog.FirstError)
+	}
+	if len(accessLog.Entries) == 0 {
+		exitf("No requests found in %s", args[0])
+	}
+
+	clientOpts := client.DefaultOptions()
+	clientOpts.Timeout = replayTimeout
+	clientOpts.MaxConns = replayMaxInFlight
+	collector := metrics.NewCollector()
+	player, err := replay.New(client.New(clientOpts), collector.Record, replay.Options{
+		Target:      replayTarget,
+		Speed:       replaySpeed,
+		MaxInFlight: replayMaxInFlight,
+		Headers:     headers,
+	})
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	duration := time.Duration(float64(accessLog.Duration()) / replaySpeed)
+	fmt.Printf("Replaying %d requests recorded over %s to %s at %gx (about %s)\n",
+		len(accessLog.Entries), accessLog.Duration().Round(time.Second), replayTarget, replaySpeed, duration.Round(time.Second))
+	if !replayYes && stdinIsTerminal() && !confirmReplay() {
+		fmt.Println("Aborted.")
+		return
+	}
+	fmt.Println()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	stats := player.Run(ctx, accessLog.Entries)
+
+	if stats.Stopped {
+		fmt.Printf("Replay stopped after %d of %d requests\n\n", stats.Sent, len(accessLog.Entries))
+	}
+	fmt.Println("Replay Timing:")
+	fmt.Printf("  Duration:                   %s\n", stats.Duration.Round(time.Millisecond))
+	fmt.Printf("  Late (>%s):              %d of %d\n", replay.LateThreshold, stats.Late, stats.Sent)
+	fmt.Printf("  Avg / Max Lag:              %.1fms / %.1fms\n", stats.AvgLagMs, stats.MaxLagMs)
+	fmt.Println()
+	showFinalStats(collector, nil)
+
+	if replaySnapshot != "" {
+		data, err := json.MarshalIndent(collector.Snapshot(), "", "  ")
+		if err == nil {
+			err = os.WriteFile(replaySnapshot, data, 0644)
+		}
+		if err != nil {
+			exitf("Failed to write snapshot %s: %v", replaySnapshot, err)
+		}
+		fmt.Printf("Metrics snapshot written to %s\n", replaySnapshot)
+	}
+}
+
+func confirmReplay() bool {
+	fmt.Print("Start replay? (yes/no) [yes]: ")
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "" || response == "yes" || response == "y"
+}
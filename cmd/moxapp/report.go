@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"moxapp/internal/metrics"
+)
+
+var (
+	// report command flags
+	reportFormat string
+	reportTop    int
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report <snapshot.json>",
+	Short: "Render a report from an exported metrics snapshot",
+	Long: `Render a human-readable report from a metrics snapshot exported from
+/api/metrics/outgoing (or /api/metrics): summary, top failures, latency per
+endpoint and DNS stats per domain. Use --format markdown for test tickets.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportFormat, "format", "text", "Output format: text or markdown")
+	reportCmd.Flags().IntVar(&reportTop, "top", 10, "Number of endpoints listed under top failures")
+
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) {
+	if reportFormat != "text" && reportFormat != "markdown" {
+		exitf("Invalid format %q: must be text or markdown", reportFormat)
+	}
+	snap, err := metrics.LoadSnapshot(args[0])
+	if err != nil {
+		exitf("Failed to load snapshot %s: %v", args[0], err)
+	}
+
+	r := &reportWriter{w: os.Stdout, markdown: reportFormat == "markdown"}
+	writeReport(r, snap, args[0])
+}
+
+func writeReport(r *reportWriter, snap *metrics.MetricsSnapshot, source string) {
+	names := make([]string, 0, len(snap.Endpoints))
+	var timeouts, dns, connection, httpErrors, other int64
+	for name, ep := range snap.Endpoints {
+		names = append(names, name)
+		timeouts += ep.TimeoutErrors
+		dns += ep.DNSErrors
+		connection += ep.ConnectionErrors
+		httpErrors += ep.HTTPErrors
+		other += ep.OtherErrors
+	}
+
+	r.title("MoxApp Load Test Report")
+	r.field("Snapshot", source)
+	r.field("Collected", snap.CollectedAt)
+	if len(snap.Labels) > 0 {
+		r.field("Labels", metrics.FormatLabels(snap.Labels))
+	}
+	if snap.Window != "" {
+		r.field("Window", "last "+snap.Window)
+	} else {
+		r.field("Duration", (time.Duration(snap.UptimeSeconds) * time.Second).String())
+	}
+	r.field("Requests", fmt.Sprintf("%d (%d ok, %d failed)", snap.TotalRequests, snap.TotalSuccesses, snap.TotalFailures))
+	r.field("Success Rate", fmt.Sprintf("%.2f%%", snap.SuccessRate))
+	r.field("Throughput", fmt.Sprintf("%.2f req/s", snap.RequestsPerSecond))
+	r.field("Endpoints", fmt.Sprint(len(snap.Endpoints)))
+	r.field("Errors", fmt.Sprintf("timeout %d, dns %d, connection %d, http %d, other %d", timeouts, dns, connection, httpErrors, other))
+
+	// Top failures: most failed requests first
+	var failing []string
+	for _, name := range names {
+		if snap.Endpoints[name].Failed > 0 {
+			failing = append(failing, name)
+		}
+	}
+	sort.Slice(failing, func(i, j int) bool {
+		a, b := snap.Endpoints[failing[i]], snap.Endpoints[failing[j]]
+		if a.Failed != b.Failed {
+			return a.Failed > b.Failed
+		}
+		return failing[i] < failing[j]
+	})
+	if len(failing) > reportTop {
+		failing = failing[:reportTop]
+	}
+
+	r.heading("Top Failures")
+	if len(failing) == 0 {
+		r.line("No failed requests.")
+	} else {
+		rows := make([][]string, 0, len(failing))
+		for _, name := range failing {
+			ep := snap.Endpoints[name]
+			rows = append(rows, []string{
+				name, fmt.Sprint(ep.Failed), fmt.Sprintf("%.2f%%", 100-ep.SuccessRate),
+				fmt.Sprint(ep.TimeoutErrors), fmt.Sprint(ep.DNSErrors), fmt.Sprint(ep.ConnectionErrors), fmt.Sprint(ep.HTTPErrors),
+				truncate(ep.LastError, 60),
+			})
+		}
+		r.table([]string{"Endpoint", "Failed", "Error Rate", "Timeout", "DNS", "Conn", "HTTP", "Last Error"}, rows)
+	}
+
+	// Latency: slowest p95 first
+	sort.Slice(names, func(i, j int) bool {
+		a, b := snap.Endpoints[names[i]], snap.Endpoints[names[j]]
+		if a.P95TotalTimeMs != b.P95TotalTimeMs {
+			return a.P95TotalTimeMs > b.P95TotalTimeMs
+		}
+		return names[i] < names[j]
+	})
+
+	r.heading("Latency by Endpoint")
+	if len(names) == 0 {
+		r.line("No endpoints.")
+	} else {
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			ep := snap.Endpoints[name]
+			rows = append(rows, []string{
+				name, fmt.Sprint(ep.TotalRequests), fmt.Sprintf("%.2f%%", ep.SuccessRate), apdex(ep),
+				ms(ep.AvgTotalTimeMs), ms(ep.P95TotalTimeMs), ms(ep.P99TotalTimeMs), ms(ep.MaxTotalTimeMs),
+				ms(ep.AvgDNSTimeMs), ms(ep.AvgTTFBMs),
+			})
+		}
+		r.table([]string{"Endpoint", "Requests", "Success", "Apdex", "Avg", "P95", "P99", "Max", "DNS Avg", "TTFB Avg"}, rows)
+	}
+
+	// DNS: slowest p95 first
+	domains := make([]string, 0, len(snap.DNSStatsByDomain))
+	for domain := range snap.DNSStatsByDomain {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		a, b := snap.DNSStatsByDomain[domains[i]], snap.DNSStatsByDomain[domains[j]]
+		if a.P95ResolutionMs != b.P95ResolutionMs {
+			return a.P95ResolutionMs > b.P95ResolutionMs
+		}
+		return domains[i] < domains[j]
+	})
+
+	r.heading("DNS by Domain")
+	if len(domains) == 0 {
+		r.line("No DNS lookups.")
+	} else {
+		rows := make([][]string, 0, len(domains))
+		for _, domain := range domains {
+			d := snap.DNSStatsByDomain[domain]
+			rows = append(rows, []string{
+				domain, fmt.Sprint(d.TotalLookups), fmt.Sprint(d.FailedLookups), dnsErrorTypes(d),
+				ms(d.AvgResolutionMs), ms(d.P95ResolutionMs), ms(d.MaxResolutionMs),
+				truncate(d.LastError, 60),
+			})
+		}
+		r.table([]string{"Domain", "Lookups", "Failed", "Error Types", "Avg", "P95", "Max", "Last Error"}, rows)
+	}
+}
+
+// reportWriter writes report sections as plain text or Markdown
+type reportWriter struct {
+	w        io.Writer
+	markdown bool
+}
+
+func (r *reportWriter) title(text string) {
+	if r.markdown {
+		fmt.Fprintf(r.w, "# %s\n\n", text)
+		return
+	}
+	fmt.Fprintln(r.w, text)
+	fmt.Fprintln(r.w, "=============================================================")
+}
+
+func (r *reportWriter) heading(text string) {
+	if r.markdown {
+		fmt.Fprintf(r.w, "\n## %s\n\n", text)
+		return
+	}
+	fmt.Fprintf(r.w, "\n%s\n%s\n", text, strings.Repeat("-", len(text)))
+}
+
+func (r *reportWriter) field(name, value string) {
+	if r.markdown {
+		fmt.Fprintf(r.w, "- **%s:** %s\n", name, value)
+		return
+	}
+	fmt.Fprintf(r.w, "  %-14s %s\n", name+":", value)
+}
+
+func (r *reportWriter) line(text string) {
+	if r.markdown {
+		fmt.Fprintln(r.w, text)
+		return
+	}
+	fmt.Fprintf(r.w, "  %s\n", text)
+}
+
+// table writes rows with the first column left-aligned and the rest
+// right-aligned, except a trailing free-text column
+func (r *reportWriter) table(headers []string, rows [][]string) {
+	if r.markdown {
+		fmt.Fprintf(r.w, "| %s |\n", strings.Join(headers, " | "))
+		aligns := make([]string, len(headers))
+		for i := range aligns {
+			aligns[i] = "---:"
+			if i == 0 || isTextColumn(headers[i]) {
+				aligns[i] = "---"
+			}
+		}
+		fmt.Fprintf(r.w, "|%s|\n", strings.Join(aligns, "|"))
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			fmt.Fprintf(r.w, "| %s |\n", strings.Join(cells, " | "))
+		}
+		return
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	writeRow := func(cells []string) {
+		parts := make([]string, len(cells))
+		for i, cell := range cells {
+			if i == 0 || isTextColumn(headers[i]) {
+				parts[i] = fmt.Sprintf("%-*s", widths[i], cell)
+			} else {
+				parts[i] = fmt.Sprintf("%*s", widths[i], cell)
+			}
+		}
+		fmt.Fprintf(r.w, "  %s\n", strings.TrimRight(strings.Join(parts, "  "), " "))
+	}
+	upper := make([]string, len(headers))
+	for i, header := range headers {
+		upper[i] = strings.ToUpper(header)
+	}
+	writeRow(upper)
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+// isTextColumn reports whether a report column holds free text
+func isTextColumn(header string) bool {
+	return header == "Last Error" || header == "Error Types"
+}
+
+// ms formats milliseconds for report tables
+func ms(value float64) string {
+	return fmt.Sprintf("%.1fms", value)
+}
+
+// apdex formats the Apdex score of an endpoint, - for snapshots without one
+func apdex(ep metrics.EndpointSnapshot) string {
+	if ep.Apdex == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *ep.Apdex)
+}
+
+// dnsErrorTypes formats the failed lookups of a domain by DNS error type,
+// e.g. "nxdomain=3, timeout=1", - without failures
+func dnsErrorTypes(d metrics.DomainSnapshot) string {
+	if len(d.ErrorsByType) == 0 {
+		return "-"
+	}
+	kinds := make([]string, 0, len(d.ErrorsByType))
+	for kind := range d.ErrorsByType {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for i, kind := range kinds {
+		kinds[i] = fmt.Sprintf("%s=%d", kind, d.ErrorsByType[kind])
+	}
+	return strings.Join(kinds, ", ")
+}
+
+// truncate shortens text to at most n characters
+func truncate(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= n {
+		return text
+	}
+	return text[:n-3] + "..."
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"moxapp/internal/metrics"
+)
+
+var (
+	// status command flags
+	statusWindow string
+	statusTop    int
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a summary of a running instance",
+	Long: `Fetch /health and /api/metrics from a running instance (see --addr) and print
+a compact summary: scheduler state, outgoing and incoming traffic, errors and
+the busiest endpoints.`,
+	Args: cobra.NoArgs,
+	Run:  runStatus,
+}
+
+func init() {
+	addRemoteFlags(statusCmd)
+	statusCmd.Flags().StringVar(&statusWindow, "window", "", "Limit outgoing metrics to the last 1m, 5m or 15m (default: since start)")
+	statusCmd.Flags().IntVar(&statusTop, "top", 10, "Number of endpoints to list, by requests (0 lists none)")
+
+	rootCmd.AddCommand(statusCmd)
+}
+
+// remoteHealth is the part of /health shown by status
+type remoteHealth struct {
+	Status           string  `json:"status"`
+	Goroutines       int     `json:"goroutines"`
+	MemoryAllocMB    float64 `json:"memory_alloc_mb"`
+	RequestsInFlight int64   `json:"requests_in_flight"`
+	SchedulerRunning bool    `json:"scheduler_running"`
+	SchedulerPaused  bool    `json:"scheduler_paused"`
+	EndpointCount    int     `json:"endpoint_count"`
+	EnabledEndpoints int     `json:"enabled_endpoints"`
+
+	IncomingRoutesEnabled  bool    `json:"incoming_routes_enabled"`
+	IncomingRoutesCount    int     `json:"incoming_routes_count"`
+	IncomingRoutesActive   int     `json:"incoming_routes_active"`
+	IncomingTotalRequests  int64   `json:"incoming_total_requests"`
+	IncomingRequestsPerSec float64 `json:"incoming_requests_per_sec"`
+}
+
+// remoteMetrics is the part of /api/metrics shown by status
+type remoteMetrics struct {
+	Outgoing struct {
+		ErrorSummary map[string]int64 `json:"error_summary"`
+	} `json:"outgoing"`
+	OutgoingSnapshot *metrics.MetricsSnapshot `json:"outgoing_snapshot"`
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	var health remoteHealth
+	if err := callAPI(http.MethodGet, "/health", nil, &health); err != nil {
+		exitf("Failed to get health: %v", err)
+	}
+
+	path := "/api/metrics"
+	if statusWindow != "" {
+		path += "?window=" + url.QueryEscape(statusWindow)
+	}
+	var m remoteMetrics
+	if err := callAPI(http.MethodGet, path, nil, &m); err != nil {
+		exitf("Failed to get metrics: %v", err)
+	}
+	if m.OutgoingSnapshot == nil {
+		exitf("Invalid metrics response from %s", remoteAddr)
+	}
+
+	printStatus(&health, &m)
+}
+
+func printStatus(health *remoteHealth, m *remoteMetrics) {
+	snap := m.OutgoingSnapshot
+	uptime := time.Duration(snap.UptimeSeconds) * time.Second
+
+	scheduler := "stopped"
+	switch {
+	case health.SchedulerRunning && health.SchedulerPaused:
+		scheduler = "paused"
+	case health.SchedulerRunning:
+		scheduler = "running"
+	}
+
+	scope := "since start"
+	if snap.Window != "" {
+		scope = "last " + snap.Window
+	}
+
+	fmt.Printf("MoxApp at %s\n", remoteAddr)
+	fmt.Println("=============================================================")
+	fmt.Printf("  Status:                     %s, up %s\n", health.Status, uptime)
+	fmt.Printf("  Scheduler:                  %s, %d requests in flight\n", scheduler, health.RequestsInFlight)
+	fmt.Printf("  Endpoints:                  %d (%d enabled)\n", health.EndpointCount, health.EnabledEndpoints)
+	fmt.Printf("  Outgoing:                   %d requests, %.2f req/s, %.2f%% success (%s)\n",
+		snap.TotalRequests, snap.RequestsPerSecond, snap.SuccessRate, scope)
+	fmt.Printf("  Errors:                     timeout %d, dns %d, connection %d, http %d\n",
+		m.Outgoing.ErrorSummary["timeout"], m.Outgoing.ErrorSummary["dns"],
+		m.Outgoing.ErrorSummary["connection"], m.Outgoing.ErrorSummary["http"])
+	if health.IncomingRoutesEnabled {
+		fmt.Printf("  Incoming:                   %d requests, %.2f req/s, %d/%d routes active\n",
+			health.IncomingTotalRequests, health.IncomingRequestsPerSec, health.IncomingRoutesActive, health.IncomingRoutesCount)
+	} else {
+		fmt.Println("  Incoming:                   disabled")
+	}
+	fmt.Printf("  Process:                    %.1f MB allocated, %d goroutines\n", health.MemoryAllocMB, health.Goroutines)
+	fmt.Println("=============================================================")
+
+	if statusTop <= 0 || len(snap.Endpoints) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(snap.Endpoints))
+	for name := range snap.Endpoints {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := snap.Endpoints[names[i]], snap.Endpoints[names[j]]
+		if a.TotalRequests != b.TotalRequests {
+			return a.TotalRequests > b.TotalRequests
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > statusTop {
+		names = names[:statusTop]
+	}
+
+	fmt.Println()
+	fmt.Printf("  %-30s %9s %9s %10s %10s %10s\n", "ENDPOINT", "REQUESTS", "SUCCESS", "AVG", "P95", "DNS")
+	for _, name := range names {
+		ep := snap.Endpoints[name]
+		fmt.Printf("  %-30s %9d %8.2f%% %8.1fms %8.1fms %8.1fms\n",
+			name, ep.TotalRequests, ep.SuccessRate, ep.AvgTotalTimeMs, ep.P95TotalTimeMs, ep.AvgDNSTimeMs)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package main
+
+import "os"
+
+// terminalSize returns the default 80x24; the terminal size is only
+// queried on Linux and macOS
+func terminalSize(f *os.File) (int, int) {
+	return 80, 24
+}
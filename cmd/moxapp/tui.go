@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"moxapp/internal/metrics"
+	"moxapp/internal/scheduler"
+)
+
+const (
+	// dashboardRefresh is how often the --tui dashboard is redrawn
+	dashboardRefresh = time.Second
+	// dashboardWindow is the metrics window of the per-endpoint table
+	dashboardWindow = "1m"
+	// dashboardLogLines is how many lines of output the dashboard keeps
+	dashboardLogLines = 200
+)
+
+// dashboard is the full-screen --tui view. While it runs, stdout, stderr
+// and the log package are redirected into it and shown below the endpoint
+// table; the kept lines are printed again when it stops.
+type dashboard struct {
+	collector *metrics.Collector
+	sched     *scheduler.Scheduler
+	startTime time.Time
+
+	term   *os.File // The terminal, i.e. the original stdout
+	stderr *os.File
+	pipe   *os.File
+
+	mu   sync.Mutex
+	logs []string
+
+	stop       chan struct{}
+	done       chan struct{}
+	readerDone chan struct{}
+}
+
+func newDashboard(collector *metrics.Collector, sched *scheduler.Scheduler) *dashboard {
+	return &dashboard{
+		collector:  collector,
+		sched:      sched,
+		startTime:  time.Now(),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		readerDone: make(chan struct{}),
+	}
+}
+
+// Start switches the terminal to the dashboard
+func (d *dashboard) Start() error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	d.term, d.stderr, d.pipe = os.Stdout, os.Stderr, w
+	os.Stdout, os.Stderr = w, w
+	log.SetOutput(w)
+	go d.readOutput(r)
+
+	// Alternate screen buffer, hidden cursor
+	fmt.Fprint(d.term, "\x1b[?1049h\x1b[?25l")
+	go d.loop()
+	return nil
+}
+
+// Stop restores the terminal and prints the output captured meanwhile
+func (d *dashboard) Stop() {
+	close(d.stop)
+	<-d.done
+	fmt.Fprint(d.term, "\x1b[?25h\x1b[?1049l")
+
+	os.Stdout, os.Stderr = d.term, d.stderr
+	log.SetOutput(d.stderr)
+	d.pipe.Close()
+	<-d.readerDone
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, line := range d.logs {
+		fmt.Fprintln(d.term, line)
+	}
+}
+
+func (d *dashboard) loop() {
+	defer close(d.done)
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
+
+	d.render()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.render()
+		}
+	}
+}
+
+// readOutput keeps the last dashboardLogLines lines written to the pipe
+func (d *dashboard) readOutput(r *os.File) {
+	defer close(d.readerDone)
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// logResult and the live line start with \r
+		line := strings.TrimLeft(scanner.Text(), "\r")
+		d.mu.Lock()
+		d.logs = append(d.logs, line)
+		if len(d.logs) > dashboardLogLines {
+			d.logs = d.logs[len(d.logs)-dashboardLogLines:]
+		}
+		d.mu.Unlock()
+	}
+}
+
+func (d *dashboard) render() {
+	width, height := terminalSize(d.term)
+	snap, err := d.collector.WindowSnapshot(dashboardWindow)
+	if err != nil {
+		return
+	}
+	stats := d.sched.GetStats()
+
+	state := "running"
+	switch {
+	case !d.sched.IsRunning():
+		state = "idle"
+	case stats.Paused:
+		state = "paused"
+	case !stats.GlobalEnabled:
+		state = "disabled"
+	}
+
+	lines := []string{
+		fmt.Sprintf("MoxApp %s | up %s | %s | Ctrl+C to stop",
+			version, time.Since(d.startTime).Round(time.Second), state),
+		fmt.Sprintf("Requests: %d total | %.1f req/s, %.2f%% success (last %s)",
+			d.collector.GetTotalRequests(), snap.RequestsPerSecond, snap.SuccessRate, dashboardWindow),
+		fmt.Sprintf("Scheduler: %d in flight, %d waiting, %d workers | %d scheduled, %d skipped, %d capped, %d starved | %d/%d endpoints active",
+			stats.RequestsInFlight, stats.RequestsWaiting, stats.Workers,
+			stats.RequestsScheduled, stats.RequestsSkipped, stats.RequestsCapped, stats.RequestsStarved,
+			stats.ActiveEndpoints, stats.EnabledEndpoints),
+		"",
+	}
+
+	// Busiest endpoints first
+	names := make([]string, 0, len(snap.Endpoints))
+	for name := range snap.Endpoints {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := snap.Endpoints[names[i]], snap.Endpoints[names[j]]
+		if a.TotalRequests != b.TotalRequests {
+			return a.TotalRequests > b.TotalRequests
+		}
+		return names[i] < names[j]
+	})
+
+	d.mu.Lock()
+	logs := d.logs
+	d.mu.Unlock()
+
+	// Leave room for a few log lines below the table
+	logRows := min(len(logs), 5)
+	if logRows > 0 {
+		logRows += 2
+	}
+	rows := height - len(lines) - 1 - logRows
+	elapsed := min(time.Minute.Seconds(), snap.UptimeSeconds)
+
+	lines = append(lines, fmt.Sprintf("%-30s %8s %9s %10s %10s %10s  %s",
+		"ENDPOINT", "RPS", "SUCCESS", "P95", "DNS AVG", "DNS P95", "LAST ERROR"))
+	for i, name := range names {
+		if i == rows-1 && len(names) > rows {
+			lines = append(lines, fmt.Sprintf("... %d more endpoints", len(names)-i))
+			break
+		}
+		if i >= rows {
+			break
+		}
+		ep := snap.Endpoints[name]
+		rps := 0.0
+		if elapsed > 0 {
+			rps = float64(ep.TotalRequests) / elapsed
+		}
+		lines = append(lines, fmt.Sprintf("%-30s %8.2f %8.2f%% %8.1fms %8.1fms %8.1fms  %s",
+			truncate(name, 30), rps, ep.SuccessRate, ep.P95TotalTimeMs, ep.AvgDNSTimeMs, ep.P95DNSTimeMs,
+			truncate(ep.LastError, 60)))
+	}
+
+	if logRows > 0 {
+		lines = append(lines, "", "Output:")
+		rest := max(height-len(lines), 0)
+		lines = append(lines, logs[len(logs)-min(len(logs), rest):]...)
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if len(line) > width {
+			line = line[:width]
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J")
+	fmt.Fprint(d.term, b.String())
+}
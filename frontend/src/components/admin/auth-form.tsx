@@ -12,6 +12,9 @@ import {
 } from '@/components/ui/select';
 import { Separator } from '@/components/ui/separator';
 import type { AuthConfig, AuthConfigRequest, AuthType } from '@/types/api';
+import { useServerValidation } from '@/hooks/use-validation';
+import { authApi } from '@/lib/api';
+import { ServerErrors } from '@/components/admin/server-errors';
 
 interface AuthFormProps {
   initialData?: AuthConfig;
@@ -29,6 +32,19 @@ const AUTH_TYPES: { value: AuthType; label: string; description: string }[] = [
   { value: 'custom_header', label: 'Custom Header', description: 'Custom header with value' },
 ];
 
+// Fields with an input, where server validation errors are shown inline
+const INLINE_FIELDS = ['name', 'env_var', 'header_name', 'query_param', 'username_env', 'password_env'];
+
+// parseJSON returns undefined for empty or invalid JSON
+function parseJSON(text: string) {
+  if (!text) return undefined;
+  try {
+    return JSON.parse(text);
+  } catch {
+    return undefined;
+  }
+}
+
 export function AuthForm({
   initialData,
   onSubmit,
@@ -116,10 +132,7 @@ export function AuthForm({
     return Object.keys(newErrors).length === 0;
   };
 
-  const handleSubmit = (e: React.FormEvent) => {
-    e.preventDefault();
-    if (!validate()) return;
-
+  const buildRequest = (): AuthConfigRequest => {
     const data: AuthConfigRequest = {
       name: formData.name.trim(),
       type: formData.type,
@@ -135,8 +148,8 @@ export function AuthForm({
           method: formData.token_method,
           token_path: formData.token_path,
           expires_path: formData.expires_path || undefined,
-          headers: formData.token_headers ? JSON.parse(formData.token_headers) : undefined,
-          body: formData.token_body ? JSON.parse(formData.token_body) : undefined,
+          headers: parseJSON(formData.token_headers),
+          body: parseJSON(formData.token_body),
         };
         data.refresh_before_expiry = formData.refresh_before_expiry;
       }
@@ -157,7 +170,21 @@ export function AuthForm({
       data.password_env = formData.password_env;
     }
 
-    onSubmit(data);
+    return data;
+  };
+
+  const validation = useServerValidation(authApi.validate, buildRequest(), INLINE_FIELDS);
+  // The auth config being edited exists, so the duplicate name warning doesn't apply
+  const server = initialData
+    ? { ...validation, warnings: validation.warnings.filter(w => !w.includes('already exists')) }
+    : validation;
+  const fieldError = (field: string) => errors[field] ?? server.fields[field];
+
+  const handleSubmit = (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!validate()) return;
+
+    onSubmit(buildRequest());
   };
 
   return (
@@ -173,8 +200,8 @@ export function AuthForm({
             placeholder="my_auth_config"
             disabled={!!initialData}
           />
-          {errors.name && (
-            <p className="text-xs text-destructive">{errors.name}</p>
+          {fieldError('name') && (
+            <p className="text-xs text-destructive">{fieldError('name')}</p>
           )}
         </div>
 
@@ -228,8 +255,8 @@ export function AuthForm({
             <p className="text-xs text-muted-foreground">
               For static tokens, or as fallback when token endpoint fails
             </p>
-            {errors.env_var && (
-              <p className="text-xs text-destructive">{errors.env_var}</p>
+            {fieldError('env_var') && (
+              <p className="text-xs text-destructive">{fieldError('env_var')}</p>
             )}
           </div>
 
@@ -260,8 +287,8 @@ export function AuthForm({
                     placeholder="TOKEN_ENDPOINT_URL"
                     className="font-mono"
                   />
-                  {errors.token_url_env && (
-                    <p className="text-xs text-destructive">{errors.token_url_env}</p>
+                  {fieldError('token_url_env') && (
+                    <p className="text-xs text-destructive">{fieldError('token_url_env')}</p>
                   )}
                 </div>
 
@@ -339,8 +366,8 @@ export function AuthForm({
                   placeholder='{"Content-Type": "application/json"}'
                   className="font-mono text-sm h-20"
                 />
-                {errors.token_headers && (
-                  <p className="text-xs text-destructive">{errors.token_headers}</p>
+                {fieldError('token_headers') && (
+                  <p className="text-xs text-destructive">{fieldError('token_headers')}</p>
                 )}
               </div>
 
@@ -358,8 +385,8 @@ export function AuthForm({
                 <p className="text-xs text-muted-foreground">
                   Use {'{{ env "VAR_NAME" }}'} for environment variables
                 </p>
-                {errors.token_body && (
-                  <p className="text-xs text-destructive">{errors.token_body}</p>
+                {fieldError('token_body') && (
+                  <p className="text-xs text-destructive">{fieldError('token_body')}</p>
                 )}
               </div>
             </div>
@@ -378,8 +405,8 @@ export function AuthForm({
               placeholder="X-API-Key"
               className="font-mono"
             />
-            {errors.header_name && (
-              <p className="text-xs text-destructive">{errors.header_name}</p>
+            {fieldError('header_name') && (
+              <p className="text-xs text-destructive">{fieldError('header_name')}</p>
             )}
           </div>
 
@@ -407,8 +434,8 @@ export function AuthForm({
               placeholder="api_key"
               className="font-mono"
             />
-            {errors.query_param && (
-              <p className="text-xs text-destructive">{errors.query_param}</p>
+            {fieldError('query_param') && (
+              <p className="text-xs text-destructive">{fieldError('query_param')}</p>
             )}
           </div>
 
@@ -436,8 +463,8 @@ export function AuthForm({
               placeholder="BASIC_AUTH_USER"
               className="font-mono"
             />
-            {errors.username_env && (
-              <p className="text-xs text-destructive">{errors.username_env}</p>
+            {fieldError('username_env') && (
+              <p className="text-xs text-destructive">{fieldError('username_env')}</p>
             )}
           </div>
 
@@ -450,13 +477,15 @@ export function AuthForm({
               placeholder="BASIC_AUTH_PASS"
               className="font-mono"
             />
-            {errors.password_env && (
-              <p className="text-xs text-destructive">{errors.password_env}</p>
+            {fieldError('password_env') && (
+              <p className="text-xs text-destructive">{fieldError('password_env')}</p>
             )}
           </div>
         </div>
       )}
 
+      <ServerErrors validation={server} />
+
       {/* Actions */}
       <div className="flex justify-end gap-2 pt-4">
         <Button type="button" variant="outline" onClick={onCancel}>
@@ -14,6 +14,9 @@ import {
 import { Separator } from '@/components/ui/separator';
 import type { OutgoingEndpoint, OutgoingEndpointRequest } from '@/types/api';
 import { useAuthConfigs } from '@/hooks/use-auth-configs';
+import { useServerValidation } from '@/hooks/use-validation';
+import { endpointsApi } from '@/lib/api';
+import { ServerErrors } from '@/components/admin/server-errors';
 
 interface EndpointFormProps {
   initialData?: OutgoingEndpoint;
@@ -24,6 +27,19 @@ interface EndpointFormProps {
 
 const HTTP_METHODS = ['GET', 'POST', 'PUT', 'DELETE', 'PATCH', 'HEAD', 'OPTIONS'];
 
+// Fields with an input, where server validation errors are shown inline
+const INLINE_FIELDS = ['name', 'url_template', 'frequency', 'headers', 'body'];
+
+// parseJSON returns undefined for empty or invalid JSON
+function parseJSON(text: string) {
+  if (!text) return undefined;
+  try {
+    return JSON.parse(text);
+  } catch {
+    return undefined;
+  }
+}
+
 export function EndpointForm({
   initialData,
   onSubmit,
@@ -83,23 +99,30 @@ export function EndpointForm({
     return Object.keys(newErrors).length === 0;
   };
 
+  const buildRequest = (): OutgoingEndpointRequest => ({
+    name: formData.name.trim(),
+    method: formData.method,
+    url_template: formData.url_template.trim(),
+    frequency: formData.frequency,
+    auth: formData.auth === 'none' ? undefined : formData.auth,
+    timeout: formData.timeout,
+    enabled: formData.enabled,
+    headers: parseJSON(formData.headers),
+    body: parseJSON(formData.body),
+  });
+
+  const validation = useServerValidation(endpointsApi.validate, buildRequest(), INLINE_FIELDS);
+  // The endpoint being edited exists, so the duplicate name warning doesn't apply
+  const server = initialData
+    ? { ...validation, warnings: validation.warnings.filter(w => !w.includes('already exists')) }
+    : validation;
+  const fieldError = (field: string) => errors[field] ?? server.fields[field];
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     if (!validate()) return;
 
-    const data: OutgoingEndpointRequest = {
-      name: formData.name.trim(),
-      method: formData.method,
-      url_template: formData.url_template.trim(),
-      frequency: formData.frequency,
-      auth: formData.auth === 'none' ? undefined : formData.auth,
-      timeout: formData.timeout,
-      enabled: formData.enabled,
-      headers: formData.headers ? JSON.parse(formData.headers) : undefined,
-      body: formData.body ? JSON.parse(formData.body) : undefined,
-    };
-
-    onSubmit(data);
+    onSubmit(buildRequest());
   };
 
   const needsBody = ['POST', 'PUT', 'PATCH'].includes(formData.method);
@@ -117,8 +140,8 @@ export function EndpointForm({
             placeholder="my_endpoint"
             disabled={!!initialData}
           />
-          {errors.name && (
-            <p className="text-xs text-destructive">{errors.name}</p>
+          {fieldError('name') && (
+            <p className="text-xs text-destructive">{fieldError('name')}</p>
           )}
         </div>
 
@@ -157,8 +180,8 @@ export function EndpointForm({
         <p className="text-xs text-muted-foreground">
           Supports Go templates: {'{{ .Env.VAR }}'}, {'{{ randomUUID }}'}, etc.
         </p>
-        {errors.url_template && (
-          <p className="text-xs text-destructive">{errors.url_template}</p>
+        {fieldError('url_template') && (
+          <p className="text-xs text-destructive">{fieldError('url_template')}</p>
         )}
       </div>
 
@@ -178,8 +201,8 @@ export function EndpointForm({
               setFormData({ ...formData, frequency: parseFloat(e.target.value) || 0 })
             }
           />
-          {errors.frequency && (
-            <p className="text-xs text-destructive">{errors.frequency}</p>
+          {fieldError('frequency') && (
+            <p className="text-xs text-destructive">{fieldError('frequency')}</p>
           )}
         </div>
 
@@ -229,8 +252,8 @@ export function EndpointForm({
           placeholder='{"X-Custom-Header": "value"}'
           className="font-mono text-sm h-24"
         />
-        {errors.headers && (
-          <p className="text-xs text-destructive">{errors.headers}</p>
+        {fieldError('headers') && (
+          <p className="text-xs text-destructive">{fieldError('headers')}</p>
         )}
       </div>
 
@@ -248,8 +271,8 @@ export function EndpointForm({
           <p className="text-xs text-muted-foreground">
             Supports templates in string values
           </p>
-          {errors.body && (
-            <p className="text-xs text-destructive">{errors.body}</p>
+          {fieldError('body') && (
+            <p className="text-xs text-destructive">{fieldError('body')}</p>
           )}
         </div>
       )}
@@ -272,6 +295,8 @@ export function EndpointForm({
         />
       </div>
 
+      <ServerErrors validation={server} />
+
       {/* Actions */}
       <div className="flex justify-end gap-2 pt-4">
         <Button type="button" variant="outline" onClick={onCancel}>
@@ -14,6 +14,9 @@ import { Separator } from '@/components/ui/separator';
 import { Card, CardContent } from '@/components/ui/card';
 import { Plus, Trash2 } from 'lucide-react';
 import type { IncomingRoute, IncomingRouteRequest, IncomingResponseConfig } from '@/types/api';
+import { useServerValidation } from '@/hooks/use-validation';
+import { routesApi } from '@/lib/api';
+import { ServerErrors } from '@/components/admin/server-errors';
 
 interface RouteFormProps {
   initialData?: IncomingRoute;
@@ -24,6 +27,9 @@ interface RouteFormProps {
 
 const HTTP_METHODS = ['*', 'GET', 'POST', 'PUT', 'DELETE', 'PATCH'];
 
+// Fields with an input, where server validation errors are shown inline
+const INLINE_FIELDS = ['name', 'path', 'responses'];
+
 const DEFAULT_RESPONSE: IncomingResponseConfig = {
   status: 200,
   share: 1,
@@ -75,19 +81,26 @@ export function RouteForm({
     return Object.keys(newErrors).length === 0;
   };
 
+  const buildRequest = (): IncomingRouteRequest => ({
+    name: formData.name.trim(),
+    path: formData.path.trim(),
+    method: formData.method,
+    enabled: formData.enabled,
+    responses: formData.responses,
+  });
+
+  const validation = useServerValidation(routesApi.validate, buildRequest(), INLINE_FIELDS);
+  // The route being edited exists, so the duplicate name warning doesn't apply
+  const server = initialData
+    ? { ...validation, warnings: validation.warnings.filter(w => !w.includes('already exists')) }
+    : validation;
+  const fieldError = (field: string) => errors[field] ?? server.fields[field];
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
     if (!validate()) return;
 
-    const data: IncomingRouteRequest = {
-      name: formData.name.trim(),
-      path: formData.path.trim(),
-      method: formData.method,
-      enabled: formData.enabled,
-      responses: formData.responses,
-    };
-
-    onSubmit(data);
+    onSubmit(buildRequest());
   };
 
   const addResponse = () => {
@@ -141,8 +154,8 @@ export function RouteForm({
             placeholder="my_route"
             disabled={!!initialData}
           />
-          {errors.name && (
-            <p className="text-xs text-destructive">{errors.name}</p>
+          {fieldError('name') && (
+            <p className="text-xs text-destructive">{fieldError('name')}</p>
           )}
         </div>
 
@@ -155,8 +168,8 @@ export function RouteForm({
             placeholder="/api/endpoint"
             className="font-mono"
           />
-          {errors.path && (
-            <p className="text-xs text-destructive">{errors.path}</p>
+          {fieldError('path') && (
+            <p className="text-xs text-destructive">{fieldError('path')}</p>
           )}
         </div>
 
@@ -202,8 +215,8 @@ export function RouteForm({
           </div>
         </div>
 
-        {errors.responses && (
-          <p className="text-xs text-destructive">{errors.responses}</p>
+        {fieldError('responses') && (
+          <p className="text-xs text-destructive">{fieldError('responses')}</p>
         )}
 
         <div className="space-y-3">
@@ -297,6 +310,8 @@ export function RouteForm({
         />
       </div>
 
+      <ServerErrors validation={server} />
+
       {/* Actions */}
       <div className="flex justify-end gap-2 pt-4">
         <Button type="button" variant="outline" onClick={onCancel}>
@@ -0,0 +1,19 @@
+import type { ServerValidation } from '@/hooks/use-validation';
+
+// Lists the server's validation errors that aren't shown next to a field,
+// and its warnings
+export function ServerErrors({ validation }: { validation: ServerValidation }) {
+  if (validation.general.length === 0 && validation.warnings.length === 0) {
+    return null;
+  }
+  return (
+    <div className="space-y-1 rounded-md border border-border p-3 text-xs">
+      {validation.general.map((message) => (
+        <p key={message} className="text-destructive">{message}</p>
+      ))}
+      {validation.warnings.map((message) => (
+        <p key={message} className="text-muted-foreground">{message}</p>
+      ))}
+    </div>
+  );
+}
@@ -0,0 +1,201 @@
+import { useMemo } from 'react';
+import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
+import {
+  AreaChart,
+  Area,
+  LineChart,
+  Line,
+  XAxis,
+  YAxis,
+  CartesianGrid,
+  Tooltip,
+  Legend,
+  ResponsiveContainer,
+} from 'recharts';
+import { Activity, Timer } from 'lucide-react';
+import type { TimeseriesPoint } from '@/types/api';
+
+const tooltipStyle = {
+  contentStyle: {
+    backgroundColor: 'oklch(16% 0 0)',
+    border: '1px solid oklch(25% 0 0)',
+    borderRadius: '6px',
+    fontFamily: 'JetBrains Mono, monospace',
+    fontSize: '12px',
+  },
+  labelStyle: { color: 'oklch(90% 0 0)' },
+};
+
+const axisProps = {
+  stroke: 'oklch(60% 0 0)',
+  fontSize: 10,
+  tickLine: false,
+  axisLine: false,
+};
+
+function formatTime(timestamp: string) {
+  return new Date(timestamp).toLocaleTimeString('en-US', {
+    hour12: false,
+    hour: '2-digit',
+    minute: '2-digit',
+    second: '2-digit',
+  });
+}
+
+// Merges the outgoing and incoming series into chart rows by timestamp
+function useChartRows(outgoing: TimeseriesPoint[], incoming: TimeseriesPoint[]) {
+  return useMemo(() => {
+    const incomingByTime = new Map(incoming.map(p => [p.timestamp, p]));
+    return outgoing.map(p => {
+      const route = incomingByTime.get(p.timestamp);
+      return {
+        time: formatTime(p.timestamp),
+        outgoingRps: p.requests_per_sec,
+        incomingRps: route?.requests_per_sec,
+        avg: p.requests > 0 ? p.avg_total_time_ms : undefined,
+        p95: p.requests > 0 ? p.p95_total_time_ms : undefined,
+        incomingAvg: route && route.requests > 0 ? route.avg_total_time_ms : undefined,
+      };
+    });
+  }, [outgoing, incoming]);
+}
+
+interface LiveChartProps {
+  outgoing: TimeseriesPoint[];
+  incoming: TimeseriesPoint[];
+  stepSeconds: number;
+}
+
+export function ThroughputChart({ outgoing, incoming, stepSeconds }: LiveChartProps) {
+  const data = useChartRows(outgoing, incoming);
+  const showIncoming = incoming.some(p => p.requests > 0);
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="flex items-center gap-2">
+          <Activity className="h-3.5 w-3.5" />
+          Requests per Second
+          <span className="text-xs font-normal text-muted-foreground">({stepSeconds}s steps)</span>
+        </CardTitle>
+      </CardHeader>
+      <CardContent>
+        <div className="h-[200px] min-h-[200px] min-w-0">
+          {data.length > 0 ? (
+            <ResponsiveContainer width="100%" height="100%" minWidth={300} minHeight={200}>
+              <AreaChart data={data}>
+                <defs>
+                  <linearGradient id="liveRpsGradient" x1="0" y1="0" x2="0" y2="1">
+                    <stop offset="5%" stopColor="oklch(70% 0.15 190)" stopOpacity={0.3} />
+                    <stop offset="95%" stopColor="oklch(70% 0.15 190)" stopOpacity={0} />
+                  </linearGradient>
+                </defs>
+                <CartesianGrid strokeDasharray="3 3" stroke="oklch(25% 0 0)" vertical={false} />
+                <XAxis dataKey="time" {...axisProps} interval="preserveStartEnd" minTickGap={50} />
+                <YAxis {...axisProps} width={40} tickFormatter={(value) => Number(value).toFixed(1)} />
+                <Tooltip
+                  {...tooltipStyle}
+                  formatter={(value) => (value != null ? Number(value).toFixed(2) : 'N/A')}
+                />
+                <Legend wrapperStyle={{ fontSize: '11px' }} />
+                <Area
+                  type="monotone"
+                  name="Outgoing"
+                  dataKey="outgoingRps"
+                  stroke="oklch(70% 0.15 190)"
+                  strokeWidth={2}
+                  fill="url(#liveRpsGradient)"
+                  dot={false}
+                  isAnimationActive={false}
+                />
+                {showIncoming && (
+                  <Area
+                    type="monotone"
+                    name="Incoming"
+                    dataKey="incomingRps"
+                    stroke="oklch(70% 0.15 300)"
+                    strokeWidth={2}
+                    fill="none"
+                    dot={false}
+                    isAnimationActive={false}
+                  />
+                )}
+              </AreaChart>
+            </ResponsiveContainer>
+          ) : (
+            <div className="h-full w-full" />
+          )}
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
+
+export function LatencyChart({ outgoing, incoming, stepSeconds }: LiveChartProps) {
+  const data = useChartRows(outgoing, incoming);
+  const showIncoming = incoming.some(p => p.requests > 0);
+
+  return (
+    <Card>
+      <CardHeader>
+        <CardTitle className="flex items-center gap-2">
+          <Timer className="h-3.5 w-3.5" />
+          Latency
+          <span className="text-xs font-normal text-muted-foreground">({stepSeconds}s steps)</span>
+        </CardTitle>
+      </CardHeader>
+      <CardContent>
+        <div className="h-[200px] min-h-[200px] min-w-0">
+          {data.length > 0 ? (
+            <ResponsiveContainer width="100%" height="100%" minWidth={300} minHeight={200}>
+              <LineChart data={data}>
+                <CartesianGrid strokeDasharray="3 3" stroke="oklch(25% 0 0)" vertical={false} />
+                <XAxis dataKey="time" {...axisProps} interval="preserveStartEnd" minTickGap={50} />
+                <YAxis {...axisProps} width={48} tickFormatter={(value) => `${Number(value).toFixed(0)}ms`} />
+                <Tooltip
+                  {...tooltipStyle}
+                  formatter={(value) => (value != null ? `${Number(value).toFixed(1)}ms` : 'N/A')}
+                />
+                <Legend wrapperStyle={{ fontSize: '11px' }} />
+                <Line
+                  type="monotone"
+                  name="Avg"
+                  dataKey="avg"
+                  stroke="oklch(70% 0.15 190)"
+                  strokeWidth={2}
+                  dot={false}
+                  connectNulls
+                  isAnimationActive={false}
+                />
+                <Line
+                  type="monotone"
+                  name="P95"
+                  dataKey="p95"
+                  stroke="oklch(75% 0.18 85)"
+                  strokeWidth={2}
+                  dot={false}
+                  connectNulls
+                  isAnimationActive={false}
+                />
+                {showIncoming && (
+                  <Line
+                    type="monotone"
+                    name="Incoming avg"
+                    dataKey="incomingAvg"
+                    stroke="oklch(70% 0.15 300)"
+                    strokeWidth={2}
+                    dot={false}
+                    connectNulls
+                    isAnimationActive={false}
+                  />
+                )}
+              </LineChart>
+            </ResponsiveContainer>
+          ) : (
+            <div className="h-full w-full" />
+          )}
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
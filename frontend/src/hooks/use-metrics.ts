@@ -1,5 +1,7 @@
+import { useEffect, useState } from 'react';
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
 import { metricsApi, controlApi, settingsApi } from '@/lib/api';
+import type { MetricsStreamEvent, TimeseriesPoint } from '@/types/api';
 
 // Live metrics - polled every 1 second
 export function useLiveMetrics() {
@@ -41,6 +43,77 @@ export function useIncomingMetrics() {
   });
 }
 
+// Live time series streamed by the server (server-sent events)
+export interface MetricsStreamOptions {
+  endpoint?: string;
+  route?: string;
+  range?: string; // e.g. 15m
+  points?: number; // Downsample the range to at most this many points
+}
+
+export interface MetricsStream {
+  outgoing: TimeseriesPoint[];
+  incoming: TimeseriesPoint[];
+  stepSeconds: number;
+  connected: boolean;
+  error?: string;
+}
+
+// mergePoints replaces points by timestamp and drops the oldest beyond limit
+function mergePoints(current: TimeseriesPoint[], update: TimeseriesPoint[] | undefined, limit: number) {
+  if (!update || update.length === 0) return current;
+  const first = update[0].timestamp;
+  const kept = current.filter(p => p.timestamp < first);
+  return [...kept, ...update].slice(-limit);
+}
+
+export function useMetricsStream({ endpoint, route, range = '15m', points = 90 }: MetricsStreamOptions = {}) {
+  const [stream, setStream] = useState<MetricsStream>({
+    outgoing: [],
+    incoming: [],
+    stepSeconds: 10,
+    connected: false,
+  });
+
+  useEffect(() => {
+    const source = new EventSource(metricsApi.streamUrl({ endpoint, route, range, points }));
+    let limit = points;
+
+    source.addEventListener('history', (e) => {
+      const data: MetricsStreamEvent = JSON.parse((e as MessageEvent).data);
+      limit = Math.max(points, data.outgoing.length);
+      setStream({
+        outgoing: data.outgoing,
+        incoming: data.incoming ?? [],
+        stepSeconds: data.step_seconds,
+        connected: true,
+      });
+    });
+    source.addEventListener('update', (e) => {
+      const data: MetricsStreamEvent = JSON.parse((e as MessageEvent).data);
+      setStream(prev => ({
+        outgoing: mergePoints(prev.outgoing, data.outgoing, limit),
+        incoming: mergePoints(prev.incoming, data.incoming, limit),
+        stepSeconds: data.step_seconds,
+        connected: true,
+      }));
+    });
+    // Sent by the server while the selected endpoint or route has no metrics
+    source.addEventListener('error', (e) => {
+      const message = (e as MessageEvent).data;
+      setStream(prev => ({
+        ...prev,
+        connected: source.readyState === EventSource.OPEN,
+        error: message ? JSON.parse(message).error : 'Connection lost, reconnecting',
+      }));
+    });
+
+    return () => source.close();
+  }, [endpoint, route, range, points]);
+
+  return stream;
+}
+
 // Reset mutations
 export function useResetMetrics() {
   const queryClient = useQueryClient();
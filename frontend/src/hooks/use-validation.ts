@@ -0,0 +1,66 @@
+import { useEffect, useState } from 'react';
+import type { FieldError, ValidationResult } from '@/types/api';
+
+const VALIDATE_DELAY_MS = 400;
+
+export interface ServerValidation {
+  // Messages by field path (e.g. url_template, stages[0].duration)
+  fields: Record<string, string>;
+  // Errors the server couldn't attribute to a field
+  general: string[];
+  warnings: string[];
+}
+
+const EMPTY: ServerValidation = { fields: {}, general: [], warnings: [] };
+
+// Groups field errors by path; errors for fields not shown inline are
+// returned as general errors
+function groupFieldErrors(errors: FieldError[], inline: string[]): Omit<ServerValidation, 'warnings'> {
+  const fields: Record<string, string> = {};
+  const general: string[] = [];
+  for (const error of errors) {
+    if (error.field && inline.includes(error.field) && !fields[error.field]) {
+      fields[error.field] = error.message;
+    } else {
+      general.push(error.message);
+    }
+  }
+  return { fields, general };
+}
+
+// Validates a request body against the server while the user edits it,
+// debounced. Nothing is reported until the body first changes, so new forms
+// don't open full of errors.
+export function useServerValidation<T>(
+  validate: (body: T) => Promise<ValidationResult>,
+  body: T,
+  inline: string[],
+): ServerValidation {
+  const [result, setResult] = useState<ServerValidation>(EMPTY);
+  const key = JSON.stringify(body);
+  const [initialKey] = useState(key);
+  const edited = key !== initialKey;
+
+  useEffect(() => {
+    if (!edited) return;
+    let canceled = false;
+    const timer = setTimeout(() => {
+      validate(JSON.parse(key))
+        .then((res) => {
+          if (!canceled) {
+            setResult({ ...groupFieldErrors(res.field_errors, inline), warnings: res.warnings });
+          }
+        })
+        // Offline or an older server: keep the client-side checks only
+        .catch(() => !canceled && setResult(EMPTY));
+    }, VALIDATE_DELAY_MS);
+    return () => {
+      canceled = true;
+      clearTimeout(timer);
+    };
+    // inline is a constant list per form
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [key, edited, validate]);
+
+  return edited ? result : EMPTY;
+}
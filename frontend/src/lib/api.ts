@@ -9,22 +9,29 @@ import type {
   MetricsResponse,
   MetricsSnapshot,
   IncomingMetricsSnapshot,
+  TimeseriesResponse,
   ControlStatus,
   ControlRequest,
   EndpointControlRequest,
   OutgoingSettings,
   ApiResponse,
+  FieldError,
+  ValidationResult,
 } from '@/types/api';
 
 const BASE_URL = '';
 
 class ApiError extends Error {
   status: number;
+  code: string;
+  fieldErrors: FieldError[];
   
-  constructor(status: number, message: string) {
+  constructor(status: number, message: string, code = '', fieldErrors: FieldError[] = []) {
     super(message);
     this.name = 'ApiError';
     this.status = status;
+    this.code = code;
+    this.fieldErrors = fieldErrors;
   }
 }
 
@@ -44,13 +51,28 @@ async function request<T>(
   if (!response.ok) {
     const text = await response.text();
     let message = `HTTP ${response.status}`;
+    let code = '';
+    let fieldErrors: FieldError[] = [];
+    let confirmToken = '';
+    let confirmReason = '';
     try {
       const json = JSON.parse(text);
-      message = json.error || json.message || message;
+      message = json.error?.message || json.message || message;
+      code = json.error?.code || '';
+      fieldErrors = json.error?.details?.field_errors || [];
+      confirmToken = json.error?.details?.confirm_token || '';
+      confirmReason = json.error?.details?.reason || message;
     } catch {
       message = text || message;
     }
-    throw new ApiError(response.status, message);
+    // Guarded actions are repeated with the token once the user confirms
+    if (code === 'CONFIRMATION_REQUIRED' && confirmToken && window.confirm(`${confirmReason}.\n\nContinue?`)) {
+      return request<T>(path, {
+        ...options,
+        headers: { ...options.headers, 'X-Confirm-Token': confirmToken },
+      });
+    }
+    throw new ApiError(response.status, message, code, fieldErrors);
   }
 
   // Handle empty responses
@@ -75,18 +97,32 @@ async function requestText(
   if (!response.ok) {
     const text = await response.text();
     let message = `HTTP ${response.status}`;
+    let code = '';
     try {
       const json = JSON.parse(text);
-      message = json.error || json.message || message;
+      message = json.error?.message || json.message || message;
+      code = json.error?.code || '';
     } catch {
       message = text || message;
     }
-    throw new ApiError(response.status, message);
+    throw new ApiError(response.status, message, code);
   }
 
   return response.text();
 }
 
+// queryString builds a query string from the set parameters
+function queryString(params: Record<string, string | number | undefined>): string {
+  const query = new URLSearchParams();
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== undefined && value !== '') {
+      query.set(key, String(value));
+    }
+  }
+  const text = query.toString();
+  return text ? `?${text}` : '';
+}
+
 // ============================================================================
 // Metrics API
 // ============================================================================
@@ -98,6 +134,12 @@ export const metricsApi = {
   resetAll: () => request<void>('/api/metrics/reset', { method: 'POST' }),
   resetOutgoing: () => request<void>('/api/metrics/outgoing/reset', { method: 'POST' }),
   resetIncoming: () => request<void>('/api/metrics/incoming/reset', { method: 'POST' }),
+  getTimeseries: (params: { endpoint?: string; from?: string; step?: string; points?: number } = {}) =>
+    request<TimeseriesResponse>(`/api/metrics/timeseries${queryString(params)}`),
+  getIncomingTimeseries: (params: { route?: string; from?: string; step?: string; points?: number } = {}) =>
+    request<TimeseriesResponse>(`/api/metrics/incoming/timeseries${queryString(params)}`),
+  streamUrl: (params: { endpoint?: string; route?: string; range?: string; points?: number; interval?: string } = {}) =>
+    `${BASE_URL}/api/metrics/stream${queryString(params)}`,
 };
 
 // ============================================================================
@@ -189,6 +231,11 @@ export const endpointsApi = {
       method: 'DELETE',
       body: JSON.stringify({ names }),
     }),
+  validate: (endpoint: OutgoingEndpointRequest) =>
+    request<ValidationResult>('/api/outgoing/endpoints/validate', {
+      method: 'POST',
+      body: JSON.stringify(endpoint),
+    }),
 };
 
 // ============================================================================
@@ -214,6 +261,11 @@ export const routesApi = {
       method: 'DELETE',
     }),
   reload: () => request<void>('/api/incoming/routes/reload', { method: 'POST' }),
+  validate: (route: IncomingRouteRequest) =>
+    request<ValidationResult>('/api/incoming/routes/validate', {
+      method: 'POST',
+      body: JSON.stringify(route),
+    }),
   getControl: () => request<{ enabled: boolean; enabled_routes: number; total_routes: number }>('/api/incoming/control')
     .then(res => ({
       enabled: res.enabled,
@@ -254,6 +306,11 @@ export const authApi = {
     request<void>(`/api/outgoing/auth-configs/${encodeURIComponent(name)}`, {
       method: 'DELETE',
     }),
+  validate: (config: AuthConfigRequest) =>
+    request<ValidationResult>('/api/outgoing/auth-configs/validate', {
+      method: 'POST',
+      body: JSON.stringify(config),
+    }),
   getTokenStatus: (name: string) =>
     request<TokenStatus>(`/api/outgoing/auth-configs/${encodeURIComponent(name)}/status`),
   refreshToken: (name: string) =>
@@ -273,6 +330,8 @@ export const authApi = {
 
 export const configApi = {
   exportYaml: () => requestText('/api/config/export'),
+  getSchema: (kind: 'endpoint' | 'auth_config' | 'incoming_route') =>
+    request<Record<string, unknown>>(`/api/config/schema/${kind}`),
   importYaml: (yamlText: string) =>
     request<ApiResponse<unknown>>('/api/config/import', {
       method: 'POST',
@@ -1,9 +1,10 @@
-import { useLiveMetrics, useOutgoingMetrics, useIncomingMetrics } from '@/hooks/use-metrics';
+import { useLiveMetrics, useOutgoingMetrics, useIncomingMetrics, useMetricsStream } from '@/hooks/use-metrics';
 import { MetricsCards } from '@/components/dashboard/metrics-cards';
 import { SettingsPanel } from '@/components/dashboard/settings-panel';
 import { EndpointsSummary } from '@/components/dashboard/endpoints-summary';
 import { RoutesSummary } from '@/components/dashboard/routes-summary';
-import { RpsChart, SuccessRateChart } from '@/components/dashboard/metrics-charts';
+import { SuccessRateChart } from '@/components/dashboard/metrics-charts';
+import { ThroughputChart, LatencyChart } from '@/components/dashboard/live-charts';
 import { DnsStats } from '@/components/dashboard/dns-stats';
 import { Skeleton } from '@/components/ui/skeleton';
 import { Card, CardContent, CardHeader } from '@/components/ui/card';
@@ -12,6 +13,7 @@ export function DashboardPage() {
   const { data: liveMetrics, isLoading: liveLoading } = useLiveMetrics();
   const { data: outgoingMetrics, isLoading: outgoingLoading } = useOutgoingMetrics();
   const { data: incomingMetrics, isLoading: incomingLoading } = useIncomingMetrics();
+  const series = useMetricsStream();
 
   if (liveLoading || !liveMetrics) {
     return <DashboardSkeleton />;
@@ -30,11 +32,17 @@ export function DashboardPage() {
       {/* Metrics Overview Cards */}
       <MetricsCards metrics={liveMetrics} />
 
-      {/* Charts Row */}
-      <div className="grid gap-4 lg:grid-cols-2">
-        <RpsChart
-          rps={liveMetrics.outgoing.requests_per_sec}
-          successRate={liveMetrics.outgoing.success_rate}
+      {/* Charts Row - server-side time series, streamed */}
+      <div className="grid gap-4 lg:grid-cols-3">
+        <ThroughputChart
+          outgoing={series.outgoing}
+          incoming={series.incoming}
+          stepSeconds={series.stepSeconds}
+        />
+        <LatencyChart
+          outgoing={series.outgoing}
+          incoming={series.incoming}
+          stepSeconds={series.stepSeconds}
         />
         <SuccessRateChart successRate={liveMetrics.outgoing.success_rate} />
       </div>
@@ -91,8 +99,8 @@ function DashboardSkeleton() {
       </div>
 
       {/* Charts skeleton */}
-      <div className="grid gap-4 lg:grid-cols-2">
-        {Array.from({ length: 2 }).map((_, i) => (
+      <div className="grid gap-4 lg:grid-cols-3">
+        {Array.from({ length: 3 }).map((_, i) => (
           <Card key={i}>
             <CardHeader>
               <Skeleton className="h-4 w-32" />
@@ -241,6 +241,42 @@ export interface IncomingRouteSnapshot {
 }
 
 
+export interface TimeseriesPoint {
+  timestamp: string;
+  requests: number;
+  successful: number;
+  failed: number;
+  error_rate: number;
+  timeout_errors: number;
+  dns_errors: number;
+  connection_errors: number;
+  http_errors: number;
+  other_errors: number;
+  requests_per_sec: number;
+  avg_total_time_ms: number;
+  avg_dns_time_ms: number;
+  p95_total_time_ms: number;
+  max_total_time_ms: number;
+}
+
+export interface TimeseriesResponse {
+  endpoint?: string;
+  route?: string;
+  interval_seconds: number;
+  count: number;
+  points: TimeseriesPoint[];
+}
+
+// Data of the history and update events of /api/metrics/stream
+export interface MetricsStreamEvent {
+  endpoint: string;
+  route?: string;
+  step_seconds: number;
+  timestamp: string;
+  outgoing: TimeseriesPoint[];
+  incoming?: TimeseriesPoint[];
+}
+
 // ============================================================================
 // Settings Types
 // ============================================================================
@@ -265,6 +301,19 @@ export interface ApiResponse<T> {
   message?: string;
 }
 
+// A validation error attributed to the JSON path of the field it is about
+export interface FieldError {
+  field?: string;
+  message: string;
+}
+
+export interface ValidationResult {
+  valid: boolean;
+  errors: string[];
+  field_errors: FieldError[];
+  warnings: string[];
+}
+
 export interface HealthResponse {
   status: string;
   uptime_seconds: number;
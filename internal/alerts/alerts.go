@@ -0,0 +1,292 @@
+// Package alerts periodically evaluates alert rules against the outgoing
+// metrics and notifies webhooks when a rule starts firing and when it resolves
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+	"moxapp/internal/webhook"
+)
+
+// Alert states
+const (
+	StateOK     = "ok"
+	StateFiring = "firing"
+)
+
+// Status is the state of one alert rule
+type Status struct {
+	Rule            string  `json:"rule"`
+	Metric          string  `json:"metric"`
+	Operator        string  `json:"operator"`
+	Threshold       float64 `json:"threshold"`
+	Window          string  `json:"window"`
+	State           string  `json:"state"`
+	Value           float64 `json:"value"`    // Metric value at the last evaluation
+	Requests        int64   `json:"requests"` // Requests within the window at the last evaluation
+	Since           string  `json:"since,omitempty"`
+	LastEvaluatedAt string  `json:"last_evaluated_at,omitempty"`
+	LastNotifiedAt  string  `json:"last_notified_at,omitempty"`
+	LastError       string  `json:"last_error,omitempty"` // Last failed notification
+}
+
+// Evaluator evaluates the configured alert rules on a fixed interval
+type Evaluator struct {
+	configManager *config.Manager
+	collector     *metrics.Collector
+	httpClient    *http.Client
+
+	states   map[string]*Status // By rule name
+	listener Listener
+	running  bool
+	mu       sync.Mutex
+}
+
+// Listener is called when a rule starts firing or resolves, with a message
+// describing it
+type Listener func(status Status, message string)
+
+// New creates a new alert evaluator
+func New(configManager *config.Manager, collector *metrics.Collector) *Evaluator {
+	return &Evaluator{
+		configManager: configManager,
+		collector:     collector,
+		httpClient:    webhook.NewClient(),
+		states:        make(map[string]*Status),
+	}
+}
+
+// SetListener sets the function notified when a rule's state changes, in
+// addition to the rule's own webhooks
+func (e *Evaluator) SetListener(listener Listener) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listener = listener
+}
+
+// Start starts a goroutine that evaluates the rules until ctx is cancelled.
+// The alerts configuration is re-read on every cycle so that changes apply live.
+func (e *Evaluator) Start(ctx context.Context) {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return
+	}
+	e.running = true
+	e.mu.Unlock()
+
+	go func() {
+		defer func() {
+			e.mu.Lock()
+			e.running = false
+			e.mu.Unlock()
+		}()
+
+		for {
+			alertsCfg := e.configManager.GetAlertsConfig()
+			e.EvaluateOnce(ctx)
+
+			timer := time.NewTimer(time.Duration(alertsCfg.IntervalSeconds) * time.Second)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// EvaluateOnce evaluates every rule once, notifying the rules whose state changed
+func (e *Evaluator) EvaluateOnce(ctx context.Context) {
+	alertsCfg := e.configManager.GetAlertsConfig()
+	endpoints := e.configManager.GetConfig().Endpoints
+	now := time.Now()
+
+	snapshots := make(map[string]*metrics.MetricsSnapshot)
+	current := make(map[string]bool, len(alertsCfg.Rules))
+	for i := range alertsCfg.Rules {
+		rule := &alertsCfg.Rules[i]
+		current[rule.Name] = true
+
+		window := rule.GetWindow()
+		snapshot, ok := snapshots[window]
+		if !ok {
+			var err error
+			if snapshot, err = e.collector.WindowSnapshot(window); err != nil {
+				log.Printf("alerts: rule %s: %v", rule.Name, err)
+				continue
+			}
+			snapshots[window] = snapshot
+		}
+
+		value, requests := Evaluate(rule, snapshot, endpoints)
+		if changed, status := e.update(rule, value, requests, now); changed {
+			e.notify(ctx, rule, status)
+
+			e.mu.Lock()
+			listener := e.listener
+			e.mu.Unlock()
+			if listener != nil {
+				listener(status, message(rule, &status))
+			}
+		}
+	}
+
+	// Forget rules removed from the config
+	e.mu.Lock()
+	for name := range e.states {
+		if !current[name] {
+			delete(e.states, name)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// update records a rule's evaluation, returning whether its state changed and
+// a copy of its status
+func (e *Evaluator) update(rule *config.AlertRule, value float64, requests int64, now time.Time) (bool, Status) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status, ok := e.states[rule.Name]
+	if !ok {
+		status = &Status{State: StateOK}
+		e.states[rule.Name] = status
+	}
+	status.Rule = rule.Name
+	status.Metric = rule.Metric
+	status.Operator = rule.GetOperator()
+	status.Threshold = rule.Threshold
+	status.Window = rule.GetWindow()
+	status.Value = value
+	status.Requests = requests
+	status.LastEvaluatedAt = now.Format(time.RFC3339)
+
+	// Too few requests to tell: keep the current state
+	if requests < rule.MinRequests {
+		return false, *status
+	}
+
+	state := StateOK
+	if rule.Breached(value) {
+		state = StateFiring
+	}
+	if state == status.State {
+		return false, *status
+	}
+	status.State = state
+	status.Since = now.Format(time.RFC3339)
+	log.Printf("alerts: %s", message(rule, status))
+	return true, *status
+}
+
+// GetStatus returns the state of every configured rule, in config order
+func (e *Evaluator) GetStatus() []Status {
+	rules := e.configManager.GetAlertsConfig().Rules
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make([]Status, 0, len(rules))
+	for _, rule := range rules {
+		status := Status{
+			Rule:      rule.Name,
+			Metric:    rule.Metric,
+			Operator:  rule.GetOperator(),
+			Threshold: rule.Threshold,
+			Window:    rule.GetWindow(),
+			State:     StateOK,
+		}
+		if state, ok := e.states[rule.Name]; ok {
+			status = *state
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Evaluate returns the value of a rule's metric over the endpoints it covers,
+// and the number of requests it is based on
+func Evaluate(rule *config.AlertRule, snapshot *metrics.MetricsSnapshot, endpoints []config.Endpoint) (float64, int64) {
+	tagged := make(map[string]bool)
+	if rule.Tag != "" {
+		for i := range endpoints {
+			if endpoints[i].HasTag(rule.Tag) {
+				tagged[endpoints[i].Name] = true
+			}
+		}
+	}
+
+	var requests, failures, counted int64
+	var totalMs, highest float64
+	for name, ep := range snapshot.Endpoints {
+		if (rule.Endpoint != "" && name != rule.Endpoint) || (rule.Tag != "" && !tagged[name]) {
+			continue
+		}
+		requests += ep.TotalRequests
+		failures += ep.Failed
+		totalMs += ep.AvgTotalTimeMs * float64(ep.TotalRequests)
+
+		switch rule.Metric {
+		case "dns_errors":
+			counted += ep.DNSErrors
+		case "timeout_errors":
+			counted += ep.TimeoutErrors
+		case "connection_errors":
+			counted += ep.ConnectionErrors
+		case "http_errors":
+			counted += ep.HTTPErrors
+		case "p95_ms":
+			highest = max(highest, ep.P95TotalTimeMs)
+		case "p99_ms":
+			highest = max(highest, ep.P99TotalTimeMs)
+		case "dns_p95_ms":
+			highest = max(highest, ep.P95DNSTimeMs)
+		}
+	}
+
+	switch rule.Metric {
+	case "requests":
+		return float64(requests), requests
+	case "failures":
+		return float64(failures), requests
+	case "error_rate":
+		if requests == 0 {
+			return 0, 0
+		}
+		return float64(failures) / float64(requests) * 100, requests
+	case "avg_ms":
+		if requests == 0 {
+			return 0, 0
+		}
+		return totalMs / float64(requests), requests
+	case "p95_ms", "p99_ms", "dns_p95_ms":
+		return highest, requests
+	}
+	return float64(counted), requests
+}
+
+// message describes a rule's state for logs and chat notifications
+func message(rule *config.AlertRule, status *Status) string {
+	scope := "all endpoints"
+	switch {
+	case rule.Endpoint != "":
+		scope = "endpoint " + rule.Endpoint
+	case rule.Tag != "":
+		scope = "tag " + rule.Tag
+	}
+	label := "FIRING"
+	if status.State == StateOK {
+		label = "RESOLVED"
+	}
+	return fmt.Sprintf("[%s] %s: %s is %.4g (%s %.4g) over %s, %s",
+		label, rule.Name, rule.Metric, status.Value, status.Operator, rule.Threshold, status.Window, scope)
+}
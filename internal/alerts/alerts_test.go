@@ -0,0 +1,108 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+)
+
+func TestEvaluator_FiresAndResolves(t *testing.T) {
+	var mu sync.Mutex
+	var received []Notification
+	var slack []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/slack" {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			slack = append(slack, body["text"])
+			return
+		}
+		var n Notification
+		json.NewDecoder(r.Body).Decode(&n)
+		received = append(received, n)
+	}))
+	defer server.Close()
+
+	cm := config.NewManager()
+	if err := cm.ReplaceConfig(&config.Config{
+		Endpoints: []config.Endpoint{{Name: "api", Method: "GET", URLTemplate: "https://api.example.com", Enabled: true}},
+		Alerts: config.AlertsConfig{Rules: []config.AlertRule{{
+			Name: "dns", Metric: "dns_errors", Threshold: 2,
+			WebhookURL: server.URL + "/hook", SlackWebhookURL: server.URL + "/slack",
+		}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	collector := metrics.NewCollector()
+	evaluator := New(cm, collector)
+	record := func(errorType string) {
+		collector.Record(&client.RequestResult{
+			EndpointName: "api", ErrorType: errorType, Error: "lookup failed", Hostname: "api.example.com",
+			RequestTimestamp: time.Now(),
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		record("dns")
+	}
+	evaluator.EvaluateOnce(context.Background())
+	if status := evaluator.GetStatus()[0]; status.State != StateOK || status.Value != 2 {
+		t.Fatalf("expected ok at the threshold, got %+v", status)
+	}
+
+	record("dns")
+	evaluator.EvaluateOnce(context.Background())
+	if status := evaluator.GetStatus()[0]; status.State != StateFiring || status.LastNotifiedAt == "" || status.LastError != "" {
+		t.Fatalf("expected firing, got %+v", status)
+	}
+
+	// Still firing: no new notification
+	evaluator.EvaluateOnce(context.Background())
+
+	collector.Reset()
+	evaluator.EvaluateOnce(context.Background())
+	if status := evaluator.GetStatus()[0]; status.State != StateOK {
+		t.Fatalf("expected resolved, got %+v", status)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 || received[0].State != StateFiring || received[0].Value != 3 || received[1].State != StateOK {
+		t.Errorf("expected firing and resolved notifications, got %+v", received)
+	}
+	if len(slack) != 2 {
+		t.Errorf("expected 2 Slack messages, got %v", slack)
+	}
+}
+
+func TestEvaluate_Filters(t *testing.T) {
+	snapshot := &metrics.MetricsSnapshot{Endpoints: map[string]metrics.EndpointSnapshot{
+		"a": {TotalRequests: 10, Failed: 1, P95TotalTimeMs: 100},
+		"b": {TotalRequests: 30, Failed: 9, P95TotalTimeMs: 300},
+	}}
+	endpoints := []config.Endpoint{{Name: "a", Tags: []string{"team-x"}}, {Name: "b"}}
+
+	if value, requests := Evaluate(&config.AlertRule{Metric: "error_rate"}, snapshot, endpoints); value != 25 || requests != 40 {
+		t.Errorf("error_rate = %v over %d, want 25 over 40", value, requests)
+	}
+	if value, _ := Evaluate(&config.AlertRule{Metric: "p95_ms"}, snapshot, endpoints); value != 300 {
+		t.Errorf("p95_ms = %v, want the highest, 300", value)
+	}
+	if value, _ := Evaluate(&config.AlertRule{Metric: "p95_ms", Tag: "team-x"}, snapshot, endpoints); value != 100 {
+		t.Errorf("p95_ms for tag = %v, want 100", value)
+	}
+	if value, _ := Evaluate(&config.AlertRule{Metric: "failures", Endpoint: "b"}, snapshot, endpoints); value != 9 {
+		t.Errorf("failures for b = %v, want 9", value)
+	}
+}
@@ -0,0 +1,72 @@
+// Package alerts periodically evaluates alert rules against the outgoing
+// metrics and notifies webhooks when a rule starts firing and when it resolves
+package alerts
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/webhook"
+)
+
+// Notification is the JSON body posted to a rule's webhook_url
+type Notification struct {
+	Rule      string  `json:"rule"`
+	State     string  `json:"state"` // firing or ok (resolved)
+	Metric    string  `json:"metric"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	Value     float64 `json:"value"`
+	Requests  int64   `json:"requests"`
+	Window    string  `json:"window"`
+	Endpoint  string  `json:"endpoint,omitempty"`
+	Tag       string  `json:"tag,omitempty"`
+	Message   string  `json:"message"`
+	Time      string  `json:"time"`
+}
+
+// notify posts a rule's new state to its webhooks, recording failures in the
+// rule's status
+func (e *Evaluator) notify(ctx context.Context, rule *config.AlertRule, status Status) {
+	text := message(rule, &status)
+
+	var errs []string
+	if rule.WebhookURL != "" {
+		notification := Notification{
+			Rule:      rule.Name,
+			State:     status.State,
+			Metric:    rule.Metric,
+			Operator:  status.Operator,
+			Threshold: rule.Threshold,
+			Value:     status.Value,
+			Requests:  status.Requests,
+			Window:    status.Window,
+			Endpoint:  rule.Endpoint,
+			Tag:       rule.Tag,
+			Message:   text,
+			Time:      status.Since,
+		}
+		if err := webhook.Post(ctx, e.httpClient, rule.WebhookURL, notification); err != nil {
+			errs = append(errs, "webhook: "+err.Error())
+		}
+	}
+	if rule.SlackWebhookURL != "" {
+		if err := webhook.Post(ctx, e.httpClient, rule.SlackWebhookURL, map[string]string{"text": text}); err != nil {
+			errs = append(errs, "slack: "+err.Error())
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if state, ok := e.states[rule.Name]; ok {
+		state.LastNotifiedAt = time.Now().Format(time.RFC3339)
+		state.LastError = ""
+		if len(errs) > 0 {
+			state.LastError = strings.Join(errs, "; ")
+			log.Printf("alerts: rule %s: notification failed: %v", rule.Name, errs)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// accessLog writes a line per API request, in the configured format
+type accessLog struct {
+	cfg       config.APIAccessLogConfig
+	mu        sync.Mutex // Serializes writes so lines don't interleave
+	out       io.Writer
+	file      *os.File // Closed on shutdown, nil when writing to stderr
+	successes uint64   // Successful requests seen, for sampling
+}
+
+// accessLogEntry is a line of the JSON access log
+type accessLogEntry struct {
+	Time       time.Time `json:"time"`
+	Remote     string    `json:"remote"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Query      string    `json:"query,omitempty"`
+	Proto      string    `json:"proto"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	DurationMs float64   `json:"duration_ms"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+}
+
+// ConfigureAccessLog enables the access log of API requests, appended to the
+// configured file or written to stderr when it is empty or "-"
+func (s *Server) ConfigureAccessLog(cfg config.APIAccessLogConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	al := &accessLog{cfg: cfg, out: os.Stderr}
+	if cfg.File != "" && cfg.File != "-" {
+		if dir := filepath.Dir(cfg.File); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create access log directory: %w", err)
+			}
+		}
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open access log: %w", err)
+		}
+		al.out = file
+		al.file = file
+	}
+	s.accessLog = al
+	return nil
+}
+
+// accessLogMiddleware logs API requests once they are answered. Excluded
+// paths aren't logged, and successful requests are sampled.
+func (s *Server) accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		al := s.accessLog
+		if al == nil || al.cfg.Excluded(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		al.log(r, rec, start)
+	})
+}
+
+// log writes the line of an answered request, unless sampled out
+func (al *accessLog) log(r *http.Request, rec *statusRecorder, start time.Time) {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < http.StatusBadRequest && al.cfg.SampleRate > 1 {
+		if (atomic.AddUint64(&al.successes, 1)-1)%uint64(al.cfg.SampleRate) != 0 {
+			return
+		}
+	}
+
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	var line []byte
+	if al.cfg.Format == config.AccessLogFormatJSON {
+		line, _ = json.Marshal(accessLogEntry{
+			Time:       start,
+			Remote:     remote,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Proto:      r.Proto,
+			Status:     status,
+			Bytes:      rec.bytes,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			Referer:    r.Referer(),
+			UserAgent:  r.UserAgent(),
+		})
+	} else {
+		size := "-"
+		if rec.bytes > 0 {
+			size = fmt.Sprint(rec.bytes)
+		}
+		line = fmt.Appendf(nil, "%s - - [%s] %q %d %s %q %q",
+			remote, start.Format("02/Jan/2006:15:04:05 -0700"),
+			r.Method+" "+r.URL.RequestURI()+" "+r.Proto, status, size,
+			orDash(r.Referer()), orDash(r.UserAgent()))
+	}
+	line = append(line, '\n')
+
+	al.mu.Lock()
+	al.out.Write(line)
+	al.mu.Unlock()
+}
+
+// close closes the access log file
+func (al *accessLog) close() {
+	if al.file == nil {
+		return
+	}
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	al.file.Close()
+}
+
+// orDash returns "-" for empty combined log fields
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// statusRecorder records the status and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the size of the response body
+func (w *statusRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends what was written so far, for event streams
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,31 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"net/http"
+
+	"moxapp/internal/alerts"
+)
+
+// handleGetAlerts returns the state of every alert rule
+// GET /api/alerts
+func (s *Server) handleGetAlerts(w http.ResponseWriter, r *http.Request) {
+	if s.alerts == nil {
+		writeError(w, "alerts not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	statuses := s.alerts.GetStatus()
+	firing := 0
+	for _, status := range statuses {
+		if status.State == alerts.StateFiring {
+			firing++
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"rules":  statuses,
+		"total":  len(statuses),
+		"firing": firing,
+	})
+}
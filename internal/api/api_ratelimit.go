@@ -0,0 +1,102 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// apiLimitPruneInterval is how often the buckets of clients gone quiet are
+// dropped
+const apiLimitPruneInterval = time.Minute
+
+// apiLimiters holds a token bucket per API client
+type apiLimiters struct {
+	buckets *incomingLimiters // keyed by client
+
+	mu     sync.Mutex
+	pruned time.Time
+}
+
+// newAPILimiters creates an empty API client limiter set
+func newAPILimiters() *apiLimiters {
+	return &apiLimiters{buckets: newIncomingLimiters(), pruned: time.Now()}
+}
+
+// allow takes a token from each of the client's buckets, or from none when
+// any is empty, dropping the buckets of clients that went quiet every
+// apiLimitPruneInterval
+func (l *apiLimiters) allow(clients []string, limit config.IncomingRateLimit, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	prune := now.Sub(l.pruned) >= apiLimitPruneInterval
+	if prune {
+		l.pruned = now
+	}
+	l.mu.Unlock()
+	if prune {
+		// A bucket refills in burst/rate seconds
+		refill := time.Duration(float64(limit.BurstSize()) / limit.RequestsPerSecond * float64(time.Second))
+		l.buckets.prune(refill, now)
+	}
+
+	return l.buckets.allowAll(clients, limit, now)
+}
+
+// rateLimitMiddleware applies the per-client API rate limit to /api/ requests,
+// answering 429 with Retry-After once a client runs out of tokens. The limit
+// is read from the config on every request, so changes apply live.
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.configManager == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limits := s.configManager.GetAPIRateLimitConfig()
+		if !limits.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, wait := s.apiLimits.allow(apiClientKeys(r, limits.KeyHeader), limits.Bucket(), time.Now())
+		if allowed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		writeAPIError(w, http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded",
+			map[string]int{"retry_after_seconds": retryAfter})
+	})
+}
+
+// apiClientKeys returns the buckets an API request takes a token from: the
+// hashed value of the key header when configured and sent, and always its
+// remote IP, so sending new key values doesn't get a client fresh buckets
+func apiClientKeys(r *http.Request, keyHeader string) []string {
+	var keys []string
+	if keyHeader != "" {
+		if value := strings.TrimSpace(strings.Split(r.Header.Get(keyHeader), ",")[0]); value != "" {
+			sum := sha256.Sum256([]byte(value))
+			keys = append(keys, "key:"+hex.EncodeToString(sum[:8]))
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return append(keys, "ip:"+host)
+}
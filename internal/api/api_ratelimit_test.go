@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const rateLimitConfig = `api_rate_limit:
+  requests_per_second: 1
+  burst: 2
+  key_header: X-API-Key
+outgoing_endpoints:
+  - name: orders
+    url_template: https://example.com/orders
+    frequency: 1
+`
+
+// rateLimitedRequest sends an API request from remoteAddr with an optional
+// API key and returns the status
+func rateLimitedRequest(s *Server, remoteAddr, key string) int {
+	req := httptest.NewRequest("GET", "/api/metrics/slo", nil)
+	req.RemoteAddr = remoteAddr
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAPIRateLimit(t *testing.T) {
+	s := newTestServer(t, rateLimitConfig)
+
+	// A new key value on every request doesn't get a fresh bucket
+	for i := 0; i < 2; i++ {
+		if code := rateLimitedRequest(s, "10.0.0.1:1000", fmt.Sprintf("key-%d", i)); code != http.StatusOK {
+			t.Fatalf("request %d: expected 200 within the burst, got %d", i, code)
+		}
+	}
+	if code := rateLimitedRequest(s, "10.0.0.1:1000", "key-new"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for a new key from the same IP, got %d", code)
+	}
+
+	// A key over its limit is limited from any IP
+	for i := 0; i < 2; i++ {
+		if code := rateLimitedRequest(s, fmt.Sprintf("10.0.1.%d:1000", i), "shared"); code != http.StatusOK {
+			t.Fatalf("shared key request %d: expected 200, got %d", i, code)
+		}
+	}
+	if code := rateLimitedRequest(s, "10.0.1.9:1000", "shared"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for a key over its limit, got %d", code)
+	}
+
+	// Other clients have their own buckets
+	if code := rateLimitedRequest(s, "10.0.0.2:1000", ""); code != http.StatusOK {
+		t.Errorf("expected 200 for another IP, got %d", code)
+	}
+}
+
+func TestAPIRateLimitDeniedByIPKeepsKeyTokens(t *testing.T) {
+	s := newTestServer(t, rateLimitConfig)
+
+	// The key has one token left and the second IP none
+	if code := rateLimitedRequest(s, "10.0.0.1:1000", "partner"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	for i := 0; i < 2; i++ {
+		if code := rateLimitedRequest(s, "10.0.0.2:1000", ""); code != http.StatusOK {
+			t.Fatalf("request %d: expected 200 within the burst, got %d", i, code)
+		}
+	}
+
+	if code := rateLimitedRequest(s, "10.0.0.2:1000", "partner"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 from the exhausted IP, got %d", code)
+	}
+	if code := rateLimitedRequest(s, "10.0.0.3:1000", "partner"); code != http.StatusOK {
+		t.Errorf("expected the key's last token kept after the IP denied, got %d", code)
+	}
+}
@@ -17,32 +17,21 @@ func (s *Server) handleListAuthConfigs(w http.ResponseWriter, r *http.Request) {
 
 	response := map[string]interface{}{
 		"count":        len(authConfigs),
-		"auth_configs": authConfigs,
+		"auth_configs": s.redactAuthConfigs(authConfigs),
 	}
-	writeJSON(w, response)
+	writeJSONWithETag(w, r, response)
 }
 
 // handleGetAuthConfig returns a single auth config by name
 // GET /api/outgoing/auth-configs/{name}
 func (s *Server) handleGetAuthConfig(w http.ResponseWriter, r *http.Request) {
-	name := strings.TrimPrefix(r.URL.Path, "/api/outgoing/auth-configs/")
-	// Remove any additional path segments (e.g., /token, /status, /refresh)
-	if idx := strings.Index(name, "/"); idx != -1 {
-		name = name[:idx]
-	}
-
-	if name == "" {
-		writeError(w, "auth config name is required", http.StatusBadRequest)
-		return
-	}
-
-	authCfg, err := s.configManager.GetAuthConfig(name)
+	authCfg, err := s.configManager.GetAuthConfig(r.PathValue("name"))
 	if err != nil {
-		writeError(w, err.Error(), http.StatusNotFound)
+		writeConfigError(w, err, nil)
 		return
 	}
 
-	writeJSON(w, authCfg)
+	writeJSON(w, s.redactAuthConfig(authCfg))
 }
 
 // handleCreateAuthConfig creates a new auth config
@@ -50,22 +39,18 @@ func (s *Server) handleGetAuthConfig(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleCreateAuthConfig(w http.ResponseWriter, r *http.Request) {
 	var authCfg config.AuthConfig
 	if err := json.NewDecoder(r.Body).Decode(&authCfg); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
 	// Validate auth config
 	if errs := authCfg.Validate(); len(errs) > 0 {
-		writeError(w, "validation failed: "+strings.Join(errs, "; "), http.StatusBadRequest)
+		writeValidationError(w, "validation failed: "+strings.Join(errs, "; "), &authCfg, errs)
 		return
 	}
 
 	if err := s.configManager.AddAuthConfig(&authCfg); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			writeError(w, err.Error(), http.StatusConflict)
-		} else {
-			writeError(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeConfigError(w, err, &authCfg)
 		return
 	}
 
@@ -73,64 +58,46 @@ func (s *Server) handleCreateAuthConfig(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, map[string]interface{}{
 		"status":      "success",
 		"message":     "Auth config created successfully",
-		"auth_config": authCfg,
+		"auth_config": s.redactAuthConfig(&authCfg),
 	})
 }
 
 // handleUpdateAuthConfig updates an existing auth config
 // PUT /api/outgoing/auth-configs/{name}
 func (s *Server) handleUpdateAuthConfig(w http.ResponseWriter, r *http.Request) {
-	name := strings.TrimPrefix(r.URL.Path, "/api/outgoing/auth-configs/")
-	if name == "" {
-		writeError(w, "auth config name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	var authCfg config.AuthConfig
 	if err := json.NewDecoder(r.Body).Decode(&authCfg); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
 	// Validate auth config
 	if errs := authCfg.Validate(); len(errs) > 0 {
-		writeError(w, "validation failed: "+strings.Join(errs, "; "), http.StatusBadRequest)
+		writeValidationError(w, "validation failed: "+strings.Join(errs, "; "), &authCfg, errs)
 		return
 	}
 
 	if err := s.configManager.UpdateAuthConfig(name, &authCfg); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, err.Error(), http.StatusNotFound)
-		} else if strings.Contains(err.Error(), "already exists") {
-			writeError(w, err.Error(), http.StatusConflict)
-		} else {
-			writeError(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeConfigError(w, err, &authCfg)
 		return
 	}
 
 	writeJSON(w, map[string]interface{}{
 		"status":      "success",
 		"message":     "Auth config updated successfully",
-		"auth_config": authCfg,
+		"auth_config": s.redactAuthConfig(&authCfg),
 	})
 }
 
 // handleDeleteAuthConfig deletes an auth config by name
 // DELETE /api/outgoing/auth-configs/{name}
 func (s *Server) handleDeleteAuthConfig(w http.ResponseWriter, r *http.Request) {
-	name := strings.TrimPrefix(r.URL.Path, "/api/outgoing/auth-configs/")
-	if name == "" {
-		writeError(w, "auth config name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	if err := s.configManager.DeleteAuthConfig(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, err.Error(), http.StatusNotFound)
-		} else {
-			writeError(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeConfigError(w, err, nil)
 		return
 	}
 
@@ -140,19 +107,21 @@ func (s *Server) handleDeleteAuthConfig(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// setTokenRequest sets the token of an auth config by hand
+type setTokenRequest struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"` // seconds
+}
+
 // handleSetAuthToken manually sets a token for an auth config
 // POST /api/outgoing/auth-configs/{name}/token
 func (s *Server) handleSetAuthToken(w http.ResponseWriter, r *http.Request) {
-	name := extractAuthConfigName(r.URL.Path, "/token")
-	if name == "" {
-		writeError(w, "auth config name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	// Check if auth config exists
 	_, err := s.configManager.GetAuthConfig(name)
 	if err != nil {
-		writeError(w, err.Error(), http.StatusNotFound)
+		writeConfigError(w, err, nil)
 		return
 	}
 
@@ -162,12 +131,9 @@ func (s *Server) handleSetAuthToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		Token     string `json:"token"`
-		ExpiresIn int    `json:"expires_in"` // seconds
-	}
+	var req setTokenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
@@ -191,16 +157,12 @@ func (s *Server) handleSetAuthToken(w http.ResponseWriter, r *http.Request) {
 // handleRefreshAuthToken forces a token refresh for an auth config
 // POST /api/outgoing/auth-configs/{name}/refresh
 func (s *Server) handleRefreshAuthToken(w http.ResponseWriter, r *http.Request) {
-	name := extractAuthConfigName(r.URL.Path, "/refresh")
-	if name == "" {
-		writeError(w, "auth config name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	// Check if auth config exists
 	authCfg, err := s.configManager.GetAuthConfig(name)
 	if err != nil {
-		writeError(w, err.Error(), http.StatusNotFound)
+		writeConfigError(w, err, nil)
 		return
 	}
 
@@ -239,16 +201,12 @@ func (s *Server) handleRefreshAuthToken(w http.ResponseWriter, r *http.Request)
 // handleAuthTokenStatus returns the token status for an auth config
 // GET /api/outgoing/auth-configs/{name}/status
 func (s *Server) handleAuthTokenStatus(w http.ResponseWriter, r *http.Request) {
-	name := extractAuthConfigName(r.URL.Path, "/status")
-	if name == "" {
-		writeError(w, "auth config name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	// Check if auth config exists
 	_, err := s.configManager.GetAuthConfig(name)
 	if err != nil {
-		writeError(w, err.Error(), http.StatusNotFound)
+		writeConfigError(w, err, nil)
 		return
 	}
 
@@ -262,80 +220,6 @@ func (s *Server) handleAuthTokenStatus(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, status)
 }
 
-// handleAuthConfigs is a router for auth config CRUD operations
-func (s *Server) handleAuthConfigs(w http.ResponseWriter, r *http.Request) {
-	// Extract path after /api/outgoing/auth-configs
-	path := strings.TrimPrefix(r.URL.Path, "/api/outgoing/auth-configs")
-
-	// Check for token management endpoints
-	if strings.Contains(path, "/token") {
-		if r.Method == http.MethodPost {
-			s.handleSetAuthToken(w, r)
-		} else {
-			writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		}
-		return
-	}
-
-	if strings.Contains(path, "/refresh") {
-		if r.Method == http.MethodPost {
-			s.handleRefreshAuthToken(w, r)
-		} else {
-			writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		}
-		return
-	}
-
-	if strings.Contains(path, "/status") {
-		if r.Method == http.MethodGet {
-			s.handleAuthTokenStatus(w, r)
-		} else {
-			writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		}
-		return
-	}
-
-	// Check if it's a request for a specific auth config
-	hasName := path != "" && path != "/"
-
-	switch r.Method {
-	case http.MethodGet:
-		if hasName {
-			s.handleGetAuthConfig(w, r)
-		} else {
-			s.handleListAuthConfigs(w, r)
-		}
-	case http.MethodPost:
-		if hasName {
-			writeError(w, "POST to specific auth config not allowed, use PUT to update", http.StatusMethodNotAllowed)
-		} else {
-			s.handleCreateAuthConfig(w, r)
-		}
-	case http.MethodPut:
-		if hasName {
-			s.handleUpdateAuthConfig(w, r)
-		} else {
-			writeError(w, "PUT requires auth config name in path", http.StatusBadRequest)
-		}
-	case http.MethodDelete:
-		if hasName {
-			s.handleDeleteAuthConfig(w, r)
-		} else {
-			writeError(w, "DELETE requires auth config name in path", http.StatusBadRequest)
-		}
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-// extractAuthConfigName extracts the auth config name from the URL path
-// removing the specified suffix (e.g., "/token", "/status", "/refresh")
-func extractAuthConfigName(path, suffix string) string {
-	name := strings.TrimPrefix(path, "/api/outgoing/auth-configs/")
-	name = strings.TrimSuffix(name, suffix)
-	return name
-}
-
 // maskToken masks a token for safe display (shows first 4 and last 4 characters)
 func maskToken(token string) string {
 	if len(token) <= 8 {
@@ -0,0 +1,103 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"moxapp/internal/metrics"
+)
+
+// handleGetBaseline returns the baseline snapshot used by compare mode
+// GET /api/metrics/baseline
+func (s *Server) handleGetBaseline(w http.ResponseWriter, r *http.Request) {
+	baseline := s.metrics.Baseline()
+	if baseline == nil {
+		writeError(w, "no baseline loaded", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, baseline)
+}
+
+// handleSetBaseline loads a baseline snapshot from the body, or captures the
+// current metrics with ?from=current
+// POST/PUT /api/metrics/baseline
+func (s *Server) handleSetBaseline(w http.ResponseWriter, r *http.Request) {
+	// ?from=current captures the live metrics as the baseline
+	if r.URL.Query().Get("from") == "current" {
+		baseline := s.metrics.Snapshot()
+		s.metrics.SetBaseline(baseline)
+		writeJSON(w, map[string]interface{}{
+			"status":       "success",
+			"message":      "Baseline captured from current metrics",
+			"collected_at": baseline.CollectedAt,
+			"endpoints":    len(baseline.Endpoints),
+		})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) == 0 {
+		writeError(w, "empty request body", http.StatusBadRequest)
+		return
+	}
+
+	baseline, err := metrics.ParseSnapshot(body)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.metrics.SetBaseline(baseline)
+
+	writeJSON(w, map[string]interface{}{
+		"status":       "success",
+		"message":      "Baseline loaded",
+		"collected_at": baseline.CollectedAt,
+		"endpoints":    len(baseline.Endpoints),
+	})
+}
+
+// handleDeleteBaseline clears the baseline snapshot
+// DELETE /api/metrics/baseline
+func (s *Server) handleDeleteBaseline(w http.ResponseWriter, r *http.Request) {
+	s.metrics.SetBaseline(nil)
+	writeJSON(w, map[string]string{
+		"status":  "success",
+		"message": "Baseline cleared",
+	})
+}
+
+// handleCompare returns per-endpoint deltas between the baseline and current metrics
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	baseline := s.metrics.Baseline()
+	if baseline == nil {
+		writeError(w, "no baseline loaded - POST a snapshot to /api/metrics/baseline first", http.StatusNotFound)
+		return
+	}
+
+	opts := metrics.DefaultCompareOptions()
+	query := r.URL.Query()
+	if v := query.Get("latency_threshold"); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			writeError(w, "invalid latency_threshold: "+v, http.StatusBadRequest)
+			return
+		}
+		opts.LatencyThresholdPct = threshold
+	}
+	if v := query.Get("error_threshold"); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			writeError(w, "invalid error_threshold: "+v, http.StatusBadRequest)
+			return
+		}
+		opts.ErrorRateThreshold = threshold
+	}
+
+	writeJSON(w, metrics.Compare(baseline, s.metrics.Snapshot(), opts))
+}
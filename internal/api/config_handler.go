@@ -2,8 +2,11 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,17 +17,12 @@ import (
 
 // handleExportConfig returns the full in-memory config as YAML
 func (s *Server) handleExportConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	if s.configManager == nil {
 		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
 		return
 	}
 
-	cfg := s.configManager.GetConfig()
+	cfg := s.redactConfig(s.configManager.GetConfig())
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
 		writeError(w, "failed to serialize config", http.StatusInternalServerError)
@@ -39,11 +37,6 @@ func (s *Server) handleExportConfig(w http.ResponseWriter, r *http.Request) {
 
 // handleImportConfig replaces the in-memory config with uploaded YAML
 func (s *Server) handleImportConfig(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	if s.configManager == nil {
 		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
 		return
@@ -65,6 +58,8 @@ func (s *Server) handleImportConfig(w http.ResponseWriter, r *http.Request) {
 		writeError(w, "invalid YAML", http.StatusBadRequest)
 		return
 	}
+	// A redacted export keeps the secrets it was exported with
+	newCfg.RestoreRedacted(s.configManager.GetConfig())
 
 	// Validate before replacing
 	manager := config.NewManager()
@@ -73,18 +68,159 @@ func (s *Server) handleImportConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if errors := manager.Validate(); len(errors) > 0 {
-		writeError(w, "validation failed: "+strings.Join(errors, "; "), http.StatusBadRequest)
+		writeValidationError(w, "validation failed: "+strings.Join(errors, "; "), nil, errors)
+		return
+	}
+	if err := s.configManager.CheckGuardrails(&newCfg); err != nil {
+		writeConfigError(w, err, nil)
 		return
 	}
 
-	if err := s.configManager.ReplaceConfig(&newCfg); err != nil {
-		writeError(w, err.Error(), http.StatusBadRequest)
+	// Dry run: report what the import would change without applying it
+	if r.URL.Query().Get("dry_run") == "true" {
+		writeJSON(w, map[string]interface{}{
+			"status":  "dry_run",
+			"message": "config is valid and was not applied",
+			"diff":    s.configManager.DiffAgainst(&newCfg),
+		})
+		return
+	}
+
+	if !s.confirmEnabling(w, r, s.newlyEnabled(manager.GetEndpoints())) {
+		return
+	}
+
+	if err := s.configManager.ReplaceConfigFrom(&newCfg, config.VersionSourceImport); err != nil {
+		writeConfigError(w, err, nil)
 		return
 	}
+	if s.cookieJars != nil {
+		s.cookieJars.ResetAll()
+	}
 
-	writeJSON(w, map[string]string{
+	writeJSON(w, map[string]interface{}{
 		"status":  "success",
 		"message": "config imported",
+		"version": s.configManager.CurrentVersion(),
+	})
+}
+
+// handleConfigVersions lists the config versions kept for rollback
+func (s *Server) handleConfigVersions(w http.ResponseWriter, r *http.Request) {
+	if s.configManager == nil {
+		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	versions := s.configManager.GetConfigVersions()
+	writeJSON(w, map[string]interface{}{
+		"current":  s.configManager.CurrentVersion(),
+		"count":    len(versions),
+		"max":      config.MaxConfigVersions,
+		"versions": versions,
+	})
+}
+
+// handleConfigRollback restores a kept config version
+func (s *Server) handleConfigRollback(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, "invalid version id", http.StatusBadRequest)
+		return
+	}
+
+	version, err := s.configManager.Rollback(id)
+	if err != nil {
+		writeConfigError(w, err, nil)
+		return
+	}
+	if s.cookieJars != nil {
+		s.cookieJars.ResetAll()
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": fmt.Sprintf("rolled back to version %d", id),
+		"version": version,
+	})
+}
+
+// transactionRequest is a batch of changes applied all or none
+type transactionRequest struct {
+	Operations []config.TxOperation `json:"operations"`
+}
+
+// txBodies are the request bodies operation values are read as, by kind, to
+// attribute validation errors to their fields
+var txBodies = map[string]interface{}{
+	config.SchemaEndpoint:      &config.EndpointRequest{},
+	config.SchemaAuthConfig:    &config.AuthConfig{},
+	config.SchemaIncomingRoute: &config.IncomingEndpointRequest{},
+}
+
+// txOperationError is the error of one operation of a failed transaction
+type txOperationError struct {
+	Index int      `json:"index"`
+	Op    string   `json:"op"`
+	Kind  string   `json:"kind"`
+	Name  string   `json:"name,omitempty"`
+	Error apiError `json:"error"`
+}
+
+// handleConfigTransaction applies add, update and delete operations across
+// endpoints, auth configs and incoming routes atomically, returning the diff
+// they make (?dry_run=true only returns it)
+func (s *Server) handleConfigTransaction(w http.ResponseWriter, r *http.Request) {
+	var req transactionRequest
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if len(req.Operations) == 0 {
+		writeError(w, "operations are required", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	if !dryRun && !s.confirmEnabling(w, r, s.newlyEnabled(s.configManager.TransactionEndpoints(req.Operations))) {
+		return
+	}
+	diff, err := s.configManager.ApplyTransaction(req.Operations, dryRun)
+	if err != nil {
+		var txErr *config.TransactionError
+		if !errors.As(err, &txErr) {
+			writeConfigError(w, err, nil)
+			return
+		}
+		var failed []txOperationError
+		for i, opErr := range txErr.Ops {
+			if opErr == nil {
+				continue
+			}
+			op := req.Operations[i]
+			_, e := configError(opErr, txBodies[op.Kind])
+			failed = append(failed, txOperationError{Index: i, Op: op.Op, Kind: op.Kind, Name: op.Name, Error: e})
+		}
+		writeAPIError(w, http.StatusBadRequest, CodeTransactionFailed, err.Error(), map[string]interface{}{
+			"operations": failed,
+			"problems":   txErr.Problems,
+		})
+		return
+	}
+
+	if dryRun {
+		writeJSON(w, map[string]interface{}{
+			"status":  "dry_run",
+			"message": "transaction is valid and was not applied",
+			"diff":    diff,
+		})
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": fmt.Sprintf("%d operations applied", len(req.Operations)),
+		"version": s.configManager.CurrentVersion(),
+		"diff":    diff,
 	})
 }
 
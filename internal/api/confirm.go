@@ -0,0 +1,97 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// confirmTokenHeader carries the token confirming a guarded action; the
+// confirm_token query parameter works too
+const confirmTokenHeader = "X-Confirm-Token"
+
+// confirmations holds the tokens handed out for guarded actions until they
+// are redeemed or expire
+type confirmations struct {
+	mu     sync.Mutex
+	tokens map[string]pendingConfirmation
+}
+
+// pendingConfirmation is the action a token confirms
+type pendingConfirmation struct {
+	action  string
+	expires time.Time
+}
+
+// newConfirmations creates an empty token store
+func newConfirmations() *confirmations {
+	return &confirmations{tokens: make(map[string]pendingConfirmation)}
+}
+
+// issue returns a new token confirming an action, dropping expired tokens
+func (c *confirmations) issue(action string, ttl time.Duration, now time.Time) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	token := hex.EncodeToString(buf)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for t, pending := range c.tokens {
+		if now.After(pending.expires) {
+			delete(c.tokens, t)
+		}
+	}
+	c.tokens[token] = pendingConfirmation{action: action, expires: now.Add(ttl)}
+	return token
+}
+
+// redeem consumes a token and returns true if it was issued for the action
+// and hasn't expired. A token confirms one request only.
+func (c *confirmations) redeem(token, action string, now time.Time) bool {
+	if token == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.tokens[token]
+	if !ok || pending.action != action {
+		return false
+	}
+	delete(c.tokens, token)
+	return !now.After(pending.expires)
+}
+
+// confirmed returns true if a request for a guarded action carries a valid
+// token for it. Otherwise it answers 428 CONFIRMATION_REQUIRED with a new
+// token, to send back in X-Confirm-Token with the same request, and returns
+// false. The action describes the change, so a token can't confirm another.
+func (s *Server) confirmed(w http.ResponseWriter, r *http.Request, action, reason string) bool {
+	token := r.Header.Get(confirmTokenHeader)
+	if token == "" {
+		token = r.URL.Query().Get("confirm_token")
+	}
+	now := time.Now()
+	if s.confirmations.redeem(token, action, now) {
+		return true
+	}
+
+	message := reason + "; repeat the request with the confirm_token in the " + confirmTokenHeader + " header to confirm"
+	if token != "" {
+		message = "confirmation token is invalid, expired or for another action; " + message
+	}
+	guarded := s.configManager.GetGuardedActionsConfig()
+	ttl := guarded.ConfirmTTL()
+	writeAPIError(w, http.StatusPreconditionRequired, CodeConfirmationRequired, message, map[string]interface{}{
+		"action":             action,
+		"reason":             reason,
+		"confirm_token":      s.confirmations.issue(action, ttl, now),
+		"expires_in_seconds": int(ttl.Seconds()),
+	})
+	return false
+}
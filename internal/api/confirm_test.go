@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+)
+
+// newTestServer creates a server whose config is loaded from yaml
+func newTestServer(t *testing.T, yaml string) *Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manager := config.NewManager()
+	if err := manager.LoadFromFile(path); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return NewServerWithManager(":0", metrics.NewCollector(), manager)
+}
+
+// serve sends a request through the server's full handler chain
+func serve(s *Server, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+const guardedConfig = `guarded_actions:
+  max_enabled_at_once: 1
+outgoing_endpoints:
+  - name: a
+    url_template: https://example.com/a
+    frequency: 1
+    enabled: false
+  - name: b
+    url_template: https://example.com/b
+    frequency: 1
+    enabled: false
+  - name: c
+    url_template: https://example.com/c
+    frequency: 1
+`
+
+func TestConfirmEnabling(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+	}{
+		{"enable all", "POST", "/api/outgoing/control/endpoints/all", `{"enabled": true}`},
+		{"bulk enable", "POST", "/api/outgoing/control/endpoints/bulk", `{"names": ["a", "b"], "enabled": true}`},
+		{"bulk update", "PUT", "/api/outgoing/endpoints/bulk", `[{"name": "a", "enabled": true}, {"name": "b", "enabled": true}]`},
+		{"bulk create", "POST", "/api/outgoing/endpoints/bulk", `[
+			{"name": "d", "method": "GET", "url_template": "https://example.com/d", "frequency": 1, "enabled": true},
+			{"name": "e", "method": "GET", "url_template": "https://example.com/e", "frequency": 1, "enabled": true}]`},
+		{"transaction", "POST", "/api/config/transaction", `{"operations": [
+			{"op": "update", "kind": "endpoint", "name": "a", "value": {"name": "a", "method": "GET", "url_template": "https://example.com/a", "frequency": 1, "enabled": true}},
+			{"op": "add", "kind": "endpoint", "value": {"name": "d", "method": "GET", "url_template": "https://example.com/d", "frequency": 1, "enabled": true}}]}`},
+		{"import", "POST", "/api/config/import", `endpoints:
+  - name: a
+    url_template: https://example.com/a
+    frequency: 1
+  - name: b
+    url_template: https://example.com/b
+    frequency: 1
+  - name: c
+    url_template: https://example.com/c
+    frequency: 1
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, guardedConfig)
+			before := s.configManager.GetEndpoints()
+
+			rec := serve(s, tt.method, tt.target, tt.body)
+			if rec.Code != http.StatusPreconditionRequired {
+				t.Fatalf("expected 428, got %d: %s", rec.Code, rec.Body.String())
+			}
+			var body struct {
+				Error struct {
+					Details struct {
+						ConfirmToken string `json:"confirm_token"`
+					} `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error.Details.ConfirmToken == "" {
+				t.Fatalf("expected a confirm token, got %s", rec.Body.String())
+			}
+			if after := s.configManager.GetEndpoints(); !sameEnabled(before, after) {
+				t.Fatalf("endpoints changed without confirmation: %+v", after)
+			}
+
+			rec = serve(s, tt.method, tt.target, tt.body, confirmTokenHeader, body.Error.Details.ConfirmToken)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200 with the token, got %d: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestConfirmEnablingUnderLimit(t *testing.T) {
+	s := newTestServer(t, guardedConfig)
+
+	// One endpoint, or endpoints already enabled, don't need confirmation
+	for _, body := range []string{
+		`[{"name": "a", "enabled": true}]`,
+		`[{"name": "a", "enabled": true}, {"name": "c", "enabled": true}]`,
+	} {
+		if rec := serve(s, "PUT", "/api/outgoing/endpoints/bulk", body); rec.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d: %s", body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// sameEnabled reports whether two endpoint lists have the same endpoints
+// with the same enabled state
+func sameEnabled(a, b []config.Endpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Enabled != b[i].Enabled {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+// maxTestRequestTimeout caps test requests so the result is written within
+// the API server's write timeout
+const maxTestRequestTimeout = 8 * time.Second
+
+// handleValidateEndpoint checks an endpoint definition without adding it:
+// validation, auth resolution and one evaluation of its templates. With
+// ?test=true a valid endpoint also fires one request.
+// POST /api/outgoing/endpoints/validate
+func (s *Server) handleValidateEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !s.checkConfigManager(w) {
+		return
+	}
+
+	var req config.EndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+
+	errors := []string{}
+	warnings := []string{}
+
+	endpoint, err := s.configManager.PrepareEndpoint(req.ToEndpoint())
+	if err != nil {
+		errors = append(errors, err.Error())
+	}
+	errors = append(errors, endpoint.Validate()...)
+
+	if _, err := s.configManager.GetEndpoint(endpoint.Name); err == nil {
+		warnings = append(warnings, "an endpoint named "+endpoint.Name+" already exists; creating this one would fail")
+	}
+
+	var auth map[string]interface{}
+	if endpoint.ResolvedAuth != nil {
+		auth, warnings = s.authDiagnostics(endpoint.ResolvedAuth, warnings)
+	}
+
+	preview := client.PreviewRequest(&endpoint)
+	errors = append(errors, preview.Errors...)
+	preview.Errors = nil // Reported with the other errors
+
+	response := map[string]interface{}{
+		"valid":        len(errors) == 0,
+		"errors":       errors,
+		"field_errors": config.FieldErrors(&req, errors),
+		"warnings":     warnings,
+		"endpoint":     s.redactEndpoint(endpoint),
+		"auth":         auth,
+		"request":      s.redactPreview(preview),
+	}
+
+	if r.URL.Query().Get("test") == "true" {
+		switch {
+		case len(errors) > 0:
+			response["test_skipped"] = "endpoint is invalid"
+		case s.httpClient == nil:
+			response["test_skipped"] = "HTTP client not available"
+		default:
+			response["test"] = s.redactResult(s.fireTestRequest(r.Context(), &endpoint))
+		}
+	}
+
+	writeJSON(w, response)
+}
+
+// authDiagnostics describes a resolved auth config, warning about credential
+// env vars that aren't set
+func (s *Server) authDiagnostics(auth *config.AuthConfig, warnings []string) (map[string]interface{}, []string) {
+	missing := []string{}
+	for _, env := range auth.CredentialEnvs() {
+		if s.configManager.GetEnv(env) == "" {
+			missing = append(missing, env)
+		}
+	}
+	if len(missing) > 0 {
+		warnings = append(warnings, "auth credentials not set: "+strings.Join(missing, ", "))
+	}
+
+	return map[string]interface{}{
+		"name":           auth.Name,
+		"type":           auth.Type,
+		"token_endpoint": auth.HasTokenEndpoint(),
+		"missing_env":    missing,
+	}, warnings
+}
+
+// fireTestRequest executes one request for an endpoint through the real
+// client. The result is not recorded in metrics.
+func (s *Server) fireTestRequest(ctx context.Context, endpoint *config.Endpoint) *client.RequestResult {
+	timeout := time.Duration(endpoint.Timeout) * time.Second
+	if timeout <= 0 || timeout > maxTestRequestTimeout {
+		timeout = maxTestRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return s.httpClient.Execute(ctx, endpoint)
+}
+
+// redactPreview masks sensitive query parameters, headers and JSON body
+// fields of a request preview
+func (s *Server) redactPreview(preview *client.RequestPreview) *client.RequestPreview {
+	if s.includeSecrets {
+		return preview
+	}
+	redacted := *preview
+	redacted.URL = config.RedactURL(preview.URL)
+	redacted.Headers = config.RedactHeaders(preview.Headers)
+
+	if preview.Body == "" || preview.ContentType != "application/json" {
+		return &redacted
+	}
+	// A truncated JSON body can't be parsed, so it is masked as a whole
+	redacted.Body = config.RedactedValue
+	var body interface{}
+	if json.Unmarshal([]byte(preview.Body), &body) == nil {
+		if data, err := json.Marshal(config.RedactBody(body)); err == nil {
+			redacted.Body = string(data)
+		}
+	}
+	return &redacted
+}
+
+// redactResult masks sensitive query parameters in a request result's URL
+func (s *Server) redactResult(result *client.RequestResult) *client.RequestResult {
+	if s.includeSecrets || result == nil {
+		return result
+	}
+	redacted := *result
+	redacted.URL = config.RedactURL(result.URL)
+	return &redacted
+}
@@ -3,61 +3,77 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
 
 	"moxapp/internal/config"
 )
 
-// handleListEndpoints returns all endpoints
-// GET /api/endpoints
+// handleListEndpoints returns the endpoints, optionally filtered and paged.
+// Without the config manager the legacy config is listed.
+// GET /api/outgoing/endpoints
 func (s *Server) handleListEndpoints(w http.ResponseWriter, r *http.Request) {
-	endpoints := s.configManager.GetEndpoints()
+	filter, err := config.ParseEndpointFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, err := parseListParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg := s.getConfigForHandlers()
+	endpoints := filter.Apply(cfg.Endpoints)
+	response := map[string]interface{}{}
 
-	response := map[string]interface{}{
-		"count":     len(endpoints),
-		"endpoints": endpoints,
+	if params.active() {
+		endpoints, response["pagination"] = s.pageEndpoints(endpoints, params)
 	}
-	writeJSON(w, response)
+	response["count"] = len(endpoints)
+	response["endpoints"] = s.redactEndpoints(endpoints)
+	writeJSONWithETag(w, r, response)
 }
 
 // handleGetEndpoint returns a single endpoint by name
-// GET /api/endpoints/{name}
+// GET /api/outgoing/endpoints/{name}
 func (s *Server) handleGetEndpoint(w http.ResponseWriter, r *http.Request) {
-	name := strings.TrimPrefix(r.URL.Path, "/api/outgoing/endpoints/")
-	if name == "" {
-		writeError(w, "endpoint name is required", http.StatusBadRequest)
+	name := r.PathValue("name")
+	if s.configManager == nil {
+		// Fallback to legacy config
+		for _, ep := range s.config.Endpoints {
+			if ep.Name == name {
+				writeJSON(w, s.redactEndpoint(ep))
+				return
+			}
+		}
+		writeError(w, "endpoint not found: "+name, http.StatusNotFound)
 		return
 	}
 
 	endpoint, err := s.configManager.GetEndpoint(name)
 	if err != nil {
-		writeError(w, err.Error(), http.StatusNotFound)
+		writeConfigError(w, err, nil)
 		return
 	}
 
-	writeJSON(w, endpoint)
+	writeJSON(w, s.redactEndpoint(*endpoint))
 }
 
 // handleCreateEndpoint creates a new endpoint
-// POST /api/endpoints
+// POST /api/outgoing/endpoints
 func (s *Server) handleCreateEndpoint(w http.ResponseWriter, r *http.Request) {
 	var req config.EndpointRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
 	endpoint := req.ToEndpoint()
 
 	if err := s.configManager.AddEndpoint(endpoint); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			writeError(w, err.Error(), http.StatusConflict)
-		} else if strings.Contains(err.Error(), "validation failed") {
-			writeError(w, err.Error(), http.StatusBadRequest)
-		} else {
-			writeError(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeConfigError(w, err, &req)
 		return
 	}
 
@@ -65,62 +81,42 @@ func (s *Server) handleCreateEndpoint(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, map[string]interface{}{
 		"status":   "success",
 		"message":  "Endpoint created successfully",
-		"endpoint": endpoint,
+		"endpoint": s.redactEndpoint(endpoint),
 	})
 }
 
 // handleUpdateEndpoint updates an existing endpoint
-// PUT /api/endpoints/{name}
+// PUT /api/outgoing/endpoints/{name}
 func (s *Server) handleUpdateEndpoint(w http.ResponseWriter, r *http.Request) {
-	name := strings.TrimPrefix(r.URL.Path, "/api/outgoing/endpoints/")
-	if name == "" {
-		writeError(w, "endpoint name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	var req config.EndpointRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
 	endpoint := req.ToEndpoint()
 
 	if err := s.configManager.UpdateEndpoint(name, endpoint); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, err.Error(), http.StatusNotFound)
-		} else if strings.Contains(err.Error(), "already exists") {
-			writeError(w, err.Error(), http.StatusConflict)
-		} else if strings.Contains(err.Error(), "validation failed") {
-			writeError(w, err.Error(), http.StatusBadRequest)
-		} else {
-			writeError(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeConfigError(w, err, &req)
 		return
 	}
 
 	writeJSON(w, map[string]interface{}{
 		"status":   "success",
 		"message":  "Endpoint updated successfully",
-		"endpoint": endpoint,
+		"endpoint": s.redactEndpoint(endpoint),
 	})
 }
 
 // handleDeleteEndpoint deletes an endpoint by name
-// DELETE /api/endpoints/{name}
+// DELETE /api/outgoing/endpoints/{name}
 func (s *Server) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
-	name := strings.TrimPrefix(r.URL.Path, "/api/outgoing/endpoints/")
-	if name == "" {
-		writeError(w, "endpoint name is required", http.StatusBadRequest)
-		return
-	}
+	name := r.PathValue("name")
 
 	if err := s.configManager.DeleteEndpoint(name); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, err.Error(), http.StatusNotFound)
-		} else {
-			writeError(w, err.Error(), http.StatusInternalServerError)
-		}
+		writeConfigError(w, err, nil)
 		return
 	}
 
@@ -130,69 +126,49 @@ func (s *Server) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleEndpoints is a router for endpoint CRUD operations
-func (s *Server) handleEndpoints(w http.ResponseWriter, r *http.Request) {
-	// Check if it's a request for a specific endpoint
-	path := strings.TrimPrefix(r.URL.Path, "/api/outgoing/endpoints")
-	hasName := path != "" && path != "/"
-
-	switch r.Method {
-	case http.MethodGet:
-		if hasName {
-			s.handleGetEndpoint(w, r)
-		} else {
-			s.handleListEndpoints(w, r)
-		}
-	case http.MethodPost:
-		if hasName {
-			writeError(w, "POST to specific endpoint not allowed, use PUT to update", http.StatusMethodNotAllowed)
-		} else {
-			s.handleCreateEndpoint(w, r)
-		}
-	case http.MethodPut:
-		if hasName {
-			s.handleUpdateEndpoint(w, r)
-		} else {
-			writeError(w, "PUT requires endpoint name in path", http.StatusBadRequest)
-		}
-	case http.MethodDelete:
-		if hasName {
-			s.handleDeleteEndpoint(w, r)
-		} else {
-			writeError(w, "DELETE requires endpoint name in path", http.StatusBadRequest)
-		}
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+// handleTestEndpoint fires one request for an endpoint through the real
+// client (auth, templates, cookies, tracing), whether or not it is enabled.
+// The result is not recorded in metrics.
+// POST /api/outgoing/endpoints/{name}/test
+func (s *Server) handleTestEndpoint(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := s.configManager.GetEndpoint(r.PathValue("name"))
+	if err != nil {
+		writeConfigError(w, err, nil)
+		return
 	}
-}
-
-// handleBulkEndpoints handles bulk operations on endpoints
-// POST /api/endpoints/bulk - create multiple endpoints
-// DELETE /api/endpoints/bulk - delete multiple endpoints
-func (s *Server) handleBulkEndpoints(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		s.handleBulkCreateEndpoints(w, r)
-	case http.MethodDelete:
-		s.handleBulkDeleteEndpoints(w, r)
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+	if s.httpClient == nil {
+		writeError(w, "HTTP client not available", http.StatusServiceUnavailable)
+		return
 	}
+
+	result := s.fireTestRequest(r.Context(), endpoint)
+	writeJSON(w, map[string]interface{}{
+		"endpoint": endpoint.Name,
+		"result":   s.redactResult(result),
+	})
 }
 
 // handleBulkCreateEndpoints creates multiple endpoints at once
+// POST /api/outgoing/endpoints/bulk
 func (s *Server) handleBulkCreateEndpoints(w http.ResponseWriter, r *http.Request) {
 	var requests []config.EndpointRequest
 	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
+		return
+	}
+
+	endpoints := make([]config.Endpoint, len(requests))
+	for i, req := range requests {
+		endpoints[i] = req.ToEndpoint()
+	}
+	if !s.confirmEnabling(w, r, s.newlyEnabled(endpoints)) {
 		return
 	}
 
 	var created []string
 	var errors []string
 
-	for _, req := range requests {
-		endpoint := req.ToEndpoint()
+	for _, endpoint := range endpoints {
 		if err := s.configManager.AddEndpoint(endpoint); err != nil {
 			errors = append(errors, endpoint.Name+": "+err.Error())
 		} else {
@@ -219,13 +195,126 @@ func (s *Server) handleBulkCreateEndpoints(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// bulkUpdateResult is the outcome of one update of a bulk update
+type bulkUpdateResult struct {
+	Name     string           `json:"name"`
+	Status   string           `json:"status"` // updated, failed or not_applied
+	Error    *apiError        `json:"error,omitempty"`
+	Endpoint *config.Endpoint `json:"endpoint,omitempty"`
+}
+
+// handleBulkUpdateEndpoints applies partial updates to multiple endpoints,
+// atomically: if any update fails, none is applied
+// PUT /api/outgoing/endpoints/bulk
+func (s *Server) handleBulkUpdateEndpoints(w http.ResponseWriter, r *http.Request) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if len(items) == 0 {
+		writeError(w, "no updates given", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]bulkUpdateResult, len(items))
+	patches := make([]config.EndpointPatch, 0, len(items))
+	for i, item := range items {
+		patch, err := config.ParseEndpointPatch(item)
+		if err != nil {
+			var invalid *config.ValidationError
+			e := apiError{Code: CodeInvalidJSON, Message: "invalid JSON: " + err.Error()}
+			if errors.As(err, &invalid) {
+				_, e = configError(err, nil)
+			}
+			results[i] = bulkUpdateResult{Status: "failed", Error: &e}
+			continue
+		}
+		results[i].Name = patch.Name
+		patches = append(patches, patch)
+	}
+
+	failed := len(items) - len(patches)
+	if failed == 0 && !s.confirmEnabling(w, r, s.newlyEnabled(patchedEnabled(s.configManager.GetEndpoints(), patches))) {
+		return
+	}
+	if failed == 0 {
+		updated, errs := s.configManager.PatchEndpoints(patches)
+		for i, err := range errs {
+			if err != nil {
+				_, e := configError(err, &config.EndpointRequest{})
+				results[i].Status = "failed"
+				results[i].Error = &e
+				failed++
+				continue
+			}
+			endpoint := s.redactEndpoint(updated[i])
+			results[i].Endpoint = &endpoint
+		}
+	}
+
+	applied := 0
+	for i := range results {
+		switch {
+		case results[i].Status == "failed":
+		case failed > 0:
+			results[i].Status = "not_applied"
+			results[i].Endpoint = nil
+		default:
+			results[i].Status = "updated"
+			applied++
+		}
+	}
+	summary := map[string]int{
+		"total_requested": len(items),
+		"updated":         applied,
+		"failed":          failed,
+	}
+
+	if failed > 0 {
+		writeAPIError(w, http.StatusBadRequest, CodeBulkUpdateFailed,
+			fmt.Sprintf("%d of %d updates failed; none was applied", failed, len(items)),
+			map[string]interface{}{"results": results, "summary": summary})
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"results": results,
+		"summary": summary,
+	})
+}
+
+// patchedEnabled returns the endpoints with the enabled state the patches set
+func patchedEnabled(endpoints []config.Endpoint, patches []config.EndpointPatch) []config.Endpoint {
+	enabled := make(map[string]bool)
+	for _, patch := range patches {
+		var fields struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if json.Unmarshal(patch.Fields, &fields) == nil && fields.Enabled != nil {
+			enabled[patch.Name] = *fields.Enabled
+		}
+	}
+	patched := make([]config.Endpoint, len(endpoints))
+	for i, ep := range endpoints {
+		if e, ok := enabled[ep.Name]; ok {
+			ep.Enabled = e
+		}
+		patched[i] = ep
+	}
+	return patched
+}
+
+// namesRequest selects endpoints by name
+type namesRequest struct {
+	Names []string `json:"names"`
+}
+
 // handleBulkDeleteEndpoints deletes multiple endpoints by name
+// DELETE /api/outgoing/endpoints/bulk
 func (s *Server) handleBulkDeleteEndpoints(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Names []string `json:"names"`
-	}
+	var req namesRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
@@ -0,0 +1,152 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"moxapp/internal/config"
+)
+
+// Error codes of API error responses, for clients to tell errors apart
+// without matching messages
+const (
+	CodeBadRequest            = "BAD_REQUEST"
+	CodeInvalidJSON           = "INVALID_JSON"
+	CodeValidationFailed      = "VALIDATION_FAILED"
+	CodeForbidden             = "FORBIDDEN"
+	CodeNotFound              = "NOT_FOUND"
+	CodeEndpointNotFound      = "ENDPOINT_NOT_FOUND"
+	CodeAuthConfigNotFound    = "AUTH_CONFIG_NOT_FOUND"
+	CodeGroupNotFound         = "GROUP_NOT_FOUND"
+	CodeIncomingRouteNotFound = "INCOMING_ROUTE_NOT_FOUND"
+	CodeConfigVersionNotFound = "CONFIG_VERSION_NOT_FOUND"
+	CodeEnvironmentNotFound   = "ENVIRONMENT_NOT_FOUND"
+	CodeMethodNotAllowed      = "METHOD_NOT_ALLOWED"
+	CodeAlreadyExists         = "ALREADY_EXISTS"
+	CodeInUse                 = "IN_USE"
+	CodeConflict              = "CONFLICT"
+	CodeGuardrailViolation    = "GUARDRAIL_VIOLATION"
+	CodeConfirmationRequired  = "CONFIRMATION_REQUIRED"
+	CodeBulkUpdateFailed      = "BULK_UPDATE_FAILED"
+	CodeTransactionFailed     = "TRANSACTION_FAILED"
+	CodeRateLimited           = "RATE_LIMITED"
+	CodeInternal              = "INTERNAL_ERROR"
+	CodeUnavailable           = "UNAVAILABLE"
+)
+
+// errorCodes lists every error code, as documented in the OpenAPI spec
+var errorCodes = []string{
+	CodeBadRequest, CodeInvalidJSON, CodeValidationFailed, CodeForbidden,
+	CodeNotFound, CodeEndpointNotFound, CodeAuthConfigNotFound, CodeGroupNotFound,
+	CodeIncomingRouteNotFound, CodeConfigVersionNotFound, CodeEnvironmentNotFound, CodeMethodNotAllowed,
+	CodeAlreadyExists, CodeInUse, CodeConflict, CodeGuardrailViolation, CodeConfirmationRequired,
+	CodeBulkUpdateFailed, CodeTransactionFailed, CodeRateLimited, CodeInternal, CodeUnavailable,
+}
+
+// statusCodes are the codes of errors that have no more specific one
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          CodeBadRequest,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusMethodNotAllowed:    CodeMethodNotAllowed,
+	http.StatusConflict:            CodeConflict,
+	http.StatusTooManyRequests:     CodeRateLimited,
+	http.StatusInternalServerError: CodeInternal,
+	http.StatusServiceUnavailable:  CodeUnavailable,
+}
+
+// notFoundCodes are the codes of missing configuration items by kind
+var notFoundCodes = map[string]string{
+	config.KindEndpoint:      CodeEndpointNotFound,
+	config.KindAuthConfig:    CodeAuthConfigNotFound,
+	config.KindGroup:         CodeGroupNotFound,
+	config.KindIncomingRoute: CodeIncomingRouteNotFound,
+	config.KindVersion:       CodeConfigVersionNotFound,
+	config.KindEnvironment:   CodeEnvironmentNotFound,
+}
+
+// apiError is the body of error responses: {"error": {code, message, details}}
+type apiError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// writeError writes a JSON error response, with the generic code of its status
+func writeError(w http.ResponseWriter, message string, statusCode int) {
+	code, ok := statusCodes[statusCode]
+	if !ok {
+		code = CodeInternal
+	}
+	writeAPIError(w, statusCode, code, message, nil)
+}
+
+// writeAPIError writes a JSON error response with a specific code and
+// optional details
+func writeAPIError(w http.ResponseWriter, statusCode int, code, message string, details interface{}) {
+	w.WriteHeader(statusCode)
+	writeJSON(w, map[string]apiError{
+		"error": {Code: code, Message: message, Details: details},
+	})
+}
+
+// writeInvalidJSON writes the error of a request body that couldn't be decoded
+func writeInvalidJSON(w http.ResponseWriter, err error) {
+	writeAPIError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid JSON: "+err.Error(), nil)
+}
+
+// writeValidationError writes a failed validation with the errors attributed
+// to the fields of the request body v, for editors to show inline
+func writeValidationError(w http.ResponseWriter, message string, v interface{}, errors []string) {
+	e := validationError(message, v, errors)
+	writeAPIError(w, http.StatusBadRequest, e.Code, e.Message, e.Details)
+}
+
+// validationError is the error of a failed validation
+func validationError(message string, v interface{}, errors []string) apiError {
+	details := map[string]interface{}{"errors": errors}
+	if v != nil {
+		details["field_errors"] = config.FieldErrors(v, errors)
+	}
+	return apiError{Code: CodeValidationFailed, Message: message, Details: details}
+}
+
+// writeConfigError writes the error of a configuration manager call with
+// the status and code of its kind. Validation problems are attributed to
+// the fields of the request body v, if any.
+func writeConfigError(w http.ResponseWriter, err error, v interface{}) {
+	status, e := configError(err, v)
+	writeAPIError(w, status, e.Code, e.Message, e.Details)
+}
+
+// configError returns the status and error of a configuration manager error
+func configError(err error, v interface{}) (int, apiError) {
+	var notFound *config.NotFoundError
+	var exists *config.AlreadyExistsError
+	var inUse *config.InUseError
+	var invalid *config.ValidationError
+	var guardrail *config.GuardrailError
+	switch {
+	case errors.As(err, &invalid):
+		return http.StatusBadRequest, validationError(err.Error(), v, invalid.Problems)
+	case errors.As(err, &notFound):
+		code, ok := notFoundCodes[notFound.Kind]
+		if !ok {
+			code = CodeNotFound
+		}
+		return http.StatusNotFound, apiError{Code: code, Message: err.Error(),
+			Details: map[string]string{"kind": notFound.Kind, "name": notFound.Name}}
+	case errors.As(err, &exists):
+		return http.StatusConflict, apiError{Code: CodeAlreadyExists, Message: err.Error(),
+			Details: map[string]string{"kind": exists.Kind, "name": exists.Name}}
+	case errors.As(err, &inUse):
+		return http.StatusConflict, apiError{Code: CodeInUse, Message: err.Error(),
+			Details: map[string]string{"kind": inUse.Kind, "name": inUse.Name, "endpoint": inUse.Endpoint}}
+	case errors.As(err, &guardrail):
+		return http.StatusForbidden, apiError{Code: CodeGuardrailViolation, Message: err.Error(),
+			Details: map[string][]string{"violations": guardrail.Violations}}
+	default:
+		return http.StatusInternalServerError, apiError{Code: CodeInternal, Message: err.Error()}
+	}
+}
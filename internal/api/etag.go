@@ -0,0 +1,72 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// metricsETagPeriod bounds how long polling clients keep metrics that didn't
+// change: uptime, rates and window aggregates move with time alone
+const metricsETagPeriod = 10 * time.Second
+
+// metricsETag returns the entity tag of a metrics response, from the
+// collectors' change counters, the current period and the request. It is
+// known before the snapshot is built, so unchanged metrics cost no
+// serialization.
+func (s *Server) metricsETag(r *http.Request) string {
+	var incoming int64
+	if s.incomingMetrics != nil {
+		incoming = s.incomingMetrics.Changes()
+	}
+	period := time.Now().UnixNano() / int64(metricsETagPeriod)
+	return entityTag(fmt.Sprintf("%d|%d|%d|%s?%s", s.metrics.Changes(), incoming, period, r.URL.Path, r.URL.RawQuery))
+}
+
+// entityTag returns a weak entity tag of content. Tags are weak because
+// responses are compressed on the fly, which doesn't keep them byte for byte.
+func entityTag(content string) string {
+	h := fnv.New64a()
+	h.Write([]byte(content))
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// notModified sets the ETag of a response and answers 304 Not Modified when
+// the client's If-None-Match has it, in which case the handler is done.
+// Clients are asked to revalidate every time.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	match := r.Header.Get("If-None-Match")
+	if match == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(match, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONWithETag writes a JSON response tagged with the hash of its
+// content, or 304 Not Modified if the client already has it
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n') // As written by writeJSON
+	if notModified(w, r, entityTag(string(body))) {
+		return
+	}
+	w.Write(body)
+}
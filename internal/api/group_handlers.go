@@ -0,0 +1,174 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"moxapp/internal/config"
+)
+
+// groupResponse returns a group along with its budget distribution
+func (s *Server) groupResponse(group config.EndpointGroup) map[string]interface{} {
+	group = s.redactGroup(group)
+	return map[string]interface{}{
+		"name":        group.Name,
+		"budget":      group.Budget,
+		"multiplier":  group.EffectiveMultiplier(),
+		"description": group.Description,
+		"cookie_jar":  group.CookieJar,
+		"cookies":     group.Cookies,
+		"members":     s.configManager.GetGroupMembers(group.Name),
+	}
+}
+
+// handleListGroups returns all endpoint groups
+// GET /api/outgoing/groups
+func (s *Server) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	groups := s.configManager.GetEndpointGroups()
+
+	result := make([]map[string]interface{}, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, s.groupResponse(group))
+	}
+
+	writeJSONWithETag(w, r, map[string]interface{}{
+		"count":  len(result),
+		"groups": result,
+	})
+}
+
+// handleGetGroup returns a single endpoint group by name
+// GET /api/outgoing/groups/{name}
+func (s *Server) handleGetGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := s.configManager.GetEndpointGroup(r.PathValue("name"))
+	if err != nil {
+		writeConfigError(w, err, nil)
+		return
+	}
+
+	writeJSON(w, s.groupResponse(*group))
+}
+
+// handleCreateGroup creates a new endpoint group
+// POST /api/outgoing/groups
+func (s *Server) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var group config.EndpointGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+
+	if err := s.configManager.AddEndpointGroup(group); err != nil {
+		writeConfigError(w, err, &group)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Endpoint group created successfully",
+		"group":   s.groupResponse(group),
+	})
+}
+
+// handleUpdateGroup updates an existing endpoint group
+// PUT /api/outgoing/groups/{name}
+func (s *Server) handleUpdateGroup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var group config.EndpointGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if group.Name == "" {
+		group.Name = name
+	}
+
+	if err := s.configManager.UpdateEndpointGroup(name, group); err != nil {
+		writeConfigError(w, err, &group)
+		return
+	}
+
+	// Start a fresh session so changed seed cookies take effect
+	if s.cookieJars != nil {
+		s.cookieJars.Reset(name)
+		s.cookieJars.Reset(group.Name)
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Endpoint group updated successfully",
+		"group":   s.groupResponse(group),
+	})
+}
+
+// budgetRequest sets the shared budget of a group
+type budgetRequest struct {
+	Budget *float64 `json:"budget"` // Requests per minute
+}
+
+// handleSetGroupBudget updates only the shared budget of a group
+// POST/PUT /api/outgoing/groups/{name}/budget
+func (s *Server) handleSetGroupBudget(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var req budgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+	if req.Budget == nil {
+		writeError(w, "budget is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.configManager.SetGroupBudget(name, *req.Budget); err != nil {
+		writeConfigError(w, err, &req)
+		return
+	}
+
+	group, _ := s.configManager.GetEndpointGroup(name)
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Group budget updated",
+		"group":   s.groupResponse(*group),
+	})
+}
+
+// handleDeleteGroup deletes an endpoint group that has no members
+// DELETE /api/outgoing/groups/{name}
+func (s *Server) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	if err := s.configManager.DeleteEndpointGroup(r.PathValue("name")); err != nil {
+		writeConfigError(w, err, nil)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Endpoint group deleted successfully",
+	})
+}
+
+// handleResetGroupCookies clears a group's cookie jar; seed cookies are
+// applied again on the next request
+// DELETE /api/outgoing/groups/{name}/cookies
+func (s *Server) handleResetGroupCookies(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, err := s.configManager.GetEndpointGroup(name); err != nil {
+		writeConfigError(w, err, nil)
+		return
+	}
+	if s.cookieJars == nil {
+		writeError(w, "cookie jars not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	s.cookieJars.Reset(name)
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Group cookies cleared",
+	})
+}
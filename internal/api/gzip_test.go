@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gzipServe sends a GET /api/ request accepting encoding through the gzip
+// middleware in front of handler
+func gzipServe(handler http.HandlerFunc, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/api/test", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	gzipMiddleware(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+// gunzip decodes a compressed response body
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("expected a gzip body: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	return string(data)
+}
+
+func TestGzipThreshold(t *testing.T) {
+	small := strings.Repeat("a", gzipMinSize-1)
+	large := strings.Repeat("b", gzipMinSize)
+
+	rec := gzipServe(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(small))
+	}, "gzip")
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != small {
+		t.Errorf("expected a body under %d bytes sent as is, got encoding %q and %d bytes",
+			gzipMinSize, rec.Header().Get("Content-Encoding"), rec.Body.Len())
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("expected Vary: Accept-Encoding, got %q", rec.Header().Get("Vary"))
+	}
+
+	// Written in parts, with a status and a length that no longer applies
+	rec = gzipServe(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(large[:100]))
+		w.Write([]byte(large[100:]))
+	}, "gzip")
+	if rec.Code != http.StatusCreated || rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Length") != "" {
+		t.Fatalf("expected a compressed 201 without Content-Length, got %d %v", rec.Code, rec.Header())
+	}
+	if body := gunzip(t, rec.Body.Bytes()); body != large {
+		t.Errorf("expected the body to round-trip, got %d bytes", len(body))
+	}
+
+	// A small body with a status keeps its status
+	rec = gzipServe(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("{}"))
+	}, "gzip")
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "{}" {
+		t.Errorf("expected 202 {}, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGzipNoBodyStatuses(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusNotModified} {
+		rec := gzipServe(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(status)
+		}, "gzip")
+		if rec.Code != status || rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+			t.Errorf("%d: expected no encoding and no body, got %d %v %q", status, rec.Code, rec.Header(), rec.Body.String())
+		}
+		if rec.Header().Get("ETag") != `"v1"` {
+			t.Errorf("%d: expected headers kept, got %v", status, rec.Header())
+		}
+	}
+}
+
+func TestGzipEventStream(t *testing.T) {
+	var flushedEarly bool
+	var rec *httptest.ResponseRecorder
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		flushedEarly = rec.Flushed && rec.Body.String() == "data: 1\n\n"
+		w.Write([]byte("data: 2\n\n"))
+	}
+	req := httptest.NewRequest("GET", "/api/metrics/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	gzipMiddleware(http.HandlerFunc(handler)).ServeHTTP(rec, req)
+
+	if !flushedEarly {
+		t.Error("expected the first event sent uncompressed on Flush, before the handler returned")
+	}
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != "data: 1\n\ndata: 2\n\n" {
+		t.Errorf("expected the stream uncompressed, got %v %q", rec.Header(), rec.Body.String())
+	}
+}
+
+func TestGzipFlushCompresses(t *testing.T) {
+	rec := gzipServe(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(" rest"))
+	}, "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || !rec.Flushed {
+		t.Fatalf("expected Flush to start compressing, got %v", rec.Header())
+	}
+	if body := gunzip(t, rec.Body.Bytes()); body != "partial rest" {
+		t.Errorf("expected the body to round-trip, got %q", body)
+	}
+}
+
+func TestGzipExistingEncoding(t *testing.T) {
+	encoded := strings.Repeat("z", 2*gzipMinSize)
+	rec := gzipServe(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte(encoded))
+	}, "gzip, br")
+	if rec.Header().Get("Content-Encoding") != "br" || rec.Body.String() != encoded {
+		t.Errorf("expected the br body kept as is, got %v and %d bytes", rec.Header(), rec.Body.Len())
+	}
+}
+
+func TestGzipAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		gzip   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"br, identity", false},
+	}
+
+	large := strings.Repeat("c", 2*gzipMinSize)
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.gzip {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.gzip)
+		}
+		rec := gzipServe(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(large))
+		}, tt.header)
+		if encoded := rec.Header().Get("Content-Encoding") == "gzip"; encoded != tt.gzip {
+			t.Errorf("Accept-Encoding %q: expected compressed %v, got %v", tt.header, tt.gzip, encoded)
+		}
+	}
+}
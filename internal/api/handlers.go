@@ -2,23 +2,64 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
 	"moxapp/internal/scheduler"
 )
 
 // --- Metrics Handlers ---
 
+// outgoingSnapshot returns outgoing metrics for the ?window= parameter (since
+// start if unset), writing an error response if the window is invalid
+func (s *Server) outgoingSnapshot(w http.ResponseWriter, r *http.Request) (*metrics.MetricsSnapshot, bool) {
+	snapshot, err := s.metrics.WindowSnapshot(r.URL.Query().Get("window"))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if s.scheduler != nil {
+		stats := s.scheduler.GetStats()
+		if hosts := stats.Hosts; len(hosts) > 0 {
+			snapshot.InFlightByHost = make(map[string]metrics.HostInFlightSnapshot, len(hosts))
+			for host, stat := range hosts {
+				snapshot.InFlightByHost[host] = metrics.HostInFlightSnapshot(stat)
+			}
+		}
+		snapshot.InFlight, snapshot.MaxInFlight = stats.RequestsInFlight, stats.MaxInFlight
+	}
+	if s.resources != nil {
+		process := s.resources.Snapshot()
+		snapshot.Process = &process
+	}
+	return snapshot, true
+}
+
 // handleMetricsOverview returns a merged metrics response (summary + snapshots)
 func (s *Server) handleMetricsOverview(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+	params, err := parseListParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if notModified(w, r, s.metricsETag(r)) {
 		return
 	}
 
-	outgoingSnapshot := s.metrics.Snapshot()
+	outgoingSnapshot, ok := s.outgoingSnapshot(w, r)
+	if !ok {
+		return
+	}
 
 	errorSummary := map[string]int64{
 		"timeout":    0,
@@ -45,9 +86,16 @@ func (s *Server) handleMetricsOverview(w http.ResponseWriter, r *http.Request) {
 			"endpoint_count":   len(outgoingSnapshot.Endpoints),
 			"domain_count":     len(outgoingSnapshot.DNSStatsByDomain),
 			"error_summary":    errorSummary,
+			"window":           outgoingSnapshot.Window,
 		},
 		"outgoing_snapshot": outgoingSnapshot,
 	}
+	if len(outgoingSnapshot.TLSWarnings) > 0 {
+		response["outgoing"].(map[string]interface{})["tls_warnings"] = outgoingSnapshot.TLSWarnings
+	}
+	if params.active() {
+		response["outgoing_snapshot"] = pageSnapshot(outgoingSnapshot, params)
+	}
 
 	if s.incomingMetrics != nil {
 		incomingSnapshot := s.incomingMetrics.Snapshot()
@@ -70,11 +118,6 @@ func (s *Server) handleMetricsOverview(w http.ResponseWriter, r *http.Request) {
 
 // handleResetAllMetrics resets both outgoing and incoming metrics
 func (s *Server) handleResetAllMetrics(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Reset outgoing metrics
 	s.metrics.Reset()
 
@@ -82,6 +125,9 @@ func (s *Server) handleResetAllMetrics(w http.ResponseWriter, r *http.Request) {
 	if s.incomingMetrics != nil {
 		s.incomingMetrics.Reset()
 	}
+	if s.authMetrics != nil {
+		s.authMetrics.Reset()
+	}
 
 	response := map[string]string{
 		"status":  "success",
@@ -92,22 +138,212 @@ func (s *Server) handleResetAllMetrics(w http.ResponseWriter, r *http.Request) {
 
 // handleGetMetrics returns current outgoing metrics
 func (s *Server) handleGetMetrics(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+	params, err := parseListParams(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if notModified(w, r, s.metricsETag(r)) {
 		return
 	}
 
-	snapshot := s.metrics.Snapshot()
+	snapshot, ok := s.outgoingSnapshot(w, r)
+	if !ok {
+		return
+	}
+	if params.active() {
+		writeJSON(w, pageSnapshot(snapshot, params))
+		return
+	}
 	writeJSON(w, snapshot)
 }
 
-// handleResetMetrics resets outgoing metrics
-func (s *Server) handleResetMetrics(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+// handleGetPrometheusMetrics returns metrics for Prometheus to scrape
+// GET /api/metrics/prometheus
+func (s *Server) handleGetPrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	outgoing, ok := s.outgoingSnapshot(w, r)
+	if !ok {
+		return
+	}
+	var incoming *metrics.IncomingMetricsSnapshot
+	if s.incomingMetrics != nil {
+		incoming = s.incomingMetrics.Snapshot()
+	}
+
+	w.Header().Set("Content-Type", metrics.PrometheusContentType)
+	metrics.WritePrometheus(w, outgoing, incoming)
+}
+
+// handleGetEndpointMetrics returns the metrics of one endpoint with its recent
+// time series (last 15 minutes unless ?from= or ?to= are given)
+// GET /api/metrics/outgoing/endpoints/{name}
+func (s *Server) handleGetEndpointMetrics(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	window := r.URL.Query().Get("window")
+	if _, err := metrics.ParseWindow(window); err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseTimeRange(r, defaultSeriesRange)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := s.metrics.EndpointStats(name, window)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	points, err := s.metrics.Timeseries(name, from, to)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"endpoint": name,
+		"window":   window,
+		"metrics":  snapshot,
+		"timeseries": map[string]interface{}{
+			"interval_seconds": metrics.BucketSeconds,
+			"count":            len(points),
+			"points":           points,
+		},
+	})
+}
+
+// handleGetErrorSamples returns the distinct errors recently seen for an endpoint
+// GET /api/metrics/outgoing/{endpoint}/errors
+func (s *Server) handleGetErrorSamples(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("view") != "errors" {
+		writeError(w, "not found: "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	name := r.PathValue("endpoint")
+	samples, err := s.metrics.ErrorSamples(name)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if !s.includeSecrets {
+		for i := range samples {
+			if samples[i].SampleURL == "" {
+				continue
+			}
+			// Go errors quote the request URL, so it is masked in the message too
+			redacted := config.RedactURL(samples[i].SampleURL)
+			samples[i].Message = strings.ReplaceAll(samples[i].Message, samples[i].SampleURL, redacted)
+			samples[i].SampleURL = redacted
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"endpoint": name,
+		"count":    len(samples),
+		"errors":   samples,
+	})
+}
+
+// handleGetTopEndpoints returns the worst outgoing endpoints by a metric
+// GET /api/metrics/top?by=errors|p95|dns&limit=10
+func (s *Server) handleGetTopEndpoints(w http.ResponseWriter, r *http.Request) {
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = "errors"
+	}
+	if by == metrics.SortByName || !metrics.IsValidEndpointSort(by) {
+		writeError(w, "invalid by: "+by+" (must be one of: "+strings.Join(metrics.EndpointSortKeys()[1:], ", ")+")", http.StatusBadRequest)
+		return
+	}
+
+	limit := metrics.DefaultTopLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	if notModified(w, r, s.metricsETag(r)) {
+		return
+	}
+
+	snapshot, ok := s.outgoingSnapshot(w, r)
+	if !ok {
+		return
+	}
+
+	top := metrics.TopEndpoints(snapshot.Endpoints, by, limit)
+	writeJSON(w, map[string]interface{}{
+		"window":    snapshot.Window,
+		"by":        by,
+		"limit":     limit,
+		"count":     len(top),
+		"endpoints": top,
+	})
+}
+
+// handleGetTagMetrics returns outgoing metrics aggregated per endpoint tag
+func (s *Server) handleGetTagMetrics(w http.ResponseWriter, r *http.Request) {
+	if !s.checkConfigManager(w) {
+		return
+	}
+
+	snapshot, ok := s.outgoingSnapshot(w, r)
+	if !ok {
+		return
+	}
+
+	tags := metrics.AggregateByTag(snapshot.Endpoints, s.configManager.GetEndpointTags())
+	writeJSON(w, map[string]interface{}{
+		"window": snapshot.Window,
+		"count":  len(tags),
+		"tags":   tags,
+	})
+}
+
+// handleGetHostMetrics returns outgoing metrics aggregated per hostname
+// GET /api/metrics/outgoing/hosts?window=5m
+func (s *Server) handleGetHostMetrics(w http.ResponseWriter, r *http.Request) {
+	window := r.URL.Query().Get("window")
+	hosts, err := s.metrics.HostStats(window)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	writeJSON(w, map[string]interface{}{
+		"window": window,
+		"count":  len(hosts),
+		"hosts":  hosts,
+	})
+}
+
+// handleGetSLOs returns the error budget and burn rate of every endpoint
+// with an SLO
+// GET /api/metrics/slo
+func (s *Server) handleGetSLOs(w http.ResponseWriter, r *http.Request) {
+	if !s.checkConfigManager(w) {
+		return
+	}
+
+	targets := make(map[string]metrics.SLOTarget)
+	for name, slo := range s.configManager.GetSLOs() {
+		targets[name] = metrics.SLOTarget{Objective: slo.Objective, LatencyMs: slo.LatencyMs}
+	}
+	slos := s.metrics.SLOs(targets)
+	writeJSON(w, map[string]interface{}{
+		"count":     len(slos),
+		"endpoints": slos,
+	})
+}
+
+// handleResetMetrics resets outgoing metrics
+func (s *Server) handleResetMetrics(w http.ResponseWriter, r *http.Request) {
 	s.metrics.Reset()
 
 	response := map[string]string{
@@ -119,13 +355,12 @@ func (s *Server) handleResetMetrics(w http.ResponseWriter, r *http.Request) {
 
 // handleGetIncomingMetrics returns metrics for incoming routes
 func (s *Server) handleGetIncomingMetrics(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+	if s.incomingMetrics == nil {
+		writeError(w, "incoming metrics not available", http.StatusServiceUnavailable)
 		return
 	}
 
-	if s.incomingMetrics == nil {
-		writeError(w, "incoming metrics not available", http.StatusServiceUnavailable)
+	if notModified(w, r, s.metricsETag(r)) {
 		return
 	}
 
@@ -135,11 +370,6 @@ func (s *Server) handleGetIncomingMetrics(w http.ResponseWriter, r *http.Request
 
 // handleResetIncomingMetrics resets incoming route metrics
 func (s *Server) handleResetIncomingMetrics(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	if s.incomingMetrics == nil {
 		writeError(w, "incoming metrics not available", http.StatusServiceUnavailable)
 		return
@@ -154,6 +384,37 @@ func (s *Server) handleResetIncomingMetrics(w http.ResponseWriter, r *http.Reque
 	writeJSON(w, response)
 }
 
+// handleGetDNSProbes returns standalone DNS probe results per hostname
+func (s *Server) handleGetDNSProbes(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"probes":    s.metrics.DNSProbeSnapshot(),
+	}
+
+	if s.configManager != nil {
+		probeCfg := s.configManager.GetDNSProbeConfig()
+		response["enabled"] = probeCfg.Enabled
+		response["interval_seconds"] = probeCfg.IntervalSeconds
+		response["resolver"] = probeCfg.Resolver
+		response["hostnames"] = s.configManager.GetDNSProbeHostnames()
+	}
+
+	writeJSON(w, response)
+}
+
+// handleGetAuthMetrics returns token endpoint metrics per auth config
+func (s *Server) handleGetAuthMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.authMetrics == nil {
+		writeError(w, "auth metrics not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"auth":      s.authMetrics.Snapshot(),
+	})
+}
+
 // handleHealth returns health check information
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	var memStats runtime.MemStats
@@ -187,6 +448,7 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"requests_per_sec":   s.metrics.GetRequestsPerSecond(),
 		"success_rate":       s.metrics.GetSuccessRate(),
 		"requests_in_flight": schedulerStats.RequestsInFlight,
+		"max_in_flight":      schedulerStats.MaxInFlight,
 		"requests_skipped":   schedulerStats.RequestsSkipped,
 		"scheduler_running":  s.scheduler != nil && s.scheduler.IsRunning(),
 		"scheduler_paused":   schedulerStats.Paused,
@@ -201,6 +463,17 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		health["incoming_routes_enabled"] = s.configManager.IsIncomingEnabled()
 		health["incoming_routes_count"] = s.configManager.GetIncomingRouteCount()
 		health["incoming_routes_active"] = s.configManager.GetEnabledIncomingRouteCount()
+		if source := s.configManager.GetProvenance(); source != nil {
+			health["config_source"] = source
+		}
+	}
+	if s.resources != nil {
+		health["process"] = s.resources.Snapshot()
+	}
+	if s.scheduler != nil {
+		throttle := s.scheduler.GetThrottleStatus()
+		health["self_limited"] = throttle.SelfLimited
+		health["resources"] = throttle
 	}
 	if s.incomingMetrics != nil {
 		health["incoming_total_requests"] = s.incomingMetrics.GetTotalRequests()
@@ -212,25 +485,21 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // --- Control Handlers ---
 
-// handleControl routes control requests
-func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+// checkScheduler answers 503 when the scheduler isn't available
+func (s *Server) checkScheduler(w http.ResponseWriter) bool {
 	if s.scheduler == nil {
 		writeError(w, "scheduler not available", http.StatusServiceUnavailable)
-		return
-	}
-
-	switch r.Method {
-	case http.MethodGet:
-		s.handleGetControlStatus(w, r)
-	case http.MethodPost:
-		s.handleControlAction(w, r)
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
 	}
+	return true
 }
 
 // handleGetControlStatus returns current scheduler control status
 func (s *Server) handleGetControlStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduler(w) {
+		return
+	}
+
 	stats := s.scheduler.GetStats()
 
 	status := map[string]interface{}{
@@ -239,23 +508,66 @@ func (s *Server) handleGetControlStatus(w http.ResponseWriter, r *http.Request)
 		"scheduler_running":  s.scheduler.IsRunning(),
 		"requests_scheduled": stats.RequestsScheduled,
 		"requests_in_flight": stats.RequestsInFlight,
+		"max_in_flight":      stats.MaxInFlight,
 		"requests_skipped":   stats.RequestsSkipped,
+		"requests_capped":    stats.RequestsCapped,
+		"requests_waiting":   stats.RequestsWaiting,
+		"requests_starved":   stats.RequestsStarved,
+		"starved_endpoints":  stats.StarvedEndpoints,
+		"workers":            stats.Workers,
 		"total_endpoints":    stats.ActiveEndpoints,
 		"enabled_endpoints":  stats.EnabledEndpoints,
 		"disabled_endpoints": stats.ActiveEndpoints - stats.EnabledEndpoints,
+		"window_paused":      stats.WindowPaused,
+		"virtual_users":      stats.VirtualUsers,
+		"hosts":              stats.Hosts,
+		"stages":             s.scheduler.GetStageStatus(),
+		"adaptive":           s.scheduler.GetAdaptiveStatus(),
+		"heartbeat":          s.scheduler.GetHeartbeatStatus(),
+		"resources":          s.scheduler.GetThrottleStatus(),
+	}
+	if drain := s.scheduler.GetDrainStatus(); drain != nil {
+		status["drain"] = drain
 	}
 
 	writeJSON(w, status)
 }
 
-// handleControlAction handles POST requests to /api/control
+// handleHeartbeat records a heartbeat of the dead-man switch and returns its
+// state
+// POST /api/outgoing/control/heartbeat
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduler(w) {
+		return
+	}
+	writeJSON(w, s.scheduler.Heartbeat())
+}
+
+// handleGetHeartbeat returns the state of the dead-man switch
+// GET /api/outgoing/control/heartbeat
+func (s *Server) handleGetHeartbeat(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduler(w) {
+		return
+	}
+	writeJSON(w, s.scheduler.GetHeartbeatStatus())
+}
+
+// controlRequest is the body of POST /api/outgoing/control
+type controlRequest struct {
+	Action string `json:"action"` // pause, resume, start, stop, emergency_stop, enable_adaptive or disable_adaptive
+}
+
+// handleControlAction pauses, resumes, starts or stops the scheduler
+// POST /api/outgoing/control
 func (s *Server) handleControlAction(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Action string `json:"action"`
+	if !s.checkScheduler(w) {
+		return
 	}
 
+	var req controlRequest
+
 	if err := readJSON(r, &req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
@@ -276,38 +588,113 @@ func (s *Server) handleControlAction(w http.ResponseWriter, r *http.Request) {
 			"paused":  false,
 		})
 
+	case "stop":
+		if !s.scheduler.Stop() {
+			writeError(w, "scheduler not running", http.StatusConflict)
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": "Scheduler stopping - in-flight requests are cancelled; use action start to run again",
+			"running": false,
+		})
+
+	case "start":
+		if err := s.scheduler.Restart(); err != nil {
+			writeError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if s.scheduler.IsPaused() {
+			s.scheduler.Resume()
+		}
+		writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": "Scheduler starting - all endpoints are scheduled from scratch",
+			"running": true,
+		})
+
+	case "drain":
+		drain := s.scheduler.Drain()
+		writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("Draining - no new requests are scheduled, %d pending requests are finishing; GET this endpoint for progress", drain.Pending),
+			"paused":  true,
+			"drain":   drain,
+		})
+
 	case "emergency_stop":
 		s.scheduler.EmergencyStop()
+		if s.notifier != nil {
+			s.notifier.EmergencyStop(s.metrics.Snapshot())
+		}
 		writeJSON(w, map[string]interface{}{
 			"status":  "success",
 			"message": "EMERGENCY STOP - All scheduling stopped and in-flight requests cancelled",
 			"paused":  true,
 		})
 
+	case "enable_adaptive", "disable_adaptive":
+		if s.configManager == nil {
+			writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+			return
+		}
+		enabled := req.Action == "enable_adaptive"
+		if enabled {
+			adaptiveCfg := s.configManager.GetAdaptiveConfig()
+			if adaptiveCfg.MaxP95Ms == 0 && adaptiveCfg.MaxErrorRate == 0 {
+				writeError(w, "adaptive mode requires adaptive.max_p95_ms or adaptive.max_error_rate in config", http.StatusBadRequest)
+				return
+			}
+		}
+		s.configManager.SetAdaptiveEnabled(enabled)
+		writeJSON(w, map[string]interface{}{
+			"status":   "success",
+			"message":  "Adaptive mode updated",
+			"adaptive": s.scheduler.GetAdaptiveStatus(),
+		})
+
+	case "hold_stages", "resume_stages", "next_stage", "restart_stages":
+		message := "Stages updated"
+		switch req.Action {
+		case "hold_stages":
+			s.scheduler.HoldStages()
+			message = "Stages held - virtual users stay at the current stage"
+		case "resume_stages":
+			s.scheduler.ResumeStages()
+			message = "Stages resumed"
+		case "next_stage":
+			if err := s.scheduler.NextStage(); err != nil {
+				writeError(w, err.Error(), http.StatusConflict)
+				return
+			}
+			message = "Skipped to the next stage"
+		case "restart_stages":
+			s.scheduler.RestartStages()
+			message = "Stages restarted from the first stage"
+		}
+		writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": message,
+			"stages":  s.scheduler.GetStageStatus(),
+		})
+
 	default:
-		writeError(w, "unknown action: "+req.Action+". Valid actions: pause, resume, emergency_stop", http.StatusBadRequest)
+		writeError(w, "unknown action: "+req.Action+". Valid actions: pause, resume, drain, start, stop, emergency_stop, enable_adaptive, disable_adaptive, hold_stages, resume_stages, next_stage, restart_stages", http.StatusBadRequest)
 	}
 }
 
+// toggleRequest enables or disables one endpoint or incoming route by name
+type toggleRequest struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
 // handleEndpointEnable handles enabling/disabling specific endpoints
 func (s *Server) handleEndpointEnable(w http.ResponseWriter, r *http.Request) {
-	if s.configManager == nil {
-		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
-		return
-	}
-
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req struct {
-		Name    string `json:"name"`
-		Enabled bool   `json:"enabled"`
-	}
+	var req toggleRequest
 
 	if err := readJSON(r, &req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
@@ -317,7 +704,7 @@ func (s *Server) handleEndpointEnable(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.configManager.SetEndpointEnabled(req.Name, req.Enabled); err != nil {
-		writeError(w, err.Error(), http.StatusNotFound)
+		writeConfigError(w, err, nil)
 		return
 	}
 
@@ -334,25 +721,54 @@ func (s *Server) handleEndpointEnable(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// bulkEnableRequest enables or disables endpoints by names or tag
+type bulkEnableRequest struct {
+	Names   []string `json:"names"`
+	Tag     string   `json:"tag"` // Selects every endpoint with this tag instead of names
+	Enabled bool     `json:"enabled"`
+}
+
 // handleBulkEndpointEnable handles enabling/disabling multiple endpoints at once
 func (s *Server) handleBulkEndpointEnable(w http.ResponseWriter, r *http.Request) {
-	if s.configManager == nil {
-		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+	var req bulkEnableRequest
+
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
 		return
 	}
 
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+	action := "disabled"
+	if req.Enabled {
+		action = "enabled"
 	}
 
-	var req struct {
-		Names   []string `json:"names"`
-		Enabled bool     `json:"enabled"`
+	if req.Enabled {
+		var enabling []config.Endpoint
+		for _, ep := range s.configManager.GetEndpoints() {
+			if !ep.Enabled && (ep.HasTag(req.Tag) || req.Tag == "" && slices.Contains(req.Names, ep.Name)) {
+				enabling = append(enabling, ep)
+			}
+		}
+		if !s.confirmEnabling(w, r, enabling) {
+			return
+		}
 	}
 
-	if err := readJSON(r, &req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+	if req.Tag != "" {
+		updated := s.configManager.SetEndpointsEnabledByTag(req.Tag, req.Enabled)
+		if len(updated) == 0 {
+			writeError(w, "no endpoints tagged "+req.Tag, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"status":  "success",
+			"message": "Bulk " + action + " completed for tag " + req.Tag,
+			"tag":     req.Tag,
+			"updated": updated,
+			"summary": map[string]int{
+				"updated": len(updated),
+			},
+		})
 		return
 	}
 
@@ -367,11 +783,6 @@ func (s *Server) handleBulkEndpointEnable(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	action := "disabled"
-	if req.Enabled {
-		action = "enabled"
-	}
-
 	writeJSON(w, map[string]interface{}{
 		"status":  "success",
 		"message": "Bulk " + action + " completed",
@@ -385,28 +796,57 @@ func (s *Server) handleBulkEndpointEnable(w http.ResponseWriter, r *http.Request
 	})
 }
 
-// handleEnableAll enables or disables all endpoints
-func (s *Server) handleEnableAll(w http.ResponseWriter, r *http.Request) {
-	if s.configManager == nil {
-		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+// handleGetBackpressure reports how far each endpoint falls behind its
+// configured schedule
+// GET /api/outgoing/control/backpressure
+func (s *Server) handleGetBackpressure(w http.ResponseWriter, r *http.Request) {
+	if !s.checkScheduler(w) {
 		return
 	}
-
-	if r.Method != http.MethodPost {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
+	stats := s.scheduler.GetStats()
+	endpoints := s.scheduler.GetBackpressure()
+	var missed int64
+	for _, ep := range endpoints {
+		missed += ep.MissedIntervals
 	}
 
-	var req struct {
-		Enabled bool `json:"enabled"`
-	}
+	writeJSON(w, map[string]interface{}{
+		"saturated":          stats.RequestsWaiting > 0,
+		"requests_in_flight": stats.RequestsInFlight,
+		"requests_waiting":   stats.RequestsWaiting,
+		"requests_capped":    stats.RequestsCapped,
+		"requests_starved":   stats.RequestsStarved,
+		"missed_intervals":   missed,
+		"endpoints":          endpoints,
+	})
+}
+
+// enabledRequest enables or disables everything of a kind: all endpoints or incoming routes
+type enabledRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// handleEnableAll enables or disables all endpoints
+func (s *Server) handleEnableAll(w http.ResponseWriter, r *http.Request) {
+	var req enabledRequest
 
 	if err := readJSON(r, &req); err != nil {
-		writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		writeInvalidJSON(w, err)
 		return
 	}
 
 	endpoints := s.configManager.GetEndpoints()
+	if req.Enabled {
+		var enabling []config.Endpoint
+		for _, ep := range endpoints {
+			if !ep.Enabled {
+				enabling = append(enabling, ep)
+			}
+		}
+		if !s.confirmEnabling(w, r, enabling) {
+			return
+		}
+	}
 	var updated int
 
 	for _, ep := range endpoints {
@@ -428,16 +868,51 @@ func (s *Server) handleEnableAll(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// newlyEnabled returns the endpoints of after that are enabled but aren't
+// now, because they are new or currently disabled
+func (s *Server) newlyEnabled(after []config.Endpoint) []config.Endpoint {
+	current := make(map[string]bool)
+	for _, ep := range s.configManager.GetEndpoints() {
+		current[ep.Name] = ep.Enabled
+	}
+	var enabling []config.Endpoint
+	for _, ep := range after {
+		if ep.Enabled && !current[ep.Name] {
+			enabling = append(enabling, ep)
+		}
+	}
+	return enabling
+}
+
+// confirmEnabling returns true if enabling endpoints at once is allowed
+// without confirmation, or was confirmed (see confirmed)
+func (s *Server) confirmEnabling(w http.ResponseWriter, r *http.Request, enabling []config.Endpoint) bool {
+	guarded := s.configManager.GetGuardedActionsConfig()
+	if !guarded.GuardsEnabling(len(enabling)) {
+		return true
+	}
+
+	names := make([]string, len(enabling))
+	for i, ep := range enabling {
+		names[i] = ep.Name
+	}
+	sort.Strings(names)
+	reason := fmt.Sprintf("enabling more than %d endpoints at once needs confirmation", guarded.MaxEnabledAtOnce)
+	// The digest ties the token to these endpoints without listing hundreds
+	digest := sha256.Sum256([]byte(strings.Join(names, "\n")))
+	action := fmt.Sprintf("enable %d endpoints (%s)", len(names), hex.EncodeToString(digest[:6]))
+	return s.confirmed(w, r, action, reason)
+}
+
 // --- Settings Handlers ---
 
 // handleGetSettings returns current runtime settings
 func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	cfg := s.getConfigForHandlers()
+	percentiles := cfg.Percentiles
+	if len(percentiles) == 0 {
+		percentiles = metrics.DefaultPercentiles
+	}
 
 	settings := map[string]interface{}{
 		"global_multiplier":   cfg.GlobalMultiplier,
@@ -445,134 +920,239 @@ func (s *Server) handleGetSettings(w http.ResponseWriter, r *http.Request) {
 		"log_all_requests":    cfg.LogAllRequests,
 		"api_port":            cfg.APIPort,
 		"enabled":             cfg.Enabled,
+		"ip_family":           cfg.IPFamily,
+		"guardrails":          cfg.Guardrails,
+		"environment":         cfg.Environment,
+		"percentiles":         percentiles,
 	}
 
 	writeJSON(w, settings)
 }
 
+// multiplierRequest sets the global load multiplier
+type multiplierRequest struct {
+	Multiplier float64 `json:"multiplier"`
+}
+
+// handleGetMultiplier returns the global load multiplier
+// GET /api/outgoing/settings/multiplier
+func (s *Server) handleGetMultiplier(w http.ResponseWriter, r *http.Request) {
+	cfg := s.getConfigForHandlers()
+	writeJSON(w, map[string]interface{}{
+		"global_multiplier": cfg.GlobalMultiplier,
+	})
+}
+
 // handleSetMultiplier updates the global load multiplier
+// POST/PUT /api/outgoing/settings/multiplier
 func (s *Server) handleSetMultiplier(w http.ResponseWriter, r *http.Request) {
-	if s.configManager == nil {
-		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+	var req multiplierRequest
+
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		cfg := s.getConfigForHandlers()
-		writeJSON(w, map[string]interface{}{
-			"global_multiplier": cfg.GlobalMultiplier,
-		})
-
-	case http.MethodPost, http.MethodPut:
-		var req struct {
-			Multiplier float64 `json:"multiplier"`
-		}
-
-		if err := readJSON(r, &req); err != nil {
-			writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+	if req.Multiplier < 0 {
+		writeError(w, "multiplier must be non-negative", http.StatusBadRequest)
+		return
+	}
 
-		if req.Multiplier < 0 {
-			writeError(w, "multiplier must be non-negative", http.StatusBadRequest)
+	oldMultiplier := s.configManager.GetConfig().GlobalMultiplier
+	guarded := s.configManager.GetGuardedActionsConfig()
+	if guarded.GuardsMultiplier(oldMultiplier, req.Multiplier) {
+		reason := fmt.Sprintf("raising the global multiplier above %g needs confirmation", guarded.MaxMultiplier)
+		if !s.confirmed(w, r, fmt.Sprintf("set global multiplier to %g", req.Multiplier), reason) {
 			return
 		}
+	}
+	if err := s.configManager.SetGlobalMultiplier(req.Multiplier); err != nil {
+		writeConfigError(w, err, nil)
+		return
+	}
 
-		oldMultiplier := s.configManager.GetConfig().GlobalMultiplier
-		s.configManager.SetGlobalMultiplier(req.Multiplier)
+	writeJSON(w, map[string]interface{}{
+		"status":         "success",
+		"message":        "Global multiplier updated",
+		"old_multiplier": oldMultiplier,
+		"new_multiplier": req.Multiplier,
+	})
+}
 
-		writeJSON(w, map[string]interface{}{
-			"status":         "success",
-			"message":        "Global multiplier updated",
-			"old_multiplier": oldMultiplier,
-			"new_multiplier": req.Multiplier,
-		})
+// groupMultipliersRequest sets the multipliers of endpoint groups by name
+type groupMultipliersRequest struct {
+	Multipliers map[string]float64 `json:"multipliers"`
+}
 
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
-	}
+// handleGetGroupMultipliers returns the global multiplier and those of the
+// endpoint groups layered on top of it
+// GET /api/outgoing/settings/multipliers
+func (s *Server) handleGetGroupMultipliers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"global_multiplier": s.configManager.GetConfig().GlobalMultiplier,
+		"multipliers":       s.configManager.GetGroupMultipliers(),
+	})
 }
 
-// handleSetConcurrency updates the concurrent requests limit
-func (s *Server) handleSetConcurrency(w http.ResponseWriter, r *http.Request) {
-	if s.configManager == nil {
-		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+// handleSetGroupMultipliers updates the multipliers of endpoint groups; groups
+// left out keep theirs
+// POST/PUT /api/outgoing/settings/multipliers
+func (s *Server) handleSetGroupMultipliers(w http.ResponseWriter, r *http.Request) {
+	var req groupMultipliersRequest
+
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		cfg := s.getConfigForHandlers()
-		writeJSON(w, map[string]interface{}{
-			"concurrent_requests": cfg.ConcurrentRequests,
-		})
+	if len(req.Multipliers) == 0 {
+		writeError(w, "multipliers is required", http.StatusBadRequest)
+		return
+	}
 
-	case http.MethodPost, http.MethodPut:
-		var req struct {
-			Concurrent int `json:"concurrent"`
+	oldMultipliers := s.configManager.GetGroupMultipliers()
+	guarded := s.configManager.GetGuardedActionsConfig()
+	var raised []string
+	for name, multiplier := range req.Multipliers {
+		if current, ok := oldMultipliers[name]; ok && guarded.GuardsMultiplier(current, multiplier) {
+			raised = append(raised, fmt.Sprintf("%s to %g", name, multiplier))
 		}
-
-		if err := readJSON(r, &req); err != nil {
-			writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+	}
+	if len(raised) > 0 {
+		sort.Strings(raised)
+		reason := fmt.Sprintf("raising a group multiplier above %g needs confirmation", guarded.MaxMultiplier)
+		if !s.confirmed(w, r, "set group multipliers "+strings.Join(raised, ", "), reason) {
 			return
 		}
+	}
+	if err := s.configManager.SetGroupMultipliers(req.Multipliers); err != nil {
+		writeConfigError(w, err, nil)
+		return
+	}
 
-		if req.Concurrent <= 0 {
-			writeError(w, "concurrent must be positive", http.StatusBadRequest)
-			return
-		}
+	writeJSON(w, map[string]interface{}{
+		"status":          "success",
+		"message":         "Group multipliers updated",
+		"old_multipliers": oldMultipliers,
+		"new_multipliers": s.configManager.GetGroupMultipliers(),
+	})
+}
 
-		oldConcurrent := s.configManager.GetConfig().ConcurrentRequests
-		s.configManager.SetConcurrentRequests(req.Concurrent)
+// environmentRequest switches the active environment
+type environmentRequest struct {
+	Environment string `json:"environment"`
+}
 
-		writeJSON(w, map[string]interface{}{
-			"status":         "success",
-			"message":        "Concurrent requests limit updated",
-			"old_concurrent": oldConcurrent,
-			"new_concurrent": req.Concurrent,
-		})
+// handleGetEnvironment returns the active environment and the defined ones
+// GET /api/outgoing/settings/environment
+func (s *Server) handleGetEnvironment(w http.ResponseWriter, r *http.Request) {
+	active, environments := s.configManager.GetEnvironments()
+	writeJSON(w, map[string]interface{}{
+		"environment":  active,
+		"environments": environments,
+	})
+}
 
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+// handleSetEnvironment switches the whole test to another environment
+// POST/PUT /api/outgoing/settings/environment
+func (s *Server) handleSetEnvironment(w http.ResponseWriter, r *http.Request) {
+	var req environmentRequest
+
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+
+	if req.Environment == "" {
+		writeError(w, "environment is required", http.StatusBadRequest)
+		return
+	}
+
+	oldEnvironment, _ := s.configManager.GetEnvironments()
+	if err := s.configManager.SetEnvironment(req.Environment); err != nil {
+		writeConfigError(w, err, nil)
+		return
 	}
+
+	writeJSON(w, map[string]interface{}{
+		"status":          "success",
+		"message":         "Environment switched",
+		"old_environment": oldEnvironment,
+		"new_environment": req.Environment,
+	})
 }
 
-// handleSetLogRequests updates the log all requests setting
-func (s *Server) handleSetLogRequests(w http.ResponseWriter, r *http.Request) {
-	if s.configManager == nil {
-		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
+// concurrencyRequest sets the concurrent requests limit
+type concurrencyRequest struct {
+	Concurrent int `json:"concurrent"`
+}
+
+// handleGetConcurrency returns the concurrent requests limit
+// GET /api/outgoing/settings/concurrency
+func (s *Server) handleGetConcurrency(w http.ResponseWriter, r *http.Request) {
+	cfg := s.getConfigForHandlers()
+	writeJSON(w, map[string]interface{}{
+		"concurrent_requests": cfg.ConcurrentRequests,
+	})
+}
+
+// handleSetConcurrency updates the concurrent requests limit
+// POST/PUT /api/outgoing/settings/concurrency
+func (s *Server) handleSetConcurrency(w http.ResponseWriter, r *http.Request) {
+	var req concurrencyRequest
+
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		cfg := s.getConfigForHandlers()
-		writeJSON(w, map[string]interface{}{
-			"log_all_requests": cfg.LogAllRequests,
-		})
+	if req.Concurrent <= 0 {
+		writeError(w, "concurrent must be positive", http.StatusBadRequest)
+		return
+	}
 
-	case http.MethodPost, http.MethodPut:
-		var req struct {
-			LogRequests bool `json:"log_requests"`
-		}
+	oldConcurrent := s.configManager.GetConfig().ConcurrentRequests
+	s.configManager.SetConcurrentRequests(req.Concurrent)
 
-		if err := readJSON(r, &req); err != nil {
-			writeError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+	writeJSON(w, map[string]interface{}{
+		"status":         "success",
+		"message":        "Concurrent requests limit updated (applied to the scheduler immediately)",
+		"old_concurrent": oldConcurrent,
+		"new_concurrent": req.Concurrent,
+	})
+}
 
-		oldValue := s.configManager.GetConfig().LogAllRequests
-		s.configManager.SetLogAllRequests(req.LogRequests)
+// logRequestsRequest turns logging of every request on or off
+type logRequestsRequest struct {
+	LogRequests bool `json:"log_requests"`
+}
 
-		writeJSON(w, map[string]interface{}{
-			"status":           "success",
-			"message":          "Log all requests setting updated",
-			"old_log_requests": oldValue,
-			"new_log_requests": req.LogRequests,
-		})
+// handleGetLogRequests returns whether every request is logged
+// GET /api/outgoing/settings/log-requests
+func (s *Server) handleGetLogRequests(w http.ResponseWriter, r *http.Request) {
+	cfg := s.getConfigForHandlers()
+	writeJSON(w, map[string]interface{}{
+		"log_all_requests": cfg.LogAllRequests,
+	})
+}
 
-	default:
-		writeError(w, "method not allowed", http.StatusMethodNotAllowed)
+// handleSetLogRequests updates the log all requests setting
+// POST/PUT /api/outgoing/settings/log-requests
+func (s *Server) handleSetLogRequests(w http.ResponseWriter, r *http.Request) {
+	var req logRequestsRequest
+
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
+		return
 	}
+
+	oldValue := s.configManager.GetConfig().LogAllRequests
+	s.configManager.SetLogAllRequests(req.LogRequests)
+
+	writeJSON(w, map[string]interface{}{
+		"status":           "success",
+		"message":          "Log all requests setting updated",
+		"old_log_requests": oldValue,
+		"new_log_requests": req.LogRequests,
+	})
 }
@@ -0,0 +1,122 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// scheduleCallback fires the route's webhook after its delay, without holding
+// up the response to the incoming request
+func (s *Server) scheduleCallback(route *config.IncomingEndpoint, echo EchoResponse) {
+	callback := route.Callback
+	data := incomingRequestData(route.Name, echo)
+	logRequests := s.configManager.GetConfig().LogAllRequests
+
+	time.AfterFunc(time.Duration(callback.DelayMs)*time.Millisecond, func() {
+		status, err := sendCallback(callback, data)
+
+		if s.incomingMetrics != nil {
+			s.incomingMetrics.RecordCallback(route.Name, route.Path, err)
+		}
+		if logRequests {
+			if err != nil {
+				fmt.Printf("\r[CALLBACK] %s -> %s failed: %v\n", route.Name, config.RedactURL(callback.URL), err)
+			} else {
+				fmt.Printf("\r[CALLBACK] %s -> %s %d\n", route.Name, config.RedactURL(callback.URL), status)
+			}
+		}
+	})
+}
+
+// incomingRequestData converts an echoed request into callback template data
+func incomingRequestData(routeName string, echo EchoResponse) *config.IncomingRequestData {
+	data := &config.IncomingRequestData{
+		Route:      routeName,
+		Method:     echo.Request.Method,
+		Path:       echo.Request.Path,
+		PathSuffix: echo.Request.PathSuffix,
+		Headers:    make(map[string]string, len(echo.Request.Headers)),
+		Query:      make(map[string]string, len(echo.Request.QueryParams)),
+		Body:       echo.Request.Body,
+		Status:     echo.Response.Status,
+	}
+	for key, values := range echo.Request.Headers {
+		if len(values) > 0 {
+			data.Headers[key] = values[0]
+		}
+	}
+	for key, values := range echo.Request.QueryParams {
+		if len(values) > 0 {
+			data.Query[key] = values[0]
+		}
+	}
+	return data
+}
+
+// sendCallback renders and sends a callback request. Non-2xx responses are
+// reported as errors.
+func sendCallback(callback *config.IncomingCallback, data *config.IncomingRequestData) (int, error) {
+	rc := &config.RequestContext{RequestID: newCallbackID(), Sequence: 1, Incoming: data}
+
+	url, err := config.EvaluateRequestTemplate(callback.URL, rc)
+	if err != nil {
+		return 0, fmt.Errorf("url template: %w", err)
+	}
+
+	var body io.Reader
+	if callback.Body != nil {
+		evaluated, err := config.EvaluateRequestBodyTemplate(callback.Body, rc)
+		if err != nil {
+			return 0, fmt.Errorf("body template: %w", err)
+		}
+		payload, err := json.Marshal(evaluated)
+		if err != nil {
+			return 0, fmt.Errorf("body marshal: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(callback.CallbackMethod(), url, body)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range callback.Headers {
+		evaluated, err := config.EvaluateRequestTemplate(value, rc)
+		if err != nil {
+			return 0, fmt.Errorf("header %s template: %w", key, err)
+		}
+		req.Header.Set(key, evaluated)
+	}
+
+	client := &http.Client{Timeout: time.Duration(callback.CallbackTimeout()) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// newCallbackID returns a random ID exposed to callback templates as {{requestID}}
+func newCallbackID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
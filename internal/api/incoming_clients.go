@@ -0,0 +1,64 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	"moxapp/internal/config"
+)
+
+// unknownClient identifies callers without the configured identity header
+const unknownClient = "unknown"
+
+// recordClient attributes an incoming route request to its caller when
+// incoming_clients is enabled
+func (s *Server) recordClient(r *http.Request, route *config.IncomingEndpoint, statusCode int) {
+	if s.incomingMetrics == nil || s.configManager == nil {
+		return
+	}
+	clients := s.configManager.GetIncomingClientsConfig()
+	if !clients.Enabled {
+		return
+	}
+	s.incomingMetrics.RecordClient(clientIdentity(r, clients), route.Name, statusCode, clients.MaxClients)
+}
+
+// clientIdentity returns the caller of a request: its remote IP, or the
+// configured header. The first comma-separated header value is used so
+// X-Forwarded-For works; secret values such as API keys are hashed.
+func clientIdentity(r *http.Request, clients config.IncomingClientsConfig) string {
+	if clients.Source != config.IncomingClientSourceHeader {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	}
+
+	value := strings.TrimSpace(strings.Split(r.Header.Get(clients.Header), ",")[0])
+	if value == "" {
+		return unknownClient
+	}
+	if config.IsSensitiveKey(clients.Header) {
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	return value
+}
+
+// handleGetIncomingClientMetrics returns incoming route metrics per caller
+func (s *Server) handleGetIncomingClientMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.incomingMetrics == nil || s.configManager == nil {
+		writeError(w, "incoming metrics not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"config":  s.configManager.GetIncomingClientsConfig(),
+		"clients": s.incomingMetrics.GetClientMetrics(),
+	})
+}
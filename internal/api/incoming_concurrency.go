@@ -0,0 +1,146 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// workerPool is the simulated worker pool of one incoming route
+type workerPool struct {
+	limit   config.IncomingConcurrency // Settings the pool was created with
+	workers chan struct{}              // Holds a token per busy worker
+
+	mu     sync.Mutex
+	queued int // Requests waiting for a worker
+}
+
+// incomingPools holds a worker pool per concurrency-limited incoming route
+type incomingPools struct {
+	mu    sync.Mutex
+	pools map[string]*workerPool // keyed by route name
+}
+
+// newIncomingPools creates an empty pool set
+func newIncomingPools() *incomingPools {
+	return &incomingPools{pools: make(map[string]*workerPool)}
+}
+
+// pool returns the route's worker pool. A route whose limit was edited gets
+// a new pool; requests still holding workers of the old one release them there.
+func (p *incomingPools) pool(routeName string, limit config.IncomingConcurrency) *workerPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pool, exists := p.pools[routeName]
+	if !exists || pool.limit != limit {
+		pool = &workerPool{limit: limit, workers: make(chan struct{}, limit.MaxConcurrent)}
+		p.pools[routeName] = pool
+	}
+	return pool
+}
+
+// acquire takes a worker, queueing if all are busy and the queue has room.
+// It returns whether a worker was taken and whether the request was queued.
+func (p *workerPool) acquire(ctx context.Context, onQueueChange func(delta int)) (bool, bool) {
+	select {
+	case p.workers <- struct{}{}:
+		return true, false
+	default:
+	}
+
+	p.mu.Lock()
+	if p.queued >= p.limit.QueueSize {
+		p.mu.Unlock()
+		return false, false
+	}
+	p.queued++
+	p.mu.Unlock()
+	onQueueChange(1)
+
+	defer func() {
+		p.mu.Lock()
+		p.queued--
+		p.mu.Unlock()
+		onQueueChange(-1)
+	}()
+
+	var timeout <-chan time.Time
+	if p.limit.QueueTimeoutMs > 0 {
+		timer := time.NewTimer(time.Duration(p.limit.QueueTimeoutMs) * time.Millisecond)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case p.workers <- struct{}{}:
+		return true, true
+	case <-timeout:
+		return false, true
+	case <-ctx.Done():
+		return false, true
+	}
+}
+
+// release frees a worker
+func (p *workerPool) release() {
+	<-p.workers
+}
+
+// inFlight returns the number of busy workers
+func (p *workerPool) inFlight() int {
+	return len(p.workers)
+}
+
+// acquireWorker takes a worker of the route's pool, writing a 503 when the
+// pool and its queue are full. It returns the release function and how long
+// the request waited in the queue.
+func (s *Server) acquireWorker(w http.ResponseWriter, r *http.Request, route *config.IncomingEndpoint) (func(), time.Duration, bool) {
+	pool := s.incomingPools.pool(route.Name, *route.Concurrency)
+
+	onQueueChange := func(delta int) {
+		if s.incomingMetrics != nil {
+			s.incomingMetrics.AddQueued(route.Name, route.Path, delta)
+		}
+	}
+
+	start := time.Now()
+	acquired, queued := pool.acquire(r.Context(), onQueueChange)
+	if !acquired {
+		if s.incomingMetrics != nil {
+			s.incomingMetrics.RecordOverloaded(route.Name, route.Path)
+		}
+		s.recordClient(r, route, http.StatusServiceUnavailable)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		writeJSON(w, map[string]interface{}{
+			"error":          "all workers busy",
+			"route":          route.Name,
+			"max_concurrent": route.Concurrency.MaxConcurrent,
+			"in_flight":      pool.inFlight(),
+		})
+		return nil, 0, false
+	}
+	var waited time.Duration
+	if queued {
+		waited = time.Since(start)
+	}
+
+	if s.incomingMetrics != nil {
+		if queued {
+			s.incomingMetrics.RecordQueueWait(route.Name, route.Path, float64(waited.Microseconds())/1000)
+		}
+		s.incomingMetrics.AddInFlight(route.Name, route.Path, 1)
+	}
+	release := func() {
+		pool.release()
+		if s.incomingMetrics != nil {
+			s.incomingMetrics.AddInFlight(route.Name, route.Path, -1)
+		}
+	}
+	return release, waited, true
+}
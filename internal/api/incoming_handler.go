@@ -2,6 +2,7 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -46,6 +47,7 @@ type RequestEcho struct {
 type ResponseInfo struct {
 	Status           int     `json:"status"`
 	SimulatedDelayMs float64 `json:"simulated_delay_ms"`
+	QueueWaitMs      float64 `json:"queue_wait_ms,omitempty"` // Time waiting for a worker of a concurrency-limited route
 }
 
 // handleSimulatedRoute handles all requests to /sim/* and routes them to configured incoming routes
@@ -69,6 +71,27 @@ func (s *Server) handleSimulatedRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Simulate the route's rate limit before anything else
+	if route.RateLimit != nil && s.rejectRateLimited(w, r, route) {
+		return
+	}
+
+	// Reject requests that break the route's contract before simulating
+	if route.Validation != nil && s.rejectInvalidRequest(w, r, route) {
+		return
+	}
+
+	// Wait for a worker of the route's simulated worker pool
+	var queueWait time.Duration
+	if route.Concurrency != nil {
+		release, waited, ok := s.acquireWorker(w, r, route)
+		if !ok {
+			return
+		}
+		defer release()
+		queueWait = waited
+	}
+
 	// Select response based on weighted probability
 	selectedResponse := selectWeightedResponse(route.Responses)
 
@@ -80,13 +103,16 @@ func (s *Server) handleSimulatedRoute(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(time.Duration(delayMs) * time.Millisecond)
 	}
 
-	// Record metrics
+	// Record metrics (time spent queueing counts towards the response time)
+	queueWaitMs := float64(queueWait.Microseconds()) / 1000
 	if s.incomingMetrics != nil {
-		s.incomingMetrics.Record(route.Name, route.Path, selectedResponse.StatusCode, float64(delayMs))
+		s.incomingMetrics.Record(route.Name, route.Path, selectedResponse.StatusCode, float64(delayMs)+queueWaitMs)
 	}
+	s.recordClient(r, route, selectedResponse.StatusCode)
 
 	// Build echo response
 	echoResponse := buildEchoResponse(r, route, path, pathSuffix, selectedResponse.StatusCode, float64(delayMs))
+	echoResponse.Response.QueueWaitMs = queueWaitMs
 
 	// Log if enabled
 	if s.configManager.GetConfig().LogAllRequests {
@@ -96,6 +122,45 @@ func (s *Server) handleSimulatedRoute(w http.ResponseWriter, r *http.Request) {
 	// Write response
 	w.WriteHeader(selectedResponse.StatusCode)
 	writeJSON(w, echoResponse)
+
+	// Call back into the system under test asynchronously
+	if route.Callback != nil && route.Callback.FiresOn(selectedResponse.StatusCode) {
+		s.scheduleCallback(route, echoResponse)
+	}
+}
+
+// rejectInvalidRequest checks a request against the route's validation rules
+// and writes the rejection if it fails. The body is restored for echoing.
+func (s *Server) rejectInvalidRequest(w http.ResponseWriter, r *http.Request, route *config.IncomingEndpoint) bool {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, "failed to read request body", http.StatusBadRequest)
+			return true
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	violations := route.Validation.Check(r.Header, r.URL.Query(), body)
+	if len(violations) == 0 {
+		return false
+	}
+
+	status := route.Validation.RejectStatus()
+	if s.incomingMetrics != nil {
+		s.incomingMetrics.RecordInvalid(route.Name, route.Path, status, violations)
+	}
+	s.recordClient(r, route, status)
+
+	w.WriteHeader(status)
+	writeJSON(w, map[string]interface{}{
+		"error":      "request validation failed",
+		"route":      route.Name,
+		"violations": violations,
+	})
+	return true
 }
 
 // selectWeightedResponse selects a response based on weighted probability (share)
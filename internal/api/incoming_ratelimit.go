@@ -0,0 +1,115 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// tokenBucket is the rate limit state of one incoming route
+type tokenBucket struct {
+	limit  config.IncomingRateLimit // Settings the bucket was created with
+	tokens float64
+	last   time.Time
+}
+
+// incomingLimiters holds a token bucket per rate-limited incoming route
+type incomingLimiters struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket // keyed by route name
+}
+
+// newIncomingLimiters creates an empty limiter set
+func newIncomingLimiters() *incomingLimiters {
+	return &incomingLimiters{buckets: make(map[string]*tokenBucket)}
+}
+
+// allow takes a token for the route. When none is left it returns false and
+// how long until the next request would be allowed.
+func (l *incomingLimiters) allow(routeName string, limit config.IncomingRateLimit, now time.Time) (bool, time.Duration) {
+	return l.allowAll([]string{routeName}, limit, now)
+}
+
+// allowAll takes a token from the bucket of each key, or from none of them
+// when any is empty, so a request denied by one bucket isn't charged to the
+// others. When denied it returns how long until every bucket has a token.
+func (l *incomingLimiters) allowAll(keys []string, limit config.IncomingRateLimit, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	burst := float64(limit.BurstSize())
+	buckets := make([]*tokenBucket, len(keys))
+	wait := 0.0
+	for i, key := range keys {
+		// A key whose limit was edited starts over with a full bucket
+		bucket, exists := l.buckets[key]
+		if !exists || bucket.limit != limit {
+			bucket = &tokenBucket{limit: limit, tokens: burst, last: now}
+			l.buckets[key] = bucket
+		}
+
+		bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*limit.RequestsPerSecond)
+		bucket.last = now
+		if bucket.tokens < 1 {
+			wait = math.Max(wait, (1-bucket.tokens)/limit.RequestsPerSecond)
+		}
+		buckets[i] = bucket
+	}
+
+	if wait > 0 {
+		return false, time.Duration(wait * float64(time.Second))
+	}
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return true, 0
+}
+
+// prune drops the buckets unused for longer than idle. They have refilled
+// since, so they would start over full anyway.
+func (l *incomingLimiters) prune(idle time.Duration, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rejectRateLimited applies the route's rate limit and writes a 429 with
+// Retry-After when it is exceeded
+func (s *Server) rejectRateLimited(w http.ResponseWriter, r *http.Request, route *config.IncomingEndpoint) bool {
+	allowed, wait := s.incomingLimits.allow(route.Name, *route.RateLimit, time.Now())
+	if allowed {
+		return false
+	}
+
+	retryAfter := route.RateLimit.RetryAfterSeconds
+	if retryAfter == 0 {
+		retryAfter = int(math.Ceil(wait.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+	}
+
+	if s.incomingMetrics != nil {
+		s.incomingMetrics.RecordRateLimited(route.Name, route.Path)
+	}
+	s.recordClient(r, route, http.StatusTooManyRequests)
+
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	writeJSON(w, map[string]interface{}{
+		"error":               "rate limit exceeded",
+		"route":               route.Name,
+		"retry_after_seconds": retryAfter,
+	})
+	return true
+}
@@ -3,31 +3,41 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"net/url"
 	"strings"
-	"time"
-)
-
-// loggingMiddleware logs incoming requests
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		// Call the next handler
-		next.ServeHTTP(w, r)
 
-		// Log the request
-		log.Printf("[API] %s %s %s", r.Method, r.URL.Path, time.Since(start))
-	})
-}
+	"moxapp/internal/config"
+)
 
-// corsMiddleware adds CORS headers
-func corsMiddleware(next http.Handler) http.Handler {
+// corsMiddleware adds CORS headers following the configured policy. With
+// allowed origins configured, requests from other origins are rejected,
+// except from the API's own origin.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		h := w.Header()
+		if s.cors.AllowsAnyOrigin() {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Add("Vary", "Origin")
+			if origin := r.Header.Get("Origin"); origin != "" {
+				if !s.cors.AllowsOrigin(origin) && !sameOrigin(r, origin) {
+					h.Set("Content-Type", "application/json")
+					writeError(w, "origin not allowed: "+origin, http.StatusForbidden)
+					return
+				}
+				h.Set("Access-Control-Allow-Origin", origin)
+				if s.cors.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+		}
+		headers := s.cors.AllowedHeaders
+		if len(headers) == 0 {
+			headers = config.DefaultCORSHeaders
+		}
+		h.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
@@ -39,6 +49,13 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// sameOrigin reports whether an Origin header names the host the request was
+// sent to, as the embedded web UI does
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host)
+}
+
 // jsonMiddleware sets JSON content type for API routes
 func jsonMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,14 +73,6 @@ func writeJSON(w http.ResponseWriter, data interface{}) {
 	}
 }
 
-// writeError writes a JSON error response
-func writeError(w http.ResponseWriter, message string, statusCode int) {
-	w.WriteHeader(statusCode)
-	writeJSON(w, map[string]string{
-		"error": message,
-	})
-}
-
 // readJSON reads and decodes JSON from request body
 func readJSON(r *http.Request, v interface{}) error {
 	return json.NewDecoder(r.Body).Decode(v)
@@ -0,0 +1,239 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"moxapp/internal/config"
+)
+
+// openAPIDescription introduces the API in the generated document
+const openAPIDescription = `High-performance concurrent HTTP load testing tool with DNS timing metrics.
+
+This API provides endpoints for:
+- **Outgoing Traffic Management**: Configure and control external API calls
+- **Incoming Traffic Simulation**: Define mock routes with configurable response patterns
+- **Metrics & Monitoring**: Real-time metrics collection and health monitoring
+- **Scheduler Control**: Pause, resume, or emergency stop the load test
+
+Errors are answered with a JSON envelope, {"error": {"code", "message", "details"}}.
+Clients should branch on the code, such as ENDPOINT_NOT_FOUND or VALIDATION_FAILED,
+rather than on the message.
+
+This document is generated from the routes the server registers.`
+
+// tagDescriptions describe the operation groups of the API docs
+var tagDescriptions = map[string]string{
+	"Health":             "Health check and system status",
+	"Documentation":      "API documentation",
+	"Metrics":            "Traffic metrics collection (outgoing and incoming)",
+	"Alerts":             "Alert rule states",
+	"Outgoing Settings":  "Outgoing traffic runtime settings (multiplier, concurrency, logging)",
+	"Outgoing Endpoints": "Outgoing endpoint CRUD operations",
+	"Endpoint Groups":    "Endpoint groups sharing a request budget",
+	"Outgoing Control":   "Outgoing scheduler control (pause/resume/emergency stop)",
+	"Auth Configs":       "Authentication configuration management for outgoing endpoints",
+	"Config":             "Import and export full runtime configuration",
+	"Runs":               "Run history",
+	"Captured Responses": "Sampled response bodies",
+	"Incoming Routes":    "Incoming route simulation management",
+	"Incoming Control":   "Incoming routes enable/disable control",
+	"Simulated Routes":   "Simulated incoming route execution",
+}
+
+// anyMethods are documented for operations serving any method
+var anyMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// pathParam matches the {param} placeholders of operation paths
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPISpec is an OpenAPI document, with its sections in the usual order
+type openAPISpec struct {
+	OpenAPI    string                 `yaml:"openapi" json:"openapi"`
+	Info       map[string]interface{} `yaml:"info" json:"info"`
+	Servers    []interface{}          `yaml:"servers" json:"servers"`
+	Tags       []interface{}          `yaml:"tags" json:"tags"`
+	Paths      map[string]interface{} `yaml:"paths" json:"paths"`
+	Components map[string]interface{} `yaml:"components" json:"components"`
+}
+
+// openAPIDocument builds the OpenAPI document of the registered routes, with
+// the request and response bodies described by their Go types
+func (s *Server) openAPIDocument(serverURL string) *openAPISpec {
+	b := &openAPIBuilder{
+		components: map[string]interface{}{
+			"Error": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"error"},
+				"properties": map[string]interface{}{"error": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"code", "message"},
+					"properties": map[string]interface{}{
+						"code":    map[string]interface{}{"type": "string", "enum": errorCodes, "description": "Machine-readable error code"},
+						"message": map[string]interface{}{"type": "string", "description": "Human-readable description"},
+						"details": map[string]interface{}{"description": "Code-specific details, such as the errors and field_errors of VALIDATION_FAILED"},
+					},
+				}},
+			},
+		},
+		kinds: map[reflect.Type]map[string]interface{}{},
+	}
+	schemas := config.Schemas()
+	b.kinds[reflect.TypeOf(config.EndpointRequest{})] = schemas[config.SchemaEndpoint]
+	b.kinds[reflect.TypeOf(config.AuthConfig{})] = schemas[config.SchemaAuthConfig]
+	b.kinds[reflect.TypeOf(config.IncomingEndpointRequest{})] = schemas[config.SchemaIncomingRoute]
+
+	paths := map[string]interface{}{}
+	var tags []interface{}
+	seenTags := map[string]bool{}
+	for _, group := range s.apiRoutes {
+		if !seenTags[group.tag] {
+			seenTags[group.tag] = true
+			tags = append(tags, map[string]interface{}{"name": group.tag, "description": tagDescriptions[group.tag]})
+		}
+		for _, op := range group.operations {
+			if op.undocumented {
+				continue
+			}
+			path := op.docPath()
+			item, ok := paths[path].(map[string]interface{})
+			if !ok {
+				item = map[string]interface{}{}
+				paths[path] = item
+			}
+			methods := []string{strings.ToLower(op.method)}
+			if op.method == "*" {
+				methods = anyMethods
+			}
+			for _, method := range methods {
+				item[method] = b.operation(group.tag, method, path, op)
+			}
+		}
+	}
+
+	return &openAPISpec{
+		OpenAPI: "3.1.0",
+		Info: map[string]interface{}{
+			"title":       "MoxApp API",
+			"description": openAPIDescription,
+			"version":     "1.0.0",
+		},
+		Servers:    []interface{}{map[string]interface{}{"url": serverURL, "description": "Current server"}},
+		Tags:       tags,
+		Paths:      paths,
+		Components: map[string]interface{}{"schemas": b.components},
+	}
+}
+
+// openAPIBuilder collects the schemas of the body types the operations
+// reference
+type openAPIBuilder struct {
+	components map[string]interface{}
+	kinds      map[reflect.Type]map[string]interface{} // Schemas of the config request bodies, with their validation rules
+}
+
+// operation returns the OpenAPI operation object of an operation at its
+// documented path
+func (b *openAPIBuilder) operation(tag, method, path string, op operation) map[string]interface{} {
+	parameters := []interface{}{}
+	for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name": m[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, name := range op.queryParams {
+		parameters = append(parameters, map[string]interface{}{
+			"name": name, "in": "query", "schema": map[string]interface{}{"type": "string"},
+		})
+	}
+
+	contentType := op.contentType
+	schema := map[string]interface{}{"type": "string"}
+	if contentType == "" {
+		contentType = "application/json"
+		schema = map[string]interface{}{"type": "object"}
+		if op.response != nil {
+			schema = b.schemaRef(reflect.TypeOf(op.response))
+		}
+	}
+
+	result := map[string]interface{}{
+		"tags":        []string{tag},
+		"summary":     op.summary,
+		"operationId": operationID(method, path),
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "Success",
+				"content":     map[string]interface{}{contentType: map[string]interface{}{"schema": schema}},
+			},
+			"default": map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+				}},
+			},
+		},
+	}
+	if len(parameters) > 0 {
+		result["parameters"] = parameters
+	}
+	if op.request != nil {
+		requestType := op.requestType
+		if requestType == "" {
+			requestType = "application/json"
+		}
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{requestType: map[string]interface{}{
+				"schema": b.schemaRef(reflect.TypeOf(op.request)),
+			}},
+		}
+	}
+	return result
+}
+
+// schemaRef returns a reference to the component schema of a named type,
+// adding it on first use; lists reference their item type
+func (b *openAPIBuilder) schemaRef(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Slice {
+		return map[string]interface{}{"type": "array", "items": b.schemaRef(t.Elem())}
+	}
+	name := []rune(t.Name())
+	if len(name) == 0 {
+		return config.JSONSchema(reflect.Zero(t).Interface())
+	}
+	name[0] = unicode.ToUpper(name[0])
+
+	if _, ok := b.components[string(name)]; !ok {
+		schema, ok := b.kinds[t]
+		if !ok {
+			schema = config.JSONSchema(reflect.Zero(t).Interface())
+		}
+		b.components[string(name)] = schema
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + string(name)}
+}
+
+// operationID names an operation after its method and path, e.g.
+// getOutgoingEndpointsByName for GET /api/outgoing/endpoints/{name}
+func operationID(method, path string) string {
+	id := method
+	path = strings.TrimPrefix(path, "/api")
+	words := strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == '.'
+	})
+	if len(words) == 0 {
+		words = []string{"root"}
+	}
+	for _, word := range words {
+		if param, ok := strings.CutPrefix(word, "{"); ok {
+			id += "By"
+			word = strings.TrimSuffix(param, "}")
+		}
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
@@ -0,0 +1,159 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+)
+
+// defaultPageLimit applies when ?page is given without ?limit
+const defaultPageLimit = 100
+
+// listParams holds the ?page, ?limit, ?sort and ?order query parameters of
+// endpoint listings
+type listParams struct {
+	page  int
+	limit int // 0 returns everything
+	sort  string
+	desc  bool
+}
+
+// pagination describes the page of a listing returned to the client
+type pagination struct {
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Total int    `json:"total"`
+	Pages int    `json:"pages"`
+	Sort  string `json:"sort,omitempty"`
+	Order string `json:"order,omitempty"`
+}
+
+// parseListParams reads pagination and sorting query parameters. Metrics sort
+// descending (worst first) and names ascending unless ?order is given.
+func parseListParams(r *http.Request) (listParams, error) {
+	query := r.URL.Query()
+	params := listParams{page: 1}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return params, fmt.Errorf("limit must be a positive integer")
+		}
+		params.limit = limit
+	}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return params, fmt.Errorf("page must be a positive integer")
+		}
+		params.page = page
+		if params.limit == 0 {
+			params.limit = defaultPageLimit
+		}
+	}
+
+	params.sort = query.Get("sort")
+	if params.sort != "" && !metrics.IsValidEndpointSort(params.sort) {
+		return params, fmt.Errorf("invalid sort %q (must be one of: %s)", params.sort, strings.Join(metrics.EndpointSortKeys(), ", "))
+	}
+	switch query.Get("order") {
+	case "":
+		params.desc = params.sort != "" && params.sort != metrics.SortByName
+	case "asc":
+	case "desc":
+		params.desc = true
+	default:
+		return params, fmt.Errorf("order must be asc or desc")
+	}
+	return params, nil
+}
+
+// active reports whether the listing should be sorted or paged
+func (p listParams) active() bool {
+	return p.limit > 0 || p.sort != ""
+}
+
+// apply sorts names by their metrics if requested and returns the requested page
+func (p listParams) apply(names []string, snapshots map[string]metrics.EndpointSnapshot) ([]string, pagination) {
+	if p.sort != "" {
+		metrics.SortEndpointNames(names, snapshots, p.sort, p.desc)
+	}
+
+	info := pagination{Page: p.page, Limit: p.limit, Total: len(names), Pages: 1, Sort: p.sort}
+	if p.sort != "" {
+		info.Order = "asc"
+		if p.desc {
+			info.Order = "desc"
+		}
+	}
+	if p.limit == 0 {
+		info.Limit = len(names)
+		return names, info
+	}
+
+	info.Pages = (len(names) + p.limit - 1) / p.limit
+	start := (p.page - 1) * p.limit
+	if start >= len(names) {
+		return []string{}, info
+	}
+	end := start + p.limit
+	if end > len(names) {
+		end = len(names)
+	}
+	return names[start:end], info
+}
+
+// pagedSnapshot is an outgoing metrics snapshot reduced to one page of
+// endpoints, listed in order by EndpointOrder
+type pagedSnapshot struct {
+	*metrics.MetricsSnapshot
+	EndpointOrder []string   `json:"endpoint_order"`
+	Pagination    pagination `json:"pagination"`
+}
+
+// pageSnapshot applies list parameters to the endpoints of a snapshot
+func pageSnapshot(snapshot *metrics.MetricsSnapshot, params listParams) *pagedSnapshot {
+	names := make([]string, 0, len(snapshot.Endpoints))
+	for name := range snapshot.Endpoints {
+		names = append(names, name)
+	}
+	if params.sort == "" {
+		metrics.SortEndpointNames(names, nil, metrics.SortByName, false)
+	}
+	page, info := params.apply(names, snapshot.Endpoints)
+
+	paged := *snapshot
+	paged.Endpoints = make(map[string]metrics.EndpointSnapshot, len(page))
+	for _, name := range page {
+		paged.Endpoints[name] = snapshot.Endpoints[name]
+	}
+	return &pagedSnapshot{MetricsSnapshot: &paged, EndpointOrder: page, Pagination: info}
+}
+
+// pageEndpoints sorts and pages a list of endpoints; metric sort keys use the
+// endpoints' current outgoing metrics
+func (s *Server) pageEndpoints(endpoints []config.Endpoint, params listParams) ([]config.Endpoint, pagination) {
+	names := make([]string, len(endpoints))
+	byName := make(map[string]config.Endpoint, len(endpoints))
+	for i, ep := range endpoints {
+		names[i] = ep.Name
+		byName[ep.Name] = ep
+	}
+
+	var snapshots map[string]metrics.EndpointSnapshot
+	if params.sort != "" && s.metrics != nil {
+		snapshots = s.metrics.Snapshot().Endpoints
+	}
+	page, info := params.apply(names, snapshots)
+
+	paged := make([]config.Endpoint, len(page))
+	for i, name := range page {
+		paged[i] = byName[name]
+	}
+	return paged, info
+}
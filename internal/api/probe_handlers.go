@@ -0,0 +1,124 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// readinessCheck is the outcome of one readiness condition
+type readinessCheck struct {
+	Ready   bool   `json:"ready"`
+	Message string `json:"message"`
+}
+
+// handleHealthz is the liveness probe: it answers as long as the process
+// serves HTTP and never depends on config, tokens or upstreams, so a failing
+// target system doesn't get the container restarted
+// GET /healthz
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"status":    "ok",
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// handleReadyz is the readiness probe: 200 when the config is loaded and
+// valid, token endpoint tokens are available and the scheduler can run,
+// 503 with the failing checks otherwise
+// GET /readyz
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]readinessCheck{
+		"config":    s.checkConfigReady(),
+		"tokens":    s.checkTokensReady(),
+		"scheduler": s.checkSchedulerReady(),
+	}
+
+	ready := true
+	for _, check := range checks {
+		ready = ready && check.Ready
+	}
+
+	status := "ready"
+	if !ready {
+		status = "not_ready"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	writeJSON(w, map[string]interface{}{
+		"status":    status,
+		"checks":    checks,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// checkConfigReady requires a loaded config that passes validation
+func (s *Server) checkConfigReady() readinessCheck {
+	if s.configManager == nil {
+		if s.config == nil {
+			return readinessCheck{Message: "no configuration loaded"}
+		}
+		return readinessCheck{Ready: true, Message: "static configuration loaded"}
+	}
+
+	if errs := s.configManager.Validate(); len(errs) > 0 {
+		return readinessCheck{Message: fmt.Sprintf("configuration invalid: %s", strings.Join(errs, "; "))}
+	}
+	return readinessCheck{Ready: true, Message: fmt.Sprintf("%d endpoints configured", len(s.getConfigForHandlers().Endpoints))}
+}
+
+// checkTokensReady fails when a token endpoint token has expired and can't
+// be refreshed. With --prewarm-tokens, every token must also have been fetched.
+func (s *Server) checkTokensReady() readinessCheck {
+	if s.tokenManager == nil {
+		return readinessCheck{Ready: true, Message: "no token manager"}
+	}
+
+	var names []string
+	for name, auth := range s.getConfigForHandlers().AuthConfigs {
+		if auth != nil && auth.TokenEndpoint != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var failing []string
+	for _, name := range names {
+		status := s.tokenManager.GetTokenStatus(name)
+		switch {
+		case !status.HasToken && s.requireTokens:
+			failing = append(failing, name+": not fetched")
+		case status.HasToken && status.IsExpired && status.LastError != "":
+			failing = append(failing, name+": "+status.LastError)
+		}
+	}
+
+	if len(failing) > 0 {
+		return readinessCheck{Message: "tokens unavailable: " + strings.Join(failing, "; ")}
+	}
+	return readinessCheck{Ready: true, Message: fmt.Sprintf("%d token endpoint auth configs ok", len(names))}
+}
+
+// checkSchedulerReady requires a scheduler with workers. A paused, stopped
+// or idle scheduler is ready: it can be started via the API.
+func (s *Server) checkSchedulerReady() readinessCheck {
+	if s.scheduler == nil {
+		return readinessCheck{Message: "no scheduler attached"}
+	}
+
+	stats := s.scheduler.GetStats()
+	if stats.Workers <= 0 {
+		return readinessCheck{Message: "scheduler has no workers (concurrent_requests is 0)"}
+	}
+
+	state := "idle"
+	switch {
+	case s.scheduler.IsRunning() && stats.Paused:
+		state = "paused"
+	case s.scheduler.IsRunning():
+		state = "running"
+	}
+	return readinessCheck{Ready: true, Message: fmt.Sprintf("scheduler %s with %d workers", state, stats.Workers)}
+}
@@ -0,0 +1,115 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+	"moxapp/internal/runs"
+)
+
+// Secrets (token endpoint bodies, sensitive headers, credential env var names,
+// seed cookies, incoming route callbacks) are masked in all API output unless
+// the server was started with --include-secrets. Masked values sent back in
+// updates and imports keep the stored secrets (see config.RestoreRedacted).
+
+// SetIncludeSecrets disables masking of secret values in API output and exports
+func (s *Server) SetIncludeSecrets(include bool) {
+	s.includeSecrets = include
+}
+
+// redactConfig masks secrets in a config snapshot
+func (s *Server) redactConfig(cfg *config.Config) *config.Config {
+	if s.includeSecrets || cfg == nil {
+		return cfg
+	}
+	return cfg.Redacted()
+}
+
+// redactAuthConfig masks credential sources of an auth config
+func (s *Server) redactAuthConfig(auth *config.AuthConfig) *config.AuthConfig {
+	if s.includeSecrets || auth == nil {
+		return auth
+	}
+	return auth.Redacted()
+}
+
+// redactAuthConfigs masks credential sources of all auth configs
+func (s *Server) redactAuthConfigs(auths map[string]*config.AuthConfig) map[string]*config.AuthConfig {
+	if s.includeSecrets {
+		return auths
+	}
+	redacted := make(map[string]*config.AuthConfig, len(auths))
+	for name, auth := range auths {
+		redacted[name] = auth.Redacted()
+	}
+	return redacted
+}
+
+// redactEndpoint masks sensitive headers, body fields and inline credentials
+func (s *Server) redactEndpoint(endpoint config.Endpoint) config.Endpoint {
+	if s.includeSecrets {
+		return endpoint
+	}
+	return endpoint.Redacted()
+}
+
+// redactEndpoints masks secrets in a list of endpoints
+func (s *Server) redactEndpoints(endpoints []config.Endpoint) []config.Endpoint {
+	if s.includeSecrets {
+		return endpoints
+	}
+	redacted := make([]config.Endpoint, len(endpoints))
+	for i := range endpoints {
+		redacted[i] = endpoints[i].Redacted()
+	}
+	return redacted
+}
+
+// redactIncomingRoute masks sensitive callback headers and body fields
+func (s *Server) redactIncomingRoute(route config.IncomingEndpoint) config.IncomingEndpoint {
+	if s.includeSecrets {
+		return route
+	}
+	return route.Redacted()
+}
+
+// redactIncomingRoutes masks secrets in a list of incoming routes
+func (s *Server) redactIncomingRoutes(routes []config.IncomingEndpoint) []config.IncomingEndpoint {
+	if s.includeSecrets {
+		return routes
+	}
+	redacted := make([]config.IncomingEndpoint, len(routes))
+	for i := range routes {
+		redacted[i] = routes[i].Redacted()
+	}
+	return redacted
+}
+
+// redactGroup masks sensitive seed cookie values
+func (s *Server) redactGroup(group config.EndpointGroup) config.EndpointGroup {
+	if s.includeSecrets {
+		return group
+	}
+	return group.Redacted()
+}
+
+// redactRun masks secrets in a run's config snapshot
+func (s *Server) redactRun(run *runs.Run) *runs.Run {
+	if s.includeSecrets || run == nil {
+		return run
+	}
+	redacted := *run
+	redacted.Config = s.redactConfig(run.Config)
+	return &redacted
+}
+
+// redactCapture masks sensitive response headers and query parameters of a
+// captured request; the body is returned as received
+func (s *Server) redactCapture(capture client.CapturedResponse) client.CapturedResponse {
+	if s.includeSecrets {
+		return capture
+	}
+	capture.URL = config.RedactURL(capture.URL)
+	capture.Headers = config.RedactHeaders(capture.Headers)
+	return capture
+}
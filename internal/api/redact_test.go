@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+const secretsConfig = `auth_configs:
+  partner:
+    name: partner
+    type: bearer
+    env_var: PARTNER_TOKEN
+    token_endpoint:
+      url: https://auth.example.com/token
+      method: POST
+      token_path: access_token
+      password_env: PARTNER_PASSWORD
+      headers:
+        Authorization: Basic c2VjcmV0
+      body:
+        grant_type: client_credentials
+        client_secret: s3cret
+outgoing_endpoints:
+  - name: orders
+    url_template: https://example.com/orders?api_key=k3y&page=1
+    frequency: 1
+    headers:
+      X-Session-Id: abc123
+      Accept: application/json
+    body:
+      password: hunter2
+      item: 7
+    auth:
+      type: bearer
+      env_var: ORDERS_TOKEN
+notifications:
+  slack_webhook_url: https://hooks.slack.com/services/T000/B000/XXXX
+`
+
+func TestRedactedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{"auth config", "/api/outgoing/auth-configs/partner"},
+		{"endpoint", "/api/outgoing/endpoints/orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, secretsConfig)
+			before := jsonOf(s.configManager.GetConfig())
+
+			rec := serve(s, "GET", tt.target, "")
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), config.RedactedValue) {
+				t.Fatalf("expected a redacted object, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			rec = serve(s, "PUT", tt.target, rec.Body.String())
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200 for the unchanged object, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "k3y") || strings.Contains(rec.Body.String(), "PARTNER_TOKEN") {
+				t.Errorf("expected the response still redacted, got %s", rec.Body.String())
+			}
+			sameSecrets(t, before, s.configManager.GetConfig())
+		})
+	}
+}
+
+func TestRedactedRoundTripBulkUpdate(t *testing.T) {
+	s := newTestServer(t, secretsConfig)
+	before := jsonOf(s.configManager.GetConfig())
+
+	body := `[{"name": "orders", "url_template": "https://example.com/orders?api_key=%5Bredacted%5D&page=1",
+		"headers": {"x-session-id": "[redacted]"}, "body": {"password": "[redacted]", "item": 7}}]`
+	if rec := serve(s, "PUT", "/api/outgoing/endpoints/bulk", body); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	sameSecrets(t, before, s.configManager.GetConfig())
+}
+
+func TestRedactedRoundTripImport(t *testing.T) {
+	s := newTestServer(t, secretsConfig)
+	before := jsonOf(s.configManager.GetConfig())
+
+	rec := serve(s, "GET", "/api/config/export", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), config.RedactedValue) {
+		t.Fatalf("expected a redacted export, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(s, "POST", "/api/config/import", rec.Body.String()); rec.Code != http.StatusOK {
+		t.Fatalf("expected the export to import, got %d: %s", rec.Code, rec.Body.String())
+	}
+	sameSecrets(t, before, s.configManager.GetConfig())
+}
+
+// sameSecrets fails unless the stored config is still the one encoded in before
+func sameSecrets(t *testing.T, before string, after *config.Config) {
+	t.Helper()
+	if got := jsonOf(after); got != before {
+		t.Errorf("config changed:\nbefore %s\nafter  %s", before, got)
+	}
+}
+
+// jsonOf returns the JSON encoding of a value
+func jsonOf(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,104 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"net/http"
+
+	"moxapp/internal/client"
+)
+
+// --- Captured Response Handlers ---
+
+// checkCaptures answers 503 when response capture isn't available
+func (s *Server) checkCaptures(w http.ResponseWriter) bool {
+	if s.captures == nil {
+		writeError(w, "response capture not available", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// handleListCaptures lists captured responses, newest first
+// GET /api/requests?endpoint={name}
+func (s *Server) handleListCaptures(w http.ResponseWriter, r *http.Request) {
+	if !s.checkCaptures(w) {
+		return
+	}
+
+	captures := s.captures.List()
+
+	if endpoint := r.URL.Query().Get("endpoint"); endpoint != "" {
+		filtered := make([]client.CapturedResponse, 0, len(captures))
+		for _, c := range captures {
+			if c.EndpointName == endpoint {
+				filtered = append(filtered, c)
+			}
+		}
+		captures = filtered
+	}
+
+	for i := range captures {
+		captures[i] = s.redactCapture(captures[i])
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"count":    len(captures),
+		"requests": captures,
+		"config":   s.configManager.GetResponseCaptureConfig(),
+	})
+}
+
+// handleClearCaptures drops all captured responses
+// DELETE /api/requests
+func (s *Server) handleClearCaptures(w http.ResponseWriter, r *http.Request) {
+	if !s.checkCaptures(w) {
+		return
+	}
+
+	s.captures.Reset()
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Captured responses cleared",
+	})
+}
+
+// handleGetCapture returns the metadata and headers of a captured response
+// GET /api/requests/{id}
+func (s *Server) handleGetCapture(w http.ResponseWriter, r *http.Request) {
+	if !s.checkCaptures(w) {
+		return
+	}
+
+	id := r.PathValue("id")
+	capture, ok := s.captures.Get(id)
+	if !ok {
+		writeError(w, "no captured response for request: "+id, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, s.redactCapture(*capture))
+}
+
+// handleGetCaptureBody returns a captured response body as it was received
+// GET /api/requests/{id}/body
+func (s *Server) handleGetCaptureBody(w http.ResponseWriter, r *http.Request) {
+	if !s.checkCaptures(w) {
+		return
+	}
+
+	id := r.PathValue("id")
+	capture, ok := s.captures.Get(id)
+	if !ok {
+		writeError(w, "no captured response for request: "+id, http.StatusNotFound)
+		return
+	}
+
+	contentType := capture.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if capture.Truncated {
+		w.Header().Set("X-Moxapp-Truncated", "true")
+	}
+	w.Write(capture.Body)
+}
@@ -0,0 +1,364 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+	"moxapp/internal/runs"
+)
+
+// routeGroup is a group of API operations documented under one tag.
+// setupRoutes registers each operation on the mux as "METHOD path", and the
+// OpenAPI document and the route list of / are generated from the same
+// table, so the docs always match what is served.
+type routeGroup struct {
+	tag        string
+	operations []operation
+}
+
+// operation is one method of an API path and its handler
+type operation struct {
+	method       string // "*" for any method
+	path         string // ServeMux path, with {param} wildcards for path segments
+	handler      http.HandlerFunc
+	summary      string
+	queryParams  []string
+	request      interface{} // Value of the request body type, nil for none
+	requestType  string      // Request body media type (default application/json)
+	response     interface{} // Value of the response body type, nil for a free-form object
+	contentType  string      // Response media type (default application/json)
+	muxPattern   string      // Registered instead of "METHOD path" when set
+	undocumented bool        // Aliases left out of the docs
+}
+
+// Operation constructors, by method
+func get(path string, handler http.HandlerFunc, summary string) operation {
+	return operation{method: http.MethodGet, path: path, handler: handler, summary: summary}
+}
+func post(path string, handler http.HandlerFunc, summary string) operation {
+	return operation{method: http.MethodPost, path: path, handler: handler, summary: summary}
+}
+func put(path string, handler http.HandlerFunc, summary string) operation {
+	return operation{method: http.MethodPut, path: path, handler: handler, summary: summary}
+}
+func del(path string, handler http.HandlerFunc, summary string) operation {
+	return operation{method: http.MethodDelete, path: path, handler: handler, summary: summary}
+}
+
+// pattern returns the ServeMux pattern the operation is registered under
+func (o operation) pattern() string {
+	switch {
+	case o.muxPattern != "":
+		return o.muxPattern
+	case o.method == "*":
+		return o.path
+	}
+	return o.method + " " + o.path
+}
+
+// docPath returns the path of the operation in the docs, without the
+// ServeMux markers of trailing wildcards and exact matches
+func (o operation) docPath() string {
+	return strings.NewReplacer("...}", "}", "{$}", "").Replace(o.path)
+}
+
+// query adds query parameters
+func (o operation) query(names ...string) operation {
+	o.queryParams = append(o.queryParams, names...)
+	return o
+}
+
+// accepts sets the JSON request body type
+func (o operation) accepts(v interface{}) operation {
+	o.request = v
+	return o
+}
+
+// consumes sets the media type of a request body that isn't JSON
+func (o operation) consumes(contentType string) operation {
+	o.requestType = contentType
+	return o
+}
+
+// returns sets the JSON response body type
+func (o operation) returns(v interface{}) operation {
+	o.response = v
+	return o
+}
+
+// produces sets the media type of a response that isn't JSON
+func (o operation) produces(contentType string) operation {
+	o.contentType = contentType
+	return o
+}
+
+// matching registers the operation under a broader ServeMux pattern, for
+// paths that would otherwise conflict with another operation's
+func (o operation) matching(pattern string) operation {
+	o.muxPattern = pattern
+	return o
+}
+
+// alias marks an operation as an alternate path of a documented one
+func (o operation) alias() operation {
+	o.undocumented = true
+	return o
+}
+
+// Query parameters shared by several operations
+var (
+	pageParams   = []string{"sort", "order", "page", "limit"}
+	seriesParams = []string{"from", "to", "step", "points"}
+)
+
+// routes returns the API routes. The root info route is left out, as it is
+// only served when the web UI isn't embedded.
+func (s *Server) routes() []routeGroup {
+	m := s.requireManager
+	return []routeGroup{
+		{tag: "Documentation", operations: []operation{
+			get("/api/docs", s.handleDocsRedirect, "Redirects to Swagger UI").produces("text/html"),
+			get("/api/docs/{$}", s.handleDocsRedirect, "").alias(),
+			get("/api/docs/swagger", s.handleSwaggerUI, "Swagger UI - Interactive API documentation").produces("text/html"),
+			get("/api/docs/swagger/{$}", s.handleSwaggerUI, "").alias(),
+			get("/api/docs/redoc", s.handleReDoc, "ReDoc - Alternative API documentation").produces("text/html"),
+			get("/api/docs/redoc/{$}", s.handleReDoc, "").alias(),
+			get("/api/docs/openapi.yaml", s.handleOpenAPIYAML, "OpenAPI specification (YAML), generated from the registered routes").produces("application/yaml"),
+			get("/api/docs/openapi.yml", s.handleOpenAPIYAML, "").alias(),
+			get("/api/docs/openapi.json", s.handleOpenAPIJSON, "OpenAPI specification (JSON), generated from the registered routes"),
+		}},
+
+		// Metrics - unified under /api/metrics
+		{tag: "Metrics", operations: []operation{
+			get("/api/metrics", s.handleMetricsOverview, "Get metrics (summary + snapshots; ?window=1m|5m|15m; ?sort=, ?order=, ?page=, ?limit= page the endpoints)").
+				query("window").query(pageParams...),
+			post("/api/metrics/reset", s.handleResetAllMetrics, "Reset all metrics (outgoing and incoming)"),
+			get("/api/metrics/outgoing", s.handleGetMetrics, "Get outgoing traffic metrics (?window=1m|5m|15m; ?sort=, ?order=, ?page=, ?limit= page the endpoints)").
+				query("window").query(pageParams...).returns(metrics.MetricsSnapshot{}),
+			post("/api/metrics/outgoing/reset", s.handleResetMetrics, "Reset outgoing metrics"),
+			get("/api/metrics/outgoing/tags", s.handleGetTagMetrics, "Get outgoing metrics aggregated per endpoint tag (?window=)").query("window"),
+			get("/api/metrics/outgoing/hosts", s.handleGetHostMetrics, "Get outgoing metrics aggregated per hostname, with latency percentiles over all its endpoints (?window=)").query("window"),
+			get("/api/metrics/outgoing/endpoints/{name}", s.handleGetEndpointMetrics, "Get one endpoint's metrics (?window=) with its time series (last 15m, or ?from=, ?to=)").
+				query("window", "from", "to"),
+			// Any {view} but errors is answered 404: a literal errors segment
+			// would conflict with endpoints/{name}
+			get("/api/metrics/outgoing/{endpoint}/errors", s.handleGetErrorSamples, "Get the last distinct errors of an endpoint (message, status, count, sample URL)").
+				matching("GET /api/metrics/outgoing/{endpoint}/{view}"),
+			get("/api/metrics/top", s.handleGetTopEndpoints, "Get the worst outgoing endpoints (?by=errors|error_rate|p95|p99|avg|dns, ?limit=10, ?window=)").
+				query("by", "limit", "window"),
+			get("/api/metrics/slo", s.handleGetSLOs, "Get the error budget, compliance and burn rates (since start, 1m, 5m, 15m) of endpoints with an SLO"),
+			get("/api/metrics/timeseries", s.handleGetTimeseries, "Get outgoing metrics per 10s interval for the last 2 hours (?endpoint=, ?from=, ?to=; ?step=1m or ?points=120 to downsample)").
+				query("endpoint").query(seriesParams...),
+			get("/api/metrics/stream", s.handleMetricsStream, "Stream outgoing and incoming time series as server-sent events (?endpoint=, ?route=, ?range=15m, ?step=, ?points=, ?interval=2s)").
+				query("endpoint", "route", "range", "step", "points", "interval").produces("text/event-stream"),
+			get("/api/metrics/incoming", s.handleGetIncomingMetrics, "Get incoming traffic metrics").returns(metrics.IncomingMetricsSnapshot{}),
+			get("/api/metrics/incoming/timeseries", s.handleGetIncomingTimeseries, "Get incoming metrics per 10s interval (?route=, ?from=, ?to=, ?step=, ?points=)").
+				query("route").query(seriesParams...),
+			post("/api/metrics/incoming/reset", s.handleResetIncomingMetrics, "Reset incoming metrics"),
+			get("/api/metrics/incoming/clients", s.handleGetIncomingClientMetrics, "Get incoming traffic per caller (remote IP or identity header)"),
+			get("/api/metrics/dns/probes", s.handleGetDNSProbes, "Get standalone DNS probe results (answers, TTLs, resolution time)"),
+			get("/api/metrics/auth", s.handleGetAuthMetrics, "Get token refresh counts, failures and latency per auth config"),
+			get("/api/metrics/baseline", s.handleGetBaseline, "Get the baseline snapshot used for comparison").returns(metrics.MetricsSnapshot{}),
+			post("/api/metrics/baseline", s.handleSetBaseline, "Load a baseline snapshot (body) or capture current metrics (?from=current)").
+				query("from").accepts(metrics.MetricsSnapshot{}),
+			put("/api/metrics/baseline", s.handleSetBaseline, "").alias(),
+			del("/api/metrics/baseline", s.handleDeleteBaseline, "Clear the baseline snapshot"),
+			get("/api/metrics/compare", s.handleCompare, "Compare current outgoing metrics against the baseline").
+				query("latency_threshold", "error_threshold").returns(metrics.Comparison{}),
+			get("/api/metrics/prometheus", s.handleGetPrometheusMetrics, "Get outgoing and incoming metrics in the Prometheus text format, with the run labels on every series").
+				produces(metrics.PrometheusContentType),
+		}},
+
+		// Alert rules
+		{tag: "Alerts", operations: []operation{
+			get("/api/alerts", s.handleGetAlerts, "Get the state of every alert rule (firing or ok, last value, last notification)"),
+		}},
+
+		// Outgoing traffic management - settings, endpoints, control
+		{tag: "Outgoing Settings", operations: []operation{
+			get("/api/outgoing/settings", s.handleGetSettings, "Get all outgoing settings"),
+			get("/api/outgoing/settings/multiplier", s.handleGetMultiplier, "Get global multiplier"),
+			post("/api/outgoing/settings/multiplier", m(s.handleSetMultiplier), "Set global multiplier").accepts(multiplierRequest{}),
+			put("/api/outgoing/settings/multiplier", m(s.handleSetMultiplier), "").alias(),
+			get("/api/outgoing/settings/multipliers", m(s.handleGetGroupMultipliers), "Get endpoint group multipliers"),
+			post("/api/outgoing/settings/multipliers", m(s.handleSetGroupMultipliers), "Set endpoint group multipliers").accepts(groupMultipliersRequest{}),
+			put("/api/outgoing/settings/multipliers", m(s.handleSetGroupMultipliers), "").alias(),
+			get("/api/outgoing/settings/environment", m(s.handleGetEnvironment), "Get the active environment and the defined ones"),
+			post("/api/outgoing/settings/environment", m(s.handleSetEnvironment), "Switch the active environment").accepts(environmentRequest{}),
+			put("/api/outgoing/settings/environment", m(s.handleSetEnvironment), "").alias(),
+			get("/api/outgoing/settings/concurrency", s.handleGetConcurrency, "Get concurrent requests limit"),
+			post("/api/outgoing/settings/concurrency", m(s.handleSetConcurrency), "Set concurrent requests limit").accepts(concurrencyRequest{}),
+			put("/api/outgoing/settings/concurrency", m(s.handleSetConcurrency), "").alias(),
+			get("/api/outgoing/settings/log-requests", s.handleGetLogRequests, "Get log all requests setting"),
+			post("/api/outgoing/settings/log-requests", m(s.handleSetLogRequests), "Set log all requests setting").accepts(logRequestsRequest{}),
+			put("/api/outgoing/settings/log-requests", m(s.handleSetLogRequests), "").alias(),
+		}},
+
+		// Config import/export
+		{tag: "Config", operations: []operation{
+			get("/api/config/export", s.handleExportConfig, "Export full config as YAML").produces("application/x-yaml"),
+			get("/api/config/schema", s.handleGetSchema, "JSON Schemas of the endpoint, auth config and incoming route bodies"),
+			get("/api/config/schema/{kind}", s.handleGetSchema, "JSON Schema of one body (endpoint, auth_config or incoming_route)"),
+			post("/api/config/import", s.handleImportConfig, "Import full config from YAML (?dry_run=true returns a diff without applying)").
+				query("dry_run").accepts(config.Config{}).consumes("application/x-yaml"),
+			post("/api/config/transaction", m(s.handleConfigTransaction), "Apply add/update/delete operations across endpoints, auth configs and incoming routes, all or none (?dry_run=true returns the diff without applying)").accepts(transactionRequest{}),
+			get("/api/config/versions", s.handleConfigVersions, "List config versions kept for rollback"),
+			post("/api/config/rollback/{id}", m(s.handleConfigRollback), "Restore a kept config version"),
+		}},
+
+		{tag: "Outgoing Endpoints", operations: []operation{
+			get("/api/outgoing/endpoints", s.handleListEndpoints, "List outgoing endpoints (?filter=, ?sort=, ?order=, ?page=, ?limit=)").query("filter").query(pageParams...),
+			post("/api/outgoing/endpoints", m(s.handleCreateEndpoint), "Create new outgoing endpoint").accepts(config.EndpointRequest{}),
+			get("/api/outgoing/endpoints/{name}", s.handleGetEndpoint, "Get outgoing endpoint by name").returns(config.Endpoint{}),
+			put("/api/outgoing/endpoints/{name}", m(s.handleUpdateEndpoint), "Update outgoing endpoint").accepts(config.EndpointRequest{}),
+			del("/api/outgoing/endpoints/{name}", m(s.handleDeleteEndpoint), "Delete outgoing endpoint"),
+			post("/api/outgoing/endpoints/{name}/test", m(s.handleTestEndpoint), "Fire one request for an endpoint and return the result with timings"),
+			post("/api/outgoing/endpoints/bulk", m(s.handleBulkCreateEndpoints), "Bulk create outgoing endpoints").accepts([]config.EndpointRequest{}),
+			put("/api/outgoing/endpoints/bulk", m(s.handleBulkUpdateEndpoints), "Bulk update outgoing endpoints with partial updates, all or none").accepts([]config.EndpointRequest{}),
+			del("/api/outgoing/endpoints/bulk", m(s.handleBulkDeleteEndpoints), "Bulk delete outgoing endpoints").accepts(namesRequest{}),
+			post("/api/outgoing/endpoints/validate", s.handleValidateEndpoint, "Validate an endpoint definition without adding it (?test=true fires one request); errors are also given per field").
+				query("test").accepts(config.EndpointRequest{}),
+		}},
+
+		{tag: "Endpoint Groups", operations: []operation{
+			get("/api/outgoing/groups", m(s.handleListGroups), "List endpoint groups with their budget distribution"),
+			post("/api/outgoing/groups", m(s.handleCreateGroup), "Create new endpoint group").accepts(config.EndpointGroup{}),
+			get("/api/outgoing/groups/{name}", m(s.handleGetGroup), "Get endpoint group by name"),
+			put("/api/outgoing/groups/{name}", m(s.handleUpdateGroup), "Update endpoint group").accepts(config.EndpointGroup{}),
+			del("/api/outgoing/groups/{name}", m(s.handleDeleteGroup), "Delete endpoint group (must have no members)"),
+			post("/api/outgoing/groups/{name}/budget", m(s.handleSetGroupBudget), "Set the shared requests/min budget of a group").accepts(budgetRequest{}),
+			put("/api/outgoing/groups/{name}/budget", m(s.handleSetGroupBudget), "").alias(),
+			del("/api/outgoing/groups/{name}/cookies", m(s.handleResetGroupCookies), "Clear the group's cookie jar (seed cookies are re-applied)"),
+		}},
+
+		{tag: "Auth Configs", operations: []operation{
+			get("/api/outgoing/auth-configs", m(s.handleListAuthConfigs), "List all auth configs"),
+			post("/api/outgoing/auth-configs", m(s.handleCreateAuthConfig), "Create new auth config").accepts(config.AuthConfig{}),
+			get("/api/outgoing/auth-configs/{name}", m(s.handleGetAuthConfig), "Get auth config by name").returns(config.AuthConfig{}),
+			put("/api/outgoing/auth-configs/{name}", m(s.handleUpdateAuthConfig), "Update auth config").accepts(config.AuthConfig{}),
+			del("/api/outgoing/auth-configs/{name}", m(s.handleDeleteAuthConfig), "Delete auth config"),
+			post("/api/outgoing/auth-configs/{name}/token", m(s.handleSetAuthToken), "Manually set token for auth config").accepts(setTokenRequest{}),
+			post("/api/outgoing/auth-configs/{name}/refresh", m(s.handleRefreshAuthToken), "Force refresh token for auth config"),
+			get("/api/outgoing/auth-configs/{name}/status", m(s.handleAuthTokenStatus), "Get token status for auth config").returns(client.TokenStatus{}),
+			post("/api/outgoing/auth-configs/validate", m(s.handleValidateAuthConfig), "Validate an auth config definition without adding it, with errors per field").accepts(config.AuthConfig{}),
+		}},
+
+		{tag: "Outgoing Control", operations: []operation{
+			get("/api/outgoing/control", s.handleGetControlStatus, "Get scheduler control status"),
+			post("/api/outgoing/control", s.handleControlAction, "Control scheduler (pause, resume, start, stop, emergency_stop, enable_adaptive, disable_adaptive)").accepts(controlRequest{}),
+			get("/api/outgoing/control/backpressure", s.handleGetBackpressure, "Get schedule lag, missed intervals and queue wait per endpoint"),
+			get("/api/outgoing/control/heartbeat", s.handleGetHeartbeat, "Get the state of the heartbeat dead-man switch"),
+			post("/api/outgoing/control/heartbeat", s.handleHeartbeat, "Send a heartbeat, postponing the pause of the dead-man switch"),
+			post("/api/outgoing/control/endpoint", m(s.handleEndpointEnable), "Enable/disable specific outgoing endpoint").accepts(toggleRequest{}),
+			post("/api/outgoing/control/endpoints/bulk", m(s.handleBulkEndpointEnable), "Enable/disable multiple outgoing endpoints (by names or tag)").accepts(bulkEnableRequest{}),
+			post("/api/outgoing/control/endpoints/all", m(s.handleEnableAll), "Enable/disable all outgoing endpoints").accepts(enabledRequest{}),
+		}},
+
+		// Run history
+		{tag: "Runs", operations: []operation{
+			get("/api/runs", s.handleListRuns, "List runs (newest first)"),
+			post("/api/runs", s.handleStartRun, "Start a new run (finalizes the current run, resets metrics)").accepts(startRunRequest{}),
+			get("/api/runs/current", s.handleGetCurrentRun, "Get the run in progress with live metrics").returns(runs.Run{}),
+			get("/api/runs/{id}", s.handleGetRun, "Get a run with its config snapshot and metrics").returns(runs.Run{}),
+			post("/api/runs/{id}/stop", s.handleStopRun, "Finalize a run and pause the scheduler"),
+		}},
+
+		// Captured responses
+		{tag: "Captured Responses", operations: []operation{
+			get("/api/requests", s.handleListCaptures, "List captured responses (newest first, ?endpoint= to filter)").query("endpoint"),
+			del("/api/requests", s.handleClearCaptures, "Clear captured responses"),
+			get("/api/requests/{id}", s.handleGetCapture, "Get captured response metadata and headers").returns(client.CapturedResponse{}),
+			get("/api/requests/{id}/body", s.handleGetCaptureBody, "Get captured response body").produces("application/octet-stream"),
+		}},
+
+		// Incoming routes management API
+		{tag: "Incoming Routes", operations: []operation{
+			get("/api/incoming/routes", m(s.handleListIncomingRoutes), "List all incoming routes"),
+			post("/api/incoming/routes", m(s.handleCreateIncomingRoute), "Create new incoming route").accepts(config.IncomingEndpointRequest{}),
+			get("/api/incoming/routes/{name}", m(s.handleGetIncomingRoute), "Get incoming route by name").returns(config.IncomingEndpoint{}),
+			put("/api/incoming/routes/{name}", m(s.handleUpdateIncomingRoute), "Update incoming route").accepts(config.IncomingEndpointRequest{}),
+			del("/api/incoming/routes/{name}", m(s.handleDeleteIncomingRoute), "Delete incoming route"),
+			post("/api/incoming/routes/reload", m(s.handleReloadIncomingRoutes), "Reload incoming routes from static config"),
+			post("/api/incoming/routes/validate", m(s.handleValidateIncomingRoute), "Validate an incoming route definition without adding it, with errors per field").accepts(config.IncomingEndpointRequest{}),
+		}},
+		{tag: "Incoming Control", operations: []operation{
+			get("/api/incoming/control", m(s.handleGetIncomingControl), "Get incoming routes status"),
+			post("/api/incoming/control", m(s.handleSetIncomingControl), "Enable/disable all incoming routes").accepts(enabledRequest{}),
+			post("/api/incoming/control/route", m(s.handleIncomingRouteControl), "Enable/disable specific incoming route").accepts(toggleRequest{}),
+		}},
+
+		// Simulated routes endpoint - handles /sim/*
+		{tag: "Simulated Routes", operations: []operation{
+			{method: "*", path: SimulatedRoutePrefix + "/{path...}", handler: s.handleSimulatedRoute, summary: "Simulated incoming routes (responds based on configured patterns)"},
+			get(SimulatedRoutePrefix, s.handleSimulatedRouteInfo, "Get information about available simulated routes"),
+		}},
+
+		// Health check, plus liveness and readiness probes
+		{tag: "Health", operations: []operation{
+			get("/health", s.handleHealth, "Health check"),
+			get("/healthz", s.handleHealthz, "Liveness probe (200 while the process serves HTTP)"),
+			get("/readyz", s.handleReadyz, "Readiness probe (200 when config, tokens and scheduler are ready, 503 otherwise)"),
+		}},
+	}
+}
+
+// rootRoute is the API info route served at / when the web UI isn't embedded
+func (s *Server) rootRoute() routeGroup {
+	return routeGroup{tag: "Health", operations: []operation{
+		get("/{$}", s.handleRoot, "API information and the list of available endpoints"),
+	}}
+}
+
+// requireManager answers 503 instead of calling handlers that need the
+// config manager when the server runs without one
+func (s *Server) requireManager(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.checkConfigManager(w) {
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// routeErrors answers the requests no route matches: 405 with the allowed
+// methods when the path has routes for other methods, the web UI for other
+// GET requests outside /api/ when it is embedded, 404 otherwise
+func routeErrors(mux *http.ServeMux, webUI http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		var allowed []string
+		for _, method := range routeMethods {
+			probe := *r
+			probe.Method = method
+			if _, pattern := mux.Handler(&probe); pattern != "" {
+				allowed = append(allowed, method)
+			}
+		}
+		if len(allowed) > 0 {
+			if allowed[0] == http.MethodGet {
+				allowed = append(allowed, http.MethodHead)
+			}
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeAPIError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed: "+r.Method+" "+r.URL.Path,
+				map[string][]string{"allowed": allowed})
+			return
+		}
+
+		if webUI != nil && (r.Method == http.MethodGet || r.Method == http.MethodHead) && !strings.HasPrefix(r.URL.Path, "/api/") {
+			webUI.ServeHTTP(w, r)
+			return
+		}
+		writeError(w, "not found: "+r.URL.Path, http.StatusNotFound)
+	})
+}
+
+// routeMethods are probed for the Allow header of 405 responses
+var routeMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
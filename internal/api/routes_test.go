@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moxapp/internal/client"
+)
+
+const routesConfig = `outgoing_endpoints:
+  - name: orders
+    url_template: https://example.com/orders
+    frequency: 1
+incoming_routes:
+  - name: status
+    path: /api/status
+    method: GET
+    responses:
+      - status: 201
+        share: 1
+`
+
+// errorCode returns the code of an API error response
+func errorCode(t *testing.T, body []byte) string {
+	t.Helper()
+	var resp struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("expected a JSON error, got %s", body)
+	}
+	return resp.Error.Code
+}
+
+func TestRoutesMethodNotAllowed(t *testing.T) {
+	s := newTestServer(t, routesConfig)
+
+	tests := []struct {
+		method, target string
+		allow          string
+	}{
+		{"POST", "/api/metrics/slo", "GET, HEAD"},
+		{"POST", "/api/outgoing/endpoints/orders", "GET, PUT, DELETE, HEAD"},
+		// GET matches endpoints/{name} with the name bulk
+		{"PATCH", "/api/outgoing/endpoints/bulk", "GET, POST, PUT, DELETE, HEAD"},
+	}
+	for _, tt := range tests {
+		rec := serve(s, tt.method, tt.target, "")
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected 405, got %d", tt.method, tt.target, rec.Code)
+			continue
+		}
+		if allow := rec.Header().Get("Allow"); allow != tt.allow {
+			t.Errorf("%s %s: expected Allow %q, got %q", tt.method, tt.target, tt.allow, allow)
+		}
+		if code := errorCode(t, rec.Body.Bytes()); code != CodeMethodNotAllowed {
+			t.Errorf("%s %s: expected %s, got %s", tt.method, tt.target, CodeMethodNotAllowed, code)
+		}
+	}
+}
+
+func TestRoutesExactPathAliases(t *testing.T) {
+	s := newTestServer(t, routesConfig)
+
+	for _, target := range []string{"/api/docs/swagger", "/api/docs/swagger/", "/api/docs/redoc", "/api/docs/redoc/"} {
+		rec := serve(s, "GET", target, "")
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("GET %s: expected the HTML docs, got %d %s", target, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+	for _, target := range []string{"/api/docs", "/api/docs/"} {
+		if rec := serve(s, "GET", target, ""); rec.Code != http.StatusFound && rec.Code != http.StatusMovedPermanently {
+			t.Errorf("GET %s: expected a redirect, got %d", target, rec.Code)
+		}
+	}
+
+	// {$} only matches the path itself, not paths below it
+	rec := serve(s, "GET", "/api/docs/swagger/index.css", "")
+	if rec.Code != http.StatusNotFound || errorCode(t, rec.Body.Bytes()) != CodeNotFound {
+		t.Errorf("expected a JSON 404 below an exact path, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRoutesNotFound(t *testing.T) {
+	s := newTestServer(t, routesConfig)
+
+	// Paths outside /api/ get the web UI, which routes them client-side
+	for _, target := range []string{"/", "/dashboard", "/endpoints/orders"} {
+		rec := serve(s, "GET", target, "")
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("GET %s: expected the web UI, got %d %s", target, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	tests := []struct {
+		method, target string
+		code           string
+	}{
+		{"GET", "/api/unknown", CodeNotFound},
+		{"POST", "/dashboard", CodeNotFound},
+		{"GET", "/api/outgoing/endpoints/missing", CodeEndpointNotFound},
+	}
+	for _, tt := range tests {
+		rec := serve(s, tt.method, tt.target, "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected 404, got %d", tt.method, tt.target, rec.Code)
+			continue
+		}
+		if code := errorCode(t, rec.Body.Bytes()); code != tt.code {
+			t.Errorf("%s %s: expected %s, got %s", tt.method, tt.target, tt.code, code)
+		}
+	}
+}
+
+func TestRoutesWithoutWebUI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/items", func(w http.ResponseWriter, r *http.Request) {})
+	handler := routeErrors(mux, nil)
+
+	for _, target := range []string{"/", "/dashboard"} {
+		rec := serveHandler(handler, "GET", target)
+		if rec.Code != http.StatusNotFound || errorCode(t, rec.Body.Bytes()) != CodeNotFound {
+			t.Errorf("GET %s: expected a JSON 404 without the web UI, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := serveHandler(handler, "GET", "/api/items"); rec.Code != http.StatusOK {
+		t.Errorf("expected the registered route, got %d", rec.Code)
+	}
+}
+
+// serveHandler sends a request to handler
+func serveHandler(handler http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestRoutesSimulated(t *testing.T) {
+	s := newTestServer(t, routesConfig)
+
+	if rec := serve(s, "GET", "/sim/api/status", ""); rec.Code != http.StatusCreated {
+		t.Errorf("expected the simulated route's 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// Any method reaches the simulated routes handler, which matches the
+	// route's method, instead of the router answering 405
+	rec := serve(s, "DELETE", "/sim/api/status", "")
+	if rec.Code != http.StatusNotFound || rec.Header().Get("Allow") != "" || !strings.Contains(rec.Body.String(), "no matching route") {
+		t.Errorf("expected the simulated routes handler's 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(s, "GET", "/sim/api/missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown simulated route, got %d", rec.Code)
+	}
+	if rec := serve(s, "GET", "/sim", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected the simulated routes info, got %d", rec.Code)
+	}
+}
+
+func TestRoutesErrorsView(t *testing.T) {
+	s := newTestServer(t, routesConfig)
+	s.metrics.Record(&client.RequestResult{EndpointName: "orders", Error: "HTTP 500", ErrorType: "http", StatusCode: 500})
+
+	rec := serve(s, "GET", "/api/metrics/outgoing/orders/errors", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "HTTP 500") {
+		t.Errorf("expected the error samples, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Other views are not found, without shadowing endpoints/{name}
+	rec = serve(s, "GET", "/api/metrics/outgoing/orders/latency", "")
+	if rec.Code != http.StatusNotFound || errorCode(t, rec.Body.Bytes()) != CodeNotFound {
+		t.Errorf("expected a JSON 404 for another view, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(s, "GET", "/api/metrics/outgoing/endpoints/orders", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected the endpoint metrics, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(s, "GET", "/api/metrics/outgoing/missing/errors", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an endpoint without metrics, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"net/http"
+)
+
+// --- Run History Handlers ---
+
+// checkRuns answers 503 when run tracking isn't available
+func (s *Server) checkRuns(w http.ResponseWriter) bool {
+	if s.runs == nil {
+		writeError(w, "run tracking not available", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// handleListRuns lists all runs, newest first
+// GET /api/runs
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRuns(w) {
+		return
+	}
+
+	runs := s.runs.List()
+
+	response := map[string]interface{}{
+		"count": len(runs),
+		"runs":  runs,
+	}
+	if current := s.runs.Current(); current != nil {
+		response["current_run_id"] = current.ID
+	}
+	writeJSON(w, response)
+}
+
+// handleGetCurrentRun returns the run in progress
+// GET /api/runs/current
+func (s *Server) handleGetCurrentRun(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRuns(w) {
+		return
+	}
+
+	current := s.runs.Current()
+	if current == nil {
+		writeError(w, "no run in progress", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, s.redactRun(current))
+}
+
+// handleGetRun returns a single run with its config snapshot and metrics
+// GET /api/runs/{id}
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRuns(w) {
+		return
+	}
+
+	run, err := s.runs.Get(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, s.redactRun(run))
+}
+
+// startRunRequest labels the run being started
+type startRunRequest struct {
+	Label string `json:"label"`
+}
+
+// handleStartRun finalizes the current run and starts a new one, resuming the scheduler
+// POST /api/runs
+func (s *Server) handleStartRun(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRuns(w) {
+		return
+	}
+
+	var req startRunRequest
+
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &req); err != nil {
+			writeInvalidJSON(w, err)
+			return
+		}
+	}
+
+	var previousID string
+	if current := s.runs.Current(); current != nil {
+		previousID = current.ID
+	}
+
+	run := s.runs.Start(req.Label, s.getConfigForHandlers())
+	if s.scheduler != nil {
+		if !s.scheduler.IsRunning() {
+			// Stopped, or launched with --idle; a pending start is fine
+			_ = s.scheduler.Restart()
+		}
+		if s.scheduler.IsPaused() {
+			s.scheduler.Resume()
+		}
+	}
+
+	response := map[string]interface{}{
+		"status":  "success",
+		"message": "Run started",
+		"run":     run,
+	}
+	if previousID != "" {
+		response["finalized_run_id"] = previousID
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, response)
+}
+
+// handleStopRun finalizes a run and pauses the scheduler
+// POST /api/runs/{id}/stop
+func (s *Server) handleStopRun(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRuns(w) {
+		return
+	}
+
+	id := r.PathValue("id")
+	current := s.runs.Current()
+	if current == nil {
+		writeError(w, "no run in progress", http.StatusConflict)
+		return
+	}
+	if id != "current" && id != current.ID {
+		if _, err := s.runs.Get(id); err != nil {
+			writeError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeError(w, "run is not in progress: "+id, http.StatusConflict)
+		return
+	}
+
+	if s.scheduler != nil {
+		s.scheduler.Pause()
+	}
+
+	run, err := s.runs.Finish()
+	if err != nil {
+		writeError(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"status":  "success",
+		"message": "Run finalized and scheduler paused",
+		"run":     run,
+	})
+}
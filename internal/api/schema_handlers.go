@@ -0,0 +1,90 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"net/http"
+
+	"moxapp/internal/config"
+)
+
+// handleGetSchema returns the JSON Schemas of the endpoint, auth config and
+// incoming route request bodies, or of one kind
+// GET /api/config/schema
+// GET /api/config/schema/{endpoint|auth_config|incoming_route}
+func (s *Server) handleGetSchema(w http.ResponseWriter, r *http.Request) {
+	schemas := config.Schemas()
+	kind := r.PathValue("kind")
+	if kind == "" {
+		writeJSON(w, schemas)
+		return
+	}
+	schema, ok := schemas[kind]
+	if !ok {
+		writeError(w, "unknown schema: "+kind+" (must be one of: endpoint, auth_config, incoming_route)", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, schema)
+}
+
+// handleValidateAuthConfig checks an auth config definition without adding it
+// POST /api/outgoing/auth-configs/validate
+func (s *Server) handleValidateAuthConfig(w http.ResponseWriter, r *http.Request) {
+	var authCfg config.AuthConfig
+	if err := readJSON(r, &authCfg); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+
+	errors := authCfg.Validate()
+	warnings := []string{}
+	if _, err := s.configManager.GetAuthConfig(authCfg.Name); err == nil {
+		warnings = append(warnings, "an auth config named "+authCfg.Name+" already exists; creating this one would fail")
+	}
+	if len(errors) == 0 {
+		_, warnings = s.authDiagnostics(&authCfg, warnings)
+	}
+
+	writeJSON(w, validationResponse(&authCfg, errors, warnings))
+}
+
+// handleValidateIncomingRoute checks an incoming route definition without
+// adding it
+// POST /api/incoming/routes/validate
+func (s *Server) handleValidateIncomingRoute(w http.ResponseWriter, r *http.Request) {
+	var req config.IncomingEndpointRequest
+	if err := readJSON(r, &req); err != nil {
+		writeInvalidJSON(w, err)
+		return
+	}
+
+	route := prepareIncomingRoute(req.ToIncomingEndpoint())
+	warnings := []string{}
+	if _, err := s.configManager.GetIncomingRoute(route.Name); err == nil {
+		warnings = append(warnings, "an incoming route named "+route.Name+" already exists; creating this one would fail")
+	}
+
+	writeJSON(w, validationResponse(&req, route.Validate(), warnings))
+}
+
+// prepareIncomingRoute applies the defaults AddIncomingRoute does before
+// validating
+func prepareIncomingRoute(route config.IncomingEndpoint) *config.IncomingEndpoint {
+	if route.Method == "" {
+		route.Method = "GET"
+	}
+	return &route
+}
+
+// validationResponse is the result of a validate request: the errors, also
+// attributed to the fields of the request body v, and warnings
+func validationResponse(v interface{}, errors, warnings []string) map[string]interface{} {
+	if errors == nil {
+		errors = []string{}
+	}
+	return map[string]interface{}{
+		"valid":        len(errors) == 0,
+		"errors":       errors,
+		"field_errors": config.FieldErrors(v, errors),
+		"warnings":     warnings,
+	}
+}
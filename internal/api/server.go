@@ -5,14 +5,19 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"path"
 	"strings"
 	"time"
 
+	"moxapp/internal/alerts"
 	"moxapp/internal/client"
 	"moxapp/internal/config"
 	"moxapp/internal/metrics"
+	"moxapp/internal/notify"
+	"moxapp/internal/resources"
+	"moxapp/internal/runs"
 	"moxapp/internal/scheduler"
 	"moxapp/internal/web"
 )
@@ -25,23 +30,64 @@ type Server struct {
 	configManager *config.Manager // Config manager with both outgoing and incoming routes
 	scheduler     *scheduler.Scheduler
 	tokenManager  *client.TokenManager // Token manager for auth configs
+	httpClient    *client.Client       // Client for endpoint test requests
+	runs          *runs.Store          // Run history
+	captures      *client.CaptureStore // Sampled response bodies
+	cookieJars    *client.CookieJars   // Per-group session cookies
+	alerts        *alerts.Evaluator    // Alert rule states
+	notifier      *notify.Notifier     // Run lifecycle notifications
 
 	// Incoming routes simulation metrics
 	incomingMetrics *metrics.IncomingCollector
+
+	// Token buckets of rate-limited incoming routes
+	incomingLimits *incomingLimiters
+
+	// Token buckets of API clients, when api_rate_limit is configured
+	apiLimits *apiLimiters
+
+	// Worker pools of concurrency-limited incoming routes
+	incomingPools *incomingPools
+
+	// Tokens handed out to confirm guarded actions
+	confirmations *confirmations
+
+	// Token endpoint metrics per auth config
+	authMetrics *metrics.AuthCollector
+
+	// Resource usage of the generator itself
+	resources *resources.Monitor
+
+	cors      config.APICORSConfig // Origins allowed to call the API, any by default
+	accessLog *accessLog           // Log of API requests, nil when disabled
+
+	includeSecrets bool // Disables masking of secrets in API output
+	requireTokens  bool // Readiness requires every token endpoint token to be fetched
+
+	redirectServer *http.Server // Redirects plain HTTP to HTTPS when TLS is configured
+
+	// Canceled on shutdown to end open event streams, which would otherwise
+	// keep the server from shutting down
+	streams     context.Context
+	stopStreams context.CancelFunc
+
+	apiRoutes []routeGroup // Registered routes, described by the API docs
 }
 
 // NewServer creates a new API server (legacy - uses Config directly)
 func NewServer(addr string, metricsCollector *metrics.Collector, cfg *config.Config) *Server {
 	s := &Server{
-		metrics: metricsCollector,
-		config:  cfg,
+		metrics:        metricsCollector,
+		config:         cfg,
+		incomingLimits: newIncomingLimiters(),
+		apiLimits:      newAPILimiters(),
+		incomingPools:  newIncomingPools(),
+		confirmations:  newConfirmations(),
 	}
-
-	mux := http.NewServeMux()
-	s.setupRoutes(mux)
+	s.streams, s.stopStreams = context.WithCancel(context.Background())
 
 	// Wrap with middleware
-	handler := corsMiddleware(jsonMiddleware(mux))
+	handler := s.accessLogMiddleware(gzipMiddleware(s.corsMiddleware(s.rateLimitMiddleware(jsonMiddleware(s.setupRoutes())))))
 
 	s.server = &http.Server{
 		Addr:         addr,
@@ -57,16 +103,18 @@ func NewServer(addr string, metricsCollector *metrics.Collector, cfg *config.Con
 // NewServerWithManager creates a new API server with config manager
 func NewServerWithManager(addr string, metricsCollector *metrics.Collector, configManager *config.Manager) *Server {
 	s := &Server{
-		metrics:       metricsCollector,
-		configManager: configManager,
-		config:        configManager.GetConfig(), // For legacy compatibility
+		metrics:        metricsCollector,
+		configManager:  configManager,
+		config:         configManager.GetConfig(), // For legacy compatibility
+		incomingLimits: newIncomingLimiters(),
+		apiLimits:      newAPILimiters(),
+		incomingPools:  newIncomingPools(),
+		confirmations:  newConfirmations(),
 	}
-
-	mux := http.NewServeMux()
-	s.setupRoutes(mux)
+	s.streams, s.stopStreams = context.WithCancel(context.Background())
 
 	// Wrap with middleware
-	handler := corsMiddleware(jsonMiddleware(mux))
+	handler := s.accessLogMiddleware(gzipMiddleware(s.corsMiddleware(s.rateLimitMiddleware(jsonMiddleware(s.setupRoutes())))))
 
 	s.server = &http.Server{
 		Addr:         addr,
@@ -84,6 +132,21 @@ func (s *Server) SetScheduler(sched *scheduler.Scheduler) {
 	s.scheduler = sched
 }
 
+// SetResourceMonitor sets the monitor of the generator's own resource usage
+func (s *Server) SetResourceMonitor(monitor *resources.Monitor) {
+	s.resources = monitor
+}
+
+// SetAlerts sets the alert evaluator whose rule states are served
+func (s *Server) SetAlerts(evaluator *alerts.Evaluator) {
+	s.alerts = evaluator
+}
+
+// SetNotifier sets the notifier told about emergency stops
+func (s *Server) SetNotifier(notifier *notify.Notifier) {
+	s.notifier = notifier
+}
+
 // SetConfigManager sets the config manager for dynamic endpoint management
 func (s *Server) SetConfigManager(manager *config.Manager) {
 	s.configManager = manager
@@ -94,102 +157,94 @@ func (s *Server) SetIncomingMetrics(collector *metrics.IncomingCollector) {
 	s.incomingMetrics = collector
 }
 
+// SetAuthMetrics sets the token endpoint metrics collector
+func (s *Server) SetAuthMetrics(collector *metrics.AuthCollector) {
+	s.authMetrics = collector
+}
+
 // SetTokenManager sets the token manager for auth config operations
 func (s *Server) SetTokenManager(tm *client.TokenManager) {
 	s.tokenManager = tm
 }
 
-// setupRoutes configures the API routes
-func (s *Server) setupRoutes(mux *http.ServeMux) {
-	staticRegistered := s.staticFrontend(mux)
-
-	// API Documentation
-	mux.HandleFunc("/api/docs", s.handleDocsRoute)
-	mux.HandleFunc("/api/docs/", s.handleDocsRoute)
-
-	// Metrics endpoints - unified under /api/metrics
-	mux.HandleFunc("/api/metrics", s.handleMetricsOverview)
-	mux.HandleFunc("/api/metrics/reset", s.handleResetAllMetrics)
-	mux.HandleFunc("/api/metrics/outgoing", s.handleGetMetrics)
-	mux.HandleFunc("/api/metrics/outgoing/reset", s.handleResetMetrics)
-	mux.HandleFunc("/api/metrics/incoming", s.handleGetIncomingMetrics)
-	mux.HandleFunc("/api/metrics/incoming/reset", s.handleResetIncomingMetrics)
-
-	// Outgoing traffic management - settings, endpoints, control
-	mux.HandleFunc("/api/outgoing/settings", s.handleGetSettings)
-	mux.HandleFunc("/api/outgoing/settings/multiplier", s.handleSetMultiplier)
-	mux.HandleFunc("/api/outgoing/settings/concurrency", s.handleSetConcurrency)
-	mux.HandleFunc("/api/outgoing/settings/log-requests", s.handleSetLogRequests)
-
-	// Config import/export
-	mux.HandleFunc("/api/config/export", s.handleExportConfig)
-	mux.HandleFunc("/api/config/import", s.handleImportConfig)
-
-	mux.HandleFunc("/api/outgoing/endpoints", s.handleEndpointsRoute)
-	mux.HandleFunc("/api/outgoing/endpoints/", s.handleEndpointsRoute)
-	mux.HandleFunc("/api/outgoing/endpoints/bulk", s.handleBulkEndpointsRoute)
-
-	mux.HandleFunc("/api/outgoing/auth-configs", s.handleAuthConfigs)
-	mux.HandleFunc("/api/outgoing/auth-configs/", s.handleAuthConfigs)
-
-	mux.HandleFunc("/api/outgoing/control", s.handleControl)
-	mux.HandleFunc("/api/outgoing/control/endpoint", s.handleEndpointEnable)
-	mux.HandleFunc("/api/outgoing/control/endpoints/bulk", s.handleBulkEndpointEnable)
-	mux.HandleFunc("/api/outgoing/control/endpoints/all", s.handleEnableAll)
-
-	// Incoming routes management API
-	mux.HandleFunc("/api/incoming/routes", s.handleIncomingRoutesRoute)
-	mux.HandleFunc("/api/incoming/routes/", s.handleIncomingRoutesRoute)
-	mux.HandleFunc("/api/incoming/control", s.handleIncomingControl)
-	mux.HandleFunc("/api/incoming/control/route", s.handleIncomingRouteControl)
-
-	// Simulated routes endpoint - handles /sim/*
-	mux.HandleFunc(SimulatedRoutePrefix+"/", s.handleSimulatedRoute)
-	mux.HandleFunc(SimulatedRoutePrefix, s.handleSimulatedRouteInfo)
-
-	// Health check
-	mux.HandleFunc("/health", s.handleHealth)
+// SetCORSConfig sets the origins, headers and credentials browsers are
+// allowed for cross-origin API requests
+func (s *Server) SetCORSConfig(cors config.APICORSConfig) {
+	s.cors = cors
+}
+
+// SetRequireTokens makes readiness require every token endpoint token to have
+// been fetched, as done by --prewarm-tokens
+func (s *Server) SetRequireTokens(require bool) {
+	s.requireTokens = require
+}
+
+// SetHTTPClient sets the client used to fire endpoint test requests
+func (s *Server) SetHTTPClient(c *client.Client) {
+	s.httpClient = c
+}
+
+// SetRunStore sets the run store for run history endpoints
+func (s *Server) SetRunStore(store *runs.Store) {
+	s.runs = store
+}
+
+// SetCaptureStore sets the response capture store for /api/requests
+func (s *Server) SetCaptureStore(store *client.CaptureStore) {
+	s.captures = store
+}
+
+// SetCookieJars sets the group cookie jars so they can be reset via the API
+func (s *Server) SetCookieJars(jars *client.CookieJars) {
+	s.cookieJars = jars
+}
+
+// setupRoutes registers the API routes and returns the handler serving them
+func (s *Server) setupRoutes() http.Handler {
+	mux := http.NewServeMux()
+	webUI := s.staticFrontend(mux)
 
+	s.apiRoutes = s.routes()
 	// Root handler - API info (only when frontend is not embedded)
-	if !staticRegistered {
-		mux.HandleFunc("/", s.handleRoot)
+	if webUI == nil {
+		s.apiRoutes = append(s.apiRoutes, s.rootRoute())
 	}
+	for _, group := range s.apiRoutes {
+		for _, op := range group.operations {
+			mux.HandleFunc(op.pattern(), op.handler)
+		}
+	}
+	return routeErrors(mux, webUI)
 }
 
-func (s *Server) staticFrontend(mux *http.ServeMux) bool {
+// staticFrontend serves the embedded web UI assets and returns the handler
+// of the other web UI paths, which get index.html unless they name a file.
+// It returns nil when the web UI isn't embedded.
+func (s *Server) staticFrontend(mux *http.ServeMux) http.Handler {
 	fsys, err := web.FS()
 	if err != nil {
-		return false
+		return nil
 	}
 
 	fsysHTTP := http.FS(fsys)
 	fileServer := http.FileServer(fsysHTTP)
 
-	mux.Handle("/assets/", http.StripPrefix("/", fileServer))
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
-			if strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/health" || strings.HasPrefix(r.URL.Path, "/sim/") {
-				http.NotFound(w, r)
-				return
-			}
-			if fileExists(fsysHTTP, strings.TrimPrefix(r.URL.Path, "/")) {
-				fileServer.ServeHTTP(w, r)
-				return
-			}
+	mux.Handle("GET /assets/", http.StripPrefix("/", fileServer))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && fileExists(fsysHTTP, strings.TrimPrefix(r.URL.Path, "/")) {
+			fileServer.ServeHTTP(w, r)
+			return
 		}
 
 		indexFile, err := fsys.Open("index.html")
 		if err != nil {
-			http.NotFound(w, r)
+			writeError(w, "not found: "+r.URL.Path, http.StatusNotFound)
 			return
 		}
 		defer indexFile.Close()
-
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		_, _ = io.Copy(w, indexFile)
 	})
-
-	return true
 }
 
 func fileExists(fsys http.FileSystem, name string) bool {
@@ -206,95 +261,59 @@ func fileExists(fsys http.FileSystem, name string) bool {
 
 // handleRoot provides API information
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
-		return
-	}
-
 	info := map[string]interface{}{
 		"app":     "moxapp",
 		"version": "1.0.0",
 		"docs": map[string]string{
-			"swagger": "/api/docs/swagger",
-			"redoc":   "/api/docs/redoc",
-			"openapi": "/api/docs/openapi.yaml",
-		},
-		"endpoints": map[string]string{
-			// Documentation
-			"GET /api/docs/swagger":      "Swagger UI - Interactive API documentation",
-			"GET /api/docs/redoc":        "ReDoc - Alternative API documentation",
-			"GET /api/docs/openapi.yaml": "OpenAPI specification (YAML)",
-
-			// Health
-			"GET /health": "Health check",
-
-			// Metrics - unified under /api/metrics
-			"GET /api/metrics":                 "Get metrics (summary + snapshots)",
-			"POST /api/metrics/reset":          "Reset all metrics (outgoing and incoming)",
-			"GET /api/metrics/outgoing":        "Get outgoing traffic metrics",
-			"POST /api/metrics/outgoing/reset": "Reset outgoing metrics",
-			"GET /api/metrics/incoming":        "Get incoming traffic metrics",
-			"POST /api/metrics/incoming/reset": "Reset incoming metrics",
-
-			// Outgoing - settings, endpoints, control
-			"GET /api/outgoing/settings":                     "Get all outgoing settings",
-			"GET /api/outgoing/settings/multiplier":          "Get global multiplier",
-			"POST /api/outgoing/settings/multiplier":         "Set global multiplier",
-			"GET /api/outgoing/settings/concurrency":         "Get concurrent requests limit",
-			"POST /api/outgoing/settings/concurrency":        "Set concurrent requests limit",
-			"GET /api/outgoing/settings/log-requests":        "Get log all requests setting",
-			"POST /api/outgoing/settings/log-requests":       "Set log all requests setting",
-			"GET /api/outgoing/endpoints":                    "List all outgoing endpoints",
-			"GET /api/outgoing/endpoints/{name}":             "Get outgoing endpoint by name",
-			"POST /api/outgoing/endpoints":                   "Create new outgoing endpoint",
-			"PUT /api/outgoing/endpoints/{name}":             "Update outgoing endpoint",
-			"DELETE /api/outgoing/endpoints/{name}":          "Delete outgoing endpoint",
-			"POST /api/outgoing/endpoints/bulk":              "Bulk create outgoing endpoints",
-			"DELETE /api/outgoing/endpoints/bulk":            "Bulk delete outgoing endpoints",
-			"GET /api/outgoing/auth-configs":                 "List all auth configs",
-			"GET /api/outgoing/auth-configs/{name}":          "Get auth config by name",
-			"POST /api/outgoing/auth-configs":                "Create new auth config",
-			"PUT /api/outgoing/auth-configs/{name}":          "Update auth config",
-			"DELETE /api/outgoing/auth-configs/{name}":       "Delete auth config",
-			"POST /api/outgoing/auth-configs/{name}/token":   "Manually set token for auth config",
-			"POST /api/outgoing/auth-configs/{name}/refresh": "Force refresh token for auth config",
-			"GET /api/outgoing/auth-configs/{name}/status":   "Get token status for auth config",
-			"GET /api/outgoing/control":                      "Get scheduler control status",
-			"POST /api/outgoing/control":                     "Control scheduler (pause, resume, emergency_stop)",
-			"POST /api/outgoing/control/endpoint":            "Enable/disable specific outgoing endpoint",
-			"POST /api/outgoing/control/endpoints/bulk":      "Enable/disable multiple outgoing endpoints",
-			"POST /api/outgoing/control/endpoints/all":       "Enable/disable all outgoing endpoints",
-			"GET /api/config/export":                         "Export full config as YAML",
-			"POST /api/config/import":                        "Import full config from YAML",
-
-			// Incoming Routes CRUD
-			"GET /api/incoming/routes":           "List all incoming routes",
-			"GET /api/incoming/routes/{name}":    "Get incoming route by name",
-			"POST /api/incoming/routes":          "Create new incoming route",
-			"PUT /api/incoming/routes/{name}":    "Update incoming route",
-			"DELETE /api/incoming/routes/{name}": "Delete incoming route",
-			"POST /api/incoming/routes/reload":   "Reload incoming routes from static config",
-
-			// Incoming Routes Control
-			"GET /api/incoming/control":        "Get incoming routes status",
-			"POST /api/incoming/control":       "Enable/disable all incoming routes",
-			"POST /api/incoming/control/route": "Enable/disable specific incoming route",
-
-			// Simulated Routes
-			"* /sim/*": "Simulated incoming routes (responds based on configured patterns)",
+			"swagger":      "/api/docs/swagger",
+			"redoc":        "/api/docs/redoc",
+			"openapi":      "/api/docs/openapi.yaml",
+			"openapi_json": "/api/docs/openapi.json",
 		},
+		"endpoints": s.routeList(),
 	}
 	writeJSON(w, info)
 }
 
+// routeList describes the registered operations by "METHOD path"
+func (s *Server) routeList() map[string]string {
+	list := map[string]string{}
+	for _, group := range s.apiRoutes {
+		for _, op := range group.operations {
+			if !op.undocumented {
+				list[op.method+" "+op.docPath()] = op.summary
+			}
+		}
+	}
+	return list
+}
+
 // Start starts the API server
 func (s *Server) Start() error {
-	return s.server.ListenAndServe()
+	if !s.TLSEnabled() {
+		return s.server.ListenAndServe()
+	}
+
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	sniffer := newTLSSniffer(ln, s.server.TLSConfig)
+	go s.redirectServer.Serve(sniffer.Plain())
+	return s.server.Serve(sniffer.TLS())
 }
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	s.stopStreams()
+	if s.redirectServer != nil {
+		s.redirectServer.Shutdown(ctx)
+	}
+	err := s.server.Shutdown(ctx)
+	if s.accessLog != nil {
+		s.accessLog.close()
+	}
+	return err
 }
 
 // Addr returns the server address
@@ -304,6 +323,9 @@ func (s *Server) Addr() string {
 
 // GetListenAddr returns a formatted listen address string
 func (s *Server) GetListenAddr() string {
+	if s.TLSEnabled() {
+		return fmt.Sprintf("https://localhost%s", s.server.Addr)
+	}
 	return fmt.Sprintf("http://localhost%s", s.server.Addr)
 }
 
@@ -315,67 +337,11 @@ func (s *Server) getConfigForHandlers() *config.Config {
 	return s.config
 }
 
-// handleEndpoints router helper - checks if config manager is available
+// checkConfigManager answers 503 when the config manager isn't available
 func (s *Server) checkConfigManager(w http.ResponseWriter) bool {
 	if s.configManager == nil {
-		writeError(w, "configuration manager not available - endpoint CRUD operations disabled", http.StatusServiceUnavailable)
+		writeError(w, "configuration manager not available", http.StatusServiceUnavailable)
 		return false
 	}
 	return true
 }
-
-// handleEndpointsRoute routes endpoint requests, checking config manager for write operations
-func (s *Server) handleEndpointsRoute(w http.ResponseWriter, r *http.Request) {
-	// Check if it's a request for a specific endpoint
-	path := strings.TrimPrefix(r.URL.Path, "/api/outgoing/endpoints")
-	hasName := path != "" && path != "/"
-
-	// For GET requests, we can work without config manager (fallback to legacy)
-	if r.Method == http.MethodGet {
-		if hasName {
-			// Get specific endpoint
-			name := strings.TrimPrefix(path, "/")
-			if s.configManager != nil {
-				endpoint, err := s.configManager.GetEndpoint(name)
-				if err != nil {
-					writeError(w, err.Error(), http.StatusNotFound)
-					return
-				}
-				writeJSON(w, endpoint)
-			} else {
-				// Fallback to legacy config
-				cfg := s.getConfigForHandlers()
-				for _, ep := range cfg.Endpoints {
-					if ep.Name == name {
-						writeJSON(w, ep)
-						return
-					}
-				}
-				writeError(w, "endpoint not found: "+name, http.StatusNotFound)
-			}
-		} else {
-			// List all endpoints
-			cfg := s.getConfigForHandlers()
-			response := map[string]interface{}{
-				"count":     len(cfg.Endpoints),
-				"endpoints": cfg.Endpoints,
-			}
-			writeJSON(w, response)
-		}
-		return
-	}
-
-	// For write operations, require config manager
-	if !s.checkConfigManager(w) {
-		return
-	}
-	s.handleEndpoints(w, r)
-}
-
-// handleBulkEndpointsRoute routes bulk endpoint requests with config manager check
-func (s *Server) handleBulkEndpointsRoute(w http.ResponseWriter, r *http.Request) {
-	if !s.checkConfigManager(w) {
-		return
-	}
-	s.handleBulkEndpoints(w, r)
-}
@@ -0,0 +1,138 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"moxapp/internal/metrics"
+)
+
+// Update intervals of the metrics stream
+const (
+	defaultStreamInterval = 2 * time.Second
+	minStreamInterval     = time.Second
+)
+
+// handleMetricsStream streams outgoing and incoming time series as
+// server-sent events for live charts. A "history" event with the series of
+// the last ?range= is sent first, then an "update" event every ?interval=
+// with the points of the previous and the current step; clients replace
+// points by timestamp.
+// GET /api/metrics/stream?endpoint=&route=&range=15m&step=&points=&interval=2s
+func (s *Server) handleMetricsStream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	seriesRange := defaultSeriesRange
+	if value := query.Get("range"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 || d > metrics.BucketRetention {
+			writeError(w, fmt.Sprintf("invalid range %q (must be a duration up to %s)", value, metrics.BucketRetention), http.StatusBadRequest)
+			return
+		}
+		seriesRange = d
+	}
+	interval := defaultStreamInterval
+	if value := query.Get("interval"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < minStreamInterval {
+			writeError(w, fmt.Sprintf("invalid interval %q (must be a duration of at least %s)", value, minStreamInterval), http.StatusBadRequest)
+			return
+		}
+		interval = d
+	}
+	now := time.Now()
+	step, err := parseSeriesStep(r, now.Add(-seriesRange), now)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	step = metrics.SeriesInterval(step)
+
+	endpoint := query.Get("endpoint")
+	route := query.Get("route")
+	if route != "" && s.incomingMetrics == nil {
+		writeError(w, "incoming metrics not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// series returns the event data for the steps since from
+	series := func(from time.Time) (map[string]interface{}, error) {
+		to := time.Now()
+		data := map[string]interface{}{
+			"endpoint":     endpoint,
+			"step_seconds": step.Seconds(),
+			"timestamp":    to.UTC().Format(time.RFC3339),
+		}
+		outgoing, err := s.metrics.DownsampledTimeseries(endpoint, from, to, step)
+		if err != nil {
+			return nil, err
+		}
+		data["outgoing"] = outgoing
+		if s.incomingMetrics != nil {
+			incoming, err := s.incomingMetrics.Timeseries(route, from, to, step)
+			if err != nil {
+				return nil, err
+			}
+			data["route"] = route
+			data["incoming"] = incoming
+		}
+		return data, nil
+	}
+
+	history, err := series(now.Add(-seriesRange))
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// Streams outlive the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, data interface{}) bool {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send("history", history) {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.streams.Done():
+			return
+		case <-ticker.C:
+		}
+
+		update, err := series(time.Now().Add(-step))
+		if err != nil {
+			// The endpoint or route is gone until its next request, e.g.
+			// after a metrics reset
+			if !send("error", map[string]string{"error": err.Error()}) {
+				return
+			}
+			continue
+		}
+		if !send("update", update) {
+			return
+		}
+	}
+}
@@ -2,17 +2,11 @@
 package api
 
 import (
-	_ "embed"
 	"fmt"
 	"net/http"
-	"strings"
-)
-
-//go:embed openapi.yaml
-var openAPISpec []byte
 
-// serverURLPlaceholder is replaced at runtime with the actual server URL
-const serverURLPlaceholder = "__SERVER_URL__"
+	"gopkg.in/yaml.v3"
+)
 
 // SwaggerUIHTML is the HTML template for Swagger UI
 // Uses unpkg CDN to load Swagger UI assets
@@ -94,8 +88,9 @@ func (s *Server) handleReDoc(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(ReDocHTML))
 }
 
-// handleOpenAPISpec serves the OpenAPI specification file with dynamic server URL
-func (s *Server) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+// handleOpenAPISpec serves the OpenAPI specification generated from the
+// registered routes, with the server URL of the request, as YAML or JSON
+func (s *Server) handleOpenAPISpec(w http.ResponseWriter, r *http.Request, asJSON bool) {
 	// Determine the server URL from the request
 	scheme := "http"
 	if r.TLS != nil {
@@ -107,33 +102,36 @@ func (s *Server) handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
 	}
 
 	serverURL := fmt.Sprintf("%s://%s", scheme, r.Host)
+	spec := s.openAPIDocument(serverURL)
 
-	// Replace placeholder with actual server URL
-	spec := strings.Replace(string(openAPISpec), serverURLPlaceholder, serverURL, 1)
+	if asJSON {
+		writeJSON(w, spec)
+		return
+	}
 
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		writeError(w, "failed to serialize OpenAPI document", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/yaml")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Write([]byte(spec))
+	w.Write(data)
 }
 
-// handleDocsRoute routes documentation requests
-func (s *Server) handleDocsRoute(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/api/docs")
+// handleDocsRedirect redirects to Swagger UI
+// GET /api/docs
+func (s *Server) handleDocsRedirect(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/api/docs/swagger", http.StatusMovedPermanently)
+}
 
-	switch {
-	case path == "" || path == "/":
-		// Redirect to Swagger UI
-		http.Redirect(w, r, "/api/docs/swagger", http.StatusMovedPermanently)
-	case path == "/swagger" || path == "/swagger/":
-		s.handleSwaggerUI(w, r)
-	case path == "/redoc" || path == "/redoc/":
-		s.handleReDoc(w, r)
-	case path == "/openapi.yaml" || path == "/openapi.yml":
-		s.handleOpenAPISpec(w, r)
-	case path == "/openapi.json":
-		// Could add YAML to JSON conversion here if needed
-		writeError(w, "JSON format not supported, use /api/docs/openapi.yaml", http.StatusNotImplemented)
-	default:
-		http.NotFound(w, r)
-	}
+// handleOpenAPIYAML serves the OpenAPI document as YAML
+// GET /api/docs/openapi.yaml
+func (s *Server) handleOpenAPIYAML(w http.ResponseWriter, r *http.Request) {
+	s.handleOpenAPISpec(w, r, false)
+}
+
+// handleOpenAPIJSON serves the OpenAPI document as JSON
+// GET /api/docs/openapi.json
+func (s *Server) handleOpenAPIJSON(w http.ResponseWriter, r *http.Request) {
+	s.handleOpenAPISpec(w, r, true)
 }
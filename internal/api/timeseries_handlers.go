@@ -0,0 +1,140 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"moxapp/internal/metrics"
+)
+
+// defaultSeriesRange is the time series included in endpoint detail metrics
+const defaultSeriesRange = 15 * time.Minute
+
+// handleGetTimeseries returns outgoing metrics per bucket interval for graphs
+// GET /api/metrics/timeseries?endpoint=name&from=15m&to=&step=1m
+func (s *Server) handleGetTimeseries(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseTimeRange(r, metrics.BucketRetention)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	step, err := parseSeriesStep(r, from, to)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	endpoint := r.URL.Query().Get("endpoint")
+	points, err := s.metrics.DownsampledTimeseries(endpoint, from, to, step)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"endpoint":         endpoint,
+		"interval_seconds": metrics.SeriesInterval(step).Seconds(),
+		"count":            len(points),
+		"points":           points,
+	})
+}
+
+// handleGetIncomingTimeseries returns incoming route metrics per bucket
+// interval for graphs
+// GET /api/metrics/incoming/timeseries?route=name&from=15m&to=&step=1m
+func (s *Server) handleGetIncomingTimeseries(w http.ResponseWriter, r *http.Request) {
+	if s.incomingMetrics == nil {
+		writeError(w, "incoming metrics not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	from, to, err := parseTimeRange(r, metrics.BucketRetention)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	step, err := parseSeriesStep(r, from, to)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	route := r.URL.Query().Get("route")
+	points, err := s.incomingMetrics.Timeseries(route, from, to, step)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"route":            route,
+		"interval_seconds": metrics.SeriesInterval(step).Seconds(),
+		"count":            len(points),
+		"points":           points,
+	})
+}
+
+// parseSeriesStep parses the downsampling parameters: ?step= is the point
+// interval (e.g. 1m) and ?points= the maximum number of points, from which
+// a step is derived. Without either, points are one bucket interval apart.
+func parseSeriesStep(r *http.Request, from, to time.Time) (time.Duration, error) {
+	query := r.URL.Query()
+	if value := query.Get("step"); value != "" {
+		if query.Get("points") != "" {
+			return 0, fmt.Errorf("step and points are mutually exclusive")
+		}
+		step, err := time.ParseDuration(value)
+		if err != nil || step <= 0 || step > metrics.BucketRetention {
+			return 0, fmt.Errorf("invalid step %q (must be a duration up to %s)", value, metrics.BucketRetention)
+		}
+		return step, nil
+	}
+	if value := query.Get("points"); value != "" {
+		points, err := strconv.Atoi(value)
+		if err != nil || points < 1 {
+			return 0, fmt.Errorf("invalid points %q (must be a positive number)", value)
+		}
+		return metrics.SeriesStep(from, to, points), nil
+	}
+	return 0, nil
+}
+
+// parseTimeRange parses the ?from= and ?to= parameters; from defaults to
+// defaultRange before now and to defaults to now
+func parseTimeRange(r *http.Request, defaultRange time.Duration) (time.Time, time.Time, error) {
+	now := time.Now()
+	query := r.URL.Query()
+	from, err := parseTimeParam(query.Get("from"), now, now.Add(-defaultRange))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := parseTimeParam(query.Get("to"), now, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %w", err)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// parseTimeParam parses a time given as RFC3339, Unix seconds or a duration
+// before now (e.g. 30m); an empty value returns def
+func parseTimeParam(value string, now, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil && ago >= 0 {
+		return now.Add(-ago), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC3339 time, Unix seconds or a duration like 30m", value)
+}
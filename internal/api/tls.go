@@ -0,0 +1,232 @@
+// Package api provides the HTTP API server for metrics and configuration
+package api
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// tlsSniffTimeout bounds how long a new connection may take to send its first byte
+const tlsSniffTimeout = 10 * time.Second
+
+// ConfigureTLS makes the server listen for HTTPS. Plain HTTP requests on the
+// same port are answered with a redirect to the HTTPS URL.
+func (s *Server) ConfigureTLS(tlsCfg config.APITLSConfig) error {
+	if !tlsCfg.Enabled {
+		return nil
+	}
+
+	var (
+		cert tls.Certificate
+		err  error
+	)
+	if tlsCfg.CertFile != "" {
+		cert, err = tls.LoadX509KeyPair(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+	} else {
+		cert, err = selfSignedCertificate(tlsCfg.Hosts)
+		if err != nil {
+			return fmt.Errorf("failed to generate self-signed certificate: %w", err)
+		}
+		fingerprint := sha256.Sum256(cert.Certificate[0])
+		log.Printf("Generated self-signed certificate for %v (SHA-256 %s)", tlsCfg.Hosts, hex.EncodeToString(fingerprint[:]))
+	}
+
+	s.server.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	s.redirectServer = &http.Server{
+		Handler:     http.HandlerFunc(redirectToHTTPS),
+		ReadTimeout: 10 * time.Second,
+	}
+	return nil
+}
+
+// TLSEnabled returns true if the server listens for HTTPS
+func (s *Server) TLSEnabled() bool {
+	return s.server.TLSConfig != nil
+}
+
+// redirectToHTTPS permanently redirects a plain HTTP request to HTTPS on the
+// same host and port. 308 keeps the method and body of POSTs to /sim routes.
+func redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	target := "https://" + r.Host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusPermanentRedirect)
+}
+
+// selfSignedCertificate generates an ECDSA certificate valid for one year
+func selfSignedCertificate(hosts []string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"moxapp"}, CommonName: "moxapp self-signed"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// tlsSniffer splits the connections of one listener into TLS connections and
+// plain ones by peeking at the first byte (0x16 starts a TLS handshake)
+type tlsSniffer struct {
+	inner     net.Listener
+	tlsConfig *tls.Config
+	tlsConns  chan net.Conn
+	plain     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+
+	errMu sync.Mutex
+	err   error // Accept error that stopped the sniffer
+}
+
+// newTLSSniffer starts accepting connections from inner
+func newTLSSniffer(inner net.Listener, tlsConfig *tls.Config) *tlsSniffer {
+	ts := &tlsSniffer{
+		inner:     inner,
+		tlsConfig: tlsConfig,
+		tlsConns:  make(chan net.Conn),
+		plain:     make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+	go ts.acceptLoop()
+	return ts
+}
+
+// acceptLoop accepts connections and sniffs each one in its own goroutine so
+// a silent client can't block other connections
+func (ts *tlsSniffer) acceptLoop() {
+	for {
+		conn, err := ts.inner.Accept()
+		if err != nil {
+			ts.errMu.Lock()
+			ts.err = err
+			ts.errMu.Unlock()
+			ts.Close()
+			return
+		}
+		go ts.sniff(conn)
+	}
+}
+
+// sniff routes a connection by its first byte
+func (ts *tlsSniffer) sniff(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(tlsSniffTimeout))
+	reader := bufio.NewReader(conn)
+	first, err := reader.Peek(1)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	peeked := &peekedConn{Conn: conn, reader: reader}
+	target, routed := ts.plain, net.Conn(peeked)
+	if first[0] == 0x16 {
+		target, routed = ts.tlsConns, tls.Server(peeked, ts.tlsConfig)
+	}
+
+	select {
+	case target <- routed:
+	case <-ts.done:
+		conn.Close()
+	}
+}
+
+// Close stops accepting connections
+func (ts *tlsSniffer) Close() error {
+	var err error
+	ts.closeOnce.Do(func() {
+		close(ts.done)
+		err = ts.inner.Close()
+	})
+	return err
+}
+
+// TLS returns a listener yielding the TLS connections
+func (ts *tlsSniffer) TLS() net.Listener {
+	return &sniffedListener{sniffer: ts, conns: ts.tlsConns}
+}
+
+// Plain returns a listener yielding the plain HTTP connections
+func (ts *tlsSniffer) Plain() net.Listener {
+	return &sniffedListener{sniffer: ts, conns: ts.plain}
+}
+
+// sniffedListener is one side of a tlsSniffer
+type sniffedListener struct {
+	sniffer *tlsSniffer
+	conns   chan net.Conn
+}
+
+func (l *sniffedListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.sniffer.done:
+		l.sniffer.errMu.Lock()
+		err := l.sniffer.err
+		l.sniffer.errMu.Unlock()
+		if err != nil && !errors.Is(err, net.ErrClosed) {
+			return nil, err
+		}
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *sniffedListener) Close() error   { return l.sniffer.Close() }
+func (l *sniffedListener) Addr() net.Addr { return l.sniffer.inner.Addr() }
+
+// peekedConn replays bytes buffered while sniffing
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
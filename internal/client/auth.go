@@ -4,6 +4,7 @@ package client
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"moxapp/internal/config"
 )
@@ -23,7 +24,7 @@ func ApplyAuth(req *http.Request, authCfg *config.AuthConfig, tokenMgr *TokenMan
 
 	switch authCfg.Type {
 	case config.AuthTypeBearer:
-		token, err := tokenMgr.GetToken(ctx, authCfg.Name)
+		token, err := tokenMgr.GetScopedToken(ctx, authCfg.Name, authCfg.Scope, authCfg.Audience)
 		if err != nil {
 			return fmt.Errorf("failed to get bearer token: %w", err)
 		}
@@ -32,7 +33,7 @@ func ApplyAuth(req *http.Request, authCfg *config.AuthConfig, tokenMgr *TokenMan
 		}
 
 	case config.AuthTypeAPIKey:
-		token, err := tokenMgr.GetToken(ctx, authCfg.Name)
+		token, err := tokenMgr.GetScopedToken(ctx, authCfg.Name, authCfg.Scope, authCfg.Audience)
 		if err != nil {
 			return fmt.Errorf("failed to get api key: %w", err)
 		}
@@ -41,7 +42,7 @@ func ApplyAuth(req *http.Request, authCfg *config.AuthConfig, tokenMgr *TokenMan
 		}
 
 	case config.AuthTypeAPIKeyQuery:
-		token, err := tokenMgr.GetToken(ctx, authCfg.Name)
+		token, err := tokenMgr.GetScopedToken(ctx, authCfg.Name, authCfg.Scope, authCfg.Audience)
 		if err != nil {
 			return fmt.Errorf("failed to get api key: %w", err)
 		}
@@ -59,7 +60,7 @@ func ApplyAuth(req *http.Request, authCfg *config.AuthConfig, tokenMgr *TokenMan
 		}
 
 	case config.AuthTypeCustom:
-		token, err := tokenMgr.GetToken(ctx, authCfg.Name)
+		token, err := tokenMgr.GetScopedToken(ctx, authCfg.Name, authCfg.Scope, authCfg.Audience)
 		if err != nil {
 			return fmt.Errorf("failed to get custom token: %w", err)
 		}
@@ -67,6 +68,20 @@ func ApplyAuth(req *http.Request, authCfg *config.AuthConfig, tokenMgr *TokenMan
 			req.Header.Set(authCfg.HeaderName, token)
 		}
 
+	case config.AuthTypeAWSSigV4:
+		accessKeyEnv, secretKeyEnv, sessionTokenEnv := authCfg.AWSCredentialEnvs()
+		creds := awsCredentials{
+			AccessKeyID:     tokenMgr.GetEnv(accessKeyEnv),
+			SecretAccessKey: tokenMgr.GetEnv(secretKeyEnv),
+			SessionToken:    tokenMgr.GetEnv(sessionTokenEnv),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return fmt.Errorf("aws credentials not set (%s, %s)", accessKeyEnv, secretKeyEnv)
+		}
+		if err := signV4(req, creds, authCfg.Region, authCfg.Service, time.Now()); err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
+
 	default:
 		return fmt.Errorf("unsupported auth type: %s", authCfg.Type)
 	}
@@ -0,0 +1,189 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"moxapp/internal/config"
+)
+
+// requestBody is a prepared request body with its content type and size
+type requestBody struct {
+	reader      io.Reader
+	contentType string
+	size        int64
+}
+
+// bodyError describes why a request body could not be built
+type bodyError struct {
+	errorType string
+	err       error
+}
+
+func (e *bodyError) Error() string {
+	return e.err.Error()
+}
+
+// bodyErrorType returns the error type of a body error, or "body" for any
+// other error building the body
+func bodyErrorType(err error) string {
+	var bodyErr *bodyError
+	if errors.As(err, &bodyErr) {
+		return bodyErr.errorType
+	}
+	return "body"
+}
+
+// buildRequestBody prepares the body for an endpoint from its JSON body
+// template, body_file, or multipart fields. Returns nil if the request has no body.
+func buildRequestBody(endpoint *config.Endpoint, rc *config.RequestContext) (*requestBody, error) {
+	if endpoint.Method != "POST" && endpoint.Method != "PUT" && endpoint.Method != "PATCH" {
+		return nil, nil
+	}
+
+	var body *requestBody
+	var err error
+	switch {
+	case len(endpoint.Multipart) > 0:
+		body, err = buildMultipartBody(endpoint.Multipart, rc)
+	case endpoint.BodyFile != "":
+		body, err = openBodyFile(endpoint.BodyFile)
+	case endpoint.Body != nil:
+		body, err = buildJSONBody(endpoint.Body, rc)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.ContentType != "" {
+		body.contentType = endpoint.ContentType
+	}
+	return body, nil
+}
+
+// buildJSONBody evaluates a body template and marshals it as JSON
+func buildJSONBody(template interface{}, rc *config.RequestContext) (*requestBody, error) {
+	evaluatedBody, err := config.EvaluateRequestBodyTemplate(template, rc)
+	if err != nil {
+		return nil, &bodyError{"template", fmt.Errorf("Body template error: %v", err)}
+	}
+
+	bodyBytes, err := json.Marshal(evaluatedBody)
+	if err != nil {
+		return nil, &bodyError{"marshal", fmt.Errorf("Body marshal error: %v", err)}
+	}
+
+	return &requestBody{
+		reader:      bytes.NewReader(bodyBytes),
+		contentType: "application/json",
+		size:        int64(len(bodyBytes)),
+	}, nil
+}
+
+// openBodyFile streams a file as the raw request body
+func openBodyFile(path string) (*requestBody, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &bodyError{"body_file", fmt.Errorf("Body file error: %v", err)}
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, &bodyError{"body_file", fmt.Errorf("Body file error: %v", err)}
+	}
+
+	return &requestBody{
+		reader:      file, // Closed by the transport
+		contentType: contentTypeForFile(path),
+		size:        info.Size(),
+	}, nil
+}
+
+// buildMultipartBody encodes multipart/form-data fields and files
+func buildMultipartBody(fields []config.MultipartField, rc *config.RequestContext) (*requestBody, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, field := range fields {
+		if field.File == "" {
+			value, err := config.EvaluateRequestTemplate(field.Value, rc)
+			if err != nil {
+				return nil, &bodyError{"template", fmt.Errorf("Multipart template error: %v", err)}
+			}
+			if err := writer.WriteField(field.Name, value); err != nil {
+				return nil, &bodyError{"body_file", fmt.Errorf("Multipart error: %v", err)}
+			}
+			continue
+		}
+
+		if err := writeMultipartFile(writer, field); err != nil {
+			return nil, &bodyError{"body_file", fmt.Errorf("Multipart file error: %v", err)}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, &bodyError{"body_file", fmt.Errorf("Multipart error: %v", err)}
+	}
+
+	return &requestBody{
+		reader:      &buf,
+		contentType: writer.FormDataContentType(),
+		size:        int64(buf.Len()),
+	}, nil
+}
+
+// writeMultipartFile copies a file into a multipart part
+func writeMultipartFile(writer *multipart.Writer, field config.MultipartField) error {
+	file, err := os.Open(field.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	filename := field.Filename
+	if filename == "" {
+		filename = filepath.Base(field.File)
+	}
+	contentType := field.ContentType
+	if contentType == "" {
+		contentType = contentTypeForFile(field.File)
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(field.Name), escapeQuotes(filename)))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	return err
+}
+
+// contentTypeForFile guesses a content type from the file extension
+func contentTypeForFile(path string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
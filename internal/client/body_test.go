@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestBuildRequestBody_Multipart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(path, []byte("hello upload"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	endpoint := &config.Endpoint{
+		Method: "POST",
+		Multipart: []config.MultipartField{
+			{Name: "title", Value: "quarterly"},
+			{Name: "file", File: path},
+		},
+	}
+
+	body, err := buildRequestBody(endpoint, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(body.contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("expected multipart/form-data, got %q", body.contentType)
+	}
+
+	data, _ := io.ReadAll(body.reader)
+	if int64(len(data)) != body.size {
+		t.Errorf("expected size %d to match body length %d", body.size, len(data))
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(data), params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("failed to parse multipart body: %v", err)
+	}
+	if form.Value["title"][0] != "quarterly" {
+		t.Errorf("expected title field, got %v", form.Value["title"])
+	}
+	if files := form.File["file"]; len(files) != 1 || files[0].Filename != "report.txt" {
+		t.Errorf("expected report.txt file part, got %v", files)
+	}
+}
+
+func TestBuildRequestBody_BodyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(path, make([]byte, 2048), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	body, err := buildRequestBody(&config.Endpoint{Method: "PUT", BodyFile: path, ContentType: "image/png"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.reader.(io.Closer).Close()
+
+	if body.size != 2048 {
+		t.Errorf("expected size 2048, got %d", body.size)
+	}
+	if body.contentType != "image/png" {
+		t.Errorf("expected content type override, got %s", body.contentType)
+	}
+
+	_, err = buildRequestBody(&config.Endpoint{Method: "POST", BodyFile: "/does/not/exist"}, nil)
+	if err == nil {
+		t.Fatal("expected error for missing body file")
+	}
+	if errorType := bodyErrorType(err); errorType != "body_file" {
+		t.Errorf("expected error type body_file, got %s", errorType)
+	}
+}
+
+func TestBodyErrorType(t *testing.T) {
+	if errorType := bodyErrorType(fmt.Errorf("wrapped: %w", &bodyError{"template", errors.New("bad")})); errorType != "template" {
+		t.Errorf("expected a wrapped body error's type, got %s", errorType)
+	}
+	if errorType := bodyErrorType(errors.New("unexpected")); errorType != "body" {
+		t.Errorf("expected the generic type body, got %s", errorType)
+	}
+}
@@ -0,0 +1,169 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// Capture reasons
+const (
+	CaptureReasonSampled = "sampled"
+	CaptureReasonFailure = "failure"
+)
+
+// CapturedResponse is a response body captured for debugging
+type CapturedResponse struct {
+	RequestID    string            `json:"request_id"`
+	EndpointName string            `json:"endpoint_name"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	StatusCode   int               `json:"status_code"`
+	Reason       string            `json:"reason"`
+	Headers      map[string]string `json:"headers"`
+	ContentType  string            `json:"content_type,omitempty"`
+	BodySize     int64             `json:"body_size"` // Full body size, including any truncated part
+	Truncated    bool              `json:"truncated"`
+	CapturedAt   time.Time         `json:"captured_at"`
+	Body         []byte            `json:"-"`
+}
+
+// CaptureStore decides which responses to capture and keeps the most recent
+// captures in a ring buffer sized by response_capture.buffer_size
+type CaptureStore struct {
+	configManager *config.Manager
+	seen          uint64 // Responses considered for sampling
+
+	captures []*CapturedResponse
+	next     int
+	mu       sync.RWMutex
+}
+
+// NewCaptureStore creates a capture store driven by the response_capture config
+func NewCaptureStore(configManager *config.Manager) *CaptureStore {
+	return &CaptureStore{configManager: configManager}
+}
+
+// decide returns the capture reason and body limit for a response, or an
+// empty reason if the response should not be captured
+func (s *CaptureStore) decide(statusCode int) (string, int64) {
+	cfg := s.configManager.GetResponseCaptureConfig()
+	if !cfg.Enabled() {
+		return "", 0
+	}
+	limit := int64(cfg.MaxBodyKB) * 1024
+
+	if cfg.Failures && statusCode >= 400 {
+		return CaptureReasonFailure, limit
+	}
+	if cfg.SampleRate > 0 && atomic.AddUint64(&s.seen, 1)%uint64(cfg.SampleRate) == 0 {
+		return CaptureReasonSampled, limit
+	}
+	return "", 0
+}
+
+// capture reads a response body up to limit bytes and stores it. The rest of
+// the body is discarded; the total size read is returned.
+func (s *CaptureStore) capture(result *RequestResult, resp *http.Response, reason string, limit int64) int64 {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, limit))
+	rest, _ := io.Copy(io.Discard, resp.Body)
+	size := int64(len(body)) + rest
+
+	headers := make(map[string]string, len(resp.Header))
+	for key, values := range resp.Header {
+		headers[key] = strings.Join(values, ", ")
+	}
+
+	s.add(&CapturedResponse{
+		RequestID:    result.RequestID,
+		EndpointName: result.EndpointName,
+		Method:       result.Method,
+		URL:          result.URL,
+		StatusCode:   resp.StatusCode,
+		Reason:       reason,
+		Headers:      headers,
+		ContentType:  resp.Header.Get("Content-Type"),
+		BodySize:     size,
+		Truncated:    rest > 0,
+		CapturedAt:   time.Now(),
+		Body:         body,
+	})
+	return size
+}
+
+// add stores a capture, overwriting the oldest one when the buffer is full
+func (s *CaptureStore) add(c *CapturedResponse) {
+	bufferSize := s.configManager.GetResponseCaptureConfig().BufferSize
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.captures) != bufferSize {
+		s.resize(bufferSize)
+	}
+	s.captures[s.next] = c
+	s.next = (s.next + 1) % len(s.captures)
+}
+
+// resize changes the buffer capacity, keeping the newest captures (caller holds lock)
+func (s *CaptureStore) resize(size int) {
+	existing := s.ordered()
+	if len(existing) > size {
+		existing = existing[len(existing)-size:]
+	}
+	s.captures = make([]*CapturedResponse, size)
+	copy(s.captures, existing)
+	s.next = len(existing) % size
+}
+
+// ordered returns stored captures oldest first (caller holds lock)
+func (s *CaptureStore) ordered() []*CapturedResponse {
+	var result []*CapturedResponse
+	for i := range s.captures {
+		if c := s.captures[(s.next+i)%len(s.captures)]; c != nil {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// List returns the stored captures, newest first
+func (s *CaptureStore) List() []CapturedResponse {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ordered := s.ordered()
+	result := make([]CapturedResponse, 0, len(ordered))
+	for i := len(ordered) - 1; i >= 0; i-- {
+		result = append(result, *ordered[i])
+	}
+	return result
+}
+
+// Get returns the capture for a request ID
+func (s *CaptureStore) Get(requestID string) (*CapturedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, c := range s.captures {
+		if c != nil && c.RequestID == requestID {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Reset clears all captures
+func (s *CaptureStore) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.captures = nil
+	s.next = 0
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func newTestCaptureStore(t *testing.T, capture config.ResponseCaptureConfig) *CaptureStore {
+	t.Helper()
+	cm := config.NewManager()
+	cfg := cm.GetConfig()
+	cfg.ResponseCapture = capture
+	cm.ReplaceConfig(cfg)
+	return NewCaptureStore(cm)
+}
+
+func TestCaptureStore_SamplingAndFailures(t *testing.T) {
+	store := newTestCaptureStore(t, config.ResponseCaptureConfig{SampleRate: 3, Failures: true})
+
+	sampled := 0
+	for i := 0; i < 9; i++ {
+		if reason, _ := store.decide(200); reason == CaptureReasonSampled {
+			sampled++
+		}
+	}
+	if sampled != 3 {
+		t.Errorf("expected 3 of 9 responses sampled, got %d", sampled)
+	}
+
+	if reason, limit := store.decide(503); reason != CaptureReasonFailure || limit != config.DefaultCaptureMaxBodyKB*1024 {
+		t.Errorf("expected failures to be captured with default limit, got %q %d", reason, limit)
+	}
+}
+
+func TestCaptureStore_RingBufferAndTruncation(t *testing.T) {
+	store := newTestCaptureStore(t, config.ResponseCaptureConfig{Failures: true, MaxBodyKB: 1, BufferSize: 2})
+
+	for i := 0; i < 3; i++ {
+		resp := &http.Response{
+			StatusCode: 500,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader(strings.Repeat("x", 1500))),
+		}
+		size := store.capture(&RequestResult{RequestID: fmt.Sprintf("req-%d", i)}, resp, CaptureReasonFailure, 1024)
+		if size != 1500 {
+			t.Errorf("expected full body size 1500, got %d", size)
+		}
+	}
+
+	captures := store.List()
+	if len(captures) != 2 || captures[0].RequestID != "req-2" || captures[1].RequestID != "req-1" {
+		t.Fatalf("expected newest two captures, got %+v", captures)
+	}
+	if _, ok := store.Get("req-0"); ok {
+		t.Error("expected oldest capture to be dropped")
+	}
+
+	c, _ := store.Get("req-2")
+	if !c.Truncated || len(c.Body) != 1024 {
+		t.Errorf("expected body truncated to 1024 bytes, got %d (truncated=%v)", len(c.Body), c.Truncated)
+	}
+}
@@ -2,13 +2,18 @@
 package client
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"moxapp/internal/config"
@@ -16,6 +21,7 @@ import (
 
 // RequestResult holds the result of an HTTP request
 type RequestResult struct {
+	RequestID        string    `json:"request_id"`
 	EndpointName     string    `json:"endpoint_name"`
 	URL              string    `json:"url"`
 	Method           string    `json:"method"`
@@ -29,14 +35,63 @@ type RequestResult struct {
 	TLSTimeMs        float64   `json:"tls_time_ms"`
 	TimeToFirstByte  float64   `json:"time_to_first_byte_ms"`
 	Hostname         string    `json:"hostname"`
-	ResponseSize     int64     `json:"response_size"`
+	RemoteAddr       string    `json:"remote_addr,omitempty"`
+	AddressFamily    string    `json:"address_family,omitempty"`
+	ResolvedIPs      []string  `json:"resolved_ips,omitempty"`
+	RequestSize      int64     `json:"request_size"`               // Bytes of request body sent
+	ResponseSize     int64     `json:"response_size"`              // Bytes of response body received, compressed if encoded
+	DecodedSize      int64     `json:"decoded_size,omitempty"`     // Uncompressed response body size, when known
+	ContentEncoding  string    `json:"content_encoding,omitempty"` // Content-Encoding of the response body
+	Redirects        int       `json:"redirects,omitempty"`        // Redirects followed
+	RedirectTimeMs   float64   `json:"redirect_time_ms,omitempty"` // Time until the last redirect was followed
+	FinalURL         string    `json:"final_url,omitempty"`        // URL of the last hop when redirects were followed
+	Apdex            string    `json:"apdex,omitempty"`            // Apdex zone: satisfied, tolerating or frustrated
+	DNSError         string    `json:"dns_error,omitempty"`        // DNS error kind: nxdomain, servfail, timeout, no_addresses or other
+	SLOBreach        bool      `json:"slo_breach,omitempty"`       // Counted against the endpoint's SLO error budget
 	RequestTimestamp time.Time `json:"request_timestamp"`
+
+	// Addresses dialed for new connections, in order, with fallbacks to
+	// other DNS answers
+	ConnectAttempts []ConnectAttempt `json:"connect_attempts,omitempty"`
+
+	// TLS version, cipher suite and certificate of a new TLS connection
+	TLS *TLSInfo `json:"tls,omitempty"`
+
+	// warm (reused) or cold (new) connection, for endpoints with cold_connections
+	Connection string `json:"connection,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"` // Run labels, set by the result sinks registry
+
+	decoded bool // DecodedSize is known
+}
+
+// UncompressedSize returns the uncompressed size of the response body and
+// whether it is known. It isn't for compressed bodies that weren't decoded.
+func (r *RequestResult) UncompressedSize() (int64, bool) {
+	return r.DecodedSize, r.decoded
+}
+
+// RemoteIP returns the IP address of the connection used for the request
+func (r *RequestResult) RemoteIP() string {
+	if r.RemoteAddr == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
 
 // Client is the HTTP client with DNS timing capabilities
 type Client struct {
 	httpClient   *http.Client
 	tokenManager *TokenManager
+	captures     *CaptureStore
+	cookies      *CookieJars
+	sequences    sync.Map // Endpoint name -> *int64 request counter
+	pinned       sync.Map // pinKey -> *http.Client of endpoints with connect_to, sni or a transport
+	ipFamily     func() string
 	logRequests  bool
 }
 
@@ -48,6 +103,10 @@ type ClientOptions struct {
 	EnvGetter    EnvGetter
 	AuthConfigs  map[string]*config.AuthConfig
 	TokenManager *TokenManager
+	IPFamily     string // dual (default), ipv4, or ipv6
+	// IPFamilySource, if set, is read on each new connection instead of
+	// IPFamily, so changes apply without a restart
+	IPFamilySource func() string
 }
 
 // DefaultOptions returns the default client options
@@ -56,28 +115,42 @@ func DefaultOptions() ClientOptions {
 		Timeout:     30 * time.Second,
 		MaxConns:    100,
 		LogRequests: false,
+		IPFamily:    config.IPFamilyDual,
 	}
 }
 
 // New creates a new HTTP client
 func New(opts ClientOptions) *Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	ipFamily := opts.IPFamilySource
+	if ipFamily == nil {
+		family := opts.IPFamily
+		ipFamily = func() string { return family }
+	}
+
 	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, DialNetwork(network, ipFamily()), addr)
+		},
 		MaxIdleConns:        opts.MaxConns,
 		MaxIdleConnsPerHost: opts.MaxConns,
 		MaxConnsPerHost:     opts.MaxConns,
 		IdleConnTimeout:     90 * time.Second,
 		DisableKeepAlives:   false,
+		DisableCompression:  true, // Accept-Encoding is set and decoded in Execute
 		ForceAttemptHTTP2:   true,
 	}
 
 	client := &Client{
 		httpClient: &http.Client{
-			Transport: transport,
-			Timeout:   opts.Timeout,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse // Don't follow redirects automatically
-			},
+			Transport:     transport,
+			Timeout:       opts.Timeout,
+			CheckRedirect: checkRedirect,
 		},
+		ipFamily:    ipFamily,
 		logRequests: opts.LogRequests,
 	}
 
@@ -93,16 +166,27 @@ func New(opts ClientOptions) *Client {
 
 // Execute executes an HTTP request for the given endpoint
 func (c *Client) Execute(ctx context.Context, endpoint *config.Endpoint) *RequestResult {
+	rc := &config.RequestContext{
+		RequestID:   newRequestID(),
+		Sequence:    c.nextSequence(endpoint.Name),
+		WorkerID:    WorkerIDFromContext(ctx),
+		Environment: endpoint.ResolvedEnv,
+	}
 	result := &RequestResult{
+		RequestID:        rc.RequestID,
 		EndpointName:     endpoint.Name,
 		Method:           endpoint.Method,
 		RequestTimestamp: time.Now(),
 	}
+	defer func() {
+		result.Apdex = endpoint.ApdexZone(result.TotalTimeMs, result.Success)
+		result.SLOBreach = endpoint.SLOBreached(result.TotalTimeMs, result.Success)
+	}()
 
 	startTime := time.Now()
 
 	// Evaluate URL template
-	evaluatedURL, err := config.EvaluateTemplate(endpoint.URLTemplate)
+	evaluatedURL, err := config.EvaluateRequestTemplate(endpoint.URLTemplate, rc)
 	if err != nil {
 		result.Error = fmt.Sprintf("Template error: %v", err)
 		result.ErrorType = "template"
@@ -113,49 +197,55 @@ func (c *Client) Execute(ctx context.Context, endpoint *config.Endpoint) *Reques
 	result.Hostname = ExtractHostname(evaluatedURL)
 
 	// Prepare request body if needed
+	body, err := buildRequestBody(endpoint, rc)
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorType = bodyErrorType(err)
+		result.TotalTimeMs = float64(time.Since(startTime).Microseconds()) / 1000.0
+		return result
+	}
 	var bodyReader io.Reader
-	if endpoint.Body != nil && (endpoint.Method == "POST" || endpoint.Method == "PUT" || endpoint.Method == "PATCH") {
-		// Evaluate body template
-		evaluatedBody, err := config.EvaluateBodyTemplate(endpoint.Body)
-		if err != nil {
-			result.Error = fmt.Sprintf("Body template error: %v", err)
-			result.ErrorType = "template"
-			result.TotalTimeMs = float64(time.Since(startTime).Microseconds()) / 1000.0
-			return result
-		}
-
-		bodyBytes, err := json.Marshal(evaluatedBody)
-		if err != nil {
-			result.Error = fmt.Sprintf("Body marshal error: %v", err)
-			result.ErrorType = "marshal"
-			result.TotalTimeMs = float64(time.Since(startTime).Microseconds()) / 1000.0
-			return result
-		}
-		bodyReader = bytes.NewReader(bodyBytes)
+	if body != nil {
+		bodyReader = body.reader
+		result.RequestSize = body.size
 	}
 
 	// Create request with context
 	req, err := http.NewRequestWithContext(ctx, endpoint.Method, evaluatedURL, bodyReader)
 	if err != nil {
+		if closer, ok := bodyReader.(io.Closer); ok {
+			closer.Close()
+		}
 		result.Error = fmt.Sprintf("Failed to create request: %v", err)
 		result.ErrorType = "request"
 		result.TotalTimeMs = float64(time.Since(startTime).Microseconds()) / 1000.0
 		return result
 	}
+	if body != nil {
+		req.ContentLength = body.size
+	}
 
 	// Set headers
 	req.Header.Set("User-Agent", "moxapp/1.0")
-	if bodyReader != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if endpoint.AcceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", endpoint.AcceptEncoding)
+	} else {
+		req.Header.Set("Accept-Encoding", DefaultAcceptEncoding)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", body.contentType)
 	}
 	for key, value := range endpoint.Headers {
 		// Evaluate header value template
-		evaluatedValue, err := config.EvaluateTemplate(value)
+		evaluatedValue, err := config.EvaluateRequestTemplate(value, rc)
 		if err != nil {
 			evaluatedValue = value // Use original if template fails
 		}
 		req.Header.Set(key, evaluatedValue)
 	}
+	if endpoint.HostHeader != "" {
+		req.Host = endpoint.HostHeader
+	}
 
 	// Apply authentication
 	if endpoint.ResolvedAuth != nil && c.tokenManager != nil {
@@ -167,21 +257,41 @@ func (c *Client) Execute(ctx context.Context, endpoint *config.Endpoint) *Reques
 		}
 	}
 
+	// Add session cookies shared within the endpoint's group
+	var jar http.CookieJar
+	if c.cookies != nil {
+		if groupJar := c.cookies.apply(req, endpoint.Group); groupJar != nil {
+			jar = groupJar
+		}
+	}
+
 	// Setup DNS/connection tracing
 	var timing TimingInfo
 	timing.RequestStart = time.Now()
 	trace := CreateClientTrace(&timing)
-	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	ctx, redirects := withRedirects(httptrace.WithClientTrace(req.Context(), trace), endpoint)
+	req = req.WithContext(ctx)
 
 	// Execute request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.httpClientFor(endpoint, req.URL.Hostname(), sendCold(endpoint)).Do(req)
 	timing.RequestDone = time.Now()
 
 	// Calculate total time
 	result.TotalTimeMs = float64(time.Since(startTime).Microseconds()) / 1000.0
+	if redirects.count > 0 {
+		result.Redirects = redirects.count
+		result.RedirectTimeMs = float64(redirects.last.Sub(timing.RequestStart).Microseconds()) / 1000.0
+	}
 
 	if err != nil {
 		errorType, errorMsg := CategorizeError(err)
+		if errors.Is(err, errTooManyRedirects) {
+			errorType, errorMsg = "redirect", fmt.Sprintf("Redirect Error: stopped after %d redirects", redirects.count)
+		}
+		if kind := timing.DNSErrorKind(err); kind != "" {
+			errorType, errorMsg = "dns", fmt.Sprintf("DNS Error (%s): %s", kind, err)
+			result.DNSError = kind
+		}
 		result.ErrorType = errorType
 		result.Error = errorMsg
 
@@ -189,27 +299,76 @@ func (c *Client) Execute(ctx context.Context, endpoint *config.Endpoint) *Reques
 		result.DNSTimeMs = timing.DNSTimeMs()
 		result.ConnectTimeMs = timing.ConnectTimeMs()
 		result.TLSTimeMs = timing.TLSTimeMs()
+		result.RemoteAddr = timing.RemoteAddr
+		result.AddressFamily = timing.AddressFamily()
+		result.ResolvedIPs = timing.ResolvedAddrs
+		result.ConnectAttempts = timing.Attempts()
+		result.TLS = timing.TLS
+		if endpoint.ColdConnections > 0 {
+			result.Connection = timing.Connection()
+		}
 		return result
 	}
 	defer resp.Body.Close()
 
-	// Read and discard body to allow connection reuse
-	bodySize, _ := io.Copy(io.Discard, resp.Body)
-	result.ResponseSize = bodySize
+	if jar != nil {
+		jar.SetCookies(req.URL, resp.Cookies())
+	}
+
+	// Read body (capturing it if sampled) and discard it to allow connection
+	// reuse. Compressed bodies are decoded for captures and with decompress.
+	wire := &countingReader{r: resp.Body}
+	var respBody io.Reader = wire
+	encoding := contentEncoding(resp)
+	decoded := encoding == ""
+	reason, limit := c.captureDecision(resp.StatusCode)
+	if encoding != "" && (endpoint.Decompress || reason != "") {
+		if decoder, ok := newDecoder(encoding, wire); ok {
+			respBody, decoded = decoder, true
+		}
+	}
+	var bodySize int64
+	if reason != "" {
+		resp.Body = io.NopCloser(respBody)
+		bodySize = c.captures.capture(result, resp, reason, limit)
+	} else {
+		bodySize, _ = io.Copy(io.Discard, respBody)
+	}
+	_, _ = io.Copy(io.Discard, wire) // Data left after a decoding error
+	result.ResponseSize = wire.n
+	result.ContentEncoding = encoding
+	if decoded {
+		result.DecodedSize, result.decoded = bodySize, true
+	}
 
 	// Set timing results
 	result.DNSTimeMs = timing.DNSTimeMs()
 	result.ConnectTimeMs = timing.ConnectTimeMs()
 	result.TLSTimeMs = timing.TLSTimeMs()
 	result.TimeToFirstByte = timing.TimeToFirstByteMs()
+	result.RemoteAddr = timing.RemoteAddr
+	result.AddressFamily = timing.AddressFamily()
+	result.ResolvedIPs = timing.ResolvedAddrs
+	result.ConnectAttempts = timing.Attempts()
+	result.TLS = timing.TLS
+	if endpoint.ColdConnections > 0 {
+		result.Connection = timing.Connection()
+	}
+
+	if result.Redirects > 0 {
+		result.FinalURL = resp.Request.URL.String()
+	}
 
 	// Set status and success
 	result.StatusCode = resp.StatusCode
-	result.Success = resp.StatusCode >= 200 && resp.StatusCode < 400
+	result.Success = endpoint.ExpectedStatus.Matches(resp.StatusCode)
 
 	if !result.Success {
 		result.ErrorType = "http"
 		result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		if len(endpoint.ExpectedStatus) > 0 {
+			result.Error += fmt.Sprintf(" (expected %s)", strings.Join(endpoint.ExpectedStatus, ", "))
+		}
 	}
 
 	return result
@@ -225,6 +384,52 @@ func (c *Client) GetTokenManager() *TokenManager {
 	return c.tokenManager
 }
 
+// SetCaptureStore enables response capture sampling
+func (c *Client) SetCaptureStore(store *CaptureStore) {
+	c.captures = store
+}
+
+// SetCookieJars enables per-group cookie jars
+func (c *Client) SetCookieJars(jars *CookieJars) {
+	c.cookies = jars
+}
+
+// captureDecision returns the capture reason and body limit for a response
+func (c *Client) captureDecision(statusCode int) (string, int64) {
+	if c.captures == nil {
+		return "", 0
+	}
+	return c.captures.decide(statusCode)
+}
+
+// nextSequence returns the next value of an endpoint's request counter
+func (c *Client) nextSequence(endpointName string) int64 {
+	counter, _ := c.sequences.LoadOrStore(endpointName, new(int64))
+	return atomic.AddInt64(counter.(*int64), 1)
+}
+
+// workerIDKey is the context key for the executing worker's ID
+type workerIDKey struct{}
+
+// WithWorkerID returns a context carrying the ID of the worker executing a
+// request, exposed to templates as {{workerID}}
+func WithWorkerID(ctx context.Context, workerID int) context.Context {
+	return context.WithValue(ctx, workerIDKey{}, workerID)
+}
+
+// WorkerIDFromContext returns the worker ID stored in ctx, or 0
+func WorkerIDFromContext(ctx context.Context) int {
+	workerID, _ := ctx.Value(workerIDKey{}).(int)
+	return workerID
+}
+
+// newRequestID returns a random ID for a request
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // SetTokenManager sets the token manager
 func (c *Client) SetTokenManager(tm *TokenManager) {
 	c.tokenManager = tm
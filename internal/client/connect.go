@@ -0,0 +1,120 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// pinKey identifies the connections of endpoints with connect_to, an SNI
+// override or a custom transport. They get a transport of their own, so a
+// pinned connection is never reused by a request to the same host that isn't
+// pinned. Cold requests get one without keep-alives.
+type pinKey struct {
+	host       string // Hostname of the request URL
+	connectTo  string
+	serverName string
+	dialer     config.Transport
+	cold       bool // Every request opens a new connection
+}
+
+// httpClientFor returns the HTTP client sending an endpoint's requests to a
+// hostname: the shared one unless the endpoint pins its connections or the
+// request must open a new connection
+func (c *Client) httpClientFor(endpoint *config.Endpoint, host string, cold bool) *http.Client {
+	key := pinKey{host: host, connectTo: endpoint.ConnectTo, serverName: endpoint.ServerName(), cold: cold}
+	if endpoint.Transport != nil {
+		key.dialer = *endpoint.Transport
+	}
+	if key == (pinKey{host: host}) {
+		return c.httpClient
+	}
+	if pinned, ok := c.pinned.Load(key); ok {
+		return pinned.(*http.Client)
+	}
+	pinned, _ := c.pinned.LoadOrStore(key, c.newPinnedClient(key))
+	return pinned.(*http.Client)
+}
+
+// sendCold reports whether a request must open a new connection, for the
+// endpoint's cold_connections share of its requests
+func sendCold(endpoint *config.Endpoint) bool {
+	return endpoint.ColdConnections > 0 && rand.Float64() < endpoint.ColdConnections
+}
+
+// newPinnedClient creates a client like the shared one whose connections go
+// through the custom transport, to connect_to for the pinned host, present
+// the SNI override, and aren't kept alive for cold requests
+func (c *Client) newPinnedClient(key pinKey) *http.Client {
+	base := c.httpClient.Transport.(*http.Transport)
+	transport := base.Clone()
+	dial := base.DialContext
+	if key.dialer != (config.Transport{}) {
+		dial = c.customDial(key.dialer)
+	}
+	if key.connectTo != "" {
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dial(ctx, network, connectAddr(addr, key.host, key.connectTo))
+		}
+	} else {
+		transport.DialContext = dial
+	}
+	if key.serverName != "" {
+		tlsConfig := &tls.Config{}
+		if base.TLSClientConfig != nil {
+			tlsConfig = base.TLSClientConfig.Clone()
+		}
+		tlsConfig.ServerName = key.serverName
+		transport.TLSClientConfig = tlsConfig
+	}
+	if key.cold {
+		transport.DisableKeepAlives = true
+	}
+
+	pinned := *c.httpClient
+	pinned.Transport = transport
+	return &pinned
+}
+
+// connectAddr returns the address to dial instead of addr: connect_to, with
+// the port of addr unless it has its own, when addr is on the pinned host
+func connectAddr(addr, host, connectTo string) string {
+	addrHost, addrPort, err := net.SplitHostPort(addr)
+	if err != nil || !strings.EqualFold(addrHost, host) {
+		return addr
+	}
+	if connectHost, connectPort, err := net.SplitHostPort(connectTo); err == nil {
+		return net.JoinHostPort(connectHost, connectPort)
+	}
+	return net.JoinHostPort(config.ConnectHost(connectTo), addrPort)
+}
+
+// customDial returns the dial function of a custom transport: its network
+// and address replace those of every connection, and its dialer options
+// apply
+func (c *Client) customDial(t config.Transport) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if timeout := t.DialTimeout(); timeout > 0 {
+		dialer.Timeout = timeout
+	}
+	if t.LocalAddress != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(t.LocalAddress)}
+	}
+	network := t.Network
+	return func(ctx context.Context, defaultNetwork, addr string) (net.Conn, error) {
+		if t.Address != "" {
+			addr = t.Address
+		}
+		if network == "" {
+			return dialer.DialContext(ctx, DialNetwork(defaultNetwork, c.ipFamily()), addr)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
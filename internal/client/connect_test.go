@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestExecute_ConnectToHostHeaderAndSNI(t *testing.T) {
+	var gotHost, gotServerName string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost, gotServerName = r.Host, r.TLS.ServerName
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	c := New(DefaultOptions())
+	// Trust the test server, whose certificate is issued for example.com
+	c.httpClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
+		RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+	}
+
+	endpoint := &config.Endpoint{
+		Name:        "pinned",
+		Method:      "GET",
+		URLTemplate: "https://node.invalid:" + port + "/",
+		ConnectTo:   "127.0.0.1",
+		HostHeader:  "api.test",
+		SNI:         "example.com",
+	}
+	result := c.Execute(context.Background(), endpoint)
+	if !result.Success {
+		t.Fatalf("expected the pinned request to succeed, got %d (%s)", result.StatusCode, result.Error)
+	}
+	if gotHost != "api.test" || gotServerName != "example.com" {
+		t.Errorf("expected Host api.test and SNI example.com, got %q and %q", gotHost, gotServerName)
+	}
+
+	// Without the override the SNI follows the Host header, which the
+	// certificate doesn't cover
+	endpoint.SNI = ""
+	if result := c.Execute(context.Background(), endpoint); result.Success {
+		t.Error("expected certificate verification against the Host header to fail")
+	}
+}
+
+func TestConnectAddr(t *testing.T) {
+	tests := []struct {
+		addr, connectTo, want string
+	}{
+		{"api.example.com:443", "10.0.0.5", "10.0.0.5:443"},
+		{"api.example.com:443", "10.0.0.5:8443", "10.0.0.5:8443"},
+		{"api.example.com:80", "[2001:db8::1]", "[2001:db8::1]:80"},
+		{"API.example.com:80", "node-1.internal", "node-1.internal:80"},
+		{"other.example.com:443", "10.0.0.5", "other.example.com:443"},
+	}
+	for _, tt := range tests {
+		if got := connectAddr(tt.addr, "api.example.com", tt.connectTo); got != tt.want {
+			t.Errorf("connectAddr(%q, %q) = %q, want %q", tt.addr, tt.connectTo, got, tt.want)
+		}
+	}
+}
+
+func TestExecute_UnixSocketTransport(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "sidecar.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	var gotHost, gotPath string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost, gotPath = r.Host, r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	c := New(DefaultOptions())
+	endpoint := &config.Endpoint{
+		Name:        "sidecar",
+		Method:      "GET",
+		URLTemplate: "http://orders.internal/v1/orders",
+		Transport:   &config.Transport{Network: config.NetworkUnix, Address: socket},
+	}
+	result := c.Execute(context.Background(), endpoint)
+	if !result.Success || result.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 through the socket, got %d (%s)", result.StatusCode, result.Error)
+	}
+	if gotHost != "orders.internal" || gotPath != "/v1/orders" {
+		t.Errorf("expected the URL's host and path, got %q and %q", gotHost, gotPath)
+	}
+
+	// Endpoints without the transport don't reuse its connections
+	plain := &config.Endpoint{Name: "plain", Method: "GET", URLTemplate: "http://orders.internal/v1/orders", Timeout: 1}
+	if result := c.Execute(context.Background(), plain); result.Success {
+		t.Error("expected the endpoint without the transport to resolve orders.internal and fail")
+	}
+}
+
+func TestExecute_ColdConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(DefaultOptions())
+	endpoint := &config.Endpoint{Name: "cold", Method: "GET", URLTemplate: server.URL + "/", ColdConnections: 1}
+	for i := 0; i < 3; i++ {
+		if result := c.Execute(context.Background(), endpoint); !result.Success || result.Connection != ConnectionCold {
+			t.Fatalf("expected every request on a new connection, got %q (%s)", result.Connection, result.Error)
+		}
+	}
+
+	// The shared pool keeps its connections, so requests after the first are warm
+	endpoint.ColdConnections = 0.000001
+	c.Execute(context.Background(), endpoint)
+	if result := c.Execute(context.Background(), endpoint); result.Connection != ConnectionWarm {
+		t.Errorf("expected a reused connection, got %q", result.Connection)
+	}
+
+	endpoint.ColdConnections = 0
+	if result := c.Execute(context.Background(), endpoint); result.Connection != "" {
+		t.Errorf("expected no connection kind without cold_connections, got %q", result.Connection)
+	}
+}
@@ -0,0 +1,113 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+
+	"moxapp/internal/config"
+)
+
+// groupJar is the cookie jar shared by the endpoints of one group
+type groupJar struct {
+	jar    *cookiejar.Jar
+	seeded map[string]bool // Hosts that received the group's seed cookies
+}
+
+// CookieJars holds one cookie jar per endpoint group with cookie_jar enabled,
+// so session cookies set by one endpoint are sent by the others
+type CookieJars struct {
+	configManager *config.Manager
+	jars          map[string]*groupJar
+	mu            sync.Mutex
+}
+
+// NewCookieJars creates an empty set of group cookie jars
+func NewCookieJars(configManager *config.Manager) *CookieJars {
+	return &CookieJars{
+		configManager: configManager,
+		jars:          make(map[string]*groupJar),
+	}
+}
+
+// apply adds the group's cookies for the request URL. Returns the jar to
+// store response cookies in, or nil if the endpoint's group has no jar.
+func (j *CookieJars) apply(req *http.Request, groupName string) *cookiejar.Jar {
+	if groupName == "" {
+		return nil
+	}
+	group, err := j.configManager.GetEndpointGroup(groupName)
+	if err != nil || !group.CookieJar {
+		return nil
+	}
+
+	j.mu.Lock()
+	gj, exists := j.jars[groupName]
+	if !exists {
+		jar, _ := cookiejar.New(nil)
+		gj = &groupJar{jar: jar, seeded: make(map[string]bool)}
+		j.jars[groupName] = gj
+	}
+	if host := req.URL.Hostname(); !gj.seeded[host] {
+		seedCookies(gj.jar, req.URL, group.Cookies)
+		gj.seeded[host] = true
+	}
+	j.mu.Unlock()
+
+	for _, cookie := range gj.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	return gj.jar
+}
+
+// seedCookies stores the configured cookies that apply to a host
+func seedCookies(jar *cookiejar.Jar, u *url.URL, seeds []config.SeedCookie) {
+	var cookies []*http.Cookie
+	for _, seed := range seeds {
+		if seed.Domain != "" && !domainMatches(u.Hostname(), seed.Domain) {
+			continue
+		}
+		value, err := config.EvaluateTemplate(seed.Value)
+		if err != nil {
+			value = seed.Value
+		}
+		path := seed.Path
+		if path == "" {
+			path = "/"
+		}
+		cookies = append(cookies, &http.Cookie{
+			Name:   seed.Name,
+			Value:  value,
+			Domain: seed.Domain,
+			Path:   path,
+		})
+	}
+	if len(cookies) > 0 {
+		jar.SetCookies(u, cookies)
+	}
+}
+
+// domainMatches reports whether host is domain or one of its subdomains
+func domainMatches(host, domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// Reset discards a group's cookies; seed cookies are applied again on the
+// next request
+func (j *CookieJars) Reset(groupName string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.jars, groupName)
+}
+
+// ResetAll discards the cookies of every group
+func (j *CookieJars) ResetAll() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jars = make(map[string]*groupJar)
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestCookieJars_SharedWithinGroup(t *testing.T) {
+	var lastSession, lastLocale string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastSession, lastLocale = "", ""
+		if c, err := r.Cookie("session"); err == nil {
+			lastSession = c.Value
+		}
+		if c, err := r.Cookie("locale"); err == nil {
+			lastLocale = c.Value
+		}
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		}
+	}))
+	defer server.Close()
+
+	cm := config.NewManager()
+	if err := cm.AddEndpointGroup(config.EndpointGroup{
+		Name:      "flow",
+		CookieJar: true,
+		Cookies:   []config.SeedCookie{{Name: "locale", Value: "en-US"}},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c := New(DefaultOptions())
+	c.SetCookieJars(NewCookieJars(cm))
+
+	login := &config.Endpoint{Name: "login", Method: "GET", URLTemplate: server.URL + "/login", Group: "flow"}
+	profile := &config.Endpoint{Name: "profile", Method: "GET", URLTemplate: server.URL + "/profile", Group: "flow"}
+	other := &config.Endpoint{Name: "other", Method: "GET", URLTemplate: server.URL + "/profile"}
+
+	c.Execute(context.Background(), login)
+	if lastLocale != "en-US" {
+		t.Errorf("expected seeded locale cookie on first request, got %q", lastLocale)
+	}
+
+	c.Execute(context.Background(), profile)
+	if lastSession != "abc" {
+		t.Errorf("expected session cookie shared within group, got %q", lastSession)
+	}
+
+	c.Execute(context.Background(), other)
+	if lastSession != "" || lastLocale != "" {
+		t.Errorf("expected no cookies outside the group, got session=%q locale=%q", lastSession, lastLocale)
+	}
+}
@@ -0,0 +1,86 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultAcceptEncoding is sent when an endpoint sets neither
+// accept_encoding nor an Accept-Encoding header, like Go's own client does
+const DefaultAcceptEncoding = "gzip"
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// contentEncoding returns the response's Content-Encoding in lower case, or
+// an empty string for identity responses
+func contentEncoding(resp *http.Response) string {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "identity" {
+		return ""
+	}
+	return encoding
+}
+
+// newDecoder returns a reader decompressing r, or false for encodings that
+// can't be decoded (such as br) and stacked encodings
+func newDecoder(encoding string, r io.Reader) (io.Reader, bool) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, false
+		}
+		return gz, true
+	case "deflate":
+		// Deflate is zlib-wrapped per RFC 9110, but some servers send raw deflate
+		return &deflateReader{src: r}, true
+	}
+	return nil, false
+}
+
+// deflateReader decodes zlib-wrapped or raw deflate data, picking the format
+// from the first bytes
+type deflateReader struct {
+	src io.Reader
+	r   io.Reader
+	err error
+}
+
+func (d *deflateReader) Read(p []byte) (int, error) {
+	if d.r == nil && d.err == nil {
+		header := make([]byte, 2)
+		n, err := io.ReadFull(d.src, header)
+		src := io.MultiReader(strings.NewReader(string(header[:n])), d.src)
+		if err != nil && n == 0 {
+			d.err = err
+			return 0, err
+		}
+		// A zlib header is a CMF byte for deflate and a checksum over both bytes
+		if n == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			if d.r, d.err = zlib.NewReader(src); d.err != nil {
+				return 0, d.err
+			}
+		} else {
+			d.r = flate.NewReader(src)
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.r.Read(p)
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestExecute_ResponseEncoding(t *testing.T) {
+	payload := strings.Repeat(`{"id":1,"name":"item"}`, 100)
+	compress := func(encoding string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		}
+		_, _ = w.Write([]byte(payload))
+		w.Close()
+		return buf.Bytes()
+	}
+
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+		switch encoding {
+		case "identity":
+			_, _ = w.Write([]byte(payload))
+			return
+		case "raw-deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+		case "br":
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte("not really brotli"))
+			return
+		default:
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		_, _ = w.Write(compress(encoding))
+	}))
+	defer server.Close()
+
+	c := New(DefaultOptions())
+
+	plain := c.Execute(context.Background(), &config.Endpoint{Name: "plain", Method: "GET", URLTemplate: server.URL + "/gzip"})
+	if acceptEncoding != DefaultAcceptEncoding {
+		t.Errorf("expected Accept-Encoding %s, got %q", DefaultAcceptEncoding, acceptEncoding)
+	}
+	if _, known := plain.UncompressedSize(); known || plain.ContentEncoding != "gzip" || plain.ResponseSize >= int64(len(payload)) {
+		t.Errorf("expected only the compressed size without decompress, got %d bytes (%s)", plain.ResponseSize, plain.ContentEncoding)
+	}
+
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate", "identity"} {
+		endpoint := &config.Endpoint{Name: encoding, Method: "GET", URLTemplate: server.URL + "/" + encoding, AcceptEncoding: "gzip, deflate, br", Decompress: true}
+		result := c.Execute(context.Background(), endpoint)
+		if size, known := result.UncompressedSize(); !known || size != int64(len(payload)) {
+			t.Errorf("%s: expected uncompressed size %d, got %d (known %t, error %q)", encoding, len(payload), size, known, result.Error)
+		}
+		if encoding != "identity" && result.ResponseSize >= int64(len(payload)) {
+			t.Errorf("%s: expected a compressed wire size, got %d", encoding, result.ResponseSize)
+		}
+	}
+	if acceptEncoding != "gzip, deflate, br" {
+		t.Errorf("expected the endpoint's Accept-Encoding, got %q", acceptEncoding)
+	}
+
+	br := c.Execute(context.Background(), &config.Endpoint{Name: "br", Method: "GET", URLTemplate: server.URL + "/br", Decompress: true})
+	if _, known := br.UncompressedSize(); known || br.ResponseSize != int64(len("not really brotli")) {
+		t.Errorf("expected brotli to be counted compressed only, got %d bytes (known %t)", br.ResponseSize, known)
+	}
+}
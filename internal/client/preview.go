@@ -0,0 +1,76 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"moxapp/internal/config"
+)
+
+// MaxPreviewBodyBytes limits the body included in a request preview
+const MaxPreviewBodyBytes = 4096
+
+// RequestPreview is an endpoint's request as it would be built, with its
+// templates evaluated once. Auth is not applied.
+type RequestPreview struct {
+	Method        string            `json:"method"`
+	URL           string            `json:"url"`
+	Headers       map[string]string `json:"headers"`
+	ContentType   string            `json:"content_type,omitempty"`
+	BodySize      int64             `json:"body_size"`
+	Body          string            `json:"body,omitempty"` // Up to MaxPreviewBodyBytes; not read for body_file
+	BodyTruncated bool              `json:"body_truncated,omitempty"`
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+// PreviewRequest evaluates an endpoint's URL, header and body templates the
+// way Execute does, collecting every error instead of stopping at the first.
+// Header templates that fail are reported, although Execute sends them as
+// written.
+func PreviewRequest(endpoint *config.Endpoint) *RequestPreview {
+	rc := &config.RequestContext{RequestID: newRequestID(), Sequence: 1, WorkerID: 1, Environment: endpoint.ResolvedEnv}
+	preview := &RequestPreview{
+		Method:  endpoint.Method,
+		Headers: make(map[string]string, len(endpoint.Headers)),
+	}
+
+	evaluatedURL, err := config.EvaluateRequestTemplate(endpoint.URLTemplate, rc)
+	if err != nil {
+		preview.Errors = append(preview.Errors, fmt.Sprintf("URL template error: %v", err))
+	}
+	preview.URL = evaluatedURL
+
+	for key, value := range endpoint.Headers {
+		evaluatedValue, err := config.EvaluateRequestTemplate(value, rc)
+		if err != nil {
+			preview.Errors = append(preview.Errors, fmt.Sprintf("header %s template error: %v", key, err))
+			evaluatedValue = value
+		}
+		preview.Headers[key] = evaluatedValue
+	}
+	if endpoint.HostHeader != "" {
+		preview.Headers["Host"] = endpoint.HostHeader
+	}
+
+	body, err := buildRequestBody(endpoint, rc)
+	if err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+		return preview
+	}
+	if body == nil {
+		return preview
+	}
+
+	preview.ContentType = body.contentType
+	preview.BodySize = body.size
+	if closer, ok := body.reader.(io.Closer); ok {
+		// body_file contents are streamed as is, so there is nothing to preview
+		closer.Close()
+		return preview
+	}
+	data, _ := io.ReadAll(io.LimitReader(body.reader, MaxPreviewBodyBytes))
+	preview.Body = string(data)
+	preview.BodyTruncated = body.size > MaxPreviewBodyBytes
+	return preview
+}
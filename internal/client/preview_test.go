@@ -0,0 +1,32 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestPreviewRequest(t *testing.T) {
+	endpoint := &config.Endpoint{
+		Method:      "POST",
+		URLTemplate: "https://example.com/orders/{{sequence}}",
+		Headers:     map[string]string{"X-Request-ID": "{{requestID}}", "X-Broken": "{{nope}}"},
+		Body:        map[string]interface{}{"worker": "{{workerID}}"},
+	}
+
+	preview := PreviewRequest(endpoint)
+
+	if preview.URL != "https://example.com/orders/1" {
+		t.Errorf("unexpected URL: %s", preview.URL)
+	}
+	if preview.Headers["X-Request-ID"] == "" || preview.Headers["X-Broken"] != "{{nope}}" {
+		t.Errorf("unexpected headers: %v", preview.Headers)
+	}
+	if preview.Body != `{"worker":"1"}` || preview.ContentType != "application/json" {
+		t.Errorf("unexpected body: %s (%s)", preview.Body, preview.ContentType)
+	}
+	if len(preview.Errors) != 1 || !strings.Contains(preview.Errors[0], "X-Broken") {
+		t.Errorf("expected a header template error, got %v", preview.Errors)
+	}
+}
@@ -0,0 +1,50 @@
+// Package client provides HTTP client functionality with DNS timing
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// errTooManyRedirects is returned when a redirect chain is longer than the
+// endpoint's max_redirects
+var errTooManyRedirects = errors.New("too many redirects")
+
+// redirectKey is the context key of a request's redirect state
+type redirectKey struct{}
+
+// redirectState carries an endpoint's redirect policy through the shared
+// http.Client and records the redirects followed
+type redirectState struct {
+	follow bool
+	max    int
+	count  int
+	last   time.Time // When the last redirect was followed
+}
+
+// withRedirects returns a context carrying the endpoint's redirect policy
+func withRedirects(ctx context.Context, endpoint *config.Endpoint) (context.Context, *redirectState) {
+	state := &redirectState{follow: endpoint.FollowRedirects, max: endpoint.RedirectLimit()}
+	return context.WithValue(ctx, redirectKey{}, state), state
+}
+
+// checkRedirect is the http.Client CheckRedirect policy. Redirects are only
+// followed for endpoints with follow_redirects; otherwise the redirect
+// response is the result.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	state, _ := req.Context().Value(redirectKey{}).(*redirectState)
+	if state == nil || !state.follow {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > state.max {
+		return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, state.max)
+	}
+	state.count = len(via)
+	state.last = time.Now()
+	return nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestExecute_Redirects(t *testing.T) {
+	// /hop/N redirects to /hop/N-1 until /hop/0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if n > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hop/%d", n-1), http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(DefaultOptions())
+
+	manual := &config.Endpoint{Name: "manual", Method: "GET", URLTemplate: server.URL + "/hop/2"}
+	if result := c.Execute(context.Background(), manual); result.StatusCode != http.StatusFound || result.Redirects != 0 {
+		t.Errorf("expected the redirect response without following, got %d after %d redirects", result.StatusCode, result.Redirects)
+	}
+
+	follow := &config.Endpoint{Name: "follow", Method: "GET", URLTemplate: server.URL + "/hop/3", FollowRedirects: true}
+	result := c.Execute(context.Background(), follow)
+	if result.StatusCode != http.StatusOK || result.Redirects != 3 || !result.Success {
+		t.Fatalf("expected 200 after 3 redirects, got %d after %d (%s)", result.StatusCode, result.Redirects, result.Error)
+	}
+	if result.FinalURL != server.URL+"/hop/0" {
+		t.Errorf("unexpected final URL %s", result.FinalURL)
+	}
+	if result.RedirectTimeMs <= 0 || result.RedirectTimeMs > result.TotalTimeMs {
+		t.Errorf("unexpected redirect time %.3fms of %.3fms", result.RedirectTimeMs, result.TotalTimeMs)
+	}
+
+	limited := &config.Endpoint{Name: "limited", Method: "GET", URLTemplate: server.URL + "/hop/5", FollowRedirects: true, MaxRedirects: 2}
+	result = c.Execute(context.Background(), limited)
+	if result.Success || result.ErrorType != "redirect" || result.Redirects != 2 {
+		t.Errorf("expected a redirect error after 2 redirects, got %q (%s) after %d", result.ErrorType, result.Error, result.Redirects)
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test vector "get-vanilla" from the AWS Signature Version 4 test suite
+func TestSignV4_GetVanilla(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	if err := signV4(req, creds, "us-east-1", "service", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("expected X-Amz-Date 20150830T123600Z, got %s", got)
+	}
+}
+
+func TestSignV4_SessionTokenAndBody(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://abc.execute-api.eu-west-1.amazonaws.com/prod/orders?b=2&a=1", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	creds := awsCredentials{AccessKeyID: "AK", SecretAccessKey: "SK", SessionToken: "TOKEN"}
+
+	if err := signV4(req, creds, "eu-west-1", "execute-api", time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.Header.Get("X-Amz-Security-Token") != "TOKEN" {
+		t.Error("expected session token header")
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("expected content-type and token to be signed, got %s", auth)
+	}
+	if got := sigV4CanonicalQuery(req.URL); got != "a=1&b=2" {
+		t.Errorf("expected sorted query, got %s", got)
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,25 +21,47 @@ import (
 
 // ManagedToken represents a token with its lifecycle state
 type ManagedToken struct {
-	Value       string
-	ExpiresAt   time.Time
-	RefreshAt   time.Time
-	LastRefresh time.Time
-	LastError   error
-	ErrorCount  int
-	mu          sync.RWMutex
+	Value        string
+	RefreshToken string
+	ExpiresAt    time.Time
+	RefreshAt    time.Time
+	LastRefresh  time.Time
+	LastError    error
+	ErrorCount   int
+	mu           sync.RWMutex
+
+	// Auth config and scope/audience overrides this token was issued for
+	authName string
+	scope    string
+	audience string
 }
 
 // TokenManager manages JWT tokens with automatic refresh
 type TokenManager struct {
-	tokens            map[string]*ManagedToken // authConfigName -> token
+	tokens            map[string]*ManagedToken // tokenKey(auth, scope, audience) -> token
 	authConfigs       map[string]*config.AuthConfig
 	httpClient        *http.Client
 	envGetter         EnvGetter
+	refreshLocks      map[string]*sync.Mutex // tokenKey -> serializes refreshes of one token
 	mu                sync.RWMutex
 	refreshInterval   time.Duration
 	stopChan          chan struct{}
 	backgroundRunning bool
+	fetchObserver     func(TokenFetch)
+}
+
+// Token grant types reported in TokenFetch
+const (
+	GrantInitial      = "initial"
+	GrantRefreshToken = "refresh_token"
+)
+
+// TokenFetch describes a single call to a token endpoint
+type TokenFetch struct {
+	AuthName string
+	Grant    string // GrantInitial or GrantRefreshToken
+	Duration time.Duration
+	Err      error
 }
 
 // TokenStatus provides information about a token's current state
@@ -48,12 +74,54 @@ type TokenStatus struct {
 	ErrorCount   int    `json:"error_count"`
 	IsExpired    bool   `json:"is_expired"`
 	NeedsRefresh bool   `json:"needs_refresh"`
+
+	HasRefreshToken bool `json:"has_refresh_token"`
+
+	// Tokens fetched for endpoint scope/audience overrides, keyed by tokenKey
+	ScopedTokens map[string]*TokenStatus `json:"scoped_tokens,omitempty"`
+}
+
+// tokenKey identifies a cached token. Tokens for the auth config's own
+// scope and audience are keyed by its name alone.
+func tokenKey(authName, scope, audience string) string {
+	if scope == "" && audience == "" {
+		return authName
+	}
+	return authName + "|" + scope + "|" + audience
+}
+
+// scopeOverrides drops scope/audience values that match the auth config's
+// own, so endpoints that don't really override share the default token
+func scopeOverrides(cfg *config.AuthConfig, scope, audience string) (string, string) {
+	if scope == cfg.Scope {
+		scope = ""
+	}
+	if audience == cfg.Audience {
+		audience = ""
+	}
+	return scope, audience
+}
+
+// scopedAuthConfig returns cfg with the scope/audience overrides applied
+func scopedAuthConfig(cfg *config.AuthConfig, scope, audience string) *config.AuthConfig {
+	if scope == "" && audience == "" {
+		return cfg
+	}
+	scoped := *cfg
+	if scope != "" {
+		scoped.Scope = scope
+	}
+	if audience != "" {
+		scoped.Audience = audience
+	}
+	return &scoped
 }
 
 // NewTokenManager creates a new token manager
 func NewTokenManager(authConfigs map[string]*config.AuthConfig, envGetter EnvGetter) *TokenManager {
 	return &TokenManager{
 		tokens:          make(map[string]*ManagedToken),
+		refreshLocks:    make(map[string]*sync.Mutex),
 		authConfigs:     authConfigs,
 		httpClient:      &http.Client{Timeout: 30 * time.Second},
 		envGetter:       envGetter,
@@ -64,9 +132,15 @@ func NewTokenManager(authConfigs map[string]*config.AuthConfig, envGetter EnvGet
 
 // GetToken returns the current token for an auth config, refreshing if needed
 func (tm *TokenManager) GetToken(ctx context.Context, authName string) (string, error) {
+	return tm.GetScopedToken(ctx, authName, "", "")
+}
+
+// GetScopedToken returns a token for an auth config requested with the given
+// scope and audience (empty = the auth config's own). Tokens are cached and
+// refreshed separately for each combination.
+func (tm *TokenManager) GetScopedToken(ctx context.Context, authName, scope, audience string) (string, error) {
 	tm.mu.RLock()
 	authCfg := tm.authConfigs[authName]
-	token := tm.tokens[authName]
 	tm.mu.RUnlock()
 
 	if authCfg == nil {
@@ -81,9 +155,15 @@ func (tm *TokenManager) GetToken(ctx context.Context, authName string) (string,
 		return tm.envGetter.GetEnv(authCfg.EnvVar), nil
 	}
 
+	scope, audience = scopeOverrides(authCfg, scope, audience)
+
+	tm.mu.RLock()
+	token := tm.tokens[tokenKey(authName, scope, audience)]
+	tm.mu.RUnlock()
+
 	// Dynamic token - check if refresh needed
 	if token == nil || time.Now().After(token.RefreshAt) {
-		return tm.refreshToken(ctx, authName, authCfg)
+		return tm.refreshToken(ctx, authName, scope, audience, authCfg)
 	}
 
 	token.mu.RLock()
@@ -92,12 +172,21 @@ func (tm *TokenManager) GetToken(ctx context.Context, authName string) (string,
 }
 
 // refreshToken fetches a new token from the token endpoint with retry logic
-func (tm *TokenManager) refreshToken(ctx context.Context, authName string, cfg *config.AuthConfig) (string, error) {
-	tm.mu.Lock()
-	defer tm.mu.Unlock()
+func (tm *TokenManager) refreshToken(ctx context.Context, authName, scope, audience string, baseCfg *config.AuthConfig) (string, error) {
+	key := tokenKey(authName, scope, audience)
+	cfg := scopedAuthConfig(baseCfg, scope, audience)
+
+	// Refreshes of different tokens don't wait for each other's retries
+	lock := tm.refreshLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	tm.mu.RLock()
+	existing := tm.tokens[key]
+	tm.mu.RUnlock()
 
 	// Check if another goroutine already refreshed
-	if token := tm.tokens[authName]; token != nil {
+	if token := existing; token != nil {
 		token.mu.RLock()
 		if time.Now().Before(token.RefreshAt) {
 			value := token.Value
@@ -107,6 +196,14 @@ func (tm *TokenManager) refreshToken(ctx context.Context, authName string, cfg *
 		token.mu.RUnlock()
 	}
 
+	// Use the stored refresh token if the provider issued one
+	var refreshTok string
+	if token := existing; token != nil {
+		token.mu.RLock()
+		refreshTok = token.RefreshToken
+		token.mu.RUnlock()
+	}
+
 	// Try to refresh with retries
 	var lastErr error
 	retryDelays := []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second}
@@ -119,10 +216,16 @@ func (tm *TokenManager) refreshToken(ctx context.Context, authName string, cfg *
 				return "", ctx.Err()
 			case <-time.After(retryDelays[attempt-1]):
 			}
-			log.Printf("Retrying token refresh for %s (attempt %d/3)", authName, attempt)
+			log.Printf("Retrying token refresh for %s (attempt %d/3)", key, attempt)
 		}
 
-		tokenValue, expiresAt, err := tm.fetchToken(ctx, cfg)
+		fetched, err := tm.observedFetch(ctx, cfg, refreshTok)
+		if err != nil && refreshTok != "" {
+			// Refresh token expired or revoked - start over with the initial grant
+			log.Printf("refresh_token grant failed for %s: %v, falling back to initial grant", key, err)
+			refreshTok = ""
+			fetched, err = tm.observedFetch(ctx, cfg, "")
+		}
 		if err == nil {
 			// Success - store token
 			refreshBeforeExpiry := time.Duration(cfg.RefreshBeforeExpiry) * time.Second
@@ -130,43 +233,102 @@ func (tm *TokenManager) refreshToken(ctx context.Context, authName string, cfg *
 				refreshBeforeExpiry = 60 * time.Second
 			}
 
+			// Rotate the refresh token; providers that don't rotate keep the old one valid
+			if fetched.refreshToken == "" {
+				fetched.refreshToken = refreshTok
+			}
+
 			newToken := &ManagedToken{
-				Value:       tokenValue,
-				ExpiresAt:   expiresAt,
-				RefreshAt:   expiresAt.Add(-refreshBeforeExpiry),
-				LastRefresh: time.Now(),
-				ErrorCount:  0,
+				Value:        fetched.value,
+				RefreshToken: fetched.refreshToken,
+				ExpiresAt:    fetched.expiresAt,
+				RefreshAt:    fetched.expiresAt.Add(-refreshBeforeExpiry),
+				LastRefresh:  time.Now(),
+				ErrorCount:   0,
+				authName:     authName,
+				scope:        scope,
+				audience:     audience,
 			}
 
-			tm.tokens[authName] = newToken
-			log.Printf("Successfully refreshed token for %s (expires at %s)", authName, expiresAt.Format(time.RFC3339))
-			return tokenValue, nil
+			tm.mu.Lock()
+			tm.tokens[key] = newToken
+			tm.mu.Unlock()
+			log.Printf("Successfully refreshed token for %s (expires at %s)", key, fetched.expiresAt.Format(time.RFC3339))
+			return fetched.value, nil
 		}
 
 		lastErr = err
-		log.Printf("Failed to refresh token for %s: %v", authName, err)
+		log.Printf("Failed to refresh token for %s: %v", key, err)
 	}
 
 	// All retries failed - keep existing token if available
-	if existingToken := tm.tokens[authName]; existingToken != nil {
+	if existingToken := existing; existingToken != nil {
 		existingToken.mu.Lock()
 		existingToken.LastError = lastErr
 		existingToken.ErrorCount++
 		value := existingToken.Value
 		existingToken.mu.Unlock()
 
-		log.Printf("Token refresh failed for %s after 3 retries, keeping existing token (error count: %d)", authName, existingToken.ErrorCount)
+		log.Printf("Token refresh failed for %s after 3 retries, keeping existing token (error count: %d)", key, existingToken.ErrorCount)
 		return value, nil
 	}
 
 	return "", fmt.Errorf("failed to refresh token after 3 retries: %w", lastErr)
 }
 
-// fetchToken makes a single attempt to fetch a token from the token endpoint
-func (tm *TokenManager) fetchToken(ctx context.Context, cfg *config.AuthConfig) (string, time.Time, error) {
+// refreshLock returns the mutex serializing refreshes of one token
+func (tm *TokenManager) refreshLock(key string) *sync.Mutex {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	lock, ok := tm.refreshLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		tm.refreshLocks[key] = lock
+	}
+	return lock
+}
+
+// SetFetchObserver registers a function called after every token endpoint call
+func (tm *TokenManager) SetFetchObserver(fn func(TokenFetch)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.fetchObserver = fn
+}
+
+// observedFetch calls fetchToken and reports the call to the fetch observer
+func (tm *TokenManager) observedFetch(ctx context.Context, cfg *config.AuthConfig, refreshToken string) (*fetchedToken, error) {
+	start := time.Now()
+	fetched, err := tm.fetchToken(ctx, cfg, refreshToken)
+
+	tm.mu.RLock()
+	observer := tm.fetchObserver
+	tm.mu.RUnlock()
+
+	if observer != nil {
+		grant := GrantInitial
+		if refreshToken != "" {
+			grant = GrantRefreshToken
+		}
+		observer(TokenFetch{AuthName: cfg.Name, Grant: grant, Duration: time.Since(start), Err: err})
+	}
+	return fetched, err
+}
+
+// fetchedToken is the result of a single token endpoint call
+type fetchedToken struct {
+	value        string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+// fetchToken makes a single attempt to fetch a token from the token endpoint.
+// With a non-empty refreshToken it performs a refresh_token grant instead of
+// the initial grant.
+func (tm *TokenManager) fetchToken(ctx context.Context, cfg *config.AuthConfig, refreshToken string) (*fetchedToken, error) {
 	endpoint := cfg.TokenEndpoint
 	if endpoint == nil {
-		return "", time.Time{}, fmt.Errorf("no token endpoint configured")
+		return nil, fmt.Errorf("no token endpoint configured")
 	}
 
 	// Build URL
@@ -175,20 +337,33 @@ func (tm *TokenManager) fetchToken(ctx context.Context, cfg *config.AuthConfig)
 		url = tm.envGetter.GetEnv(endpoint.URLEnv)
 	}
 	if url == "" {
-		return "", time.Time{}, fmt.Errorf("token endpoint URL not configured")
+		return nil, fmt.Errorf("token endpoint URL not configured")
 	}
 
 	// Build request body (evaluate templates if needed)
+	body := endpoint.Body
+	if refreshToken != "" {
+		body = refreshGrantBody(endpoint.RefreshBody, refreshToken)
+	}
+	body = withScope(body, cfg.Scope, cfg.Audience)
+
+	contentType := "application/json"
+	for key, value := range endpoint.Headers {
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			contentType = value
+		}
+	}
+
 	var bodyReader io.Reader
-	if endpoint.Body != nil {
-		evaluatedBody, err := config.EvaluateBodyTemplate(endpoint.Body)
+	if body != nil {
+		evaluatedBody, err := config.EvaluateBodyTemplate(body)
 		if err != nil {
-			return "", time.Time{}, fmt.Errorf("failed to evaluate body template: %w", err)
+			return nil, fmt.Errorf("failed to evaluate body template: %w", err)
 		}
 
-		bodyBytes, err := json.Marshal(evaluatedBody)
+		bodyBytes, err := encodeTokenBody(evaluatedBody, contentType)
 		if err != nil {
-			return "", time.Time{}, fmt.Errorf("failed to marshal body: %w", err)
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
 		}
 		bodyReader = bytes.NewReader(bodyBytes)
 	}
@@ -201,7 +376,7 @@ func (tm *TokenManager) fetchToken(ctx context.Context, cfg *config.AuthConfig)
 
 	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -220,45 +395,115 @@ func (tm *TokenManager) fetchToken(ctx context.Context, cfg *config.AuthConfig)
 	// Execute request
 	resp, err := tm.httpClient.Do(req)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", time.Time{}, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	// Parse JSON response
 	var respData map[string]interface{}
 	if err := json.Unmarshal(respBody, &respData); err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to parse JSON response: %w", err)
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
 	// Extract token using path
 	tokenValue, err := config.ExtractJSONPath(respData, endpoint.TokenPath)
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to extract token from response: %w", err)
+		return nil, fmt.Errorf("failed to extract token from response: %w", err)
 	}
 
 	tokenStr, ok := tokenValue.(string)
 	if !ok {
-		return "", time.Time{}, fmt.Errorf("token value is not a string: %T", tokenValue)
+		return nil, fmt.Errorf("token value is not a string: %T", tokenValue)
+	}
+
+	fetched := &fetchedToken{
+		value:     tokenStr,
+		expiresAt: tokenExpiry(cfg.Name, endpoint.ExpiresPath, respData, tokenStr),
 	}
 
-	// Extract expiry if configured
+	// Extract refresh token if configured (absent = provider didn't rotate it)
+	if endpoint.RefreshTokenPath != "" {
+		if value, err := config.ExtractJSONPath(respData, endpoint.RefreshTokenPath); err == nil {
+			if str, ok := value.(string); ok {
+				fetched.refreshToken = str
+			}
+		}
+	}
+
+	return fetched, nil
+}
+
+// refreshGrantBody builds the body of a refresh_token grant from the
+// configured refresh_body, filling in grant_type and refresh_token
+func refreshGrantBody(refreshBody interface{}, refreshToken string) map[string]interface{} {
+	body := map[string]interface{}{"grant_type": "refresh_token"}
+	if fields, ok := refreshBody.(map[string]interface{}); ok {
+		for key, value := range fields {
+			body[key] = value
+		}
+	}
+	body["refresh_token"] = refreshToken
+	return body
+}
+
+// withScope adds the requested scope and audience to a token request body
+func withScope(body interface{}, scope, audience string) interface{} {
+	if scope == "" && audience == "" {
+		return body
+	}
+
+	fields := map[string]interface{}{}
+	if existing, ok := body.(map[string]interface{}); ok {
+		for key, value := range existing {
+			fields[key] = value
+		}
+	} else if body != nil {
+		return body
+	}
+
+	if scope != "" {
+		fields["scope"] = scope
+	}
+	if audience != "" {
+		fields["audience"] = audience
+	}
+	return fields
+}
+
+// encodeTokenBody encodes a token request body as JSON, or as a form when the
+// token endpoint expects application/x-www-form-urlencoded (standard OAuth2)
+func encodeTokenBody(body interface{}, contentType string) ([]byte, error) {
+	fields, isMap := body.(map[string]interface{})
+	if !isMap || !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		return json.Marshal(body)
+	}
+
+	form := url.Values{}
+	for key, value := range fields {
+		form.Set(key, fmt.Sprint(value))
+	}
+	return []byte(form.Encode()), nil
+}
+
+// tokenExpiry determines when a token expires: the value at expiresPath if
+// configured, otherwise the token's own `exp` claim if it is a JWT. If both
+// are available the earlier one wins. Defaults to 1 hour.
+func tokenExpiry(authName, expiresPath string, respData map[string]interface{}, token string) time.Time {
 	var expiresAt time.Time
-	if endpoint.ExpiresPath != "" {
-		expiresValue, err := config.ExtractJSONPath(respData, endpoint.ExpiresPath)
+	if expiresPath != "" {
+		expiresValue, err := config.ExtractJSONPath(respData, expiresPath)
 		if err != nil {
-			// Default to 1 hour if expiry not found
-			log.Printf("Warning: Could not extract expiry for %s: %v, defaulting to 1 hour", cfg.Name, err)
-			expiresAt = time.Now().Add(1 * time.Hour)
+			log.Printf("Warning: Could not extract expiry for %s: %v", authName, err)
 		} else {
 			// Try to parse as seconds (int or float) or timestamp
 			switch v := expiresValue.(type) {
@@ -273,16 +518,67 @@ func (tm *TokenManager) fetchToken(ctx context.Context, cfg *config.AuthConfig)
 			case int:
 				expiresAt = time.Now().Add(time.Duration(v) * time.Second)
 			default:
-				log.Printf("Warning: Unrecognized expiry format for %s: %T, defaulting to 1 hour", cfg.Name, v)
-				expiresAt = time.Now().Add(1 * time.Hour)
+				log.Printf("Warning: Unrecognized expiry format for %s: %T", authName, v)
 			}
 		}
-	} else {
-		// Default to 1 hour if no expiry path configured
+	}
+
+	if jwtExpiry, ok := parseJWTExpiry(token); ok {
+		if expiresAt.IsZero() || jwtExpiry.Before(expiresAt) {
+			expiresAt = jwtExpiry
+		}
+	}
+
+	if expiresAt.IsZero() {
+		// Default to 1 hour if no expiry is known
 		expiresAt = time.Now().Add(1 * time.Hour)
 	}
+	return expiresAt
+}
 
-	return tokenStr, expiresAt, nil
+// Prewarm fetches every token endpoint token up front: the default token of
+// each auth config plus any scope/audience variant used by the given enabled
+// endpoints. It returns one error per token that could not be obtained.
+func (tm *TokenManager) Prewarm(ctx context.Context, endpoints []config.Endpoint) error {
+	type scopedRef struct{ authName, scope, audience string }
+
+	tm.mu.RLock()
+	refs := make(map[scopedRef]bool)
+	for name, cfg := range tm.authConfigs {
+		if cfg.TokenEndpoint != nil {
+			refs[scopedRef{authName: name}] = true
+		}
+	}
+	for _, ep := range endpoints {
+		auth := ep.ResolvedAuth
+		if !ep.Enabled || auth == nil || tm.authConfigs[auth.Name] == nil || tm.authConfigs[auth.Name].TokenEndpoint == nil {
+			continue
+		}
+		scope, audience := scopeOverrides(tm.authConfigs[auth.Name], auth.Scope, auth.Audience)
+		refs[scopedRef{auth.Name, scope, audience}] = true
+	}
+	tm.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for ref := range refs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := tm.GetScopedToken(ctx, ref.authName, ref.scope, ref.audience); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("auth %s: %w", tokenKey(ref.authName, ref.scope, ref.audience), err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
 }
 
 // SetToken manually sets a token (for API updates)
@@ -303,6 +599,7 @@ func (tm *TokenManager) SetToken(authName, token string, expiresIn time.Duration
 		RefreshAt:   refreshAt,
 		LastRefresh: time.Now(),
 		ErrorCount:  0,
+		authName:    authName,
 	}
 
 	return nil
@@ -322,7 +619,7 @@ func (tm *TokenManager) ForceRefresh(ctx context.Context, authName string) error
 		return fmt.Errorf("auth config %s does not have a token endpoint", authName)
 	}
 
-	_, err := tm.refreshToken(ctx, authName, authCfg)
+	_, err := tm.refreshToken(ctx, authName, "", "", authCfg)
 	return err
 }
 
@@ -331,8 +628,34 @@ func (tm *TokenManager) GetTokenStatus(authName string) *TokenStatus {
 	tm.mu.RLock()
 	token := tm.tokens[authName]
 	authCfg := tm.authConfigs[authName]
+	scoped := make(map[string]*ManagedToken)
+	for key, t := range tm.tokens {
+		if t.authName == authName && key != authName {
+			scoped[key] = t
+		}
+	}
 	tm.mu.RUnlock()
 
+	status := newTokenStatus(token)
+
+	// Check if this auth config has a token endpoint
+	if authCfg != nil && authCfg.TokenEndpoint == nil {
+		// Static token from env - always available
+		status.HasToken = true
+	}
+
+	if len(scoped) > 0 {
+		status.ScopedTokens = make(map[string]*TokenStatus, len(scoped))
+		for key, t := range scoped {
+			status.ScopedTokens[key] = newTokenStatus(t)
+		}
+	}
+
+	return status
+}
+
+// newTokenStatus describes the state of a managed token (nil = none fetched yet)
+func newTokenStatus(token *ManagedToken) *TokenStatus {
 	status := &TokenStatus{
 		HasToken: token != nil,
 	}
@@ -347,18 +670,13 @@ func (tm *TokenManager) GetTokenStatus(authName string) *TokenStatus {
 		status.ErrorCount = token.ErrorCount
 		status.IsExpired = time.Now().After(token.ExpiresAt)
 		status.NeedsRefresh = time.Now().After(token.RefreshAt)
+		status.HasRefreshToken = token.RefreshToken != ""
 
 		if token.LastError != nil {
 			status.LastError = token.LastError.Error()
 		}
 	}
 
-	// Check if this auth config has a token endpoint
-	if authCfg != nil && authCfg.TokenEndpoint == nil {
-		// Static token from env - always available
-		status.HasToken = true
-	}
-
 	return status
 }
 
@@ -419,18 +737,14 @@ func (tm *TokenManager) refreshExpiringTokens(ctx context.Context) {
 	for name, cfg := range tm.authConfigs {
 		authConfigsSnapshot[name] = cfg
 	}
-	for name, token := range tm.tokens {
-		tokensSnapshot[name] = token
+	for key, token := range tm.tokens {
+		tokensSnapshot[key] = token
 	}
 	tm.mu.RUnlock()
 
-	for authName, authCfg := range authConfigsSnapshot {
-		if authCfg.TokenEndpoint == nil {
-			continue
-		}
-
-		token, exists := tokensSnapshot[authName]
-		if !exists {
+	for key, token := range tokensSnapshot {
+		authCfg := authConfigsSnapshot[token.authName]
+		if authCfg == nil || authCfg.TokenEndpoint == nil {
 			continue
 		}
 
@@ -439,8 +753,8 @@ func (tm *TokenManager) refreshExpiringTokens(ctx context.Context) {
 		token.mu.RUnlock()
 
 		if needsRefresh {
-			log.Printf("Background refresh triggered for %s", authName)
-			_, _ = tm.refreshToken(ctx, authName, authCfg)
+			log.Printf("Background refresh triggered for %s", key)
+			_, _ = tm.refreshToken(ctx, token.authName, token.scope, token.audience, authCfg)
 		}
 	}
 }
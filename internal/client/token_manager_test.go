@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+type staticEnv map[string]string
+
+func (e staticEnv) GetEnv(key string) string { return e[key] }
+
+func testJWT(exp time.Time) string {
+	payload, _ := json.Marshal(map[string]interface{}{"sub": "moxapp", "exp": exp.Unix()})
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func TestParseJWTExpiry(t *testing.T) {
+	exp := time.Unix(1900000000, 0)
+	got, ok := parseJWTExpiry(testJWT(exp))
+	if !ok || !got.Equal(exp) {
+		t.Fatalf("expected %v, got %v (ok=%v)", exp, got, ok)
+	}
+
+	for _, token := range []string{"opaque-token", "a.b.c", "eyJhbGciOiJIUzI1NiJ9.e30.sig"} {
+		if _, ok := parseJWTExpiry(token); ok {
+			t.Errorf("expected %q not to yield an expiry", token)
+		}
+	}
+}
+
+func TestTokenManager_RefreshTokenRotation(t *testing.T) {
+	var grants []string
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		grants = append(grants, body["grant_type"])
+		received = append(received, body["refresh_token"])
+
+		if body["refresh_token"] == "rt-revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		n := len(grants)
+		// Expires inside the refresh window so every GetToken refreshes
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token":  testJWT(time.Now().Add(30 * time.Second)),
+			"refresh_token": fmt.Sprintf("rt-%d", n),
+		})
+	}))
+	defer server.Close()
+
+	auth := &config.AuthConfig{
+		Name: "oauth",
+		Type: config.AuthTypeBearer,
+		TokenEndpoint: &config.TokenEndpointConfig{
+			URL:              server.URL,
+			Body:             map[string]interface{}{"grant_type": "client_credentials"},
+			TokenPath:        "access_token",
+			RefreshTokenPath: "refresh_token",
+		},
+	}
+	tm := NewTokenManager(map[string]*config.AuthConfig{"oauth": auth}, staticEnv{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := tm.GetToken(ctx, "oauth"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	wantGrants := []string{"client_credentials", "refresh_token", "refresh_token"}
+	wantTokens := []string{"", "rt-1", "rt-2"}
+	for i := range wantGrants {
+		if grants[i] != wantGrants[i] || received[i] != wantTokens[i] {
+			t.Fatalf("call %d: expected %s/%q, got %s/%q", i, wantGrants[i], wantTokens[i], grants[i], received[i])
+		}
+	}
+
+	status := tm.GetTokenStatus("oauth")
+	if !status.HasRefreshToken {
+		t.Error("expected refresh token to be stored")
+	}
+	expiresAt, _ := time.Parse(time.RFC3339, status.ExpiresAt)
+	if time.Until(expiresAt) > time.Minute {
+		t.Errorf("expected JWT exp to be used, got expiry %s", status.ExpiresAt)
+	}
+
+	// A rejected refresh token falls back to the initial grant
+	tm.tokens["oauth"].RefreshToken = "rt-revoked"
+	if _, err := tm.GetToken(ctx, "oauth"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last := grants[len(grants)-1]; last != "client_credentials" {
+		t.Errorf("expected fallback to initial grant, got %s", last)
+	}
+}
+
+func TestTokenManager_ScopedTokens(t *testing.T) {
+	var requests []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-" + body["scope"] + "-" + body["audience"],
+			"expires_in":   3600,
+		})
+	}))
+	defer server.Close()
+
+	auth := &config.AuthConfig{
+		Name:  "oauth",
+		Type:  config.AuthTypeBearer,
+		Scope: "read",
+		TokenEndpoint: &config.TokenEndpointConfig{
+			URL:         server.URL,
+			Body:        map[string]interface{}{"grant_type": "client_credentials"},
+			TokenPath:   "access_token",
+			ExpiresPath: "expires_in",
+		},
+	}
+	configs := map[string]*config.AuthConfig{"oauth": auth}
+	tm := NewTokenManager(configs, staticEnv{})
+	ctx := context.Background()
+
+	override, err := config.ResolveEndpointAuth(map[string]interface{}{"ref": "oauth", "scope": "write", "audience": "orders"}, configs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		scope, audience, want string
+	}{
+		{"", "", "token-read-"},
+		{"read", "", "token-read-"}, // same as the auth config's own scope
+		{override.Scope, override.Audience, "token-write-orders"},
+		{override.Scope, override.Audience, "token-write-orders"},
+	}
+	for _, tc := range cases {
+		got, err := tm.GetScopedToken(ctx, "oauth", tc.scope, tc.audience)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("scope %q audience %q: expected %s, got %s", tc.scope, tc.audience, tc.want, got)
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("expected one fetch per scope, got %d", len(requests))
+	}
+	if scoped := tm.GetTokenStatus("oauth").ScopedTokens; len(scoped) != 1 {
+		t.Errorf("expected 1 scoped token in status, got %d", len(scoped))
+	}
+}
+
+func TestTokenManager_Prewarm(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "invalid_client", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "ok"})
+	}))
+	defer server.Close()
+
+	newAuth := func(name, path string) *config.AuthConfig {
+		return &config.AuthConfig{
+			Name: name,
+			Type: config.AuthTypeBearer,
+			TokenEndpoint: &config.TokenEndpointConfig{
+				URL:       server.URL + path,
+				TokenPath: "access_token",
+			},
+		}
+	}
+	configs := map[string]*config.AuthConfig{
+		"good":   newAuth("good", "/token"),
+		"broken": newAuth("broken", "/broken"),
+		"static": {Name: "static", Type: config.AuthTypeBearer, EnvVar: "TOKEN"},
+	}
+	tm := NewTokenManager(configs, staticEnv{})
+
+	scoped := *configs["good"]
+	scoped.Scope = "admin"
+	endpoints := []config.Endpoint{{Name: "admin", Enabled: true, ResolvedAuth: &scoped}}
+
+	// Deadline cuts the retries short
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err := tm.Prewarm(ctx, endpoints)
+	if err == nil || !strings.Contains(err.Error(), "auth broken") {
+		t.Fatalf("expected error for broken auth, got %v", err)
+	}
+	if strings.Contains(err.Error(), "auth good") {
+		t.Errorf("unexpected error for good auth: %v", err)
+	}
+
+	status := tm.GetTokenStatus("good")
+	if !status.HasToken || len(status.ScopedTokens) != 1 {
+		t.Errorf("expected default and scoped tokens to be cached, got %+v", status)
+	}
+}
@@ -4,11 +4,16 @@ package client
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http/httptrace"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"moxapp/internal/config"
 )
 
 // TimingInfo holds the timing information for a request
@@ -23,8 +28,66 @@ type TimingInfo struct {
 	RequestStart time.Time
 	RequestDone  time.Time
 
+	RemoteAddr    string   // Address of the connection used (set for new and reused connections)
+	ResolvedAddrs []string // IP addresses returned by the DNS lookup
+	TLS           *TLSInfo // Negotiated parameters of the last TLS handshake
+	GotConn       bool     // A connection was obtained for the request
+	Reused        bool     // The connection was reused from the pool
+
 	DNSError     error
 	ConnectError error
+
+	// Dials to each address tried for a new connection. With several DNS
+	// answers, failed addresses fall back to the next and Happy Eyeballs
+	// races IPv6 and IPv4, so attempts may run concurrently.
+	ConnectAttempts []ConnectAttempt
+
+	mu sync.Mutex // Guards connect fields, set from concurrent dials
+}
+
+// ConnectAttempt is one dial to an address while opening a connection
+type ConnectAttempt struct {
+	Addr       string    `json:"addr"` // host:port dialed
+	Network    string    `json:"network"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	start      time.Time // When the dial started, until it is done
+}
+
+// TLSInfo describes a completed TLS handshake and the server's certificate
+type TLSInfo struct {
+	ServerName   string    `json:"server_name,omitempty"` // SNI sent
+	Version      string    `json:"version"`               // e.g. TLS 1.3
+	CipherSuite  string    `json:"cipher_suite"`
+	ALPN         string    `json:"alpn,omitempty"`         // Negotiated protocol, e.g. h2
+	CertSubject  string    `json:"cert_subject,omitempty"` // Common name of the leaf certificate
+	CertIssuer   string    `json:"cert_issuer,omitempty"`
+	CertNotAfter time.Time `json:"cert_not_after,omitempty"`
+}
+
+// NewTLSInfo returns the TLS details of a connection state
+func NewTLSInfo(state tls.ConnectionState) *TLSInfo {
+	info := &TLSInfo{
+		ServerName:  state.ServerName,
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+	}
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		info.CertSubject = leaf.Subject.CommonName
+		info.CertIssuer = leaf.Issuer.CommonName
+		info.CertNotAfter = leaf.NotAfter
+	}
+	return info
+}
+
+// IP returns the IP address dialed, or the address itself if it has no port
+func (a ConnectAttempt) IP() string {
+	if host, _, err := net.SplitHostPort(a.Addr); err == nil {
+		return host
+	}
+	return a.Addr
 }
 
 // DNSTimeMs returns the DNS resolution time in milliseconds
@@ -35,8 +98,12 @@ func (t *TimingInfo) DNSTimeMs() float64 {
 	return float64(t.DNSDone.Sub(t.DNSStart).Microseconds()) / 1000.0
 }
 
-// ConnectTimeMs returns the TCP connect time in milliseconds
+// ConnectTimeMs returns the TCP connect time in milliseconds, from the first
+// dial to the one that connected, including failed attempts before it
 func (t *TimingInfo) ConnectTimeMs() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	if t.ConnectDone.IsZero() || t.ConnectStart.IsZero() {
 		return 0
 	}
@@ -59,6 +126,95 @@ func (t *TimingInfo) TimeToFirstByteMs() float64 {
 	return float64(t.FirstByte.Sub(t.RequestStart).Microseconds()) / 1000.0
 }
 
+// AddressFamily returns the address family (ipv4 or ipv6) of the connection used
+func (t *TimingInfo) AddressFamily() string {
+	return AddressFamilyOf(t.RemoteAddr)
+}
+
+// Connection kinds of requests to endpoints measuring cold connections
+const (
+	ConnectionWarm = "warm" // Sent on a reused connection
+	ConnectionCold = "cold" // Sent on a new connection
+)
+
+// Connection returns the kind of connection the request was sent on, or an
+// empty string if it got none
+func (t *TimingInfo) Connection() string {
+	switch {
+	case !t.GotConn:
+		return ""
+	case t.Reused:
+		return ConnectionWarm
+	default:
+		return ConnectionCold
+	}
+}
+
+// Attempts returns the connect attempts made so far
+func (t *TimingInfo) Attempts() []ConnectAttempt {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.ConnectAttempts) == 0 {
+		return nil
+	}
+	return append([]ConnectAttempt(nil), t.ConnectAttempts...)
+}
+
+// DNS error kinds of failed lookups. Go's resolver reports a name without
+// records like one that doesn't exist, so both are nxdomain.
+const (
+	DNSErrorNXDomain    = "nxdomain"     // The name does not exist
+	DNSErrorServFail    = "servfail"     // The server answered SERVFAIL or another error code
+	DNSErrorTimeout     = "timeout"      // The server did not answer in time
+	DNSErrorNoAddresses = "no_addresses" // The answer has no addresses of the IP family used
+	DNSErrorOther       = "other"
+)
+
+// errServerMisbehaving is the message of net.DNSError for SERVFAIL and
+// unexpected response codes
+const errServerMisbehaving = "server misbehaving"
+
+// DNSErrorKind returns the kind of DNS error that failed a request, from the
+// lookup error reported by DNSDone or else the request error, or an empty
+// string if the request did not fail resolving its hostname
+func (t *TimingInfo) DNSErrorKind(err error) string {
+	if errors.Is(err, context.Canceled) {
+		return ""
+	}
+
+	lookupErr := t.DNSError
+	if lookupErr == nil {
+		lookupErr = err
+	}
+	var dnsErr *net.DNSError
+	if errors.As(lookupErr, &dnsErr) {
+		switch {
+		case dnsErr.IsTimeout:
+			return DNSErrorTimeout
+		case dnsErr.IsNotFound:
+			return DNSErrorNXDomain
+		case dnsErr.Err == errServerMisbehaving:
+			return DNSErrorServFail
+		default:
+			return DNSErrorOther
+		}
+	}
+	if t.DNSError != nil {
+		return DNSErrorOther
+	}
+
+	// The lookup succeeded, but without an address to dial
+	var addrErr *net.AddrError
+	if errors.As(err, &addrErr) && addrErr.Err == "no suitable address found" {
+		return DNSErrorNoAddresses
+	}
+	if !t.DNSDone.IsZero() && len(t.ResolvedAddrs) == 0 {
+		return DNSErrorNoAddresses
+	}
+	return ""
+}
+
 // CreateClientTrace creates an httptrace.ClientTrace that populates TimingInfo
 func CreateClientTrace(timing *TimingInfo) *httptrace.ClientTrace {
 	return &httptrace.ClientTrace{
@@ -68,19 +224,58 @@ func CreateClientTrace(timing *TimingInfo) *httptrace.ClientTrace {
 		DNSDone: func(info httptrace.DNSDoneInfo) {
 			timing.DNSDone = time.Now()
 			timing.DNSError = info.Err
+			for _, addr := range info.Addrs {
+				timing.ResolvedAddrs = append(timing.ResolvedAddrs, addr.IP.String())
+			}
 		},
 		ConnectStart: func(network, addr string) {
-			timing.ConnectStart = time.Now()
+			now := time.Now()
+			timing.mu.Lock()
+			defer timing.mu.Unlock()
+			// The first dial of a connection, also after a redirect to
+			// another host once the previous connection is established
+			if timing.ConnectStart.IsZero() || (!timing.ConnectDone.IsZero() && timing.ConnectError == nil) {
+				timing.ConnectStart = now
+				timing.ConnectDone = time.Time{}
+			}
+			timing.ConnectAttempts = append(timing.ConnectAttempts, ConnectAttempt{Addr: addr, Network: network, start: now})
 		},
 		ConnectDone: func(network, addr string, err error) {
-			timing.ConnectDone = time.Now()
-			timing.ConnectError = err
+			now := time.Now()
+			timing.mu.Lock()
+			defer timing.mu.Unlock()
+			// A failed attempt doesn't overwrite the one that connected
+			if err == nil || timing.ConnectError != nil || timing.ConnectDone.IsZero() {
+				timing.ConnectDone = now
+				timing.ConnectError = err
+			}
+			for i := range timing.ConnectAttempts {
+				attempt := &timing.ConnectAttempts[i]
+				if attempt.Addr == addr && attempt.Network == network && !attempt.start.IsZero() {
+					attempt.DurationMs = float64(now.Sub(attempt.start).Microseconds()) / 1000.0
+					attempt.start = time.Time{}
+					if err != nil {
+						attempt.Error = err.Error()
+					}
+					break
+				}
+			}
 		},
 		TLSHandshakeStart: func() {
 			timing.TLSStart = time.Now()
 		},
 		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
 			timing.TLSDone = time.Now()
+			if err == nil {
+				timing.TLS = NewTLSInfo(state)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			timing.GotConn = true
+			timing.Reused = info.Reused
+			if info.Conn != nil && info.Conn.RemoteAddr() != nil {
+				timing.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
 		},
 		GotFirstResponseByte: func() {
 			timing.FirstByte = time.Now()
@@ -88,6 +283,42 @@ func CreateClientTrace(timing *TimingInfo) *httptrace.ClientTrace {
 	}
 }
 
+// AddressFamilyOf returns "ipv4" or "ipv6" for an address (host:port or bare IP),
+// or an empty string if the address is not an IP
+func AddressFamilyOf(addr string) string {
+	if addr == "" {
+		return ""
+	}
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return config.IPFamilyIPv4
+	}
+	return config.IPFamilyIPv6
+}
+
+// DialNetwork maps a dial network (tcp, udp) to its family-restricted variant
+// for the given IP family setting
+func DialNetwork(network, ipFamily string) string {
+	switch ipFamily {
+	case config.IPFamilyIPv4:
+		if network == "tcp" || network == "udp" {
+			return network + "4"
+		}
+	case config.IPFamilyIPv6:
+		if network == "tcp" || network == "udp" {
+			return network + "6"
+		}
+	}
+	return network
+}
+
 // ExtractHostname extracts the hostname from a URL
 func ExtractHostname(rawURL string) string {
 	parsedURL, err := url.Parse(rawURL)
@@ -123,11 +354,8 @@ func CategorizeError(err error) (errorType string, errorMsg string) {
 	}
 
 	// Check for DNS errors
-	if strings.Contains(errStr, "no such host") ||
-		strings.Contains(errStr, "lookup") ||
-		strings.Contains(errStr, "dns") ||
-		strings.Contains(errStr, "getaddrinfo") ||
-		strings.Contains(errStr, "Temporary failure in name resolution") {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
 		return "dns", fmt.Sprintf("DNS Error: %s", errStr)
 	}
 
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSErrorKind(t *testing.T) {
+	lookup := func(dnsErr *net.DNSError) error {
+		return &url.Error{Op: "Get", URL: "http://api.example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: dnsErr}}
+	}
+
+	tests := []struct {
+		name   string
+		timing *TimingInfo
+		err    error
+		want   string
+	}{
+		{"nxdomain", &TimingInfo{DNSError: &net.DNSError{Err: "no such host", IsNotFound: true}}, errors.New("dial failed"), DNSErrorNXDomain},
+		{"servfail", &TimingInfo{DNSError: &net.DNSError{Err: "server misbehaving", IsTemporary: true}}, errors.New("dial failed"), DNSErrorServFail},
+		{"timeout", &TimingInfo{DNSError: &net.DNSError{Err: "i/o timeout", IsTimeout: true}}, context.DeadlineExceeded, DNSErrorTimeout},
+		{"request error without trace", &TimingInfo{}, lookup(&net.DNSError{Err: "no such host", IsNotFound: true}), DNSErrorNXDomain},
+		{"other", &TimingInfo{DNSError: errors.New("resolver broken")}, errors.New("dial failed"), DNSErrorOther},
+		{"no addresses", &TimingInfo{DNSDone: time.Now()}, &net.OpError{Op: "dial", Err: &net.AddrError{Err: "no suitable address found", Addr: "api.example.com"}}, DNSErrorNoAddresses},
+		{"cancelled", &TimingInfo{DNSError: &net.DNSError{Err: "operation was canceled"}}, context.Canceled, ""},
+		{"not a dns error", &TimingInfo{DNSDone: time.Now(), ResolvedAddrs: []string{"10.0.0.1"}}, errors.New("connection refused"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.timing.DNSErrorKind(tt.err); got != tt.want {
+				t.Errorf("DNSErrorKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if errorType, _ := CategorizeError(lookup(&net.DNSError{Err: "no such host", IsNotFound: true})); errorType != "dns" {
+		t.Errorf("expected a wrapped net.DNSError to be categorized as dns, got %s", errorType)
+	}
+}
+
+func TestClientTraceConnectAttempts(t *testing.T) {
+	var timing TimingInfo
+	trace := CreateClientTrace(&timing)
+
+	trace.ConnectStart("tcp", "[2001:db8::1]:443")
+	trace.ConnectStart("tcp", "10.0.0.1:443")
+	trace.ConnectDone("tcp", "10.0.0.1:443", errors.New("connection refused"))
+	time.Sleep(2 * time.Millisecond)
+	trace.ConnectDone("tcp", "[2001:db8::1]:443", nil)
+
+	attempts := timing.Attempts()
+	if len(attempts) != 2 || attempts[0].IP() != "2001:db8::1" || attempts[1].Error != "connection refused" {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+	if attempts[0].Error != "" || attempts[0].DurationMs < 2 {
+		t.Errorf("expected the first attempt to connect after 2ms, got %+v", attempts[0])
+	}
+	if timing.ConnectError != nil || timing.ConnectTimeMs() < attempts[0].DurationMs {
+		t.Errorf("expected the connect time to span to the attempt that connected, got %.3fms, %v", timing.ConnectTimeMs(), timing.ConnectError)
+	}
+}
+
+func TestAddressFamilyOf(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"", ""},
+		{"10.0.0.1", "ipv4"},
+		{"10.0.0.1:443", "ipv4"},
+		{"::1", "ipv6"},
+		{"[2001:db8::1]:443", "ipv6"},
+		{"::ffff:10.0.0.1", "ipv4"}, // IPv4-mapped
+		{"api.example.com:443", ""},
+		{"not an address", ""},
+	}
+	for _, tt := range tests {
+		if got := AddressFamilyOf(tt.addr); got != tt.want {
+			t.Errorf("AddressFamilyOf(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestDialNetwork(t *testing.T) {
+	tests := []struct {
+		network, family string
+		want            string
+	}{
+		{"tcp", "dual", "tcp"},
+		{"tcp", "", "tcp"},
+		{"tcp", "ipv4", "tcp4"},
+		{"tcp", "ipv6", "tcp6"},
+		{"udp", "ipv4", "udp4"},
+		{"udp", "ipv6", "udp6"},
+		{"tcp4", "ipv6", "tcp4"}, // Already restricted
+		{"unix", "ipv4", "unix"},
+	}
+	for _, tt := range tests {
+		if got := DialNetwork(tt.network, tt.family); got != tt.want {
+			t.Errorf("DialNetwork(%q, %q) = %q, want %q", tt.network, tt.family, got, tt.want)
+		}
+	}
+}
+
+func TestIPFamilySourceReadOnEachDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var family atomic.Value
+	family.Store("ipv6")
+	opts := DefaultOptions()
+	opts.IPFamilySource = func() string { return family.Load().(string) }
+	c := New(opts)
+
+	// The server only listens on 127.0.0.1
+	if resp, err := c.httpClient.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected an IPv6-only dial to an IPv4 address to fail")
+	}
+	family.Store("ipv4")
+	resp, err := c.httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the new family on the next dial, got %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,90 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+// AdaptiveConfig configures adaptive mode, in which the scheduler raises the
+// global multiplier step by step until p95 latency or error rate crosses a
+// threshold, then backs off (automated capacity finding)
+type AdaptiveConfig struct {
+	Enabled         bool    `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	IntervalSeconds int     `mapstructure:"interval" yaml:"interval" json:"interval"`                   // Evaluation period
+	Step            float64 `mapstructure:"step" yaml:"step" json:"step"`                               // Multiplier increase per healthy period
+	BackoffFactor   float64 `mapstructure:"backoff_factor" yaml:"backoff_factor" json:"backoff_factor"` // Multiplier is scaled by this on breach
+	MinMultiplier   float64 `mapstructure:"min_multiplier" yaml:"min_multiplier" json:"min_multiplier"`
+	MaxMultiplier   float64 `mapstructure:"max_multiplier" yaml:"max_multiplier" json:"max_multiplier"`
+	MaxP95Ms        float64 `mapstructure:"max_p95_ms" yaml:"max_p95_ms" json:"max_p95_ms"`             // 0 disables the latency threshold
+	MaxErrorRate    float64 `mapstructure:"max_error_rate" yaml:"max_error_rate" json:"max_error_rate"` // Percent, 0 disables the error threshold
+	MinSamples      int     `mapstructure:"min_samples" yaml:"min_samples" json:"min_samples"`          // Requests needed before a period is evaluated
+}
+
+// Default adaptive mode settings
+const (
+	DefaultAdaptiveInterval      = 30
+	DefaultAdaptiveStep          = 0.1
+	DefaultAdaptiveBackoffFactor = 0.5
+	DefaultAdaptiveMinMultiplier = 0.1
+	DefaultAdaptiveMaxMultiplier = 10.0
+	DefaultAdaptiveMinSamples    = 20
+)
+
+// Validate checks if the adaptive configuration is valid
+func (a *AdaptiveConfig) Validate() []string {
+	var errors []string
+
+	if a.IntervalSeconds < 0 {
+		errors = append(errors, "adaptive: interval must be non-negative")
+	}
+	if a.Step < 0 {
+		errors = append(errors, "adaptive: step must be non-negative")
+	}
+	if a.BackoffFactor < 0 || a.BackoffFactor >= 1 {
+		errors = append(errors, "adaptive: backoff_factor must be between 0 and 1")
+	}
+	if a.MinMultiplier < 0 || a.MaxMultiplier < 0 {
+		errors = append(errors, "adaptive: multiplier bounds must be non-negative")
+	}
+	if a.MaxMultiplier > 0 && a.MinMultiplier > a.MaxMultiplier {
+		errors = append(errors, "adaptive: min_multiplier must not exceed max_multiplier")
+	}
+	if a.MaxP95Ms < 0 || a.MaxErrorRate < 0 {
+		errors = append(errors, "adaptive: thresholds must be non-negative")
+	}
+	if a.Enabled && a.MaxP95Ms == 0 && a.MaxErrorRate == 0 {
+		errors = append(errors, "adaptive: max_p95_ms or max_error_rate is required when enabled")
+	}
+
+	return errors
+}
+
+// GetAdaptiveConfig returns the adaptive mode configuration with defaults applied
+func (m *Manager) GetAdaptiveConfig() AdaptiveConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	adaptive := m.config.Adaptive
+	if adaptive.IntervalSeconds <= 0 {
+		adaptive.IntervalSeconds = DefaultAdaptiveInterval
+	}
+	if adaptive.Step <= 0 {
+		adaptive.Step = DefaultAdaptiveStep
+	}
+	if adaptive.BackoffFactor <= 0 || adaptive.BackoffFactor >= 1 {
+		adaptive.BackoffFactor = DefaultAdaptiveBackoffFactor
+	}
+	if adaptive.MinMultiplier <= 0 {
+		adaptive.MinMultiplier = DefaultAdaptiveMinMultiplier
+	}
+	if adaptive.MaxMultiplier <= 0 {
+		adaptive.MaxMultiplier = DefaultAdaptiveMaxMultiplier
+	}
+	if adaptive.MinSamples <= 0 {
+		adaptive.MinSamples = DefaultAdaptiveMinSamples
+	}
+	return adaptive
+}
+
+// SetAdaptiveEnabled turns adaptive mode on or off
+func (m *Manager) SetAdaptiveEnabled(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config.Adaptive.Enabled = enabled
+}
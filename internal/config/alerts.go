@@ -0,0 +1,170 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlertsConfig configures alert rules, evaluated periodically against the
+// outgoing metrics. A rule notifies its webhooks when it starts firing and
+// when it resolves.
+type AlertsConfig struct {
+	IntervalSeconds int         `mapstructure:"interval" yaml:"interval,omitempty" json:"interval,omitempty"` // Evaluation period (default 15)
+	Rules           []AlertRule `mapstructure:"rules" yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// AlertRule fires while a metric of the outgoing requests within a window
+// crosses a threshold, e.g. dns_errors > 10 in 1m
+type AlertRule struct {
+	Name            string  `mapstructure:"name" yaml:"name" json:"name"`
+	Metric          string  `mapstructure:"metric" yaml:"metric" json:"metric"`                                                      // See AlertMetrics
+	Operator        string  `mapstructure:"operator" yaml:"operator,omitempty" json:"operator,omitempty"`                            // >, >=, < or <= (default >)
+	Threshold       float64 `mapstructure:"threshold" yaml:"threshold" json:"threshold"`                                             // Compared with the metric's value
+	Window          string  `mapstructure:"window" yaml:"window,omitempty" json:"window,omitempty"`                                  // 1m, 5m or 15m (default 1m)
+	Endpoint        string  `mapstructure:"endpoint" yaml:"endpoint,omitempty" json:"endpoint,omitempty"`                            // Only this endpoint (default all)
+	Tag             string  `mapstructure:"tag" yaml:"tag,omitempty" json:"tag,omitempty"`                                           // Only endpoints with this tag
+	MinRequests     int64   `mapstructure:"min_requests" yaml:"min_requests,omitempty" json:"min_requests,omitempty"`                // Requests in the window needed to evaluate the rule
+	WebhookURL      string  `mapstructure:"webhook_url" yaml:"webhook_url,omitempty" json:"webhook_url,omitempty"`                   // Receives a JSON notification
+	SlackWebhookURL string  `mapstructure:"slack_webhook_url" yaml:"slack_webhook_url,omitempty" json:"slack_webhook_url,omitempty"` // Slack incoming webhook
+}
+
+// Default alert settings
+const (
+	DefaultAlertInterval = 15
+	DefaultAlertWindow   = "1m"
+	DefaultAlertOperator = ">"
+)
+
+// AlertMetrics are the metrics alert rules can watch, with their description
+var AlertMetrics = map[string]string{
+	"requests":          "Requests completed",
+	"failures":          "Failed requests",
+	"error_rate":        "Failed requests in percent",
+	"dns_errors":        "DNS resolution failures",
+	"timeout_errors":    "Timed out requests",
+	"connection_errors": "Connection failures",
+	"http_errors":       "Responses with an unexpected status code",
+	"avg_ms":            "Average response time",
+	"p95_ms":            "95th percentile response time (highest of the endpoints)",
+	"p99_ms":            "99th percentile response time (highest of the endpoints)",
+	"dns_p95_ms":        "95th percentile DNS resolution time (highest of the endpoints)",
+}
+
+// alertWindows are the metrics windows alert rules can use
+var alertWindows = map[string]bool{"1m": true, "5m": true, "15m": true}
+
+// IsValidAlertOperator returns true if the given value is a supported comparison
+func IsValidAlertOperator(op string) bool {
+	switch op {
+	case ">", ">=", "<", "<=":
+		return true
+	}
+	return false
+}
+
+// Validate checks if the alerts configuration is valid
+func (a *AlertsConfig) Validate() []string {
+	var errors []string
+
+	if a.IntervalSeconds < 0 {
+		errors = append(errors, "alerts: interval must be non-negative")
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range a.Rules {
+		if seen[rule.Name] {
+			errors = append(errors, fmt.Sprintf("alerts: duplicate rule name: %s", rule.Name))
+		}
+		seen[rule.Name] = true
+		errors = append(errors, rule.Validate()...)
+	}
+
+	return errors
+}
+
+// Validate checks if the alert rule is valid
+func (r *AlertRule) Validate() []string {
+	var errors []string
+
+	if strings.TrimSpace(r.Name) == "" {
+		errors = append(errors, "alerts: rule name is required")
+	}
+	if _, ok := AlertMetrics[r.Metric]; !ok {
+		errors = append(errors, fmt.Sprintf("alerts: rule %s: unknown metric %q", r.Name, r.Metric))
+	}
+	if r.Operator != "" && !IsValidAlertOperator(r.Operator) {
+		errors = append(errors, fmt.Sprintf("alerts: rule %s: invalid operator %q (must be one of: >, >=, <, <=)", r.Name, r.Operator))
+	}
+	if r.Window != "" && !alertWindows[r.Window] {
+		errors = append(errors, fmt.Sprintf("alerts: rule %s: invalid window %q (must be one of: 1m, 5m, 15m)", r.Name, r.Window))
+	}
+	if r.MinRequests < 0 {
+		errors = append(errors, fmt.Sprintf("alerts: rule %s: min_requests must be non-negative", r.Name))
+	}
+	for _, webhook := range []string{r.WebhookURL, r.SlackWebhookURL} {
+		if webhook != "" && !strings.HasPrefix(webhook, "http://") && !strings.HasPrefix(webhook, "https://") {
+			errors = append(errors, fmt.Sprintf("alerts: rule %s: webhook URLs must start with http:// or https://", r.Name))
+		}
+	}
+
+	return errors
+}
+
+// GetOperator returns the rule's comparison, defaulting to >
+func (r *AlertRule) GetOperator() string {
+	if r.Operator == "" {
+		return DefaultAlertOperator
+	}
+	return r.Operator
+}
+
+// GetWindow returns the rule's metrics window, defaulting to 1m
+func (r *AlertRule) GetWindow() string {
+	if r.Window == "" {
+		return DefaultAlertWindow
+	}
+	return r.Window
+}
+
+// Breached reports whether a value crosses the rule's threshold
+func (r *AlertRule) Breached(value float64) bool {
+	switch r.GetOperator() {
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	default:
+		return value > r.Threshold
+	}
+}
+
+// Redacted returns a copy of the alerts config with webhook URLs masked, as
+// they usually embed a secret token
+func (a AlertsConfig) Redacted() AlertsConfig {
+	if a.Rules != nil {
+		rules := make([]AlertRule, len(a.Rules))
+		for i, rule := range a.Rules {
+			rule.WebhookURL = maskIfSet(rule.WebhookURL)
+			rule.SlackWebhookURL = maskIfSet(rule.SlackWebhookURL)
+			rules[i] = rule
+		}
+		a.Rules = rules
+	}
+	return a
+}
+
+// GetAlertsConfig returns the alerts configuration with defaults applied
+func (m *Manager) GetAlertsConfig() AlertsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alerts := m.config.Alerts
+	alerts.Rules = append([]AlertRule(nil), m.config.Alerts.Rules...)
+	if alerts.IntervalSeconds <= 0 {
+		alerts.IntervalSeconds = DefaultAlertInterval
+	}
+	return alerts
+}
@@ -0,0 +1,70 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "fmt"
+
+// DefaultApdexSatisfiedMs is the Apdex threshold T of endpoints that don't
+// set one
+const DefaultApdexSatisfiedMs = 500
+
+// Apdex zones of a request
+const (
+	ApdexSatisfied  = "satisfied"
+	ApdexTolerating = "tolerating"
+	ApdexFrustrated = "frustrated"
+)
+
+// Apdex sets the response time thresholds of an endpoint's Apdex score:
+// successful requests up to satisfied_ms satisfy users, those up to
+// tolerating_ms are tolerated, and slower or failed ones frustrate them
+type Apdex struct {
+	SatisfiedMs  int `mapstructure:"satisfied_ms" yaml:"satisfied_ms,omitempty" json:"satisfied_ms,omitempty"`    // Threshold T (default 500)
+	ToleratingMs int `mapstructure:"tolerating_ms" yaml:"tolerating_ms,omitempty" json:"tolerating_ms,omitempty"` // Default 4T
+}
+
+// Validate checks if the Apdex thresholds are valid
+func (a *Apdex) Validate() []string {
+	var errors []string
+
+	if a.SatisfiedMs < 0 || a.ToleratingMs < 0 {
+		errors = append(errors, "apdex: satisfied_ms and tolerating_ms must be non-negative")
+	}
+	if satisfied, tolerating := a.thresholds(); a.ToleratingMs > 0 && tolerating < satisfied {
+		errors = append(errors, fmt.Sprintf("apdex: tolerating_ms must be at least satisfied_ms (%d)", satisfied))
+	}
+
+	return errors
+}
+
+// thresholds returns the satisfied and tolerating thresholds with defaults
+func (a *Apdex) thresholds() (satisfied, tolerating int) {
+	satisfied = DefaultApdexSatisfiedMs
+	if a != nil && a.SatisfiedMs > 0 {
+		satisfied = a.SatisfiedMs
+	}
+	tolerating = 4 * satisfied
+	if a != nil && a.ToleratingMs > 0 {
+		tolerating = a.ToleratingMs
+	}
+	return satisfied, tolerating
+}
+
+// ApdexThresholds returns the endpoint's satisfied and tolerating response
+// times in ms, with the defaults filled in
+func (e *Endpoint) ApdexThresholds() (satisfied, tolerating int) {
+	return e.Apdex.thresholds()
+}
+
+// ApdexZone returns the Apdex zone of a request to the endpoint: failed
+// requests frustrate users whatever their response time
+func (e *Endpoint) ApdexZone(totalTimeMs float64, success bool) string {
+	satisfied, tolerating := e.ApdexThresholds()
+	switch {
+	case !success || totalTimeMs > float64(tolerating):
+		return ApdexFrustrated
+	case totalTimeMs > float64(satisfied):
+		return ApdexTolerating
+	default:
+		return ApdexSatisfied
+	}
+}
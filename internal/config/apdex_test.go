@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestEndpointApdexZone(t *testing.T) {
+	ep := Endpoint{Name: "a"}
+	tests := []struct {
+		totalTimeMs float64
+		success     bool
+		want        string
+	}{
+		{500, true, ApdexSatisfied}, // Default T of 500ms
+		{501, true, ApdexTolerating},
+		{2000, true, ApdexTolerating}, // 4T
+		{2001, true, ApdexFrustrated},
+		{10, false, ApdexFrustrated},
+	}
+	for _, tt := range tests {
+		if got := ep.ApdexZone(tt.totalTimeMs, tt.success); got != tt.want {
+			t.Errorf("ApdexZone(%v, %v) = %s, want %s", tt.totalTimeMs, tt.success, got, tt.want)
+		}
+	}
+
+	ep.Apdex = &Apdex{SatisfiedMs: 100, ToleratingMs: 250}
+	if got := ep.ApdexZone(300, true); got != ApdexFrustrated {
+		t.Errorf("expected 300ms to frustrate above tolerating_ms, got %s", got)
+	}
+	if errs := (&Apdex{ToleratingMs: 300}).Validate(); len(errs) != 1 {
+		t.Errorf("expected tolerating_ms below the default T to be invalid, got %v", errs)
+	}
+}
@@ -0,0 +1,87 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Access log formats
+const (
+	AccessLogFormatCombined = "combined" // Apache/NGINX combined log format
+	AccessLogFormatJSON     = "json"     // One JSON object per line
+)
+
+// APIAccessLogConfig configures the log of requests to the API server, for
+// auditing who changed what
+type APIAccessLogConfig struct {
+	Enabled      bool     `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Format       string   `mapstructure:"format" yaml:"format,omitempty" json:"format,omitempty"`                      // combined (default) or json
+	File         string   `mapstructure:"file" yaml:"file,omitempty" json:"file,omitempty"`                            // Appended to; stderr if empty or -
+	ExcludePaths []string `mapstructure:"exclude_paths" yaml:"exclude_paths,omitempty" json:"exclude_paths,omitempty"` // Path prefixes not logged (default health checks and web UI assets)
+	SampleRate   int      `mapstructure:"sample_rate" yaml:"sample_rate,omitempty" json:"sample_rate,omitempty"`       // Log 1 in N successful requests; failures are always logged
+}
+
+// DefaultAccessLogExcludePaths are not logged when no exclude_paths are
+// configured: health probes and web UI assets would drown the API calls
+var DefaultAccessLogExcludePaths = []string{"/health", "/healthz", "/readyz", "/assets/"}
+
+// Validate checks if the API access log configuration is valid
+func (c *APIAccessLogConfig) Validate() []string {
+	var errors []string
+
+	switch c.Format {
+	case "", AccessLogFormatCombined, AccessLogFormatJSON:
+	default:
+		errors = append(errors, fmt.Sprintf("api_access_log: invalid format %q (must be combined or json)", c.Format))
+	}
+	if c.SampleRate < 0 {
+		errors = append(errors, "api_access_log: sample_rate must be non-negative")
+	}
+	for _, path := range c.ExcludePaths {
+		if !strings.HasPrefix(path, "/") {
+			errors = append(errors, fmt.Sprintf("api_access_log: exclude path %q must start with /", path))
+		}
+	}
+
+	return errors
+}
+
+// Excluded reports whether requests to a path are not logged
+func (c *APIAccessLogConfig) Excluded(path string) bool {
+	for _, prefix := range c.ExcludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAPIAccessLogConfig returns the API access log configuration with defaults applied
+func (m *Manager) GetAPIAccessLogConfig() APIAccessLogConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	accessLog := m.config.APIAccessLog
+	accessLog.ExcludePaths = append([]string(nil), accessLog.ExcludePaths...)
+	if len(accessLog.ExcludePaths) == 0 {
+		accessLog.ExcludePaths = append([]string(nil), DefaultAccessLogExcludePaths...)
+	}
+	if accessLog.Format == "" {
+		accessLog.Format = AccessLogFormatCombined
+	}
+	if accessLog.SampleRate <= 0 {
+		accessLog.SampleRate = 1
+	}
+	return accessLog
+}
+
+// SetAPIAccessLogFile enables the API access log, written to path, as done
+// by --access-log
+func (m *Manager) SetAPIAccessLogFile(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.APIAccessLog.Enabled = true
+	m.config.APIAccessLog.File = path
+}
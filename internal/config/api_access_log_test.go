@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestAPIAccessLogConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     APIAccessLogConfig
+		wantErr bool
+	}{
+		{"default", APIAccessLogConfig{}, false},
+		{"json sampled", APIAccessLogConfig{Enabled: true, Format: AccessLogFormatJSON, SampleRate: 10, ExcludePaths: []string{"/api/metrics/stream"}}, false},
+		{"invalid format", APIAccessLogConfig{Format: "common"}, true},
+		{"negative sample rate", APIAccessLogConfig{SampleRate: -1}, true},
+		{"relative exclude path", APIAccessLogConfig{ExcludePaths: []string{"health"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.cfg.Validate()
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetAPIAccessLogConfigDefaults(t *testing.T) {
+	m := NewManager()
+	m.SetAPIAccessLogFile("logs/api.log")
+
+	cfg := m.GetAPIAccessLogConfig()
+	if !cfg.Enabled || cfg.File != "logs/api.log" {
+		t.Errorf("Enabled = %v, File = %q, want enabled logs/api.log", cfg.Enabled, cfg.File)
+	}
+	if cfg.Format != AccessLogFormatCombined || cfg.SampleRate != 1 {
+		t.Errorf("Format = %q, SampleRate = %d, want combined and 1", cfg.Format, cfg.SampleRate)
+	}
+	if !cfg.Excluded("/health") || !cfg.Excluded("/assets/index.js") || cfg.Excluded("/api/metrics") {
+		t.Errorf("default exclusions = %v", cfg.ExcludePaths)
+	}
+}
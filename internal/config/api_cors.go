@@ -0,0 +1,101 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// APICORSConfig controls which browser origins may call the API. Without
+// allowed origins any origin may (*); with them, requests from other
+// origins are rejected, except from the API's own origin.
+type APICORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins" yaml:"allowed_origins,omitempty" json:"allowed_origins,omitempty"`       // Origins such as https://dash.example.com; *.example.com hosts match subdomains
+	AllowedHeaders   []string `mapstructure:"allowed_headers" yaml:"allowed_headers,omitempty" json:"allowed_headers,omitempty"`       // Request headers browsers may send (default Content-Type, Authorization, X-Confirm-Token)
+	AllowCredentials bool     `mapstructure:"allow_credentials" yaml:"allow_credentials,omitempty" json:"allow_credentials,omitempty"` // Let browsers send cookies and auth headers; requires allowed_origins
+}
+
+// DefaultCORSHeaders are allowed when no allowed_headers are configured
+var DefaultCORSHeaders = []string{"Content-Type", "Authorization", "X-Confirm-Token"}
+
+// Validate checks if the API CORS configuration is valid
+func (c *APICORSConfig) Validate() []string {
+	var errors []string
+
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				errors = append(errors, "api_cors: allow_credentials cannot be combined with allowed origin *")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			errors = append(errors, fmt.Sprintf("api_cors: invalid allowed origin %q (must be * or scheme://host[:port])", origin))
+		}
+	}
+	if c.AllowCredentials && len(c.AllowedOrigins) == 0 {
+		errors = append(errors, "api_cors: allow_credentials requires allowed_origins")
+	}
+	for _, header := range c.AllowedHeaders {
+		if header == "" || strings.ContainsAny(header, " ,:") {
+			errors = append(errors, fmt.Sprintf("api_cors: invalid allowed header %q", header))
+		}
+	}
+
+	return errors
+}
+
+// AllowsAnyOrigin reports whether every origin may call the API
+func (c *APICORSConfig) AllowsAnyOrigin() bool {
+	if len(c.AllowedOrigins) == 0 {
+		return true
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin reports whether a request Origin header value is allowed.
+// Schemes and hosts match case-insensitively, and an allowed host starting
+// with *. matches its subdomains.
+func (c *APICORSConfig) AllowsOrigin(origin string) bool {
+	if c.AllowsAnyOrigin() {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, allowed := range c.AllowedOrigins {
+		a, err := url.Parse(strings.ToLower(allowed))
+		if err != nil || a.Scheme != u.Scheme {
+			continue
+		}
+		if a.Host == u.Host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(a.Host, "*."); ok && strings.HasSuffix(u.Host, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAPICORSConfig returns the API CORS configuration with defaults applied
+func (m *Manager) GetAPICORSConfig() APICORSConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	corsCfg := m.config.APICORS
+	corsCfg.AllowedOrigins = append([]string(nil), corsCfg.AllowedOrigins...)
+	corsCfg.AllowedHeaders = append([]string(nil), corsCfg.AllowedHeaders...)
+	if len(corsCfg.AllowedHeaders) == 0 {
+		corsCfg.AllowedHeaders = append([]string(nil), DefaultCORSHeaders...)
+	}
+	return corsCfg
+}
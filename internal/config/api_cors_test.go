@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestAPICORSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     APICORSConfig
+		wantErr bool
+	}{
+		{"default", APICORSConfig{}, false},
+		{"origins", APICORSConfig{AllowedOrigins: []string{"https://dash.example.com", "http://localhost:5173", "https://*.example.com"}, AllowCredentials: true}, false},
+		{"any origin", APICORSConfig{AllowedOrigins: []string{"*"}}, false},
+		{"origin with path", APICORSConfig{AllowedOrigins: []string{"https://dash.example.com/app"}}, true},
+		{"origin without scheme", APICORSConfig{AllowedOrigins: []string{"dash.example.com"}}, true},
+		{"credentials with any origin", APICORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, true},
+		{"credentials without origins", APICORSConfig{AllowCredentials: true}, true},
+		{"invalid header", APICORSConfig{AllowedHeaders: []string{"X-Token, X-Other"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.cfg.Validate()
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPICORSConfigAllowsOrigin(t *testing.T) {
+	cfg := APICORSConfig{AllowedOrigins: []string{"https://dash.example.com", "https://*.corp.example.com"}}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://dash.example.com", true},
+		{"HTTPS://Dash.Example.com", true},
+		{"http://dash.example.com", false},
+		{"https://dash.example.com:8443", false},
+		{"https://evil.com", false},
+		{"https://team.corp.example.com", true},
+		{"https://corp.example.com", false},
+		{"https://evilcorp.example.com", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		if got := cfg.AllowsOrigin(tt.origin); got != tt.want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if !(&APICORSConfig{}).AllowsOrigin("https://anything.example") {
+		t.Error("expected any origin to be allowed without allowed_origins")
+	}
+}
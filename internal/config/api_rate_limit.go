@@ -0,0 +1,50 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIRateLimitConfig limits the requests each client may send to the API, to
+// keep runaway dashboards or scripts from starving the load generator
+type APIRateLimitConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" yaml:"requests_per_second" json:"requests_per_second"` // Per client; 0 disables the limit
+	Burst             int     `mapstructure:"burst" yaml:"burst,omitempty" json:"burst,omitempty"`                       // Defaults to ceil(requests_per_second)
+	KeyHeader         string  `mapstructure:"key_header" yaml:"key_header,omitempty" json:"key_header,omitempty"`        // Header whose values are limited too, e.g. X-API-Key, besides each remote IP
+}
+
+// Enabled returns true if API requests are rate limited
+func (c *APIRateLimitConfig) Enabled() bool {
+	return c.RequestsPerSecond > 0
+}
+
+// Bucket returns the token bucket settings of each client
+func (c *APIRateLimitConfig) Bucket() IncomingRateLimit {
+	return IncomingRateLimit{RequestsPerSecond: c.RequestsPerSecond, Burst: c.Burst}
+}
+
+// Validate checks if the API rate limit configuration is valid
+func (c *APIRateLimitConfig) Validate() []string {
+	var errors []string
+
+	if c.RequestsPerSecond < 0 {
+		errors = append(errors, "api_rate_limit: requests_per_second must be non-negative")
+	}
+	if c.Burst < 0 {
+		errors = append(errors, "api_rate_limit: burst must be non-negative")
+	}
+	if strings.ContainsAny(c.KeyHeader, " ,:") {
+		errors = append(errors, fmt.Sprintf("api_rate_limit: invalid key_header %q", c.KeyHeader))
+	}
+
+	return errors
+}
+
+// GetAPIRateLimitConfig returns the API rate limit configuration
+func (m *Manager) GetAPIRateLimitConfig() APIRateLimitConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.APIRateLimit
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestAPIRateLimitConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     APIRateLimitConfig
+		wantErr bool
+	}{
+		{"disabled", APIRateLimitConfig{}, false},
+		{"per key", APIRateLimitConfig{RequestsPerSecond: 5, Burst: 20, KeyHeader: "X-API-Key"}, false},
+		{"negative rate", APIRateLimitConfig{RequestsPerSecond: -1}, true},
+		{"negative burst", APIRateLimitConfig{RequestsPerSecond: 1, Burst: -1}, true},
+		{"invalid key header", APIRateLimitConfig{RequestsPerSecond: 1, KeyHeader: "X-API-Key:"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.cfg.Validate()
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
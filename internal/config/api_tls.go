@@ -0,0 +1,62 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+)
+
+// APITLSConfig serves the API and simulated routes over HTTPS, either with a
+// certificate from files or a self-signed one generated at startup. Plain
+// HTTP requests to the port are redirected to HTTPS.
+type APITLSConfig struct {
+	Enabled    bool     `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	CertFile   string   `mapstructure:"cert_file" yaml:"cert_file,omitempty" json:"cert_file,omitempty"`
+	KeyFile    string   `mapstructure:"key_file" yaml:"key_file,omitempty" json:"key_file,omitempty"`
+	SelfSigned bool     `mapstructure:"self_signed" yaml:"self_signed,omitempty" json:"self_signed,omitempty"` // Generate a certificate when no cert_file is set
+	Hosts      []string `mapstructure:"hosts" yaml:"hosts,omitempty" json:"hosts,omitempty"`                   // Names/IPs in the self-signed certificate
+}
+
+// DefaultSelfSignedHosts are used when a self-signed certificate has no hosts configured
+var DefaultSelfSignedHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// Validate checks if the API TLS configuration is valid
+func (c *APITLSConfig) Validate() []string {
+	var errors []string
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errors = append(errors, "api_tls: cert_file and key_file must be set together")
+	}
+	if c.Enabled && c.CertFile == "" && !c.SelfSigned {
+		errors = append(errors, "api_tls: cert_file/key_file or self_signed required when enabled")
+	}
+	if c.CertFile != "" && c.SelfSigned {
+		errors = append(errors, fmt.Sprintf("api_tls: self_signed cannot be combined with cert_file %s", c.CertFile))
+	}
+
+	return errors
+}
+
+// GetAPITLSConfig returns the API TLS configuration with defaults applied
+func (m *Manager) GetAPITLSConfig() APITLSConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tlsCfg := m.config.APITLS
+	tlsCfg.Hosts = append([]string(nil), tlsCfg.Hosts...)
+	if tlsCfg.SelfSigned && len(tlsCfg.Hosts) == 0 {
+		tlsCfg.Hosts = append([]string(nil), DefaultSelfSignedHosts...)
+	}
+	return tlsCfg
+}
+
+// SetAPITLSConfig replaces the API TLS configuration
+func (m *Manager) SetAPITLSConfig(tlsCfg APITLSConfig) error {
+	if errors := tlsCfg.Validate(); len(errors) > 0 {
+		return &ValidationError{Problems: errors}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config.APITLS = tlsCfg
+	return nil
+}
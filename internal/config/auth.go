@@ -14,6 +14,14 @@ const (
 	AuthTypeAPIKeyQuery = "api_key_query"
 	AuthTypeBasic       = "basic"
 	AuthTypeCustom      = "custom_header"
+	AuthTypeAWSSigV4    = "aws_sigv4"
+)
+
+// Default env vars for aws_sigv4 credentials
+const (
+	DefaultAWSAccessKeyEnv    = "AWS_ACCESS_KEY_ID"
+	DefaultAWSSecretKeyEnv    = "AWS_SECRET_ACCESS_KEY"
+	DefaultAWSSessionTokenEnv = "AWS_SESSION_TOKEN"
 )
 
 // AuthConfig represents a reusable authentication configuration
@@ -33,9 +41,21 @@ type AuthConfig struct {
 	UsernameEnv string `mapstructure:"username_env" yaml:"username_env,omitempty" json:"username_env,omitempty"`
 	PasswordEnv string `mapstructure:"password_env" yaml:"password_env,omitempty" json:"password_env,omitempty"`
 
+	// For aws_sigv4 type (credential env vars default to the standard AWS_* names)
+	Region          string `mapstructure:"region" yaml:"region,omitempty" json:"region,omitempty"`
+	Service         string `mapstructure:"service" yaml:"service,omitempty" json:"service,omitempty"` // e.g. execute-api, s3
+	AccessKeyEnv    string `mapstructure:"access_key_env" yaml:"access_key_env,omitempty" json:"access_key_env,omitempty"`
+	SecretKeyEnv    string `mapstructure:"secret_key_env" yaml:"secret_key_env,omitempty" json:"secret_key_env,omitempty"`
+	SessionTokenEnv string `mapstructure:"session_token_env" yaml:"session_token_env,omitempty" json:"session_token_env,omitempty"`
+
 	// Token endpoint configuration for JWT/OAuth (bearer type with refresh)
 	TokenEndpoint *TokenEndpointConfig `mapstructure:"token_endpoint" yaml:"token_endpoint,omitempty" json:"token_endpoint,omitempty"`
 
+	// OAuth scope/audience requested from the token endpoint. Endpoints can
+	// override them per reference; each combination gets its own token.
+	Scope    string `mapstructure:"scope" yaml:"scope,omitempty" json:"scope,omitempty"`
+	Audience string `mapstructure:"audience" yaml:"audience,omitempty" json:"audience,omitempty"`
+
 	// Refresh settings (seconds before expiry to refresh token)
 	RefreshBeforeExpiry int `mapstructure:"refresh_before_expiry" yaml:"refresh_before_expiry,omitempty" json:"refresh_before_expiry,omitempty"`
 }
@@ -51,6 +71,13 @@ type TokenEndpointConfig struct {
 	Body        interface{}       `mapstructure:"body" yaml:"body,omitempty" json:"body,omitempty"`
 	TokenPath   string            `mapstructure:"token_path" yaml:"token_path,omitempty" json:"token_path,omitempty"`       // JSON path to token in response (e.g., "access_token" or "data.token")
 	ExpiresPath string            `mapstructure:"expires_path" yaml:"expires_path,omitempty" json:"expires_path,omitempty"` // JSON path to expiry (seconds or timestamp)
+
+	// refresh_token grant: when RefreshTokenPath is set, the refresh token in
+	// each response is stored and sent on the next refresh, replacing the old
+	// one whenever the provider rotates it. RefreshBody (default: grant_type
+	// and refresh_token only) gets refresh_token filled in automatically.
+	RefreshTokenPath string      `mapstructure:"refresh_token_path" yaml:"refresh_token_path,omitempty" json:"refresh_token_path,omitempty"`
+	RefreshBody      interface{} `mapstructure:"refresh_body" yaml:"refresh_body,omitempty" json:"refresh_body,omitempty"`
 }
 
 // Validate validates an AuthConfig
@@ -68,10 +95,11 @@ func (a *AuthConfig) Validate() []string {
 		AuthTypeAPIKeyQuery: true,
 		AuthTypeBasic:       true,
 		AuthTypeCustom:      true,
+		AuthTypeAWSSigV4:    true,
 	}
 
 	if !validTypes[a.Type] {
-		errors = append(errors, fmt.Sprintf("auth %s: invalid type '%s' (must be one of: none, bearer, api_key, api_key_query, basic, custom_header)", a.Name, a.Type))
+		errors = append(errors, fmt.Sprintf("auth %s: invalid type '%s' (must be one of: none, bearer, api_key, api_key_query, basic, custom_header, aws_sigv4)", a.Name, a.Type))
 	}
 
 	switch a.Type {
@@ -103,11 +131,59 @@ func (a *AuthConfig) Validate() []string {
 		if a.TokenEndpoint != nil {
 			errors = append(errors, a.validateTokenEndpoint()...)
 		}
+
+	case AuthTypeAWSSigV4:
+		if a.Region == "" || a.Service == "" {
+			errors = append(errors, fmt.Sprintf("auth %s: region and service required for aws_sigv4", a.Name))
+		}
+	}
+
+	if (a.Scope != "" || a.Audience != "") && a.TokenEndpoint == nil {
+		errors = append(errors, fmt.Sprintf("auth %s: scope and audience require a token_endpoint", a.Name))
 	}
 
 	return errors
 }
 
+// AWSCredentialEnvs returns the env var names holding the access key, secret
+// key and session token for aws_sigv4, with defaults applied
+func (a *AuthConfig) AWSCredentialEnvs() (accessKey, secretKey, sessionToken string) {
+	accessKey, secretKey, sessionToken = a.AccessKeyEnv, a.SecretKeyEnv, a.SessionTokenEnv
+	if accessKey == "" {
+		accessKey = DefaultAWSAccessKeyEnv
+	}
+	if secretKey == "" {
+		secretKey = DefaultAWSSecretKeyEnv
+	}
+	if sessionToken == "" {
+		sessionToken = DefaultAWSSessionTokenEnv
+	}
+	return accessKey, secretKey, sessionToken
+}
+
+// CredentialEnvs returns the names of the env vars the auth config reads
+// credentials from
+func (a *AuthConfig) CredentialEnvs() []string {
+	var envs []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if name != "" {
+				envs = append(envs, name)
+			}
+		}
+	}
+
+	add(a.EnvVar, a.UsernameEnv, a.PasswordEnv)
+	if a.Type == AuthTypeAWSSigV4 {
+		accessKey, secretKey, _ := a.AWSCredentialEnvs()
+		add(accessKey, secretKey)
+	}
+	if a.TokenEndpoint != nil {
+		add(a.TokenEndpoint.URLEnv, a.TokenEndpoint.UsernameEnv, a.TokenEndpoint.PasswordEnv)
+	}
+	return envs
+}
+
 // validateTokenEndpoint validates the token endpoint configuration
 func (a *AuthConfig) validateTokenEndpoint() []string {
 	var errors []string
@@ -150,7 +226,7 @@ func ResolveEndpointAuth(auth interface{}, configs map[string]*AuthConfig) (*Aut
 		}
 		cfg, exists := configs[ref]
 		if !exists {
-			return nil, fmt.Errorf("auth config not found: %s", ref)
+			return nil, &NotFoundError{Kind: KindAuthConfig, Name: ref}
 		}
 		return cfg, nil
 	}
@@ -169,7 +245,7 @@ func parseAuthConfigMap(authMap map[string]interface{}, configs map[string]*Auth
 	if ref, ok := authMap["ref"].(string); ok && ref != "" {
 		baseCfg, exists := configs[ref]
 		if !exists {
-			return nil, fmt.Errorf("auth config not found: %s", ref)
+			return nil, &NotFoundError{Kind: KindAuthConfig, Name: ref}
 		}
 		// Clone base config
 		cfg := *baseCfg
@@ -180,6 +256,23 @@ func parseAuthConfigMap(authMap map[string]interface{}, configs map[string]*Auth
 		if queryParam, ok := authMap["query_param"].(string); ok {
 			cfg.QueryParam = queryParam
 		}
+		if region, ok := authMap["region"].(string); ok {
+			cfg.Region = region
+		}
+		if service, ok := authMap["service"].(string); ok {
+			cfg.Service = service
+		}
+		scope, hasScope := authMap["scope"].(string)
+		audience, hasAudience := authMap["audience"].(string)
+		if (hasScope || hasAudience) && cfg.TokenEndpoint == nil {
+			return nil, fmt.Errorf("auth config %s: scope/audience overrides require a token_endpoint", ref)
+		}
+		if hasScope {
+			cfg.Scope = scope
+		}
+		if hasAudience {
+			cfg.Audience = audience
+		}
 		return &cfg, nil
 	}
 
@@ -203,6 +296,21 @@ func parseAuthConfigMap(authMap map[string]interface{}, configs map[string]*Auth
 	if passwordEnv, ok := authMap["password_env"].(string); ok {
 		cfg.PasswordEnv = passwordEnv
 	}
+	if region, ok := authMap["region"].(string); ok {
+		cfg.Region = region
+	}
+	if service, ok := authMap["service"].(string); ok {
+		cfg.Service = service
+	}
+	if accessKeyEnv, ok := authMap["access_key_env"].(string); ok {
+		cfg.AccessKeyEnv = accessKeyEnv
+	}
+	if secretKeyEnv, ok := authMap["secret_key_env"].(string); ok {
+		cfg.SecretKeyEnv = secretKeyEnv
+	}
+	if sessionTokenEnv, ok := authMap["session_token_env"].(string); ok {
+		cfg.SessionTokenEnv = sessionTokenEnv
+	}
 
 	if cfg.Type == "" {
 		return nil, fmt.Errorf("inline auth config missing required field: type")
@@ -19,10 +19,53 @@ type Config struct {
 	APIPort            int                    `mapstructure:"api_port" json:"api_port"`
 	AuthConfigs        map[string]*AuthConfig `mapstructure:"auth_configs" json:"auth_configs"`
 	Endpoints          []Endpoint             `mapstructure:"outgoing_endpoints" json:"outgoing_endpoints"`
+	EndpointGroups     []EndpointGroup        `mapstructure:"endpoint_groups" json:"endpoint_groups"`
 	IncomingEnabled    bool                   `mapstructure:"incoming_enabled" json:"incoming_enabled"`
 	IncomingRoutes     []IncomingEndpoint     `mapstructure:"incoming_routes" json:"incoming_routes"`
+	IncomingClients    IncomingClientsConfig  `mapstructure:"incoming_clients" json:"incoming_clients"`
+	IPFamily           string                 `mapstructure:"ip_family" json:"ip_family"`
+	DNSProbe           DNSProbeConfig         `mapstructure:"dns_probe" json:"dns_probe"`
+	TLSCerts           TLSCertsConfig         `mapstructure:"tls_certs" json:"tls_certs"`
+	Adaptive           AdaptiveConfig         `mapstructure:"adaptive" json:"adaptive"`
+	ResponseCapture    ResponseCaptureConfig  `mapstructure:"response_capture" json:"response_capture"`
+	APITLS             APITLSConfig           `mapstructure:"api_tls" json:"api_tls"`
+	APICORS            APICORSConfig          `mapstructure:"api_cors" json:"api_cors"`
+	APIAccessLog       APIAccessLogConfig     `mapstructure:"api_access_log" json:"api_access_log"`
+	APIRateLimit       APIRateLimitConfig     `mapstructure:"api_rate_limit" json:"api_rate_limit"`
+	Notifications      NotificationsConfig    `mapstructure:"notifications" json:"notifications"`
+	Alerts             AlertsConfig           `mapstructure:"alerts" json:"alerts"`
+	HostLimits         HostLimitsConfig       `mapstructure:"host_limits" json:"host_limits"`
+	Guardrails         GuardrailsConfig       `mapstructure:"guardrails" json:"guardrails"`
+	GuardedActions     GuardedActionsConfig   `mapstructure:"guarded_actions" json:"guarded_actions"`
+	Heartbeat          HeartbeatConfig        `mapstructure:"heartbeat" json:"heartbeat"`
+	ResourceLimits     ResourceLimitsConfig   `mapstructure:"resource_limits" json:"resource_limits"`
+	Percentiles        []float64              `mapstructure:"percentiles" json:"percentiles,omitempty"` // Latency percentiles reported, such as 50, 90 and 99.9 (default 95 and 99)
+	ResultSinks        ResultSinksConfig      `mapstructure:"result_sinks" json:"result_sinks"`
+	Stages             []Stage                `mapstructure:"stages" json:"stages,omitempty"` // Ramp of the virtual users shared by closed-loop endpoints
+	Labels             map[string]string      `mapstructure:"labels" json:"labels,omitempty"` // Attached to metric snapshots, reports and result records
+	Environments       map[string]Environment `mapstructure:"environments" json:"environments,omitempty"`
+	Environment        string                 `mapstructure:"environment" json:"environment,omitempty"` // Active environment, referenced by templates as {{.BaseURL}} and {{.Vars.name}}
+}
+
+// IP family constants for outgoing connection dialing
+const (
+	IPFamilyDual = "dual"
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+)
 
-	mu sync.RWMutex `mapstructure:"-" json:"-"`
+// IsValidIPFamily returns true if the given value is a supported IP family setting
+func IsValidIPFamily(family string) bool {
+	switch family {
+	case IPFamilyDual, IPFamilyIPv4, IPFamilyIPv6:
+		return true
+	}
+	return false
+}
+
+// invalidIPFamily describes an unsupported IP family setting
+func invalidIPFamily(family string) string {
+	return fmt.Sprintf("invalid ip_family %s (must be one of: dual, ipv4, ipv6)", family)
 }
 
 // Manager handles configuration with thread-safe endpoint management
@@ -30,8 +73,12 @@ type Manager struct {
 	config     *Config
 	viper      *viper.Viper
 	envViper   *viper.Viper
-	configPath string // Path to the config file
+	configPath string      // Path to the config file
+	provenance *Provenance // Revision of the config file, nil if none was loaded
 	mu         sync.RWMutex
+
+	versions    []ConfigVersion // Applied configs kept for rollback, oldest first
+	lastVersion int
 }
 
 // NewManager creates a new configuration manager
@@ -47,11 +94,22 @@ func NewManager() *Manager {
 	v.SetDefault("outgoing_endpoints", []Endpoint{})
 	v.SetDefault("incoming_enabled", true)
 	v.SetDefault("incoming_routes", []IncomingEndpoint{})
+	v.SetDefault("ip_family", IPFamilyDual)
 
 	// Enable environment variable reading for LOADTEST_ prefixed vars
 	v.SetEnvPrefix("LOADTEST")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	// Nested keys only come from the environment when bound
+	_ = v.BindEnv("api_cors.allowed_origins")
+	_ = v.BindEnv("api_cors.allowed_headers")
+	_ = v.BindEnv("api_cors.allow_credentials")
+	_ = v.BindEnv("api_access_log.enabled")
+	_ = v.BindEnv("api_access_log.format")
+	_ = v.BindEnv("api_access_log.file")
+	_ = v.BindEnv("api_rate_limit.requests_per_second")
+	_ = v.BindEnv("api_rate_limit.burst")
+	_ = v.BindEnv("api_rate_limit.key_header")
 
 	// Create a separate viper instance for .env file
 	envV := viper.New()
@@ -71,6 +129,7 @@ func NewManager() *Manager {
 			Endpoints:          []Endpoint{},
 			IncomingEnabled:    true,
 			IncomingRoutes:     []IncomingEndpoint{},
+			IPFamily:           IPFamilyDual,
 		},
 		viper:    v,
 		envViper: envV,
@@ -93,6 +152,10 @@ func (m *Manager) LoadFromFile(path string) error {
 	if err := m.viper.Unmarshal(m.config); err != nil {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	provenance, err := ReadProvenance(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
 
 	// Initialize auth configs map if nil
 	if m.config.AuthConfigs == nil {
@@ -104,17 +167,52 @@ func (m *Manager) LoadFromFile(path string) error {
 		authCfg.Name = name
 	}
 
+	// Decoding can't tell enabled: false from a missing key, which defaults to true
+	for _, i := range enabledKeyIndexes(m.viper.Get("outgoing_endpoints")) {
+		if i < len(m.config.Endpoints) {
+			m.config.Endpoints[i].EnabledSet = true
+		}
+	}
+	for _, i := range enabledKeyIndexes(m.viper.Get("incoming_routes")) {
+		if i < len(m.config.IncomingRoutes) {
+			m.config.IncomingRoutes[i].EnabledSet = true
+		}
+	}
+
 	// Set default values for endpoints and resolve auth
 	m.normalizeEndpoints()
 
 	// Normalize incoming routes
 	m.normalizeIncomingRoutes()
 
+	provenance.Version = m.recordVersion(VersionSourceFile, 0).ID
+	m.provenance = provenance
 	return nil
 }
 
+// enabledKeyIndexes returns the indexes of the items of a raw config list
+// that set the enabled key
+func enabledKeyIndexes(list interface{}) []int {
+	items, _ := list.([]interface{})
+	var indexes []int
+	for i, item := range items {
+		if fields, ok := item.(map[string]interface{}); ok {
+			if _, set := fields["enabled"]; set {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	return indexes
+}
+
 // ReplaceConfig replaces the in-memory configuration entirely
 func (m *Manager) ReplaceConfig(newCfg *Config) error {
+	return m.ReplaceConfigFrom(newCfg, VersionSourceReplace)
+}
+
+// ReplaceConfigFrom replaces the in-memory configuration entirely, recording
+// it as a config version with the given source
+func (m *Manager) ReplaceConfigFrom(newCfg *Config, source string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -132,6 +230,9 @@ func (m *Manager) ReplaceConfig(newCfg *Config) error {
 	if newCfg.GlobalMultiplier == 0 {
 		newCfg.GlobalMultiplier = 1.0
 	}
+	if newCfg.IPFamily == "" {
+		newCfg.IPFamily = IPFamilyDual
+	}
 	if newCfg.AuthConfigs == nil {
 		newCfg.AuthConfigs = make(map[string]*AuthConfig)
 	}
@@ -141,11 +242,68 @@ func (m *Manager) ReplaceConfig(newCfg *Config) error {
 		authCfg.Name = name
 	}
 
+	// Guardrails and guarded actions only change with the config file
+	if source != VersionSourceReload {
+		newCfg.Guardrails = m.config.Guardrails
+		newCfg.GuardedActions = m.config.GuardedActions
+	}
+	if violations := newCfg.Guardrails.Check(newCfg); len(violations) > 0 {
+		return &GuardrailError{Violations: violations}
+	}
+
 	// Swap config, then normalize (ensures endpoints/routes are valid)
 	m.config = newCfg
 	m.normalizeEndpoints()
 	m.normalizeIncomingRoutes()
 
+	m.recordVersion(source, 0)
+	return nil
+}
+
+// ReloadFromFile reloads the config file loaded at startup. The file is
+// validated before it replaces the current config, so a broken file leaves
+// the running config untouched. The API port, TLS, CORS and access log
+// settings can't change without a restart and are kept, as is the enabled
+// switch, which follows the scheduler's pause state. The active environment
+// is kept unless the file sets one.
+func (m *Manager) ReloadFromFile() error {
+	path := m.GetConfigPath()
+	if path == "" {
+		return fmt.Errorf("no config file loaded")
+	}
+
+	fresh := NewManager()
+	if err := fresh.LoadFromFile(path); err != nil {
+		return err
+	}
+	if current, _ := m.GetEnvironments(); fresh.config.Environment == "" {
+		if _, ok := fresh.config.Environments[current]; ok {
+			fresh.config.Environment = current
+			fresh.config.resolveEnvironment()
+		}
+	}
+	if errs := fresh.Validate(); len(errs) > 0 {
+		return &ValidationError{Problems: errs}
+	}
+
+	newCfg := fresh.config
+	m.mu.RLock()
+	newCfg.APIPort = m.config.APIPort
+	newCfg.APITLS = m.config.APITLS
+	newCfg.APICORS = m.config.APICORS
+	newCfg.APIAccessLog = m.config.APIAccessLog
+	newCfg.Enabled = m.config.Enabled
+	m.mu.RUnlock()
+
+	if err := m.ReplaceConfigFrom(newCfg, VersionSourceReload); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	provenance := *fresh.provenance
+	provenance.Version = m.lastVersion
+	m.provenance = &provenance
 	return nil
 }
 
@@ -176,6 +334,7 @@ func (m *Manager) normalizeEndpoints() {
 			m.config.Endpoints[i].ResolvedAuth = resolvedAuth
 		}
 	}
+	m.config.resolveEnvironment()
 }
 
 // normalizeIncomingRoutes sets default values for incoming routes
@@ -230,14 +389,24 @@ func (m *Manager) GetConfig() *Config {
 	cfg := *m.config
 	cfg.Endpoints = make([]Endpoint, len(m.config.Endpoints))
 	copy(cfg.Endpoints, m.config.Endpoints)
+	cfg.EndpointGroups = make([]EndpointGroup, len(m.config.EndpointGroups))
+	copy(cfg.EndpointGroups, m.config.EndpointGroups)
 	return &cfg
 }
 
-// SetGlobalMultiplier updates the global multiplier
-func (m *Manager) SetGlobalMultiplier(multiplier float64) {
+// SetGlobalMultiplier updates the global multiplier, unless the adjusted rate
+// would exceed the guardrails
+func (m *Manager) SetGlobalMultiplier(multiplier float64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+
+	candidate := *m.config
+	candidate.GlobalMultiplier = multiplier
+	if err := m.checkGuardrails(&candidate); err != nil {
+		return err
+	}
 	m.config.GlobalMultiplier = multiplier
+	return nil
 }
 
 // SetConcurrentRequests updates the concurrent requests limit
@@ -254,6 +423,28 @@ func (m *Manager) SetAPIPort(port int) {
 	m.config.APIPort = port
 }
 
+// SetIPFamily updates the IP family used when dialing outgoing connections
+func (m *Manager) SetIPFamily(family string) error {
+	if !IsValidIPFamily(family) {
+		return &ValidationError{Problems: []string{invalidIPFamily(family)}}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config.IPFamily = family
+	return nil
+}
+
+// GetIPFamily returns the IP family used when dialing outgoing connections
+func (m *Manager) GetIPFamily() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.config.IPFamily == "" {
+		return IPFamilyDual
+	}
+	return m.config.IPFamily
+}
+
 // SetLogAllRequests updates the log all requests setting
 func (m *Manager) SetLogAllRequests(log bool) {
 	m.mu.Lock()
@@ -286,7 +477,37 @@ func (m *Manager) SetEndpointEnabled(name string, enabled bool) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("endpoint not found: %s", name)
+	return &NotFoundError{Kind: KindEndpoint, Name: name}
+}
+
+// SetEndpointsEnabledByTag enables or disables every endpoint with a tag and
+// returns the names of the updated endpoints
+func (m *Manager) SetEndpointsEnabledByTag(tag string, enabled bool) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	updated := []string{}
+	for i := range m.config.Endpoints {
+		if m.config.Endpoints[i].HasTag(tag) {
+			m.config.Endpoints[i].Enabled = enabled
+			updated = append(updated, m.config.Endpoints[i].Name)
+		}
+	}
+	return updated
+}
+
+// GetEndpointTags returns the tags of every tagged endpoint, keyed by endpoint name
+func (m *Manager) GetEndpointTags() map[string][]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tags := make(map[string][]string)
+	for _, ep := range m.config.Endpoints {
+		if len(ep.Tags) > 0 {
+			tags[ep.Name] = append([]string(nil), ep.Tags...)
+		}
+	}
+	return tags
 }
 
 // IsEndpointEnabled returns whether a specific endpoint is enabled
@@ -299,7 +520,7 @@ func (m *Manager) IsEndpointEnabled(name string) (bool, error) {
 			return m.config.Endpoints[i].Enabled, nil
 		}
 	}
-	return false, fmt.Errorf("endpoint not found: %s", name)
+	return false, &NotFoundError{Kind: KindEndpoint, Name: name}
 }
 
 // --- Endpoint CRUD Operations ---
@@ -325,7 +546,7 @@ func (m *Manager) GetEndpoint(name string) (*Endpoint, error) {
 			return &ep, nil
 		}
 	}
-	return nil, fmt.Errorf("endpoint not found: %s", name)
+	return nil, &NotFoundError{Kind: KindEndpoint, Name: name}
 }
 
 // AddEndpoint adds a new endpoint
@@ -336,11 +557,43 @@ func (m *Manager) AddEndpoint(endpoint Endpoint) error {
 	// Check for duplicate name
 	for _, ep := range m.config.Endpoints {
 		if ep.Name == endpoint.Name {
-			return fmt.Errorf("endpoint already exists: %s", endpoint.Name)
+			return &AlreadyExistsError{Kind: KindEndpoint, Name: endpoint.Name}
 		}
 	}
 
-	// Set defaults
+	endpoint, err := m.completeEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+
+	// Validate
+	if errors := endpoint.Validate(); len(errors) > 0 {
+		return &ValidationError{Problems: errors}
+	}
+
+	candidate := *m.config
+	candidate.Endpoints = append(m.config.Endpoints[:len(m.config.Endpoints):len(m.config.Endpoints)], endpoint)
+	if err := m.checkGuardrails(&candidate); err != nil {
+		return err
+	}
+
+	m.config.Endpoints = candidate.Endpoints
+	return nil
+}
+
+// PrepareEndpoint applies the defaults and auth resolution AddEndpoint would,
+// without adding the endpoint. The endpoint is returned with defaults applied
+// even when its auth can't be resolved.
+func (m *Manager) PrepareEndpoint(endpoint Endpoint) (Endpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.completeEndpoint(endpoint)
+}
+
+// completeEndpoint applies the defaults of unset endpoint fields and
+// resolves its auth. The caller must hold the lock.
+func (m *Manager) completeEndpoint(endpoint Endpoint) (Endpoint, error) {
 	if endpoint.Timeout == 0 {
 		endpoint.Timeout = 30
 	}
@@ -351,20 +604,13 @@ func (m *Manager) AddEndpoint(endpoint Endpoint) error {
 		endpoint.Method = "GET"
 	}
 
-	// Resolve auth
 	resolvedAuth, err := ResolveEndpointAuth(endpoint.Auth, m.config.AuthConfigs)
 	if err != nil {
-		return fmt.Errorf("failed to resolve auth: %w", err)
+		return endpoint, fmt.Errorf("failed to resolve auth: %w", err)
 	}
 	endpoint.ResolvedAuth = resolvedAuth
-
-	// Validate
-	if errors := endpoint.Validate(); len(errors) > 0 {
-		return fmt.Errorf("validation failed: %s", strings.Join(errors, "; "))
-	}
-
-	m.config.Endpoints = append(m.config.Endpoints, endpoint)
-	return nil
+	endpoint.ResolvedEnv = m.config.ActiveEnvironment()
+	return endpoint, nil
 }
 
 // UpdateEndpoint updates an existing endpoint by name
@@ -378,39 +624,34 @@ func (m *Manager) UpdateEndpoint(name string, endpoint Endpoint) error {
 			if endpoint.Name != name {
 				for j, ep := range m.config.Endpoints {
 					if ep.Name == endpoint.Name && i != j {
-						return fmt.Errorf("endpoint with name %s already exists", endpoint.Name)
+						return &AlreadyExistsError{Kind: KindEndpoint, Name: endpoint.Name}
 					}
 				}
 			}
 
-			// Set defaults
-			if endpoint.Timeout == 0 {
-				endpoint.Timeout = 30
-			}
-			if endpoint.Auth == nil {
-				endpoint.Auth = "none"
-			}
-			if endpoint.Method == "" {
-				endpoint.Method = "GET"
-			}
-
-			// Resolve auth
-			resolvedAuth, err := ResolveEndpointAuth(endpoint.Auth, m.config.AuthConfigs)
+			endpoint.RestoreRedacted(&m.config.Endpoints[i])
+			endpoint, err := m.completeEndpoint(endpoint)
 			if err != nil {
-				return fmt.Errorf("failed to resolve auth: %w", err)
+				return err
 			}
-			endpoint.ResolvedAuth = resolvedAuth
 
 			// Validate
 			if errors := endpoint.Validate(); len(errors) > 0 {
-				return fmt.Errorf("validation failed: %s", strings.Join(errors, "; "))
+				return &ValidationError{Problems: errors}
+			}
+
+			candidate := *m.config
+			candidate.Endpoints = append([]Endpoint(nil), m.config.Endpoints...)
+			candidate.Endpoints[i] = endpoint
+			if err := m.checkGuardrails(&candidate); err != nil {
+				return err
 			}
 
 			m.config.Endpoints[i] = endpoint
 			return nil
 		}
 	}
-	return fmt.Errorf("endpoint not found: %s", name)
+	return &NotFoundError{Kind: KindEndpoint, Name: name}
 }
 
 // DeleteEndpoint removes an endpoint by name
@@ -425,38 +666,21 @@ func (m *Manager) DeleteEndpoint(name string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("endpoint not found: %s", name)
+	return &NotFoundError{Kind: KindEndpoint, Name: name}
 }
 
-// FilterEndpoints returns endpoints matching the given filter patterns
-func (m *Manager) FilterEndpoints(filter string) []Endpoint {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if filter == "" {
-		endpoints := make([]Endpoint, len(m.config.Endpoints))
-		copy(endpoints, m.config.Endpoints)
-		return endpoints
+// FilterEndpoints returns endpoints matching the given filter patterns (see
+// ParseEndpointFilter)
+func (m *Manager) FilterEndpoints(filter string) ([]Endpoint, error) {
+	f, err := ParseEndpointFilter(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	patterns := strings.Split(filter, ",")
-	var filtered []Endpoint
-
-	for _, ep := range m.config.Endpoints {
-		for _, pattern := range patterns {
-			pattern = strings.TrimSpace(pattern)
-			if pattern == "" {
-				continue
-			}
-			// Simple substring matching
-			if strings.Contains(strings.ToLower(ep.Name), strings.ToLower(pattern)) {
-				filtered = append(filtered, ep)
-				break
-			}
-		}
-	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	return filtered
+	return f.Apply(m.config.Endpoints), nil
 }
 
 // --- Auth Config CRUD Operations ---
@@ -481,7 +705,7 @@ func (m *Manager) GetAuthConfig(name string) (*AuthConfig, error) {
 
 	cfg, exists := m.config.AuthConfigs[name]
 	if !exists {
-		return nil, fmt.Errorf("auth config not found: %s", name)
+		return nil, &NotFoundError{Kind: KindAuthConfig, Name: name}
 	}
 
 	configCopy := *cfg
@@ -494,17 +718,17 @@ func (m *Manager) AddAuthConfig(authCfg *AuthConfig) error {
 	defer m.mu.Unlock()
 
 	if authCfg.Name == "" {
-		return fmt.Errorf("auth config name is required")
+		return &ValidationError{Problems: []string{"auth config name is required"}}
 	}
 
 	// Check for duplicate name
 	if _, exists := m.config.AuthConfigs[authCfg.Name]; exists {
-		return fmt.Errorf("auth config already exists: %s", authCfg.Name)
+		return &AlreadyExistsError{Kind: KindAuthConfig, Name: authCfg.Name}
 	}
 
 	// Validate
 	if errors := authCfg.Validate(); len(errors) > 0 {
-		return fmt.Errorf("validation failed: %s", strings.Join(errors, "; "))
+		return &ValidationError{Problems: errors}
 	}
 
 	m.config.AuthConfigs[authCfg.Name] = authCfg
@@ -516,14 +740,16 @@ func (m *Manager) UpdateAuthConfig(name string, authCfg *AuthConfig) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.config.AuthConfigs[name]; !exists {
-		return fmt.Errorf("auth config not found: %s", name)
+	current, exists := m.config.AuthConfigs[name]
+	if !exists {
+		return &NotFoundError{Kind: KindAuthConfig, Name: name}
 	}
+	authCfg.RestoreRedacted(current)
 
 	// If name is being changed, check for duplicate
 	if authCfg.Name != name {
 		if _, exists := m.config.AuthConfigs[authCfg.Name]; exists {
-			return fmt.Errorf("auth config with name %s already exists", authCfg.Name)
+			return &AlreadyExistsError{Kind: KindAuthConfig, Name: authCfg.Name}
 		}
 		// Remove old name
 		delete(m.config.AuthConfigs, name)
@@ -531,7 +757,7 @@ func (m *Manager) UpdateAuthConfig(name string, authCfg *AuthConfig) error {
 
 	// Validate
 	if errors := authCfg.Validate(); len(errors) > 0 {
-		return fmt.Errorf("validation failed: %s", strings.Join(errors, "; "))
+		return &ValidationError{Problems: errors}
 	}
 
 	m.config.AuthConfigs[authCfg.Name] = authCfg
@@ -544,13 +770,13 @@ func (m *Manager) DeleteAuthConfig(name string) error {
 	defer m.mu.Unlock()
 
 	if _, exists := m.config.AuthConfigs[name]; !exists {
-		return fmt.Errorf("auth config not found: %s", name)
+		return &NotFoundError{Kind: KindAuthConfig, Name: name}
 	}
 
 	// Check if any endpoint is using this auth config
 	for _, ep := range m.config.Endpoints {
 		if authRef, ok := ep.Auth.(string); ok && authRef == name {
-			return fmt.Errorf("cannot delete auth config %s: used by endpoint %s", name, ep.Name)
+			return &InUseError{Kind: KindAuthConfig, Name: name, Endpoint: ep.Name}
 		}
 	}
 
@@ -597,7 +823,7 @@ func (m *Manager) GetIncomingRoute(name string) (*IncomingEndpoint, error) {
 			return &routeCopy, nil
 		}
 	}
-	return nil, fmt.Errorf("incoming route not found: %s", name)
+	return nil, &NotFoundError{Kind: KindIncomingRoute, Name: name}
 }
 
 // AddIncomingRoute adds a new incoming route
@@ -608,7 +834,7 @@ func (m *Manager) AddIncomingRoute(route IncomingEndpoint) error {
 	// Check for duplicate name
 	for _, r := range m.config.IncomingRoutes {
 		if r.Name == route.Name {
-			return fmt.Errorf("incoming route already exists: %s", route.Name)
+			return &AlreadyExistsError{Kind: KindIncomingRoute, Name: route.Name}
 		}
 	}
 
@@ -619,7 +845,7 @@ func (m *Manager) AddIncomingRoute(route IncomingEndpoint) error {
 
 	// Validate
 	if errors := route.Validate(); len(errors) > 0 {
-		return fmt.Errorf("validation failed: %s", strings.Join(errors, "; "))
+		return &ValidationError{Problems: errors}
 	}
 
 	m.config.IncomingRoutes = append(m.config.IncomingRoutes, route)
@@ -637,11 +863,13 @@ func (m *Manager) UpdateIncomingRoute(name string, route IncomingEndpoint) error
 			if route.Name != name {
 				for j, r := range m.config.IncomingRoutes {
 					if r.Name == route.Name && i != j {
-						return fmt.Errorf("incoming route with name %s already exists", route.Name)
+						return &AlreadyExistsError{Kind: KindIncomingRoute, Name: route.Name}
 					}
 				}
 			}
 
+			route.RestoreRedacted(&m.config.IncomingRoutes[i])
+
 			// Set defaults
 			if route.Method == "" {
 				route.Method = "GET"
@@ -649,14 +877,14 @@ func (m *Manager) UpdateIncomingRoute(name string, route IncomingEndpoint) error
 
 			// Validate
 			if errors := route.Validate(); len(errors) > 0 {
-				return fmt.Errorf("validation failed: %s", strings.Join(errors, "; "))
+				return &ValidationError{Problems: errors}
 			}
 
 			m.config.IncomingRoutes[i] = route
 			return nil
 		}
 	}
-	return fmt.Errorf("incoming route not found: %s", name)
+	return &NotFoundError{Kind: KindIncomingRoute, Name: name}
 }
 
 // DeleteIncomingRoute removes an incoming route by name
@@ -670,7 +898,7 @@ func (m *Manager) DeleteIncomingRoute(name string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("incoming route not found: %s", name)
+	return &NotFoundError{Kind: KindIncomingRoute, Name: name}
 }
 
 // SetIncomingRouteEnabled enables or disables a specific incoming route
@@ -684,7 +912,7 @@ func (m *Manager) SetIncomingRouteEnabled(name string, enabled bool) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("incoming route not found: %s", name)
+	return &NotFoundError{Kind: KindIncomingRoute, Name: name}
 }
 
 // MatchIncomingRoute finds the best matching route for a given path and method
@@ -764,28 +992,29 @@ func (m *Manager) GetConfigPath() string {
 
 // --- Statistics ---
 
-// GetTotalBaseRequestsPerMin returns the sum of all endpoint frequencies
+// GetTotalBaseRequestsPerMin returns the sum of all endpoint frequencies (group budgets included)
 func (m *Manager) GetTotalBaseRequestsPerMin() float64 {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var total float64
-	for _, ep := range m.config.Endpoints {
-		total += ep.FrequencyPerMin
+	for _, freq := range m.config.EffectiveFrequencies() {
+		total += freq
 	}
 	return total
 }
 
-// GetAdjustedRequestsPerMin returns the total requests per minute after applying multiplier
+// GetAdjustedRequestsPerMin returns the total requests per minute after applying
+// the global and group multipliers
 func (m *Manager) GetAdjustedRequestsPerMin() float64 {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var total float64
-	for _, ep := range m.config.Endpoints {
-		total += ep.FrequencyPerMin
+	for _, freq := range m.config.AdjustedFrequencies() {
+		total += freq
 	}
-	return total * m.config.GlobalMultiplier
+	return total
 }
 
 // --- Validation ---
@@ -805,10 +1034,47 @@ func (m *Manager) Validate() []string {
 		errors = append(errors, "concurrent_requests must be positive")
 	}
 
+	if m.config.IPFamily != "" && !IsValidIPFamily(m.config.IPFamily) {
+		errors = append(errors, invalidIPFamily(m.config.IPFamily))
+	}
+
+	errors = append(errors, m.config.DNSProbe.Validate()...)
+	errors = append(errors, m.config.Adaptive.Validate()...)
+	errors = append(errors, m.config.ResponseCapture.Validate()...)
+	errors = append(errors, m.config.APITLS.Validate()...)
+	errors = append(errors, m.config.APICORS.Validate()...)
+	errors = append(errors, m.config.APIAccessLog.Validate()...)
+	errors = append(errors, m.config.APIRateLimit.Validate()...)
+	errors = append(errors, m.config.IncomingClients.Validate()...)
+	errors = append(errors, m.config.HostLimits.Validate()...)
+	errors = append(errors, m.config.TLSCerts.Validate()...)
+	errors = append(errors, m.config.Guardrails.Validate()...)
+	errors = append(errors, m.config.Guardrails.Check(m.config)...)
+	errors = append(errors, m.config.GuardedActions.Validate()...)
+	errors = append(errors, m.config.Heartbeat.Validate()...)
+	errors = append(errors, m.config.ResourceLimits.Validate()...)
+	errors = append(errors, m.config.ValidateEnvironments()...)
+	errors = append(errors, m.config.Alerts.Validate()...)
+	errors = append(errors, m.config.Notifications.Validate()...)
+	errors = append(errors, m.config.ResultSinks.Validate()...)
+	errors = append(errors, ValidateStages("stages ", m.config.Stages)...)
+	errors = append(errors, ValidateLabels(m.config.Labels)...)
+	errors = append(errors, ValidatePercentiles(m.config.Percentiles)...)
+
 	if len(m.config.Endpoints) == 0 {
 		errors = append(errors, "at least one endpoint must be defined")
 	}
 
+	// Check endpoint groups
+	groups := make(map[string]bool)
+	for _, group := range m.config.EndpointGroups {
+		if groups[group.Name] {
+			errors = append(errors, fmt.Sprintf("duplicate endpoint group name: %s", group.Name))
+		}
+		groups[group.Name] = true
+		errors = append(errors, group.Validate()...)
+	}
+
 	// Check for duplicate endpoint names
 	seen := make(map[string]bool)
 	for _, ep := range m.config.Endpoints {
@@ -817,6 +1083,10 @@ func (m *Manager) Validate() []string {
 		}
 		seen[ep.Name] = true
 
+		if ep.Group != "" && !groups[ep.Group] {
+			errors = append(errors, fmt.Sprintf("endpoint %s: unknown group %s", ep.Name, ep.Group))
+		}
+
 		// Validate each endpoint
 		epErrors := ep.Validate()
 		errors = append(errors, epErrors...)
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetIPFamily(t *testing.T) {
+	m := NewManager()
+	if got := m.GetIPFamily(); got != IPFamilyDual {
+		t.Errorf("expected dual by default, got %q", got)
+	}
+
+	for _, family := range []string{IPFamilyIPv4, IPFamilyIPv6, IPFamilyDual} {
+		if err := m.SetIPFamily(family); err != nil || m.GetIPFamily() != family {
+			t.Errorf("SetIPFamily(%q) = %v, family %q", family, err, m.GetIPFamily())
+		}
+	}
+
+	m.SetIPFamily(IPFamilyIPv4)
+	for _, family := range []string{"", "ipv5", "IPv4", "tcp4"} {
+		var invalid *ValidationError
+		if err := m.SetIPFamily(family); !errors.As(err, &invalid) {
+			t.Errorf("SetIPFamily(%q): expected a validation error, got %v", family, err)
+		}
+		if got := m.GetIPFamily(); got != IPFamilyIPv4 {
+			t.Errorf("SetIPFamily(%q) changed the family to %q", family, got)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ConfigDiff describes what replacing a config with another would change
+type ConfigDiff struct {
+	Endpoints      NamedDiff       `json:"endpoints"`
+	EndpointGroups NamedDiff       `json:"endpoint_groups"`
+	AuthConfigs    NamedDiff       `json:"auth_configs"`
+	IncomingRoutes NamedDiff       `json:"incoming_routes"`
+	Settings       []SettingChange `json:"settings"`
+	Rate           RateDelta       `json:"rate"`
+	HasChanges     bool            `json:"has_changes"`
+}
+
+// NamedDiff lists added, removed and changed items of a named collection
+type NamedDiff struct {
+	Added   []string      `json:"added"`
+	Removed []string      `json:"removed"`
+	Changed []ChangedItem `json:"changed"`
+}
+
+// ChangedItem names an item present in both configs and the fields that differ
+type ChangedItem struct {
+	Name      string   `json:"name"`
+	Fields    []string `json:"fields"`
+	RateDelta float64  `json:"rate_delta,omitempty"` // Change in effective requests/min (endpoints only)
+}
+
+// SettingChange is a changed top-level setting
+type SettingChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// RateDelta compares the outgoing request rate of both configs, including
+// the global multiplier
+type RateDelta struct {
+	CurrentPerMin float64 `json:"current_per_min"`
+	NewPerMin     float64 `json:"new_per_min"`
+	DeltaPerMin   float64 `json:"delta_per_min"`
+	DeltaPercent  float64 `json:"delta_percent"`
+}
+
+// diffCollectionKeys are the top-level keys diffed item by item rather than as settings
+var diffCollectionKeys = map[string]bool{
+	"outgoing_endpoints": true,
+	"endpoint_groups":    true,
+	"auth_configs":       true,
+	"incoming_routes":    true,
+}
+
+// DiffConfigs compares the current config with a proposed replacement. Both
+// should be normalized (see Manager.ReplaceConfig) so defaults don't show up
+// as changes.
+func DiffConfigs(current, proposed *Config) ConfigDiff {
+	currentFreqs := current.AdjustedFrequencies()
+	proposedFreqs := proposed.AdjustedFrequencies()
+
+	diff := ConfigDiff{
+		Endpoints: diffNamed(
+			namedItems(current.Endpoints, func(e Endpoint) string { return e.Name }),
+			namedItems(proposed.Endpoints, func(e Endpoint) string { return e.Name }),
+		),
+		EndpointGroups: diffNamed(
+			namedItems(current.EndpointGroups, func(g EndpointGroup) string { return g.Name }),
+			namedItems(proposed.EndpointGroups, func(g EndpointGroup) string { return g.Name }),
+		),
+		AuthConfigs: diffNamed(
+			authItems(current.AuthConfigs),
+			authItems(proposed.AuthConfigs),
+		),
+		IncomingRoutes: diffNamed(
+			namedItems(current.IncomingRoutes, func(r IncomingEndpoint) string { return r.Name }),
+			namedItems(proposed.IncomingRoutes, func(r IncomingEndpoint) string { return r.Name }),
+		),
+		Settings: diffSettings(current, proposed),
+	}
+
+	// A group budget, multiplier or weight change can alter an endpoint's rate without
+	// changing the endpoint itself
+	for i := range diff.Endpoints.Changed {
+		name := diff.Endpoints.Changed[i].Name
+		diff.Endpoints.Changed[i].RateDelta = proposedFreqs[name] - currentFreqs[name]
+	}
+
+	diff.Rate.CurrentPerMin = totalPerMin(currentFreqs)
+	diff.Rate.NewPerMin = totalPerMin(proposedFreqs)
+	diff.Rate.DeltaPerMin = diff.Rate.NewPerMin - diff.Rate.CurrentPerMin
+	if diff.Rate.CurrentPerMin > 0 {
+		diff.Rate.DeltaPercent = diff.Rate.DeltaPerMin / diff.Rate.CurrentPerMin * 100
+	}
+
+	diff.HasChanges = len(diff.Settings) > 0
+	for _, named := range []NamedDiff{diff.Endpoints, diff.EndpointGroups, diff.AuthConfigs, diff.IncomingRoutes} {
+		if len(named.Added) > 0 || len(named.Removed) > 0 || len(named.Changed) > 0 {
+			diff.HasChanges = true
+		}
+	}
+	return diff
+}
+
+// namedItems converts items to their JSON representation keyed by name
+func namedItems[T any](items []T, name func(T) string) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{}, len(items))
+	for _, item := range items {
+		result[name(item)] = toJSONMap(item)
+	}
+	return result
+}
+
+// authItems converts auth configs to their JSON representation keyed by name
+func authItems(auths map[string]*AuthConfig) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{}, len(auths))
+	for name, auth := range auths {
+		result[name] = toJSONMap(auth)
+	}
+	return result
+}
+
+// toJSONMap returns the JSON object form of a value (nil if it isn't an object)
+func toJSONMap(value interface{}) map[string]interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// diffNamed compares two collections of items keyed by name
+func diffNamed(current, proposed map[string]map[string]interface{}) NamedDiff {
+	diff := NamedDiff{Added: []string{}, Removed: []string{}, Changed: []ChangedItem{}}
+
+	for name, item := range proposed {
+		old, exists := current[name]
+		if !exists {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		if fields := changedFields(old, item); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, ChangedItem{Name: name, Fields: fields})
+		}
+	}
+	for name := range current {
+		if _, exists := proposed[name]; !exists {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
+
+// changedFields returns the sorted keys whose values differ between two objects
+func changedFields(old, updated map[string]interface{}) []string {
+	var fields []string
+	for key, value := range updated {
+		if !reflect.DeepEqual(old[key], value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range old {
+		if _, exists := updated[key]; !exists {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// diffSettings compares the top-level settings of two configs
+func diffSettings(current, proposed *Config) []SettingChange {
+	old, updated := toJSONMap(current), toJSONMap(proposed)
+
+	changes := []SettingChange{}
+	for _, field := range changedFields(old, updated) {
+		if diffCollectionKeys[field] {
+			continue
+		}
+		changes = append(changes, SettingChange{Field: field, Old: old[field], New: updated[field]})
+	}
+	return changes
+}
+
+// totalPerMin sums effective endpoint frequencies
+func totalPerMin(freqs map[string]float64) float64 {
+	var total float64
+	for _, freq := range freqs {
+		total += freq
+	}
+	return total
+}
+
+// DiffAgainst compares the current config with a proposed replacement
+func (m *Manager) DiffAgainst(proposed *Config) ConfigDiff {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return DiffConfigs(m.config, proposed)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	current := &Config{
+		GlobalMultiplier:   1,
+		ConcurrentRequests: 30,
+		Endpoints: []Endpoint{
+			{Name: "a", Method: "GET", URLTemplate: "https://example.com/a", FrequencyPerMin: 60, Enabled: true},
+			{Name: "b", Method: "GET", URLTemplate: "https://example.com/b", FrequencyPerMin: 30, Enabled: true},
+		},
+	}
+	proposed := &Config{
+		GlobalMultiplier:   2,
+		ConcurrentRequests: 30,
+		Endpoints: []Endpoint{
+			{Name: "a", Method: "POST", URLTemplate: "https://example.com/a", FrequencyPerMin: 60, Enabled: true},
+			{Name: "c", Method: "GET", URLTemplate: "https://example.com/c", FrequencyPerMin: 10, Enabled: true},
+		},
+	}
+
+	diff := DiffConfigs(current, proposed)
+
+	if !diff.HasChanges {
+		t.Error("expected changes")
+	}
+	if !reflect.DeepEqual(diff.Endpoints.Added, []string{"c"}) || !reflect.DeepEqual(diff.Endpoints.Removed, []string{"b"}) {
+		t.Errorf("unexpected added/removed endpoints: %+v", diff.Endpoints)
+	}
+	if len(diff.Endpoints.Changed) != 1 || !reflect.DeepEqual(diff.Endpoints.Changed[0].Fields, []string{"method"}) {
+		t.Fatalf("expected endpoint a method change, got %+v", diff.Endpoints.Changed)
+	}
+	if diff.Endpoints.Changed[0].RateDelta != 60 {
+		t.Errorf("expected rate delta 60 for a, got %v", diff.Endpoints.Changed[0].RateDelta)
+	}
+	if len(diff.Settings) != 1 || diff.Settings[0].Field != "global_multiplier" {
+		t.Errorf("expected global_multiplier change, got %+v", diff.Settings)
+	}
+	if diff.Rate.CurrentPerMin != 90 || diff.Rate.NewPerMin != 140 || diff.Rate.DeltaPerMin != 50 {
+		t.Errorf("unexpected rate delta: %+v", diff.Rate)
+	}
+
+	if same := DiffConfigs(current, current.Clone()); same.HasChanges {
+		t.Errorf("expected no changes against a clone, got %+v", same)
+	}
+}
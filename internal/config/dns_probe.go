@@ -0,0 +1,96 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DNSProbeConfig configures the standalone DNS probe, which resolves hostnames
+// on a fixed interval independently of outgoing HTTP traffic
+type DNSProbeConfig struct {
+	Enabled         bool     `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	IntervalSeconds int      `mapstructure:"interval" yaml:"interval" json:"interval"`
+	Timeout         int      `mapstructure:"timeout" yaml:"timeout" json:"timeout"`
+	Resolver        string   `mapstructure:"resolver" yaml:"resolver,omitempty" json:"resolver,omitempty"` // host:port, defaults to the system resolver
+	Hostnames       []string `mapstructure:"hostnames" yaml:"hostnames,omitempty" json:"hostnames,omitempty"`
+}
+
+// Default DNS probe settings
+const (
+	DefaultDNSProbeInterval = 30
+	DefaultDNSProbeTimeout  = 5
+)
+
+// Validate checks if the DNS probe configuration is valid
+func (p *DNSProbeConfig) Validate() []string {
+	var errors []string
+
+	if p.IntervalSeconds < 0 {
+		errors = append(errors, "dns_probe: interval must be non-negative")
+	}
+
+	if p.Timeout < 0 {
+		errors = append(errors, "dns_probe: timeout must be non-negative")
+	}
+
+	if p.Resolver != "" {
+		if _, _, err := net.SplitHostPort(p.Resolver); err != nil {
+			errors = append(errors, fmt.Sprintf("dns_probe: invalid resolver %s (expected host:port)", p.Resolver))
+		}
+	}
+
+	for _, hostname := range p.Hostnames {
+		if strings.TrimSpace(hostname) == "" {
+			errors = append(errors, "dns_probe: hostnames must not be empty")
+			break
+		}
+	}
+
+	return errors
+}
+
+// GetDNSProbeConfig returns the DNS probe configuration with defaults applied
+func (m *Manager) GetDNSProbeConfig() DNSProbeConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	probe := m.config.DNSProbe
+	probe.Hostnames = append([]string(nil), m.config.DNSProbe.Hostnames...)
+	if probe.IntervalSeconds <= 0 {
+		probe.IntervalSeconds = DefaultDNSProbeInterval
+	}
+	if probe.Timeout <= 0 {
+		probe.Timeout = DefaultDNSProbeTimeout
+	}
+	return probe
+}
+
+// GetDNSProbeHostnames returns the hostnames the DNS probe should resolve.
+// Explicitly configured hostnames take precedence; otherwise the distinct
+// hostnames of enabled outgoing endpoints are used.
+func (m *Manager) GetDNSProbeHostnames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.config.DNSProbe.Hostnames) > 0 {
+		return append([]string(nil), m.config.DNSProbe.Hostnames...)
+	}
+
+	seen := make(map[string]bool)
+	var hostnames []string
+	for _, ep := range m.config.Endpoints {
+		if !ep.Enabled {
+			continue
+		}
+		hostname := ep.GetHostname()
+		// Skip hostnames that are only known after template rendering
+		if hostname == "" || strings.Contains(hostname, "{{") || seen[hostname] {
+			continue
+		}
+		seen[hostname] = true
+		hostnames = append(hostnames, hostname)
+	}
+	return hostnames
+}
@@ -3,7 +3,12 @@ package config
 
 import (
 	"fmt"
+	"math/rand"
+	"net"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,26 +22,94 @@ type Endpoint struct {
 	FrequencyPerMin float64           `mapstructure:"frequency" yaml:"frequency" json:"frequency"`
 	Auth            interface{}       `mapstructure:"auth" yaml:"auth" json:"auth"` // string ref or inline object
 	ResolvedAuth    *AuthConfig       `mapstructure:"-" yaml:"-" json:"-"`          // Resolved at load time
+	ResolvedEnv     *Environment      `mapstructure:"-" yaml:"-" json:"-"`          // Active environment, resolved at load time
 	Headers         map[string]string `mapstructure:"headers" yaml:"headers,omitempty" json:"headers,omitempty"`
 	Body            interface{}       `mapstructure:"body" yaml:"body,omitempty" json:"body,omitempty"`
+	BodyFile        string            `mapstructure:"body_file" yaml:"body_file,omitempty" json:"body_file,omitempty"`          // Raw request body read from a file
+	ContentType     string            `mapstructure:"content_type" yaml:"content_type,omitempty" json:"content_type,omitempty"` // Overrides the default Content-Type
+	Multipart       []MultipartField  `mapstructure:"multipart" yaml:"multipart,omitempty" json:"multipart,omitempty"`
 	Timeout         int               `mapstructure:"timeout" yaml:"timeout" json:"timeout"`
+	PauseWindows    []PauseWindow     `mapstructure:"pause_windows" yaml:"pause_windows,omitempty" json:"pause_windows,omitempty"`
+	Jitter          float64           `mapstructure:"jitter" yaml:"jitter,omitempty" json:"jitter,omitempty"`                               // Randomize interval by ±percent
+	Arrival         string            `mapstructure:"arrival" yaml:"arrival,omitempty" json:"arrival,omitempty"`                            // fixed (default) or poisson
+	Group           string            `mapstructure:"group" yaml:"group,omitempty" json:"group,omitempty"`                                  // Shares the group's budget instead of using frequency
+	Weight          float64           `mapstructure:"weight" yaml:"weight,omitempty" json:"weight,omitempty"`                               // Share of the group budget (default 1)
+	Tags            []string          `mapstructure:"tags" yaml:"tags,omitempty" json:"tags,omitempty"`                                     // Labels such as the owning team, used by filters and per-tag metrics
+	MaxInFlight     int               `mapstructure:"max_in_flight" yaml:"max_in_flight,omitempty" json:"max_in_flight,omitempty"`          // Cap on queued and running requests (0 = unlimited)
+	Priority        int               `mapstructure:"priority" yaml:"priority,omitempty" json:"priority,omitempty"`                         // Higher priorities get workers first when all are busy (default 0)
+	ExpectedStatus  StatusCodes       `mapstructure:"expected_status" yaml:"expected_status,omitempty" json:"expected_status,omitempty"`    // Status codes counted as success (default 2xx and 3xx)
+	Apdex           *Apdex            `mapstructure:"apdex" yaml:"apdex,omitempty" json:"apdex,omitempty"`                                  // Response time thresholds of the Apdex score
+	SLO             *SLO              `mapstructure:"slo" yaml:"slo,omitempty" json:"slo,omitempty"`                                        // Objective whose error budget is tracked by /api/metrics/slo
+	FollowRedirects bool              `mapstructure:"follow_redirects" yaml:"follow_redirects,omitempty" json:"follow_redirects,omitempty"` // Follow redirects instead of reporting the 3xx response
+	MaxRedirects    int               `mapstructure:"max_redirects" yaml:"max_redirects,omitempty" json:"max_redirects,omitempty"`          // Longest redirect chain followed (default 10)
+	AcceptEncoding  string            `mapstructure:"accept_encoding" yaml:"accept_encoding,omitempty" json:"accept_encoding,omitempty"`    // Accept-Encoding header (default gzip)
+	ConnectTo       string            `mapstructure:"connect_to" yaml:"connect_to,omitempty" json:"connect_to,omitempty"`                   // IP or host[:port] to connect to instead of resolving the URL's host
+	HostHeader      string            `mapstructure:"host_header" yaml:"host_header,omitempty" json:"host_header,omitempty"`                // Host header sent instead of the URL's host
+	SNI             string            `mapstructure:"sni" yaml:"sni,omitempty" json:"sni,omitempty"`                                        // TLS server name (default host_header, then the URL's host)
+	Transport       *Transport        `mapstructure:"transport" yaml:"transport,omitempty" json:"transport,omitempty"`                      // Custom dialer, such as a Unix socket, instead of TCP to the URL's host
+	Decompress      bool              `mapstructure:"decompress" yaml:"decompress,omitempty" json:"decompress,omitempty"`                   // Decode gzip and deflate bodies to measure their uncompressed size
+	ColdConnections float64           `mapstructure:"cold_connections" yaml:"cold_connections,omitempty" json:"cold_connections,omitempty"` // Share of requests forced onto new connections, splitting metrics into warm and cold (0-1)
+	VirtualUsers    int               `mapstructure:"virtual_users" yaml:"virtual_users,omitempty" json:"virtual_users,omitempty"`          // Closed-loop users sending requests one after another, instead of frequency
+	ThinkTimeMs     int               `mapstructure:"think_time_ms" yaml:"think_time_ms,omitempty" json:"think_time_ms,omitempty"`          // Wait of a virtual user between a response and its next request
+	Stages          []Stage           `mapstructure:"stages" yaml:"stages,omitempty" json:"stages,omitempty"`                               // Ramp of the endpoint's own virtual users, instead of the global stages
 	Enabled         bool              `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
 	EnabledSet      bool              `mapstructure:"enabled" yaml:"-" json:"-"`
 }
 
+// DefaultMaxRedirects is the longest redirect chain followed when
+// max_redirects isn't set
+const DefaultMaxRedirects = 10
+
+// Arrival modes controlling the spacing between requests of an endpoint
+const (
+	ArrivalFixed   = "fixed"   // Evenly spaced, optionally randomized by jitter
+	ArrivalPoisson = "poisson" // Exponentially distributed inter-arrival times
+)
+
+// IsValidArrival returns true if the given value is a supported arrival mode
+func IsValidArrival(arrival string) bool {
+	return arrival == ArrivalFixed || arrival == ArrivalPoisson
+}
+
 // UnmarshalYAML implements custom YAML parsing to detect explicit enabled field
 func (e *Endpoint) UnmarshalYAML(value *yaml.Node) error {
 	var raw struct {
-		Name        string            `yaml:"name"`
-		Method      string            `yaml:"method"`
-		URLTemplate string            `yaml:"url_template"`
-		ConfigPath  string            `yaml:"config_path"`
-		Frequency   float64           `yaml:"frequency"`
-		Auth        interface{}       `yaml:"auth"`
-		Headers     map[string]string `yaml:"headers"`
-		Body        interface{}       `yaml:"body"`
-		Timeout     int               `yaml:"timeout"`
-		Enabled     *bool             `yaml:"enabled"`
+		Name            string            `yaml:"name"`
+		Method          string            `yaml:"method"`
+		URLTemplate     string            `yaml:"url_template"`
+		ConfigPath      string            `yaml:"config_path"`
+		Frequency       float64           `yaml:"frequency"`
+		Auth            interface{}       `yaml:"auth"`
+		Headers         map[string]string `yaml:"headers"`
+		Body            interface{}       `yaml:"body"`
+		BodyFile        string            `yaml:"body_file"`
+		ContentType     string            `yaml:"content_type"`
+		Multipart       []MultipartField  `yaml:"multipart"`
+		Timeout         int               `yaml:"timeout"`
+		PauseWindows    []PauseWindow     `yaml:"pause_windows"`
+		Jitter          float64           `yaml:"jitter"`
+		Arrival         string            `yaml:"arrival"`
+		Group           string            `yaml:"group"`
+		Weight          float64           `yaml:"weight"`
+		Tags            []string          `yaml:"tags"`
+		MaxInFlight     int               `yaml:"max_in_flight"`
+		Priority        int               `yaml:"priority"`
+		ExpectedStatus  StatusCodes       `yaml:"expected_status"`
+		Apdex           *Apdex            `yaml:"apdex"`
+		SLO             *SLO              `yaml:"slo"`
+		FollowRedirects bool              `yaml:"follow_redirects"`
+		MaxRedirects    int               `yaml:"max_redirects"`
+		AcceptEncoding  string            `yaml:"accept_encoding"`
+		ConnectTo       string            `yaml:"connect_to"`
+		HostHeader      string            `yaml:"host_header"`
+		SNI             string            `yaml:"sni"`
+		Transport       *Transport        `yaml:"transport"`
+		Decompress      bool              `yaml:"decompress"`
+		ColdConnections float64           `yaml:"cold_connections"`
+		VirtualUsers    int               `yaml:"virtual_users"`
+		ThinkTimeMs     int               `yaml:"think_time_ms"`
+		Stages          []Stage           `yaml:"stages"`
+		Enabled         *bool             `yaml:"enabled"`
 	}
 
 	if err := value.Decode(&raw); err != nil {
@@ -51,7 +124,33 @@ func (e *Endpoint) UnmarshalYAML(value *yaml.Node) error {
 	e.Auth = raw.Auth
 	e.Headers = raw.Headers
 	e.Body = raw.Body
+	e.BodyFile = raw.BodyFile
+	e.ContentType = raw.ContentType
+	e.Multipart = raw.Multipart
 	e.Timeout = raw.Timeout
+	e.PauseWindows = raw.PauseWindows
+	e.Jitter = raw.Jitter
+	e.Arrival = raw.Arrival
+	e.Group = raw.Group
+	e.Weight = raw.Weight
+	e.Tags = raw.Tags
+	e.MaxInFlight = raw.MaxInFlight
+	e.Priority = raw.Priority
+	e.ExpectedStatus = raw.ExpectedStatus
+	e.Apdex = raw.Apdex
+	e.SLO = raw.SLO
+	e.FollowRedirects = raw.FollowRedirects
+	e.MaxRedirects = raw.MaxRedirects
+	e.AcceptEncoding = raw.AcceptEncoding
+	e.ConnectTo = raw.ConnectTo
+	e.HostHeader = raw.HostHeader
+	e.SNI = raw.SNI
+	e.Transport = raw.Transport
+	e.Decompress = raw.Decompress
+	e.ColdConnections = raw.ColdConnections
+	e.VirtualUsers = raw.VirtualUsers
+	e.ThinkTimeMs = raw.ThinkTimeMs
+	e.Stages = raw.Stages
 	if raw.Enabled != nil {
 		e.Enabled = *raw.Enabled
 		e.EnabledSet = true
@@ -89,13 +188,188 @@ func (e *Endpoint) Validate() []string {
 		errors = append(errors, fmt.Sprintf("endpoint %s: timeout must be positive", e.Name))
 	}
 
+	bodySources := 0
+	for _, set := range []bool{e.Body != nil, e.BodyFile != "", len(e.Multipart) > 0} {
+		if set {
+			bodySources++
+		}
+	}
+	if bodySources > 1 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: body, body_file and multipart are mutually exclusive", e.Name))
+	}
+
+	for _, field := range e.Multipart {
+		for _, err := range field.Validate() {
+			errors = append(errors, fmt.Sprintf("endpoint %s: %s", e.Name, err))
+		}
+	}
+
+	if e.Jitter < 0 || e.Jitter > 100 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: jitter must be between 0 and 100", e.Name))
+	}
+
+	if e.Weight < 0 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: weight must be non-negative", e.Name))
+	}
+
+	if e.MaxInFlight < 0 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: max_in_flight must be non-negative", e.Name))
+	}
+
+	for _, tag := range e.Tags {
+		if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+			errors = append(errors, fmt.Sprintf("endpoint %s: invalid tag %q (must be non-empty and contain no commas)", e.Name, tag))
+		}
+	}
+
+	for _, err := range e.ExpectedStatus.Validate() {
+		errors = append(errors, fmt.Sprintf("endpoint %s: expected_status: %s", e.Name, err))
+	}
+
+	if e.VirtualUsers < 0 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: virtual_users must be non-negative", e.Name))
+	}
+	if e.ThinkTimeMs < 0 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: think_time_ms must be non-negative", e.Name))
+	}
+	if e.ThinkTimeMs > 0 && !e.IsClosedLoop() {
+		errors = append(errors, fmt.Sprintf("endpoint %s: think_time_ms requires virtual_users or stages", e.Name))
+	}
+	if e.IsClosedLoop() && e.Group != "" {
+		errors = append(errors, fmt.Sprintf("endpoint %s: virtual_users can't be combined with group", e.Name))
+	}
+	if e.IsClosedLoop() && e.Arrival == ArrivalPoisson {
+		errors = append(errors, fmt.Sprintf("endpoint %s: arrival poisson can't be combined with virtual_users", e.Name))
+	}
+	errors = append(errors, ValidateStages(fmt.Sprintf("endpoint %s: stages ", e.Name), e.Stages)...)
+
+	if e.MaxRedirects < 0 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: max_redirects must be non-negative", e.Name))
+	}
+
+	if e.Arrival != "" && !IsValidArrival(e.Arrival) {
+		errors = append(errors, fmt.Sprintf("endpoint %s: invalid arrival %s (must be one of: fixed, poisson)", e.Name, e.Arrival))
+	}
+
+	for _, window := range e.PauseWindows {
+		for _, err := range window.Validate() {
+			errors = append(errors, fmt.Sprintf("endpoint %s: %s", e.Name, err))
+		}
+	}
+
+	if e.ConnectTo != "" && ConnectHost(e.ConnectTo) == "" {
+		errors = append(errors, fmt.Sprintf("endpoint %s: connect_to must be an IP or host, with an optional port", e.Name))
+	}
+	if e.HostHeader != "" && strings.ContainsAny(e.HostHeader, " /") {
+		errors = append(errors, fmt.Sprintf("endpoint %s: host_header must be a host with an optional port", e.Name))
+	}
+	if e.SNI != "" && (strings.ContainsAny(e.SNI, " /:") || net.ParseIP(e.SNI) != nil) {
+		errors = append(errors, fmt.Sprintf("endpoint %s: sni must be a hostname", e.Name))
+	}
+
+	if e.ColdConnections < 0 || e.ColdConnections > 1 {
+		errors = append(errors, fmt.Sprintf("endpoint %s: cold_connections must be between 0 and 1", e.Name))
+	}
+
+	if e.Apdex != nil {
+		for _, err := range e.Apdex.Validate() {
+			errors = append(errors, fmt.Sprintf("endpoint %s: %s", e.Name, err))
+		}
+	}
+
+	if e.SLO != nil {
+		for _, err := range e.SLO.Validate() {
+			errors = append(errors, fmt.Sprintf("endpoint %s: %s", e.Name, err))
+		}
+	}
+
+	if e.Transport != nil {
+		for _, err := range e.Transport.Validate() {
+			errors = append(errors, fmt.Sprintf("endpoint %s: %s", e.Name, err))
+		}
+		if e.ConnectTo != "" && (e.Transport.Address != "" || e.Transport.DialNetwork() == NetworkUnix) {
+			errors = append(errors, fmt.Sprintf("endpoint %s: connect_to and transport.address are mutually exclusive", e.Name))
+		}
+	}
+
 	return errors
 }
 
-// GetHostname extracts the hostname from the URL template
+// ConnectHost returns the host of a connect_to address, "" if it is invalid
+func ConnectHost(connectTo string) string {
+	host, port, err := net.SplitHostPort(connectTo)
+	if err != nil {
+		host, port = strings.TrimSuffix(strings.TrimPrefix(connectTo, "["), "]"), ""
+	}
+	if _, err := strconv.Atoi(port); port != "" && err != nil {
+		return ""
+	}
+	if host == "" || strings.ContainsAny(host, " /[]") {
+		return ""
+	}
+	return host
+}
+
+// ServerName returns the TLS server name sent: sni, else the hostname of
+// host_header, else "" for the URL's host
+func (e *Endpoint) ServerName() string {
+	if e.SNI != "" {
+		return e.SNI
+	}
+	if e.HostHeader != "" {
+		if host, _, err := net.SplitHostPort(e.HostHeader); err == nil {
+			return host
+		}
+		return e.HostHeader
+	}
+	return ""
+}
+
+// IsClosedLoop reports whether the endpoint is driven by virtual users
+// instead of its frequency
+func (e *Endpoint) IsClosedLoop() bool {
+	return e.VirtualUsers > 0 || len(e.Stages) > 0
+}
+
+// ThinkTime returns a virtual user's wait before its next request, randomized
+// by the endpoint's jitter
+func (e *Endpoint) ThinkTime() time.Duration {
+	think := time.Duration(e.ThinkTimeMs) * time.Millisecond
+	if e.Jitter > 0 && think > 0 {
+		factor := 1 + (rand.Float64()*2-1)*e.Jitter/100
+		think = time.Duration(float64(think) * factor)
+	}
+	return think
+}
+
+// RedirectLimit returns the longest redirect chain followed
+func (e *Endpoint) RedirectLimit() int {
+	if e.MaxRedirects > 0 {
+		return e.MaxRedirects
+	}
+	return DefaultMaxRedirects
+}
+
+// HasTag reports whether the endpoint has a tag (case-insensitive)
+func (e *Endpoint) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetHostname extracts the hostname from the URL template, with the base
+// URL of the active environment filled in
 func (e *Endpoint) GetHostname() string {
+	urlTemplate := e.URLTemplate
+	if e.ResolvedEnv != nil {
+		urlTemplate = baseURLPattern.ReplaceAllLiteralString(urlTemplate, e.ResolvedEnv.BaseURL)
+	}
+
 	// Try to parse the URL template (may contain template variables)
-	parsedURL, err := url.Parse(e.URLTemplate)
+	parsedURL, err := url.Parse(urlTemplate)
 	if err != nil {
 		return ""
 	}
@@ -111,6 +385,37 @@ func (e *Endpoint) Clone() Endpoint {
 			clone.Headers[k] = v
 		}
 	}
+	if e.Tags != nil {
+		clone.Tags = append([]string(nil), e.Tags...)
+	}
+	if e.ExpectedStatus != nil {
+		clone.ExpectedStatus = append(StatusCodes(nil), e.ExpectedStatus...)
+	}
+	if e.Stages != nil {
+		clone.Stages = append([]Stage(nil), e.Stages...)
+	}
+	if e.Multipart != nil {
+		clone.Multipart = append([]MultipartField(nil), e.Multipart...)
+	}
+	if e.Apdex != nil {
+		apdex := *e.Apdex
+		clone.Apdex = &apdex
+	}
+	if e.SLO != nil {
+		slo := *e.SLO
+		clone.SLO = &slo
+	}
+	if e.Transport != nil {
+		transport := *e.Transport
+		clone.Transport = &transport
+	}
+	if e.PauseWindows != nil {
+		clone.PauseWindows = make([]PauseWindow, len(e.PauseWindows))
+		for i, window := range e.PauseWindows {
+			clone.PauseWindows[i] = window
+			clone.PauseWindows[i].Days = append([]string(nil), window.Days...)
+		}
+	}
 	return clone
 }
 
@@ -124,7 +429,33 @@ type EndpointRequest struct {
 	Auth            interface{}       `json:"auth,omitempty"`
 	Headers         map[string]string `json:"headers,omitempty"`
 	Body            interface{}       `json:"body,omitempty"`
+	BodyFile        string            `json:"body_file,omitempty"`
+	ContentType     string            `json:"content_type,omitempty"`
+	Multipart       []MultipartField  `json:"multipart,omitempty"`
 	Timeout         int               `json:"timeout,omitempty"`
+	PauseWindows    []PauseWindow     `json:"pause_windows,omitempty"`
+	Jitter          float64           `json:"jitter,omitempty"`
+	Arrival         string            `json:"arrival,omitempty"`
+	Group           string            `json:"group,omitempty"`
+	Weight          float64           `json:"weight,omitempty"`
+	Tags            []string          `json:"tags,omitempty"`
+	MaxInFlight     int               `json:"max_in_flight,omitempty"`
+	Priority        int               `json:"priority,omitempty"`
+	ExpectedStatus  StatusCodes       `json:"expected_status,omitempty"`
+	Apdex           *Apdex            `json:"apdex,omitempty"`
+	SLO             *SLO              `json:"slo,omitempty"`
+	FollowRedirects bool              `json:"follow_redirects,omitempty"`
+	MaxRedirects    int               `json:"max_redirects,omitempty"`
+	AcceptEncoding  string            `json:"accept_encoding,omitempty"`
+	ConnectTo       string            `json:"connect_to,omitempty"`
+	HostHeader      string            `json:"host_header,omitempty"`
+	SNI             string            `json:"sni,omitempty"`
+	Transport       *Transport        `json:"transport,omitempty"`
+	Decompress      bool              `json:"decompress,omitempty"`
+	ColdConnections float64           `json:"cold_connections,omitempty"`
+	VirtualUsers    int               `json:"virtual_users,omitempty"`
+	ThinkTimeMs     int               `json:"think_time_ms,omitempty"`
+	Stages          []Stage           `json:"stages,omitempty"`
 	Enabled         bool              `json:"enabled"`
 }
 
@@ -139,7 +470,33 @@ func (r *EndpointRequest) ToEndpoint() Endpoint {
 		Auth:            r.Auth,
 		Headers:         r.Headers,
 		Body:            r.Body,
+		BodyFile:        r.BodyFile,
+		ContentType:     r.ContentType,
+		Multipart:       r.Multipart,
 		Timeout:         r.Timeout,
+		PauseWindows:    r.PauseWindows,
+		Jitter:          r.Jitter,
+		Arrival:         r.Arrival,
+		Group:           r.Group,
+		Weight:          r.Weight,
+		Tags:            r.Tags,
+		MaxInFlight:     r.MaxInFlight,
+		Priority:        r.Priority,
+		ExpectedStatus:  r.ExpectedStatus,
+		Apdex:           r.Apdex,
+		SLO:             r.SLO,
+		FollowRedirects: r.FollowRedirects,
+		MaxRedirects:    r.MaxRedirects,
+		AcceptEncoding:  r.AcceptEncoding,
+		ConnectTo:       r.ConnectTo,
+		HostHeader:      r.HostHeader,
+		SNI:             r.SNI,
+		Transport:       r.Transport,
+		Decompress:      r.Decompress,
+		ColdConnections: r.ColdConnections,
+		VirtualUsers:    r.VirtualUsers,
+		ThinkTimeMs:     r.ThinkTimeMs,
+		Stages:          r.Stages,
 		Enabled:         r.Enabled,
 		EnabledSet:      true,
 	}
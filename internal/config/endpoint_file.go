@@ -0,0 +1,277 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EndpointFile edits the outgoing endpoints of a config file in place. Edits
+// are made on the lines of the affected endpoint only, so comments, blank
+// lines and templates elsewhere in the file are kept as written.
+type EndpointFile struct {
+	path  string
+	lines []string
+}
+
+// OpenEndpointFile reads a config file for editing
+func OpenEndpointFile(path string) (*EndpointFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	f := &EndpointFile{path: path}
+	if content := strings.TrimSuffix(string(data), "\n"); content != "" {
+		f.lines = strings.Split(content, "\n")
+	}
+	if _, err := f.parse(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Add appends an endpoint after validating it against the file's auth
+// configs and existing endpoints
+func (f *EndpointFile) Add(endpoint Endpoint) error {
+	manager := NewManager()
+	if err := f.load(manager); err != nil {
+		return err
+	}
+	if err := manager.AddEndpoint(endpoint); err != nil {
+		return err
+	}
+	added, err := manager.GetEndpoint(endpoint.Name)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(added); err != nil {
+		return fmt.Errorf("failed to encode endpoint: %w", err)
+	}
+	var fields []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		// Endpoints are enabled unless the file says otherwise
+		if strings.HasPrefix(line, "enabled:") && !endpoint.EnabledSet {
+			continue
+		}
+		fields = append(fields, line)
+	}
+
+	seq, err := f.parse()
+	if err != nil {
+		return err
+	}
+	dash := "  "
+	if seq.node != nil && len(seq.node.Content) > 0 {
+		dash = strings.Repeat(" ", seq.node.Content[0].Column-3)
+	}
+	item := []string{dash + "- " + fields[0]}
+	for _, field := range fields[1:] {
+		item = append(item, dash+"  "+field)
+	}
+
+	switch {
+	case seq.node == nil:
+		f.lines = append(f.lines, "outgoing_endpoints:")
+		f.lines = append(f.lines, item...)
+	case len(seq.node.Content) == 0:
+		// outgoing_endpoints: [] or no value
+		f.lines[seq.keyLine] = f.lines[seq.keyLine][:seq.keyEnd] + ":"
+		f.insert(seq.keyLine+1, item)
+	default:
+		f.insert(seq.itemEnd(len(seq.node.Content)-1)+1, item)
+	}
+	return nil
+}
+
+// Delete removes an endpoint by name, along with the comments right above it
+func (f *EndpointFile) Delete(name string) error {
+	seq, err := f.parse()
+	if err != nil {
+		return err
+	}
+	i, err := seq.find(name)
+	if err != nil {
+		return err
+	}
+
+	start, end := seq.itemStart(i), seq.itemEnd(i)
+	f.lines = append(f.lines[:start], f.lines[end+1:]...)
+	return nil
+}
+
+// SetEnabled enables or disables an endpoint by name
+func (f *EndpointFile) SetEnabled(name string, enabled bool) error {
+	seq, err := f.parse()
+	if err != nil {
+		return err
+	}
+	i, err := seq.find(name)
+	if err != nil {
+		return err
+	}
+
+	item := seq.node.Content[i]
+	value := fmt.Sprint(enabled)
+	for k := 0; k+1 < len(item.Content); k += 2 {
+		if item.Content[k].Value != "enabled" {
+			continue
+		}
+		node := item.Content[k+1]
+		line := f.lines[node.Line-1]
+		start := node.Column - 1
+		end := start + len(node.Value)
+		if node.Kind != yaml.ScalarNode || node.Style != 0 || end > len(line) || line[start:end] != node.Value {
+			return fmt.Errorf("endpoint %s: can't edit enabled on line %d", name, node.Line)
+		}
+		f.lines[node.Line-1] = line[:start] + value + line[end:]
+		return nil
+	}
+
+	// No enabled key yet: add it below the name
+	nameKey := item.Content[0]
+	for k := 0; k+1 < len(item.Content); k += 2 {
+		if item.Content[k].Value == "name" {
+			nameKey = item.Content[k]
+		}
+	}
+	f.insert(nameKey.Line, []string{strings.Repeat(" ", nameKey.Column-1) + "enabled: " + value})
+	return nil
+}
+
+// Save writes the edited file back, keeping its permissions
+func (f *EndpointFile) Save() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, []byte(strings.Join(f.lines, "\n")+"\n"), info.Mode().Perm())
+}
+
+// load loads the edited lines into a manager, from a file next to the
+// original so relative paths resolve the same
+func (f *EndpointFile) load(manager *Manager) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".moxapp-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(strings.Join(f.lines, "\n") + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return manager.LoadFromFile(tmp.Name())
+}
+
+// insert inserts lines before the line at index at (0-based)
+func (f *EndpointFile) insert(at int, lines []string) {
+	f.lines = append(f.lines[:at], append(lines, f.lines[at:]...)...)
+}
+
+// endpointSeq locates the outgoing_endpoints list in the file's lines
+type endpointSeq struct {
+	lines   []string
+	node    *yaml.Node // nil if the file has no outgoing_endpoints key
+	keyLine int        // 0-based line of the outgoing_endpoints key
+	keyEnd  int        // Column just after the key
+}
+
+// parse locates the outgoing_endpoints list
+func (f *EndpointFile) parse() (*endpointSeq, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(f.lines, "\n")), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	seq := &endpointSeq{lines: f.lines}
+	if doc.Kind == 0 {
+		return seq, nil // Empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config file %s is not a YAML mapping", f.path)
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "outgoing_endpoints" {
+			continue
+		}
+		switch {
+		case value.Kind == yaml.SequenceNode && value.Style&yaml.FlowStyle != 0 && len(value.Content) > 0:
+			return nil, fmt.Errorf("outgoing_endpoints on line %d is a flow sequence; only block lists can be edited", value.Line)
+		case value.Kind == yaml.SequenceNode:
+			seq.node = value
+		case value.Kind == yaml.ScalarNode && value.Tag == "!!null":
+			seq.node = &yaml.Node{Kind: yaml.SequenceNode}
+		default:
+			return nil, fmt.Errorf("outgoing_endpoints on line %d is not a list", value.Line)
+		}
+		seq.keyLine = key.Line - 1
+		seq.keyEnd = key.Column - 1 + len(key.Value)
+		return seq, nil
+	}
+	return seq, nil
+}
+
+// find returns the index of the named endpoint
+func (s *endpointSeq) find(name string) (int, error) {
+	if s.node != nil {
+		for i, item := range s.node.Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+			for k := 0; k+1 < len(item.Content); k += 2 {
+				if item.Content[k].Value == "name" && item.Content[k+1].Value == name {
+					return i, nil
+				}
+			}
+		}
+	}
+	return 0, &NotFoundError{Kind: KindEndpoint, Name: name}
+}
+
+// itemStart returns the first line of an item (0-based), including the
+// comment lines directly above it
+func (s *endpointSeq) itemStart(i int) int {
+	start := s.node.Content[i].Line - 1
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(s.lines[start-1]), "#") {
+		start--
+	}
+	return start
+}
+
+// itemEnd returns the last line of an item (0-based): the line before the
+// next item, or for the last item the last line indented past its dash
+func (s *endpointSeq) itemEnd(i int) int {
+	if i+1 < len(s.node.Content) {
+		return s.itemStart(i+1) - 1
+	}
+
+	item := s.node.Content[i]
+	dash := item.Column - 3
+	end := item.Line - 1
+	for l := end + 1; l < len(s.lines); l++ {
+		line := s.lines[l]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line)-len(strings.TrimLeft(line, " ")) <= dash {
+			break
+		}
+		end = l
+	}
+	return end
+}
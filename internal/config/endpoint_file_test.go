@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEndpointFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	original := `# Load test config
+concurrent_requests: 5
+outgoing_endpoints:
+  # Keep this one
+  - name: a
+    url_template: "{{ .Env.BASE_URL }}/a"
+    frequency: 1
+  - name: b
+    url_template: https://example.com/b
+    frequency: 2
+`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenEndpointFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Add(Endpoint{Name: "c", URLTemplate: "https://example.com/c", FrequencyPerMin: 3}); err != nil {
+		t.Fatalf("unexpected add error: %v", err)
+	}
+	if err := f.Add(Endpoint{Name: "a", URLTemplate: "https://example.com/a", FrequencyPerMin: 1}); err == nil {
+		t.Error("expected error for duplicate endpoint")
+	}
+	if err := f.SetEnabled("a", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Delete("missing"); err == nil {
+		t.Error("expected error for unknown endpoint")
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Load test config", "# Keep this one", "{{ .Env.BASE_URL }}/a"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("saved file lost %q:\n%s", want, data)
+		}
+	}
+
+	manager := NewManager()
+	if err := manager.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	endpoints := manager.GetEndpoints()
+	if len(endpoints) != 2 || endpoints[0].Name != "a" || endpoints[1].Name != "c" {
+		t.Fatalf("expected endpoints a and c, got %v", endpoints)
+	}
+	if endpoints[0].Enabled || !endpoints[1].Enabled {
+		t.Errorf("expected a disabled and c enabled, got %v and %v", endpoints[0].Enabled, endpoints[1].Enabled)
+	}
+	if endpoints[1].FrequencyPerMin != 3 || endpoints[1].Method != "GET" {
+		t.Errorf("unexpected added endpoint: %+v", endpoints[1])
+	}
+}
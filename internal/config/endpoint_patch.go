@@ -0,0 +1,108 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EndpointPatch is a partial update of an endpoint: the JSON object of the
+// fields to change, which must name the endpoint. Fields left out keep their
+// values; objects such as headers are merged and lists replaced.
+type EndpointPatch struct {
+	Name   string
+	Fields json.RawMessage
+}
+
+// ParseEndpointPatch reads a partial update from its JSON object
+func ParseEndpointPatch(raw json.RawMessage) (EndpointPatch, error) {
+	var named struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return EndpointPatch{}, err
+	}
+	if named.Name == "" {
+		return EndpointPatch{}, &ValidationError{Problems: []string{"name is required"}}
+	}
+	return EndpointPatch{Name: named.Name, Fields: raw}, nil
+}
+
+// PatchEndpoints applies partial updates to endpoints atomically: either
+// every update is valid and all are applied, or none is. It returns the
+// updated endpoints and an error per patch, nil for those that were (or
+// would have been) applied. Patches apply in order, so an endpoint may be
+// patched more than once.
+func (m *Manager) PatchEndpoints(patches []EndpointPatch) ([]Endpoint, []error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	working := append([]Endpoint(nil), m.config.Endpoints...)
+	index := make(map[string]int, len(working))
+	for i, ep := range working {
+		index[ep.Name] = i
+	}
+
+	updated := make([]Endpoint, len(patches))
+	errs := make([]error, len(patches))
+	failed := false
+	for p, patch := range patches {
+		i, ok := index[patch.Name]
+		if !ok {
+			errs[p] = &NotFoundError{Kind: KindEndpoint, Name: patch.Name}
+			failed = true
+			continue
+		}
+
+		endpoint, err := patchEndpoint(working[i], patch.Fields)
+		if err == nil {
+			endpoint, err = m.completeEndpoint(endpoint)
+		}
+		if err == nil {
+			if problems := endpoint.Validate(); len(problems) > 0 {
+				err = &ValidationError{Problems: problems}
+			}
+		}
+		if err != nil {
+			errs[p] = err
+			failed = true
+			continue
+		}
+		working[i] = endpoint
+		updated[p] = endpoint
+	}
+
+	if !failed {
+		candidate := *m.config
+		candidate.Endpoints = working
+		if err := m.checkGuardrails(&candidate); err != nil {
+			// The patches are only rejected together
+			for p := range errs {
+				errs[p] = err
+			}
+			return updated, errs
+		}
+		m.config.Endpoints = working
+	}
+	return updated, errs
+}
+
+// patchEndpoint returns a copy of an endpoint with the fields of a partial
+// update set. The copy goes through JSON so it shares no maps or slices with
+// the original.
+func patchEndpoint(endpoint Endpoint, fields json.RawMessage) (Endpoint, error) {
+	base, err := json.Marshal(endpoint)
+	if err != nil {
+		return endpoint, err
+	}
+	var patched Endpoint
+	if err := json.Unmarshal(base, &patched); err != nil {
+		return endpoint, err
+	}
+	if err := json.Unmarshal(fields, &patched); err != nil {
+		return endpoint, &ValidationError{Problems: []string{fmt.Sprintf("invalid update: %v", err)}}
+	}
+	patched.RestoreRedacted(&endpoint)
+	patched.EnabledSet = true
+	return patched, nil
+}
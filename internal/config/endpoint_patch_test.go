@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func patches(t *testing.T, raw string) []EndpointPatch {
+	t.Helper()
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		t.Fatalf("invalid test JSON: %v", err)
+	}
+	var out []EndpointPatch
+	for _, item := range items {
+		patch, err := ParseEndpointPatch(item)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out = append(out, patch)
+	}
+	return out
+}
+
+func TestPatchEndpoints(t *testing.T) {
+	m := NewManager()
+	for _, name := range []string{"a", "b"} {
+		ep := Endpoint{Name: name, URLTemplate: "https://example.com/" + name, FrequencyPerMin: 6, Headers: map[string]string{"X-Team": "core"}}
+		if err := m.AddEndpoint(ep); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	updated, errs := m.PatchEndpoints(patches(t, `[{"name":"a","timeout":10},{"name":"b","timeout":10,"headers":{"X-Env":"ci"}}]`))
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("patch %d: unexpected error: %v", i, err)
+		}
+	}
+	b, _ := m.GetEndpoint("b")
+	if b.Timeout != 10 || b.FrequencyPerMin != 6 || b.URLTemplate != "https://example.com/b" {
+		t.Errorf("expected only timeout to change, got %+v", b)
+	}
+	if b.Headers["X-Team"] != "core" || b.Headers["X-Env"] != "ci" {
+		t.Errorf("expected headers to be merged, got %v", b.Headers)
+	}
+	if updated[0].Timeout != 10 {
+		t.Errorf("expected updated endpoint in results, got %+v", updated[0])
+	}
+
+	// One invalid update leaves every endpoint untouched
+	_, errs = m.PatchEndpoints(patches(t, `[{"name":"a","timeout":20},{"name":"b","jitter":500},{"name":"c","timeout":20}]`))
+	var invalid *ValidationError
+	var notFound *NotFoundError
+	if errs[0] != nil || !errors.As(errs[1], &invalid) || !errors.As(errs[2], &notFound) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if a, _ := m.GetEndpoint("a"); a.Timeout != 10 {
+		t.Errorf("expected failed batch not to apply, got timeout %d", a.Timeout)
+	}
+}
+
+func TestParseEndpointPatch_RequiresName(t *testing.T) {
+	var invalid *ValidationError
+	if _, err := ParseEndpointPatch(json.RawMessage(`{"timeout":10}`)); !errors.As(err, &invalid) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Environment is a target of the whole test, such as staging or perf. URL,
+// header and body templates of endpoints reference the active environment
+// as {{.BaseURL}} and {{.Vars.name}}.
+type Environment struct {
+	BaseURL string            `mapstructure:"base_url" yaml:"base_url" json:"base_url"`
+	Vars    map[string]string `mapstructure:"vars" yaml:"vars,omitempty" json:"vars,omitempty"` // Keys are lowercased in the config file
+}
+
+// baseURLPattern matches references to the base URL in templates
+var baseURLPattern = regexp.MustCompile(`\{\{-?\s*\.BaseURL\s*-?\}\}`)
+
+// environmentPattern matches references to the environment in templates
+var environmentPattern = regexp.MustCompile(`\{\{[^}]*\.(BaseURL|Vars)\b`)
+
+// UsesEnvironment returns true if a template references the environment
+func UsesEnvironment(template string) bool {
+	return environmentPattern.MatchString(template)
+}
+
+// ActiveEnvironment returns the active environment, nil if none is
+func (c *Config) ActiveEnvironment() *Environment {
+	env, ok := c.Environments[c.Environment]
+	if !ok {
+		return nil
+	}
+	return &env
+}
+
+// resolveEnvironment points every endpoint at the active environment
+func (c *Config) resolveEnvironment() {
+	env := c.ActiveEnvironment()
+	for i := range c.Endpoints {
+		c.Endpoints[i].ResolvedEnv = env
+	}
+}
+
+// ValidateEnvironments checks the environments, the active one, and that
+// endpoints only reference the environment when one is active
+func (c *Config) ValidateEnvironments() []string {
+	var errors []string
+
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			errors = append(errors, "environments: names must be non-empty")
+			continue
+		}
+		env := c.Environments[name]
+		if env.BaseURL == "" {
+			continue
+		}
+		parsedURL, err := url.Parse(env.BaseURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			errors = append(errors, fmt.Sprintf("environments: %s: base_url must be an absolute http or https URL", name))
+		}
+	}
+
+	if c.Environment != "" {
+		if _, ok := c.Environments[c.Environment]; !ok {
+			errors = append(errors, fmt.Sprintf("environment %s is not defined in environments", c.Environment))
+		}
+	} else {
+		for i := range c.Endpoints {
+			if UsesEnvironment(c.Endpoints[i].URLTemplate) {
+				errors = append(errors, fmt.Sprintf("endpoint %s: url_template references the environment, but no environment is active", c.Endpoints[i].Name))
+			}
+		}
+	}
+
+	return errors
+}
+
+// GetEnvironments returns the name of the active environment, "" if none,
+// and the defined environments
+func (m *Manager) GetEnvironments() (string, map[string]Environment) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	envs := make(map[string]Environment, len(m.config.Environments))
+	for name, env := range m.config.Environments {
+		envs[name] = env
+	}
+	return m.config.Environment, envs
+}
+
+// SetEnvironment switches the whole test to another environment. The
+// endpoints must stay within the guardrails in the new environment.
+func (m *Manager) SetEnvironment(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.config.Environments[name]; !ok {
+		return &NotFoundError{Kind: KindEnvironment, Name: name}
+	}
+
+	candidate := *m.config
+	candidate.Environment = name
+	if err := m.checkGuardrails(&candidate); err != nil {
+		return err
+	}
+
+	m.config.Environment = name
+	m.config.resolveEnvironment()
+	return nil
+}
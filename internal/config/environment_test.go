@@ -0,0 +1,64 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnvironmentSwitch(t *testing.T) {
+	m := NewManager()
+	m.config.Environments = map[string]Environment{
+		"staging": {BaseURL: "https://api.staging.example.com", Vars: map[string]string{"tenant": "acme"}},
+		"perf":    {BaseURL: "https://api.perf.example.com"},
+		"prod":    {BaseURL: "https://api.example.com"},
+	}
+	m.config.Environment = "staging"
+	m.config.Guardrails = GuardrailsConfig{DeniedHosts: []string{"api.example.com"}}
+	if err := m.AddEndpoint(Endpoint{Name: "orders", URLTemplate: "{{.BaseURL}}/orders?tenant={{.Vars.tenant}}", FrequencyPerMin: 60, Enabled: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ep := m.GetConfig().Endpoints[0]
+	if host := ep.GetHostname(); host != "api.staging.example.com" {
+		t.Errorf("expected the staging hostname, got %q", host)
+	}
+	rendered, err := EvaluateRequestTemplate(ep.URLTemplate, &RequestContext{Environment: ep.ResolvedEnv})
+	if err != nil || rendered != "https://api.staging.example.com/orders?tenant=acme" {
+		t.Errorf("unexpected rendered URL %q (%v)", rendered, err)
+	}
+
+	if err := m.SetEnvironment("perf"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ep = m.GetConfig().Endpoints[0]
+	if host := ep.GetHostname(); host != "api.perf.example.com" {
+		t.Errorf("expected the perf hostname after switching, got %q", host)
+	}
+
+	var guardrail *GuardrailError
+	if err := m.SetEnvironment("prod"); !errors.As(err, &guardrail) {
+		t.Errorf("expected a guardrail error switching to a denied host, got %v", err)
+	}
+	var notFound *NotFoundError
+	if err := m.SetEnvironment("qa"); !errors.As(err, &notFound) || notFound.Kind != KindEnvironment {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if active, _ := m.GetEnvironments(); active != "perf" {
+		t.Errorf("expected failed switches to keep perf, got %q", active)
+	}
+}
+
+func TestValidateEnvironments(t *testing.T) {
+	cfg := &Config{
+		Environments: map[string]Environment{"staging": {BaseURL: "staging.example.com"}},
+		Environment:  "perf",
+	}
+	if errs := cfg.ValidateEnvironments(); len(errs) != 2 {
+		t.Errorf("expected errors for the base URL and the undefined environment, got %v", errs)
+	}
+
+	cfg = &Config{Endpoints: []Endpoint{{Name: "orders", URLTemplate: "{{ .BaseURL }}/orders"}}}
+	if errs := cfg.ValidateEnvironments(); len(errs) != 1 {
+		t.Errorf("expected an error for an endpoint referencing no environment, got %v", errs)
+	}
+}
@@ -0,0 +1,71 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of configuration items named in errors
+const (
+	KindEndpoint      = "endpoint"
+	KindAuthConfig    = "auth config"
+	KindGroup         = "endpoint group"
+	KindIncomingRoute = "incoming route"
+	KindVersion       = "config version"
+	KindEnvironment   = "environment"
+)
+
+// NotFoundError reports that no configuration item of a kind has a name
+type NotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.Kind, e.Name)
+}
+
+// AlreadyExistsError reports that a configuration item of a kind already has
+// a name
+type AlreadyExistsError struct {
+	Kind string
+	Name string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return fmt.Sprintf("%s already exists: %s", e.Kind, e.Name)
+}
+
+// InUseError reports that a configuration item can't be deleted while an
+// endpoint refers to it
+type InUseError struct {
+	Kind     string
+	Name     string
+	Endpoint string
+}
+
+func (e *InUseError) Error() string {
+	return fmt.Sprintf("cannot delete %s %s: used by endpoint %s", e.Kind, e.Name, e.Endpoint)
+}
+
+// ValidationError lists the problems that make a configuration invalid, as
+// returned by the Validate methods
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed: " + strings.Join(e.Problems, "; ")
+}
+
+// GuardrailError reports that a change would violate the guardrails of the
+// config, such as its ceiling of the outgoing rate or its allowed hostnames.
+// Violations are prefixed with "guardrails:".
+type GuardrailError struct {
+	Violations []string
+}
+
+func (e *GuardrailError) Error() string {
+	return strings.Join(e.Violations, "; ")
+}
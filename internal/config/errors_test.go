@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestManagerErrorTypes(t *testing.T) {
+	m := NewManager()
+	if err := m.AddEndpoint(Endpoint{Name: "orders", URLTemplate: "https://example.com/orders", FrequencyPerMin: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var exists *AlreadyExistsError
+	err := m.AddEndpoint(Endpoint{Name: "orders", URLTemplate: "https://example.com/orders", FrequencyPerMin: 1})
+	if !errors.As(err, &exists) || exists.Kind != KindEndpoint || exists.Name != "orders" {
+		t.Errorf("expected AlreadyExistsError for orders, got %v", err)
+	}
+
+	var notFound *NotFoundError
+	_, err = m.GetEndpoint("missing")
+	if !errors.As(err, &notFound) || notFound.Kind != KindEndpoint {
+		t.Errorf("expected NotFoundError for endpoint, got %v", err)
+	}
+	if err.Error() != "endpoint not found: missing" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	if err := m.SetGroupBudget("missing", 1); !errors.As(err, &notFound) || notFound.Kind != KindGroup {
+		t.Errorf("expected NotFoundError for group, got %v", err)
+	}
+
+	var invalid *ValidationError
+	err = m.AddEndpoint(Endpoint{Name: "broken", FrequencyPerMin: 1})
+	if !errors.As(err, &invalid) || len(invalid.Problems) == 0 {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+
+	// Unknown auth references are reported as missing auth configs
+	err = m.AddEndpoint(Endpoint{Name: "authed", URLTemplate: "https://example.com", FrequencyPerMin: 1, Auth: "nope"})
+	if !errors.As(err, &notFound) || notFound.Kind != KindAuthConfig || notFound.Name != "nope" {
+		t.Errorf("expected NotFoundError for auth config, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusCodes is a list of HTTP status codes and ranges counted as success,
+// such as 200, "404", "4xx" or "500-503". YAML and JSON accept numbers and
+// strings.
+type StatusCodes []string
+
+// UnmarshalJSON accepts numbers as well as strings
+func (s *StatusCodes) UnmarshalJSON(data []byte) error {
+	var raw []interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	codes := make(StatusCodes, 0, len(raw))
+	for _, v := range raw {
+		switch val := v.(type) {
+		case string:
+			codes = append(codes, val)
+		case float64:
+			codes = append(codes, strconv.FormatFloat(val, 'f', -1, 64))
+		default:
+			return fmt.Errorf("invalid status code %v", v)
+		}
+	}
+	*s = codes
+	return nil
+}
+
+// Validate checks that every entry is a status code or range
+func (s StatusCodes) Validate() []string {
+	var errors []string
+	for _, code := range s {
+		if _, _, err := parseStatusRange(code); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+	return errors
+}
+
+// Matches reports whether a status code is in the list. An empty list
+// matches 2xx and 3xx responses.
+func (s StatusCodes) Matches(statusCode int) bool {
+	if len(s) == 0 {
+		return statusCode >= 200 && statusCode < 400
+	}
+	for _, code := range s {
+		if low, high, err := parseStatusRange(code); err == nil && statusCode >= low && statusCode <= high {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatusRange parses "404", "4xx" or "500-503" into an inclusive range
+func parseStatusRange(code string) (int, int, error) {
+	code = strings.TrimSpace(code)
+	invalid := fmt.Errorf("invalid status code %q (must be a code such as 404, a class such as 4xx or a range such as 500-503)", code)
+
+	if len(code) == 3 && strings.EqualFold(code[1:], "xx") {
+		class, err := strconv.Atoi(code[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, invalid
+		}
+		return class * 100, class*100 + 99, nil
+	}
+
+	lowText, highText, isRange := strings.Cut(code, "-")
+	low, err := strconv.Atoi(strings.TrimSpace(lowText))
+	if err != nil {
+		return 0, 0, invalid
+	}
+	high := low
+	if isRange {
+		if high, err = strconv.Atoi(strings.TrimSpace(highText)); err != nil {
+			return 0, 0, invalid
+		}
+	}
+	if low < 100 || high > 599 || low > high {
+		return 0, 0, invalid
+	}
+	return low, high, nil
+}
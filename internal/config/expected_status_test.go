@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestStatusCodes_Matches(t *testing.T) {
+	var defaults StatusCodes
+	if !defaults.Matches(200) || !defaults.Matches(302) || defaults.Matches(404) {
+		t.Error("expected an empty list to match 2xx and 3xx only")
+	}
+
+	codes := StatusCodes{"200", "4xx", "500-503"}
+	for _, code := range []int{200, 401, 404, 499, 500, 503} {
+		if !codes.Matches(code) {
+			t.Errorf("expected %d to match %v", code, codes)
+		}
+	}
+	for _, code := range []int{201, 302, 504} {
+		if codes.Matches(code) {
+			t.Errorf("expected %d not to match %v", code, codes)
+		}
+	}
+}
+
+func TestStatusCodes_Validate(t *testing.T) {
+	if errs := (StatusCodes{"404", "2XX", "500 - 599"}).Validate(); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs := (StatusCodes{"42", "6xx", "503-500", "ok"}).Validate(); len(errs) != 4 {
+		t.Errorf("expected 4 errors, got %v", errs)
+	}
+}
+
+func TestStatusCodes_Unmarshal(t *testing.T) {
+	var fromJSON struct {
+		ExpectedStatus StatusCodes `json:"expected_status"`
+	}
+	if err := json.Unmarshal([]byte(`{"expected_status": [404, "5xx"]}`), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if len(fromJSON.ExpectedStatus) != 2 || fromJSON.ExpectedStatus[0] != "404" || fromJSON.ExpectedStatus[1] != "5xx" {
+		t.Errorf("unexpected JSON codes: %v", fromJSON.ExpectedStatus)
+	}
+
+	var endpoint Endpoint
+	if err := yaml.Unmarshal([]byte("name: a\nexpected_status: [401, 4xx]\n"), &endpoint); err != nil {
+		t.Fatal(err)
+	}
+	if !endpoint.ExpectedStatus.Matches(401) || endpoint.ExpectedStatus.Matches(200) {
+		t.Errorf("unexpected YAML codes: %v", endpoint.ExpectedStatus)
+	}
+}
@@ -0,0 +1,198 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldError is a validation error attributed to the field it is about, so
+// editors can show it next to the input
+type FieldError struct {
+	Field   string `json:"field,omitempty"` // JSON path, e.g. stages[0].duration; empty if not about one field
+	Message string `json:"message"`
+}
+
+// ownerPrefix matches the "endpoint NAME: " style prefix of validation
+// messages, capturing the response index of incoming route responses
+var ownerPrefix = regexp.MustCompile(`^(?:endpoint|auth|incoming endpoint) .*?(?: response\[(\d+)\])?: `)
+
+// FieldErrors attributes validation messages to the JSON fields of v, the
+// request body they were reported for (e.g. an EndpointRequest). Messages name
+// fields by their JSON name, so the path is built from the first word that is
+// a field of v (or of one of its nested objects), followed by words naming
+// fields of that field's object. A number after a list of objects is a
+// 1-based position. Messages without a recognizable field keep an empty Field.
+func FieldErrors(v interface{}, messages []string) []FieldError {
+	root := reflect.TypeOf(v)
+	errors := make([]FieldError, 0, len(messages))
+	for _, msg := range messages {
+		errors = append(errors, FieldError{Field: fieldPath(root, msg), Message: msg})
+	}
+	return errors
+}
+
+// fieldPath finds the JSON path of the field a message is about
+func fieldPath(t reflect.Type, msg string) string {
+	var path []string
+	if m := ownerPrefix.FindStringSubmatch(msg); m != nil {
+		msg = msg[len(m[0]):]
+		if m[1] != "" {
+			if field, ok := jsonField(t, "responses"); ok && field.Type.Kind() == reflect.Slice {
+				path = append(path, "responses["+m[1]+"]")
+				t = field.Type.Elem()
+			}
+		}
+	}
+
+	words := strings.Fields(msg)
+	for i := range words {
+		words[i] = strings.Trim(words[i], `:;,'"()`)
+	}
+
+	// Find the first word naming a field, directly or one object down
+	start := -1
+	for i := range words {
+		if p, next, used := matchField(t, words, i); used > 0 {
+			path = append(path, p...)
+			t = next
+			start = i + used
+			break
+		}
+		if nested, next, used := matchNested(t, words, i); used > 0 {
+			path = append(path, nested...)
+			t = next
+			start = i + used
+			break
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+
+	// Follow the words naming fields of the matched field's object. After a
+	// list position, words are skipped until one names a field of the item.
+	search := false
+	for i := start; i < len(words); i++ {
+		if elem := derefType(t); elem != nil && elem.Kind() == reflect.Slice && derefType(elem.Elem()).Kind() == reflect.Struct {
+			n, err := strconv.Atoi(words[i])
+			if err != nil || n < 1 {
+				break
+			}
+			path[len(path)-1] += "[" + strconv.Itoa(n-1) + "]"
+			t = elem.Elem()
+			search = true
+			continue
+		}
+		p, next, used := matchField(t, words, i)
+		if used == 0 {
+			if search {
+				continue
+			}
+			break
+		}
+		path = append(path, p...)
+		t = next
+		i += used - 1
+		search = false
+	}
+	return strings.Join(path, ".")
+}
+
+// matchField matches the words at i against a field of t: a JSON name, a
+// dotted path of names, names split into words (status code) or a singular
+// (tag for tags). It returns the path, the field type and the words used.
+func matchField(t reflect.Type, words []string, i int) ([]string, reflect.Type, int) {
+	word := words[i]
+	if strings.Contains(word, ".") {
+		parts := strings.Split(word, ".")
+		field, ok := jsonField(t, parts[0])
+		if !ok {
+			return nil, nil, 0
+		}
+		path := []string{parts[0]}
+		next := field.Type
+		for j, part := range parts[1:] {
+			sub, ok := jsonField(next, part)
+			if !ok {
+				// Into a map or free-form value: keep the rest as is
+				path = append(path, parts[j+1:]...)
+				return path, nil, 1
+			}
+			path = append(path, part)
+			next = sub.Type
+		}
+		return path, next, 1
+	}
+	if i+1 < len(words) {
+		joined := word + "_" + words[i+1]
+		for _, name := range []string{joined, joined + "s"} {
+			if field, ok := jsonField(t, name); ok {
+				return []string{name}, field.Type, 2
+			}
+		}
+	}
+	for _, name := range []string{word, word + "s"} {
+		if field, ok := jsonField(t, name); ok {
+			return []string{name}, field.Type, 1
+		}
+	}
+	return nil, nil, 0
+}
+
+// matchNested matches the words at i against a field of one of t's nested
+// objects, for messages that leave out the object (body_schema for
+// validation.body_schema)
+func matchNested(t reflect.Type, words []string, i int) ([]string, reflect.Type, int) {
+	t = derefType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil, 0
+	}
+	for j := 0; j < t.NumField(); j++ {
+		name := jsonName(t.Field(j))
+		if name == "" || derefType(t.Field(j).Type).Kind() != reflect.Struct {
+			continue
+		}
+		if p, next, used := matchField(t.Field(j).Type, words, i); used > 0 {
+			return append([]string{name}, p...), next, used
+		}
+	}
+	return nil, nil, 0
+}
+
+// jsonField returns the field of a struct type with a JSON name
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	t = derefType(t)
+	if t == nil || t.Kind() != reflect.Struct || name == "" {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonName returns the JSON name of an exported struct field, "" if it isn't
+// serialized
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// derefType returns the type pointers point to
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,117 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Endpoint filter pattern prefixes
+const (
+	TagFilterPrefix   = "tag:" // tag:checkout matches endpoints tagged checkout
+	RegexFilterPrefix = "re:"  // re:^api_v2_.* matches names against a regular expression
+)
+
+// EndpointFilter selects endpoints by a list of patterns; an endpoint
+// matches if any pattern does
+type EndpointFilter struct {
+	patterns []filterPattern
+}
+
+// filterPattern is one parsed filter pattern; exactly one field is set
+type filterPattern struct {
+	tag       string
+	regex     *regexp.Regexp
+	glob      string // Lower-cased
+	substring string // Lower-cased
+}
+
+// ParseEndpointFilter parses comma-separated filter patterns:
+//   - tag:<tag> matches a tag (case-insensitive)
+//   - re:<regexp> matches the name against a regular expression
+//   - a pattern containing *, ? or [ is a glob matched against the whole name
+//     (case-insensitive)
+//   - anything else matches part of the name (case-insensitive)
+//
+// Since patterns are separated by commas, regular expressions can't contain
+// them. An empty filter matches every endpoint.
+func ParseEndpointFilter(filter string) (*EndpointFilter, error) {
+	f := &EndpointFilter{}
+	for _, pattern := range strings.Split(filter, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if tag, ok := strings.CutPrefix(pattern, TagFilterPrefix); ok {
+			f.patterns = append(f.patterns, filterPattern{tag: tag})
+			continue
+		}
+		if expr, ok := strings.CutPrefix(pattern, RegexFilterPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter regex %q: %w", expr, err)
+			}
+			f.patterns = append(f.patterns, filterPattern{regex: re})
+			continue
+		}
+		if strings.ContainsAny(pattern, "*?[") {
+			glob := strings.ToLower(pattern)
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("invalid filter glob %q: %w", pattern, err)
+			}
+			f.patterns = append(f.patterns, filterPattern{glob: glob})
+			continue
+		}
+		f.patterns = append(f.patterns, filterPattern{substring: strings.ToLower(pattern)})
+	}
+	return f, nil
+}
+
+// IsEmpty reports whether the filter has no patterns and so matches everything
+func (f *EndpointFilter) IsEmpty() bool {
+	return len(f.patterns) == 0
+}
+
+// Matches reports whether an endpoint matches the filter
+func (f *EndpointFilter) Matches(e *Endpoint) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	name := strings.ToLower(e.Name)
+	for _, p := range f.patterns {
+		switch {
+		case p.tag != "":
+			if e.HasTag(p.tag) {
+				return true
+			}
+		case p.regex != nil:
+			if p.regex.MatchString(e.Name) {
+				return true
+			}
+		case p.glob != "":
+			if matched, _ := path.Match(p.glob, name); matched {
+				return true
+			}
+		default:
+			if strings.Contains(name, p.substring) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply returns copies of the endpoints matching the filter
+func (f *EndpointFilter) Apply(endpoints []Endpoint) []Endpoint {
+	filtered := []Endpoint{}
+	for i := range endpoints {
+		if f.Matches(&endpoints[i]) {
+			filtered = append(filtered, endpoints[i])
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestEndpointFilter(t *testing.T) {
+	endpoints := []Endpoint{
+		{Name: "checkout-cart"},
+		{Name: "checkout-pay", Tags: []string{"payments"}},
+		{Name: "api_v2_orders"},
+		{Name: "API_v1_orders"},
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"checkout-cart", "checkout-pay", "api_v2_orders", "API_v1_orders"}},
+		{"cart", []string{"checkout-cart"}},
+		{"Checkout-*", []string{"checkout-cart", "checkout-pay"}},
+		{"*orders", []string{"api_v2_orders", "API_v1_orders"}},
+		{"re:^api_v2_.*", []string{"api_v2_orders"}},
+		{"tag:PAYMENTS, re:^API", []string{"checkout-pay", "API_v1_orders"}},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseEndpointFilter(tt.filter)
+		if err != nil {
+			t.Fatalf("filter %q: unexpected error: %v", tt.filter, err)
+		}
+		var got []string
+		for _, ep := range f.Apply(endpoints) {
+			got = append(got, ep.Name)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("filter %q: got %v, want %v", tt.filter, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("filter %q: got %v, want %v", tt.filter, got, tt.want)
+				break
+			}
+		}
+	}
+
+	for _, invalid := range []string{"re:(", "checkout-[a"} {
+		if _, err := ParseEndpointFilter(invalid); err == nil {
+			t.Errorf("expected error for filter %q", invalid)
+		}
+	}
+}
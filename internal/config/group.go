@@ -0,0 +1,373 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EndpointGroup is a set of endpoints sharing a requests/min budget that is
+// split between members by weight
+type EndpointGroup struct {
+	Name        string  `mapstructure:"name" yaml:"name" json:"name"`
+	Budget      float64 `mapstructure:"budget" yaml:"budget" json:"budget"`                                 // Requests per minute shared by all enabled members, 0 = members use their own frequency
+	Multiplier  float64 `mapstructure:"multiplier" yaml:"multiplier,omitempty" json:"multiplier,omitempty"` // Scales the members' load on top of the global multiplier, 0 = 1
+	Description string  `mapstructure:"description" yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Session support: members share a cookie jar, seeded with Cookies
+	CookieJar bool         `mapstructure:"cookie_jar" yaml:"cookie_jar,omitempty" json:"cookie_jar,omitempty"`
+	Cookies   []SeedCookie `mapstructure:"cookies" yaml:"cookies,omitempty" json:"cookies,omitempty"`
+}
+
+// SeedCookie is a cookie placed in a group's jar before its first request to a host
+type SeedCookie struct {
+	Name   string `mapstructure:"name" yaml:"name" json:"name"`
+	Value  string `mapstructure:"value" yaml:"value" json:"value"`                        // Evaluated as a template
+	Domain string `mapstructure:"domain" yaml:"domain,omitempty" json:"domain,omitempty"` // Empty = every host the group requests
+	Path   string `mapstructure:"path" yaml:"path,omitempty" json:"path,omitempty"`       // Defaults to /
+}
+
+// GroupMember describes how a group's budget is distributed to one endpoint
+type GroupMember struct {
+	Name               string  `json:"name"`
+	Weight             float64 `json:"weight"`
+	Enabled            bool    `json:"enabled"`
+	SharePct           float64 `json:"share_pct"`
+	EffectiveFrequency float64 `json:"effective_frequency"`
+}
+
+// Validate checks if the group configuration is valid
+func (g *EndpointGroup) Validate() []string {
+	var errors []string
+
+	if g.Name == "" {
+		errors = append(errors, "group name is required")
+	}
+	if g.Budget < 0 {
+		errors = append(errors, fmt.Sprintf("group %s: budget must be non-negative", g.Name))
+	}
+	if g.Multiplier < 0 {
+		errors = append(errors, fmt.Sprintf("group %s: multiplier must be non-negative", g.Name))
+	}
+	for _, cookie := range g.Cookies {
+		if cookie.Name == "" {
+			errors = append(errors, fmt.Sprintf("group %s: cookie name is required", g.Name))
+		}
+	}
+	if len(g.Cookies) > 0 && !g.CookieJar {
+		errors = append(errors, fmt.Sprintf("group %s: cookies require cookie_jar to be enabled", g.Name))
+	}
+
+	return errors
+}
+
+// EffectiveMultiplier returns the group's multiplier (default 1)
+func (g *EndpointGroup) EffectiveMultiplier() float64 {
+	if g.Multiplier <= 0 {
+		return 1
+	}
+	return g.Multiplier
+}
+
+// memberWeight returns the endpoint's weight within its group (default 1)
+func memberWeight(ep *Endpoint) float64 {
+	if ep.Weight <= 0 {
+		return 1
+	}
+	return ep.Weight
+}
+
+// EffectiveFrequencies returns the requests/min of every endpoint before the
+// global multiplier. Endpoints in a group with a budget get their weighted
+// share of it, split among enabled members only; all others (including
+// members of groups without a budget) use their own frequency.
+func (c *Config) EffectiveFrequencies() map[string]float64 {
+	budgets := make(map[string]float64, len(c.EndpointGroups))
+	for _, group := range c.EndpointGroups {
+		if group.Budget > 0 {
+			budgets[group.Name] = group.Budget
+		}
+	}
+
+	totalWeights := make(map[string]float64)
+	for i := range c.Endpoints {
+		ep := &c.Endpoints[i]
+		if _, grouped := budgets[ep.Group]; grouped && ep.Enabled {
+			totalWeights[ep.Group] += memberWeight(ep)
+		}
+	}
+
+	freqs := make(map[string]float64, len(c.Endpoints))
+	for i := range c.Endpoints {
+		ep := &c.Endpoints[i]
+		budget, grouped := budgets[ep.Group]
+		switch {
+		case !grouped:
+			freqs[ep.Name] = ep.FrequencyPerMin
+		case ep.Enabled && totalWeights[ep.Group] > 0:
+			freqs[ep.Name] = budget * memberWeight(ep) / totalWeights[ep.Group]
+		default:
+			freqs[ep.Name] = 0
+		}
+	}
+	return freqs
+}
+
+// Multiplier returns the multiplier applied to an endpoint's frequency: the
+// global multiplier times the multiplier of its group
+func (c *Config) Multiplier(ep *Endpoint) float64 {
+	if ep.Group != "" {
+		for i := range c.EndpointGroups {
+			if c.EndpointGroups[i].Name == ep.Group {
+				return c.GlobalMultiplier * c.EndpointGroups[i].EffectiveMultiplier()
+			}
+		}
+	}
+	return c.GlobalMultiplier
+}
+
+// AdjustedFrequencies returns the requests/min of every endpoint after the
+// global and group multipliers
+func (c *Config) AdjustedFrequencies() map[string]float64 {
+	freqs := c.EffectiveFrequencies()
+	for i := range c.Endpoints {
+		ep := &c.Endpoints[i]
+		freqs[ep.Name] *= c.Multiplier(ep)
+	}
+	return freqs
+}
+
+// --- Endpoint Group CRUD Operations ---
+
+// GetEndpointGroups returns all endpoint groups
+func (m *Manager) GetEndpointGroups() []EndpointGroup {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	groups := make([]EndpointGroup, len(m.config.EndpointGroups))
+	copy(groups, m.config.EndpointGroups)
+	return groups
+}
+
+// GetEndpointGroup returns an endpoint group by name
+func (m *Manager) GetEndpointGroup(name string) (*EndpointGroup, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := range m.config.EndpointGroups {
+		if m.config.EndpointGroups[i].Name == name {
+			group := m.config.EndpointGroups[i]
+			return &group, nil
+		}
+	}
+	return nil, &NotFoundError{Kind: KindGroup, Name: name}
+}
+
+// GetGroupMembers returns the budget distribution across a group's endpoints
+func (m *Manager) GetGroupMembers(name string) []GroupMember {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	freqs := m.config.EffectiveFrequencies()
+	var budget float64
+	for _, group := range m.config.EndpointGroups {
+		if group.Name == name {
+			budget = group.Budget
+		}
+	}
+
+	members := []GroupMember{}
+	for i := range m.config.Endpoints {
+		ep := &m.config.Endpoints[i]
+		if ep.Group != name {
+			continue
+		}
+		member := GroupMember{
+			Name:               ep.Name,
+			Weight:             memberWeight(ep),
+			Enabled:            ep.Enabled,
+			EffectiveFrequency: freqs[ep.Name],
+		}
+		if budget > 0 {
+			member.SharePct = freqs[ep.Name] / budget * 100
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
+// AddEndpointGroup adds a new endpoint group
+func (m *Manager) AddEndpointGroup(group EndpointGroup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, g := range m.config.EndpointGroups {
+		if g.Name == group.Name {
+			return &AlreadyExistsError{Kind: KindGroup, Name: group.Name}
+		}
+	}
+
+	if errors := group.Validate(); len(errors) > 0 {
+		return &ValidationError{Problems: errors}
+	}
+
+	candidate := *m.config
+	candidate.EndpointGroups = append(m.config.EndpointGroups[:len(m.config.EndpointGroups):len(m.config.EndpointGroups)], group)
+	if err := m.checkGuardrails(&candidate); err != nil {
+		return err
+	}
+
+	m.config.EndpointGroups = candidate.EndpointGroups
+	return nil
+}
+
+// UpdateEndpointGroup updates an existing endpoint group. Renaming a group
+// moves its member endpoints to the new name.
+func (m *Manager) UpdateEndpointGroup(name string, group EndpointGroup) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.config.EndpointGroups {
+		if m.config.EndpointGroups[i].Name != name {
+			continue
+		}
+
+		if group.Name != name {
+			for j, g := range m.config.EndpointGroups {
+				if g.Name == group.Name && i != j {
+					return &AlreadyExistsError{Kind: KindGroup, Name: group.Name}
+				}
+			}
+		}
+
+		group.RestoreRedacted(&m.config.EndpointGroups[i])
+		if errors := group.Validate(); len(errors) > 0 {
+			return &ValidationError{Problems: errors}
+		}
+
+		candidate := *m.config
+		candidate.EndpointGroups = append([]EndpointGroup(nil), m.config.EndpointGroups...)
+		candidate.EndpointGroups[i] = group
+		if group.Name != name {
+			candidate.Endpoints = append([]Endpoint(nil), m.config.Endpoints...)
+			for j := range candidate.Endpoints {
+				if candidate.Endpoints[j].Group == name {
+					candidate.Endpoints[j].Group = group.Name
+				}
+			}
+		}
+		if err := m.checkGuardrails(&candidate); err != nil {
+			return err
+		}
+
+		m.config.EndpointGroups[i] = group
+		if group.Name != name {
+			for j := range m.config.Endpoints {
+				if m.config.Endpoints[j].Group == name {
+					m.config.Endpoints[j].Group = group.Name
+				}
+			}
+		}
+		return nil
+	}
+	return &NotFoundError{Kind: KindGroup, Name: name}
+}
+
+// SetGroupBudget updates the shared requests/min budget of a group
+func (m *Manager) SetGroupBudget(name string, budget float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if budget < 0 {
+		return &ValidationError{Problems: []string{"budget must be non-negative"}}
+	}
+
+	for i := range m.config.EndpointGroups {
+		if m.config.EndpointGroups[i].Name == name {
+			candidate := *m.config
+			candidate.EndpointGroups = append([]EndpointGroup(nil), m.config.EndpointGroups...)
+			candidate.EndpointGroups[i].Budget = budget
+			if err := m.checkGuardrails(&candidate); err != nil {
+				return err
+			}
+			m.config.EndpointGroups[i].Budget = budget
+			return nil
+		}
+	}
+	return &NotFoundError{Kind: KindGroup, Name: name}
+}
+
+// GetGroupMultipliers returns the multiplier of every endpoint group
+func (m *Manager) GetGroupMultipliers() map[string]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	multipliers := make(map[string]float64, len(m.config.EndpointGroups))
+	for i := range m.config.EndpointGroups {
+		multipliers[m.config.EndpointGroups[i].Name] = m.config.EndpointGroups[i].EffectiveMultiplier()
+	}
+	return multipliers
+}
+
+// SetGroupMultipliers updates the multipliers of endpoint groups by name.
+// Either all are set or, if a group is unknown, a multiplier invalid or the
+// result above the guardrails, none.
+func (m *Manager) SetGroupMultipliers(multipliers map[string]float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	index := make(map[string]int, len(m.config.EndpointGroups))
+	for i := range m.config.EndpointGroups {
+		index[m.config.EndpointGroups[i].Name] = i
+	}
+
+	names := make([]string, 0, len(multipliers))
+	for name := range multipliers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		if _, ok := index[name]; !ok {
+			return &NotFoundError{Kind: KindGroup, Name: name}
+		}
+		if multipliers[name] < 0 {
+			problems = append(problems, fmt.Sprintf("group %s: multiplier must be non-negative", name))
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	candidate := *m.config
+	candidate.EndpointGroups = append([]EndpointGroup(nil), m.config.EndpointGroups...)
+	for name, multiplier := range multipliers {
+		candidate.EndpointGroups[index[name]].Multiplier = multiplier
+	}
+	if err := m.checkGuardrails(&candidate); err != nil {
+		return err
+	}
+
+	m.config.EndpointGroups = candidate.EndpointGroups
+	return nil
+}
+
+// DeleteEndpointGroup removes an endpoint group by name
+func (m *Manager) DeleteEndpointGroup(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.config.EndpointGroups {
+		if m.config.EndpointGroups[i].Name == name {
+			for _, ep := range m.config.Endpoints {
+				if ep.Group == name {
+					return &InUseError{Kind: KindGroup, Name: name, Endpoint: ep.Name}
+				}
+			}
+			m.config.EndpointGroups = append(m.config.EndpointGroups[:i], m.config.EndpointGroups[i+1:]...)
+			return nil
+		}
+	}
+	return &NotFoundError{Kind: KindGroup, Name: name}
+}
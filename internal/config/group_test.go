@@ -0,0 +1,101 @@
+package config
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEffectiveFrequencies_GroupBudget(t *testing.T) {
+	cfg := &Config{
+		EndpointGroups: []EndpointGroup{{Name: "checkout", Budget: 500}},
+		Endpoints: []Endpoint{
+			{Name: "view_cart", Group: "checkout", Weight: 60, Enabled: true, FrequencyPerMin: 1},
+			{Name: "place_order", Group: "checkout", Weight: 30, Enabled: true},
+			{Name: "confirm_payment", Group: "checkout", Weight: 10, Enabled: true},
+			{Name: "health", FrequencyPerMin: 12, Enabled: true},
+		},
+	}
+
+	freqs := cfg.EffectiveFrequencies()
+	expected := map[string]float64{"view_cart": 300, "place_order": 150, "confirm_payment": 50, "health": 12}
+	for name, want := range expected {
+		if math.Abs(freqs[name]-want) > 1e-9 {
+			t.Errorf("expected %s at %v req/min, got %v", name, want, freqs[name])
+		}
+	}
+
+	// Disabled members give their share to the rest of the group
+	cfg.Endpoints[2].Enabled = false
+	freqs = cfg.EffectiveFrequencies()
+	if freqs["confirm_payment"] != 0 {
+		t.Errorf("expected disabled member to get no budget, got %v", freqs["confirm_payment"])
+	}
+	if math.Abs(freqs["view_cart"]+freqs["place_order"]-500) > 1e-9 {
+		t.Errorf("expected enabled members to share the full budget, got %v", freqs["view_cart"]+freqs["place_order"])
+	}
+}
+
+func TestSetGroupBudget(t *testing.T) {
+	m := NewManager()
+	if err := m.AddEndpointGroup(EndpointGroup{Name: "checkout", Budget: 100}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m.config.Endpoints = []Endpoint{{Name: "a", Group: "checkout", Enabled: true}}
+
+	if err := m.SetGroupBudget("checkout", 800); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.GetTotalBaseRequestsPerMin(); got != 800 {
+		t.Errorf("expected 800 req/min, got %v", got)
+	}
+	if err := m.SetGroupBudget("missing", 1); err == nil {
+		t.Error("expected error for unknown group")
+	}
+	if err := m.DeleteEndpointGroup("checkout"); err == nil {
+		t.Error("expected error deleting a group with members")
+	}
+}
+
+func TestGroupMultipliers(t *testing.T) {
+	m := NewManager()
+	m.config.GlobalMultiplier = 2
+	m.config.EndpointGroups = []EndpointGroup{{Name: "internal_apis"}, {Name: "third_party", Budget: 100}}
+	m.config.Endpoints = []Endpoint{
+		{Name: "users", Group: "internal_apis", FrequencyPerMin: 10, Enabled: true},
+		{Name: "payments", Group: "third_party", Enabled: true},
+		{Name: "health", FrequencyPerMin: 6, Enabled: true},
+	}
+
+	if err := m.SetGroupMultipliers(map[string]float64{"internal_apis": 2, "third_party": 0.1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	freqs := m.GetConfig().AdjustedFrequencies()
+	expected := map[string]float64{"users": 40, "payments": 20, "health": 12}
+	for name, want := range expected {
+		if math.Abs(freqs[name]-want) > 1e-9 {
+			t.Errorf("expected %s at %v req/min, got %v", name, want, freqs[name])
+		}
+	}
+	if got := m.GetAdjustedRequestsPerMin(); math.Abs(got-72) > 1e-9 {
+		t.Errorf("expected 72 req/min, got %v", got)
+	}
+
+	// Unknown groups leave every multiplier unchanged
+	if err := m.SetGroupMultipliers(map[string]float64{"internal_apis": 5, "missing": 1}); err == nil {
+		t.Error("expected error for unknown group")
+	}
+	if err := m.SetGroupMultipliers(map[string]float64{"third_party": -1}); err == nil {
+		t.Error("expected error for negative multiplier")
+	}
+	if got := m.GetGroupMultipliers(); got["internal_apis"] != 2 || got["third_party"] != 0.1 {
+		t.Errorf("expected multipliers to be unchanged, got %v", got)
+	}
+
+	// Zero resets a group to the global multiplier
+	if err := m.SetGroupMultipliers(map[string]float64{"internal_apis": 0}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.GetGroupMultipliers()["internal_apis"]; got != 1 {
+		t.Errorf("expected multiplier 1, got %v", got)
+	}
+}
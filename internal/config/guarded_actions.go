@@ -0,0 +1,61 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "time"
+
+// DefaultConfirmTTL is how long a confirmation token stays valid by default
+const DefaultConfirmTTL = 60 * time.Second
+
+// GuardedActionsConfig lists the API actions that take a second step to
+// confirm: the first request is answered with a token, and only a repeat
+// carrying the token applies the action
+type GuardedActionsConfig struct {
+	MaxMultiplier    float64 `mapstructure:"max_multiplier" yaml:"max_multiplier,omitempty" json:"max_multiplier,omitempty"`                // Raising a multiplier above this needs confirmation (0 = never)
+	MaxEnabledAtOnce int     `mapstructure:"max_enabled_at_once" yaml:"max_enabled_at_once,omitempty" json:"max_enabled_at_once,omitempty"` // Enabling more endpoints than this in one request needs confirmation (0 = never)
+	TokenTTL         int     `mapstructure:"token_ttl" yaml:"token_ttl,omitempty" json:"token_ttl,omitempty"`                               // Seconds a confirmation token stays valid (default 60)
+}
+
+// Validate checks if the guarded actions configuration is valid
+func (g *GuardedActionsConfig) Validate() []string {
+	var errors []string
+
+	if g.MaxMultiplier < 0 {
+		errors = append(errors, "guarded_actions: max_multiplier must be non-negative")
+	}
+	if g.MaxEnabledAtOnce < 0 {
+		errors = append(errors, "guarded_actions: max_enabled_at_once must be non-negative")
+	}
+	if g.TokenTTL < 0 {
+		errors = append(errors, "guarded_actions: token_ttl must be non-negative")
+	}
+
+	return errors
+}
+
+// GuardsMultiplier returns true if raising a multiplier from current to next
+// needs confirmation
+func (g *GuardedActionsConfig) GuardsMultiplier(current, next float64) bool {
+	return g.MaxMultiplier > 0 && next > g.MaxMultiplier && next > current
+}
+
+// GuardsEnabling returns true if enabling a number of endpoints in one
+// request needs confirmation
+func (g *GuardedActionsConfig) GuardsEnabling(count int) bool {
+	return g.MaxEnabledAtOnce > 0 && count > g.MaxEnabledAtOnce
+}
+
+// ConfirmTTL returns how long a confirmation token stays valid
+func (g *GuardedActionsConfig) ConfirmTTL() time.Duration {
+	if g.TokenTTL <= 0 {
+		return DefaultConfirmTTL
+	}
+	return time.Duration(g.TokenTTL) * time.Second
+}
+
+// GetGuardedActionsConfig returns the guarded actions configuration
+func (m *Manager) GetGuardedActionsConfig() GuardedActionsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.GuardedActions
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGuardedActions(t *testing.T) {
+	g := GuardedActionsConfig{MaxMultiplier: 5, MaxEnabledAtOnce: 100}
+
+	if !g.GuardsMultiplier(1, 6) {
+		t.Error("expected raising the multiplier above the maximum to be guarded")
+	}
+	if g.GuardsMultiplier(1, 5) || g.GuardsMultiplier(8, 6) {
+		t.Error("expected multipliers up to the maximum, and lowering one, not to be guarded")
+	}
+	if !g.GuardsEnabling(101) || g.GuardsEnabling(100) {
+		t.Error("expected only enabling more than 100 endpoints to be guarded")
+	}
+	if g.ConfirmTTL() != DefaultConfirmTTL {
+		t.Errorf("expected the default token TTL, got %v", g.ConfirmTTL())
+	}
+
+	// Nothing is guarded by default
+	var none GuardedActionsConfig
+	if none.GuardsMultiplier(1, 1000) || none.GuardsEnabling(1000) {
+		t.Error("expected no guarded actions without configuration")
+	}
+
+	g.TokenTTL = 10
+	if g.ConfirmTTL() != 10*time.Second {
+		t.Errorf("expected a 10s token TTL, got %v", g.ConfirmTTL())
+	}
+	g.MaxEnabledAtOnce = -1
+	if len(g.Validate()) == 0 {
+		t.Error("expected a negative max_enabled_at_once to be invalid")
+	}
+}
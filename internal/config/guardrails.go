@@ -0,0 +1,155 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// GuardrailsConfig keeps the load generator from being pointed at targets it
+// must not load, such as production: a ceiling of the outgoing rate and the
+// hostnames endpoints may target. Guardrails only come from the config file;
+// the API can't change them.
+type GuardrailsConfig struct {
+	MaxRequestsPerMin float64  `mapstructure:"max_requests_per_min" yaml:"max_requests_per_min,omitempty" json:"max_requests_per_min,omitempty"` // Ceiling of the adjusted requests/min (0 = none)
+	AllowedHosts      []string `mapstructure:"allowed_hosts" yaml:"allowed_hosts,omitempty" json:"allowed_hosts,omitempty"`                      // Only these hostnames may be targeted (empty = any)
+	DeniedHosts       []string `mapstructure:"denied_hosts" yaml:"denied_hosts,omitempty" json:"denied_hosts,omitempty"`                         // These hostnames may never be targeted, even if allowed
+}
+
+// Validate checks if the guardrails configuration is valid
+func (g *GuardrailsConfig) Validate() []string {
+	var errors []string
+
+	if g.MaxRequestsPerMin < 0 {
+		errors = append(errors, "guardrails: max_requests_per_min must be non-negative")
+	}
+	for _, host := range append(append([]string(nil), g.AllowedHosts...), g.DeniedHosts...) {
+		if strings.TrimSpace(strings.TrimPrefix(host, "*.")) == "" {
+			errors = append(errors, "guardrails: hostnames must be non-empty")
+		}
+	}
+
+	return errors
+}
+
+// HostAllowed returns true if endpoints may target a hostname. Hostnames are
+// matched case-insensitively; *.example.com matches every subdomain of
+// example.com. With an allowlist, an unknown (empty) hostname is not allowed.
+func (g *GuardrailsConfig) HostAllowed(hostname string) bool {
+	for _, pattern := range g.DeniedHosts {
+		if matchHost(pattern, hostname) {
+			return false
+		}
+	}
+	if len(g.AllowedHosts) == 0 {
+		return true
+	}
+	for _, pattern := range g.AllowedHosts {
+		if matchHost(pattern, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the guardrail violations of a config: an adjusted rate above
+// the ceiling, and endpoints targeting hostnames that are not allowed
+func (g *GuardrailsConfig) Check(cfg *Config) []string {
+	var violations []string
+
+	if g.MaxRequestsPerMin > 0 {
+		var total float64
+		for _, freq := range cfg.AdjustedFrequencies() {
+			total += freq
+		}
+		// Allow for rounding in the multipliers
+		if total > g.MaxRequestsPerMin*(1+1e-9) {
+			violations = append(violations, fmt.Sprintf("guardrails: %.1f requests/min exceed max_requests_per_min of %.1f",
+				total, g.MaxRequestsPerMin))
+		}
+	}
+
+	if len(g.AllowedHosts) > 0 || len(g.DeniedHosts) > 0 {
+		for i := range cfg.Endpoints {
+			ep := &cfg.Endpoints[i]
+			hostname := targetHostname(cfg, ep)
+			switch {
+			case g.HostAllowed(hostname):
+			case hostname == "":
+				violations = append(violations, fmt.Sprintf("guardrails: endpoint %s: hostname can't be determined to check it against allowed_hosts", ep.Name))
+			default:
+				violations = append(violations, fmt.Sprintf("guardrails: endpoint %s: hostname %s is not allowed", ep.Name, hostname))
+			}
+			// Pinned requests reach connect_to or the transport address whatever the URL says
+			if connectHost := ConnectHost(ep.ConnectTo); ep.ConnectTo != "" && !g.HostAllowed(connectHost) {
+				violations = append(violations, fmt.Sprintf("guardrails: endpoint %s: connect_to %s is not allowed", ep.Name, connectHost))
+			}
+			if ep.Transport != nil {
+				if targetHost := ep.Transport.TargetHost(); targetHost != "" && !g.HostAllowed(targetHost) {
+					violations = append(violations, fmt.Sprintf("guardrails: endpoint %s: transport address %s is not allowed", ep.Name, targetHost))
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// matchHost returns true if a hostname matches a guardrail pattern
+func matchHost(pattern, hostname string) bool {
+	if hostname == "" {
+		return false
+	}
+	pattern = strings.ToLower(pattern)
+	hostname = strings.ToLower(hostname)
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(hostname, "."+domain)
+	}
+	return pattern == hostname
+}
+
+// targetHostname returns the hostname an endpoint sends requests to in the
+// config's active environment, rendering its URL template if needed, or "" if
+// it can't be determined
+func targetHostname(cfg *Config, ep *Endpoint) string {
+	if !strings.Contains(ep.URLTemplate, "{{") {
+		return ep.GetHostname()
+	}
+	rendered, err := EvaluateRequestTemplate(ep.URLTemplate, &RequestContext{Environment: cfg.ActiveEnvironment()})
+	if err != nil {
+		return ""
+	}
+	parsedURL, err := url.Parse(rendered)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Hostname()
+}
+
+// checkGuardrails returns a GuardrailError if a config, usually the current
+// one with a pending change applied, violates the current guardrails. The
+// caller must hold the lock.
+func (m *Manager) checkGuardrails(cfg *Config) error {
+	if violations := m.config.Guardrails.Check(cfg); len(violations) > 0 {
+		return &GuardrailError{Violations: violations}
+	}
+	return nil
+}
+
+// CheckGuardrails returns a GuardrailError if a proposed config violates the
+// current guardrails
+func (m *Manager) CheckGuardrails(cfg *Config) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.checkGuardrails(cfg)
+}
+
+// GetGuardrails returns the guardrails configuration
+func (m *Manager) GetGuardrails() GuardrailsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.Guardrails
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGuardrailsHostAllowed(t *testing.T) {
+	g := GuardrailsConfig{
+		AllowedHosts: []string{"*.staging.example.com", "localhost"},
+		DeniedHosts:  []string{"db.staging.example.com"},
+	}
+
+	tests := map[string]bool{
+		"api.staging.example.com": true,
+		"API.Staging.Example.com": true,
+		"staging.example.com":     false, // Wildcards only match subdomains
+		"localhost":               true,
+		"db.staging.example.com":  false,
+		"api.example.com":         false,
+		"":                        false,
+	}
+	for host, want := range tests {
+		if got := g.HostAllowed(host); got != want {
+			t.Errorf("HostAllowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+
+	// Without an allowlist only denied hosts are refused
+	g.AllowedHosts = nil
+	if !g.HostAllowed("api.example.com") || g.HostAllowed("db.staging.example.com") {
+		t.Error("expected only denied hosts to be refused without an allowlist")
+	}
+}
+
+func TestGuardrailsEnforcedOnChanges(t *testing.T) {
+	m := NewManager()
+	m.config.Guardrails = GuardrailsConfig{MaxRequestsPerMin: 100, DeniedHosts: []string{"prod.example.com"}}
+	if err := m.AddEndpoint(Endpoint{Name: "a", URLTemplate: "http://staging.example.com/a", FrequencyPerMin: 60, Enabled: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var guardrailErr *GuardrailError
+	if err := m.AddEndpoint(Endpoint{Name: "b", URLTemplate: "http://prod.example.com/b", FrequencyPerMin: 1}); !errors.As(err, &guardrailErr) {
+		t.Errorf("expected a guardrail error for a denied host, got %v", err)
+	}
+	if err := m.AddEndpoint(Endpoint{Name: "c", URLTemplate: "http://staging.example.com/c", FrequencyPerMin: 50}); !errors.As(err, &guardrailErr) {
+		t.Errorf("expected a guardrail error above the ceiling, got %v", err)
+	}
+	if err := m.UpdateEndpoint("a", Endpoint{Name: "a", URLTemplate: "http://prod.example.com/a", FrequencyPerMin: 60}); !errors.As(err, &guardrailErr) {
+		t.Errorf("expected a guardrail error updating to a denied host, got %v", err)
+	}
+	if err := m.AddEndpoint(Endpoint{Name: "d", URLTemplate: "http://staging.example.com/d", ConnectTo: "prod.example.com:8080", FrequencyPerMin: 1}); !errors.As(err, &guardrailErr) {
+		t.Errorf("expected a guardrail error connecting to a denied host, got %v", err)
+	}
+	if err := m.SetGlobalMultiplier(2); !errors.As(err, &guardrailErr) {
+		t.Errorf("expected a guardrail error raising the multiplier, got %v", err)
+	}
+	if len(m.GetEndpoints()) != 1 || m.GetConfig().GlobalMultiplier != 1 {
+		t.Error("expected rejected changes to leave the config unchanged")
+	}
+	if err := m.SetGlobalMultiplier(1.5); err != nil {
+		t.Errorf("unexpected error at the ceiling: %v", err)
+	}
+
+	// Imports keep the current guardrails
+	replacement := &Config{Endpoints: []Endpoint{{Name: "a", URLTemplate: "http://prod.example.com/a", FrequencyPerMin: 1}}}
+	if err := m.ReplaceConfig(replacement); !errors.As(err, &guardrailErr) {
+		t.Errorf("expected a guardrail error replacing the config, got %v", err)
+	}
+}
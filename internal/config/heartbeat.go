@@ -0,0 +1,45 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "time"
+
+// HeartbeatConfig is a dead-man switch: when no heartbeat reaches
+// POST /api/outgoing/control/heartbeat within the timeout, the scheduler is
+// paused, so a forgotten load test doesn't keep loading a shared environment
+type HeartbeatConfig struct {
+	Timeout int `mapstructure:"timeout" yaml:"timeout,omitempty" json:"timeout,omitempty"` // Minutes without a heartbeat before pausing (0 = off)
+}
+
+// Enabled returns true if the scheduler pauses without heartbeats
+func (h *HeartbeatConfig) Enabled() bool {
+	return h.Timeout > 0
+}
+
+// TimeoutDuration returns how long the scheduler runs without a heartbeat
+func (h *HeartbeatConfig) TimeoutDuration() time.Duration {
+	return time.Duration(h.Timeout) * time.Minute
+}
+
+// Validate checks if the heartbeat configuration is valid
+func (h *HeartbeatConfig) Validate() []string {
+	if h.Timeout < 0 {
+		return []string{"heartbeat: timeout must be non-negative"}
+	}
+	return nil
+}
+
+// GetHeartbeatConfig returns the heartbeat configuration
+func (m *Manager) GetHeartbeatConfig() HeartbeatConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.Heartbeat
+}
+
+// SetHeartbeatTimeout updates the minutes without a heartbeat before the
+// scheduler is paused (0 turns the dead-man switch off)
+func (m *Manager) SetHeartbeatTimeout(minutes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config.Heartbeat.Timeout = minutes
+}
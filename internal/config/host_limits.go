@@ -0,0 +1,48 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HostLimitsConfig caps the outgoing requests in flight (queued for a worker
+// or running) per hostname, so one slow domain can't take every worker of the
+// shared pool and starve the endpoints of other domains
+type HostLimitsConfig struct {
+	MaxInFlight int            `mapstructure:"max_in_flight" yaml:"max_in_flight,omitempty" json:"max_in_flight,omitempty"` // Cap for every hostname (0 = unlimited)
+	Hosts       map[string]int `mapstructure:"hosts" yaml:"hosts,omitempty" json:"hosts,omitempty"`                         // Caps of specific hostnames, overriding max_in_flight
+}
+
+// Validate checks if the host limits configuration is valid
+func (h *HostLimitsConfig) Validate() []string {
+	var errors []string
+
+	if h.MaxInFlight < 0 {
+		errors = append(errors, "host_limits: max_in_flight must be non-negative")
+	}
+	for host, limit := range h.Hosts {
+		if strings.TrimSpace(host) == "" {
+			errors = append(errors, "host_limits: hostnames must be non-empty")
+		}
+		if limit < 0 {
+			errors = append(errors, fmt.Sprintf("host_limits: limit of %s must be non-negative", host))
+		}
+	}
+
+	return errors
+}
+
+// Limit returns the in-flight cap of a hostname, 0 if unlimited. Hostnames
+// are matched case-insensitively.
+func (h *HostLimitsConfig) Limit(hostname string) int {
+	if hostname == "" {
+		return 0
+	}
+	for host, limit := range h.Hosts {
+		if strings.EqualFold(host, hostname) {
+			return limit
+		}
+	}
+	return h.MaxInFlight
+}
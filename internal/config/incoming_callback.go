@@ -0,0 +1,94 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IncomingCallback is a webhook fired asynchronously after an incoming route
+// responds, simulating an async API calling back into the system under test.
+// URL, header values and body are templates that can read the incoming
+// request as .Request (e.g. {{.Request.Body.order_id}}).
+type IncomingCallback struct {
+	URL      string            `mapstructure:"url" yaml:"url" json:"url"`
+	Method   string            `mapstructure:"method" yaml:"method,omitempty" json:"method,omitempty"` // Defaults to POST
+	Headers  map[string]string `mapstructure:"headers" yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body     interface{}       `mapstructure:"body" yaml:"body,omitempty" json:"body,omitempty"` // Sent as JSON
+	DelayMs  int               `mapstructure:"delay_ms" yaml:"delay_ms,omitempty" json:"delay_ms,omitempty"`
+	Timeout  int               `mapstructure:"timeout" yaml:"timeout,omitempty" json:"timeout,omitempty"`       // Seconds, defaults to 10
+	OnStatus []int             `mapstructure:"on_status" yaml:"on_status,omitempty" json:"on_status,omitempty"` // Simulated statuses that trigger the callback (empty = all)
+}
+
+// DefaultCallbackTimeout is the callback request timeout in seconds when none is configured
+const DefaultCallbackTimeout = 10
+
+// IncomingRequestData is the incoming request exposed to callback templates as .Request
+type IncomingRequestData struct {
+	Route      string
+	Method     string
+	Path       string
+	PathSuffix string
+	Headers    map[string]string // First value of each header
+	Query      map[string]string // First value of each query parameter
+	Body       interface{}       // Decoded JSON, or the raw string
+	Status     int               // Simulated response status
+}
+
+// CallbackMethod returns the HTTP method of the callback request
+func (c *IncomingCallback) CallbackMethod() string {
+	if c.Method == "" {
+		return "POST"
+	}
+	return strings.ToUpper(c.Method)
+}
+
+// CallbackTimeout returns the callback request timeout in seconds
+func (c *IncomingCallback) CallbackTimeout() int {
+	if c.Timeout <= 0 {
+		return DefaultCallbackTimeout
+	}
+	return c.Timeout
+}
+
+// FiresOn reports whether a simulated response status triggers the callback
+func (c *IncomingCallback) FiresOn(status int) bool {
+	if len(c.OnStatus) == 0 {
+		return true
+	}
+	for _, s := range c.OnStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks if the callback configuration is valid
+func (c *IncomingCallback) Validate(routeName string) []string {
+	var errors []string
+
+	if c.URL == "" {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: callback url is required", routeName))
+	}
+
+	validMethods := map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true}
+	if !validMethods[c.CallbackMethod()] {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: invalid callback method %s", routeName, c.Method))
+	}
+
+	if c.DelayMs < 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: callback delay_ms must be non-negative", routeName))
+	}
+	if c.Timeout < 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: callback timeout must be non-negative", routeName))
+	}
+
+	for _, status := range c.OnStatus {
+		if status < 100 || status > 599 {
+			errors = append(errors, fmt.Sprintf("incoming endpoint %s: callback on_status %d must be between 100 and 599", routeName, status))
+		}
+	}
+
+	return errors
+}
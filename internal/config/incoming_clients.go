@@ -0,0 +1,58 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "fmt"
+
+// IncomingClientsConfig segments incoming route metrics by caller identity,
+// either the remote IP or the value of a header such as an API key, so the
+// traffic of several services hitting the simulator can be told apart.
+type IncomingClientsConfig struct {
+	Enabled    bool   `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Source     string `mapstructure:"source" yaml:"source,omitempty" json:"source,omitempty"`                // "ip" (default) or "header"
+	Header     string `mapstructure:"header" yaml:"header,omitempty" json:"header,omitempty"`                // Header identifying the caller when source is "header"
+	MaxClients int    `mapstructure:"max_clients" yaml:"max_clients,omitempty" json:"max_clients,omitempty"` // Further callers are grouped as "other"
+}
+
+// Incoming client identity sources
+const (
+	IncomingClientSourceIP     = "ip"
+	IncomingClientSourceHeader = "header"
+)
+
+// DefaultIncomingMaxClients bounds the number of callers tracked separately
+const DefaultIncomingMaxClients = 100
+
+// Validate checks if the incoming clients configuration is valid
+func (c *IncomingClientsConfig) Validate() []string {
+	var errors []string
+
+	switch c.Source {
+	case "", IncomingClientSourceIP:
+	case IncomingClientSourceHeader:
+		if c.Header == "" {
+			errors = append(errors, "incoming_clients: header is required when source is header")
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("incoming_clients: invalid source %s (must be one of: ip, header)", c.Source))
+	}
+	if c.MaxClients < 0 {
+		errors = append(errors, "incoming_clients: max_clients must be non-negative")
+	}
+
+	return errors
+}
+
+// GetIncomingClientsConfig returns the incoming clients configuration with defaults applied
+func (m *Manager) GetIncomingClientsConfig() IncomingClientsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clients := m.config.IncomingClients
+	if clients.Source == "" {
+		clients.Source = IncomingClientSourceIP
+	}
+	if clients.MaxClients <= 0 {
+		clients.MaxClients = DefaultIncomingMaxClients
+	}
+	return clients
+}
@@ -0,0 +1,30 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "fmt"
+
+// IncomingConcurrency mimics a backend with a worker pool on an incoming
+// route: at most MaxConcurrent requests are served at once, up to QueueSize
+// more wait for a free worker, and the rest are answered with 503.
+type IncomingConcurrency struct {
+	MaxConcurrent  int `mapstructure:"max_concurrent" yaml:"max_concurrent" json:"max_concurrent"`
+	QueueSize      int `mapstructure:"queue_size" yaml:"queue_size,omitempty" json:"queue_size,omitempty"`                   // 0 rejects requests as soon as all workers are busy
+	QueueTimeoutMs int `mapstructure:"queue_timeout_ms" yaml:"queue_timeout_ms,omitempty" json:"queue_timeout_ms,omitempty"` // 0 waits until a worker is free
+}
+
+// Validate checks if the concurrency configuration is valid
+func (c *IncomingConcurrency) Validate(routeName string) []string {
+	var errors []string
+
+	if c.MaxConcurrent <= 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: concurrency max_concurrent must be positive", routeName))
+	}
+	if c.QueueSize < 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: concurrency queue_size must be non-negative", routeName))
+	}
+	if c.QueueTimeoutMs < 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: concurrency queue_timeout_ms must be non-negative", routeName))
+	}
+
+	return errors
+}
@@ -11,22 +11,30 @@ import (
 
 // IncomingEndpoint represents an incoming route configuration for traffic simulation
 type IncomingEndpoint struct {
-	Name       string                   `mapstructure:"name" yaml:"name" json:"name"`
-	Path       string                   `mapstructure:"path" yaml:"path" json:"path"`
-	Method     string                   `mapstructure:"method" yaml:"method" json:"method"`
-	Responses  []IncomingResponseConfig `mapstructure:"responses" yaml:"responses" json:"responses"`
-	Enabled    bool                     `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
-	EnabledSet bool                     `mapstructure:"enabled" yaml:"-" json:"-"`
+	Name        string                   `mapstructure:"name" yaml:"name" json:"name"`
+	Path        string                   `mapstructure:"path" yaml:"path" json:"path"`
+	Method      string                   `mapstructure:"method" yaml:"method" json:"method"`
+	Responses   []IncomingResponseConfig `mapstructure:"responses" yaml:"responses" json:"responses"`
+	Validation  *RequestValidation       `mapstructure:"validation" yaml:"validation,omitempty" json:"validation,omitempty"`
+	RateLimit   *IncomingRateLimit       `mapstructure:"rate_limit" yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	Callback    *IncomingCallback        `mapstructure:"callback" yaml:"callback,omitempty" json:"callback,omitempty"`
+	Concurrency *IncomingConcurrency     `mapstructure:"concurrency" yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	Enabled     bool                     `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	EnabledSet  bool                     `mapstructure:"enabled" yaml:"-" json:"-"`
 }
 
 // UnmarshalYAML implements custom YAML parsing to detect explicit enabled field
 func (e *IncomingEndpoint) UnmarshalYAML(value *yaml.Node) error {
 	var raw struct {
-		Name      string                   `yaml:"name"`
-		Path      string                   `yaml:"path"`
-		Method    string                   `yaml:"method"`
-		Responses []IncomingResponseConfig `yaml:"responses"`
-		Enabled   *bool                    `yaml:"enabled"`
+		Name        string                   `yaml:"name"`
+		Path        string                   `yaml:"path"`
+		Method      string                   `yaml:"method"`
+		Responses   []IncomingResponseConfig `yaml:"responses"`
+		Validation  *RequestValidation       `yaml:"validation"`
+		RateLimit   *IncomingRateLimit       `yaml:"rate_limit"`
+		Callback    *IncomingCallback        `yaml:"callback"`
+		Concurrency *IncomingConcurrency     `yaml:"concurrency"`
+		Enabled     *bool                    `yaml:"enabled"`
 	}
 
 	if err := value.Decode(&raw); err != nil {
@@ -37,6 +45,10 @@ func (e *IncomingEndpoint) UnmarshalYAML(value *yaml.Node) error {
 	e.Path = raw.Path
 	e.Method = raw.Method
 	e.Responses = raw.Responses
+	e.Validation = raw.Validation
+	e.RateLimit = raw.RateLimit
+	e.Callback = raw.Callback
+	e.Concurrency = raw.Concurrency
 	if raw.Enabled != nil {
 		e.Enabled = *raw.Enabled
 		e.EnabledSet = true
@@ -93,6 +105,19 @@ func (e *IncomingEndpoint) Validate() []string {
 		errors = append(errors, fmt.Sprintf("incoming endpoint %s: response shares must sum to 1.0 (got %.3f)", e.Name, totalShare))
 	}
 
+	if e.Validation != nil {
+		errors = append(errors, e.Validation.Validate(e.Name)...)
+	}
+	if e.RateLimit != nil {
+		errors = append(errors, e.RateLimit.Validate(e.Name)...)
+	}
+	if e.Callback != nil {
+		errors = append(errors, e.Callback.Validate(e.Name)...)
+	}
+	if e.Concurrency != nil {
+		errors = append(errors, e.Concurrency.Validate(e.Name)...)
+	}
+
 	return errors
 }
 
@@ -130,25 +155,58 @@ func (e *IncomingEndpoint) Clone() IncomingEndpoint {
 		clone.Responses = make([]IncomingResponseConfig, len(e.Responses))
 		copy(clone.Responses, e.Responses)
 	}
+	if e.Validation != nil {
+		validation := *e.Validation
+		validation.RequiredHeaders = append([]string(nil), e.Validation.RequiredHeaders...)
+		validation.RequiredQuery = append([]string(nil), e.Validation.RequiredQuery...)
+		clone.Validation = &validation
+	}
+	if e.RateLimit != nil {
+		rateLimit := *e.RateLimit
+		clone.RateLimit = &rateLimit
+	}
+	if e.Callback != nil {
+		callback := *e.Callback
+		if e.Callback.Headers != nil {
+			callback.Headers = make(map[string]string, len(e.Callback.Headers))
+			for k, v := range e.Callback.Headers {
+				callback.Headers[k] = v
+			}
+		}
+		callback.OnStatus = append([]int(nil), e.Callback.OnStatus...)
+		clone.Callback = &callback
+	}
+	if e.Concurrency != nil {
+		concurrency := *e.Concurrency
+		clone.Concurrency = &concurrency
+	}
 	return clone
 }
 
 // IncomingEndpointRequest represents a request to create or update an incoming endpoint
 type IncomingEndpointRequest struct {
-	Name      string                   `json:"name"`
-	Path      string                   `json:"path"`
-	Method    string                   `json:"method"`
-	Responses []IncomingResponseConfig `json:"responses"`
-	Enabled   bool                     `json:"enabled"`
+	Name        string                   `json:"name"`
+	Path        string                   `json:"path"`
+	Method      string                   `json:"method"`
+	Responses   []IncomingResponseConfig `json:"responses"`
+	Validation  *RequestValidation       `json:"validation,omitempty"`
+	RateLimit   *IncomingRateLimit       `json:"rate_limit,omitempty"`
+	Callback    *IncomingCallback        `json:"callback,omitempty"`
+	Concurrency *IncomingConcurrency     `json:"concurrency,omitempty"`
+	Enabled     bool                     `json:"enabled"`
 }
 
 // ToIncomingEndpoint converts an IncomingEndpointRequest to an IncomingEndpoint
 func (r *IncomingEndpointRequest) ToIncomingEndpoint() IncomingEndpoint {
 	return IncomingEndpoint{
-		Name:      r.Name,
-		Path:      r.Path,
-		Method:    r.Method,
-		Responses: r.Responses,
-		Enabled:   r.Enabled,
+		Name:        r.Name,
+		Path:        r.Path,
+		Method:      r.Method,
+		Responses:   r.Responses,
+		Validation:  r.Validation,
+		RateLimit:   r.RateLimit,
+		Callback:    r.Callback,
+		Concurrency: r.Concurrency,
+		Enabled:     r.Enabled,
 	}
 }
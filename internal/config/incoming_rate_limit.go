@@ -0,0 +1,45 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "fmt"
+
+// IncomingRateLimit simulates a rate-limited API on an incoming route.
+// Requests beyond RequestsPerSecond (with Burst allowed at once) are answered
+// with 429 Too Many Requests and a Retry-After header.
+type IncomingRateLimit struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second" yaml:"requests_per_second" json:"requests_per_second"`
+	Burst             int     `mapstructure:"burst" yaml:"burst,omitempty" json:"burst,omitempty"`                                           // Defaults to ceil(requests_per_second)
+	RetryAfterSeconds int     `mapstructure:"retry_after_seconds" yaml:"retry_after_seconds,omitempty" json:"retry_after_seconds,omitempty"` // Fixed Retry-After; 0 = time until the next request is allowed
+}
+
+// BurstSize returns the number of requests allowed at once
+func (l *IncomingRateLimit) BurstSize() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	burst := int(l.RequestsPerSecond)
+	if float64(burst) < l.RequestsPerSecond {
+		burst++
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return burst
+}
+
+// Validate checks if the rate limit configuration is valid
+func (l *IncomingRateLimit) Validate(routeName string) []string {
+	var errors []string
+
+	if l.RequestsPerSecond <= 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: rate_limit requests_per_second must be positive", routeName))
+	}
+	if l.Burst < 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: rate_limit burst must be non-negative", routeName))
+	}
+	if l.RetryAfterSeconds < 0 {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: rate_limit retry_after_seconds must be non-negative", routeName))
+	}
+
+	return errors
+}
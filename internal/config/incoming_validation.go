@@ -0,0 +1,268 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RequestValidation describes the contract a request to an incoming route
+// must satisfy. Requests that violate it are rejected with Status instead of
+// a simulated response.
+type RequestValidation struct {
+	RequiredHeaders []string               `mapstructure:"required_headers" yaml:"required_headers,omitempty" json:"required_headers,omitempty"`
+	RequiredQuery   []string               `mapstructure:"required_query" yaml:"required_query,omitempty" json:"required_query,omitempty"`
+	BodySchema      map[string]interface{} `mapstructure:"body_schema" yaml:"body_schema,omitempty" json:"body_schema,omitempty"` // JSON Schema subset
+	Status          int                    `mapstructure:"status" yaml:"status,omitempty" json:"status,omitempty"`                // Defaults to 400
+}
+
+// DefaultValidationStatus is returned for invalid requests when no status is configured
+const DefaultValidationStatus = 400
+
+// schemaTypes are the JSON Schema types supported in body_schema
+var schemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// RejectStatus returns the status code for requests failing validation
+func (v *RequestValidation) RejectStatus() int {
+	if v.Status == 0 {
+		return DefaultValidationStatus
+	}
+	return v.Status
+}
+
+// Validate checks if the validation rules are valid
+func (v *RequestValidation) Validate(routeName string) []string {
+	var errors []string
+
+	if v.Status != 0 && (v.Status < 400 || v.Status > 499) {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: validation status must be a 4xx code", routeName))
+	}
+	for _, msg := range validateSchema(v.BodySchema, "body_schema") {
+		errors = append(errors, fmt.Sprintf("incoming endpoint %s: %s", routeName, msg))
+	}
+
+	return errors
+}
+
+// validateSchema checks that a schema only uses supported types
+func validateSchema(schema map[string]interface{}, path string) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var errors []string
+	if t, ok := schemaKeyword(schema, "type"); ok {
+		if name, isString := t.(string); !isString || !schemaTypes[name] {
+			errors = append(errors, fmt.Sprintf("%s: unsupported type %v", path, t))
+		}
+	}
+	if props, ok := schemaMap(schema, "properties"); ok {
+		for name, prop := range props {
+			propSchema, isMap := prop.(map[string]interface{})
+			if !isMap {
+				errors = append(errors, fmt.Sprintf("%s.properties.%s: must be an object", path, name))
+				continue
+			}
+			errors = append(errors, validateSchema(propSchema, path+".properties."+name)...)
+		}
+	}
+	if items, ok := schemaMap(schema, "items"); ok {
+		errors = append(errors, validateSchema(items, path+".items")...)
+	}
+	return errors
+}
+
+// Check returns the violations of a request against the rules (empty = valid)
+func (v *RequestValidation) Check(header http.Header, query url.Values, body []byte) []string {
+	var violations []string
+
+	for _, name := range v.RequiredHeaders {
+		if header.Get(name) == "" {
+			violations = append(violations, fmt.Sprintf("missing required header %s", name))
+		}
+	}
+	for _, name := range v.RequiredQuery {
+		if !query.Has(name) {
+			violations = append(violations, fmt.Sprintf("missing required query parameter %s", name))
+		}
+	}
+
+	if v.BodySchema != nil {
+		var doc interface{}
+		if len(body) == 0 {
+			violations = append(violations, "body is required")
+		} else if err := json.Unmarshal(body, &doc); err != nil {
+			violations = append(violations, fmt.Sprintf("body is not valid JSON: %v", err))
+		} else {
+			violations = append(violations, checkSchema(v.BodySchema, doc, "body")...)
+		}
+	}
+
+	return violations
+}
+
+// checkSchema validates a decoded JSON value against a JSON Schema subset:
+// type, properties, required, additionalProperties (bool), items, enum,
+// minLength/maxLength, minimum/maximum and minItems/maxItems
+func checkSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	var violations []string
+
+	if t, ok := schemaString(schema, "type"); ok && !matchesType(t, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, t, jsonType(value))}
+	}
+
+	if enum, ok := schemaList(schema, "enum"); ok {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("%s: value %v is not one of %v", path, value, enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schemaList(schema, "required"); ok {
+			for _, name := range required {
+				if _, present := v[fmt.Sprint(name)]; !present {
+					violations = append(violations, fmt.Sprintf("%s: missing required property %v", path, name))
+				}
+			}
+		}
+		props, _ := schemaMap(schema, "properties")
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propSchema, known := schemaMap(props, name)
+			if known {
+				violations = append(violations, checkSchema(propSchema, v[name], path+"."+name)...)
+			} else if additional, ok := schemaKeyword(schema, "additionalProperties"); ok && additional == false {
+				violations = append(violations, fmt.Sprintf("%s: unexpected property %s", path, name))
+			}
+		}
+
+	case []interface{}:
+		if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(v)) < min {
+			violations = append(violations, fmt.Sprintf("%s: expected at least %v items", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(v)) > max {
+			violations = append(violations, fmt.Sprintf("%s: expected at most %v items", path, max))
+		}
+		if items, ok := schemaMap(schema, "items"); ok {
+			for i, item := range v {
+				violations = append(violations, checkSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+	case string:
+		length := float64(len([]rune(v)))
+		if min, ok := schemaNumber(schema, "minLength"); ok && length < min {
+			violations = append(violations, fmt.Sprintf("%s: shorter than %v characters", path, min))
+		}
+		if max, ok := schemaNumber(schema, "maxLength"); ok && length > max {
+			violations = append(violations, fmt.Sprintf("%s: longer than %v characters", path, max))
+		}
+
+	case float64:
+		if min, ok := schemaNumber(schema, "minimum"); ok && v < min {
+			violations = append(violations, fmt.Sprintf("%s: %v is less than minimum %v", path, v, min))
+		}
+		if max, ok := schemaNumber(schema, "maximum"); ok && v > max {
+			violations = append(violations, fmt.Sprintf("%s: %v is greater than maximum %v", path, v, max))
+		}
+	}
+
+	return violations
+}
+
+// matchesType reports whether a decoded JSON value has the given schema type
+func matchesType(schemaType string, value interface{}) bool {
+	if schemaType == "integer" {
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	}
+	return jsonType(value) == schemaType
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return strings.ToLower(fmt.Sprintf("%T", value))
+	}
+}
+
+// schemaKeyword looks up a schema keyword or property name. Viper lowercases
+// map keys when loading YAML, so a case-insensitive match is accepted too.
+func schemaKeyword(schema map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := schema[key]; ok {
+		return value, true
+	}
+	for name, value := range schema {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// schemaMap reads an object-valued schema keyword
+func schemaMap(schema map[string]interface{}, key string) (map[string]interface{}, bool) {
+	value, _ := schemaKeyword(schema, key)
+	m, ok := value.(map[string]interface{})
+	return m, ok
+}
+
+// schemaList reads an array-valued schema keyword
+func schemaList(schema map[string]interface{}, key string) ([]interface{}, bool) {
+	value, _ := schemaKeyword(schema, key)
+	list, ok := value.([]interface{})
+	return list, ok
+}
+
+// schemaString reads a string-valued schema keyword
+func schemaString(schema map[string]interface{}, key string) (string, bool) {
+	value, _ := schemaKeyword(schema, key)
+	s, ok := value.(string)
+	return s, ok
+}
+
+// schemaNumber reads a numeric schema keyword (YAML may decode it as int)
+func schemaNumber(schema map[string]interface{}, key string) (float64, bool) {
+	value, _ := schemaKeyword(schema, key)
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRequestValidation_Check(t *testing.T) {
+	// Keys lowercased the way Viper loads them from YAML
+	validation := &RequestValidation{
+		RequiredHeaders: []string{"X-Request-ID"},
+		RequiredQuery:   []string{"tenant"},
+		BodySchema: map[string]interface{}{
+			"type":                 "object",
+			"required":             []interface{}{"title", "priority"},
+			"additionalproperties": false,
+			"properties": map[string]interface{}{
+				"title":    map[string]interface{}{"type": "string", "minlength": 1},
+				"priority": map[string]interface{}{"type": "string", "enum": []interface{}{"low", "high"}},
+				"count":    map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+		},
+	}
+
+	header := http.Header{}
+	header.Set("X-Request-ID", "abc")
+	query := url.Values{"tenant": {"acme"}}
+
+	if violations := validation.Check(header, query, []byte(`{"title":"t","priority":"low","count":2}`)); len(violations) != 0 {
+		t.Errorf("expected valid request, got %v", violations)
+	}
+
+	violations := validation.Check(http.Header{}, url.Values{}, []byte(`{"title":"","priority":"urgent","count":1.5,"extra":true}`))
+	if len(violations) != 6 {
+		t.Errorf("expected 6 violations, got %d: %v", len(violations), violations)
+	}
+
+	if violations := validation.Check(header, query, []byte(`not json`)); len(violations) != 1 {
+		t.Errorf("expected invalid JSON violation, got %v", violations)
+	}
+	if violations := validation.Check(header, query, nil); len(violations) != 1 || violations[0] != "body is required" {
+		t.Errorf("expected missing body violation, got %v", violations)
+	}
+}
+
+func TestRequestValidation_Validate(t *testing.T) {
+	validation := &RequestValidation{
+		Status:     503,
+		BodySchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "uuid"}}},
+	}
+
+	if errors := validation.Validate("orders"); len(errors) != 2 {
+		t.Errorf("expected 2 validation errors, got %d: %v", len(errors), errors)
+	}
+	if status := (&RequestValidation{}).RejectStatus(); status != DefaultValidationStatus {
+		t.Errorf("expected default status %d, got %d", DefaultValidationStatus, status)
+	}
+}
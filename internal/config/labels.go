@@ -0,0 +1,85 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// labelNamePattern matches label names that are valid Prometheus label names
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ReservedLabels are label names used by the series moxapp exports itself
+var ReservedLabels = []string{"endpoint", "route", "error_type", "domain", "hostname", "status", "quantile"}
+
+// ParseLabel parses a key=value label
+func ParseLabel(label string) (string, string, error) {
+	key, value, ok := strings.Cut(label, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid label %q (expected key=value)", label)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// ValidateLabels checks that label names are valid Prometheus label names
+// that don't clash with moxapp's own
+func ValidateLabels(labels map[string]string) []string {
+	var errors []string
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch {
+		case !labelNamePattern.MatchString(key) || strings.HasPrefix(key, "__"):
+			errors = append(errors, fmt.Sprintf("labels: invalid name %q (letters, digits and underscores, not starting with a digit or __)", key))
+		case isReservedLabel(key):
+			errors = append(errors, fmt.Sprintf("labels: %q is reserved (reserved: %s)", key, strings.Join(ReservedLabels, ", ")))
+		}
+	}
+	return errors
+}
+
+func isReservedLabel(key string) bool {
+	for _, reserved := range ReservedLabels {
+		if key == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// GetLabels returns a copy of the run labels, nil if there are none
+func (m *Manager) GetLabels() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.config.Labels) == 0 {
+		return nil
+	}
+	labels := make(map[string]string, len(m.config.Labels))
+	for k, v := range m.config.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// SetLabel sets a run label, overriding the config file's
+func (m *Manager) SetLabel(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Copy on write: GetConfig hands out the map
+	labels := make(map[string]string, len(m.config.Labels)+1)
+	for k, v := range m.config.Labels {
+		labels[k] = v
+	}
+	labels[key] = value
+	m.config.Labels = labels
+}
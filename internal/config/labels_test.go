@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLabel(t *testing.T) {
+	key, value, err := ParseLabel("env = staging=2")
+	if err != nil || key != "env" || value != "staging=2" {
+		t.Errorf("unexpected label %q=%q (%v)", key, value, err)
+	}
+	for _, invalid := range []string{"env", "=staging", ""} {
+		if _, _, err := ParseLabel(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	if errs := ValidateLabels(map[string]string{"env": "staging", "git_branch": "main"}); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	errs := ValidateLabels(map[string]string{"1env": "", "__name": "", "endpoint": "x", "my-label": ""})
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+	if !strings.Contains(strings.Join(errs, "\n"), `"endpoint" is reserved`) {
+		t.Errorf("expected endpoint to be reserved, got %v", errs)
+	}
+}
@@ -0,0 +1,31 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+// MultipartField is one part of a multipart/form-data request body. Either
+// Value (a template) or File (a path read on every request) must be set.
+type MultipartField struct {
+	Name        string `mapstructure:"name" yaml:"name" json:"name"`
+	Value       string `mapstructure:"value" yaml:"value,omitempty" json:"value,omitempty"`
+	File        string `mapstructure:"file" yaml:"file,omitempty" json:"file,omitempty"`
+	Filename    string `mapstructure:"filename" yaml:"filename,omitempty" json:"filename,omitempty"`             // Defaults to the base name of File
+	ContentType string `mapstructure:"content_type" yaml:"content_type,omitempty" json:"content_type,omitempty"` // Defaults from the file extension
+}
+
+// Validate checks if the multipart field is valid
+func (f *MultipartField) Validate() []string {
+	var errors []string
+
+	if f.Name == "" {
+		errors = append(errors, "multipart field name is required")
+	}
+	if f.Value != "" && f.File != "" {
+		errors = append(errors, "multipart field "+f.Name+": value and file are mutually exclusive")
+	}
+
+	return errors
+}
+
+// HasRequestBody returns true if the endpoint sends a request body
+func (e *Endpoint) HasRequestBody() bool {
+	return e.Body != nil || e.BodyFile != "" || len(e.Multipart) > 0
+}
@@ -0,0 +1,90 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NotificationsConfig configures chat notifications about the lifecycle of
+// load test runs, posted to Slack and/or Microsoft Teams incoming webhooks
+type NotificationsConfig struct {
+	SlackWebhookURL string   `mapstructure:"slack_webhook_url" yaml:"slack_webhook_url,omitempty" json:"slack_webhook_url,omitempty"`
+	TeamsWebhookURL string   `mapstructure:"teams_webhook_url" yaml:"teams_webhook_url,omitempty" json:"teams_webhook_url,omitempty"`
+	Events          []string `mapstructure:"events" yaml:"events,omitempty" json:"events,omitempty"` // Events to post (default all)
+}
+
+// Notification events
+const (
+	NotifyRunStarted    = "run_started"
+	NotifyRunCompleted  = "run_completed"
+	NotifyAlertFiring   = "alert_firing"
+	NotifyAlertResolved = "alert_resolved"
+	NotifyEmergencyStop = "emergency_stop"
+)
+
+// NotificationEvents are the events notifications can be sent for
+var NotificationEvents = []string{
+	NotifyRunStarted, NotifyRunCompleted, NotifyAlertFiring, NotifyAlertResolved, NotifyEmergencyStop,
+}
+
+// Enabled returns true if notifications have somewhere to go
+func (n *NotificationsConfig) Enabled() bool {
+	return n.SlackWebhookURL != "" || n.TeamsWebhookURL != ""
+}
+
+// Wants reports whether an event is posted
+func (n *NotificationsConfig) Wants(event string) bool {
+	if !n.Enabled() {
+		return false
+	}
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, e := range n.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks if the notifications configuration is valid
+func (n *NotificationsConfig) Validate() []string {
+	var errors []string
+
+	for _, webhook := range []string{n.SlackWebhookURL, n.TeamsWebhookURL} {
+		if webhook != "" && !strings.HasPrefix(webhook, "http://") && !strings.HasPrefix(webhook, "https://") {
+			errors = append(errors, "notifications: webhook URLs must start with http:// or https://")
+		}
+	}
+	for _, event := range n.Events {
+		valid := false
+		for _, known := range NotificationEvents {
+			valid = valid || event == known
+		}
+		if !valid {
+			errors = append(errors, fmt.Sprintf("notifications: unknown event %q (must be one of: %s)", event, strings.Join(NotificationEvents, ", ")))
+		}
+	}
+
+	return errors
+}
+
+// Redacted returns a copy of the notifications config with webhook URLs
+// masked, as they embed a secret token
+func (n NotificationsConfig) Redacted() NotificationsConfig {
+	n.SlackWebhookURL = maskIfSet(n.SlackWebhookURL)
+	n.TeamsWebhookURL = maskIfSet(n.TeamsWebhookURL)
+	return n
+}
+
+// GetNotificationsConfig returns the notifications configuration
+func (m *Manager) GetNotificationsConfig() NotificationsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notifications := m.config.Notifications
+	notifications.Events = append([]string(nil), m.config.Notifications.Events...)
+	return notifications
+}
@@ -0,0 +1,142 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PauseWindow is a recurring daily time window during which an endpoint is not called
+type PauseWindow struct {
+	Start    string   `mapstructure:"start" yaml:"start" json:"start"`                              // HH:MM
+	End      string   `mapstructure:"end" yaml:"end" json:"end"`                                    // HH:MM, may be before start to span midnight
+	Days     []string `mapstructure:"days" yaml:"days,omitempty" json:"days,omitempty"`             // mon..sun, empty means every day
+	Timezone string   `mapstructure:"timezone" yaml:"timezone,omitempty" json:"timezone,omitempty"` // IANA name, empty means local time
+}
+
+// locationCache avoids re-reading tzdata on every scheduler tick
+var locationCache sync.Map
+
+// weekdays maps accepted day names to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Validate checks if the pause window is valid
+func (w *PauseWindow) Validate() []string {
+	var errors []string
+
+	if _, err := parseClock(w.Start); err != nil {
+		errors = append(errors, fmt.Sprintf("pause window start: %v", err))
+	}
+	if _, err := parseClock(w.End); err != nil {
+		errors = append(errors, fmt.Sprintf("pause window end: %v", err))
+	}
+	if w.Start == w.End && w.Start != "" {
+		errors = append(errors, "pause window start and end must differ")
+	}
+
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			errors = append(errors, fmt.Sprintf("pause window: invalid day %s (must be one of: mon, tue, wed, thu, fri, sat, sun)", day))
+		}
+	}
+
+	if w.Timezone != "" {
+		if _, err := loadLocation(w.Timezone); err != nil {
+			errors = append(errors, fmt.Sprintf("pause window: invalid timezone %s", w.Timezone))
+		}
+	}
+
+	return errors
+}
+
+// Contains returns true if t falls inside the window. For windows spanning
+// midnight, days refer to the day the window starts.
+func (w *PauseWindow) Contains(t time.Time) bool {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false
+	}
+
+	if w.Timezone != "" {
+		loc, err := loadLocation(w.Timezone)
+		if err != nil {
+			return false
+		}
+		t = t.In(loc)
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if start < end {
+		return minute >= start && minute < end && w.onDay(day)
+	}
+
+	// Window spans midnight: the evening part belongs to today, the morning part to yesterday
+	if minute >= start {
+		return w.onDay(day)
+	}
+	if minute < end {
+		return w.onDay((day + 6) % 7)
+	}
+	return false
+}
+
+// onDay returns true if the window applies on the given weekday
+func (w *PauseWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if wd, ok := weekdays[strings.ToLower(d)]; ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPausedAt returns true if any of the endpoint's pause windows contains t
+func (e *Endpoint) IsPausedAt(t time.Time) bool {
+	for i := range e.PauseWindows {
+		if e.PauseWindows[i].Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses an HH:MM string into minutes since midnight
+func parseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// loadLocation loads a time zone, caching the result
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
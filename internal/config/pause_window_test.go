@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseWindow_Contains(t *testing.T) {
+	window := PauseWindow{Start: "02:00", End: "03:00"}
+
+	if !window.Contains(time.Date(2024, 5, 6, 2, 30, 0, 0, time.Local)) {
+		t.Error("expected 02:30 to be inside 02:00-03:00")
+	}
+	if window.Contains(time.Date(2024, 5, 6, 3, 0, 0, 0, time.Local)) {
+		t.Error("expected window end to be exclusive")
+	}
+
+	// Monday 2024-05-06 23:00 through Tuesday 01:00, only starting on Mondays
+	overnight := PauseWindow{Start: "23:00", End: "01:00", Days: []string{"mon"}}
+
+	if !overnight.Contains(time.Date(2024, 5, 6, 23, 30, 0, 0, time.Local)) {
+		t.Error("expected Monday 23:30 to be inside overnight window")
+	}
+	if !overnight.Contains(time.Date(2024, 5, 7, 0, 30, 0, 0, time.Local)) {
+		t.Error("expected Tuesday 00:30 to be inside window started on Monday")
+	}
+	if overnight.Contains(time.Date(2024, 5, 7, 23, 30, 0, 0, time.Local)) {
+		t.Error("expected Tuesday 23:30 to be outside Monday-only window")
+	}
+}
+
+func TestPauseWindow_Validate(t *testing.T) {
+	window := PauseWindow{Start: "25:00", End: "03:00", Days: []string{"someday"}, Timezone: "Nowhere/Special"}
+
+	if errors := window.Validate(); len(errors) != 3 {
+		t.Errorf("expected 3 validation errors, got %d: %v", len(errors), errors)
+	}
+}
@@ -0,0 +1,75 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxPercentiles is the most latency percentiles that can be reported
+const MaxPercentiles = 10
+
+// ValidatePercentiles checks the latency percentiles to report: between 0
+// (exclusive) and 100, without duplicates
+func ValidatePercentiles(percentiles []float64) []string {
+	var errors []string
+
+	if len(percentiles) > MaxPercentiles {
+		errors = append(errors, fmt.Sprintf("percentiles: at most %d can be reported", MaxPercentiles))
+	}
+	seen := make(map[float64]bool, len(percentiles))
+	for _, p := range percentiles {
+		if p <= 0 || p > 100 {
+			errors = append(errors, fmt.Sprintf("percentiles: %g must be above 0 and at most 100", p))
+		}
+		if seen[p] {
+			errors = append(errors, fmt.Sprintf("percentiles: %g is listed twice", p))
+		}
+		seen[p] = true
+	}
+	return errors
+}
+
+// ParsePercentiles parses a comma-separated list of percentiles, such as
+// "50,90,99.9" or "p50,p99.9"
+func ParsePercentiles(list string) ([]float64, error) {
+	var percentiles []float64
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimPrefix(strings.TrimSpace(item), "p")
+		if item == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid percentile %q", item)
+		}
+		percentiles = append(percentiles, p)
+	}
+	if errs := ValidatePercentiles(percentiles); len(errs) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return percentiles, nil
+}
+
+// GetPercentiles returns a copy of the latency percentiles to report, nil
+// for the defaults
+func (m *Manager) GetPercentiles() []float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]float64(nil), m.config.Percentiles...)
+}
+
+// SetPercentiles sets the latency percentiles to report, nil for the defaults
+func (m *Manager) SetPercentiles(percentiles []float64) error {
+	if errs := ValidatePercentiles(percentiles); len(errs) > 0 {
+		return &ValidationError{Problems: errs}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config.Percentiles = append([]float64(nil), percentiles...)
+	return nil
+}
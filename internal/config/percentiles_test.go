@@ -0,0 +1,24 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePercentiles(t *testing.T) {
+	got, err := ParsePercentiles("50, p90,99.9")
+	if err != nil || !reflect.DeepEqual(got, []float64{50, 90, 99.9}) {
+		t.Errorf("ParsePercentiles = %v, %v", got, err)
+	}
+
+	for _, list := range []string{"0", "100.1", "p95,95", "fifty"} {
+		if _, err := ParsePercentiles(list); err == nil {
+			t.Errorf("expected an error for %q", list)
+		}
+	}
+
+	m := NewManager()
+	if err := m.SetPercentiles([]float64{-1}); err == nil || m.GetPercentiles() != nil {
+		t.Errorf("expected invalid percentiles to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Provenance identifies the config file revision a config was loaded from
+type Provenance struct {
+	Path      string    `json:"path"`
+	SHA256    string    `json:"sha256"`               // Checksum of the file contents
+	GitCommit string    `json:"git_commit,omitempty"` // HEAD of the repository containing the file, if any
+	GitDirty  bool      `json:"git_dirty,omitempty"`  // The file differs from the committed version
+	LoadedAt  time.Time `json:"loaded_at"`            // When the file was read
+	Version   int       `json:"config_version"`       // Config version the file was loaded as
+	Modified  bool      `json:"modified,omitempty"`   // The config was changed through the API since
+	GitError  string    `json:"git_error,omitempty"`  // Why git info is missing, when git failed in a repository
+}
+
+// gitTimeout bounds each git command
+const gitTimeout = 2 * time.Second
+
+// ReadProvenance computes the checksum of a config file and looks up the git
+// commit of the repository containing it. Git info is left empty when git
+// isn't installed or the file isn't in a repository.
+func ReadProvenance(path string) (*Provenance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+
+	p := &Provenance{
+		Path:     path,
+		SHA256:   hex.EncodeToString(sum[:]),
+		LoadedAt: time.Now(),
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		p.Path = abs
+	}
+
+	dir, file := filepath.Split(p.Path)
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		// Not a repository (or no git): nothing to report
+		return p, nil
+	}
+	p.GitCommit = commit
+
+	// Untracked files count as dirty: their contents aren't in the commit
+	status, err := git(dir, "status", "--porcelain", "--", file)
+	if err != nil {
+		p.GitError = err.Error()
+		return p, nil
+	}
+	p.GitDirty = status != ""
+	return p, nil
+}
+
+// git runs a git command in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetProvenance returns where the current config was loaded from, nil if it
+// wasn't loaded from a file
+func (m *Manager) GetProvenance() *Provenance {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.provenance == nil {
+		return nil
+	}
+	p := *m.provenance
+	p.Modified = m.lastVersion != p.Version
+	return &p
+}
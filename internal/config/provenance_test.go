@@ -0,0 +1,82 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadProvenance_Checksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	data := []byte("global_multiplier: 1\n")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ReadProvenance(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if p.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected checksum %s", p.SHA256)
+	}
+	if p.GitCommit != "" {
+		t.Errorf("expected no git commit outside a repository, got %s", p.GitCommit)
+	}
+}
+
+func TestReadProvenance_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "endpoints.yaml")
+	os.WriteFile(path, []byte("global_multiplier: 1\n"), 0644)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "endpoints.yaml"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "config"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	p, err := ReadProvenance(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.GitCommit) != 40 || p.GitDirty {
+		t.Errorf("expected a clean commit, got %q (dirty %v)", p.GitCommit, p.GitDirty)
+	}
+
+	os.WriteFile(path, []byte("global_multiplier: 2\n"), 0644)
+	if p, _ := ReadProvenance(path); !p.GitDirty {
+		t.Error("expected a modified file to be dirty")
+	}
+}
+
+func TestManager_ProvenanceModified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	os.WriteFile(path, []byte("global_multiplier: 1\n"), 0644)
+
+	m := NewManager()
+	if m.GetProvenance() != nil {
+		t.Error("expected no provenance before loading a file")
+	}
+	if err := m.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if p := m.GetProvenance(); p == nil || p.Modified {
+		t.Fatalf("expected an unmodified file config, got %+v", p)
+	}
+
+	m.ReplaceConfig(m.GetConfig())
+	if p := m.GetProvenance(); !p.Modified {
+		t.Error("expected the config to be marked modified after a replace")
+	}
+}
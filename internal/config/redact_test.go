@@ -0,0 +1,69 @@
+package config
+
+import "testing"
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := &Config{
+		AuthConfigs: map[string]*AuthConfig{
+			"oauth": {
+				Name:   "oauth",
+				Type:   AuthTypeBearer,
+				EnvVar: "OAUTH_TOKEN",
+				TokenEndpoint: &TokenEndpointConfig{
+					URL:         "https://auth.example.com/token",
+					PasswordEnv: "OAUTH_PASS",
+					Body:        map[string]interface{}{"client_id": "moxapp", "client_secret": "s3cr3t"},
+				},
+			},
+		},
+		Endpoints: []Endpoint{{
+			Name:        "orders",
+			URLTemplate: "https://api.example.com/orders?api_key=abc&page=1",
+			Headers:     map[string]string{"Authorization": "Bearer abc", "Accept": "application/json"},
+			Body:        map[string]interface{}{"user": map[string]interface{}{"name": "a", "password": "p"}},
+			Auth:        map[string]interface{}{"type": AuthTypeBearer, "env_var": "INLINE_TOKEN"},
+		}},
+		EndpointGroups: []EndpointGroup{{
+			Name:      "flow",
+			CookieJar: true,
+			Cookies:   []SeedCookie{{Name: "session_id", Value: "xyz"}, {Name: "locale", Value: "en"}},
+		}},
+	}
+
+	redacted := cfg.Redacted()
+
+	auth := redacted.AuthConfigs["oauth"]
+	if auth.EnvVar != RedactedValue || auth.TokenEndpoint.PasswordEnv != RedactedValue {
+		t.Errorf("expected credential env vars to be masked, got %+v", auth)
+	}
+	body := auth.TokenEndpoint.Body.(map[string]interface{})
+	if body["client_secret"] != RedactedValue || body["client_id"] != "moxapp" {
+		t.Errorf("unexpected token body: %v", body)
+	}
+
+	ep := redacted.Endpoints[0]
+	if ep.Headers["Authorization"] != RedactedValue || ep.Headers["Accept"] != "application/json" {
+		t.Errorf("unexpected headers: %v", ep.Headers)
+	}
+	if ep.URLTemplate != "https://api.example.com/orders?api_key=%5Bredacted%5D&page=1" {
+		t.Errorf("unexpected url: %s", ep.URLTemplate)
+	}
+	if user := ep.Body.(map[string]interface{})["user"].(map[string]interface{}); user["password"] != RedactedValue || user["name"] != "a" {
+		t.Errorf("expected nested password to be masked, got %v", user)
+	}
+	if ep.Auth.(map[string]interface{})["env_var"] != RedactedValue {
+		t.Errorf("expected inline auth env_var to be masked, got %v", ep.Auth)
+	}
+
+	cookies := redacted.EndpointGroups[0].Cookies
+	if cookies[0].Value != RedactedValue || cookies[1].Value != "en" {
+		t.Errorf("unexpected cookies: %v", cookies)
+	}
+
+	// The original config is untouched
+	if cfg.AuthConfigs["oauth"].EnvVar != "OAUTH_TOKEN" || cfg.Endpoints[0].Headers["Authorization"] != "Bearer abc" ||
+		cfg.AuthConfigs["oauth"].TokenEndpoint.Body.(map[string]interface{})["client_secret"] != "s3cr3t" ||
+		cfg.EndpointGroups[0].Cookies[0].Value != "xyz" {
+		t.Error("expected original config to be unchanged")
+	}
+}
@@ -0,0 +1,59 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "time"
+
+// DefaultResourceCheckInterval is how often resource usage is checked when
+// resource_limits.interval is not set
+const DefaultResourceCheckInterval = 5 * time.Second
+
+// ResourceLimitsConfig caps the generator's own resource usage. When the
+// process gets close to a limit, scheduling is throttled so that the
+// generator doesn't become the bottleneck of the measurements it takes.
+type ResourceLimitsConfig struct {
+	MaxCPUPercent float64 `mapstructure:"max_cpu_percent" yaml:"max_cpu_percent,omitempty" json:"max_cpu_percent,omitempty"` // Of all cores (0 = no limit)
+	MaxMemoryMB   float64 `mapstructure:"max_memory_mb" yaml:"max_memory_mb,omitempty" json:"max_memory_mb,omitempty"`       // Resident memory (0 = no limit)
+	MaxFDPercent  float64 `mapstructure:"max_fd_percent" yaml:"max_fd_percent,omitempty" json:"max_fd_percent,omitempty"`    // Open files in percent of the process limit (0 = no limit)
+	Interval      int     `mapstructure:"interval" yaml:"interval,omitempty" json:"interval,omitempty"`                      // Seconds between checks (default 5)
+}
+
+// Enabled returns true if any resource limit is set
+func (r *ResourceLimitsConfig) Enabled() bool {
+	return r.MaxCPUPercent > 0 || r.MaxMemoryMB > 0 || r.MaxFDPercent > 0
+}
+
+// CheckInterval returns how often resource usage is checked
+func (r *ResourceLimitsConfig) CheckInterval() time.Duration {
+	if r.Interval <= 0 {
+		return DefaultResourceCheckInterval
+	}
+	return time.Duration(r.Interval) * time.Second
+}
+
+// Validate checks if the resource limits configuration is valid
+func (r *ResourceLimitsConfig) Validate() []string {
+	var errors []string
+
+	if r.MaxCPUPercent < 0 || r.MaxCPUPercent > 100 {
+		errors = append(errors, "resource_limits: max_cpu_percent must be between 0 and 100")
+	}
+	if r.MaxMemoryMB < 0 {
+		errors = append(errors, "resource_limits: max_memory_mb must be non-negative")
+	}
+	if r.MaxFDPercent < 0 || r.MaxFDPercent > 100 {
+		errors = append(errors, "resource_limits: max_fd_percent must be between 0 and 100")
+	}
+	if r.Interval < 0 {
+		errors = append(errors, "resource_limits: interval must be non-negative")
+	}
+
+	return errors
+}
+
+// GetResourceLimitsConfig returns the resource limits configuration
+func (m *Manager) GetResourceLimitsConfig() ResourceLimitsConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.ResourceLimits
+}
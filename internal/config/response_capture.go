@@ -0,0 +1,55 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+// ResponseCaptureConfig configures sampling of outgoing response bodies for
+// debugging. Captured bodies are kept in a ring buffer and served by
+// GET /api/requests/{id}/body.
+type ResponseCaptureConfig struct {
+	SampleRate int  `mapstructure:"sample_rate" yaml:"sample_rate" json:"sample_rate"` // Capture 1 in N responses, 0 disables sampling
+	Failures   bool `mapstructure:"failures" yaml:"failures" json:"failures"`          // Capture every failing (4xx/5xx) response
+	MaxBodyKB  int  `mapstructure:"max_body_kb" yaml:"max_body_kb" json:"max_body_kb"` // Bodies are truncated to this size
+	BufferSize int  `mapstructure:"buffer_size" yaml:"buffer_size" json:"buffer_size"` // Number of captures kept
+}
+
+// Default response capture settings
+const (
+	DefaultCaptureMaxBodyKB  = 64
+	DefaultCaptureBufferSize = 100
+)
+
+// Enabled returns true if any responses are captured
+func (c *ResponseCaptureConfig) Enabled() bool {
+	return c.SampleRate > 0 || c.Failures
+}
+
+// Validate checks if the response capture configuration is valid
+func (c *ResponseCaptureConfig) Validate() []string {
+	var errors []string
+
+	if c.SampleRate < 0 {
+		errors = append(errors, "response_capture: sample_rate must be non-negative")
+	}
+	if c.MaxBodyKB < 0 {
+		errors = append(errors, "response_capture: max_body_kb must be non-negative")
+	}
+	if c.BufferSize < 0 {
+		errors = append(errors, "response_capture: buffer_size must be non-negative")
+	}
+
+	return errors
+}
+
+// GetResponseCaptureConfig returns the response capture configuration with defaults applied
+func (m *Manager) GetResponseCaptureConfig() ResponseCaptureConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	capture := m.config.ResponseCapture
+	if capture.MaxBodyKB <= 0 {
+		capture.MaxBodyKB = DefaultCaptureMaxBodyKB
+	}
+	if capture.BufferSize <= 0 {
+		capture.BufferSize = DefaultCaptureBufferSize
+	}
+	return capture
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ScaffoldAuthTypes are the auth types a scaffolded config can use
+var ScaffoldAuthTypes = []string{"none", "api_key", "bearer", "basic"}
+
+// ScaffoldOptions configures a starter config generated by Scaffold
+type ScaffoldOptions struct {
+	BaseURL        string  // Base URL of the system under test
+	Auth           string  // One of ScaffoldAuthTypes
+	Frequency      float64 // Requests per minute of each endpoint
+	APIPort        int
+	IncomingRoutes bool // Include example incoming routes
+}
+
+// DefaultScaffoldOptions returns the options used for values not given
+func DefaultScaffoldOptions() ScaffoldOptions {
+	return ScaffoldOptions{
+		BaseURL:        "https://api.example.com",
+		Auth:           "none",
+		Frequency:      10,
+		APIPort:        8080,
+		IncomingRoutes: true,
+	}
+}
+
+// Validate checks scaffold options
+func (o ScaffoldOptions) Validate() []string {
+	var errors []string
+
+	u, err := url.Parse(o.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errors = append(errors, fmt.Sprintf("base URL %q must be an http:// or https:// URL", o.BaseURL))
+	}
+	valid := false
+	for _, auth := range ScaffoldAuthTypes {
+		valid = valid || o.Auth == auth
+	}
+	if !valid {
+		errors = append(errors, fmt.Sprintf("auth must be one of %s", strings.Join(ScaffoldAuthTypes, ", ")))
+	}
+	if o.Frequency <= 0 {
+		errors = append(errors, "frequency must be positive")
+	}
+	if o.APIPort < 1 || o.APIPort > 65535 {
+		errors = append(errors, "API port must be between 1 and 65535")
+	}
+
+	return errors
+}
+
+// Scaffold generates a commented starter config: an auth config if asked
+// for, a GET, a search and a POST endpoint against the base URL, and
+// optionally two incoming routes
+func Scaffold(opts ScaffoldOptions) ([]byte, error) {
+	if errors := opts.Validate(); len(errors) > 0 {
+		return nil, fmt.Errorf("invalid options: %s", strings.Join(errors, "; "))
+	}
+	base := strings.TrimSuffix(opts.BaseURL, "/")
+	auth := "none"
+	if opts.Auth != "none" {
+		auth = opts.Auth
+	}
+
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	line("# MoxApp configuration generated by `moxapp init`.")
+	line("# See configs/endpoints.example.yaml in the repository for all options.")
+	line("")
+	line("global_multiplier: 1.0")
+	line("concurrent_requests: 20")
+	line("log_all_requests: false")
+	line("api_port: %d", opts.APIPort)
+	line("")
+
+	if opts.Auth != "none" {
+		line("# Auth configs are referenced by name in the endpoints' auth field.")
+		line("# Secrets are read from environment variables.")
+		line("auth_configs:")
+		line("  %s:", auth)
+		line("    name: %s", auth)
+		switch opts.Auth {
+		case "api_key":
+			line("    type: api_key")
+			line("    header_name: \"x-api-key\"")
+			line("    env_var: \"MOXAPP_API_KEY\"")
+			line("    description: \"API key passed via header\"")
+		case "bearer":
+			line("    type: bearer")
+			line("    env_var: \"MOXAPP_BEARER_TOKEN\"")
+			line("    description: \"Static bearer token from env\"")
+		case "basic":
+			line("    type: basic")
+			line("    username_env: \"MOXAPP_BASIC_USER\"")
+			line("    password_env: \"MOXAPP_BASIC_PASS\"")
+			line("    description: \"HTTP basic auth\"")
+		}
+		line("")
+	}
+
+	line("# Frequencies are requests per minute; scale them all with -m/--multiplier.")
+	line("outgoing_endpoints:")
+	line("  # Simple GET endpoint")
+	line("  - name: health_check")
+	line("    method: GET")
+	line("    url_template: %s", strconv.Quote(base+"/health"))
+	line("    frequency: %s", formatFrequency(opts.Frequency))
+	line("    auth: none")
+	line("    timeout: 10")
+	line("")
+	line("  # GET endpoint with query params and template functions")
+	line("  - name: search_items")
+	line("    method: GET")
+	line("    url_template: %s", strconv.Quote(base+"/items/search?q={{ urlEncode (randomString 6) }}&limit=10"))
+	line("    frequency: %s", formatFrequency(opts.Frequency))
+	line("    auth: %s", auth)
+	line("    timeout: 15")
+	line("")
+	line("  # POST endpoint with a JSON body")
+	line("  - name: create_item")
+	line("    method: POST")
+	line("    url_template: %s", strconv.Quote(base+"/items"))
+	line("    frequency: %s", formatFrequency(opts.Frequency/2))
+	line("    auth: %s", auth)
+	line("    timeout: 20")
+	line("    headers:")
+	line("      x-request-id: \"{{ requestID }}\"")
+	line("    body:")
+	line("      id: \"{{ randomUUID }}\"")
+	line("      name: \"item-{{ randomInt 1000 9999 }}\"")
+	line("      created_at: \"{{ now }}\"")
+
+	if opts.IncomingRoutes {
+		line("")
+		line("# Simulated routes, served under /sim (e.g. GET http://localhost:%d/sim/api/status)", opts.APIPort)
+		line("incoming_routes:")
+		line("  - name: status")
+		line("    path: /api/status")
+		line("    method: GET")
+		line("    enabled: true")
+		line("    responses:")
+		line("      - status: 200")
+		line("        share: 0.95")
+		line("        min_response_ms: 20")
+		line("        max_response_ms: 60")
+		line("      - status: 503")
+		line("        share: 0.05")
+		line("        min_response_ms: 100")
+		line("        max_response_ms: 300")
+		line("")
+		line("  - name: create_order")
+		line("    path: /api/orders")
+		line("    method: POST")
+		line("    enabled: true")
+		line("    responses:")
+		line("      - status: 201")
+		line("        share: 0.9")
+		line("        min_response_ms: 100")
+		line("        max_response_ms: 400")
+		line("      - status: 500")
+		line("        share: 0.1")
+		line("        min_response_ms: 200")
+		line("        max_response_ms: 800")
+	}
+
+	return []byte(b.String()), nil
+}
+
+// formatFrequency formats a frequency without trailing zeros
+func formatFrequency(freq float64) string {
+	return strconv.FormatFloat(freq, 'f', -1, 64)
+}
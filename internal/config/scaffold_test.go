@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	for _, auth := range ScaffoldAuthTypes {
+		opts := DefaultScaffoldOptions()
+		opts.Auth = auth
+		opts.BaseURL = "http://localhost:9000/"
+		data, err := Scaffold(opts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", auth, err)
+		}
+
+		path := filepath.Join(t.TempDir(), "endpoints.yaml")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		manager := NewManager()
+		if err := manager.LoadFromFile(path); err != nil {
+			t.Fatalf("%s: generated config fails to load: %v\n%s", auth, err, data)
+		}
+		if errors := manager.Validate(); len(errors) > 0 {
+			t.Fatalf("%s: generated config is invalid: %v\n%s", auth, errors, data)
+		}
+		if got := len(manager.GetEndpoints()); got != 3 {
+			t.Errorf("%s: expected 3 endpoints, got %d", auth, got)
+		}
+		if got := len(manager.GetIncomingRoutes()); got != 2 {
+			t.Errorf("%s: expected 2 incoming routes, got %d", auth, got)
+		}
+		ep, err := manager.GetEndpoint("health_check")
+		if err != nil || ep.URLTemplate != "http://localhost:9000/health" {
+			t.Errorf("%s: unexpected health_check endpoint %+v (%v)", auth, ep, err)
+		}
+	}
+
+	opts := DefaultScaffoldOptions()
+	opts.BaseURL = "api.example.com"
+	opts.Auth = "oauth"
+	if _, err := Scaffold(opts); err == nil {
+		t.Error("expected error for invalid options")
+	}
+}
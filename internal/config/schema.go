@@ -0,0 +1,125 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Schema kinds served for editors, by the request body they describe
+const (
+	SchemaEndpoint      = "endpoint"
+	SchemaAuthConfig    = "auth_config"
+	SchemaIncomingRoute = "incoming_route"
+)
+
+// httpMethods are the methods endpoints can send
+var httpMethods = []interface{}{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
+
+// Schemas returns JSON Schemas of the endpoint, auth config and incoming
+// route request bodies, keyed by kind, so editors can build forms from them.
+// Properties are derived from the request types; enums and required fields
+// follow the validation rules.
+func Schemas() map[string]map[string]interface{} {
+	endpoint := JSONSchema(EndpointRequest{})
+	endpoint["required"] = []string{"name"}
+	endpoint["anyOf"] = []interface{}{
+		map[string]interface{}{"required": []string{"url_template"}},
+		map[string]interface{}{"required": []string{"config_path"}},
+	}
+	setProperty(endpoint, "method", map[string]interface{}{"enum": httpMethods})
+	setProperty(endpoint, "arrival", map[string]interface{}{"enum": []interface{}{ArrivalFixed, ArrivalPoisson}})
+	setProperty(endpoint, "auth", map[string]interface{}{
+		"type":        []string{"string", "object"},
+		"description": "Name of an auth config, or an inline auth config",
+	})
+	setProperty(endpoint, "expected_status", map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": []string{"string", "integer"}},
+	})
+
+	auth := JSONSchema(AuthConfig{})
+	auth["required"] = []string{"name", "type"}
+	setProperty(auth, "type", map[string]interface{}{"enum": []interface{}{
+		AuthTypeNone, AuthTypeBearer, AuthTypeAPIKey, AuthTypeAPIKeyQuery, AuthTypeBasic, AuthTypeCustom, AuthTypeAWSSigV4,
+	}})
+
+	route := JSONSchema(IncomingEndpointRequest{})
+	route["required"] = []string{"name", "path", "method", "responses"}
+	setProperty(route, "method", map[string]interface{}{"enum": append([]interface{}{"*"}, httpMethods...)})
+
+	return map[string]map[string]interface{}{
+		SchemaEndpoint:      endpoint,
+		SchemaAuthConfig:    auth,
+		SchemaIncomingRoute: route,
+	}
+}
+
+// JSONSchema describes the JSON encoding of a value's type as a JSON Schema:
+// objects with their properties by JSON name, arrays, maps and scalars.
+// Fields of type interface{}, and types nested in themselves, accept any value.
+func JSONSchema(v interface{}) map[string]interface{} {
+	return typeSchema(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+// timeType is encoded as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// rawMessageType holds any JSON value
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// typeSchema returns the JSON Schema of a type; visiting holds the struct
+// types being described, to stop at recursive types
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	t = derefType(t)
+	if t == nil || visiting[t] {
+		return map[string]interface{}{}
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if t == rawMessageType {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), visiting)}
+	case reflect.Struct:
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			if name := jsonName(t.Field(i)); name != "" {
+				properties[name] = typeSchema(t.Field(i).Type, visiting)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// setProperty merges keywords into the schema of a property
+func setProperty(schema map[string]interface{}, name string, keywords map[string]interface{}) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	property, ok := properties[name].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, value := range keywords {
+		property[key] = value
+	}
+}
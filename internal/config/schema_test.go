@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFieldErrors(t *testing.T) {
+	endpoint := Endpoint{
+		Name:        "orders",
+		Method:      "FETCH",
+		URLTemplate: "http://example.com",
+		Timeout:     30,
+		Jitter:      150,
+		Tags:        []string{"a,b"},
+		Stages:      []Stage{{Duration: "1m"}, {Duration: "soon"}},
+	}
+	want := map[string]string{
+		"endpoint orders: invalid method FETCH":                                                 "method",
+		"endpoint orders: jitter must be between 0 and 100":                                     "jitter",
+		`endpoint orders: invalid tag "a,b" (must be non-empty and contain no commas)`:          "tags",
+		`endpoint orders: stages 2: invalid duration "soon" (must be positive, e.g. 30s or 2m)`: "stages[1].duration",
+	}
+	errors := FieldErrors(&EndpointRequest{}, endpoint.Validate())
+	if len(errors) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errors), len(want), errors)
+	}
+	for _, e := range errors {
+		if field, ok := want[e.Message]; !ok || e.Field != field {
+			t.Errorf("%q: got field %q, want %q", e.Message, e.Field, field)
+		}
+	}
+
+	tests := []struct {
+		v       interface{}
+		message string
+		want    string
+	}{
+		{&AuthConfig{}, "auth api: token_endpoint.url or token_endpoint.url_env required", "token_endpoint.url"},
+		{&AuthConfig{}, "auth config name is required", "name"},
+		{&IncomingEndpointRequest{}, "incoming endpoint orders response[1]: max_response_ms must be >= min_response_ms", "responses[1].max_response_ms"},
+		{&IncomingEndpointRequest{}, "incoming endpoint orders response[0]: status code must be between 100 and 599", "responses[0].status"},
+		{&IncomingEndpointRequest{}, "incoming endpoint orders: rate_limit burst must be non-negative", "rate_limit.burst"},
+		{&IncomingEndpointRequest{}, "incoming endpoint orders: invalid callback method FETCH", "callback.method"},
+		{&IncomingEndpointRequest{}, "incoming endpoint orders: body_schema.properties.id: must be an object", "validation.body_schema.properties.id"},
+		{&IncomingEndpointRequest{}, "incoming endpoint orders: response shares must sum to 1.0 (got 0.500)", "responses"},
+		{&EndpointRequest{}, "endpoint orders: pause window start: invalid time", "pause_windows"},
+		{&EndpointRequest{}, "something unrelated went wrong", ""},
+	}
+	for _, tt := range tests {
+		if got := FieldErrors(tt.v, []string{tt.message})[0].Field; got != tt.want {
+			t.Errorf("%q: got field %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	schemas := Schemas()
+	for _, kind := range []string{SchemaEndpoint, SchemaAuthConfig, SchemaIncomingRoute} {
+		if schemas[kind]["type"] != "object" {
+			t.Errorf("%s: expected an object schema, got %v", kind, schemas[kind])
+		}
+	}
+
+	properties := schemas[SchemaEndpoint]["properties"].(map[string]interface{})
+	if properties["frequency"].(map[string]interface{})["type"] != "number" {
+		t.Errorf("frequency: got %v", properties["frequency"])
+	}
+	if enum, ok := properties["method"].(map[string]interface{})["enum"].([]interface{}); !ok || len(enum) != 7 {
+		t.Errorf("method: expected the 7 methods as enum, got %v", properties["method"])
+	}
+	stages := properties["stages"].(map[string]interface{})
+	item := stages["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	if item["duration"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("stages: unexpected items %v", stages["items"])
+	}
+
+	route := schemas[SchemaIncomingRoute]["properties"].(map[string]interface{})
+	if _, ok := route["rate_limit"].(map[string]interface{})["properties"]; !ok {
+		t.Errorf("rate_limit: expected the nested object's properties, got %v", route["rate_limit"])
+	}
+}
+
+func TestJSONSchemaTimeAndRecursion(t *testing.T) {
+	type node struct {
+		At       time.Time        `json:"at"`
+		Children map[string]*node `json:"children"`
+	}
+	properties := JSONSchema(node{})["properties"].(map[string]interface{})
+	if at := properties["at"].(map[string]interface{}); at["type"] != "string" || at["format"] != "date-time" {
+		t.Errorf("at: expected a date-time string, got %v", at)
+	}
+	children := properties["children"].(map[string]interface{})
+	if len(children["additionalProperties"].(map[string]interface{})) != 0 {
+		t.Errorf("children: expected the recursive type to accept any value, got %v", children)
+	}
+}
@@ -0,0 +1,49 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import "fmt"
+
+// SLO is the service level objective of an endpoint: the percentage of its
+// requests that must be good. Failed requests are bad, and so are successful
+// ones slower than latency_ms if set. The rest is the error budget.
+type SLO struct {
+	Objective float64 `mapstructure:"objective" yaml:"objective" json:"objective"`                        // Percent of good requests, e.g. 99.9
+	LatencyMs int     `mapstructure:"latency_ms" yaml:"latency_ms,omitempty" json:"latency_ms,omitempty"` // Slower successful requests are bad too (0 = failures only)
+}
+
+// Validate checks if the SLO is valid
+func (s *SLO) Validate() []string {
+	var errors []string
+
+	if s.Objective <= 0 || s.Objective >= 100 {
+		errors = append(errors, fmt.Sprintf("slo: objective must be above 0 and below 100, got %g", s.Objective))
+	}
+	if s.LatencyMs < 0 {
+		errors = append(errors, "slo: latency_ms must be non-negative")
+	}
+
+	return errors
+}
+
+// SLOBreached returns true if a request to the endpoint counts against its
+// error budget, false for endpoints without an SLO
+func (e *Endpoint) SLOBreached(totalTimeMs float64, success bool) bool {
+	if e.SLO == nil {
+		return false
+	}
+	return !success || (e.SLO.LatencyMs > 0 && totalTimeMs > float64(e.SLO.LatencyMs))
+}
+
+// GetSLOs returns the SLOs of endpoints by endpoint name
+func (m *Manager) GetSLOs() map[string]SLO {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	slos := make(map[string]SLO)
+	for _, ep := range m.config.Endpoints {
+		if ep.SLO != nil {
+			slos[ep.Name] = *ep.SLO
+		}
+	}
+	return slos
+}
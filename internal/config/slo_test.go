@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestEndpointSLOBreached(t *testing.T) {
+	ep := Endpoint{Name: "a"}
+	if ep.SLOBreached(10, false) {
+		t.Error("expected no breaches without an SLO")
+	}
+
+	ep.SLO = &SLO{Objective: 99.9, LatencyMs: 300}
+	if ep.SLOBreached(300, true) || !ep.SLOBreached(301, true) || !ep.SLOBreached(10, false) {
+		t.Error("expected failed and slow requests to breach the SLO")
+	}
+
+	for _, slo := range []SLO{{Objective: 100}, {Objective: 0}, {Objective: 99, LatencyMs: -1}} {
+		if errs := slo.Validate(); len(errs) == 0 {
+			t.Errorf("expected %+v to be invalid", slo)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Stage is one step of a virtual user ramp, such as 10 users for 2m
+type Stage struct {
+	Duration     string `mapstructure:"duration" yaml:"duration" json:"duration"`                // Go duration, e.g. 2m or 30s
+	VirtualUsers int    `mapstructure:"virtual_users" yaml:"virtual_users" json:"virtual_users"` // Users during the stage
+	Ramp         bool   `mapstructure:"ramp" yaml:"ramp,omitempty" json:"ramp,omitempty"`        // Change linearly from the previous stage's users over the stage
+}
+
+// StagePosition is where a ramp is at a point in time
+type StagePosition struct {
+	Stage        int     `json:"stage"`         // 1-based index of the current stage
+	Stages       int     `json:"stages"`        // Number of stages
+	VirtualUsers int     `json:"virtual_users"` // Users before the global multiplier
+	RemainingSec float64 `json:"remaining_sec"` // Until the current stage ends
+	Completed    bool    `json:"completed"`     // All stages are over; the last one's users are kept
+}
+
+// Validate checks if the stage is valid
+func (st *Stage) Validate() []string {
+	var errors []string
+
+	if d, err := time.ParseDuration(st.Duration); err != nil || d <= 0 {
+		errors = append(errors, fmt.Sprintf("invalid duration %q (must be positive, e.g. 30s or 2m)", st.Duration))
+	}
+	if st.VirtualUsers < 0 {
+		errors = append(errors, "virtual_users must be non-negative")
+	}
+
+	return errors
+}
+
+// GetDuration returns the stage's duration, 0 if invalid
+func (st *Stage) GetDuration() time.Duration {
+	d, err := time.ParseDuration(st.Duration)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ValidateStages validates a list of stages, prefixing errors with where the
+// stages are defined
+func ValidateStages(prefix string, stages []Stage) []string {
+	var errors []string
+	for i := range stages {
+		for _, err := range stages[i].Validate() {
+			errors = append(errors, fmt.Sprintf("%s%d: %s", prefix, i+1, err))
+		}
+	}
+	return errors
+}
+
+// StageAt returns the position in a ramp after elapsed time. Ramped stages
+// start from the previous stage's users, or from zero for the first stage.
+func StageAt(stages []Stage, elapsed time.Duration) StagePosition {
+	pos := StagePosition{Stages: len(stages)}
+	previous := 0
+	var start time.Duration
+	for i := range stages {
+		duration := stages[i].GetDuration()
+		if elapsed < start+duration {
+			pos.Stage = i + 1
+			pos.VirtualUsers = stages[i].VirtualUsers
+			if stages[i].Ramp {
+				progress := float64(elapsed-start) / float64(duration)
+				pos.VirtualUsers = int(math.Round(float64(previous) + float64(stages[i].VirtualUsers-previous)*progress))
+			}
+			pos.RemainingSec = (start + duration - elapsed).Seconds()
+			return pos
+		}
+		previous = stages[i].VirtualUsers
+		start += duration
+	}
+
+	pos.Stage = len(stages)
+	pos.VirtualUsers = previous
+	pos.Completed = true
+	return pos
+}
+
+// NextStageStart returns when the stage after the one at elapsed starts, or
+// false if elapsed is in the last stage or past it
+func NextStageStart(stages []Stage, elapsed time.Duration) (time.Duration, bool) {
+	var start time.Duration
+	for i := range stages {
+		start += stages[i].GetDuration()
+		if elapsed < start && i < len(stages)-1 {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// ScaleUsers applies the global multiplier to a number of virtual users,
+// keeping at least one while both are positive
+func ScaleUsers(users int, globalMultiplier float64) int {
+	if users <= 0 || globalMultiplier <= 0 {
+		return 0
+	}
+	return max(1, int(math.Round(float64(users)*globalMultiplier)))
+}
+
+// DistributeUsers splits a total number of users across endpoints in
+// proportion to their weights, by largest remainder
+func DistributeUsers(total int, names []string, weights map[string]float64) map[string]int {
+	shares := make(map[string]int, len(names))
+	var sum float64
+	for _, name := range names {
+		sum += weights[name]
+	}
+	if total <= 0 || sum <= 0 {
+		return shares
+	}
+
+	assigned := 0
+	remainders := make(map[string]float64, len(names))
+	for _, name := range names {
+		exact := float64(total) * weights[name] / sum
+		shares[name] = int(exact)
+		remainders[name] = exact - float64(shares[name])
+		assigned += shares[name]
+	}
+	for ; assigned < total; assigned++ {
+		best := ""
+		for _, name := range names {
+			if best == "" || remainders[name] > remainders[best] {
+				best = name
+			}
+		}
+		shares[best]++
+		remainders[best] = -1
+	}
+	return shares
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStageAt(t *testing.T) {
+	stages := []Stage{
+		{Duration: "1m", VirtualUsers: 10, Ramp: true},
+		{Duration: "2m", VirtualUsers: 10},
+		{Duration: "1m", VirtualUsers: 50, Ramp: true},
+	}
+
+	tests := []struct {
+		elapsed   time.Duration
+		stage     int
+		users     int
+		completed bool
+	}{
+		{0, 1, 0, false},
+		{30 * time.Second, 1, 5, false},
+		{time.Minute, 2, 10, false},
+		{3*time.Minute + 30*time.Second, 3, 30, false},
+		{10 * time.Minute, 3, 50, true},
+	}
+	for _, tt := range tests {
+		pos := StageAt(stages, tt.elapsed)
+		if pos.Stage != tt.stage || pos.VirtualUsers != tt.users || pos.Completed != tt.completed {
+			t.Errorf("StageAt(%v) = %+v, want stage %d with %d users (completed %v)", tt.elapsed, pos, tt.stage, tt.users, tt.completed)
+		}
+	}
+
+	if next, ok := NextStageStart(stages, 90*time.Second); !ok || next != 3*time.Minute {
+		t.Errorf("NextStageStart(90s) = %v, %v, want 3m", next, ok)
+	}
+	if _, ok := NextStageStart(stages, 3*time.Minute); ok {
+		t.Error("expected no stage after the last one")
+	}
+}
+
+func TestStage_Validate(t *testing.T) {
+	if errs := ValidateStages("stages ", []Stage{{Duration: "30s", VirtualUsers: 5}}); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if errs := ValidateStages("stages ", []Stage{{Duration: "soon"}, {Duration: "0s"}, {Duration: "1m", VirtualUsers: -1}}); len(errs) != 3 {
+		t.Errorf("expected 3 errors, got %v", errs)
+	}
+}
+
+func TestDistributeUsers(t *testing.T) {
+	shares := DistributeUsers(10, []string{"a", "b", "c"}, map[string]float64{"a": 1, "b": 1, "c": 1})
+	if shares["a"]+shares["b"]+shares["c"] != 10 || shares["a"] != 4 {
+		t.Errorf("expected 4/3/3, got %v", shares)
+	}
+
+	shares = DistributeUsers(6, []string{"a", "b"}, map[string]float64{"a": 2, "b": 1})
+	if shares["a"] != 4 || shares["b"] != 2 {
+		t.Errorf("expected 4/2, got %v", shares)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"math/rand"
 	"net/url"
+	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"text/template"
@@ -110,11 +112,77 @@ var TemplateFuncs = template.FuncMap{
 		}
 		return defaultVal
 	},
+
+	// Per-request values, bound by EvaluateRequestTemplate (zero values otherwise)
+	"requestID": func() string { return "" },
+	"sequence":  func() int64 { return 0 },
+	"workerID":  func() int { return 0 },
+}
+
+// templateFuncsMu guards TemplateFuncs against registration while templates
+// are being evaluated
+var templateFuncsMu sync.RWMutex
+
+// templateNamePattern matches names usable as template functions
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RegisterTemplateFunc adds a custom function (e.g. an HMAC signature or a
+// custom ID format) usable in URL, header and body templates. fn must be a
+// function returning one value, or a value and an error. Built-in functions
+// cannot be replaced.
+func RegisterTemplateFunc(name string, fn interface{}) error {
+	if !templateNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid template function name: %q", name)
+	}
+
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("template function %s: not a function", name)
+	}
+	switch {
+	case fnType.NumOut() == 1:
+	case fnType.NumOut() == 2 && fnType.Out(1) == reflect.TypeOf((*error)(nil)).Elem():
+	default:
+		return fmt.Errorf("template function %s: must return a value, or a value and an error", name)
+	}
+
+	templateFuncsMu.Lock()
+	defer templateFuncsMu.Unlock()
+
+	if _, exists := TemplateFuncs[name]; exists {
+		return fmt.Errorf("template function already registered: %s", name)
+	}
+	TemplateFuncs[name] = fn
+	return nil
+}
+
+// RequestContext holds the per-request values exposed to templates as
+// {{requestID}}, {{sequence}} and {{workerID}}, so a request's URL, headers
+// and body can carry the same correlation ID
+type RequestContext struct {
+	RequestID string // Also reported in the request result
+	Sequence  int64  // Per-endpoint request counter, starting at 1
+	WorkerID  int    // Concurrency slot executing the request, starting at 1
+
+	Incoming    *IncomingRequestData // Request that triggered an incoming route callback
+	Environment *Environment         // Active environment of the endpoint, nil if none
+}
+
+// funcs returns template functions bound to the request context
+func (rc *RequestContext) funcs() template.FuncMap {
+	return template.FuncMap{
+		"requestID": func() string { return rc.RequestID },
+		"sequence":  func() int64 { return rc.Sequence },
+		"workerID":  func() int { return rc.WorkerID },
+	}
 }
 
 // TemplateData provides data for template evaluation
 type TemplateData struct {
-	Env map[string]string
+	Env     map[string]string
+	Request *IncomingRequestData // Set for incoming route callbacks
+	BaseURL string               // Of the active environment
+	Vars    map[string]string    // Of the active environment
 }
 
 // GetEnvMap returns a map of all environment variables from .env file
@@ -131,14 +199,32 @@ func GetEnvMap() map[string]string {
 
 // EvaluateTemplate evaluates a URL template with random/dynamic values
 func EvaluateTemplate(templateStr string) (string, error) {
-	tmpl, err := template.New("url").Funcs(TemplateFuncs).Parse(templateStr)
+	return EvaluateRequestTemplate(templateStr, nil)
+}
+
+// EvaluateRequestTemplate evaluates a template with the per-request functions
+// bound to rc (nil leaves them at their zero values)
+func EvaluateRequestTemplate(templateStr string, rc *RequestContext) (string, error) {
+	if rc == nil {
+		rc = &RequestContext{}
+	}
+	templateFuncsMu.RLock()
+	tmpl := template.New("url").Funcs(TemplateFuncs)
+	templateFuncsMu.RUnlock()
+
+	tmpl, err := tmpl.Funcs(rc.funcs()).Parse(templateStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	var buf bytes.Buffer
 	data := TemplateData{
-		Env: GetEnvMap(),
+		Env:     GetEnvMap(),
+		Request: rc.Incoming,
+	}
+	if rc.Environment != nil {
+		data.BaseURL = rc.Environment.BaseURL
+		data.Vars = rc.Environment.Vars
 	}
 
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -150,13 +236,19 @@ func EvaluateTemplate(templateStr string) (string, error) {
 
 // EvaluateBodyTemplate evaluates a body template (for POST requests)
 func EvaluateBodyTemplate(body interface{}) (interface{}, error) {
+	return EvaluateRequestBodyTemplate(body, nil)
+}
+
+// EvaluateRequestBodyTemplate evaluates a body template with the per-request
+// functions bound to rc
+func EvaluateRequestBodyTemplate(body interface{}, rc *RequestContext) (interface{}, error) {
 	switch v := body.(type) {
 	case string:
-		return EvaluateTemplate(v)
+		return EvaluateRequestTemplate(v, rc)
 	case map[string]interface{}:
 		result := make(map[string]interface{})
 		for key, value := range v {
-			evaluated, err := EvaluateBodyTemplate(value)
+			evaluated, err := EvaluateRequestBodyTemplate(value, rc)
 			if err != nil {
 				return nil, err
 			}
@@ -166,7 +258,7 @@ func EvaluateBodyTemplate(body interface{}) (interface{}, error) {
 	case []interface{}:
 		var result []interface{}
 		for _, item := range v {
-			evaluated, err := EvaluateBodyTemplate(item)
+			evaluated, err := EvaluateRequestBodyTemplate(item, rc)
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,65 @@
+package config
+
+import "testing"
+
+func TestEvaluateRequestTemplate_RequestContext(t *testing.T) {
+	rc := &RequestContext{RequestID: "abc123", Sequence: 7, WorkerID: 3}
+
+	got, err := EvaluateRequestTemplate("/orders/{{ sequence }}?rid={{ requestID }}&w={{ workerID }}", rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "/orders/7?rid=abc123&w=3" {
+		t.Errorf("expected request context values, got %s", got)
+	}
+
+	body, err := EvaluateRequestBodyTemplate(map[string]interface{}{"id": "{{ requestID }}"}, rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.(map[string]interface{})["id"] != "abc123" {
+		t.Errorf("expected request ID in body, got %v", body)
+	}
+
+	// Without a request context the functions still parse
+	if got, err := EvaluateTemplate("{{ requestID }}{{ sequence }}"); err != nil || got != "0" {
+		t.Errorf("expected zero values without context, got %q (%v)", got, err)
+	}
+}
+
+func TestRegisterTemplateFunc(t *testing.T) {
+	if err := RegisterTemplateFunc("testShout", func(s string) string { return s + "!" }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := EvaluateTemplate(`{{ testShout "hi" }}`)
+	if err != nil || got != "hi!" {
+		t.Errorf("expected registered function to be usable, got %q (%v)", got, err)
+	}
+
+	if err := RegisterTemplateFunc("randomUUID", func() string { return "" }); err == nil {
+		t.Error("expected error replacing a built-in function")
+	}
+	if err := RegisterTemplateFunc("notAFunc", 42); err == nil {
+		t.Error("expected error for a non-function value")
+	}
+	if err := RegisterTemplateFunc("noResult", func() {}); err == nil {
+		t.Error("expected error for a function without a result")
+	}
+	if err := RegisterTemplateFunc("bad name", func() string { return "" }); err == nil {
+		t.Error("expected error for an invalid name")
+	}
+}
+
+func TestEvaluateRequestTemplate_IncomingRequest(t *testing.T) {
+	rc := &RequestContext{Incoming: &IncomingRequestData{
+		Route:   "create_order",
+		Headers: map[string]string{"X-Trace-Id": "t-1"},
+		Body:    map[string]interface{}{"order_id": "o-42"},
+		Status:  202,
+	}}
+
+	got, err := EvaluateRequestTemplate(`/orders/{{.Request.Body.order_id}}/{{index .Request.Headers "X-Trace-Id"}}?status={{.Request.Status}}`, rc)
+	if err != nil || got != "/orders/o-42/t-1?status=202" {
+		t.Errorf("unexpected callback template result %q (%v)", got, err)
+	}
+}
@@ -0,0 +1,25 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+// TLSCertsConfig configures the checks on the certificates of the servers
+// outgoing requests connect to
+type TLSCertsConfig struct {
+	ExpiryWarningDays int `mapstructure:"expiry_warning_days" yaml:"expiry_warning_days,omitempty" json:"expiry_warning_days,omitempty"` // Warn about certificates expiring within this many days (default 30)
+}
+
+// Validate checks if the TLS certificate configuration is valid
+func (t *TLSCertsConfig) Validate() []string {
+	if t.ExpiryWarningDays < 0 {
+		return []string{"tls_certs: expiry_warning_days must be non-negative"}
+	}
+	return nil
+}
+
+// GetTLSExpiryWarningDays returns how many days before expiry certificates
+// are warned about, 0 for the default
+func (m *Manager) GetTLSExpiryWarningDays() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config.TLSCerts.ExpiryWarningDays
+}
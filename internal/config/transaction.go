@@ -0,0 +1,169 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Config transaction operations
+const (
+	TxAdd    = "add"
+	TxUpdate = "update"
+	TxDelete = "delete"
+)
+
+// TxOperation is one change of a config transaction. Kind is a schema kind:
+// endpoint, auth_config or incoming_route. Value is the item to add, or the
+// replacement of the named item, in the JSON of the matching create request.
+type TxOperation struct {
+	Op    string          `json:"op"`
+	Kind  string          `json:"kind"`
+	Name  string          `json:"name,omitempty"`  // Item updated or deleted
+	Value json.RawMessage `json:"value,omitempty"` // Item added, or its replacement
+}
+
+// TransactionError reports why a config transaction was not applied: the
+// errors of its operations, by index, and the problems of the config the
+// transaction would have produced
+type TransactionError struct {
+	Ops      []error
+	Problems []string
+}
+
+func (e *TransactionError) Error() string {
+	var reasons []string
+	for i, err := range e.Ops {
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("operation %d: %v", i+1, err))
+		}
+	}
+	reasons = append(reasons, e.Problems...)
+	return "transaction failed: " + strings.Join(reasons, "; ")
+}
+
+// ApplyTransaction applies a batch of operations atomically: they apply in
+// order to a copy of the config, and the copy replaces the config only if
+// every operation succeeds and the result is valid. The returned diff shows
+// what changed, or would have with dryRun. Applied transactions are recorded
+// as a config version.
+func (m *Manager) ApplyTransaction(ops []TxOperation, dryRun bool) (ConfigDiff, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The copy goes through the same checks as single changes
+	tx := &Manager{config: m.config.Clone()}
+	txErr := &TransactionError{Ops: make([]error, len(ops))}
+	failed := false
+	for i, op := range ops {
+		if err := tx.applyOperation(op); err != nil {
+			txErr.Ops[i] = err
+			failed = true
+		}
+	}
+
+	// Only report problems the transaction introduced
+	existing := map[string]bool{}
+	for _, problem := range (&Manager{config: m.config}).Validate() {
+		existing[problem] = true
+	}
+	for _, problem := range tx.Validate() {
+		if !existing[problem] {
+			txErr.Problems = append(txErr.Problems, problem)
+			failed = true
+		}
+	}
+
+	diff := DiffConfigs(m.config, tx.config)
+	if failed {
+		return diff, txErr
+	}
+	if !dryRun && diff.HasChanges {
+		m.config = tx.config
+		m.recordVersion(VersionSourceTransaction, 0)
+	}
+	return diff, nil
+}
+
+// TransactionEndpoints returns the endpoints as they would be after the
+// operations, skipping those that fail, without applying them
+func (m *Manager) TransactionEndpoints(ops []TxOperation) []Endpoint {
+	m.mu.RLock()
+	tx := &Manager{config: m.config.Clone()}
+	m.mu.RUnlock()
+
+	for _, op := range ops {
+		_ = tx.applyOperation(op)
+	}
+	return tx.GetEndpoints()
+}
+
+// applyOperation applies one transaction operation
+func (m *Manager) applyOperation(op TxOperation) error {
+	if op.Op != TxAdd && op.Op != TxUpdate && op.Op != TxDelete {
+		return &ValidationError{Problems: []string{fmt.Sprintf("invalid op %q (must be add, update or delete)", op.Op)}}
+	}
+	if op.Op != TxAdd && op.Name == "" {
+		return &ValidationError{Problems: []string{"name is required to " + op.Op}}
+	}
+	if op.Op != TxDelete && len(op.Value) == 0 {
+		return &ValidationError{Problems: []string{"value is required to " + op.Op}}
+	}
+
+	switch op.Kind {
+	case SchemaEndpoint:
+		if op.Op == TxDelete {
+			return m.DeleteEndpoint(op.Name)
+		}
+		var req EndpointRequest
+		if err := decodeTxValue(op.Value, &req); err != nil {
+			return err
+		}
+		if op.Op == TxAdd {
+			return m.AddEndpoint(req.ToEndpoint())
+		}
+		return m.UpdateEndpoint(op.Name, req.ToEndpoint())
+
+	case SchemaAuthConfig:
+		if op.Op == TxDelete {
+			return m.DeleteAuthConfig(op.Name)
+		}
+		var authCfg AuthConfig
+		if err := decodeTxValue(op.Value, &authCfg); err != nil {
+			return err
+		}
+		if errs := authCfg.Validate(); len(errs) > 0 {
+			return &ValidationError{Problems: errs}
+		}
+		if op.Op == TxAdd {
+			return m.AddAuthConfig(&authCfg)
+		}
+		return m.UpdateAuthConfig(op.Name, &authCfg)
+
+	case SchemaIncomingRoute:
+		if op.Op == TxDelete {
+			return m.DeleteIncomingRoute(op.Name)
+		}
+		var req IncomingEndpointRequest
+		if err := decodeTxValue(op.Value, &req); err != nil {
+			return err
+		}
+		if op.Op == TxAdd {
+			return m.AddIncomingRoute(req.ToIncomingEndpoint())
+		}
+		return m.UpdateIncomingRoute(op.Name, req.ToIncomingEndpoint())
+
+	default:
+		return &ValidationError{Problems: []string{fmt.Sprintf("invalid kind %q (must be one of: %s, %s, %s)",
+			op.Kind, SchemaEndpoint, SchemaAuthConfig, SchemaIncomingRoute)}}
+	}
+}
+
+// decodeTxValue decodes the value of an operation
+func decodeTxValue(value json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(value, v); err != nil {
+		return &ValidationError{Problems: []string{"invalid value: " + err.Error()}}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func txOps(t *testing.T, raw string) []TxOperation {
+	t.Helper()
+	var ops []TxOperation
+	if err := json.Unmarshal([]byte(raw), &ops); err != nil {
+		t.Fatalf("invalid test JSON: %v", err)
+	}
+	return ops
+}
+
+func TestApplyTransaction(t *testing.T) {
+	m := NewManager()
+	if err := m.AddEndpoint(Endpoint{Name: "old", URLTemplate: "https://example.com/old", FrequencyPerMin: 6}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The new endpoint uses the auth config added before it
+	diff, err := m.ApplyTransaction(txOps(t, `[
+		{"op":"add","kind":"auth_config","value":{"name":"api","type":"bearer","env_var":"API_TOKEN"}},
+		{"op":"add","kind":"endpoint","value":{"name":"orders","url_template":"https://example.com/orders","frequency":6,"auth":"api"}},
+		{"op":"delete","kind":"endpoint","name":"old"}
+	]`), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diff.Endpoints.Added) != 1 || len(diff.Endpoints.Removed) != 1 || len(diff.AuthConfigs.Added) != 1 {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+	if _, err := m.GetEndpoint("orders"); err != nil {
+		t.Errorf("expected orders to be added: %v", err)
+	}
+	if m.CurrentVersion() != 1 {
+		t.Errorf("expected the transaction to be recorded as a version, got %d", m.CurrentVersion())
+	}
+
+	// A failing operation leaves the config untouched
+	_, err = m.ApplyTransaction(txOps(t, `[
+		{"op":"delete","kind":"endpoint","name":"orders"},
+		{"op":"update","kind":"endpoint","name":"missing","value":{"name":"missing","url_template":"https://example.com"}}
+	]`), false)
+	var txErr *TransactionError
+	var notFound *NotFoundError
+	if !errors.As(err, &txErr) || txErr.Ops[0] != nil || !errors.As(txErr.Ops[1], &notFound) {
+		t.Fatalf("expected the second operation to fail, got %v", err)
+	}
+	if _, err := m.GetEndpoint("orders"); err != nil {
+		t.Errorf("expected failed transaction not to delete orders: %v", err)
+	}
+
+	// Dry runs report the diff without applying it
+	diff, err = m.ApplyTransaction(txOps(t, `[{"op":"update","kind":"endpoint","name":"orders","value":{"name":"orders","url_template":"https://example.com/orders","frequency":12,"auth":"api"}}]`), true)
+	if err != nil || len(diff.Endpoints.Changed) != 1 {
+		t.Fatalf("unexpected dry run result: %+v, %v", diff, err)
+	}
+	if orders, _ := m.GetEndpoint("orders"); orders.FrequencyPerMin != 6 {
+		t.Errorf("expected dry run not to update orders, got %v req/min", orders.FrequencyPerMin)
+	}
+}
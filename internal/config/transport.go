@@ -0,0 +1,92 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Networks an endpoint's transport can dial
+const (
+	NetworkTCP  = "tcp"
+	NetworkTCP4 = "tcp4"
+	NetworkTCP6 = "tcp6"
+	NetworkUnix = "unix"
+)
+
+// Transport is a custom dialer of an endpoint, for targets such as a
+// sidecar or local proxy: every connection of the endpoint dials its address
+// instead of the URL's host, which still sets the Host header and TLS server
+// name
+type Transport struct {
+	Network       string `mapstructure:"network" yaml:"network,omitempty" json:"network,omitempty"`                         // tcp (default), tcp4, tcp6 or unix
+	Address       string `mapstructure:"address" yaml:"address,omitempty" json:"address,omitempty"`                         // Socket path, or host:port of TCP networks (default: the URL's host)
+	LocalAddress  string `mapstructure:"local_address" yaml:"local_address,omitempty" json:"local_address,omitempty"`       // Source IP of TCP connections
+	DialTimeoutMs int    `mapstructure:"dial_timeout_ms" yaml:"dial_timeout_ms,omitempty" json:"dial_timeout_ms,omitempty"` // Connect timeout (default 30s)
+}
+
+// IsValidNetwork returns true if the given value is a network transports can dial
+func IsValidNetwork(network string) bool {
+	switch network {
+	case NetworkTCP, NetworkTCP4, NetworkTCP6, NetworkUnix:
+		return true
+	}
+	return false
+}
+
+// Validate checks if the transport is valid
+func (t *Transport) Validate() []string {
+	var errors []string
+
+	network := t.DialNetwork()
+	if !IsValidNetwork(network) {
+		errors = append(errors, fmt.Sprintf("transport: invalid network %s (must be one of: tcp, tcp4, tcp6, unix)", network))
+	}
+	if network == NetworkUnix {
+		if t.Address == "" {
+			errors = append(errors, "transport: address (the socket path) is required for the unix network")
+		}
+		if t.LocalAddress != "" {
+			errors = append(errors, "transport: local_address only applies to TCP networks")
+		}
+	} else {
+		if _, _, err := net.SplitHostPort(t.Address); t.Address != "" && err != nil {
+			errors = append(errors, "transport: address must be host:port for TCP networks")
+		}
+		if t.LocalAddress != "" && net.ParseIP(t.LocalAddress) == nil {
+			errors = append(errors, "transport: local_address must be an IP address")
+		}
+	}
+	if t.DialTimeoutMs < 0 {
+		errors = append(errors, "transport: dial_timeout_ms must be non-negative")
+	}
+
+	return errors
+}
+
+// DialNetwork returns the network dialed, tcp by default
+func (t *Transport) DialNetwork() string {
+	if t.Network == "" {
+		return NetworkTCP
+	}
+	return t.Network
+}
+
+// DialTimeout returns the connect timeout, 0 for the client's default
+func (t *Transport) DialTimeout() time.Duration {
+	return time.Duration(t.DialTimeoutMs) * time.Millisecond
+}
+
+// TargetHost returns the host every connection goes to, "" if it is the
+// URL's host or a Unix socket
+func (t *Transport) TargetHost() string {
+	if t.DialNetwork() == NetworkUnix {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(t.Address)
+	if err != nil {
+		return ""
+	}
+	return host
+}
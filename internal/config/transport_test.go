@@ -0,0 +1,38 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransportValidate(t *testing.T) {
+	tests := []struct {
+		transport Transport
+		wantErr   string
+	}{
+		{Transport{Network: NetworkUnix, Address: "/run/envoy.sock"}, ""},
+		{Transport{Address: "127.0.0.1:15001", LocalAddress: "10.0.0.7", DialTimeoutMs: 2000}, ""},
+		{Transport{Network: NetworkTCP6}, ""},
+		{Transport{Network: "udp"}, "invalid network"},
+		{Transport{Network: NetworkUnix}, "address (the socket path) is required"},
+		{Transport{Network: NetworkUnix, Address: "/run/envoy.sock", LocalAddress: "10.0.0.7"}, "local_address only applies"},
+		{Transport{Address: "127.0.0.1"}, "must be host:port"},
+		{Transport{LocalAddress: "eth0"}, "must be an IP address"},
+		{Transport{DialTimeoutMs: -1}, "dial_timeout_ms"},
+	}
+	for _, tt := range tests {
+		errs := strings.Join(tt.transport.Validate(), "; ")
+		if tt.wantErr == "" && errs != "" {
+			t.Errorf("%+v: unexpected errors: %s", tt.transport, errs)
+		}
+		if tt.wantErr != "" && !strings.Contains(errs, tt.wantErr) {
+			t.Errorf("%+v: expected an error containing %q, got %q", tt.transport, tt.wantErr, errs)
+		}
+	}
+
+	ep := Endpoint{Name: "a", Method: "GET", URLTemplate: "http://localhost/a", Timeout: 10, ConnectTo: "10.0.0.5",
+		Transport: &Transport{Network: NetworkUnix, Address: "/run/envoy.sock"}}
+	if errs := strings.Join(ep.Validate(), "; "); !strings.Contains(errs, "mutually exclusive") {
+		t.Errorf("expected connect_to and a socket to be mutually exclusive, got %q", errs)
+	}
+}
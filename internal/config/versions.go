@@ -0,0 +1,140 @@
+// Package config handles configuration loading and endpoint definitions
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// MaxConfigVersions is the number of applied configs kept for rollback
+const MaxConfigVersions = 10
+
+// Config version sources
+const (
+	VersionSourceFile        = "file"
+	VersionSourceImport      = "import"
+	VersionSourceReplace     = "replace"
+	VersionSourceRollback    = "rollback"
+	VersionSourceReload      = "reload"
+	VersionSourceTransaction = "transaction"
+)
+
+// ConfigVersion is a snapshot of a config as it was loaded, imported or
+// rolled back to. Runtime edits made afterwards are not part of the snapshot.
+type ConfigVersion struct {
+	ID             int       `json:"id"`
+	Source         string    `json:"source"`
+	RolledBackFrom int       `json:"rolled_back_from,omitempty"` // Version restored by a rollback
+	CreatedAt      time.Time `json:"created_at"`
+	Endpoints      int       `json:"endpoints"`
+	IncomingRoutes int       `json:"incoming_routes"`
+
+	config *Config
+}
+
+// Clone creates a deep copy of the config
+func (c *Config) Clone() *Config {
+	clone := *c
+
+	if c.AuthConfigs != nil {
+		clone.AuthConfigs = make(map[string]*AuthConfig, len(c.AuthConfigs))
+		for name, auth := range c.AuthConfigs {
+			authCopy := *auth
+			if auth.TokenEndpoint != nil {
+				tokenEndpoint := *auth.TokenEndpoint
+				authCopy.TokenEndpoint = &tokenEndpoint
+			}
+			clone.AuthConfigs[name] = &authCopy
+		}
+	}
+
+	clone.Endpoints = make([]Endpoint, len(c.Endpoints))
+	for i := range c.Endpoints {
+		clone.Endpoints[i] = c.Endpoints[i].Clone()
+	}
+
+	clone.EndpointGroups = make([]EndpointGroup, len(c.EndpointGroups))
+	for i, group := range c.EndpointGroups {
+		group.Cookies = append([]SeedCookie(nil), group.Cookies...)
+		clone.EndpointGroups[i] = group
+	}
+
+	clone.IncomingRoutes = make([]IncomingEndpoint, len(c.IncomingRoutes))
+	for i := range c.IncomingRoutes {
+		clone.IncomingRoutes[i] = c.IncomingRoutes[i].Clone()
+	}
+
+	clone.APITLS.Hosts = append([]string(nil), c.APITLS.Hosts...)
+	clone.APICORS.AllowedOrigins = append([]string(nil), c.APICORS.AllowedOrigins...)
+	clone.APICORS.AllowedHeaders = append([]string(nil), c.APICORS.AllowedHeaders...)
+	clone.APIAccessLog.ExcludePaths = append([]string(nil), c.APIAccessLog.ExcludePaths...)
+	return &clone
+}
+
+// recordVersion snapshots the current config as a new version, dropping the
+// oldest beyond MaxConfigVersions. m.mu must be held.
+func (m *Manager) recordVersion(source string, rolledBackFrom int) ConfigVersion {
+	m.lastVersion++
+	version := ConfigVersion{
+		ID:             m.lastVersion,
+		Source:         source,
+		RolledBackFrom: rolledBackFrom,
+		CreatedAt:      time.Now(),
+		Endpoints:      len(m.config.Endpoints),
+		IncomingRoutes: len(m.config.IncomingRoutes),
+		config:         m.config.Clone(),
+	}
+
+	m.versions = append(m.versions, version)
+	if len(m.versions) > MaxConfigVersions {
+		m.versions = m.versions[len(m.versions)-MaxConfigVersions:]
+	}
+	return version
+}
+
+// GetConfigVersions returns the kept config versions, newest first
+func (m *Manager) GetConfigVersions() []ConfigVersion {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	versions := make([]ConfigVersion, len(m.versions))
+	for i, version := range m.versions {
+		version.config = nil
+		versions[len(m.versions)-1-i] = version
+	}
+	return versions
+}
+
+// CurrentVersion returns the ID of the last applied config version (0 if none)
+func (m *Manager) CurrentVersion() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.lastVersion
+}
+
+// Rollback replaces the config with a kept version. The restored config is
+// recorded as a new version, so a rollback can itself be rolled back. The
+// current guardrails and guarded actions are kept, and the guardrails must
+// hold for the restored config.
+func (m *Manager) Rollback(id int) (ConfigVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, version := range m.versions {
+		if version.ID == id {
+			// The current guardrails and guarded actions stay in place
+			restoredCfg := version.config.Clone()
+			restoredCfg.Guardrails = m.config.Guardrails
+			restoredCfg.GuardedActions = m.config.GuardedActions
+			if err := m.checkGuardrails(restoredCfg); err != nil {
+				return ConfigVersion{}, err
+			}
+			m.config = restoredCfg
+			restored := m.recordVersion(VersionSourceRollback, id)
+			restored.config = nil
+			return restored, nil
+		}
+	}
+	return ConfigVersion{}, &NotFoundError{Kind: KindVersion, Name: strconv.Itoa(id)}
+}
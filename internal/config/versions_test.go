@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_Rollback(t *testing.T) {
+	manager := NewManager()
+
+	first := &Config{Endpoints: []Endpoint{{Name: "a", Method: "GET", URLTemplate: "https://example.com/a", FrequencyPerMin: 1}}}
+	if err := manager.ReplaceConfigFrom(first, VersionSourceImport); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second := &Config{Endpoints: []Endpoint{{Name: "b", Method: "GET", URLTemplate: "https://example.com/b", FrequencyPerMin: 1}}}
+	if err := manager.ReplaceConfigFrom(second, VersionSourceImport); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Runtime edits after a version is recorded don't change the snapshot
+	if err := manager.SetEndpointEnabled("b", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	version, err := manager.Rollback(1)
+	if err != nil {
+		t.Fatalf("unexpected rollback error: %v", err)
+	}
+	if version.ID != 3 || version.Source != VersionSourceRollback || version.RolledBackFrom != 1 {
+		t.Errorf("unexpected rollback version: %+v", version)
+	}
+	if endpoints := manager.GetEndpoints(); len(endpoints) != 1 || endpoints[0].Name != "a" {
+		t.Errorf("expected endpoint a after rollback, got %v", endpoints)
+	}
+
+	if _, err := manager.Rollback(2); err != nil {
+		t.Fatalf("unexpected rollback error: %v", err)
+	}
+	if endpoint, _ := manager.GetEndpoint("b"); endpoint == nil || !endpoint.Enabled {
+		t.Errorf("expected endpoint b as imported, got %+v", endpoint)
+	}
+
+	versions := manager.GetConfigVersions()
+	if len(versions) != 4 || versions[0].ID != 4 {
+		t.Errorf("expected 4 versions newest first, got %+v", versions)
+	}
+	if _, err := manager.Rollback(42); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+func TestManager_VersionsAreBounded(t *testing.T) {
+	manager := NewManager()
+	for i := 0; i < MaxConfigVersions+5; i++ {
+		if err := manager.ReplaceConfig(&Config{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	versions := manager.GetConfigVersions()
+	if len(versions) != MaxConfigVersions || versions[len(versions)-1].ID != 6 {
+		t.Errorf("expected the last %d versions, got %d starting at %d", MaxConfigVersions, len(versions), versions[len(versions)-1].ID)
+	}
+}
+
+func TestManager_ReloadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeConfig := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeConfig("outgoing_endpoints:\n  - name: a\n    url_template: https://example.com/a\n    frequency: 1\n")
+	manager := NewManager()
+	if err := manager.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	manager.SetAPIPort(9999)
+
+	writeConfig("api_port: 8080\noutgoing_endpoints:\n  - name: b\n    url_template: https://example.com/b\n    frequency: 2\n")
+	if err := manager.ReloadFromFile(); err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	if endpoints := manager.GetEndpoints(); len(endpoints) != 1 || endpoints[0].Name != "b" {
+		t.Errorf("expected endpoint b after reload, got %v", endpoints)
+	}
+	if port := manager.GetConfig().APIPort; port != 9999 {
+		t.Errorf("API port should be kept on reload, got %d", port)
+	}
+	if versions := manager.GetConfigVersions(); versions[0].Source != VersionSourceReload {
+		t.Errorf("expected a reload version, got %+v", versions[0])
+	}
+
+	// An invalid file leaves the current config in place
+	writeConfig("outgoing_endpoints:\n  - name: c\n    frequency: 1\n")
+	if err := manager.ReloadFromFile(); err == nil {
+		t.Error("expected error for invalid config")
+	}
+	if endpoints := manager.GetEndpoints(); len(endpoints) != 1 || endpoints[0].Name != "b" {
+		t.Errorf("expected endpoint b after failed reload, got %v", endpoints)
+	}
+}
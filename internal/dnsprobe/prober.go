@@ -0,0 +1,111 @@
+// Package dnsprobe periodically resolves hostnames directly against a DNS
+// resolver, independent of outgoing HTTP traffic, to observe TTL and cache behavior
+package dnsprobe
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+)
+
+// Prober runs DNS probes on a fixed interval and records results in the metrics collector
+type Prober struct {
+	configManager *config.Manager
+	collector     *metrics.Collector
+
+	running bool
+	mu      sync.Mutex
+}
+
+// New creates a new DNS prober
+func New(configManager *config.Manager, collector *metrics.Collector) *Prober {
+	return &Prober{
+		configManager: configManager,
+		collector:     collector,
+	}
+}
+
+// Start starts a goroutine that probes configured hostnames until ctx is cancelled.
+// The probe configuration is re-read on every cycle so that changes apply live.
+func (p *Prober) Start(ctx context.Context) {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return
+	}
+	p.running = true
+	p.mu.Unlock()
+
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			p.running = false
+			p.mu.Unlock()
+		}()
+
+		log.Println("DNS probe started")
+
+		for {
+			probeCfg := p.configManager.GetDNSProbeConfig()
+			if probeCfg.Enabled {
+				p.ProbeOnce(ctx)
+			}
+
+			timer := time.NewTimer(time.Duration(probeCfg.IntervalSeconds) * time.Second)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				log.Println("DNS probe stopped")
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// ProbeOnce resolves every probe hostname once, querying the record types
+// allowed by the configured IP family
+func (p *Prober) ProbeOnce(ctx context.Context) {
+	probeCfg := p.configManager.GetDNSProbeConfig()
+	hostnames := p.configManager.GetDNSProbeHostnames()
+	recordTypes := recordTypesForFamily(p.configManager.GetConfig().IPFamily)
+
+	resolver := probeCfg.Resolver
+	if resolver == "" {
+		resolver = systemResolver()
+	}
+	timeout := time.Duration(probeCfg.Timeout) * time.Second
+
+	var wg sync.WaitGroup
+	for _, hostname := range hostnames {
+		for _, recordType := range recordTypes {
+			wg.Add(1)
+			go func(hostname, recordType string) {
+				defer wg.Done()
+
+				queryCtx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				result := query(queryCtx, resolver, hostname, recordType)
+				p.collector.RecordDNSProbe(result)
+			}(hostname, recordType)
+		}
+	}
+	wg.Wait()
+}
+
+// recordTypesForFamily returns the DNS record types to query for an IP family setting
+func recordTypesForFamily(family string) []string {
+	switch family {
+	case config.IPFamilyIPv4:
+		return []string{RecordTypeA}
+	case config.IPFamilyIPv6:
+		return []string{RecordTypeAAAA}
+	default:
+		return []string{RecordTypeA, RecordTypeAAAA}
+	}
+}
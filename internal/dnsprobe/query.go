@@ -0,0 +1,214 @@
+// Package dnsprobe periodically resolves hostnames directly against a DNS
+// resolver, independent of outgoing HTTP traffic, to observe TTL and cache behavior
+package dnsprobe
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"moxapp/internal/metrics"
+)
+
+// Supported DNS record types
+const (
+	RecordTypeA    = "A"
+	RecordTypeAAAA = "AAAA"
+)
+
+// defaultResolver is used when no resolver is configured and /etc/resolv.conf has none
+const defaultResolver = "127.0.0.1:53"
+
+// query sends a single DNS question to the resolver and returns the parsed answer set
+func query(ctx context.Context, resolver, hostname, recordType string) *metrics.DNSProbeResult {
+	result := &metrics.DNSProbeResult{
+		Hostname:   hostname,
+		RecordType: recordType,
+		Resolver:   resolver,
+		Timestamp:  time.Now(),
+	}
+
+	qtype := dnsmessage.TypeA
+	if recordType == RecordTypeAAAA {
+		qtype = dnsmessage.TypeAAAA
+	}
+
+	name, err := dnsmessage.NewName(dnsName(hostname))
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid hostname: %v", err)
+		return result
+	}
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               queryID(),
+			RecursionDesired: true,
+		},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packet, err := msg.Pack()
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to build query: %v", err)
+		return result
+	}
+
+	start := time.Now()
+	resp, err := exchange(ctx, "udp", resolver, packet)
+	if err == nil && resp.Header.Truncated {
+		// Answer did not fit in a UDP datagram, retry over TCP
+		resp, err = exchange(ctx, "tcp", resolver, packet)
+	}
+	result.ResolutionMs = float64(time.Since(start).Microseconds()) / 1000.0
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if resp.Header.ID != msg.Header.ID {
+		result.Error = "response ID mismatch"
+		return result
+	}
+	if resp.Header.RCode != dnsmessage.RCodeSuccess {
+		result.Error = fmt.Sprintf("resolver returned %s", rcodeName(resp.Header.RCode))
+		return result
+	}
+
+	var minTTL uint32
+	for i, answer := range resp.Answers {
+		var ip string
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			ip = netip.AddrFrom4(body.A).String()
+		case *dnsmessage.AAAAResource:
+			ip = netip.AddrFrom16(body.AAAA).String()
+		default:
+			// CNAME chain entries carry no addresses but still count toward the TTL
+		}
+		if ip != "" {
+			result.Answers = append(result.Answers, ip)
+		}
+		// A TTL of 0 is a real value (don't cache), not an unset minimum
+		if i == 0 || answer.Header.TTL < minTTL {
+			minTTL = answer.Header.TTL
+		}
+	}
+	result.TTLSeconds = minTTL
+
+	return result
+}
+
+// queryID returns an unpredictable message ID, so off-path replies can't
+// easily be passed off as the answer
+func queryID() uint16 {
+	var b [2]byte
+	_, _ = rand.Read(b[:])
+	return binary.BigEndian.Uint16(b[:])
+}
+
+// exchange sends a packed DNS message over the given network and parses the reply
+func exchange(ctx context.Context, network, resolver string, packet []byte) (*dnsmessage.Message, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to resolver: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var buf []byte
+	if network == "tcp" {
+		// DNS over TCP prefixes each message with a two-byte length
+		framed := make([]byte, 2+len(packet))
+		binary.BigEndian.PutUint16(framed, uint16(len(packet)))
+		copy(framed[2:], packet)
+		if _, err := conn.Write(framed); err != nil {
+			return nil, fmt.Errorf("failed to send query: %w", err)
+		}
+
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+	} else {
+		if _, err := conn.Write(packet); err != nil {
+			return nil, fmt.Errorf("failed to send query: %w", err)
+		}
+
+		buf = make([]byte, 1232)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		buf = buf[:n]
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(buf); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &resp, nil
+}
+
+// rcodeName returns the conventional name of a DNS response code
+func rcodeName(rcode dnsmessage.RCode) string {
+	switch rcode {
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	}
+	return strings.TrimPrefix(rcode.String(), "RCode")
+}
+
+// dnsName converts a hostname to a fully qualified DNS name
+func dnsName(hostname string) string {
+	if strings.HasSuffix(hostname, ".") {
+		return hostname
+	}
+	return hostname + "."
+}
+
+// systemResolver returns the first nameserver from /etc/resolv.conf
+func systemResolver() string {
+	file, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return defaultResolver
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+	return defaultResolver
+}
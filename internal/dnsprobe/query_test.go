@@ -0,0 +1,250 @@
+package dnsprobe
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// stubResolver answers DNS queries over UDP and TCP on the same local port
+type stubResolver struct {
+	addr    string
+	udp     net.PacketConn
+	tcp     net.Listener
+	tcpHits atomic.Int32
+	// answer builds the reply to a query received over network; returning
+	// nil drops the query
+	answer func(network string, query dnsmessage.Message) *dnsmessage.Message
+}
+
+func newStubResolver(t *testing.T, answer func(network string, query dnsmessage.Message) *dnsmessage.Message) *stubResolver {
+	t.Helper()
+	s := &stubResolver{answer: answer}
+
+	// Retry until a UDP port is also free for TCP
+	for i := 0; s.tcp == nil; i++ {
+		udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+		if err != nil {
+			udp.Close()
+			if i == 10 {
+				t.Fatal(err)
+			}
+			continue
+		}
+		s.udp, s.tcp, s.addr = udp, tcp, udp.LocalAddr().String()
+	}
+	t.Cleanup(func() {
+		s.udp.Close()
+		s.tcp.Close()
+	})
+
+	go s.serveUDP()
+	go s.serveTCP()
+	return s
+}
+
+func (s *stubResolver) serveUDP() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if reply := s.reply("udp", buf[:n]); reply != nil {
+			s.udp.WriteTo(reply, addr)
+		}
+	}
+}
+
+func (s *stubResolver) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		s.tcpHits.Add(1)
+		go func() {
+			defer conn.Close()
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return
+			}
+			packet := make([]byte, binary.BigEndian.Uint16(length[:]))
+			if _, err := io.ReadFull(conn, packet); err != nil {
+				return
+			}
+			reply := s.reply("tcp", packet)
+			if reply == nil {
+				return
+			}
+			binary.BigEndian.PutUint16(length[:], uint16(len(reply)))
+			conn.Write(append(length[:], reply...))
+		}()
+	}
+}
+
+func (s *stubResolver) reply(network string, packet []byte) []byte {
+	var query dnsmessage.Message
+	if err := query.Unpack(packet); err != nil {
+		return nil
+	}
+	msg := s.answer(network, query)
+	if msg == nil {
+		return nil
+	}
+	reply, err := msg.Pack()
+	if err != nil {
+		return nil
+	}
+	return reply
+}
+
+// answerA builds a successful reply to query with an A record per ttl
+func answerA(query dnsmessage.Message, ttls ...uint32) *dnsmessage.Message {
+	reply := &dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.Header.ID, Response: true, RecursionAvailable: true},
+		Questions: query.Questions,
+	}
+	for i, ttl := range ttls {
+		reply.Answers = append(reply.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: query.Questions[0].Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+			Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, byte(i + 1)}},
+		})
+	}
+	return reply
+}
+
+func TestQuery(t *testing.T) {
+	stub := newStubResolver(t, func(network string, query dnsmessage.Message) *dnsmessage.Message {
+		return answerA(query, 300, 60)
+	})
+
+	result := query(context.Background(), stub.addr, "example.com", RecordTypeA)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if strings.Join(result.Answers, ",") != "192.0.2.1,192.0.2.2" {
+		t.Errorf("expected both addresses, got %v", result.Answers)
+	}
+	if result.TTLSeconds != 60 {
+		t.Errorf("expected the lowest TTL 60, got %d", result.TTLSeconds)
+	}
+	if stub.tcpHits.Load() != 0 {
+		t.Error("expected no TCP retry for a complete UDP answer")
+	}
+}
+
+func TestQueryZeroTTL(t *testing.T) {
+	stub := newStubResolver(t, func(network string, query dnsmessage.Message) *dnsmessage.Message {
+		return answerA(query, 0, 60)
+	})
+
+	result := query(context.Background(), stub.addr, "example.com", RecordTypeA)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if result.TTLSeconds != 0 {
+		t.Errorf("expected the TTL 0 kept as the lowest, got %d", result.TTLSeconds)
+	}
+}
+
+func TestQueryTruncatedRetriesOverTCP(t *testing.T) {
+	stub := newStubResolver(t, func(network string, query dnsmessage.Message) *dnsmessage.Message {
+		if network == "udp" {
+			reply := answerA(query)
+			reply.Header.Truncated = true
+			return reply
+		}
+		return answerA(query, 30, 30, 30)
+	})
+
+	result := query(context.Background(), stub.addr, "example.com", RecordTypeA)
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if stub.tcpHits.Load() != 1 {
+		t.Errorf("expected one TCP retry, got %d", stub.tcpHits.Load())
+	}
+	if len(result.Answers) != 3 || result.TTLSeconds != 30 {
+		t.Errorf("expected the full TCP answer, got %v ttl %d", result.Answers, result.TTLSeconds)
+	}
+}
+
+func TestQueryIDMismatch(t *testing.T) {
+	stub := newStubResolver(t, func(network string, query dnsmessage.Message) *dnsmessage.Message {
+		reply := answerA(query, 60)
+		reply.Header.ID++
+		return reply
+	})
+
+	result := query(context.Background(), stub.addr, "example.com", RecordTypeA)
+	if result.Error != "response ID mismatch" {
+		t.Errorf("expected an ID mismatch, got %q", result.Error)
+	}
+	if len(result.Answers) != 0 {
+		t.Errorf("expected the answers of a mismatched reply dropped, got %v", result.Answers)
+	}
+}
+
+func TestQueryRCodes(t *testing.T) {
+	tests := []struct {
+		rcode dnsmessage.RCode
+		want  string
+	}{
+		{dnsmessage.RCodeNameError, "resolver returned NXDOMAIN"},
+		{dnsmessage.RCodeServerFailure, "resolver returned SERVFAIL"},
+		{dnsmessage.RCodeRefused, "resolver returned REFUSED"},
+		{dnsmessage.RCodeFormatError, "resolver returned FORMERR"},
+		{dnsmessage.RCodeNotImplemented, "resolver returned NOTIMP"},
+	}
+
+	for _, tt := range tests {
+		stub := newStubResolver(t, func(network string, query dnsmessage.Message) *dnsmessage.Message {
+			reply := answerA(query)
+			reply.Header.RCode = tt.rcode
+			return reply
+		})
+		result := query(context.Background(), stub.addr, "example.com", RecordTypeA)
+		if result.Error != tt.want {
+			t.Errorf("rcode %d: expected %q, got %q", tt.rcode, tt.want, result.Error)
+		}
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	stub := newStubResolver(t, func(network string, query dnsmessage.Message) *dnsmessage.Message {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := query(ctx, stub.addr, "example.com", RecordTypeA)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the query to stop at the context deadline, took %s", elapsed)
+	}
+	if !strings.Contains(result.Error, "failed to read response") {
+		t.Errorf("expected a read timeout, got %q", result.Error)
+	}
+}
+
+func TestQueryIDs(t *testing.T) {
+	seen := make(map[uint16]bool)
+	for i := 0; i < 32; i++ {
+		seen[queryID()] = true
+	}
+	if len(seen) < 16 {
+		t.Errorf("expected varying query IDs, got %d distinct in 32", len(seen))
+	}
+}
@@ -0,0 +1,352 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// curlValueFlags are curl options taking a value that don't affect the
+// request sent, so they are skipped with their value
+var curlValueFlags = map[string]bool{
+	"-o": true, "--output": true, "-w": true, "--write-out": true,
+	"--connect-timeout": true, "--retry": true, "--retry-delay": true, "--retry-max-time": true,
+	"-x": true, "--proxy": true, "--cacert": true, "--capath": true, "-E": true, "--cert": true, "--key": true,
+	"--resolve": true, "--connect-to": true, "-c": true, "--cookie-jar": true, "-D": true, "--dump-header": true,
+	"--limit-rate": true, "-r": true, "--range": true, "-T": true, "--upload-file": true, "--interface": true,
+}
+
+// FromCurl converts curl commands, one per line or continued with a
+// trailing backslash as copied from browser dev tools, into endpoints.
+// Blank lines and # comments are ignored.
+func FromCurl(r io.Reader, opts Options) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	commands, err := splitShellWords(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []request
+	var warnings []string
+	for i, args := range commands {
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] != "curl" {
+			return nil, fmt.Errorf("command %d: expected curl, got %q", i+1, args[0])
+		}
+		req, cmdWarnings, err := parseCurl(args[1:])
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", i+1, err)
+		}
+		requests = append(requests, req)
+		warnings = append(warnings, cmdWarnings...)
+	}
+
+	result := build(requests, opts, 1)
+	result.Warnings = append(warnings, result.Warnings...)
+	return result, nil
+}
+
+// parseCurl parses the arguments of a curl command
+func parseCurl(args []string) (request, []string, error) {
+	var req request
+	var warnings, data []string
+	method, get := "", false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// -HName: value and --header=Name: value forms
+		flag, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			if name, v, ok := strings.Cut(arg, "="); ok {
+				flag, value, hasValue = name, v, true
+			}
+		} else if strings.HasPrefix(arg, "-") && len(arg) > 2 && strings.ContainsRune("XHdbuAemx", rune(arg[1])) {
+			flag, value, hasValue = arg[:2], arg[2:], true
+		}
+		next := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", flag)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch flag {
+		case "-X", "--request":
+			method, err = next()
+		case "-H", "--header":
+			var header string
+			if header, err = next(); err == nil {
+				name, v, _ := strings.Cut(header, ":")
+				req.headers = append(req.headers, [2]string{strings.TrimSpace(name), strings.TrimSpace(v)})
+				if strings.EqualFold(strings.TrimSpace(name), "content-type") {
+					req.contentType = strings.TrimSpace(v)
+				}
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii", "--data-urlencode":
+			var d string
+			if d, err = next(); err == nil {
+				if strings.HasPrefix(d, "@") && flag != "--data-raw" {
+					warnings = append(warnings, fmt.Sprintf("%s %s: data read from a file isn't imported, set body_file", flag, d))
+					continue
+				}
+				if flag == "--data-urlencode" {
+					d = urlEncodeData(d)
+				}
+				data = append(data, d)
+			}
+		case "--json":
+			var d string
+			if d, err = next(); err == nil {
+				data = append(data, d)
+				req.contentType = "application/json"
+			}
+		case "-u", "--user":
+			var user string
+			if user, err = next(); err == nil {
+				req.headers = append(req.headers, [2]string{"Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(user))})
+				warnings = append(warnings, "credentials from -u were converted to an Authorization header; consider a basic auth config instead")
+			}
+		case "-b", "--cookie":
+			var cookie string
+			if cookie, err = next(); err == nil {
+				req.headers = append(req.headers, [2]string{"Cookie", cookie})
+			}
+		case "-A", "--user-agent":
+			var agent string
+			if agent, err = next(); err == nil {
+				req.headers = append(req.headers, [2]string{"User-Agent", agent})
+			}
+		case "-e", "--referer":
+			var referer string
+			if referer, err = next(); err == nil {
+				req.headers = append(req.headers, [2]string{"Referer", referer})
+			}
+		case "-m", "--max-time":
+			var seconds string
+			if seconds, err = next(); err == nil {
+				if s, parseErr := strconv.ParseFloat(seconds, 64); parseErr == nil && s > 0 {
+					req.timeout = int(math.Ceil(s))
+				}
+			}
+		case "--url":
+			req.url, err = next()
+		case "-I", "--head":
+			method = "HEAD"
+		case "-G", "--get":
+			get = true
+		default:
+			switch {
+			case curlValueFlags[flag]:
+				_, err = next()
+			case strings.HasPrefix(arg, "-") && arg != "-":
+				// Boolean options such as --compressed, -sSL or -k
+			case req.url == "":
+				req.url = arg
+			default:
+				warnings = append(warnings, fmt.Sprintf("extra argument %q ignored", arg))
+			}
+		}
+		if err != nil {
+			return req, nil, err
+		}
+	}
+
+	if req.url == "" {
+		return req, nil, fmt.Errorf("no URL")
+	}
+	if !strings.Contains(req.url, "://") {
+		req.url = "http://" + req.url
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case get && body != "":
+		sep := "?"
+		if strings.Contains(req.url, "?") {
+			sep = "&"
+		}
+		req.url += sep + body
+		body = ""
+	case body != "" && req.contentType == "":
+		req.contentType = "application/x-www-form-urlencoded"
+	}
+	req.body = body
+
+	switch {
+	case method != "":
+		req.method = method
+	case get:
+		req.method = "GET"
+	case body != "":
+		req.method = "POST"
+	default:
+		req.method = "GET"
+	}
+	return req, warnings, nil
+}
+
+// urlEncodeData encodes a --data-urlencode value: "content", "name=content"
+// or "=content"
+func urlEncodeData(d string) string {
+	name, content, ok := strings.Cut(d, "=")
+	if !ok {
+		return url.QueryEscape(d)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
+
+// splitShellWords splits shell input into commands of words, handling
+// single, double and $'...' quotes, backslash escapes and line
+// continuations. Unquoted newlines and ; end a command.
+func splitShellWords(input string) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' {
+					continue // Line continuation
+				}
+				if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+					continue
+				}
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			end, err := readANSIQuoted(runes, i+2, &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i = end
+		case c == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case c == '#' && !inWord:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			endCommand()
+		case c == '\n' || c == ';':
+			endCommand()
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
+
+// readANSIQuoted reads a $'...' string starting after the opening quote and
+// returns the index of the closing quote
+func readANSIQuoted(runes []rune, i int, word *strings.Builder) (int, error) {
+	escapes := map[rune]string{'n': "\n", 't': "\t", 'r': "\r", '\\': "\\", '\'': "'", '"': "\"", '0': "\x00", 'a': "\a", 'b': "\b", 'e': "\x1b", 'f': "\f", 'v': "\v"}
+	for ; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return i, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				return 0, fmt.Errorf("unterminated $' quote")
+			}
+			i++
+			if s, ok := escapes[runes[i]]; ok {
+				word.WriteString(s)
+			} else if runes[i] == 'x' || runes[i] == 'u' {
+				n := 2
+				if runes[i] == 'u' {
+					n = 4
+				}
+				if i+n < len(runes) {
+					if code, err := strconv.ParseUint(string(runes[i+1:i+1+n]), 16, 32); err == nil {
+						word.WriteRune(rune(code))
+						i += n
+						continue
+					}
+				}
+				word.WriteRune('\\')
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune('\\')
+				word.WriteRune(runes[i])
+			}
+		default:
+			word.WriteRune(runes[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+// indexRune returns the index of r in runes at or after from, or -1
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
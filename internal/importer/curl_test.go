@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromCurl(t *testing.T) {
+	input := `# Copied from dev tools
+curl 'https://api.example.com/v1/orders?page=1' \
+  -H 'accept: application/json' \
+  -H 'cookie: session=abc' \
+  --compressed
+curl -X POST https://api.example.com/v1/orders -H "Content-Type: application/json" --data-raw $'{"sku":"a\'b","qty":2}' -m 2.5
+curl -sS -G https://api.example.com/v1/search -d q=shoes --data-urlencode 'tag=red & blue'
+curl 'https://api.example.com/v1/orders?page=1' -H 'accept: application/json'
+curl https://api.example.com/v1/form -d 'a=1' -d 'b=2'
+`
+	result, err := FromCurl(strings.NewReader(input), Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Endpoints) != 4 {
+		t.Fatalf("expected 4 endpoints, got %d: %+v", len(result.Endpoints), result.Endpoints)
+	}
+
+	list := result.Endpoints[0]
+	if list.Name != "get_v1_orders" || list.Method != "GET" || list.FrequencyPerMin != 2 {
+		t.Errorf("unexpected list endpoint: %+v", list)
+	}
+	if list.Headers["accept"] != "application/json" || list.Headers["cookie"] != "" {
+		t.Errorf("unexpected list headers: %v", list.Headers)
+	}
+
+	create := result.Endpoints[1]
+	body, ok := create.Body.(map[string]interface{})
+	if create.Method != "POST" || !ok || body["sku"] != "a'b" || create.Timeout != 3 || create.ContentType != "" {
+		t.Errorf("unexpected create endpoint: %+v", create)
+	}
+
+	search := result.Endpoints[2]
+	if search.Method != "GET" || search.URLTemplate != "https://api.example.com/v1/search?q=shoes&tag=red+%26+blue" {
+		t.Errorf("unexpected search endpoint: %+v", search)
+	}
+
+	// Form bodies can't be inline
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "post_v1_form") {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+
+	if _, err := FromCurl(strings.NewReader("wget https://example.com"), Options{}); err == nil {
+		t.Error("expected error for a non-curl command")
+	}
+	if _, err := FromCurl(strings.NewReader("curl 'https://example.com"), Options{}); err == nil {
+		t.Error("expected error for an unterminated quote")
+	}
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// harFile is the part of a HAR 1.2 file used by FromHAR
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	StartedDateTime string `json:"startedDateTime"`
+	ResourceType    string `json:"_resourceType"` // Chrome and Edge only
+	Request         struct {
+		Method   string         `json:"method"`
+		URL      string         `json:"url"`
+		Headers  []harNameValue `json:"headers"`
+		PostData *struct {
+			MimeType string         `json:"mimeType"`
+			Text     string         `json:"text"`
+			Params   []harNameValue `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Content struct {
+			MimeType string `json:"mimeType"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// staticResourceTypes are the Chrome resource types of page assets
+var staticResourceTypes = map[string]bool{
+	"image": true, "stylesheet": true, "script": true, "font": true, "media": true, "manifest": true,
+}
+
+// staticExtensions identify page assets in browsers that don't record the
+// resource type
+var staticExtensions = map[string]bool{
+	".js": true, ".mjs": true, ".css": true, ".map": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true, ".avif": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp4": true, ".webm": true, ".mp3": true,
+}
+
+// FromHAR converts the requests of a browser HAR file into endpoints. Unless
+// opts.Frequency is set, each endpoint gets the rate it was recorded at.
+func FromHAR(r io.Reader, opts Options) (*Result, error) {
+	var har harFile
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file: %w", err)
+	}
+
+	var requests []request
+	for _, entry := range har.Log.Entries {
+		if !opts.IncludeStatic && isStatic(entry) {
+			continue
+		}
+		req := request{
+			method: entry.Request.Method,
+			url:    entry.Request.URL,
+		}
+		req.at, _ = time.Parse(time.RFC3339Nano, entry.StartedDateTime)
+		for _, h := range entry.Request.Headers {
+			req.headers = append(req.headers, [2]string{h.Name, h.Value})
+		}
+		if pd := entry.Request.PostData; pd != nil {
+			req.contentType = pd.MimeType
+			req.body = pd.Text
+			if req.body == "" && len(pd.Params) > 0 {
+				form := url.Values{}
+				for _, p := range pd.Params {
+					form.Add(p.Name, p.Value)
+				}
+				req.body = form.Encode()
+			}
+		}
+		requests = append(requests, req)
+	}
+	return build(requests, opts, 0), nil
+}
+
+// isStatic reports whether a HAR entry loads a page asset
+func isStatic(entry harEntry) bool {
+	if entry.ResourceType != "" {
+		return staticResourceTypes[entry.ResourceType]
+	}
+	mimeType := entry.Response.Content.MimeType
+	for _, prefix := range []string{"image/", "font/", "audio/", "video/", "text/css", "text/javascript", "application/javascript"} {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	if u, err := url.Parse(entry.Request.URL); err == nil {
+		return staticExtensions[strings.ToLower(path.Ext(u.Path))]
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromHAR(t *testing.T) {
+	har := `{"log": {"entries": [
+  {"startedDateTime": "2026-01-02T10:00:00.000Z", "_resourceType": "document",
+   "request": {"method": "GET", "url": "https://shop.example.com/", "headers": [{"name": ":authority", "value": "shop.example.com"}, {"name": "user-agent", "value": "test"}]},
+   "response": {"content": {"mimeType": "text/html"}}},
+  {"startedDateTime": "2026-01-02T10:00:01.000Z", "_resourceType": "script",
+   "request": {"method": "GET", "url": "https://shop.example.com/app.js", "headers": []},
+   "response": {"content": {"mimeType": "application/javascript"}}},
+  {"startedDateTime": "2026-01-02T10:00:30.000Z",
+   "request": {"method": "POST", "url": "https://api.example.com/cart", "headers": [{"name": "Content-Type", "value": "application/json"}, {"name": "Content-Length", "value": "12"}],
+     "postData": {"mimeType": "application/json", "text": "{\"sku\":\"a1\"}"}},
+   "response": {"content": {"mimeType": "application/json"}}},
+  {"startedDateTime": "2026-01-02T10:02:00.000Z",
+   "request": {"method": "POST", "url": "https://api.example.com/cart", "headers": [],
+     "postData": {"mimeType": "application/json", "text": "{\"sku\":\"a1\"}"}},
+   "response": {"content": {"mimeType": "application/json"}}},
+  {"startedDateTime": "2026-01-02T10:04:00.000Z",
+   "request": {"method": "POST", "url": "https://api.example.com/cart", "headers": [],
+     "postData": {"mimeType": "application/json", "text": "{\"sku\":\"a1\"}"}},
+   "response": {"content": {"mimeType": "application/json"}}}
+]}}`
+
+	result, err := FromHAR(strings.NewReader(har), Options{Tags: []string{"recorded"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Endpoints) != 2 {
+		t.Fatalf("expected 2 endpoints, got %d: %+v", len(result.Endpoints), result.Endpoints)
+	}
+
+	page := result.Endpoints[0]
+	if page.Name != "get_root" || page.Headers["user-agent"] != "test" || len(page.Headers) != 1 {
+		t.Errorf("unexpected page endpoint: %+v", page)
+	}
+	// Recorded over 4 minutes
+	if page.FrequencyPerMin != 0.25 {
+		t.Errorf("expected frequency 0.25, got %v", page.FrequencyPerMin)
+	}
+
+	cart := result.Endpoints[1]
+	if cart.Name != "post_cart" || cart.FrequencyPerMin != 0.75 || cart.Tags[0] != "recorded" || len(cart.Headers) != 0 {
+		t.Errorf("unexpected cart endpoint: %+v", cart)
+	}
+
+	result, err = FromHAR(strings.NewReader(har), Options{Frequency: 10, IncludeStatic: true, Hosts: []string{"shop.example.com"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Endpoints) != 2 || result.Endpoints[1].Name != "get_app_js" || result.Endpoints[1].FrequencyPerMin != 10 {
+		t.Errorf("unexpected endpoints with static assets: %+v", result.Endpoints)
+	}
+
+	if _, err := FromHAR(strings.NewReader("not json"), Options{}); err == nil {
+		t.Error("expected error for invalid HAR")
+	}
+}
@@ -0,0 +1,252 @@
+// Package importer converts recorded requests, such as browser HAR files and
+// curl commands, and Postman collections into outgoing endpoints, and
+// endpoints back into Postman collections
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// DefaultTimeout is the timeout in seconds of imported endpoints
+const DefaultTimeout = 30
+
+// Options configures how requests are converted into endpoints
+type Options struct {
+	// Frequency is the requests per minute of each recorded request. Repeated
+	// requests add up. 0 uses the recorded rate for HAR files and 1 for curl.
+	Frequency float64
+	// Hosts keeps only requests to these hostnames (empty keeps all)
+	Hosts []string
+	// KeepCookies keeps Cookie headers, which are usually session-bound
+	KeepCookies bool
+	// IncludeStatic keeps HAR requests for images, stylesheets, scripts,
+	// fonts and media
+	IncludeStatic bool
+	// Tags are added to every imported endpoint
+	Tags []string
+}
+
+// Result holds the imported endpoints and what couldn't be converted
+type Result struct {
+	Endpoints []config.Endpoint
+	Warnings  []string
+	Variables map[string]string // Environment variables the endpoints use, with known values
+}
+
+// request is a recorded request before conversion
+type request struct {
+	method      string
+	url         string
+	headers     [][2]string
+	body        string
+	contentType string
+	timeout     int
+	at          time.Time // Zero if not recorded
+}
+
+// droppedHeaders are set by the HTTP client or bound to the recorded
+// connection, so they are not copied to endpoints
+var droppedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"keep-alive":        true,
+	"proxy-connection":  true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"te":                true,
+	"accept-encoding":   true,
+	"content-type":      true, // Becomes the endpoint's content_type
+}
+
+// validMethods are the methods endpoints support
+var validMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true}
+
+// build converts requests into endpoints. Requests with the same method, URL
+// and body become one endpoint whose frequency adds up.
+func build(requests []request, opts Options, defaultFrequency float64) *Result {
+	result := &Result{}
+	hosts := make(map[string]bool, len(opts.Hosts))
+	for _, host := range opts.Hosts {
+		hosts[strings.ToLower(host)] = true
+	}
+
+	// Recorded rate: occurrences per minute over the recording, at least a minute
+	minutes := 1.0
+	var first, last time.Time
+	for _, req := range requests {
+		if req.at.IsZero() {
+			continue
+		}
+		if first.IsZero() || req.at.Before(first) {
+			first = req.at
+		}
+		if req.at.After(last) {
+			last = req.at
+		}
+	}
+	if span := last.Sub(first).Minutes(); span > minutes {
+		minutes = span
+	}
+	frequency := opts.Frequency
+	if frequency <= 0 {
+		frequency = defaultFrequency
+	}
+	if frequency <= 0 {
+		frequency = 1 / minutes
+	}
+
+	type entry struct {
+		endpoint config.Endpoint
+		count    int
+	}
+	var entries []*entry
+	byKey := make(map[string]*entry)
+	names := make(map[string]bool)
+
+	for _, req := range requests {
+		method := strings.ToUpper(req.method)
+		if !validMethods[method] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s: unsupported method, skipped", req.method, req.url))
+			continue
+		}
+		u, err := url.Parse(req.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s: not an http(s) URL, skipped", method, req.url))
+			continue
+		}
+		if len(hosts) > 0 && !hosts[strings.ToLower(u.Hostname())] {
+			continue
+		}
+		if strings.Contains(req.url, "{{") {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s %s: URL contains template delimiters, skipped", method, req.url))
+			continue
+		}
+
+		key := method + " " + req.url + "\n" + req.body
+		if e, ok := byKey[key]; ok {
+			e.count++
+			continue
+		}
+
+		endpoint := config.Endpoint{
+			Name:        uniqueName(endpointName(method, u), names),
+			Method:      method,
+			URLTemplate: req.url,
+			Auth:        "none",
+			Timeout:     DefaultTimeout,
+			Tags:        opts.Tags,
+			Enabled:     true,
+		}
+		if req.timeout > 0 {
+			endpoint.Timeout = req.timeout
+		}
+		for _, h := range req.headers {
+			name := strings.ToLower(h[0])
+			if strings.HasPrefix(name, ":") || droppedHeaders[name] || (name == "cookie" && !opts.KeepCookies) {
+				continue
+			}
+			if endpoint.Headers == nil {
+				endpoint.Headers = make(map[string]string)
+			}
+			if existing, ok := endpoint.Headers[name]; ok {
+				sep := ", "
+				if name == "cookie" {
+					sep = "; "
+				}
+				endpoint.Headers[name] = existing + sep + h[1]
+			} else {
+				endpoint.Headers[name] = h[1]
+			}
+		}
+		if req.body != "" {
+			if warning := setBody(&endpoint, req.body, req.contentType); warning != "" {
+				result.Warnings = append(result.Warnings, warning)
+			}
+		}
+
+		e := &entry{endpoint: endpoint, count: 1}
+		byKey[key] = e
+		entries = append(entries, e)
+	}
+
+	for _, e := range entries {
+		e.endpoint.FrequencyPerMin = math.Round(frequency*float64(e.count)*100) / 100
+		if e.endpoint.FrequencyPerMin == 0 {
+			e.endpoint.FrequencyPerMin = 0.01
+		}
+		result.Endpoints = append(result.Endpoints, e.endpoint)
+	}
+	return result
+}
+
+// setBody sets a recorded body on an endpoint. Only JSON bodies can be
+// expressed inline; others are reported.
+func setBody(endpoint *config.Endpoint, body, contentType string) string {
+	if endpoint.Method != "POST" && endpoint.Method != "PUT" && endpoint.Method != "PATCH" {
+		return fmt.Sprintf("endpoint %s: %s requests are sent without a body, body dropped", endpoint.Name, endpoint.Method)
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return fmt.Sprintf("endpoint %s: body is not JSON (%s), save it to a file and set body_file", endpoint.Name, contentTypeOrUnknown(contentType))
+	}
+	if strings.Contains(body, "{{") {
+		return fmt.Sprintf("endpoint %s: body contains template delimiters, body dropped", endpoint.Name)
+	}
+	endpoint.Body = parsed
+	if mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0]); contentType != "" && mediaType != "application/json" {
+		endpoint.ContentType = contentType
+	}
+	return ""
+}
+
+func contentTypeOrUnknown(contentType string) string {
+	if contentType == "" {
+		return "unknown content type"
+	}
+	return contentType
+}
+
+// endpointName derives a name such as get_api_users from a request
+func endpointName(method string, u *url.URL) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	inWord := false
+	for _, r := range strings.ToLower(u.Path) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if !inWord {
+				b.WriteByte('_')
+				inWord = true
+			}
+			b.WriteRune(r)
+			continue
+		}
+		inWord = false
+	}
+	name := b.String()
+	if name == strings.ToLower(method) {
+		name += "_root"
+	}
+	if len(name) > 60 {
+		name = strings.TrimRight(name[:60], "_")
+	}
+	return name
+}
+
+// uniqueName appends _2, _3, ... to a name already taken
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
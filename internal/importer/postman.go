@@ -0,0 +1,605 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"moxapp/internal/config"
+)
+
+// PostmanSchema is the schema of exported collections (Postman v2.1)
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// postmanItem is a request or, with Item set, a folder
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item,omitempty"`
+	Auth    *postmanAuth    `json:"auth,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body,omitempty"`
+	Auth   *postmanAuth      `json:"auth,omitempty"`
+}
+
+// postmanURL is a request URL, given as a string or an object with a raw
+// field
+type postmanURL struct {
+	Raw string `json:"raw"`
+}
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &u.Raw)
+	}
+	var obj struct {
+		Raw string `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	u.Raw = obj.Raw
+	return nil
+}
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type postmanBody struct {
+	Mode     string            `json:"mode"`
+	Raw      string            `json:"raw,omitempty"`
+	FormData []postmanKeyValue `json:"formdata,omitempty"`
+	Options  *struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options,omitempty"`
+}
+
+// postmanAuth is a Postman auth definition; its parameters are listed under
+// the key named by Type
+type postmanAuth struct {
+	Type   string             `json:"type"`
+	Bearer []postmanAuthParam `json:"bearer,omitempty"`
+	APIKey []postmanAuthParam `json:"apikey,omitempty"`
+	Basic  []postmanAuthParam `json:"basic,omitempty"`
+	AWSv4  []postmanAuthParam `json:"awsv4,omitempty"`
+}
+
+type postmanAuthParam struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Type  string      `json:"type,omitempty"`
+}
+
+// param returns an auth parameter as a string
+func param(params []postmanAuthParam, key string) string {
+	for _, p := range params {
+		if p.Key == key {
+			if s, ok := p.Value.(string); ok {
+				return s
+			}
+			return fmt.Sprint(p.Value)
+		}
+	}
+	return ""
+}
+
+// postmanVariable matches {{name}} Postman variables
+var postmanVariable = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// postmanDynamic maps Postman dynamic variables to template functions
+var postmanDynamic = map[string]string{
+	"$guid":               "randomUUID",
+	"$randomUUID":         "randomUUID",
+	"$timestamp":          "unixNow",
+	"$isoTimestamp":       "now",
+	"$randomInt":          "randomInt 0 1000",
+	"$randomEmail":        "randomEmail",
+	"$randomPhoneNumber":  "randomPhone",
+	"$randomAlphaNumeric": "randomString 1",
+}
+
+// PostmanEnvironment reads the enabled values of a Postman environment file
+func PostmanEnvironment(r io.Reader) (map[string]string, error) {
+	var env struct {
+		Values []struct {
+			Key     string `json:"key"`
+			Value   string `json:"value"`
+			Enabled *bool  `json:"enabled"`
+		} `json:"values"`
+	}
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman environment: %w", err)
+	}
+	values := make(map[string]string, len(env.Values))
+	for _, v := range env.Values {
+		if v.Enabled == nil || *v.Enabled {
+			values[v.Key] = v.Value
+		}
+	}
+	return values, nil
+}
+
+// postmanImport converts one collection
+type postmanImport struct {
+	opts      Options
+	result    *Result
+	names     map[string]bool
+	variables map[string]string // Collection and environment values
+}
+
+// FromPostman converts the requests of a Postman collection (v2.0 or v2.1)
+// into endpoints. Folders become tags. Variables such as {{baseUrl}} become
+// {{ env "baseUrl" }}; Result.Variables lists them with the values from the
+// collection, overridden by environment (which may be nil).
+func FromPostman(r io.Reader, opts Options, environment map[string]string) (*Result, error) {
+	var collection postmanCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	p := &postmanImport{
+		opts:      opts,
+		result:    &Result{Variables: make(map[string]string)},
+		names:     make(map[string]bool),
+		variables: make(map[string]string),
+	}
+	for _, v := range collection.Variable {
+		if !v.Disabled {
+			p.variables[v.Key] = v.Value
+		}
+	}
+	for k, v := range environment {
+		p.variables[k] = v
+	}
+
+	p.items(collection.Item, nil, collection.Auth)
+	return p.result, nil
+}
+
+// items converts the requests of a folder, recursively
+func (p *postmanImport) items(items []postmanItem, folders []string, auth *postmanAuth) {
+	for _, item := range items {
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+		if item.Request == nil {
+			p.items(item.Item, append(folders[:len(folders):len(folders)], item.Name), itemAuth)
+			continue
+		}
+		if item.Request.Auth != nil {
+			itemAuth = item.Request.Auth
+		}
+		p.request(item.Name, item.Request, folders, itemAuth)
+	}
+}
+
+// request converts one request
+func (p *postmanImport) request(name string, req *postmanRequest, folders []string, auth *postmanAuth) {
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = "GET"
+	}
+	if !validMethods[method] {
+		p.warn("%s: unsupported method %s, skipped", name, req.Method)
+		return
+	}
+	if req.URL.Raw == "" {
+		p.warn("%s: no URL, skipped", name)
+		return
+	}
+
+	endpoint := config.Endpoint{
+		Name:            uniqueName(snakeCase(name, "request"), p.names),
+		Method:          method,
+		URLTemplate:     p.template(req.URL.Raw),
+		FrequencyPerMin: p.opts.Frequency,
+		Auth:            "none",
+		Timeout:         DefaultTimeout,
+		Enabled:         true,
+	}
+	if endpoint.FrequencyPerMin <= 0 {
+		endpoint.FrequencyPerMin = 1
+	}
+	if !strings.Contains(endpoint.URLTemplate, "://") && !strings.HasPrefix(endpoint.URLTemplate, "{{") {
+		endpoint.URLTemplate = "https://" + endpoint.URLTemplate
+	}
+	for _, folder := range folders {
+		endpoint.Tags = append(endpoint.Tags, snakeCase(folder, "folder"))
+	}
+	endpoint.Tags = append(endpoint.Tags, p.opts.Tags...)
+
+	for _, h := range req.Header {
+		key := strings.ToLower(h.Key)
+		if h.Disabled || droppedHeaders[key] || (key == "cookie" && !p.opts.KeepCookies) {
+			if key == "content-type" && !h.Disabled {
+				endpoint.ContentType = p.template(h.Value)
+			}
+			continue
+		}
+		p.setHeader(&endpoint, key, p.template(h.Value))
+	}
+	p.auth(&endpoint, auth)
+	p.body(&endpoint, req.Body)
+	if strings.HasPrefix(endpoint.ContentType, "application/json") && endpoint.Body != nil {
+		endpoint.ContentType = "" // The default for JSON bodies
+	}
+
+	p.result.Endpoints = append(p.result.Endpoints, endpoint)
+}
+
+// auth applies a Postman auth as headers or query params
+func (p *postmanImport) auth(endpoint *config.Endpoint, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+	switch auth.Type {
+	case "noauth", "":
+	case "bearer":
+		p.setHeader(endpoint, "authorization", "Bearer "+p.template(param(auth.Bearer, "token")))
+	case "apikey":
+		key, value := param(auth.APIKey, "key"), p.template(param(auth.APIKey, "value"))
+		if param(auth.APIKey, "in") == "query" {
+			sep := "?"
+			if strings.Contains(endpoint.URLTemplate, "?") {
+				sep = "&"
+			}
+			endpoint.URLTemplate += sep + url.QueryEscape(key) + "=" + value
+		} else {
+			p.setHeader(endpoint, strings.ToLower(key), value)
+		}
+	default:
+		p.warn("%s: %s auth isn't converted; add an auth config and set the endpoint's auth", endpoint.Name, auth.Type)
+	}
+}
+
+// body sets a raw JSON or form-data body
+func (p *postmanImport) body(endpoint *config.Endpoint, body *postmanBody) {
+	if body == nil || body.Mode == "" {
+		return
+	}
+	if endpoint.Method != "POST" && endpoint.Method != "PUT" && endpoint.Method != "PATCH" {
+		p.warn("%s: %s requests are sent without a body, body dropped", endpoint.Name, endpoint.Method)
+		return
+	}
+
+	switch body.Mode {
+	case "raw":
+		if strings.TrimSpace(body.Raw) == "" {
+			return
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(body.Raw), &parsed); err != nil {
+			p.warn("%s: body is not JSON, save it to a file and set body_file", endpoint.Name)
+			return
+		}
+		endpoint.Body = p.templateValue(parsed)
+	case "formdata":
+		for _, field := range body.FormData {
+			if field.Disabled {
+				continue
+			}
+			if field.Type == "file" {
+				p.warn("%s: form-data file %s isn't converted, set its file", endpoint.Name, field.Key)
+				endpoint.Multipart = append(endpoint.Multipart, config.MultipartField{Name: field.Key, File: "CHANGE_ME"})
+				continue
+			}
+			endpoint.Multipart = append(endpoint.Multipart, config.MultipartField{Name: field.Key, Value: p.template(field.Value)})
+		}
+	default:
+		p.warn("%s: %s body isn't converted, save it to a file and set body_file", endpoint.Name, body.Mode)
+	}
+}
+
+// template converts Postman variables in s to template actions
+func (p *postmanImport) template(s string) string {
+	return postmanVariable.ReplaceAllStringFunc(s, func(match string) string {
+		name := postmanVariable.FindStringSubmatch(match)[1]
+		if strings.ContainsAny(name, " \t\"(") {
+			// A template action, e.g. kept by ToPostman
+			return match
+		}
+		if strings.HasPrefix(name, "$") {
+			if fn, ok := postmanDynamic[name]; ok {
+				return "{{ " + fn + " }}"
+			}
+			p.warn("dynamic variable {{%s}} isn't supported, removed", name)
+			return ""
+		}
+		p.result.Variables[name] = p.variables[name]
+		return fmt.Sprintf("{{ env %q }}", name)
+	})
+}
+
+// templateValue converts Postman variables in the strings of a JSON value
+func (p *postmanImport) templateValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return p.template(val)
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = p.templateValue(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = p.templateValue(item)
+		}
+	}
+	return v
+}
+
+func (p *postmanImport) setHeader(endpoint *config.Endpoint, name, value string) {
+	if endpoint.Headers == nil {
+		endpoint.Headers = make(map[string]string)
+	}
+	endpoint.Headers[name] = value
+}
+
+func (p *postmanImport) warn(format string, args ...interface{}) {
+	p.result.Warnings = append(p.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// snakeCase turns a display name such as "Get User (by id)" into
+// get_user_by_id, or fallback if nothing is left
+func snakeCase(s, fallback string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pending && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			pending = false
+			b.WriteRune(r)
+			continue
+		}
+		pending = true
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	name := b.String()
+	if len(name) > 60 {
+		name = strings.TrimRight(name[:60], "_")
+	}
+	return name
+}
+
+// templateAction matches a template action such as {{ env "X" }}
+var templateAction = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+
+// templateDynamic maps template functions to Postman dynamic variables
+var templateDynamic = map[string]string{
+	"randomUUID":   "$guid",
+	"requestID":    "$guid",
+	"now":          "$isoTimestamp",
+	"unixNow":      "$timestamp",
+	"randomInt":    "$randomInt",
+	"randomEmail":  "$randomEmail",
+	"randomPhone":  "$randomPhoneNumber",
+	"randomString": "$randomAlphaNumeric",
+}
+
+// postmanExport converts endpoints to one collection
+type postmanExport struct {
+	variables map[string]bool
+	warnings  []string
+}
+
+// ToPostman converts endpoints into a Postman v2.1 collection. Endpoints of a
+// group are put in a folder named after it. Environment variables become
+// collection variables, without values.
+func ToPostman(name string, endpoints []config.Endpoint) ([]byte, []string, error) {
+	e := &postmanExport{variables: make(map[string]bool)}
+	collection := postmanCollection{Info: postmanInfo{Name: name, Schema: PostmanSchema}}
+
+	folders := make(map[string]int)
+	for _, endpoint := range endpoints {
+		item := e.item(endpoint)
+		if endpoint.Group == "" {
+			collection.Item = append(collection.Item, item)
+			continue
+		}
+		i, ok := folders[endpoint.Group]
+		if !ok {
+			i = len(collection.Item)
+			folders[endpoint.Group] = i
+			collection.Item = append(collection.Item, postmanItem{Name: endpoint.Group, Item: []postmanItem{}})
+		}
+		collection.Item[i].Item = append(collection.Item[i].Item, item)
+	}
+	if collection.Item == nil {
+		collection.Item = []postmanItem{}
+	}
+
+	names := make([]string, 0, len(e.variables))
+	for v := range e.variables {
+		names = append(names, v)
+	}
+	sort.Strings(names)
+	for _, v := range names {
+		collection.Variable = append(collection.Variable, postmanKeyValue{Key: v, Value: "", Type: "string"})
+	}
+
+	data, err := marshalIndent(collection)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, e.warnings, nil
+}
+
+// marshalIndent encodes v as indented JSON without escaping &, < and >,
+// which are common in URLs and templates
+func marshalIndent(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// item converts one endpoint to a request item
+func (e *postmanExport) item(endpoint config.Endpoint) postmanItem {
+	req := &postmanRequest{
+		Method: endpoint.Method,
+		Header: []postmanKeyValue{},
+		URL:    postmanURL{Raw: e.variablesOf(endpoint.Name, endpoint.URLTemplate)},
+	}
+
+	headers := make([]string, 0, len(endpoint.Headers))
+	for h := range endpoint.Headers {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	for _, h := range headers {
+		req.Header = append(req.Header, postmanKeyValue{Key: h, Value: e.variablesOf(endpoint.Name, endpoint.Headers[h])})
+	}
+
+	switch {
+	case len(endpoint.Multipart) > 0:
+		req.Body = &postmanBody{Mode: "formdata"}
+		for _, field := range endpoint.Multipart {
+			if field.File != "" {
+				req.Body.FormData = append(req.Body.FormData, postmanKeyValue{Key: field.Name, Type: "file"})
+				e.warn("%s: form-data file %s must be selected in Postman", endpoint.Name, field.Name)
+				continue
+			}
+			req.Body.FormData = append(req.Body.FormData, postmanKeyValue{Key: field.Name, Value: e.variablesOf(endpoint.Name, field.Value), Type: "text"})
+		}
+	case endpoint.BodyFile != "":
+		e.warn("%s: body_file %s isn't exported", endpoint.Name, endpoint.BodyFile)
+	case endpoint.Body != nil:
+		data, err := marshalIndent(endpoint.Body)
+		if err != nil {
+			e.warn("%s: body can't be exported: %v", endpoint.Name, err)
+			break
+		}
+		req.Body = &postmanBody{Mode: "raw", Raw: e.variablesOf(endpoint.Name, strings.TrimSuffix(string(data), "\n"))}
+		req.Body.Options = &struct {
+			Raw struct {
+				Language string `json:"language"`
+			} `json:"raw"`
+		}{}
+		req.Body.Options.Raw.Language = "json"
+	}
+	if endpoint.ContentType != "" {
+		req.Header = append(req.Header, postmanKeyValue{Key: "Content-Type", Value: endpoint.ContentType})
+	}
+
+	req.Auth = e.auth(endpoint, req)
+	return postmanItem{Name: endpoint.Name, Request: req}
+}
+
+// auth converts an endpoint's resolved auth config
+func (e *postmanExport) auth(endpoint config.Endpoint, req *postmanRequest) *postmanAuth {
+	auth := endpoint.ResolvedAuth
+	if auth == nil || auth.Type == config.AuthTypeNone {
+		return nil
+	}
+	// Env var names are redacted in the config export of instances run
+	// without --include-secrets; a variable named after the field is used then
+	variable := func(env, field string) string {
+		if env == "" {
+			return ""
+		}
+		if env == config.RedactedValue {
+			env = auth.Name + "_" + field
+			e.warn("%s: %s of %s is redacted, set {{%s}}", endpoint.Name, field, auth.Name, env)
+		}
+		e.variables[env] = true
+		return "{{" + env + "}}"
+	}
+	str := func(key, value string) postmanAuthParam {
+		return postmanAuthParam{Key: key, Value: value, Type: "string"}
+	}
+
+	switch auth.Type {
+	case config.AuthTypeBearer:
+		token := variable(auth.EnvVar, "token")
+		if auth.TokenEndpoint != nil || token == "" {
+			token = variable(auth.Name+"_token", "token")
+			e.warn("%s: token endpoint of %s isn't exported, set {{%s_token}}", endpoint.Name, auth.Name, auth.Name)
+		}
+		return &postmanAuth{Type: "bearer", Bearer: []postmanAuthParam{str("token", token)}}
+	case config.AuthTypeAPIKey:
+		return &postmanAuth{Type: "apikey", APIKey: []postmanAuthParam{str("key", auth.HeaderName), str("value", variable(auth.EnvVar, "key")), str("in", "header")}}
+	case config.AuthTypeAPIKeyQuery:
+		return &postmanAuth{Type: "apikey", APIKey: []postmanAuthParam{str("key", auth.QueryParam), str("value", variable(auth.EnvVar, "key")), str("in", "query")}}
+	case config.AuthTypeBasic:
+		return &postmanAuth{Type: "basic", Basic: []postmanAuthParam{str("username", variable(auth.UsernameEnv, "username")), str("password", variable(auth.PasswordEnv, "password"))}}
+	case config.AuthTypeCustom:
+		req.Header = append(req.Header, postmanKeyValue{Key: auth.HeaderName, Value: variable(auth.EnvVar, "value")})
+		return nil
+	case config.AuthTypeAWSSigV4:
+		accessKey, secretKey := auth.AccessKeyEnv, auth.SecretKeyEnv
+		if accessKey == "" {
+			accessKey = config.DefaultAWSAccessKeyEnv
+		}
+		if secretKey == "" {
+			secretKey = config.DefaultAWSSecretKeyEnv
+		}
+		return &postmanAuth{Type: "awsv4", AWSv4: []postmanAuthParam{
+			str("accessKey", variable(accessKey, "access_key")), str("secretKey", variable(secretKey, "secret_key")),
+			str("region", auth.Region), str("service", auth.Service),
+		}}
+	default:
+		e.warn("%s: %s auth isn't exported", endpoint.Name, auth.Type)
+		return nil
+	}
+}
+
+// variablesOf converts template actions in s to Postman variables
+func (e *postmanExport) variablesOf(endpoint, s string) string {
+	return templateAction.ReplaceAllStringFunc(s, func(match string) string {
+		fields := strings.Fields(templateAction.FindStringSubmatch(match)[1])
+		switch {
+		case len(fields) == 1 && strings.HasPrefix(fields[0], ".Env."):
+			name := strings.TrimPrefix(fields[0], ".Env.")
+			e.variables[name] = true
+			return "{{" + name + "}}"
+		case len(fields) >= 2 && (fields[0] == "env" || fields[0] == "envDefault") && strings.HasPrefix(fields[1], `"`):
+			name := strings.Trim(fields[1], `"`)
+			e.variables[name] = true
+			return "{{" + name + "}}"
+		case len(fields) > 0 && templateDynamic[fields[0]] != "":
+			return "{{" + templateDynamic[fields[0]] + "}}"
+		}
+		e.warn("%s: template %s has no Postman equivalent, kept as is", endpoint, match)
+		return match
+	})
+}
+
+func (e *postmanExport) warn(format string, args ...interface{}) {
+	e.warnings = append(e.warnings, fmt.Sprintf(format, args...))
+}
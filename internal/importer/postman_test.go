@@ -0,0 +1,116 @@
+package importer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"moxapp/internal/config"
+)
+
+func TestFromPostman(t *testing.T) {
+	collection := `{
+  "info": {"name": "Shop", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "https://shop.example.com"}],
+  "item": [
+    {"name": "Orders", "item": [
+      {"name": "Create Order", "request": {
+        "method": "POST",
+        "header": [{"key": "Content-Type", "value": "application/json"}, {"key": "X-Trace", "value": "{{$guid}}"}, {"key": "X-Old", "value": "1", "disabled": true}],
+        "url": {"raw": "{{baseUrl}}/orders", "host": ["{{baseUrl}}"], "path": ["orders"]},
+        "body": {"mode": "raw", "raw": "{\"sku\": \"{{sku}}\", \"qty\": 1}", "options": {"raw": {"language": "json"}}}
+      }}
+    ]},
+    {"name": "Health", "auth": {"type": "noauth"}, "request": {"method": "GET", "url": "{{baseUrl}}/health"}},
+    {"name": "Legacy", "request": {"method": "POST", "header": [], "url": "{{baseUrl}}/legacy", "auth": {"type": "digest"},
+      "body": {"mode": "urlencoded", "urlencoded": [{"key": "a", "value": "1"}]}}}
+  ]
+}`
+
+	result, err := FromPostman(strings.NewReader(collection), Options{}, map[string]string{"sku": "sku-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Endpoints) != 3 {
+		t.Fatalf("expected 3 endpoints, got %d", len(result.Endpoints))
+	}
+
+	create := result.Endpoints[0]
+	if create.Name != "create_order" || create.URLTemplate != `{{ env "baseUrl" }}/orders` || create.Tags[0] != "orders" {
+		t.Errorf("unexpected create endpoint: %+v", create)
+	}
+	if create.Headers["authorization"] != `Bearer {{ env "token" }}` || create.Headers["x-trace"] != "{{ randomUUID }}" || create.Headers["x-old"] != "" {
+		t.Errorf("unexpected create headers: %v", create.Headers)
+	}
+	body, _ := create.Body.(map[string]interface{})
+	if body["sku"] != `{{ env "sku" }}` || create.ContentType != "" {
+		t.Errorf("unexpected create body: %v (%s)", create.Body, create.ContentType)
+	}
+
+	if health := result.Endpoints[1]; health.Headers["authorization"] != "" {
+		t.Errorf("expected no auth for health, got %v", health.Headers)
+	}
+
+	want := map[string]string{"baseUrl": "https://shop.example.com", "token": "", "sku": "sku-1"}
+	for k, v := range want {
+		if got, ok := result.Variables[k]; !ok || got != v {
+			t.Errorf("variable %s: expected %q, got %q", k, v, got)
+		}
+	}
+	// Digest auth and the urlencoded body
+	if len(result.Warnings) != 2 {
+		t.Errorf("expected 2 warnings, got %v", result.Warnings)
+	}
+}
+
+func TestToPostman(t *testing.T) {
+	endpoints := []config.Endpoint{
+		{
+			Name: "create_order", Method: "POST", Group: "checkout",
+			URLTemplate:  "{{ .Env.BASE_URL }}/orders",
+			Headers:      map[string]string{"x-trace": "{{ randomUUID }}"},
+			Body:         map[string]interface{}{"id": "{{ randomUUID }}"},
+			ResolvedAuth: &config.AuthConfig{Name: "key", Type: config.AuthTypeAPIKey, HeaderName: "x-api-key", EnvVar: "API_KEY"},
+		},
+		{
+			Name: "search", Method: "GET",
+			URLTemplate: `{{ env "BASE_URL" }}/search?q={{ urlEncode (randomString 6) }}`,
+		},
+	}
+
+	data, warnings, err := ToPostman("Shop", endpoints)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "urlEncode") {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		t.Fatalf("invalid collection: %v", err)
+	}
+	if len(collection.Item) != 2 || collection.Item[0].Name != "checkout" || len(collection.Item[0].Item) != 1 {
+		t.Fatalf("unexpected items: %+v", collection.Item)
+	}
+	req := collection.Item[0].Item[0].Request
+	if req.URL.Raw != "{{BASE_URL}}/orders" || req.Header[0].Value != "{{$guid}}" || req.Auth.Type != "apikey" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if !strings.Contains(req.Body.Raw, `"id": "{{$guid}}"`) {
+		t.Errorf("unexpected body: %s", req.Body.Raw)
+	}
+	if len(collection.Variable) != 2 || collection.Variable[0].Key != "API_KEY" || collection.Variable[1].Key != "BASE_URL" {
+		t.Errorf("unexpected variables: %+v", collection.Variable)
+	}
+
+	// Round trip
+	result, err := FromPostman(strings.NewReader(string(data)), Options{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Endpoints) != 2 || result.Endpoints[0].URLTemplate != `{{ env "BASE_URL" }}/orders` || result.Endpoints[0].Headers["x-api-key"] != `{{ env "API_KEY" }}` {
+		t.Errorf("unexpected round trip: %+v", result.Endpoints)
+	}
+}
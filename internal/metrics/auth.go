@@ -0,0 +1,140 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"moxapp/internal/client"
+)
+
+// AuthMetrics holds token endpoint metrics for a single auth config
+type AuthMetrics struct {
+	refreshes     int64
+	failures      int64
+	refreshGrants int64 // Calls using the refresh_token grant
+
+	totalLatencyMs float64
+	latencies      *RingBuffer
+
+	lastRefreshAt time.Time
+	lastError     string
+	lastErrorAt   time.Time
+
+	mu sync.Mutex
+}
+
+// NewAuthMetrics creates new auth metrics
+func NewAuthMetrics() *AuthMetrics {
+	return &AuthMetrics{
+		latencies: NewRingBuffer(1000),
+	}
+}
+
+// Record records a single token endpoint call
+func (m *AuthMetrics) Record(fetch client.TokenFetch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	latencyMs := float64(fetch.Duration.Microseconds()) / 1000
+	m.refreshes++
+	m.totalLatencyMs += latencyMs
+	m.latencies.Add(latencyMs)
+	m.lastRefreshAt = time.Now()
+
+	if fetch.Grant == client.GrantRefreshToken {
+		m.refreshGrants++
+	}
+	if fetch.Err != nil {
+		m.failures++
+		m.lastError = fetch.Err.Error()
+		m.lastErrorAt = m.lastRefreshAt
+	}
+}
+
+// GetStats returns a snapshot of the auth metrics
+func (m *AuthMetrics) GetStats() AuthSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snap := AuthSnapshot{
+		Refreshes:          m.refreshes,
+		Failures:           m.failures,
+		RefreshTokenGrants: m.refreshGrants,
+		P95LatencyMs:       m.latencies.Percentile(95),
+		MaxLatencyMs:       m.latencies.Max(),
+		LastError:          m.lastError,
+	}
+
+	if m.refreshes > 0 {
+		snap.AvgLatencyMs = m.totalLatencyMs / float64(m.refreshes)
+		snap.SuccessRate = float64(m.refreshes-m.failures) / float64(m.refreshes) * 100
+	}
+	if !m.lastRefreshAt.IsZero() {
+		snap.LastRefreshAt = m.lastRefreshAt.Format(time.RFC3339)
+	}
+	if !m.lastErrorAt.IsZero() {
+		snap.LastErrorAt = m.lastErrorAt.Format(time.RFC3339)
+	}
+
+	return snap
+}
+
+// AuthSnapshot is a serializable snapshot of token endpoint metrics for one auth config
+type AuthSnapshot struct {
+	Refreshes          int64   `json:"refreshes"`
+	Failures           int64   `json:"failures"`
+	SuccessRate        float64 `json:"success_rate"`
+	RefreshTokenGrants int64   `json:"refresh_token_grants"`
+	AvgLatencyMs       float64 `json:"avg_latency_ms"`
+	P95LatencyMs       float64 `json:"p95_latency_ms"`
+	MaxLatencyMs       float64 `json:"max_latency_ms"`
+	LastRefreshAt      string  `json:"last_refresh_at,omitempty"`
+	LastError          string  `json:"last_error,omitempty"`
+	LastErrorAt        string  `json:"last_error_at,omitempty"`
+}
+
+// AuthCollector collects token endpoint metrics per auth config
+type AuthCollector struct {
+	auths map[string]*AuthMetrics
+	mu    sync.RWMutex
+}
+
+// NewAuthCollector creates a new auth metrics collector
+func NewAuthCollector() *AuthCollector {
+	return &AuthCollector{
+		auths: make(map[string]*AuthMetrics),
+	}
+}
+
+// Record records a token endpoint call; pass it to TokenManager.SetFetchObserver
+func (c *AuthCollector) Record(fetch client.TokenFetch) {
+	c.mu.Lock()
+	m, exists := c.auths[fetch.AuthName]
+	if !exists {
+		m = NewAuthMetrics()
+		c.auths[fetch.AuthName] = m
+	}
+	c.mu.Unlock()
+
+	m.Record(fetch)
+}
+
+// Snapshot returns auth metrics keyed by auth config name
+func (c *AuthCollector) Snapshot() map[string]AuthSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	auths := make(map[string]AuthSnapshot, len(c.auths))
+	for name, m := range c.auths {
+		auths[name] = m.GetStats()
+	}
+	return auths
+}
+
+// Reset clears all auth metrics
+func (c *AuthCollector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auths = make(map[string]*AuthMetrics)
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+)
+
+func TestAuthCollector_RecordsPerAuthConfig(t *testing.T) {
+	collector := NewAuthCollector()
+
+	collector.Record(client.TokenFetch{AuthName: "oauth", Grant: client.GrantInitial, Duration: 100 * time.Millisecond})
+	collector.Record(client.TokenFetch{AuthName: "oauth", Grant: client.GrantRefreshToken, Duration: 300 * time.Millisecond, Err: errors.New("invalid_grant")})
+	collector.Record(client.TokenFetch{AuthName: "other", Grant: client.GrantInitial, Duration: 50 * time.Millisecond})
+
+	snap := collector.Snapshot()
+	oauth := snap["oauth"]
+	if oauth.Refreshes != 2 || oauth.Failures != 1 || oauth.RefreshTokenGrants != 1 {
+		t.Errorf("unexpected counts: %+v", oauth)
+	}
+	if oauth.AvgLatencyMs != 200 || oauth.MaxLatencyMs != 300 {
+		t.Errorf("expected avg 200ms / max 300ms, got %v / %v", oauth.AvgLatencyMs, oauth.MaxLatencyMs)
+	}
+	if oauth.SuccessRate != 50 || oauth.LastError != "invalid_grant" {
+		t.Errorf("unexpected success rate or last error: %+v", oauth)
+	}
+	if snap["other"].Refreshes != 1 {
+		t.Errorf("expected 1 refresh for other, got %d", snap["other"].Refreshes)
+	}
+
+	collector.Reset()
+	if len(collector.Snapshot()) != 0 {
+		t.Error("expected reset to clear auth metrics")
+	}
+}
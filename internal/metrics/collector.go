@@ -2,11 +2,13 @@
 package metrics
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"moxapp/internal/client"
+	"moxapp/internal/resources"
 )
 
 // Collector collects and aggregates metrics from all requests
@@ -15,9 +17,21 @@ type Collector struct {
 	totalRequests  int64
 	totalSuccesses int64
 	totalFailures  int64
+	changes        int64 // Counts recordings and resets
 
 	endpoints map[string]*EndpointMetrics
+	hosts     map[string]*EndpointMetrics // Outgoing metrics of all endpoints per hostname
 	domains   map[string]*DomainMetrics
+	dnsProbes map[string]*DNSProbeMetrics
+	tls       map[string]*hostTLS // TLS handshakes per hostname
+
+	// Snapshot of a previous run used by compare mode (kept across resets)
+	baseline *MetricsSnapshot
+
+	labels      LabelSource      // Run labels attached to snapshots
+	percentiles PercentileSource // Latency percentiles reported by snapshots
+
+	tlsExpiryWarningDays DaysSource // Certificates expiring sooner are warned about
 
 	mu sync.RWMutex
 }
@@ -27,7 +41,10 @@ func NewCollector() *Collector {
 	return &Collector{
 		startTime: time.Now(),
 		endpoints: make(map[string]*EndpointMetrics),
+		hosts:     make(map[string]*EndpointMetrics),
 		domains:   make(map[string]*DomainMetrics),
+		dnsProbes: make(map[string]*DNSProbeMetrics),
+		tls:       make(map[string]*hostTLS),
 	}
 }
 
@@ -37,6 +54,7 @@ func (c *Collector) Record(result *client.RequestResult) {
 	defer c.mu.Unlock()
 
 	// Update global counters
+	atomic.AddInt64(&c.changes, 1)
 	atomic.AddInt64(&c.totalRequests, 1)
 	if result.Success {
 		atomic.AddInt64(&c.totalSuccesses, 1)
@@ -51,34 +69,120 @@ func (c *Collector) Record(result *client.RequestResult) {
 		c.endpoints[result.EndpointName] = ep
 	}
 
-	// Update endpoint metrics
-	if result.Success {
-		ep.RecordSuccess(result.TotalTimeMs, result.DNSTimeMs, result.ConnectTimeMs, result.StatusCode)
-	} else {
-		ep.RecordFailure(result.TotalTimeMs, result.DNSTimeMs, result.ConnectTimeMs, result.StatusCode, result.ErrorType, result.Error)
+	recordRequest(ep, result)
+
+	// Aggregate per hostname, for endpoints sharing a service
+	if result.Hostname != "" {
+		host, exists := c.hosts[result.Hostname]
+		if !exists {
+			host = NewEndpointMetrics("", result.Hostname)
+			c.hosts[result.Hostname] = host
+		}
+		recordRequest(host, result)
+	}
+
+	if result.TLS != nil {
+		hostname := result.TLS.ServerName
+		if hostname == "" {
+			hostname = result.Hostname
+		}
+		c.recordTLS(hostname, result.TLS)
 	}
 
 	// Update domain metrics only when we actually performed DNS work
 	if result.Hostname != "" {
 		// DNS success if we got a positive DNS time and no DNS error
 		if result.DNSTimeMs > 0 && result.ErrorType != "dns" {
-			domain, exists := c.domains[result.Hostname]
-			if !exists {
-				domain = NewDomainMetrics()
-				c.domains[result.Hostname] = domain
-			}
-			domain.RecordSuccess(result.DNSTimeMs)
+			domain := c.getDomain(result.Hostname)
+			domain.RecordSuccess(result.DNSTimeMs, result.AddressFamily)
 		} else if result.ErrorType == "dns" {
-			domain, exists := c.domains[result.Hostname]
-			if !exists {
-				domain = NewDomainMetrics()
-				c.domains[result.Hostname] = domain
+			domain := c.getDomain(result.Hostname)
+			domain.RecordFailure(result.DNSError, result.Error)
+		}
+
+		// Track DNS answers and request distribution per resolved IP
+		remoteIP := result.RemoteIP()
+		if len(result.ResolvedIPs) > 0 || remoteIP != "" {
+			domain := c.getDomain(result.Hostname)
+			if len(result.ResolvedIPs) > 0 {
+				domain.RecordAnswers(result.ResolvedIPs)
+			}
+			if remoteIP != "" {
+				domain.RecordIPRequest(remoteIP, result.Success)
+			}
+		}
+
+		// Track dials per address, and fallbacks from one address to another
+		if len(result.ConnectAttempts) > 0 {
+			domain := c.getDomain(result.Hostname)
+			addrs := make(map[string]bool, len(result.ConnectAttempts))
+			for _, attempt := range result.ConnectAttempts {
+				domain.RecordConnectAttempt(attempt.IP(), attempt.DurationMs, attempt.Error != "")
+				addrs[attempt.Addr] = true
+			}
+			if len(addrs) > 1 {
+				domain.RecordConnectFallback()
 			}
-			domain.RecordFailure(result.Error)
 		}
 	}
 }
 
+// recordRequest records the result of a request into endpoint or hostname metrics
+func recordRequest(ep *EndpointMetrics, result *client.RequestResult) {
+	if result.Success {
+		ep.RecordSuccess(result.TotalTimeMs, result.DNSTimeMs, result.ConnectTimeMs, result.StatusCode)
+	} else {
+		ep.RecordFailure(result.TotalTimeMs, result.DNSTimeMs, result.ConnectTimeMs, result.StatusCode, result.ErrorType, result.Error)
+		ep.RecordErrorSample(result.ErrorType, result.Error, result.StatusCode, result.URL)
+	}
+	ep.RecordPhases(result.TLSTimeMs, result.TimeToFirstByte)
+	if result.Connection != "" && result.Success {
+		ep.RecordConnection(result.Connection, result.TotalTimeMs)
+	}
+	if result.Apdex != "" {
+		ep.RecordApdex(result.Apdex)
+	}
+	if result.SLOBreach {
+		ep.RecordSLOBreach()
+	}
+	if result.AddressFamily != "" {
+		ep.RecordAddressFamily(result.AddressFamily)
+	}
+	if result.RequestSize > 0 {
+		ep.RecordRequestSize(result.RequestSize)
+	}
+	if result.StatusCode > 0 {
+		decodedSize, decoded := result.UncompressedSize()
+		ep.RecordResponseSize(result.ResponseSize, decodedSize, decoded, result.ContentEncoding)
+	}
+}
+
+// RecordDNSProbe records the result of a standalone DNS probe query
+func (c *Collector) RecordDNSProbe(result *DNSProbeResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	atomic.AddInt64(&c.changes, 1)
+	probe, exists := c.dnsProbes[result.Hostname]
+	if !exists {
+		probe = NewDNSProbeMetrics()
+		c.dnsProbes[result.Hostname] = probe
+	}
+	probe.Record(result)
+}
+
+// DNSProbeSnapshot returns probe metrics keyed by hostname
+func (c *Collector) DNSProbeSnapshot() map[string]DNSProbeSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	probes := make(map[string]DNSProbeSnapshot, len(c.dnsProbes))
+	for hostname, probe := range c.dnsProbes {
+		probes[hostname] = probe.GetStats()
+	}
+	return probes
+}
+
 // Snapshot returns a serializable snapshot of all metrics
 func (c *Collector) Snapshot() *MetricsSnapshot {
 	c.mu.RLock()
@@ -94,6 +198,7 @@ func (c *Collector) Snapshot() *MetricsSnapshot {
 		Endpoints:        make(map[string]EndpointSnapshot),
 		DNSStatsByDomain: make(map[string]DomainSnapshot),
 		CollectedAt:      time.Now().Format(time.RFC3339),
+		Labels:           c.labels.get(),
 	}
 
 	// Calculate rates
@@ -105,29 +210,148 @@ func (c *Collector) Snapshot() *MetricsSnapshot {
 	}
 
 	// Collect endpoint metrics
+	percentiles := c.percentiles.get()
 	for name, ep := range c.endpoints {
-		snapshot.Endpoints[name] = ep.GetStats()
+		snapshot.Endpoints[name] = ep.GetStats(percentiles...)
 	}
 
 	// Collect domain metrics
 	for hostname, domain := range c.domains {
 		snapshot.DNSStatsByDomain[hostname] = domain.GetStats()
 	}
+	snapshot.TLSByHost, snapshot.TLSWarnings = c.tlsSnapshot(time.Now())
 
 	return snapshot
 }
 
+// WindowSnapshot returns a snapshot of the requests within the last window
+// (see Windows). Totals, success rate and RPS cover the window, or the uptime
+// if shorter; DNS stats by domain stay since start.
+func (c *Collector) WindowSnapshot(label string) (*MetricsSnapshot, error) {
+	window, err := ParseWindow(label)
+	if err != nil {
+		return nil, err
+	}
+	if window == 0 {
+		return c.Snapshot(), nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	uptime := time.Since(c.startTime).Seconds()
+	snapshot := &MetricsSnapshot{
+		UptimeSeconds:    uptime,
+		Window:           label,
+		Endpoints:        make(map[string]EndpointSnapshot),
+		DNSStatsByDomain: make(map[string]DomainSnapshot),
+		CollectedAt:      time.Now().Format(time.RFC3339),
+		Labels:           c.labels.get(),
+	}
+
+	percentiles := c.percentiles.get()
+	for name, ep := range c.endpoints {
+		stats := ep.GetWindowStats(window, percentiles...)
+		snapshot.Endpoints[name] = stats
+		snapshot.TotalRequests += stats.TotalRequests
+		snapshot.TotalSuccesses += stats.Successful
+		snapshot.TotalFailures += stats.Failed
+	}
+
+	if elapsed := min(window.Seconds(), uptime); elapsed > 0 {
+		snapshot.RequestsPerSecond = float64(snapshot.TotalRequests) / elapsed
+	}
+	if snapshot.TotalRequests > 0 {
+		snapshot.SuccessRate = float64(snapshot.TotalSuccesses) / float64(snapshot.TotalRequests) * 100
+	}
+
+	for hostname, domain := range c.domains {
+		snapshot.DNSStatsByDomain[hostname] = domain.GetStats()
+	}
+	snapshot.TLSByHost, snapshot.TLSWarnings = c.tlsSnapshot(time.Now())
+
+	return snapshot, nil
+}
+
+// EndpointStats returns the snapshot of one endpoint, since start or within
+// the last window (see Windows)
+func (c *Collector) EndpointStats(endpoint, window string) (EndpointSnapshot, error) {
+	duration, err := ParseWindow(window)
+	if err != nil {
+		return EndpointSnapshot{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ep, exists := c.endpoints[endpoint]
+	if !exists {
+		return EndpointSnapshot{}, fmt.Errorf("no metrics for endpoint: %s", endpoint)
+	}
+	if duration == 0 {
+		return ep.GetStats(c.percentiles.get()...), nil
+	}
+	return ep.GetWindowStats(duration, c.percentiles.get()...), nil
+}
+
 // Reset resets all metrics
 func (c *Collector) Reset() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.startTime = time.Now()
+	atomic.AddInt64(&c.changes, 1)
 	atomic.StoreInt64(&c.totalRequests, 0)
 	atomic.StoreInt64(&c.totalSuccesses, 0)
 	atomic.StoreInt64(&c.totalFailures, 0)
 	c.endpoints = make(map[string]*EndpointMetrics)
+	c.hosts = make(map[string]*EndpointMetrics)
 	c.domains = make(map[string]*DomainMetrics)
+	c.dnsProbes = make(map[string]*DNSProbeMetrics)
+	c.tls = make(map[string]*hostTLS)
+}
+
+// SetBaseline sets the snapshot current metrics are compared against (nil clears it)
+func (c *Collector) SetBaseline(baseline *MetricsSnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseline = baseline
+}
+
+// SetLabelSource sets the function returning the run labels attached to snapshots
+func (c *Collector) SetLabelSource(labels LabelSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = labels
+}
+
+// SetPercentileSource sets the function returning the latency percentiles
+// reported by snapshots
+func (c *Collector) SetPercentileSource(percentiles PercentileSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.percentiles = percentiles
+}
+
+// SetTLSExpiryWarningSource sets the function returning how many days before
+// expiry certificates are warned about
+func (c *Collector) SetTLSExpiryWarningSource(days DaysSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tlsExpiryWarningDays = days
+}
+
+// Baseline returns the snapshot current metrics are compared against, or nil
+func (c *Collector) Baseline() *MetricsSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baseline
+}
+
+// Changes returns a counter that increases whenever metrics are recorded or
+// reset, so callers can tell whether anything changed since they last looked
+func (c *Collector) Changes() int64 {
+	return atomic.LoadInt64(&c.changes)
 }
 
 // GetTotalRequests returns the total number of requests
@@ -154,6 +378,16 @@ func (c *Collector) GetRequestsPerSecond() float64 {
 	return float64(atomic.LoadInt64(&c.totalRequests)) / uptime
 }
 
+// getDomain returns the metrics for a hostname, creating them if needed (caller holds lock)
+func (c *Collector) getDomain(hostname string) *DomainMetrics {
+	domain, exists := c.domains[hostname]
+	if !exists {
+		domain = NewDomainMetrics()
+		c.domains[hostname] = domain
+	}
+	return domain
+}
+
 // MetricsSnapshot is a serializable snapshot of all metrics
 type MetricsSnapshot struct {
 	UptimeSeconds     float64                     `json:"uptime_seconds"`
@@ -163,6 +397,31 @@ type MetricsSnapshot struct {
 	SuccessRate       float64                     `json:"success_rate"`
 	RequestsPerSecond float64                     `json:"requests_per_second"`
 	CollectedAt       string                      `json:"collected_at"`
+	Window            string                      `json:"window,omitempty"` // Empty for since-start metrics
+	Labels            map[string]string           `json:"labels,omitempty"` // Run labels, such as env or branch
 	Endpoints         map[string]EndpointSnapshot `json:"endpoints"`
 	DNSStatsByDomain  map[string]DomainSnapshot   `json:"dns_stats_by_domain"`
+
+	// TLS version, cipher suite and certificate per hostname, and the
+	// certificates expiring soon (since start, also in windowed snapshots)
+	TLSByHost   map[string]TLSHostSnapshot `json:"tls_by_host,omitempty"`
+	TLSWarnings []TLSWarning               `json:"tls_warnings,omitempty"`
+
+	// Current requests in flight per hostname, filled in from the scheduler
+	InFlightByHost map[string]HostInFlightSnapshot `json:"in_flight_by_host,omitempty"`
+
+	// Requests in flight and their peak, filled in from the scheduler
+	InFlight    int64 `json:"in_flight,omitempty"`
+	MaxInFlight int64 `json:"max_in_flight,omitempty"`
+
+	// Resource usage of the generator itself, filled in from the resource
+	// monitor, to tell generator-side saturation from a slow target
+	Process *resources.Process `json:"process,omitempty"`
+}
+
+// HostInFlightSnapshot is the in-flight gauge of one hostname
+type HostInFlightSnapshot struct {
+	InFlight int   `json:"in_flight"`       // Queued and running requests
+	Limit    int   `json:"limit,omitempty"` // host_limits cap, 0 if unlimited
+	Capped   int64 `json:"capped"`          // Not sent because the hostname was at its cap
 }
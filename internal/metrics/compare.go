@@ -0,0 +1,188 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Default thresholds used to flag an endpoint as regressed
+const (
+	DefaultLatencyRegressionPct = 10.0 // p95 latency increase, in percent
+	DefaultErrorRateRegression  = 1.0  // error rate increase, in percentage points
+)
+
+// CompareOptions controls when an endpoint is reported as regressed
+type CompareOptions struct {
+	LatencyThresholdPct float64 `json:"latency_threshold_pct"`
+	ErrorRateThreshold  float64 `json:"error_rate_threshold"`
+}
+
+// DefaultCompareOptions returns the default regression thresholds
+func DefaultCompareOptions() CompareOptions {
+	return CompareOptions{
+		LatencyThresholdPct: DefaultLatencyRegressionPct,
+		ErrorRateThreshold:  DefaultErrorRateRegression,
+	}
+}
+
+// Comparison holds per-endpoint deltas between a baseline and a current snapshot
+type Comparison struct {
+	BaselineCollectedAt string                        `json:"baseline_collected_at"`
+	CurrentCollectedAt  string                        `json:"current_collected_at"`
+	Options             CompareOptions                `json:"options"`
+	Summary             ComparisonSummary             `json:"summary"`
+	Endpoints           map[string]EndpointComparison `json:"endpoints"`
+	OnlyInBaseline      []string                      `json:"only_in_baseline"`
+	OnlyInCurrent       []string                      `json:"only_in_current"`
+}
+
+// ComparisonSummary holds overall deltas between two snapshots
+type ComparisonSummary struct {
+	BaselineRequests   int64    `json:"baseline_requests"`
+	CurrentRequests    int64    `json:"current_requests"`
+	BaselineErrorRate  float64  `json:"baseline_error_rate"`
+	CurrentErrorRate   float64  `json:"current_error_rate"`
+	ErrorRateChange    float64  `json:"error_rate_change"`
+	ComparedEndpoints  int      `json:"compared_endpoints"`
+	RegressedEndpoints []string `json:"regressed_endpoints"`
+}
+
+// EndpointComparison holds deltas for a single endpoint. Latency changes are
+// relative (percent), error rate change is absolute (percentage points).
+type EndpointComparison struct {
+	BaselineRequests int64 `json:"baseline_requests"`
+	CurrentRequests  int64 `json:"current_requests"`
+
+	BaselineAvgMs       float64 `json:"baseline_avg_ms"`
+	CurrentAvgMs        float64 `json:"current_avg_ms"`
+	AvgLatencyChangePct float64 `json:"avg_latency_change_pct"`
+	BaselineP95Ms       float64 `json:"baseline_p95_ms"`
+	CurrentP95Ms        float64 `json:"current_p95_ms"`
+	P95LatencyChangePct float64 `json:"p95_latency_change_pct"`
+	BaselineP99Ms       float64 `json:"baseline_p99_ms"`
+	CurrentP99Ms        float64 `json:"current_p99_ms"`
+	P99LatencyChangePct float64 `json:"p99_latency_change_pct"`
+
+	BaselineErrorRate float64 `json:"baseline_error_rate"`
+	CurrentErrorRate  float64 `json:"current_error_rate"`
+	ErrorRateChange   float64 `json:"error_rate_change"`
+
+	Regressed bool `json:"regressed"`
+}
+
+// Compare computes per-endpoint deltas of current against baseline
+func Compare(baseline, current *MetricsSnapshot, opts CompareOptions) *Comparison {
+	cmp := &Comparison{
+		BaselineCollectedAt: baseline.CollectedAt,
+		CurrentCollectedAt:  current.CollectedAt,
+		Options:             opts,
+		Endpoints:           make(map[string]EndpointComparison),
+		OnlyInBaseline:      []string{},
+		OnlyInCurrent:       []string{},
+	}
+
+	cmp.Summary = ComparisonSummary{
+		BaselineRequests:   baseline.TotalRequests,
+		CurrentRequests:    current.TotalRequests,
+		BaselineErrorRate:  errorRate(baseline.TotalFailures, baseline.TotalRequests),
+		CurrentErrorRate:   errorRate(current.TotalFailures, current.TotalRequests),
+		RegressedEndpoints: []string{},
+	}
+	cmp.Summary.ErrorRateChange = cmp.Summary.CurrentErrorRate - cmp.Summary.BaselineErrorRate
+
+	for name, base := range baseline.Endpoints {
+		cur, exists := current.Endpoints[name]
+		if !exists {
+			cmp.OnlyInBaseline = append(cmp.OnlyInBaseline, name)
+			continue
+		}
+
+		ec := EndpointComparison{
+			BaselineRequests:    base.TotalRequests,
+			CurrentRequests:     cur.TotalRequests,
+			BaselineAvgMs:       base.AvgTotalTimeMs,
+			CurrentAvgMs:        cur.AvgTotalTimeMs,
+			AvgLatencyChangePct: percentChange(base.AvgTotalTimeMs, cur.AvgTotalTimeMs),
+			BaselineP95Ms:       base.P95TotalTimeMs,
+			CurrentP95Ms:        cur.P95TotalTimeMs,
+			P95LatencyChangePct: percentChange(base.P95TotalTimeMs, cur.P95TotalTimeMs),
+			BaselineP99Ms:       base.P99TotalTimeMs,
+			CurrentP99Ms:        cur.P99TotalTimeMs,
+			P99LatencyChangePct: percentChange(base.P99TotalTimeMs, cur.P99TotalTimeMs),
+			BaselineErrorRate:   errorRate(base.Failed, base.TotalRequests),
+			CurrentErrorRate:    errorRate(cur.Failed, cur.TotalRequests),
+		}
+		ec.ErrorRateChange = ec.CurrentErrorRate - ec.BaselineErrorRate
+		ec.Regressed = ec.P95LatencyChangePct > opts.LatencyThresholdPct || ec.ErrorRateChange > opts.ErrorRateThreshold
+
+		cmp.Endpoints[name] = ec
+		if ec.Regressed {
+			cmp.Summary.RegressedEndpoints = append(cmp.Summary.RegressedEndpoints, name)
+		}
+	}
+
+	for name := range current.Endpoints {
+		if _, exists := baseline.Endpoints[name]; !exists {
+			cmp.OnlyInCurrent = append(cmp.OnlyInCurrent, name)
+		}
+	}
+
+	cmp.Summary.ComparedEndpoints = len(cmp.Endpoints)
+	sort.Strings(cmp.Summary.RegressedEndpoints)
+	sort.Strings(cmp.OnlyInBaseline)
+	sort.Strings(cmp.OnlyInCurrent)
+
+	return cmp
+}
+
+// ParseSnapshot parses an exported metrics snapshot. Both the outgoing metrics
+// response (GET /api/metrics/outgoing) and the overview response
+// (GET /api/metrics, using its outgoing_snapshot) are accepted.
+func ParseSnapshot(data []byte) (*MetricsSnapshot, error) {
+	var overview struct {
+		OutgoingSnapshot *MetricsSnapshot `json:"outgoing_snapshot"`
+	}
+	if err := json.Unmarshal(data, &overview); err != nil {
+		return nil, fmt.Errorf("invalid snapshot JSON: %w", err)
+	}
+	if overview.OutgoingSnapshot != nil {
+		return overview.OutgoingSnapshot, nil
+	}
+
+	var snapshot MetricsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot JSON: %w", err)
+	}
+	if snapshot.Endpoints == nil {
+		return nil, fmt.Errorf("snapshot contains no endpoints")
+	}
+	return &snapshot, nil
+}
+
+// LoadSnapshot reads and parses an exported metrics snapshot from a file
+func LoadSnapshot(path string) (*MetricsSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return ParseSnapshot(data)
+}
+
+// percentChange returns the relative change from base to current in percent
+func percentChange(base, current float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (current - base) / base * 100
+}
+
+// errorRate returns failures as a percentage of total requests
+func errorRate(failures, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(failures) / float64(total) * 100
+}
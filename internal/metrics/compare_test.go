@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := &MetricsSnapshot{
+		TotalRequests: 200,
+		TotalFailures: 2,
+		Endpoints: map[string]EndpointSnapshot{
+			"stable":  {TotalRequests: 100, Failed: 1, P95TotalTimeMs: 100},
+			"slower":  {TotalRequests: 100, Failed: 1, P95TotalTimeMs: 100},
+			"removed": {TotalRequests: 10},
+		},
+	}
+	current := &MetricsSnapshot{
+		TotalRequests: 200,
+		TotalFailures: 6,
+		Endpoints: map[string]EndpointSnapshot{
+			"stable": {TotalRequests: 100, Failed: 1, P95TotalTimeMs: 105},
+			"slower": {TotalRequests: 100, Failed: 5, P95TotalTimeMs: 150},
+			"added":  {TotalRequests: 10},
+		},
+	}
+
+	cmp := Compare(baseline, current, DefaultCompareOptions())
+
+	if cmp.Endpoints["stable"].Regressed {
+		t.Error("expected stable endpoint not to be regressed")
+	}
+
+	slower := cmp.Endpoints["slower"]
+	if !slower.Regressed {
+		t.Error("expected slower endpoint to be regressed")
+	}
+	if slower.P95LatencyChangePct != 50 {
+		t.Errorf("expected 50%% p95 change, got %.2f", slower.P95LatencyChangePct)
+	}
+	if slower.ErrorRateChange != 4 {
+		t.Errorf("expected +4 pts error rate change, got %.2f", slower.ErrorRateChange)
+	}
+
+	if cmp.Summary.ErrorRateChange != 2 {
+		t.Errorf("expected +2 pts overall error rate change, got %.2f", cmp.Summary.ErrorRateChange)
+	}
+	if len(cmp.OnlyInBaseline) != 1 || cmp.OnlyInBaseline[0] != "removed" {
+		t.Errorf("expected [removed] only in baseline, got %v", cmp.OnlyInBaseline)
+	}
+	if len(cmp.OnlyInCurrent) != 1 || cmp.OnlyInCurrent[0] != "added" {
+		t.Errorf("expected [added] only in current, got %v", cmp.OnlyInCurrent)
+	}
+}
+
+func TestParseSnapshot_Overview(t *testing.T) {
+	data := []byte(`{"outgoing_snapshot": {"total_requests": 5, "endpoints": {"a": {"total_requests": 5}}}}`)
+
+	snapshot, err := ParseSnapshot(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.TotalRequests != 5 || len(snapshot.Endpoints) != 1 {
+		t.Errorf("expected overview snapshot to be unwrapped, got %+v", snapshot)
+	}
+
+	if _, err := ParseSnapshot([]byte(`{"foo": 1}`)); err == nil {
+		t.Error("expected error for snapshot without endpoints")
+	}
+}
@@ -0,0 +1,78 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import "moxapp/internal/client"
+
+// connectionGroup holds the latency of successful requests sent on one kind
+// of connection, warm or cold
+type connectionGroup struct {
+	requests    int64
+	totalTimeMs float64
+	times       *RingBuffer
+}
+
+// ConnectionSplit compares requests sent on reused (warm) and new (cold)
+// connections. SetupCostMs is the difference of their median latencies: the
+// time DNS, connect and TLS add to a request.
+type ConnectionSplit struct {
+	Warm        ConnectionGroupSnapshot `json:"warm"`
+	Cold        ConnectionGroupSnapshot `json:"cold"`
+	SetupCostMs float64                 `json:"setup_cost_ms"` // 0 until both have requests
+}
+
+// ConnectionGroupSnapshot is the latency of successful requests on one kind
+// of connection
+type ConnectionGroupSnapshot struct {
+	Requests       int64   `json:"requests"`
+	AvgTotalTimeMs float64 `json:"avg_total_time_ms"`
+	P50TotalTimeMs float64 `json:"p50_total_time_ms"`
+	P95TotalTimeMs float64 `json:"p95_total_time_ms"`
+}
+
+// RecordConnection records the latency of a successful request sent on a
+// warm or cold connection
+func (em *EndpointMetrics) RecordConnection(kind string, totalTimeMs float64) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if em.connections == nil {
+		em.connections = make(map[string]*connectionGroup, 2)
+	}
+	group, exists := em.connections[kind]
+	if !exists {
+		group = &connectionGroup{times: NewRingBuffer(1000)}
+		em.connections[kind] = group
+	}
+	group.requests++
+	group.totalTimeMs += totalTimeMs
+	group.times.Add(totalTimeMs)
+}
+
+// connectionSplit returns the warm and cold latency groups, nil for
+// endpoints without cold_connections (caller holds lock)
+func (em *EndpointMetrics) connectionSplit() *ConnectionSplit {
+	if len(em.connections) == 0 {
+		return nil
+	}
+	split := &ConnectionSplit{
+		Warm: em.connections[client.ConnectionWarm].snapshot(),
+		Cold: em.connections[client.ConnectionCold].snapshot(),
+	}
+	if split.Warm.Requests > 0 && split.Cold.Requests > 0 {
+		split.SetupCostMs = split.Cold.P50TotalTimeMs - split.Warm.P50TotalTimeMs
+	}
+	return split
+}
+
+// snapshot returns the latency of the group, zero for a nil group
+func (g *connectionGroup) snapshot() ConnectionGroupSnapshot {
+	if g == nil || g.requests == 0 {
+		return ConnectionGroupSnapshot{}
+	}
+	return ConnectionGroupSnapshot{
+		Requests:       g.requests,
+		AvgTotalTimeMs: g.totalTimeMs / float64(g.requests),
+		P50TotalTimeMs: g.times.Percentile(50),
+		P95TotalTimeMs: g.times.Percentile(95),
+	}
+}
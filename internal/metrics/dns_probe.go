@@ -0,0 +1,162 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DNSProbeResult is the outcome of a single DNS probe query
+type DNSProbeResult struct {
+	Hostname     string
+	RecordType   string // A or AAAA
+	Resolver     string
+	Answers      []string
+	TTLSeconds   uint32 // Minimum TTL across the answer set
+	ResolutionMs float64
+	Error        string
+	Timestamp    time.Time
+}
+
+// DNSProbeMetrics holds probe metrics for a single hostname, split by record type
+type DNSProbeMetrics struct {
+	records map[string]*probeRecordMetrics
+	mu      sync.Mutex
+}
+
+// probeRecordMetrics holds probe metrics for a single hostname and record type
+type probeRecordMetrics struct {
+	probes          int64
+	failures        int64
+	answerChanges   int64 // Probes whose answer set differed from the previous one
+	cachedResponses int64 // Probes whose TTL was below the highest TTL seen (served from a cache)
+
+	totalResolutionMs float64
+	resolutionTimes   *RingBuffer
+
+	lastAnswers []string
+	lastTTL     uint32
+	minTTL      uint32
+	maxTTL      uint32
+	lastError   string
+	lastProbeAt time.Time
+	resolver    string
+}
+
+// NewDNSProbeMetrics creates new DNS probe metrics
+func NewDNSProbeMetrics() *DNSProbeMetrics {
+	return &DNSProbeMetrics{
+		records: make(map[string]*probeRecordMetrics),
+	}
+}
+
+// Record records the result of a probe query
+func (pm *DNSProbeMetrics) Record(result *DNSProbeResult) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	rm, exists := pm.records[result.RecordType]
+	if !exists {
+		rm = &probeRecordMetrics{resolutionTimes: NewRingBuffer(1000)}
+		pm.records[result.RecordType] = rm
+	}
+
+	rm.probes++
+	rm.lastProbeAt = result.Timestamp
+	rm.resolver = result.Resolver
+
+	if result.Error != "" {
+		rm.failures++
+		rm.lastError = result.Error
+		return
+	}
+
+	rm.totalResolutionMs += result.ResolutionMs
+	rm.resolutionTimes.Add(result.ResolutionMs)
+
+	answers := append([]string(nil), result.Answers...)
+	sort.Strings(answers)
+	if rm.lastAnswers != nil && strings.Join(answers, ",") != strings.Join(rm.lastAnswers, ",") {
+		rm.answerChanges++
+	}
+	rm.lastAnswers = answers
+
+	// A TTL below the maximum observed means a cache answered with a partially expired record
+	if len(answers) > 0 {
+		if result.TTLSeconds < rm.maxTTL {
+			rm.cachedResponses++
+		}
+		if rm.minTTL == 0 || result.TTLSeconds < rm.minTTL {
+			rm.minTTL = result.TTLSeconds
+		}
+		if result.TTLSeconds > rm.maxTTL {
+			rm.maxTTL = result.TTLSeconds
+		}
+		rm.lastTTL = result.TTLSeconds
+	}
+}
+
+// GetStats returns a snapshot of the probe metrics
+func (pm *DNSProbeMetrics) GetStats() DNSProbeSnapshot {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	snap := DNSProbeSnapshot{
+		Records: make(map[string]DNSProbeRecordSnapshot, len(pm.records)),
+	}
+
+	for recordType, rm := range pm.records {
+		rs := DNSProbeRecordSnapshot{
+			Probes:          rm.probes,
+			Failures:        rm.failures,
+			AnswerChanges:   rm.answerChanges,
+			CachedResponses: rm.cachedResponses,
+			P95ResolutionMs: rm.resolutionTimes.Percentile(95),
+			MaxResolutionMs: rm.resolutionTimes.Max(),
+			LastAnswers:     append([]string(nil), rm.lastAnswers...),
+			LastTTLSeconds:  rm.lastTTL,
+			MinTTLSeconds:   rm.minTTL,
+			MaxTTLSeconds:   rm.maxTTL,
+			LastError:       rm.lastError,
+			Resolver:        rm.resolver,
+		}
+		if !rm.lastProbeAt.IsZero() {
+			rs.LastProbeAt = rm.lastProbeAt.Format(time.RFC3339)
+		}
+		if successes := rm.probes - rm.failures; successes > 0 {
+			rs.AvgResolutionMs = rm.totalResolutionMs / float64(successes)
+		}
+		snap.Probes += rm.probes
+		snap.Failures += rm.failures
+		snap.Records[recordType] = rs
+	}
+
+	return snap
+}
+
+// DNSProbeSnapshot is a serializable snapshot of probe metrics for one hostname
+type DNSProbeSnapshot struct {
+	Probes   int64                             `json:"probes"`
+	Failures int64                             `json:"failures"`
+	Records  map[string]DNSProbeRecordSnapshot `json:"records"`
+}
+
+// DNSProbeRecordSnapshot is a serializable snapshot of probe metrics for one record type
+type DNSProbeRecordSnapshot struct {
+	Probes          int64    `json:"probes"`
+	Failures        int64    `json:"failures"`
+	AvgResolutionMs float64  `json:"avg_resolution_ms"`
+	P95ResolutionMs float64  `json:"p95_resolution_ms"`
+	MaxResolutionMs float64  `json:"max_resolution_ms"`
+	LastAnswers     []string `json:"last_answers"`
+	AnswerChanges   int64    `json:"answer_changes"`
+	LastTTLSeconds  uint32   `json:"last_ttl_seconds"`
+	MinTTLSeconds   uint32   `json:"min_ttl_seconds"`
+	MaxTTLSeconds   uint32   `json:"max_ttl_seconds"`
+	CachedResponses int64    `json:"cached_responses"`
+	Resolver        string   `json:"resolver"`
+	LastProbeAt     string   `json:"last_probe_at,omitempty"`
+	LastError       string   `json:"last_error,omitempty"`
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDNSProbeMetrics_TTLAndAnswerChanges(t *testing.T) {
+	metrics := NewDNSProbeMetrics()
+
+	record := func(ttl uint32, answers ...string) {
+		metrics.Record(&DNSProbeResult{
+			Hostname:     "api.example.com",
+			RecordType:   "A",
+			Answers:      answers,
+			TTLSeconds:   ttl,
+			ResolutionMs: 10,
+			Timestamp:    time.Now(),
+		})
+	}
+
+	record(300, "10.0.0.1", "10.0.0.2")
+	record(240, "10.0.0.2", "10.0.0.1") // same set, different order
+	record(300, "10.0.0.3")
+
+	metrics.Record(&DNSProbeResult{
+		Hostname:   "api.example.com",
+		RecordType: "A",
+		Error:      "resolver returned SERVFAIL",
+		Timestamp:  time.Now(),
+	})
+
+	stats := metrics.GetStats()
+	a := stats.Records["A"]
+
+	if a.Probes != 4 {
+		t.Errorf("expected 4 probes, got %d", a.Probes)
+	}
+	if a.Failures != 1 {
+		t.Errorf("expected 1 failure, got %d", a.Failures)
+	}
+	if a.AnswerChanges != 1 {
+		t.Errorf("expected 1 answer change, got %d", a.AnswerChanges)
+	}
+	if a.CachedResponses != 1 {
+		t.Errorf("expected 1 cached response, got %d", a.CachedResponses)
+	}
+	if a.MinTTLSeconds != 240 || a.MaxTTLSeconds != 300 {
+		t.Errorf("expected TTL range 240-300, got %d-%d", a.MinTTLSeconds, a.MaxTTLSeconds)
+	}
+	if a.AvgResolutionMs != 10 {
+		t.Errorf("expected avg resolution 10ms, got %.2f", a.AvgResolutionMs)
+	}
+	if a.LastError != "resolver returned SERVFAIL" {
+		t.Errorf("unexpected last error: %s", a.LastError)
+	}
+}
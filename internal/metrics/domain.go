@@ -16,18 +16,55 @@ type DomainMetrics struct {
 
 	LastError string `json:"last_error,omitempty"`
 
+	// Failed lookups by DNS error kind (nxdomain, servfail, timeout, ...)
+	ErrorsByType map[string]int64 `json:"-"`
+
+	// Lookups segmented by the address family of the connection that followed
+	ByFamily map[string]*familyDNSMetrics `json:"-"`
+
+	// Answer and request distribution per resolved IP
+	ByIP map[string]*ipMetrics `json:"-"`
+
+	// Requests whose new connection dialed more than one address
+	ConnectFallbacks int64 `json:"connect_fallbacks"`
+
 	mu sync.Mutex
 }
 
+// maxTrackedIPsPerDomain caps the number of distinct IPs tracked per domain
+const maxTrackedIPsPerDomain = 256
+
+// ipMetrics holds DNS answer and request counters for a single resolved IP
+type ipMetrics struct {
+	answers  int64 // Times the IP appeared in a DNS answer
+	requests int64 // Requests sent over a connection to the IP
+	failures int64 // Failed requests sent over a connection to the IP
+
+	connectAttempts int64   // Dials to the IP
+	connectFailures int64   // Dials to the IP that failed
+	totalConnectMs  float64 // Duration of successful dials, for the average
+}
+
+// familyDNSMetrics holds DNS lookup metrics for a single address family
+type familyDNSMetrics struct {
+	lookups        int64
+	totalDNSTimeMs float64
+	dnsTimes       *RingBuffer
+}
+
 // NewDomainMetrics creates new domain metrics
 func NewDomainMetrics() *DomainMetrics {
 	return &DomainMetrics{
-		DNSTimes: NewRingBuffer(1000),
+		DNSTimes:     NewRingBuffer(1000),
+		ErrorsByType: make(map[string]int64),
+		ByFamily:     make(map[string]*familyDNSMetrics),
+		ByIP:         make(map[string]*ipMetrics),
 	}
 }
 
-// RecordSuccess records a successful DNS lookup
-func (dm *DomainMetrics) RecordSuccess(dnsTimeMs float64) {
+// RecordSuccess records a successful DNS lookup. family is the address family
+// (ipv4/ipv6) of the connection established after the lookup, if known.
+func (dm *DomainMetrics) RecordSuccess(dnsTimeMs float64, family string) {
 	dm.mu.Lock()
 	defer dm.mu.Unlock()
 
@@ -35,16 +72,102 @@ func (dm *DomainMetrics) RecordSuccess(dnsTimeMs float64) {
 	dm.SuccessfulLookups++
 	dm.TotalDNSTimeMs += dnsTimeMs
 	dm.DNSTimes.Add(dnsTimeMs)
+
+	if family == "" {
+		family = "unknown"
+	}
+	fm, exists := dm.ByFamily[family]
+	if !exists {
+		fm = &familyDNSMetrics{dnsTimes: NewRingBuffer(1000)}
+		dm.ByFamily[family] = fm
+	}
+	fm.lookups++
+	fm.totalDNSTimeMs += dnsTimeMs
+	fm.dnsTimes.Add(dnsTimeMs)
 }
 
-// RecordFailure records a failed DNS lookup
-func (dm *DomainMetrics) RecordFailure(errorMsg string) {
+// RecordFailure records a failed DNS lookup and its error kind ("other" if empty)
+func (dm *DomainMetrics) RecordFailure(kind, errorMsg string) {
 	dm.mu.Lock()
 	defer dm.mu.Unlock()
 
 	dm.TotalLookups++
 	dm.FailedLookups++
 	dm.LastError = errorMsg
+	if kind == "" {
+		kind = "other"
+	}
+	dm.ErrorsByType[kind]++
+}
+
+// RecordAnswers records the IP addresses returned by a DNS lookup
+func (dm *DomainMetrics) RecordAnswers(ips []string) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, ip := range ips {
+		if im := dm.getIP(ip); im != nil {
+			im.answers++
+		}
+	}
+}
+
+// RecordIPRequest records a request sent to a resolved IP and whether it succeeded
+func (dm *DomainMetrics) RecordIPRequest(ip string, success bool) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	im := dm.getIP(ip)
+	if im == nil {
+		return
+	}
+	im.requests++
+	if !success {
+		im.failures++
+	}
+}
+
+// RecordConnectAttempt records a dial to a resolved IP, its duration and
+// whether it failed
+func (dm *DomainMetrics) RecordConnectAttempt(ip string, durationMs float64, failed bool) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	im := dm.getIP(ip)
+	if im == nil {
+		return
+	}
+	im.connectAttempts++
+	if failed {
+		im.connectFailures++
+	} else {
+		im.totalConnectMs += durationMs
+	}
+}
+
+// RecordConnectFallback records a request whose new connection dialed more
+// than one address
+func (dm *DomainMetrics) RecordConnectFallback() {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	dm.ConnectFallbacks++
+}
+
+// getIP returns the metrics for an IP, creating them if the cap allows (caller holds lock)
+func (dm *DomainMetrics) getIP(ip string) *ipMetrics {
+	if ip == "" {
+		return nil
+	}
+	im, exists := dm.ByIP[ip]
+	if !exists {
+		if len(dm.ByIP) >= maxTrackedIPsPerDomain {
+			return nil
+		}
+		im = &ipMetrics{}
+		dm.ByIP[ip] = im
+	}
+	return im
 }
 
 // GetStats returns a snapshot of the domain metrics
@@ -57,6 +180,7 @@ func (dm *DomainMetrics) GetStats() DomainSnapshot {
 		SuccessfulLookups: dm.SuccessfulLookups,
 		FailedLookups:     dm.FailedLookups,
 		LastError:         dm.LastError,
+		ConnectFallbacks:  dm.ConnectFallbacks,
 	}
 
 	if dm.SuccessfulLookups > 0 && dm.TotalDNSTimeMs > 0 {
@@ -67,6 +191,55 @@ func (dm *DomainMetrics) GetStats() DomainSnapshot {
 	snap.MaxResolutionMs = dm.DNSTimes.Max()
 	snap.MinResolutionMs = dm.DNSTimes.Min()
 
+	if len(dm.ErrorsByType) > 0 {
+		snap.ErrorsByType = make(map[string]int64, len(dm.ErrorsByType))
+		for kind, count := range dm.ErrorsByType {
+			snap.ErrorsByType[kind] = count
+		}
+	}
+
+	if len(dm.ByFamily) > 0 {
+		snap.ByFamily = make(map[string]FamilyDNSSnapshot, len(dm.ByFamily))
+		for family, fm := range dm.ByFamily {
+			fs := FamilyDNSSnapshot{
+				Lookups:         fm.lookups,
+				P95ResolutionMs: fm.dnsTimes.Percentile(95),
+			}
+			if fm.lookups > 0 {
+				fs.AvgResolutionMs = fm.totalDNSTimeMs / float64(fm.lookups)
+			}
+			snap.ByFamily[family] = fs
+		}
+	}
+
+	if len(dm.ByIP) > 0 {
+		var totalIPRequests int64
+		for _, im := range dm.ByIP {
+			totalIPRequests += im.requests
+		}
+
+		snap.ByIP = make(map[string]IPSnapshot, len(dm.ByIP))
+		for ip, im := range dm.ByIP {
+			is := IPSnapshot{
+				Answers:         im.answers,
+				Requests:        im.requests,
+				Failures:        im.failures,
+				ConnectAttempts: im.connectAttempts,
+				ConnectFailures: im.connectFailures,
+			}
+			if connected := im.connectAttempts - im.connectFailures; connected > 0 {
+				is.AvgConnectMs = im.totalConnectMs / float64(connected)
+			}
+			if im.requests > 0 {
+				is.ErrorRate = float64(im.failures) / float64(im.requests) * 100
+			}
+			if totalIPRequests > 0 {
+				is.RequestShare = float64(im.requests) / float64(totalIPRequests) * 100
+			}
+			snap.ByIP[ip] = is
+		}
+	}
+
 	return snap
 }
 
@@ -80,7 +253,11 @@ func (dm *DomainMetrics) Reset() {
 	dm.FailedLookups = 0
 	dm.TotalDNSTimeMs = 0
 	dm.LastError = ""
+	dm.ConnectFallbacks = 0
 	dm.DNSTimes.Reset()
+	dm.ErrorsByType = make(map[string]int64)
+	dm.ByFamily = make(map[string]*familyDNSMetrics)
+	dm.ByIP = make(map[string]*ipMetrics)
 }
 
 // DomainSnapshot is a serializable snapshot of domain metrics
@@ -93,6 +270,35 @@ type DomainSnapshot struct {
 	MaxResolutionMs   float64 `json:"max_resolution_ms"`
 	MinResolutionMs   float64 `json:"min_resolution_ms"`
 	LastError         string  `json:"last_error,omitempty"`
+
+	ErrorsByType map[string]int64 `json:"errors_by_type,omitempty"` // Failed lookups by DNS error kind
+
+	ConnectFallbacks int64 `json:"connect_fallbacks,omitempty"` // Requests whose new connection dialed more than one address
+
+	ByFamily map[string]FamilyDNSSnapshot `json:"by_family,omitempty"`
+	ByIP     map[string]IPSnapshot        `json:"by_ip,omitempty"`
+}
+
+// IPSnapshot is a serializable snapshot of answer and request stats for one resolved IP
+type IPSnapshot struct {
+	Answers      int64   `json:"answers"`
+	Requests     int64   `json:"requests"`
+	Failures     int64   `json:"failures"`
+	ErrorRate    float64 `json:"error_rate"`
+	RequestShare float64 `json:"request_share"`
+
+	// Dials to the IP for new connections, and the average duration of
+	// those that connected
+	ConnectAttempts int64   `json:"connect_attempts,omitempty"`
+	ConnectFailures int64   `json:"connect_failures,omitempty"`
+	AvgConnectMs    float64 `json:"avg_connect_ms,omitempty"`
+}
+
+// FamilyDNSSnapshot is a serializable snapshot of DNS metrics for one address family
+type FamilyDNSSnapshot struct {
+	Lookups         int64   `json:"lookups"`
+	AvgResolutionMs float64 `json:"avg_resolution_ms"`
+	P95ResolutionMs float64 `json:"p95_resolution_ms"`
 }
 
 // DNSStats aggregates DNS statistics across all domains
@@ -101,6 +307,7 @@ type DNSStats struct {
 	SuccessfulLookups int64                      `json:"successful_lookups"`
 	FailedLookups     int64                      `json:"failed_lookups"`
 	AvgResolutionMs   float64                    `json:"avg_resolution_ms"`
+	ErrorsByType      map[string]int64           `json:"errors_by_type,omitempty"`
 	ByDomain          map[string]*DomainSnapshot `json:"by_domain"`
 }
 
@@ -119,6 +326,12 @@ func CalculateDNSStats(domains map[string]DomainSnapshot) DNSStats {
 		stats.SuccessfulLookups += snap.SuccessfulLookups
 		stats.FailedLookups += snap.FailedLookups
 		totalDNSTime += snap.AvgResolutionMs * float64(snap.SuccessfulLookups)
+		for kind, count := range snap.ErrorsByType {
+			if stats.ErrorsByType == nil {
+				stats.ErrorsByType = make(map[string]int64)
+			}
+			stats.ErrorsByType[kind] += count
+		}
 	}
 
 	if stats.SuccessfulLookups > 0 {
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"testing"
+
+	"moxapp/internal/client"
+)
+
+func TestDomainMetrics_ByIP(t *testing.T) {
+	metrics := NewDomainMetrics()
+
+	metrics.RecordAnswers([]string{"10.0.0.1", "10.0.0.2"})
+	metrics.RecordAnswers([]string{"10.0.0.1", "10.0.0.2"})
+	metrics.RecordIPRequest("10.0.0.1", true)
+	metrics.RecordIPRequest("10.0.0.1", true)
+	metrics.RecordIPRequest("10.0.0.1", true)
+	metrics.RecordIPRequest("10.0.0.2", false)
+
+	stats := metrics.GetStats()
+
+	if len(stats.ByIP) != 2 {
+		t.Fatalf("expected 2 IPs, got %d", len(stats.ByIP))
+	}
+
+	ip1 := stats.ByIP["10.0.0.1"]
+	if ip1.Answers != 2 {
+		t.Errorf("expected 2 answers for 10.0.0.1, got %d", ip1.Answers)
+	}
+	if ip1.Requests != 3 {
+		t.Errorf("expected 3 requests for 10.0.0.1, got %d", ip1.Requests)
+	}
+	if ip1.RequestShare != 75 {
+		t.Errorf("expected 75%% request share for 10.0.0.1, got %.2f", ip1.RequestShare)
+	}
+
+	ip2 := stats.ByIP["10.0.0.2"]
+	if ip2.Failures != 1 {
+		t.Errorf("expected 1 failure for 10.0.0.2, got %d", ip2.Failures)
+	}
+	if ip2.ErrorRate != 100 {
+		t.Errorf("expected 100%% error rate for 10.0.0.2, got %.2f", ip2.ErrorRate)
+	}
+
+	metrics.Reset()
+	if stats := metrics.GetStats(); len(stats.ByIP) != 0 {
+		t.Error("expected no IPs after reset")
+	}
+}
+
+func TestDomainMetrics_ByFamily(t *testing.T) {
+	metrics := NewDomainMetrics()
+
+	metrics.RecordSuccess(10, "ipv4")
+	metrics.RecordSuccess(20, "ipv4")
+	metrics.RecordSuccess(90, "ipv6")
+
+	stats := metrics.GetStats()
+
+	if stats.ByFamily["ipv4"].Lookups != 2 {
+		t.Errorf("expected 2 ipv4 lookups, got %d", stats.ByFamily["ipv4"].Lookups)
+	}
+	if stats.ByFamily["ipv4"].AvgResolutionMs != 15 {
+		t.Errorf("expected ipv4 avg 15ms, got %.2f", stats.ByFamily["ipv4"].AvgResolutionMs)
+	}
+	if stats.ByFamily["ipv6"].Lookups != 1 {
+		t.Errorf("expected 1 ipv6 lookup, got %d", stats.ByFamily["ipv6"].Lookups)
+	}
+}
+
+func TestDomainMetrics_ErrorsByType(t *testing.T) {
+	metrics := NewDomainMetrics()
+	metrics.RecordFailure("nxdomain", "DNS Error (nxdomain): no such host")
+	metrics.RecordFailure("nxdomain", "DNS Error (nxdomain): no such host")
+	metrics.RecordFailure("", "DNS Error: lookup failed")
+
+	stats := metrics.GetStats()
+	if stats.FailedLookups != 3 || stats.ErrorsByType["nxdomain"] != 2 || stats.ErrorsByType["other"] != 1 {
+		t.Errorf("unexpected errors by type: %+v", stats)
+	}
+
+	total := CalculateDNSStats(map[string]DomainSnapshot{"a.example.com": stats, "b.example.com": stats})
+	if total.ErrorsByType["nxdomain"] != 4 {
+		t.Errorf("expected errors by type summed over domains, got %v", total.ErrorsByType)
+	}
+}
+
+func TestCollectorConnectAttempts(t *testing.T) {
+	c := NewCollector()
+	c.Record(&client.RequestResult{
+		EndpointName: "api", Hostname: "api.example.com", Success: true, StatusCode: 200,
+		ConnectAttempts: []client.ConnectAttempt{
+			{Addr: "10.0.0.1:443", Network: "tcp", DurationMs: 30, Error: "connection refused"},
+			{Addr: "10.0.0.2:443", Network: "tcp", DurationMs: 4},
+		},
+	})
+	c.Record(&client.RequestResult{
+		EndpointName: "api", Hostname: "api.example.com", Success: true, StatusCode: 200,
+		ConnectAttempts: []client.ConnectAttempt{{Addr: "10.0.0.2:443", Network: "tcp", DurationMs: 6}},
+	})
+
+	domain := c.Snapshot().DNSStatsByDomain["api.example.com"]
+	if domain.ConnectFallbacks != 1 {
+		t.Errorf("expected 1 fallback, got %d", domain.ConnectFallbacks)
+	}
+	if ip := domain.ByIP["10.0.0.1"]; ip.ConnectAttempts != 1 || ip.ConnectFailures != 1 || ip.AvgConnectMs != 0 {
+		t.Errorf("unexpected failed address stats: %+v", ip)
+	}
+	if ip := domain.ByIP["10.0.0.2"]; ip.ConnectAttempts != 2 || ip.ConnectFailures != 0 || ip.AvgConnectMs != 5 {
+		t.Errorf("unexpected connected address stats: %+v", ip)
+	}
+}
@@ -21,8 +21,23 @@ type EndpointMetrics struct {
 	TotalDNSTimeMs float64 `json:"-"`
 	TotalConnectMs float64 `json:"-"`
 
+	// Request phases, averaged over the requests that went through them
+	TLSHandshakes  int64   `json:"tls_handshakes"`
+	TotalTLSTimeMs float64 `json:"-"`
+	FirstBytes     int64   `json:"first_bytes"` // Requests that got a response
+	TotalTTFBMs    float64 `json:"-"`
+
+	// Requests by Apdex zone
+	ApdexSatisfied  int64 `json:"apdex_satisfied"`
+	ApdexTolerating int64 `json:"apdex_tolerating"`
+	ApdexFrustrated int64 `json:"apdex_frustrated"`
+
+	SLOBreaches int64 `json:"slo_breaches"` // Requests counted against the SLO error budget
+
 	ResponseTimes *RingBuffer `json:"-"` // For percentiles
 	DNSTimes      *RingBuffer `json:"-"`
+	TLSTimes      *RingBuffer `json:"-"`
+	TTFBTimes     *RingBuffer `json:"-"`
 
 	LastStatusCode int       `json:"last_status_code"`
 	LastError      string    `json:"last_error"`
@@ -31,16 +46,46 @@ type EndpointMetrics struct {
 	URLPattern string `json:"url_pattern"`
 	Hostname   string `json:"hostname"`
 
+	RequestsByFamily map[string]int64 `json:"requests_by_family"`
+
+	// Request payloads (only requests with a body are counted)
+	BytesSent        int64 `json:"bytes_sent"`
+	RequestsWithBody int64 `json:"requests_with_body"`
+	MaxRequestSize   int64 `json:"max_request_size"`
+
+	// Response bodies: bytes received on the wire and, where known, their
+	// uncompressed size
+	BytesReceived       int64 `json:"bytes_received"`
+	Responses           int64 `json:"responses"`
+	MaxResponseSize     int64 `json:"max_response_size"`
+	BytesDecoded        int64 `json:"bytes_decoded"`
+	DecodedResponses    int64 `json:"decoded_responses"`
+	CompressedResponses int64 `json:"compressed_responses"`
+	compressedWire      int64 // Wire and uncompressed bytes of decoded
+	compressedDecoded   int64 // compressed responses, for the ratio
+
+	recent *windowRing   // Sliding-window buckets for ?window= snapshots
+	errors *errorSamples // Distinct recent errors
+
+	// Latency by warm and cold connection, for endpoints with
+	// cold_connections (allocated on first use)
+	connections map[string]*connectionGroup
+
 	mu sync.Mutex
 }
 
 // NewEndpointMetrics creates new endpoint metrics
 func NewEndpointMetrics(urlPattern, hostname string) *EndpointMetrics {
 	return &EndpointMetrics{
-		ResponseTimes: NewRingBuffer(1000),
-		DNSTimes:      NewRingBuffer(1000),
-		URLPattern:    urlPattern,
-		Hostname:      hostname,
+		ResponseTimes:    NewRingBuffer(1000),
+		DNSTimes:         NewRingBuffer(1000),
+		TLSTimes:         NewRingBuffer(1000),
+		TTFBTimes:        NewRingBuffer(1000),
+		URLPattern:       urlPattern,
+		Hostname:         hostname,
+		RequestsByFamily: make(map[string]int64),
+		recent:           &windowRing{},
+		errors:           &errorSamples{},
 	}
 }
 
@@ -53,6 +98,7 @@ func (em *EndpointMetrics) RecordSuccess(totalTimeMs, dnsTimeMs, connectTimeMs f
 	em.Successful++
 	em.LastStatusCode = statusCode
 	em.LastSuccess = time.Now()
+	em.recent.record(em.LastSuccess, true, "", totalTimeMs, dnsTimeMs, connectTimeMs)
 
 	em.TotalTimeMs += totalTimeMs
 	em.TotalDNSTimeMs += dnsTimeMs
@@ -73,6 +119,7 @@ func (em *EndpointMetrics) RecordFailure(totalTimeMs, dnsTimeMs, connectTimeMs f
 	em.Failed++
 	em.LastStatusCode = statusCode
 	em.LastError = errorMsg
+	em.recent.record(time.Now(), false, errorType, totalTimeMs, dnsTimeMs, connectTimeMs)
 
 	em.TotalTimeMs += totalTimeMs
 	em.TotalDNSTimeMs += dnsTimeMs
@@ -98,8 +145,116 @@ func (em *EndpointMetrics) RecordFailure(totalTimeMs, dnsTimeMs, connectTimeMs f
 	}
 }
 
+// RecordPhases records the TLS handshake time and time to first byte of a
+// request; zero values (reused connection, no response) are skipped
+func (em *EndpointMetrics) RecordPhases(tlsTimeMs, ttfbMs float64) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if tlsTimeMs > 0 {
+		em.TLSHandshakes++
+		em.TotalTLSTimeMs += tlsTimeMs
+		em.TLSTimes.Add(tlsTimeMs)
+	}
+	if ttfbMs > 0 {
+		em.FirstBytes++
+		em.TotalTTFBMs += ttfbMs
+		em.TTFBTimes.Add(ttfbMs)
+	}
+}
+
+// RecordApdex records the Apdex zone of a request: satisfied, tolerating or
+// frustrated
+func (em *EndpointMetrics) RecordApdex(zone string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	b := em.recent.bucket(time.Now())
+	switch zone {
+	case "satisfied":
+		em.ApdexSatisfied++
+		b.apdexSatisfied++
+	case "tolerating":
+		em.ApdexTolerating++
+		b.apdexTolerating++
+	default:
+		em.ApdexFrustrated++
+		b.apdexFrustrated++
+	}
+}
+
+// RecordSLOBreach records a request counted against the SLO error budget
+func (em *EndpointMetrics) RecordSLOBreach() {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.SLOBreaches++
+	em.recent.bucket(time.Now()).sloBreaches++
+}
+
+// RecordErrorSample records an occurrence of an error with the URL requested
+func (em *EndpointMetrics) RecordErrorSample(errorType, errorMsg string, statusCode int, url string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.errors.record(time.Now(), errorType, errorMsg, statusCode, url)
+}
+
+// GetErrorSamples returns the distinct recent errors, most recently seen first
+func (em *EndpointMetrics) GetErrorSamples() []ErrorSample {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	return em.errors.list()
+}
+
+// RecordAddressFamily records the address family (ipv4/ipv6) used by a request
+func (em *EndpointMetrics) RecordAddressFamily(family string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.RequestsByFamily[family]++
+}
+
+// RecordRequestSize records the size of a request body that was sent
+func (em *EndpointMetrics) RecordRequestSize(size int64) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.BytesSent += size
+	em.RequestsWithBody++
+	if size > em.MaxRequestSize {
+		em.MaxRequestSize = size
+	}
+}
+
+// RecordResponseSize records the size of a response body as received and,
+// when decoded is set, its uncompressed size. encoding is its
+// Content-Encoding, empty for identity.
+func (em *EndpointMetrics) RecordResponseSize(size, decodedSize int64, decoded bool, encoding string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	em.BytesReceived += size
+	em.Responses++
+	if size > em.MaxResponseSize {
+		em.MaxResponseSize = size
+	}
+	if decoded {
+		em.BytesDecoded += decodedSize
+		em.DecodedResponses++
+	}
+	if encoding != "" {
+		em.CompressedResponses++
+		if decoded {
+			em.compressedWire += size
+			em.compressedDecoded += decodedSize
+		}
+	}
+}
+
 // GetStats returns a snapshot of the endpoint metrics
-func (em *EndpointMetrics) GetStats() EndpointSnapshot {
+func (em *EndpointMetrics) GetStats(percentiles ...float64) EndpointSnapshot {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
@@ -116,12 +271,40 @@ func (em *EndpointMetrics) GetStats() EndpointSnapshot {
 		LastError:        em.LastError,
 		URLPattern:       em.URLPattern,
 		Hostname:         em.Hostname,
+		BytesSent:        em.BytesSent,
+		MaxRequestSize:   em.MaxRequestSize,
+		TLSHandshakes:    em.TLSHandshakes,
+
+		BytesReceived:       em.BytesReceived,
+		MaxResponseSize:     em.MaxResponseSize,
+		CompressedResponses: em.CompressedResponses,
+	}
+
+	if em.Responses > 0 {
+		snap.AvgResponseSize = float64(em.BytesReceived) / float64(em.Responses)
+	}
+	if em.DecodedResponses > 0 {
+		snap.AvgDecodedSize = float64(em.BytesDecoded) / float64(em.DecodedResponses)
+	}
+	if em.compressedWire > 0 {
+		snap.CompressionRatio = float64(em.compressedDecoded) / float64(em.compressedWire)
+	}
+
+	if em.RequestsWithBody > 0 {
+		snap.AvgRequestSize = float64(em.BytesSent) / float64(em.RequestsWithBody)
 	}
 
 	if !em.LastSuccess.IsZero() {
 		snap.LastSuccess = em.LastSuccess.Format(time.RFC3339)
 	}
 
+	if len(em.RequestsByFamily) > 0 {
+		snap.RequestsByFamily = make(map[string]int64, len(em.RequestsByFamily))
+		for family, count := range em.RequestsByFamily {
+			snap.RequestsByFamily[family] = count
+		}
+	}
+
 	if em.TotalRequests > 0 {
 		snap.SuccessRate = float64(em.Successful) / float64(em.TotalRequests) * 100
 		snap.AvgTotalTimeMs = em.TotalTimeMs / float64(em.TotalRequests)
@@ -133,11 +316,46 @@ func (em *EndpointMetrics) GetStats() EndpointSnapshot {
 		}
 	}
 
+	snap.ApdexSatisfied = em.ApdexSatisfied
+	snap.ApdexTolerating = em.ApdexTolerating
+	snap.ApdexFrustrated = em.ApdexFrustrated
+	snap.Apdex = apdexScore(em.ApdexSatisfied, em.ApdexTolerating, em.ApdexFrustrated)
+	snap.SLOBreaches = em.SLOBreaches
+	snap.Connections = em.connectionSplit()
+
 	snap.P95TotalTimeMs = em.ResponseTimes.Percentile(95)
 	snap.P99TotalTimeMs = em.ResponseTimes.Percentile(99)
+	snap.Percentiles = percentileValues(percentiles, em.ResponseTimes.Percentile)
 	snap.MaxTotalTimeMs = em.ResponseTimes.Max()
 	snap.P95DNSTimeMs = em.DNSTimes.Percentile(95)
 
+	if em.TLSHandshakes > 0 {
+		snap.AvgTLSTimeMs = em.TotalTLSTimeMs / float64(em.TLSHandshakes)
+		snap.P95TLSTimeMs = em.TLSTimes.Percentile(95)
+	}
+	if em.FirstBytes > 0 {
+		snap.AvgTTFBMs = em.TotalTTFBMs / float64(em.FirstBytes)
+		snap.P95TTFBMs = em.TTFBTimes.Percentile(95)
+	}
+
+	return snap
+}
+
+// GetWindowStats returns a snapshot of the requests within the last window.
+// Identity and last-seen fields are the since-start ones; request sizes and
+// address families are not tracked per window.
+func (em *EndpointMetrics) GetWindowStats(window time.Duration, percentiles ...float64) EndpointSnapshot {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	snap := em.recent.snapshot(time.Now(), window, percentiles...)
+	snap.LastStatusCode = em.LastStatusCode
+	snap.LastError = em.LastError
+	snap.URLPattern = em.URLPattern
+	snap.Hostname = em.Hostname
+	if !em.LastSuccess.IsZero() {
+		snap.LastSuccess = em.LastSuccess.Format(time.RFC3339)
+	}
 	return snap
 }
 
@@ -157,11 +375,36 @@ func (em *EndpointMetrics) Reset() {
 	em.TotalTimeMs = 0
 	em.TotalDNSTimeMs = 0
 	em.TotalConnectMs = 0
+	em.TLSHandshakes = 0
+	em.TotalTLSTimeMs = 0
+	em.FirstBytes = 0
+	em.TotalTTFBMs = 0
+	em.ApdexSatisfied = 0
+	em.ApdexTolerating = 0
+	em.ApdexFrustrated = 0
+	em.SLOBreaches = 0
 	em.LastStatusCode = 0
 	em.LastError = ""
 	em.LastSuccess = time.Time{}
 	em.ResponseTimes.Reset()
 	em.DNSTimes.Reset()
+	em.TLSTimes.Reset()
+	em.TTFBTimes.Reset()
+	em.RequestsByFamily = make(map[string]int64)
+	em.BytesSent = 0
+	em.RequestsWithBody = 0
+	em.MaxRequestSize = 0
+	em.BytesReceived = 0
+	em.Responses = 0
+	em.MaxResponseSize = 0
+	em.BytesDecoded = 0
+	em.DecodedResponses = 0
+	em.CompressedResponses = 0
+	em.compressedWire = 0
+	em.compressedDecoded = 0
+	em.recent = &windowRing{}
+	em.errors = &errorSamples{}
+	em.connections = nil
 }
 
 // EndpointSnapshot is a serializable snapshot of endpoint metrics
@@ -184,10 +427,62 @@ type EndpointSnapshot struct {
 	MaxTotalTimeMs   float64 `json:"max_total_time_ms"`
 	P95DNSTimeMs     float64 `json:"p95_dns_time_ms"`
 
+	// Total time percentiles by name, such as p50 and p99.9, as configured
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+
+	// Apdex score from 0 (all users frustrated) to 1 (all satisfied), nil
+	// without requests, and the requests by zone
+	Apdex           *float64 `json:"apdex,omitempty"`
+	ApdexSatisfied  int64    `json:"apdex_satisfied"`
+	ApdexTolerating int64    `json:"apdex_tolerating"`
+	ApdexFrustrated int64    `json:"apdex_frustrated"`
+
+	SLOBreaches int64 `json:"slo_breaches,omitempty"` // Requests counted against the SLO error budget
+
+	// Latency on warm and cold connections, for endpoints with cold_connections
+	Connections *ConnectionSplit `json:"connections,omitempty"`
+
+	// TLS handshakes (new connections only) and time to first byte (requests
+	// that got a response)
+	TLSHandshakes int64   `json:"tls_handshakes"`
+	AvgTLSTimeMs  float64 `json:"avg_tls_time_ms"`
+	P95TLSTimeMs  float64 `json:"p95_tls_time_ms"`
+	AvgTTFBMs     float64 `json:"avg_ttfb_ms"`
+	P95TTFBMs     float64 `json:"p95_ttfb_ms"`
+
 	LastStatusCode int    `json:"last_status_code"`
 	LastError      string `json:"last_error,omitempty"`
 	LastSuccess    string `json:"last_success,omitempty"`
 
 	URLPattern string `json:"url_pattern"`
 	Hostname   string `json:"hostname"`
+
+	RequestsByFamily map[string]int64 `json:"requests_by_family,omitempty"`
+
+	BytesSent      int64   `json:"bytes_sent"`
+	AvgRequestSize float64 `json:"avg_request_size"`
+	MaxRequestSize int64   `json:"max_request_size"`
+
+	// Response sizes: avg_response_size is on the wire (compressed) and
+	// avg_decoded_size uncompressed, over the responses whose size is known.
+	// compression_ratio is uncompressed/compressed for decoded compressed
+	// responses.
+	BytesReceived       int64   `json:"bytes_received"`
+	AvgResponseSize     float64 `json:"avg_response_size"`
+	MaxResponseSize     int64   `json:"max_response_size"`
+	AvgDecodedSize      float64 `json:"avg_decoded_size"`
+	CompressedResponses int64   `json:"compressed_responses"`
+	CompressionRatio    float64 `json:"compression_ratio,omitempty"`
+}
+
+// apdexScore returns the Apdex score of requests by zone: satisfied ones
+// count fully and tolerating ones half. Without requests there is no score,
+// rather than a score of 0 that reads as all users frustrated.
+func apdexScore(satisfied, tolerating, frustrated int64) *float64 {
+	total := satisfied + tolerating + frustrated
+	if total == 0 {
+		return nil
+	}
+	score := (float64(satisfied) + float64(tolerating)/2) / float64(total)
+	return &score
 }
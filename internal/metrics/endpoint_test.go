@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointMetrics_RecordPhases(t *testing.T) {
+	em := NewEndpointMetrics("https://api.example.com/items", "api.example.com")
+	em.RecordSuccess(120, 5, 10, 200)
+	em.RecordPhases(30, 100)
+	em.RecordSuccess(80, 0, 0, 200)
+	em.RecordPhases(0, 60) // Reused connection, no handshake
+	em.RecordFailure(5000, 0, 0, 0, "timeout", "timeout")
+	em.RecordPhases(0, 0) // No response
+
+	snap := em.GetStats()
+	if snap.TLSHandshakes != 1 || snap.AvgTLSTimeMs != 30 || snap.P95TLSTimeMs != 30 {
+		t.Errorf("TLS: got %d handshakes, avg %.1f, p95 %.1f", snap.TLSHandshakes, snap.AvgTLSTimeMs, snap.P95TLSTimeMs)
+	}
+	if snap.AvgTTFBMs != 80 || snap.P95TTFBMs != 100 {
+		t.Errorf("TTFB: got avg %.1f, p95 %.1f, want 80 and 100", snap.AvgTTFBMs, snap.P95TTFBMs)
+	}
+
+	em.Reset()
+	if snap := em.GetStats(); snap.TLSHandshakes != 0 || snap.AvgTTFBMs != 0 {
+		t.Errorf("after reset: got %+v", snap)
+	}
+}
+
+func TestEndpointMetrics_RecordResponseSize(t *testing.T) {
+	em := NewEndpointMetrics("https://api.example.com/items", "api.example.com")
+
+	em.RecordResponseSize(100, 400, true, "gzip")
+	em.RecordResponseSize(300, 300, true, "")
+	em.RecordResponseSize(200, 0, false, "br") // Not decoded
+
+	snap := em.GetStats()
+	if snap.BytesReceived != 600 || snap.AvgResponseSize != 200 || snap.MaxResponseSize != 300 {
+		t.Errorf("wire sizes: got %d bytes, avg %.1f, max %d", snap.BytesReceived, snap.AvgResponseSize, snap.MaxResponseSize)
+	}
+	if snap.AvgDecodedSize != 350 || snap.CompressedResponses != 2 || snap.CompressionRatio != 4 {
+		t.Errorf("decoded sizes: got avg %.1f, %d compressed, ratio %.1f", snap.AvgDecodedSize, snap.CompressedResponses, snap.CompressionRatio)
+	}
+}
+
+func TestEndpointMetrics_RecordApdex(t *testing.T) {
+	em := NewEndpointMetrics("https://api.example.com/items", "api.example.com")
+	if snap := em.GetStats(); snap.Apdex != nil {
+		t.Errorf("expected no apdex without requests, got %.3f", *snap.Apdex)
+	}
+	for _, zone := range []string{"satisfied", "satisfied", "tolerating", "frustrated"} {
+		em.RecordApdex(zone)
+	}
+
+	// (2 + 1/2) / 4
+	if snap := em.GetStats(); snap.Apdex == nil || *snap.Apdex != 0.625 || snap.ApdexTolerating != 1 || snap.ApdexFrustrated != 1 {
+		t.Errorf("got apdex %v with %+v", snap.Apdex, snap)
+	}
+	if snap := em.GetWindowStats(time.Minute); snap.Apdex == nil || *snap.Apdex != 0.625 || snap.ApdexSatisfied != 2 {
+		t.Errorf("window: got apdex %v with %d satisfied", snap.Apdex, snap.ApdexSatisfied)
+	}
+
+	em.Reset()
+	if snap := em.GetStats(); snap.Apdex != nil || snap.ApdexSatisfied != 0 {
+		t.Errorf("after reset: got apdex %v", snap.Apdex)
+	}
+	if data, _ := json.Marshal(em.GetStats()); strings.Contains(string(data), `"apdex":`) {
+		t.Errorf("expected apdex omitted without requests, got %s", data)
+	}
+}
+
+func TestEndpointMetrics_Connections(t *testing.T) {
+	em := NewEndpointMetrics("http://api/", "api")
+	if snap := em.GetStats(); snap.Connections != nil {
+		t.Fatalf("expected no connection split without cold connections, got %+v", snap.Connections)
+	}
+
+	for i := 0; i < 10; i++ {
+		em.RecordConnection("warm", 20)
+	}
+	em.RecordConnection("cold", 60)
+	em.RecordConnection("cold", 80)
+
+	split := em.GetStats().Connections
+	if split.Warm.Requests != 10 || split.Cold.Requests != 2 || split.Cold.AvgTotalTimeMs != 70 {
+		t.Errorf("unexpected warm and cold groups: %+v", split)
+	}
+	if split.SetupCostMs != split.Cold.P50TotalTimeMs-20 || split.SetupCostMs <= 0 {
+		t.Errorf("expected the setup cost to be the difference of the medians, got %v", split.SetupCostMs)
+	}
+}
@@ -0,0 +1,99 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxErrorSamples is the number of distinct errors kept per endpoint
+const MaxErrorSamples = 10
+
+// ErrorSample is a distinct error seen for an endpoint
+type ErrorSample struct {
+	Message    string    `json:"message"`
+	ErrorType  string    `json:"error_type"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Count      int64     `json:"count"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	SampleURL  string    `json:"sample_url"` // URL of the latest occurrence
+
+	key string
+}
+
+// errorSamples keeps the most recently seen distinct errors of an endpoint
+type errorSamples struct {
+	samples []ErrorSample
+}
+
+// errorKey identifies an error; the request URL is left out of the message
+// so errors that only differ by templated URL count as one
+func errorKey(errorType, errorMsg string, statusCode int, url string) string {
+	if url != "" {
+		errorMsg = strings.ReplaceAll(errorMsg, url, "{url}")
+	}
+	return fmt.Sprintf("%s|%d|%s", errorType, statusCode, errorMsg)
+}
+
+// record adds an occurrence of an error, replacing the least recently seen
+// sample when full
+func (e *errorSamples) record(now time.Time, errorType, errorMsg string, statusCode int, url string) {
+	key := errorKey(errorType, errorMsg, statusCode, url)
+	for i := range e.samples {
+		if e.samples[i].key == key {
+			sample := &e.samples[i]
+			sample.Count++
+			sample.LastSeen = now
+			sample.Message = errorMsg
+			sample.SampleURL = url
+			return
+		}
+	}
+
+	sample := ErrorSample{
+		Message:    errorMsg,
+		ErrorType:  errorType,
+		StatusCode: statusCode,
+		Count:      1,
+		FirstSeen:  now,
+		LastSeen:   now,
+		SampleURL:  url,
+		key:        key,
+	}
+	if len(e.samples) < MaxErrorSamples {
+		e.samples = append(e.samples, sample)
+		return
+	}
+	oldest := 0
+	for i := range e.samples {
+		if e.samples[i].LastSeen.Before(e.samples[oldest].LastSeen) {
+			oldest = i
+		}
+	}
+	e.samples[oldest] = sample
+}
+
+// list returns a copy of the samples, most recently seen first
+func (e *errorSamples) list() []ErrorSample {
+	samples := make([]ErrorSample, len(e.samples))
+	copy(samples, e.samples)
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].LastSeen.After(samples[j].LastSeen)
+	})
+	return samples
+}
+
+// ErrorSamples returns the distinct errors recently seen for an endpoint
+func (c *Collector) ErrorSamples(endpoint string) ([]ErrorSample, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ep, exists := c.endpoints[endpoint]
+	if !exists {
+		return nil, fmt.Errorf("no metrics for endpoint: %s", endpoint)
+	}
+	return ep.GetErrorSamples(), nil
+}
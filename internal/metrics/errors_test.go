@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorSamples(t *testing.T) {
+	var samples errorSamples
+	now := time.Unix(1_700_000_000, 0)
+
+	// Errors that only differ by the requested URL are one sample
+	for i := 0; i < 3; i++ {
+		url := fmt.Sprintf("http://api.local/items/%d", i)
+		samples.record(now.Add(time.Duration(i)*time.Second), "connection", "Get \""+url+"\": connection refused", 0, url)
+	}
+	samples.record(now.Add(5*time.Second), "http", "HTTP 503", 503, "http://api.local/items/9")
+
+	list := samples.list()
+	if len(list) != 2 {
+		t.Fatalf("got %d samples, want 2", len(list))
+	}
+	if list[0].StatusCode != 503 || list[0].Count != 1 {
+		t.Errorf("newest sample: got %+v", list[0])
+	}
+	if list[1].Count != 3 || list[1].SampleURL != "http://api.local/items/2" || !list[1].FirstSeen.Equal(now) {
+		t.Errorf("connection sample: got %+v", list[1])
+	}
+
+	// The least recently seen sample is replaced when full
+	for i := 0; i < MaxErrorSamples; i++ {
+		samples.record(now.Add(time.Minute+time.Duration(i)*time.Second), "other", fmt.Sprintf("error %d", i), 0, "")
+	}
+	list = samples.list()
+	if len(list) != MaxErrorSamples || list[0].Message != fmt.Sprintf("error %d", MaxErrorSamples-1) {
+		t.Errorf("got %d samples, newest %q", len(list), list[0].Message)
+	}
+	for _, sample := range list {
+		if sample.ErrorType == "connection" || sample.ErrorType == "http" {
+			t.Errorf("old sample was not evicted: %+v", sample)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import "sort"
+
+// HostSnapshot is the outgoing metrics of all endpoints sending requests to
+// one hostname, with latency percentiles over their combined requests
+type HostSnapshot struct {
+	Endpoints []string `json:"endpoints"`
+	EndpointSnapshot
+}
+
+// HostStats returns outgoing metrics aggregated per hostname, since start or
+// within the last window (see Windows)
+func (c *Collector) HostStats(window string) (map[string]HostSnapshot, error) {
+	duration, err := ParseWindow(window)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	endpoints := make(map[string][]string, len(c.hosts))
+	for name, ep := range c.endpoints {
+		if ep.Hostname != "" {
+			endpoints[ep.Hostname] = append(endpoints[ep.Hostname], name)
+		}
+	}
+
+	percentiles := c.percentiles.get()
+	hosts := make(map[string]HostSnapshot, len(c.hosts))
+	for hostname, host := range c.hosts {
+		snap := HostSnapshot{Endpoints: endpoints[hostname]}
+		if duration == 0 {
+			snap.EndpointSnapshot = host.GetStats(percentiles...)
+		} else {
+			snap.EndpointSnapshot = host.GetWindowStats(duration, percentiles...)
+		}
+		sort.Strings(snap.Endpoints)
+		hosts[hostname] = snap
+	}
+	return hosts, nil
+}
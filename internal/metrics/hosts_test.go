@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	"moxapp/internal/client"
+)
+
+func TestCollectorHostStats(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < 10; i++ {
+		c.Record(&client.RequestResult{EndpointName: "cart", Hostname: "api.example.com", Success: true, StatusCode: 200, TotalTimeMs: 10})
+		c.Record(&client.RequestResult{EndpointName: "checkout", Hostname: "api.example.com", StatusCode: 500, ErrorType: "http", TotalTimeMs: 100})
+	}
+	c.Record(&client.RequestResult{EndpointName: "search", Hostname: "search.example.com", Success: true, StatusCode: 200, TotalTimeMs: 5})
+
+	hosts, err := c.HostStats("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := hosts["api.example.com"]
+	if !reflect.DeepEqual(api.Endpoints, []string{"cart", "checkout"}) {
+		t.Errorf("unexpected endpoints: %v", api.Endpoints)
+	}
+	if api.TotalRequests != 20 || api.HTTPErrors != 10 || api.SuccessRate != 50 || api.AvgTotalTimeMs != 55 || api.MaxTotalTimeMs != 100 {
+		t.Errorf("unexpected host metrics: %+v", api.EndpointSnapshot)
+	}
+	if hosts["search.example.com"].TotalRequests != 1 {
+		t.Errorf("expected search.example.com to be aggregated on its own, got %+v", hosts)
+	}
+
+	if windowed, err := c.HostStats("5m"); err != nil || windowed["api.example.com"].TotalRequests != 20 {
+		t.Errorf("expected the 5m window to cover all requests, got %+v, %v", windowed, err)
+	}
+	if _, err := c.HostStats("2h"); err == nil {
+		t.Error("expected an error for an unsupported window")
+	}
+
+	c.Reset()
+	if hosts, _ := c.HostStats(""); len(hosts) != 0 {
+		t.Errorf("expected no hosts after reset, got %v", hosts)
+	}
+}
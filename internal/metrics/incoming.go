@@ -14,9 +14,35 @@ type IncomingRouteMetrics struct {
 
 	TotalResponseMs float64     `json:"-"` // Not exported, used for avg calculation
 	ResponseTimes   *RingBuffer `json:"-"` // For percentiles
+	recent          *windowRing // Buckets for time series
 
 	LastRequest time.Time `json:"last_request,omitempty"`
 
+	// Requests rejected by the route's validation rules, kept out of the
+	// totals and latency stats above
+	InvalidRequests int64         `json:"invalid_requests"`
+	InvalidByStatus map[int]int64 `json:"invalid_by_status"`
+	LastViolations  []string      `json:"last_violations,omitempty"`
+	LastInvalidAt   time.Time     `json:"last_invalid_at,omitempty"`
+
+	// Requests answered with 429 by the route's simulated rate limit
+	RateLimited       int64     `json:"rate_limited"`
+	LastRateLimitedAt time.Time `json:"last_rate_limited_at,omitempty"`
+
+	// Webhook callbacks fired after responding
+	CallbacksSent     int64  `json:"callbacks_sent"`
+	CallbacksFailed   int64  `json:"callbacks_failed"`
+	LastCallbackError string `json:"last_callback_error,omitempty"`
+
+	// Simulated worker pool of routes with a concurrency limit
+	InFlight         int64   `json:"in_flight"`
+	MaxInFlight      int64   `json:"max_in_flight"`
+	QueueDepth       int64   `json:"queue_depth"`
+	MaxQueueDepth    int64   `json:"max_queue_depth"`
+	QueuedRequests   int64   `json:"queued_requests"` // Requests that waited for a worker
+	TotalQueueWaitMs float64 `json:"-"`
+	Overloaded       int64   `json:"overloaded"` // Requests rejected with 503
+
 	RouteName string `json:"route_name"`
 	RoutePath string `json:"route_path"`
 
@@ -28,6 +54,8 @@ func NewIncomingRouteMetrics(routeName, routePath string) *IncomingRouteMetrics
 	return &IncomingRouteMetrics{
 		ResponsesByStatus: make(map[int]int64),
 		ResponseTimes:     NewRingBuffer(1000),
+		recent:            &windowRing{},
+		InvalidByStatus:   make(map[int]int64),
 		RouteName:         routeName,
 		RoutePath:         routePath,
 	}
@@ -43,10 +71,84 @@ func (m *IncomingRouteMetrics) Record(statusCode int, responseTimeMs float64) {
 	m.TotalResponseMs += responseTimeMs
 	m.ResponseTimes.Add(responseTimeMs)
 	m.LastRequest = time.Now()
+
+	errorType := ""
+	if statusCode >= 400 {
+		errorType = "http"
+	}
+	m.recent.record(m.LastRequest, errorType == "", errorType, responseTimeMs, 0, 0)
+}
+
+// RecordInvalid records a request rejected by the route's validation rules
+func (m *IncomingRouteMetrics) RecordInvalid(statusCode int, violations []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.InvalidRequests++
+	m.InvalidByStatus[statusCode]++
+	m.LastViolations = violations
+	m.LastInvalidAt = time.Now()
+}
+
+// RecordRateLimited records a request rejected by the route's simulated rate limit
+func (m *IncomingRouteMetrics) RecordRateLimited() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RateLimited++
+	m.LastRateLimitedAt = time.Now()
+}
+
+// RecordCallback records the outcome of a webhook callback (nil err = delivered)
+func (m *IncomingRouteMetrics) RecordCallback(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CallbacksSent++
+	if err != nil {
+		m.CallbacksFailed++
+		m.LastCallbackError = err.Error()
+	}
+}
+
+// AddInFlight adjusts the number of requests being served by the worker pool.
+// Gauges never go below zero, e.g. when requests finish after a reset.
+func (m *IncomingRouteMetrics) AddInFlight(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.InFlight = max(0, m.InFlight+int64(delta))
+	m.MaxInFlight = max(m.MaxInFlight, m.InFlight)
+}
+
+// AddQueued adjusts the number of requests waiting for a worker
+func (m *IncomingRouteMetrics) AddQueued(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.QueueDepth = max(0, m.QueueDepth+int64(delta))
+	m.MaxQueueDepth = max(m.MaxQueueDepth, m.QueueDepth)
+}
+
+// RecordQueueWait records how long a request waited for a worker
+func (m *IncomingRouteMetrics) RecordQueueWait(waitMs float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.QueuedRequests++
+	m.TotalQueueWaitMs += waitMs
+}
+
+// RecordOverloaded records a request rejected because the worker pool and its queue were full
+func (m *IncomingRouteMetrics) RecordOverloaded() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Overloaded++
 }
 
 // GetStats returns a snapshot of the incoming route metrics
-func (m *IncomingRouteMetrics) GetStats() IncomingRouteSnapshot {
+func (m *IncomingRouteMetrics) GetStats(percentiles ...float64) IncomingRouteSnapshot {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -70,8 +172,38 @@ func (m *IncomingRouteMetrics) GetStats() IncomingRouteSnapshot {
 		snap.AvgResponseMs = m.TotalResponseMs / float64(m.TotalRequests)
 	}
 
+	snap.InvalidRequests = m.InvalidRequests
+	if m.InvalidRequests > 0 {
+		snap.InvalidByStatus = make(map[int]int64, len(m.InvalidByStatus))
+		for status, count := range m.InvalidByStatus {
+			snap.InvalidByStatus[status] = count
+		}
+		snap.LastViolations = append([]string(nil), m.LastViolations...)
+		snap.LastInvalidAt = m.LastInvalidAt.Format(time.RFC3339)
+	}
+
+	snap.RateLimited = m.RateLimited
+	if !m.LastRateLimitedAt.IsZero() {
+		snap.LastRateLimitedAt = m.LastRateLimitedAt.Format(time.RFC3339)
+	}
+
+	snap.CallbacksSent = m.CallbacksSent
+	snap.CallbacksFailed = m.CallbacksFailed
+	snap.LastCallbackError = m.LastCallbackError
+
+	snap.InFlight = m.InFlight
+	snap.MaxInFlight = m.MaxInFlight
+	snap.QueueDepth = m.QueueDepth
+	snap.MaxQueueDepth = m.MaxQueueDepth
+	snap.QueuedRequests = m.QueuedRequests
+	snap.Overloaded = m.Overloaded
+	if m.QueuedRequests > 0 {
+		snap.AvgQueueWaitMs = m.TotalQueueWaitMs / float64(m.QueuedRequests)
+	}
+
 	snap.P95ResponseMs = m.ResponseTimes.Percentile(95)
 	snap.P99ResponseMs = m.ResponseTimes.Percentile(99)
+	snap.Percentiles = percentileValues(percentiles, m.ResponseTimes.Percentile)
 	snap.MaxResponseMs = m.ResponseTimes.Max()
 	snap.MinResponseMs = m.ResponseTimes.Min()
 
@@ -88,6 +220,21 @@ func (m *IncomingRouteMetrics) Reset() {
 	m.TotalResponseMs = 0
 	m.LastRequest = time.Time{}
 	m.ResponseTimes.Reset()
+	m.recent = &windowRing{}
+	m.InvalidRequests = 0
+	m.InvalidByStatus = make(map[int]int64)
+	m.LastViolations = nil
+	m.LastInvalidAt = time.Time{}
+	m.RateLimited = 0
+	m.LastRateLimitedAt = time.Time{}
+	m.CallbacksSent = 0
+	m.CallbacksFailed = 0
+	m.LastCallbackError = ""
+	m.MaxInFlight = m.InFlight
+	m.MaxQueueDepth = m.QueueDepth
+	m.QueuedRequests = 0
+	m.TotalQueueWaitMs = 0
+	m.Overloaded = 0
 }
 
 // IncomingRouteSnapshot is a serializable snapshot of incoming route metrics
@@ -101,18 +248,49 @@ type IncomingRouteSnapshot struct {
 	MaxResponseMs float64 `json:"max_response_ms"`
 	MinResponseMs float64 `json:"min_response_ms"`
 
+	// Response time percentiles by name, such as p50 and p99.9, as configured
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+
 	LastRequest string `json:"last_request,omitempty"`
 
+	InvalidRequests int64         `json:"invalid_requests"`
+	InvalidByStatus map[int]int64 `json:"invalid_by_status,omitempty"`
+	LastViolations  []string      `json:"last_violations,omitempty"`
+	LastInvalidAt   string        `json:"last_invalid_at,omitempty"`
+
+	RateLimited       int64  `json:"rate_limited"`
+	LastRateLimitedAt string `json:"last_rate_limited_at,omitempty"`
+
+	CallbacksSent     int64  `json:"callbacks_sent"`
+	CallbacksFailed   int64  `json:"callbacks_failed"`
+	LastCallbackError string `json:"last_callback_error,omitempty"`
+
+	InFlight       int64   `json:"in_flight"`
+	MaxInFlight    int64   `json:"max_in_flight"`
+	QueueDepth     int64   `json:"queue_depth"`
+	MaxQueueDepth  int64   `json:"max_queue_depth"`
+	QueuedRequests int64   `json:"queued_requests"`
+	AvgQueueWaitMs float64 `json:"avg_queue_wait_ms"`
+	Overloaded     int64   `json:"overloaded"`
+
 	RouteName string `json:"route_name"`
 	RoutePath string `json:"route_path"`
 }
 
 // IncomingCollector collects and aggregates metrics for incoming routes
 type IncomingCollector struct {
-	startTime     time.Time
-	totalRequests int64
+	startTime       time.Time
+	totalRequests   int64
+	invalidRequests int64
+	rateLimited     int64
+	overloaded      int64
+	changes         int64 // Counts recordings and resets
+
+	routes  map[string]*IncomingRouteMetrics  // keyed by route name
+	clients map[string]*IncomingClientMetrics // keyed by caller identity, when segmentation is enabled
 
-	routes map[string]*IncomingRouteMetrics // keyed by route name
+	labels      LabelSource      // Run labels attached to snapshots
+	percentiles PercentileSource // Latency percentiles reported by snapshots
 
 	mu sync.RWMutex
 }
@@ -122,9 +300,22 @@ func NewIncomingCollector() *IncomingCollector {
 	return &IncomingCollector{
 		startTime: time.Now(),
 		routes:    make(map[string]*IncomingRouteMetrics),
+		clients:   make(map[string]*IncomingClientMetrics),
 	}
 }
 
+// route returns the metrics of a route, creating them on first use, and counts
+// the change the caller records. c.mu must be held.
+func (c *IncomingCollector) route(routeName, routePath string) *IncomingRouteMetrics {
+	atomic.AddInt64(&c.changes, 1)
+	route, exists := c.routes[routeName]
+	if !exists {
+		route = NewIncomingRouteMetrics(routeName, routePath)
+		c.routes[routeName] = route
+	}
+	return route
+}
+
 // Record records a request to an incoming route
 func (c *IncomingCollector) Record(routeName, routePath string, statusCode int, responseTimeMs float64) {
 	c.mu.Lock()
@@ -132,14 +323,86 @@ func (c *IncomingCollector) Record(routeName, routePath string, statusCode int,
 
 	atomic.AddInt64(&c.totalRequests, 1)
 
-	// Get or create route metrics
-	route, exists := c.routes[routeName]
-	if !exists {
-		route = NewIncomingRouteMetrics(routeName, routePath)
-		c.routes[routeName] = route
-	}
+	c.route(routeName, routePath).Record(statusCode, responseTimeMs)
+}
+
+// RecordInvalid records a request rejected by an incoming route's validation rules
+func (c *IncomingCollector) RecordInvalid(routeName, routePath string, statusCode int, violations []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	atomic.AddInt64(&c.invalidRequests, 1)
+
+	c.route(routeName, routePath).RecordInvalid(statusCode, violations)
+}
+
+// RecordRateLimited records a request rejected by an incoming route's simulated rate limit
+func (c *IncomingCollector) RecordRateLimited(routeName, routePath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	atomic.AddInt64(&c.rateLimited, 1)
+
+	c.route(routeName, routePath).RecordRateLimited()
+}
+
+// RecordCallback records the outcome of an incoming route's webhook callback
+func (c *IncomingCollector) RecordCallback(routeName, routePath string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.route(routeName, routePath).RecordCallback(err)
+}
+
+// AddInFlight adjusts the number of requests an incoming route's simulated
+// worker pool is serving
+func (c *IncomingCollector) AddInFlight(routeName, routePath string, delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	route.Record(statusCode, responseTimeMs)
+	c.route(routeName, routePath).AddInFlight(delta)
+}
+
+// AddQueued adjusts the number of requests waiting for a worker of an
+// incoming route's simulated worker pool
+func (c *IncomingCollector) AddQueued(routeName, routePath string, delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.route(routeName, routePath).AddQueued(delta)
+}
+
+// RecordQueueWait records how long a request waited for a worker
+func (c *IncomingCollector) RecordQueueWait(routeName, routePath string, waitMs float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.route(routeName, routePath).RecordQueueWait(waitMs)
+}
+
+// RecordOverloaded records a request rejected with 503 because all workers
+// of an incoming route were busy and its queue was full
+func (c *IncomingCollector) RecordOverloaded(routeName, routePath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	atomic.AddInt64(&c.overloaded, 1)
+	c.route(routeName, routePath).RecordOverloaded()
+}
+
+// SetLabelSource sets the function returning the run labels attached to snapshots
+func (c *IncomingCollector) SetLabelSource(labels LabelSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = labels
+}
+
+// SetPercentileSource sets the function returning the latency percentiles
+// reported by snapshots
+func (c *IncomingCollector) SetPercentileSource(percentiles PercentileSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.percentiles = percentiles
 }
 
 // Snapshot returns a serializable snapshot of all incoming route metrics
@@ -150,10 +413,14 @@ func (c *IncomingCollector) Snapshot() *IncomingMetricsSnapshot {
 	uptime := time.Since(c.startTime).Seconds()
 
 	snapshot := &IncomingMetricsSnapshot{
-		UptimeSeconds: uptime,
-		TotalRequests: atomic.LoadInt64(&c.totalRequests),
-		Routes:        make(map[string]IncomingRouteSnapshot),
-		CollectedAt:   time.Now().Format(time.RFC3339),
+		UptimeSeconds:   uptime,
+		TotalRequests:   atomic.LoadInt64(&c.totalRequests),
+		InvalidRequests: atomic.LoadInt64(&c.invalidRequests),
+		RateLimited:     atomic.LoadInt64(&c.rateLimited),
+		Overloaded:      atomic.LoadInt64(&c.overloaded),
+		Routes:          make(map[string]IncomingRouteSnapshot),
+		CollectedAt:     time.Now().Format(time.RFC3339),
+		Labels:          c.labels.get(),
 	}
 
 	// Calculate requests per second
@@ -162,8 +429,16 @@ func (c *IncomingCollector) Snapshot() *IncomingMetricsSnapshot {
 	}
 
 	// Collect route metrics
+	percentiles := c.percentiles.get()
 	for name, route := range c.routes {
-		snapshot.Routes[name] = route.GetStats()
+		snapshot.Routes[name] = route.GetStats(percentiles...)
+	}
+
+	if len(c.clients) > 0 {
+		snapshot.Clients = make(map[string]IncomingClientSnapshot, len(c.clients))
+		for id, client := range c.clients {
+			snapshot.Clients[id] = client.GetStats()
+		}
 	}
 
 	return snapshot
@@ -175,8 +450,19 @@ func (c *IncomingCollector) Reset() {
 	defer c.mu.Unlock()
 
 	c.startTime = time.Now()
+	atomic.AddInt64(&c.changes, 1)
 	atomic.StoreInt64(&c.totalRequests, 0)
+	atomic.StoreInt64(&c.invalidRequests, 0)
+	atomic.StoreInt64(&c.rateLimited, 0)
+	atomic.StoreInt64(&c.overloaded, 0)
 	c.routes = make(map[string]*IncomingRouteMetrics)
+	c.clients = make(map[string]*IncomingClientMetrics)
+}
+
+// Changes returns a counter that increases whenever metrics are recorded or
+// reset, so callers can tell whether anything changed since they last looked
+func (c *IncomingCollector) Changes() int64 {
+	return atomic.LoadInt64(&c.changes)
 }
 
 // GetTotalRequests returns the total number of incoming requests
@@ -203,15 +489,20 @@ func (c *IncomingCollector) GetRouteMetrics(routeName string) (*IncomingRouteSna
 		return nil, false
 	}
 
-	stats := route.GetStats()
+	stats := route.GetStats(c.percentiles.get()...)
 	return &stats, true
 }
 
 // IncomingMetricsSnapshot is a serializable snapshot of all incoming metrics
 type IncomingMetricsSnapshot struct {
-	UptimeSeconds     float64                          `json:"uptime_seconds"`
-	TotalRequests     int64                            `json:"total_requests"`
-	RequestsPerSecond float64                          `json:"requests_per_second"`
-	CollectedAt       string                           `json:"collected_at"`
-	Routes            map[string]IncomingRouteSnapshot `json:"routes"`
+	UptimeSeconds     float64                           `json:"uptime_seconds"`
+	TotalRequests     int64                             `json:"total_requests"`
+	InvalidRequests   int64                             `json:"invalid_requests"`
+	RateLimited       int64                             `json:"rate_limited"`
+	Overloaded        int64                             `json:"overloaded"`
+	RequestsPerSecond float64                           `json:"requests_per_second"`
+	CollectedAt       string                            `json:"collected_at"`
+	Routes            map[string]IncomingRouteSnapshot  `json:"routes"`
+	Clients           map[string]IncomingClientSnapshot `json:"clients,omitempty"` // Per caller, when incoming_clients is enabled
+	Labels            map[string]string                 `json:"labels,omitempty"`  // Run labels, such as env or branch
 }
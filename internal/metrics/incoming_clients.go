@@ -0,0 +1,110 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OtherClients groups the traffic of callers beyond the tracked maximum
+const OtherClients = "other"
+
+// IncomingClientMetrics holds the incoming traffic of one caller
+type IncomingClientMetrics struct {
+	TotalRequests     int64
+	ResponsesByStatus map[int]int64
+	RequestsByRoute   map[string]int64
+	FirstSeen         time.Time
+	LastSeen          time.Time
+
+	mu sync.Mutex
+}
+
+// NewIncomingClientMetrics creates new incoming client metrics
+func NewIncomingClientMetrics() *IncomingClientMetrics {
+	return &IncomingClientMetrics{
+		ResponsesByStatus: make(map[int]int64),
+		RequestsByRoute:   make(map[string]int64),
+	}
+}
+
+// Record records a request from this caller
+func (m *IncomingClientMetrics) Record(routeName string, statusCode int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.FirstSeen.IsZero() {
+		m.FirstSeen = now
+	}
+	m.LastSeen = now
+	m.TotalRequests++
+	m.ResponsesByStatus[statusCode]++
+	m.RequestsByRoute[routeName]++
+}
+
+// GetStats returns a snapshot of the caller's metrics
+func (m *IncomingClientMetrics) GetStats() IncomingClientSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snap := IncomingClientSnapshot{
+		TotalRequests:     m.TotalRequests,
+		ResponsesByStatus: make(map[int]int64, len(m.ResponsesByStatus)),
+		RequestsByRoute:   make(map[string]int64, len(m.RequestsByRoute)),
+		FirstSeen:         m.FirstSeen.Format(time.RFC3339),
+		LastSeen:          m.LastSeen.Format(time.RFC3339),
+	}
+	for status, count := range m.ResponsesByStatus {
+		snap.ResponsesByStatus[status] = count
+	}
+	for route, count := range m.RequestsByRoute {
+		snap.RequestsByRoute[route] = count
+	}
+	return snap
+}
+
+// IncomingClientSnapshot is a serializable snapshot of a caller's incoming traffic
+type IncomingClientSnapshot struct {
+	TotalRequests     int64            `json:"total_requests"`
+	ResponsesByStatus map[int]int64    `json:"responses_by_status"`
+	RequestsByRoute   map[string]int64 `json:"requests_by_route"`
+	FirstSeen         string           `json:"first_seen"`
+	LastSeen          string           `json:"last_seen"`
+}
+
+// RecordClient records a request to an incoming route by caller identity,
+// including requests rejected by rate limits or validation. At most
+// maxClients callers are tracked; later ones are grouped under OtherClients.
+func (c *IncomingCollector) RecordClient(clientID, routeName string, statusCode int, maxClients int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	atomic.AddInt64(&c.changes, 1)
+	client, exists := c.clients[clientID]
+	if !exists {
+		if len(c.clients) >= maxClients {
+			clientID = OtherClients
+			client = c.clients[OtherClients]
+		}
+		if client == nil {
+			client = NewIncomingClientMetrics()
+			c.clients[clientID] = client
+		}
+	}
+
+	client.Record(routeName, statusCode)
+}
+
+// GetClientMetrics returns the incoming traffic of every tracked caller
+func (c *IncomingCollector) GetClientMetrics() map[string]IncomingClientSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	clients := make(map[string]IncomingClientSnapshot, len(c.clients))
+	for id, client := range c.clients {
+		clients[id] = client.GetStats()
+	}
+	return clients
+}
@@ -204,3 +204,106 @@ func TestIncomingCollector_Percentiles(t *testing.T) {
 		t.Errorf("Min should be 1, got %.2f", stats.MinResponseMs)
 	}
 }
+
+func TestIncomingCollector_RejectedRequests(t *testing.T) {
+	collector := NewIncomingCollector()
+
+	collector.Record("orders", "/api/orders", 200, 10.0)
+	collector.RecordInvalid("orders", "/api/orders", 422, []string{"missing required header X-Request-ID"})
+	collector.RecordRateLimited("orders", "/api/orders")
+	collector.RecordRateLimited("orders", "/api/orders")
+
+	snapshot := collector.Snapshot()
+	if snapshot.TotalRequests != 1 || snapshot.InvalidRequests != 1 || snapshot.RateLimited != 2 {
+		t.Errorf("expected 1 total, 1 invalid and 2 rate limited, got %d, %d and %d",
+			snapshot.TotalRequests, snapshot.InvalidRequests, snapshot.RateLimited)
+	}
+
+	route := snapshot.Routes["orders"]
+	if route.TotalRequests != 1 || route.AvgResponseMs != 10.0 {
+		t.Errorf("expected rejected requests to stay out of route totals, got %+v", route)
+	}
+	if route.InvalidByStatus[422] != 1 || len(route.LastViolations) != 1 || route.RateLimited != 2 {
+		t.Errorf("unexpected rejection stats: %+v", route)
+	}
+
+	collector.Reset()
+	if snapshot := collector.Snapshot(); snapshot.InvalidRequests != 0 || snapshot.RateLimited != 0 {
+		t.Errorf("expected rejection counts to be reset, got %+v", snapshot)
+	}
+}
+
+func TestIncomingCollector_RecordClient(t *testing.T) {
+	collector := NewIncomingCollector()
+
+	collector.RecordClient("10.0.0.1", "orders", 200, 2)
+	collector.RecordClient("10.0.0.1", "users", 429, 2)
+	collector.RecordClient("10.0.0.2", "orders", 200, 2)
+	collector.RecordClient("10.0.0.3", "orders", 500, 2)
+	collector.RecordClient("10.0.0.4", "orders", 200, 2)
+
+	clients := collector.Snapshot().Clients
+	if len(clients) != 3 {
+		t.Fatalf("expected 2 tracked clients plus %q, got %v", OtherClients, clients)
+	}
+
+	first := clients["10.0.0.1"]
+	if first.TotalRequests != 2 || first.RequestsByRoute["users"] != 1 || first.ResponsesByStatus[429] != 1 {
+		t.Errorf("unexpected stats for 10.0.0.1: %+v", first)
+	}
+	if other := clients[OtherClients]; other.TotalRequests != 2 {
+		t.Errorf("expected 2 requests grouped as other, got %d", other.TotalRequests)
+	}
+
+	collector.Reset()
+	if clients := collector.GetClientMetrics(); len(clients) != 0 {
+		t.Errorf("expected clients to be reset, got %v", clients)
+	}
+}
+
+func TestIncomingCollector_WorkerPool(t *testing.T) {
+	collector := NewIncomingCollector()
+
+	collector.AddInFlight("orders", "/api/orders", 1)
+	collector.AddInFlight("orders", "/api/orders", 1)
+	collector.AddQueued("orders", "/api/orders", 1)
+	collector.AddQueued("orders", "/api/orders", -1)
+	collector.RecordQueueWait("orders", "/api/orders", 40)
+	collector.AddInFlight("orders", "/api/orders", -1)
+	collector.RecordOverloaded("orders", "/api/orders")
+
+	snapshot := collector.Snapshot()
+	route := snapshot.Routes["orders"]
+	if route.InFlight != 1 || route.MaxInFlight != 2 || route.QueueDepth != 0 || route.MaxQueueDepth != 1 {
+		t.Errorf("unexpected worker pool gauges: %+v", route)
+	}
+	if route.QueuedRequests != 1 || route.AvgQueueWaitMs != 40 || route.Overloaded != 1 || snapshot.Overloaded != 1 {
+		t.Errorf("unexpected worker pool counters: %+v", route)
+	}
+
+	// Requests finishing after a reset don't push the gauge below zero
+	collector.Reset()
+	collector.AddInFlight("orders", "/api/orders", -1)
+	if route := collector.Snapshot().Routes["orders"]; route.InFlight != 0 {
+		t.Errorf("expected in-flight gauge to stay at 0, got %d", route.InFlight)
+	}
+}
+
+func TestIncomingCollector_Changes(t *testing.T) {
+	collector := NewIncomingCollector()
+	start := collector.Changes()
+
+	collector.Record("route1", "/api/route1", 200, 100.0)
+	afterRecord := collector.Changes()
+	if afterRecord <= start {
+		t.Errorf("expected a recording to count as a change, got %d after %d", afterRecord, start)
+	}
+	if collector.Changes() != afterRecord {
+		t.Error("expected no change without recordings")
+	}
+
+	collector.Reset()
+	if collector.Changes() <= afterRecord {
+		t.Error("expected a reset to count as a change")
+	}
+}
@@ -0,0 +1,29 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// LabelSource returns the current run labels, such as env=staging or
+// branch=main. It is called for every snapshot, so label changes apply live.
+type LabelSource func() map[string]string
+
+// get returns the labels, nil without a source
+func (l LabelSource) get() map[string]string {
+	if l == nil {
+		return nil
+	}
+	return l()
+}
+
+// FormatLabels formats labels as sorted key=value pairs, e.g. "branch=main, env=staging"
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
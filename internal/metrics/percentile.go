@@ -1,7 +1,11 @@
 // Package metrics provides in-memory metrics collection
 package metrics
 
-import "sort"
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // RingBuffer is a fixed-size circular buffer for storing recent values
 type RingBuffer struct {
@@ -107,3 +111,65 @@ func (rb *RingBuffer) Values() []float64 {
 	copy(result, rb.data[:rb.size])
 	return result
 }
+
+// DefaultPercentiles are the latency percentiles reported unless configured
+var DefaultPercentiles = []float64{95, 99}
+
+// PercentileSource returns the latency percentiles to report, such as 50, 90
+// and 99.9. It is called for every snapshot, so changes apply live.
+type PercentileSource func() []float64
+
+// get returns the percentiles, the defaults without a source or with none
+func (p PercentileSource) get() []float64 {
+	if p == nil {
+		return DefaultPercentiles
+	}
+	if percentiles := p(); len(percentiles) > 0 {
+		return percentiles
+	}
+	return DefaultPercentiles
+}
+
+// PercentileLabel returns the name of a percentile, such as p50 or p99.9
+func PercentileLabel(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// percentileValues returns the percentiles of a distribution by label, nil
+// for no percentiles
+func percentileValues(percentiles []float64, percentile func(float64) float64) map[string]float64 {
+	if len(percentiles) == 0 {
+		return nil
+	}
+	values := make(map[string]float64, len(percentiles))
+	for _, p := range percentiles {
+		values[PercentileLabel(p)] = percentile(p)
+	}
+	return values
+}
+
+// quantile is a percentile of a distribution as a Prometheus quantile
+type quantile struct {
+	p     float64
+	value float64
+}
+
+// label returns the quantile label, such as 0.999 for p99.9
+func (q quantile) label() string {
+	// 12 digits drop the rounding error of p/100
+	return strconv.FormatFloat(q.p/100, 'g', 12, 64)
+}
+
+// quantiles returns percentiles by label as quantiles in ascending order
+func quantiles(percentiles map[string]float64) []quantile {
+	result := make([]quantile, 0, len(percentiles))
+	for label, value := range percentiles {
+		p, err := strconv.ParseFloat(strings.TrimPrefix(label, "p"), 64)
+		if err != nil {
+			continue
+		}
+		result = append(result, quantile{p: p, value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].p < result[j].p })
+	return result
+}
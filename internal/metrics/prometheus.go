@@ -0,0 +1,268 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"moxapp/internal/resources"
+)
+
+// PrometheusContentType is the content type of the Prometheus text format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// promWriter writes metric families in the Prometheus text format, adding the
+// run labels to every series
+type promWriter struct {
+	w      io.Writer
+	labels string // Rendered run labels, e.g. env="staging"
+	err    error
+}
+
+// family writes the HELP and TYPE lines of a metric
+func (p *promWriter) family(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one series; labels are name/value pairs
+func (p *promWriter) sample(name string, value float64, labels ...string) {
+	var b strings.Builder
+	b.WriteString(p.labels)
+	for i := 0; i+1 < len(labels); i += 2 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labels[i] + "=" + promQuote(labels[i+1]))
+	}
+	if b.Len() > 0 {
+		p.printf("%s{%s} %s\n", name, b.String(), strconv.FormatFloat(value, 'g', -1, 64))
+	} else {
+		p.printf("%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+}
+
+func (p *promWriter) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+// promQuote quotes a label value, escaping backslashes, quotes and newlines
+func promQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// renderLabels renders run labels sorted by name
+func renderLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + promQuote(labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WritePrometheus writes outgoing and incoming metrics in the Prometheus text
+// exposition format. The outgoing snapshot's run labels are added to every
+// series. Latencies are in seconds, as is customary for Prometheus. The
+// generator's own resource usage is included if the snapshot has it.
+func WritePrometheus(w io.Writer, outgoing *MetricsSnapshot, incoming *IncomingMetricsSnapshot) error {
+	p := &promWriter{w: w, labels: renderLabels(outgoing.Labels)}
+	endpoints := sortedKeys(outgoing.Endpoints)
+
+	p.family("moxapp_uptime_seconds", "gauge", "Seconds since the metrics were last reset.")
+	p.sample("moxapp_uptime_seconds", outgoing.UptimeSeconds)
+
+	p.family("moxapp_outgoing_requests_total", "counter", "Outgoing requests sent.")
+	for _, name := range endpoints {
+		p.sample("moxapp_outgoing_requests_total", float64(outgoing.Endpoints[name].TotalRequests), "endpoint", name)
+	}
+
+	p.family("moxapp_outgoing_failures_total", "counter", "Failed outgoing requests by error type.")
+	for _, name := range endpoints {
+		ep := outgoing.Endpoints[name]
+		for _, e := range []struct {
+			kind  string
+			count int64
+		}{
+			{"timeout", ep.TimeoutErrors}, {"dns", ep.DNSErrors}, {"connection", ep.ConnectionErrors},
+			{"http", ep.HTTPErrors}, {"other", ep.OtherErrors},
+		} {
+			p.sample("moxapp_outgoing_failures_total", float64(e.count), "endpoint", name, "error_type", e.kind)
+		}
+	}
+
+	p.family("moxapp_outgoing_request_duration_seconds", "summary", "Total time of outgoing requests.")
+	for _, name := range endpoints {
+		ep := outgoing.Endpoints[name]
+		for _, q := range latencyQuantiles(ep.Percentiles, ep.P95TotalTimeMs, ep.P99TotalTimeMs) {
+			p.sample("moxapp_outgoing_request_duration_seconds", q.value/1000, "endpoint", name, "quantile", q.label())
+		}
+		p.sample("moxapp_outgoing_request_duration_seconds_sum", ep.AvgTotalTimeMs*float64(ep.TotalRequests)/1000, "endpoint", name)
+		p.sample("moxapp_outgoing_request_duration_seconds_count", float64(ep.TotalRequests), "endpoint", name)
+	}
+
+	p.family("moxapp_outgoing_apdex", "gauge", "Apdex score of outgoing requests, from 0 (all frustrated) to 1 (all satisfied).")
+	for _, name := range endpoints {
+		if apdex := outgoing.Endpoints[name].Apdex; apdex != nil {
+			p.sample("moxapp_outgoing_apdex", *apdex, "endpoint", name)
+		}
+	}
+
+	p.family("moxapp_outgoing_dns_duration_seconds", "summary", "DNS resolution time of outgoing requests.")
+	for _, name := range endpoints {
+		ep := outgoing.Endpoints[name]
+		p.sample("moxapp_outgoing_dns_duration_seconds", ep.P95DNSTimeMs/1000, "endpoint", name, "quantile", "0.95")
+		p.sample("moxapp_outgoing_dns_duration_seconds_sum", ep.AvgDNSTimeMs*float64(ep.TotalRequests)/1000, "endpoint", name)
+		p.sample("moxapp_outgoing_dns_duration_seconds_count", float64(ep.TotalRequests), "endpoint", name)
+	}
+
+	p.family("moxapp_outgoing_sent_bytes_total", "counter", "Request body bytes sent.")
+	for _, name := range endpoints {
+		p.sample("moxapp_outgoing_sent_bytes_total", float64(outgoing.Endpoints[name].BytesSent), "endpoint", name)
+	}
+	p.family("moxapp_outgoing_received_bytes_total", "counter", "Response body bytes received.")
+	for _, name := range endpoints {
+		p.sample("moxapp_outgoing_received_bytes_total", float64(outgoing.Endpoints[name].BytesReceived), "endpoint", name)
+	}
+
+	if len(outgoing.InFlightByHost) > 0 {
+		p.family("moxapp_outgoing_in_flight", "gauge", "Queued and running outgoing requests per hostname.")
+		for _, host := range sortedKeys(outgoing.InFlightByHost) {
+			p.sample("moxapp_outgoing_in_flight", float64(outgoing.InFlightByHost[host].InFlight), "hostname", host)
+		}
+	}
+
+	if outgoing.MaxInFlight > 0 {
+		p.family("moxapp_outgoing_requests_in_flight", "gauge", "Outgoing requests being sent.")
+		p.sample("moxapp_outgoing_requests_in_flight", float64(outgoing.InFlight))
+		p.family("moxapp_outgoing_requests_in_flight_max", "gauge", "Peak of outgoing requests being sent at once.")
+		p.sample("moxapp_outgoing_requests_in_flight_max", float64(outgoing.MaxInFlight))
+	}
+
+	domains := sortedKeys(outgoing.DNSStatsByDomain)
+	p.family("moxapp_dns_lookups_total", "counter", "DNS lookups of outgoing requests.")
+	for _, domain := range domains {
+		p.sample("moxapp_dns_lookups_total", float64(outgoing.DNSStatsByDomain[domain].TotalLookups), "domain", domain)
+	}
+	p.family("moxapp_dns_lookup_failures_total", "counter", "Failed DNS lookups of outgoing requests.")
+	for _, domain := range domains {
+		p.sample("moxapp_dns_lookup_failures_total", float64(outgoing.DNSStatsByDomain[domain].FailedLookups), "domain", domain)
+	}
+	p.family("moxapp_dns_lookup_errors_total", "counter", "Failed DNS lookups of outgoing requests by error type.")
+	for _, domain := range domains {
+		errorsByType := outgoing.DNSStatsByDomain[domain].ErrorsByType
+		for _, kind := range sortedKeys(errorsByType) {
+			p.sample("moxapp_dns_lookup_errors_total", float64(errorsByType[kind]), "domain", domain, "type", kind)
+		}
+	}
+
+	if len(outgoing.TLSByHost) > 0 {
+		p.family("moxapp_tls_cert_expiry_days", "gauge", "Days until the certificate of a hostname expires, negative once expired.")
+		for _, host := range sortedKeys(outgoing.TLSByHost) {
+			if tls := outgoing.TLSByHost[host]; tls.CertNotAfter != "" {
+				p.sample("moxapp_tls_cert_expiry_days", tls.DaysRemaining, "hostname", host)
+			}
+		}
+	}
+
+	if process := outgoing.Process; process != nil {
+		p.writeProcess(process)
+	}
+
+	if incoming != nil {
+		routes := sortedKeys(incoming.Routes)
+
+		p.family("moxapp_incoming_requests_total", "counter", "Requests received by simulated incoming routes.")
+		for _, name := range routes {
+			p.sample("moxapp_incoming_requests_total", float64(incoming.Routes[name].TotalRequests), "route", name)
+		}
+
+		p.family("moxapp_incoming_responses_total", "counter", "Responses of simulated incoming routes by status code.")
+		for _, name := range routes {
+			byStatus := incoming.Routes[name].ResponsesByStatus
+			statuses := make([]int, 0, len(byStatus))
+			for status := range byStatus {
+				statuses = append(statuses, status)
+			}
+			sort.Ints(statuses)
+			for _, status := range statuses {
+				p.sample("moxapp_incoming_responses_total", float64(byStatus[status]), "route", name, "status", strconv.Itoa(status))
+			}
+		}
+
+		p.family("moxapp_incoming_response_duration_seconds", "summary", "Response time of simulated incoming routes.")
+		for _, name := range routes {
+			route := incoming.Routes[name]
+			for _, q := range latencyQuantiles(route.Percentiles, route.P95ResponseMs, route.P99ResponseMs) {
+				p.sample("moxapp_incoming_response_duration_seconds", q.value/1000, "route", name, "quantile", q.label())
+			}
+			p.sample("moxapp_incoming_response_duration_seconds_sum", route.AvgResponseMs*float64(route.TotalRequests)/1000, "route", name)
+			p.sample("moxapp_incoming_response_duration_seconds_count", float64(route.TotalRequests), "route", name)
+		}
+
+		p.family("moxapp_incoming_in_flight", "gauge", "Requests being handled by simulated incoming routes.")
+		for _, name := range routes {
+			p.sample("moxapp_incoming_in_flight", float64(incoming.Routes[name].InFlight), "route", name)
+		}
+	}
+
+	return p.err
+}
+
+// writeProcess writes the resource usage of the generator itself
+func (p *promWriter) writeProcess(process *resources.Process) {
+	for _, g := range []struct {
+		name, help string
+		value      float64
+	}{
+		{"moxapp_process_cpu_percent", "CPU used by the generator in percent of all cores.", process.CPUPercent},
+		{"moxapp_process_resident_memory_bytes", "Resident memory of the generator.", process.MemoryMB * 1024 * 1024},
+		{"moxapp_process_open_fds", "Open file descriptors of the generator.", float64(process.OpenFDs)},
+		{"moxapp_process_max_fds", "Limit of open file descriptors of the generator.", float64(process.MaxFDs)},
+		{"moxapp_process_open_sockets", "Open sockets of the generator.", float64(process.OpenSockets)},
+		{"moxapp_goroutines", "Goroutines of the generator.", float64(process.Goroutines)},
+		{"moxapp_heap_alloc_bytes", "Heap memory allocated by the generator.", process.HeapMB * 1024 * 1024},
+		{"moxapp_gc_last_pause_seconds", "Duration of the last garbage collection pause.", process.GC.LastPauseMs / 1000},
+		{"moxapp_gc_max_pause_seconds", "Longest of the last 256 garbage collection pauses.", process.GC.MaxPauseMs / 1000},
+		{"moxapp_gc_cpu_fraction", "Fraction of the available CPU time spent in garbage collection.", process.GC.CPUFraction},
+	} {
+		p.family(g.name, "gauge", g.help)
+		p.sample(g.name, g.value)
+	}
+
+	p.family("moxapp_gc_cycles_total", "counter", "Completed garbage collection cycles.")
+	p.sample("moxapp_gc_cycles_total", float64(process.GC.Cycles))
+	p.family("moxapp_gc_pause_seconds_total", "counter", "Total time of garbage collection pauses.")
+	p.sample("moxapp_gc_pause_seconds_total", process.GC.PauseTotalMs/1000)
+}
+
+// latencyQuantiles returns the configured latency percentiles of a snapshot
+// as quantiles, p95 and p99 for snapshots without them, such as baselines
+// saved by older versions
+func latencyQuantiles(percentiles map[string]float64, p95, p99 float64) []quantile {
+	if len(percentiles) == 0 {
+		return []quantile{{p: 95, value: p95}, {p: 99, value: p99}}
+	}
+	return quantiles(percentiles)
+}
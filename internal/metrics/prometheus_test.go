@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"moxapp/internal/client"
+	"moxapp/internal/resources"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	outgoing := &MetricsSnapshot{
+		UptimeSeconds: 60,
+		Labels:        map[string]string{"env": "staging", "branch": `fix "quotes"`},
+		Endpoints: map[string]EndpointSnapshot{
+			"checkout": {TotalRequests: 10, TimeoutErrors: 2, P95TotalTimeMs: 250, AvgTotalTimeMs: 100},
+		},
+		DNSStatsByDomain: map[string]DomainSnapshot{"api.example.com": {TotalLookups: 3, FailedLookups: 1}},
+	}
+	incoming := &IncomingMetricsSnapshot{
+		Routes: map[string]IncomingRouteSnapshot{
+			"webhook": {TotalRequests: 4, ResponsesByStatus: map[int]int64{200: 3, 503: 1}},
+		},
+	}
+
+	var out strings.Builder
+	if err := WritePrometheus(&out, outgoing, incoming); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+
+	for _, want := range []string{
+		"# TYPE moxapp_outgoing_requests_total counter\n",
+		`moxapp_outgoing_requests_total{branch="fix \"quotes\"",env="staging",endpoint="checkout"} 10` + "\n",
+		`moxapp_outgoing_failures_total{branch="fix \"quotes\"",env="staging",endpoint="checkout",error_type="timeout"} 2` + "\n",
+		`moxapp_outgoing_request_duration_seconds{branch="fix \"quotes\"",env="staging",endpoint="checkout",quantile="0.95"} 0.25` + "\n",
+		`moxapp_outgoing_request_duration_seconds_sum{branch="fix \"quotes\"",env="staging",endpoint="checkout"} 1` + "\n",
+		`moxapp_dns_lookup_failures_total{branch="fix \"quotes\"",env="staging",domain="api.example.com"} 1` + "\n",
+		`moxapp_incoming_responses_total{branch="fix \"quotes\"",env="staging",route="webhook",status="503"} 1` + "\n",
+		`moxapp_uptime_seconds{branch="fix \"quotes\"",env="staging"} 60` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestWritePrometheus_Apdex(t *testing.T) {
+	score := 0.75
+	outgoing := &MetricsSnapshot{
+		Endpoints: map[string]EndpointSnapshot{
+			"checkout": {TotalRequests: 4, Apdex: &score, ApdexSatisfied: 3, ApdexFrustrated: 1},
+			"idle":     {},
+		},
+	}
+
+	var out strings.Builder
+	WritePrometheus(&out, outgoing, nil)
+	if !strings.Contains(out.String(), "\nmoxapp_outgoing_apdex{endpoint=\"checkout\"} 0.75\n") {
+		t.Errorf("expected the checkout apdex, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), `moxapp_outgoing_apdex{endpoint="idle"}`) {
+		t.Error("expected no apdex series for an endpoint without requests")
+	}
+}
+
+func TestWritePrometheus_NoLabels(t *testing.T) {
+	var out strings.Builder
+	WritePrometheus(&out, &MetricsSnapshot{UptimeSeconds: 5}, nil)
+	if !strings.Contains(out.String(), "\nmoxapp_uptime_seconds 5\n") {
+		t.Errorf("expected an unlabeled series, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "moxapp_incoming") {
+		t.Error("expected no incoming series without an incoming snapshot")
+	}
+	if strings.Contains(out.String(), "moxapp_process") {
+		t.Error("expected no process series without a process sample")
+	}
+}
+
+func TestWritePrometheus_Process(t *testing.T) {
+	outgoing := &MetricsSnapshot{
+		InFlight:    3,
+		MaxInFlight: 12,
+		Process: &resources.Process{
+			Usage:      resources.Usage{OpenFDs: 40, MaxFDs: 1024, OpenSockets: 25},
+			Goroutines: 80,
+			GC:         resources.GCStats{Cycles: 7, PauseTotalMs: 1500, LastPauseMs: 2},
+		},
+	}
+
+	var out strings.Builder
+	if err := WritePrometheus(&out, outgoing, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"\nmoxapp_outgoing_requests_in_flight 3\n",
+		"\nmoxapp_outgoing_requests_in_flight_max 12\n",
+		"\nmoxapp_process_open_fds 40\n",
+		"\nmoxapp_process_open_sockets 25\n",
+		"\nmoxapp_goroutines 80\n",
+		"# TYPE moxapp_gc_pause_seconds_total counter\nmoxapp_gc_pause_seconds_total 1.5\n",
+		"\nmoxapp_gc_last_pause_seconds 0.002\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWritePrometheus_Percentiles(t *testing.T) {
+	c := NewCollector()
+	c.SetPercentileSource(func() []float64 { return []float64{99.9, 50} })
+	for i := 1; i <= 1000; i++ {
+		c.Record(&client.RequestResult{EndpointName: "checkout", Success: true, StatusCode: 200, TotalTimeMs: float64(i)})
+	}
+	snap := c.Snapshot()
+	if got := snap.Endpoints["checkout"].Percentiles; got["p50"] != 501 || got["p99.9"] != 1000 || len(got) != 2 {
+		t.Errorf("unexpected percentiles %v", got)
+	}
+
+	var out strings.Builder
+	if err := WritePrometheus(&out, snap, nil); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	median := `moxapp_outgoing_request_duration_seconds{endpoint="checkout",quantile="0.5"} 0.501` + "\n"
+	tail := `moxapp_outgoing_request_duration_seconds{endpoint="checkout",quantile="0.999"} 1` + "\n"
+	if !strings.Contains(text, median) || !strings.Contains(text, tail) || strings.Index(text, median) > strings.Index(text, tail) {
+		t.Errorf("expected the configured quantiles in ascending order in:\n%s", text)
+	}
+	if strings.Contains(text, `moxapp_outgoing_request_duration_seconds{endpoint="checkout",quantile="0.95"}`) {
+		t.Error("expected no default quantiles with configured percentiles")
+	}
+}
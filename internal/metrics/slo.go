@@ -0,0 +1,102 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+// SLO statuses
+const (
+	SLOStatusNoData    = "no_data"   // No requests yet
+	SLOStatusOK        = "ok"        // Within budget, not burning faster than it lasts
+	SLOStatusBurning   = "burning"   // The last 5 minutes burned the budget faster than it lasts
+	SLOStatusExhausted = "exhausted" // The error budget is spent
+)
+
+// sloBurnWindow is the window whose burn rate tells if the budget is burning
+const sloBurnWindow = "5m"
+
+// SLOTarget is the objective of an endpoint's SLO
+type SLOTarget struct {
+	Objective float64 // Percent of requests that must be good
+	LatencyMs int     // Successful requests slower than this are bad too (0 = failures only)
+}
+
+// SLOSnapshot is the error budget of an endpoint's SLO since start. Requests
+// earn budget as they are sent; a burn rate of 1 spends it as fast as it is
+// earned, and above 1 the remaining budget shrinks.
+type SLOSnapshot struct {
+	Objective          float64            `json:"objective"`
+	LatencyMs          int                `json:"latency_ms,omitempty"`
+	TotalRequests      int64              `json:"total_requests"`
+	BadRequests        int64              `json:"bad_requests"`
+	Compliance         float64            `json:"compliance"`           // Percent of good requests
+	ErrorBudget        float64            `json:"error_budget"`         // Bad requests the objective allows so far
+	BudgetRemaining    float64            `json:"budget_remaining"`     // Bad requests left, negative once exceeded
+	BudgetRemainingPct float64            `json:"budget_remaining_pct"` // Share of the budget left, negative once exceeded
+	BurnRate           float64            `json:"burn_rate"`            // Since start
+	BurnRates          map[string]float64 `json:"burn_rates"`           // Within the last 1m, 5m and 15m
+	Status             string             `json:"status"`
+}
+
+// SLOs returns the error budgets of endpoints with an SLO target, keyed by
+// endpoint name. Endpoints without metrics yet are listed with no_data.
+func (c *Collector) SLOs(targets map[string]SLOTarget) map[string]SLOSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	slos := make(map[string]SLOSnapshot, len(targets))
+	for name, target := range targets {
+		var total EndpointSnapshot
+		windows := make(map[string]EndpointSnapshot, len(Windows))
+		if ep, exists := c.endpoints[name]; exists {
+			total = ep.GetStats()
+			for label, window := range Windows {
+				windows[label] = ep.GetWindowStats(window)
+			}
+		}
+		slos[name] = computeSLO(target, total, windows)
+	}
+	return slos
+}
+
+// computeSLO computes the error budget of an SLO from the endpoint's
+// snapshot since start and its windowed snapshots
+func computeSLO(target SLOTarget, total EndpointSnapshot, windows map[string]EndpointSnapshot) SLOSnapshot {
+	allowed := 1 - target.Objective/100
+	slo := SLOSnapshot{
+		Objective:     target.Objective,
+		LatencyMs:     target.LatencyMs,
+		TotalRequests: total.TotalRequests,
+		BadRequests:   total.SLOBreaches,
+		BurnRates:     make(map[string]float64, len(windows)),
+		Status:        SLOStatusNoData,
+	}
+	for label, window := range windows {
+		slo.BurnRates[label] = burnRate(window.SLOBreaches, window.TotalRequests, allowed)
+	}
+	if total.TotalRequests == 0 {
+		return slo
+	}
+
+	slo.Compliance = float64(total.TotalRequests-total.SLOBreaches) / float64(total.TotalRequests) * 100
+	slo.ErrorBudget = allowed * float64(total.TotalRequests)
+	slo.BudgetRemaining = slo.ErrorBudget - float64(total.SLOBreaches)
+	slo.BudgetRemainingPct = slo.BudgetRemaining / slo.ErrorBudget * 100
+	slo.BurnRate = burnRate(total.SLOBreaches, total.TotalRequests, allowed)
+
+	switch {
+	case slo.BudgetRemaining <= 0:
+		slo.Status = SLOStatusExhausted
+	case slo.BurnRates[sloBurnWindow] > 1:
+		slo.Status = SLOStatusBurning
+	default:
+		slo.Status = SLOStatusOK
+	}
+	return slo
+}
+
+// burnRate returns the rate bad requests spend the error budget at: the
+// share of bad requests over the share the objective allows
+func burnRate(bad, total int64, allowed float64) float64 {
+	if total == 0 || allowed <= 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / allowed
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"moxapp/internal/client"
+)
+
+func TestCollectorSLOs(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < 1000; i++ {
+		c.Record(&client.RequestResult{EndpointName: "checkout", Success: true, StatusCode: 200, TotalTimeMs: 50, SLOBreach: i < 5})
+	}
+
+	slos := c.SLOs(map[string]SLOTarget{
+		"checkout": {Objective: 99},
+		"tight":    {Objective: 99.9},
+	})
+	slo := slos["checkout"]
+	// 1% of 1000 requests may be bad, 5 were: half the budget is spent
+	if slo.BadRequests != 5 || slo.Compliance != 99.5 || math.Abs(slo.ErrorBudget-10) > 1e-9 {
+		t.Errorf("unexpected budget %+v", slo)
+	}
+	if math.Abs(slo.BudgetRemainingPct-50) > 1e-9 || math.Abs(slo.BurnRate-0.5) > 1e-9 || slo.Status != SLOStatusOK {
+		t.Errorf("expected half the budget left at burn rate 0.5, got %+v", slo)
+	}
+	if math.Abs(slo.BurnRates["5m"]-0.5) > 1e-9 {
+		t.Errorf("expected the 5m burn rate to cover all requests, got %v", slo.BurnRates)
+	}
+	if slos["tight"].Status != SLOStatusNoData {
+		t.Errorf("expected no_data for an endpoint without requests, got %s", slos["tight"].Status)
+	}
+
+	for i := 0; i < 11; i++ {
+		c.Record(&client.RequestResult{EndpointName: "checkout", SLOBreach: true})
+	}
+	if slo := c.SLOs(map[string]SLOTarget{"checkout": {Objective: 99}})["checkout"]; slo.Status != SLOStatusExhausted || slo.BudgetRemaining >= 0 {
+		t.Errorf("expected the budget to be exhausted, got %+v", slo)
+	}
+}
+
+func TestComputeSLO_Burning(t *testing.T) {
+	total := EndpointSnapshot{TotalRequests: 10000, SLOBreaches: 20}
+	recent := map[string]EndpointSnapshot{"5m": {TotalRequests: 100, SLOBreaches: 3}}
+
+	slo := computeSLO(SLOTarget{Objective: 99}, total, recent)
+	if slo.Status != SLOStatusBurning || math.Abs(slo.BurnRates["5m"]-3) > 1e-9 {
+		t.Errorf("expected a burning budget at 3x in the last 5m, got %+v", slo)
+	}
+}
@@ -0,0 +1,115 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"sort"
+)
+
+// SortByName sorts endpoints alphabetically instead of by a metric
+const SortByName = "name"
+
+// endpointSortValues are the metrics endpoints can be sorted by
+var endpointSortValues = map[string]func(EndpointSnapshot) float64{
+	"error_rate": func(s EndpointSnapshot) float64 { return errorRate(s.Failed, s.TotalRequests) },
+	"errors":     func(s EndpointSnapshot) float64 { return float64(s.Failed) },
+	"requests":   func(s EndpointSnapshot) float64 { return float64(s.TotalRequests) },
+	"avg":        func(s EndpointSnapshot) float64 { return s.AvgTotalTimeMs },
+	"p95":        func(s EndpointSnapshot) float64 { return s.P95TotalTimeMs },
+	"p99":        func(s EndpointSnapshot) float64 { return s.P99TotalTimeMs },
+	"dns":        func(s EndpointSnapshot) float64 { return s.P95DNSTimeMs },
+}
+
+// EndpointSortKeys returns the supported sort keys, SortByName first
+func EndpointSortKeys() []string {
+	keys := make([]string, 0, len(endpointSortValues))
+	for key := range endpointSortValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return append([]string{SortByName}, keys...)
+}
+
+// IsValidEndpointSort reports whether endpoints can be sorted by a key
+func IsValidEndpointSort(by string) bool {
+	_, ok := endpointSortValues[by]
+	return ok || by == SortByName
+}
+
+// EndpointSortValue returns the value an endpoint is sorted by (0 for
+// SortByName or unknown keys)
+func EndpointSortValue(snapshot EndpointSnapshot, by string) float64 {
+	if value, ok := endpointSortValues[by]; ok {
+		return value(snapshot)
+	}
+	return 0
+}
+
+// SortEndpointNames orders names by a sort key, looking their metrics up in
+// endpoints (names without metrics sort as zero). Ties are ordered by name.
+func SortEndpointNames(names []string, endpoints map[string]EndpointSnapshot, by string, desc bool) {
+	value := endpointSortValues[by]
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := names[i], names[j]
+		if value != nil {
+			va, vb := value(endpoints[a]), value(endpoints[b])
+			if va != vb {
+				if desc {
+					return va > vb
+				}
+				return va < vb
+			}
+		}
+		if desc && value == nil {
+			return a > b
+		}
+		return a < b
+	})
+}
+
+// DefaultTopLimit is the number of endpoints returned by TopEndpoints by default
+const DefaultTopLimit = 10
+
+// TopEndpoint is one of the worst endpoints by a metric
+type TopEndpoint struct {
+	Name           string  `json:"name"`
+	Value          float64 `json:"value"` // The metric ranked by
+	TotalRequests  int64   `json:"total_requests"`
+	Failed         int64   `json:"failed"`
+	ErrorRate      float64 `json:"error_rate"`
+	AvgTotalTimeMs float64 `json:"avg_total_time_ms"`
+	P95TotalTimeMs float64 `json:"p95_total_time_ms"`
+	P95DNSTimeMs   float64 `json:"p95_dns_time_ms"`
+	LastError      string  `json:"last_error,omitempty"`
+}
+
+// TopEndpoints returns up to limit endpoints with the highest value of a
+// metric sort key, skipping endpoints where it is zero
+func TopEndpoints(endpoints map[string]EndpointSnapshot, by string, limit int) []TopEndpoint {
+	names := make([]string, 0, len(endpoints))
+	for name, snapshot := range endpoints {
+		if EndpointSortValue(snapshot, by) > 0 {
+			names = append(names, name)
+		}
+	}
+	SortEndpointNames(names, endpoints, by, true)
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+
+	top := make([]TopEndpoint, len(names))
+	for i, name := range names {
+		snapshot := endpoints[name]
+		top[i] = TopEndpoint{
+			Name:           name,
+			Value:          EndpointSortValue(snapshot, by),
+			TotalRequests:  snapshot.TotalRequests,
+			Failed:         snapshot.Failed,
+			ErrorRate:      errorRate(snapshot.Failed, snapshot.TotalRequests),
+			AvgTotalTimeMs: snapshot.AvgTotalTimeMs,
+			P95TotalTimeMs: snapshot.P95TotalTimeMs,
+			P95DNSTimeMs:   snapshot.P95DNSTimeMs,
+			LastError:      snapshot.LastError,
+		}
+	}
+	return top
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortEndpointNames(t *testing.T) {
+	endpoints := map[string]EndpointSnapshot{
+		"a": {TotalRequests: 10, Failed: 5, P95TotalTimeMs: 100},
+		"b": {TotalRequests: 10, Failed: 1, P95TotalTimeMs: 300},
+		"c": {TotalRequests: 10, Failed: 5, P95TotalTimeMs: 200},
+	}
+
+	names := []string{"c", "d", "b", "a"}
+	SortEndpointNames(names, endpoints, "error_rate", true)
+	if want := []string{"a", "c", "b", "d"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("error_rate desc: got %v, want %v", names, want)
+	}
+
+	SortEndpointNames(names, endpoints, "p95", false)
+	if want := []string{"d", "a", "c", "b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("p95 asc: got %v, want %v", names, want)
+	}
+
+	SortEndpointNames(names, nil, SortByName, true)
+	if want := []string{"d", "c", "b", "a"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("name desc: got %v, want %v", names, want)
+	}
+}
+
+func TestTopEndpoints(t *testing.T) {
+	endpoints := map[string]EndpointSnapshot{
+		"a": {TotalRequests: 10, Failed: 2},
+		"b": {TotalRequests: 10, Failed: 7, LastError: "HTTP 500"},
+		"c": {TotalRequests: 10},
+		"d": {TotalRequests: 10, Failed: 4},
+	}
+
+	top := TopEndpoints(endpoints, "errors", 2)
+	if len(top) != 2 || top[0].Name != "b" || top[1].Name != "d" {
+		t.Fatalf("unexpected top endpoints: %+v", top)
+	}
+	if top[0].Value != 7 || top[0].ErrorRate != 70 || top[0].LastError != "HTTP 500" {
+		t.Errorf("unexpected top entry: %+v", top[0])
+	}
+
+	if all := TopEndpoints(endpoints, "errors", 0); len(all) != 3 {
+		t.Errorf("expected endpoints without errors to be skipped, got %+v", all)
+	}
+}
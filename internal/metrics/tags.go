@@ -0,0 +1,81 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+// TagSnapshot aggregates the metrics of all endpoints sharing a tag
+type TagSnapshot struct {
+	Endpoints        []string `json:"endpoints"`
+	TotalRequests    int64    `json:"total_requests"`
+	Successful       int64    `json:"successful"`
+	Failed           int64    `json:"failed"`
+	SuccessRate      float64  `json:"success_rate"`
+	TimeoutErrors    int64    `json:"timeout_errors"`
+	DNSErrors        int64    `json:"dns_errors"`
+	ConnectionErrors int64    `json:"connection_errors"`
+	HTTPErrors       int64    `json:"http_errors"`
+	OtherErrors      int64    `json:"other_errors"`
+	AvgTotalTimeMs   float64  `json:"avg_total_time_ms"` // Weighted by request count
+	MaxTotalTimeMs   float64  `json:"max_total_time_ms"`
+	BytesSent        int64    `json:"bytes_sent"`
+	BytesReceived    int64    `json:"bytes_received"`
+}
+
+// AggregateByTag sums endpoint snapshots per tag. tags maps endpoint names
+// to their tags; tags are compared case-insensitively and reported in lower
+// case. Tagged endpoints without metrics are listed with zero counts.
+func AggregateByTag(endpoints map[string]EndpointSnapshot, tags map[string][]string) map[string]TagSnapshot {
+	result := make(map[string]TagSnapshot)
+	totalTimes := make(map[string]float64)
+
+	for name, endpointTags := range tags {
+		ep := endpoints[name]
+		for _, tag := range endpointTags {
+			tag = strings.ToLower(tag)
+			agg := result[tag]
+			if containsString(agg.Endpoints, name) {
+				continue // Same tag listed twice with different case
+			}
+
+			agg.Endpoints = append(agg.Endpoints, name)
+			agg.TotalRequests += ep.TotalRequests
+			agg.Successful += ep.Successful
+			agg.Failed += ep.Failed
+			agg.TimeoutErrors += ep.TimeoutErrors
+			agg.DNSErrors += ep.DNSErrors
+			agg.ConnectionErrors += ep.ConnectionErrors
+			agg.HTTPErrors += ep.HTTPErrors
+			agg.OtherErrors += ep.OtherErrors
+			agg.BytesSent += ep.BytesSent
+			agg.BytesReceived += ep.BytesReceived
+			if ep.MaxTotalTimeMs > agg.MaxTotalTimeMs {
+				agg.MaxTotalTimeMs = ep.MaxTotalTimeMs
+			}
+			totalTimes[tag] += ep.AvgTotalTimeMs * float64(ep.TotalRequests)
+			result[tag] = agg
+		}
+	}
+
+	for tag, agg := range result {
+		sort.Strings(agg.Endpoints)
+		if agg.TotalRequests > 0 {
+			agg.SuccessRate = float64(agg.Successful) / float64(agg.TotalRequests) * 100
+			agg.AvgTotalTimeMs = totalTimes[tag] / float64(agg.TotalRequests)
+		}
+		result[tag] = agg
+	}
+	return result
+}
+
+// containsString reports whether a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
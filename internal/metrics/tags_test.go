@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregateByTag(t *testing.T) {
+	endpoints := map[string]EndpointSnapshot{
+		"cart":     {TotalRequests: 10, Successful: 9, Failed: 1, HTTPErrors: 1, AvgTotalTimeMs: 100, MaxTotalTimeMs: 300},
+		"checkout": {TotalRequests: 30, Successful: 30, AvgTotalTimeMs: 20, MaxTotalTimeMs: 50},
+	}
+	tags := map[string][]string{
+		"cart":     {"Checkout", "web"},
+		"checkout": {"checkout", "CHECKOUT"},
+		"search":   {"web"},
+	}
+
+	result := AggregateByTag(endpoints, tags)
+
+	checkout := result["checkout"]
+	if !reflect.DeepEqual(checkout.Endpoints, []string{"cart", "checkout"}) {
+		t.Errorf("unexpected checkout endpoints: %v", checkout.Endpoints)
+	}
+	if checkout.TotalRequests != 40 || checkout.Failed != 1 || checkout.HTTPErrors != 1 {
+		t.Errorf("unexpected checkout counts: %+v", checkout)
+	}
+	if checkout.AvgTotalTimeMs != 40 || checkout.MaxTotalTimeMs != 300 || checkout.SuccessRate != 97.5 {
+		t.Errorf("unexpected checkout latency or success rate: %+v", checkout)
+	}
+
+	web := result["web"]
+	if !reflect.DeepEqual(web.Endpoints, []string{"cart", "search"}) || web.TotalRequests != 10 {
+		t.Errorf("unexpected web aggregate: %+v", web)
+	}
+}
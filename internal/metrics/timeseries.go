@@ -0,0 +1,176 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeseriesPoint is the traffic of one step of a time series
+type TimeseriesPoint struct {
+	Timestamp        string  `json:"timestamp"` // Step start
+	Requests         int64   `json:"requests"`
+	Successful       int64   `json:"successful"`
+	Failed           int64   `json:"failed"`
+	ErrorRate        float64 `json:"error_rate"`
+	TimeoutErrors    int64   `json:"timeout_errors"`
+	DNSErrors        int64   `json:"dns_errors"`
+	ConnectionErrors int64   `json:"connection_errors"`
+	HTTPErrors       int64   `json:"http_errors"`
+	OtherErrors      int64   `json:"other_errors"`
+	RequestsPerSec   float64 `json:"requests_per_sec"`
+	AvgTotalTimeMs   float64 `json:"avg_total_time_ms"`
+	AvgDNSTimeMs     float64 `json:"avg_dns_time_ms"`
+	P95TotalTimeMs   float64 `json:"p95_total_time_ms"`
+	MaxTotalTimeMs   float64 `json:"max_total_time_ms"`
+}
+
+// SeriesStep returns the smallest step that keeps a series between from and
+// to within maxPoints points. Steps are multiples of the bucket interval.
+func SeriesStep(from, to time.Time, maxPoints int) time.Duration {
+	bucket := BucketSeconds * time.Second
+	if maxPoints <= 0 {
+		return bucket
+	}
+	buckets := int64(to.Sub(from)/bucket) + 1
+	perPoint := (buckets + int64(maxPoints) - 1) / int64(maxPoints)
+	return time.Duration(max(perPoint, 1)) * bucket
+}
+
+// SeriesInterval returns the point interval of a step: the step rounded up
+// to a multiple of the bucket interval, or one bucket interval if it is 0
+func SeriesInterval(step time.Duration) time.Duration {
+	bucket := BucketSeconds * time.Second
+	buckets := (step + bucket - 1) / bucket
+	return max(buckets, 1) * bucket
+}
+
+// Timeseries returns one point per bucket interval between from and to for
+// an endpoint, or summed over all endpoints if endpoint is empty. The range
+// is clamped to the retained buckets and the time since the last reset;
+// intervals without requests are included as zero points.
+func (c *Collector) Timeseries(endpoint string, from, to time.Time) ([]TimeseriesPoint, error) {
+	return c.DownsampledTimeseries(endpoint, from, to, 0)
+}
+
+// DownsampledTimeseries is Timeseries with one point per step, merging the
+// buckets within it. The step is rounded up by SeriesInterval and points
+// start at multiples of it, so successive calls line up.
+func (c *Collector) DownsampledTimeseries(endpoint string, from, to time.Time, step time.Duration) ([]TimeseriesPoint, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	selected := c.endpoints
+	if endpoint != "" {
+		ep, exists := c.endpoints[endpoint]
+		if !exists {
+			return nil, fmt.Errorf("no metrics for endpoint: %s", endpoint)
+		}
+		selected = map[string]*EndpointMetrics{endpoint: ep}
+	}
+
+	s := newSeries(c.startTime, from, to, step)
+	for _, ep := range selected {
+		ep.mu.Lock()
+		s.add(ep.recent)
+		ep.mu.Unlock()
+	}
+	return s.points(), nil
+}
+
+// Timeseries returns one point per step between from and to for an incoming
+// route, or summed over all routes if route is empty, like the outgoing
+// DownsampledTimeseries. Responses with a status of 400 or above count as
+// failed (http errors).
+func (c *IncomingCollector) Timeseries(route string, from, to time.Time, step time.Duration) ([]TimeseriesPoint, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	selected := c.routes
+	if route != "" {
+		rm, exists := c.routes[route]
+		if !exists {
+			return nil, fmt.Errorf("no metrics for route: %s", route)
+		}
+		selected = map[string]*IncomingRouteMetrics{route: rm}
+	}
+
+	s := newSeries(c.startTime, from, to, step)
+	for _, rm := range selected {
+		rm.mu.Lock()
+		s.add(rm.recent)
+		rm.mu.Unlock()
+	}
+	return s.points(), nil
+}
+
+// series merges window buckets into the steps of a time series
+type series struct {
+	first, step int64 // Unix time of the first step and step length in seconds
+	steps       []windowBucket
+}
+
+// newSeries prepares the steps between from and to, clamped to the retained
+// buckets, the start time and now
+func newSeries(start, from, to time.Time, step time.Duration) *series {
+	stepSeconds := int64(SeriesInterval(step).Seconds())
+
+	now := time.Now()
+	if oldest := now.Add(-BucketRetention + BucketSeconds*time.Second); from.Before(oldest) {
+		from = oldest
+	}
+	if from.Before(start) {
+		from = start
+	}
+	if to.After(now) {
+		to = now
+	}
+	s := &series{step: stepSeconds}
+	s.first = from.Unix() - from.Unix()%stepSeconds
+	last := to.Unix() - to.Unix()%stepSeconds
+	if last >= s.first {
+		s.steps = make([]windowBucket, (last-s.first)/stepSeconds+1)
+	}
+	return s
+}
+
+// add merges the buckets of a ring that fall within the series. The ring's
+// lock must be held.
+func (s *series) add(ring *windowRing) {
+	if len(s.steps) == 0 {
+		return
+	}
+	last := s.first + int64(len(s.steps))*s.step - 1
+	ring.each(s.first, last, func(b *windowBucket) {
+		s.steps[(b.start-s.first)/s.step].add(b)
+	})
+}
+
+// points converts the steps to time series points
+func (s *series) points() []TimeseriesPoint {
+	points := make([]TimeseriesPoint, len(s.steps))
+	for i := range s.steps {
+		b := &s.steps[i]
+		point := TimeseriesPoint{
+			Timestamp:        time.Unix(s.first+int64(i)*s.step, 0).UTC().Format(time.RFC3339),
+			Requests:         b.requests,
+			Successful:       b.successful,
+			Failed:           b.requests - b.successful,
+			TimeoutErrors:    b.timeoutErrors,
+			DNSErrors:        b.dnsErrors,
+			ConnectionErrors: b.connectionErrors,
+			HTTPErrors:       b.httpErrors,
+			OtherErrors:      b.otherErrors,
+			RequestsPerSec:   float64(b.requests) / float64(s.step),
+			MaxTotalTimeMs:   b.maxTimeMs,
+		}
+		if b.requests > 0 {
+			point.ErrorRate = float64(point.Failed) / float64(b.requests) * 100
+			point.AvgTotalTimeMs = b.totalTimeMs / float64(b.requests)
+			point.AvgDNSTimeMs = b.totalDNSTimeMs / float64(b.requests)
+			point.P95TotalTimeMs = b.percentile(95)
+		}
+		points[i] = point
+	}
+	return points
+}
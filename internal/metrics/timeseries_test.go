@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+)
+
+func TestCollectorTimeseries(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < 5; i++ {
+		c.Record(&client.RequestResult{EndpointName: "a", Success: true, TotalTimeMs: 20})
+	}
+	c.Record(&client.RequestResult{EndpointName: "b", Success: false, ErrorType: "http", TotalTimeMs: 40})
+
+	now := time.Now()
+	points, err := c.Timeseries("", now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests, failed int64
+	for _, p := range points {
+		requests += p.Requests
+		failed += p.Failed
+	}
+	if requests != 6 || failed != 1 {
+		t.Errorf("all endpoints: got %d requests, %d failed, want 6 and 1", requests, failed)
+	}
+
+	points, err = c.Timeseries("b", now.Add(-time.Minute), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, p := range points {
+		if p.Requests == 0 {
+			continue
+		}
+		found = true
+		if p.Requests != 1 || p.HTTPErrors != 1 || p.ErrorRate != 100 || p.MaxTotalTimeMs != 40 {
+			t.Errorf("endpoint b: got %+v", p)
+		}
+	}
+	if !found {
+		t.Error("endpoint b: no point with requests")
+	}
+
+	if _, err := c.Timeseries("missing", now.Add(-time.Minute), now); err == nil {
+		t.Error("expected an error for an unknown endpoint")
+	}
+}
+
+func TestDownsampledTimeseries(t *testing.T) {
+	c := NewCollector()
+	c.Record(&client.RequestResult{EndpointName: "a", Success: true, TotalTimeMs: 20})
+
+	now := time.Now()
+	points, err := c.DownsampledTimeseries("a", now.Add(-time.Hour), now, 15*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int64
+	for i, p := range points {
+		requests += p.Requests
+		ts, err := time.Parse(time.RFC3339, p.Timestamp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts.Unix()%20 != 0 {
+			t.Errorf("point %d: %s is not aligned to the 20s step", i, p.Timestamp)
+		}
+		if p.Requests > 0 && p.RequestsPerSec != float64(p.Requests)/20 {
+			t.Errorf("point %d: got %v requests/s for %d requests", i, p.RequestsPerSec, p.Requests)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestSeriesStep(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		span   time.Duration
+		points int
+		want   time.Duration
+	}{
+		{15 * time.Minute, 0, 10 * time.Second},
+		{15 * time.Minute, 1000, 10 * time.Second},
+		{15 * time.Minute, 30, 40 * time.Second},
+		{2 * time.Hour, 120, time.Minute + 10*time.Second},
+	}
+	for _, tt := range tests {
+		if got := SeriesStep(now.Add(-tt.span), now, tt.points); got != tt.want {
+			t.Errorf("SeriesStep(%s, %d) = %s, want %s", tt.span, tt.points, got, tt.want)
+		}
+	}
+	if got := SeriesInterval(15 * time.Second); got != 20*time.Second {
+		t.Errorf("SeriesInterval(15s) = %s, want 20s", got)
+	}
+}
+
+func TestIncomingTimeseries(t *testing.T) {
+	c := NewIncomingCollector()
+	c.Record("orders", "/orders", 200, 10)
+	c.Record("orders", "/orders", 503, 30)
+
+	now := time.Now()
+	points, err := c.Timeseries("orders", now.Add(-time.Minute), now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests, httpErrors int64
+	for _, p := range points {
+		requests += p.Requests
+		httpErrors += p.HTTPErrors
+	}
+	if requests != 2 || httpErrors != 1 {
+		t.Errorf("got %d requests, %d http errors, want 2 and 1", requests, httpErrors)
+	}
+	if _, err := c.Timeseries("missing", now.Add(-time.Minute), now, 0); err == nil {
+		t.Error("expected an error for an unknown route")
+	}
+}
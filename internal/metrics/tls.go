@@ -0,0 +1,129 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"moxapp/internal/client"
+)
+
+// DefaultTLSExpiryWarningDays is how close to expiry certificates are warned
+// about without a source
+const DefaultTLSExpiryWarningDays = 30
+
+// DaysSource returns a number of days, such as how close to expiry
+// certificates are warned about. It is called for every snapshot, so changes
+// apply live.
+type DaysSource func() int
+
+// get returns the days, the default without a source or with none
+func (d DaysSource) get(def int) int {
+	if d == nil {
+		return def
+	}
+	if days := d(); days > 0 {
+		return days
+	}
+	return def
+}
+
+// hostTLS holds the TLS handshakes with one hostname
+type hostTLS struct {
+	handshakes int64
+	versions   map[string]int64 // Handshakes by TLS version
+	last       client.TLSInfo   // Most recent handshake
+	lastSeen   time.Time
+}
+
+// TLSHostSnapshot is the TLS parameters and certificate of one hostname, from
+// its most recent handshake
+type TLSHostSnapshot struct {
+	Handshakes    int64            `json:"handshakes"`
+	Versions      map[string]int64 `json:"versions"` // Handshakes by TLS version
+	Version       string           `json:"version"`
+	CipherSuite   string           `json:"cipher_suite"`
+	ALPN          string           `json:"alpn,omitempty"`
+	ServerName    string           `json:"server_name,omitempty"`
+	CertSubject   string           `json:"cert_subject,omitempty"`
+	CertIssuer    string           `json:"cert_issuer,omitempty"`
+	CertNotAfter  string           `json:"cert_not_after,omitempty"`
+	DaysRemaining float64          `json:"days_remaining"` // Until the certificate expires, negative once expired
+	LastSeen      string           `json:"last_seen"`
+}
+
+// TLSWarning is a certificate expiring soon
+type TLSWarning struct {
+	Hostname      string  `json:"hostname"`
+	CertNotAfter  string  `json:"cert_not_after"`
+	DaysRemaining float64 `json:"days_remaining"`
+	Message       string  `json:"message"`
+}
+
+// recordTLS records a TLS handshake with a hostname (caller holds lock)
+func (c *Collector) recordTLS(hostname string, info *client.TLSInfo) {
+	host, exists := c.tls[hostname]
+	if !exists {
+		host = &hostTLS{versions: make(map[string]int64)}
+		c.tls[hostname] = host
+	}
+	host.handshakes++
+	host.versions[info.Version]++
+	host.last = *info
+	host.lastSeen = time.Now()
+}
+
+// tlsSnapshot returns the TLS details per hostname and warnings for
+// certificates expiring within the warning days, soonest first (caller holds lock)
+func (c *Collector) tlsSnapshot(now time.Time) (map[string]TLSHostSnapshot, []TLSWarning) {
+	if len(c.tls) == 0 {
+		return nil, nil
+	}
+
+	warningDays := c.tlsExpiryWarningDays.get(DefaultTLSExpiryWarningDays)
+	hosts := make(map[string]TLSHostSnapshot, len(c.tls))
+	var warnings []TLSWarning
+	for hostname, host := range c.tls {
+		snap := TLSHostSnapshot{
+			Handshakes:  host.handshakes,
+			Versions:    make(map[string]int64, len(host.versions)),
+			Version:     host.last.Version,
+			CipherSuite: host.last.CipherSuite,
+			ALPN:        host.last.ALPN,
+			ServerName:  host.last.ServerName,
+			CertSubject: host.last.CertSubject,
+			CertIssuer:  host.last.CertIssuer,
+			LastSeen:    host.lastSeen.Format(time.RFC3339),
+		}
+		for version, count := range host.versions {
+			snap.Versions[version] = count
+		}
+		if !host.last.CertNotAfter.IsZero() {
+			snap.CertNotAfter = host.last.CertNotAfter.Format(time.RFC3339)
+			snap.DaysRemaining = host.last.CertNotAfter.Sub(now).Hours() / 24
+			if snap.DaysRemaining < float64(warningDays) {
+				warnings = append(warnings, TLSWarning{
+					Hostname:      hostname,
+					CertNotAfter:  snap.CertNotAfter,
+					DaysRemaining: snap.DaysRemaining,
+					Message:       expiryMessage(hostname, snap.DaysRemaining),
+				})
+			}
+		}
+		hosts[hostname] = snap
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].DaysRemaining < warnings[j].DaysRemaining
+	})
+	return hosts, warnings
+}
+
+// expiryMessage describes how soon a certificate expires
+func expiryMessage(hostname string, daysRemaining float64) string {
+	if daysRemaining < 0 {
+		return fmt.Sprintf("certificate of %s expired %.0f days ago", hostname, -daysRemaining)
+	}
+	return fmt.Sprintf("certificate of %s expires in %.0f days", hostname, daysRemaining)
+}
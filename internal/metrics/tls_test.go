@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+)
+
+func TestCollectorTLS(t *testing.T) {
+	c := NewCollector()
+	c.SetTLSExpiryWarningSource(func() int { return 14 })
+
+	record := func(hostname string, info client.TLSInfo) {
+		c.Record(&client.RequestResult{EndpointName: hostname, Hostname: hostname, Success: true, StatusCode: 200, TLS: &info})
+	}
+	record("api.example.com", client.TLSInfo{Version: "TLS 1.2", CipherSuite: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", CertNotAfter: time.Now().Add(90 * 24 * time.Hour)})
+	record("api.example.com", client.TLSInfo{Version: "TLS 1.3", CipherSuite: "TLS_AES_128_GCM_SHA256", CertNotAfter: time.Now().Add(90 * 24 * time.Hour)})
+	record("legacy.example.com", client.TLSInfo{Version: "TLS 1.2", CertNotAfter: time.Now().Add(10*24*time.Hour + time.Hour)})
+	record("old.example.com", client.TLSInfo{Version: "TLS 1.2", CertNotAfter: time.Now().Add(-2*24*time.Hour - time.Hour)})
+	record("10.0.0.1", client.TLSInfo{ServerName: "sni.example.com", Version: "TLS 1.3", CertNotAfter: time.Now().Add(365 * 24 * time.Hour)})
+
+	snapshot := c.Snapshot()
+	api := snapshot.TLSByHost["api.example.com"]
+	if api.Handshakes != 2 || api.Versions["TLS 1.2"] != 1 || api.Version != "TLS 1.3" || api.CipherSuite != "TLS_AES_128_GCM_SHA256" {
+		t.Errorf("unexpected TLS stats: %+v", api)
+	}
+	if _, exists := snapshot.TLSByHost["sni.example.com"]; !exists {
+		t.Errorf("expected handshakes keyed by server name, got %v", snapshot.TLSByHost)
+	}
+
+	warnings := snapshot.TLSWarnings
+	if len(warnings) != 2 || warnings[0].Hostname != "old.example.com" || warnings[1].Hostname != "legacy.example.com" {
+		t.Fatalf("expected warnings for the expired and the expiring certificate, soonest first, got %+v", warnings)
+	}
+	if warnings[0].Message != "certificate of old.example.com expired 2 days ago" || warnings[1].Message != "certificate of legacy.example.com expires in 10 days" {
+		t.Errorf("unexpected warning messages: %q, %q", warnings[0].Message, warnings[1].Message)
+	}
+
+	c.Reset()
+	if snapshot := c.Snapshot(); snapshot.TLSByHost != nil || snapshot.TLSWarnings != nil {
+		t.Errorf("expected no TLS stats after reset, got %+v", snapshot.TLSByHost)
+	}
+}
@@ -0,0 +1,213 @@
+// Package metrics provides in-memory metrics collection
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Recent requests are kept in fixed buckets, used for sliding windows and
+// time series. The newest bucket is still filling, so a window covers between
+// its length and one bucket more.
+const (
+	BucketSeconds   = 10
+	BucketRetention = 2 * time.Hour
+	bucketCount     = int(BucketRetention / (BucketSeconds * time.Second))
+)
+
+// Windows are the supported ?window= values
+var Windows = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+}
+
+// ParseWindow returns the duration of a window name ("" means since start, 0)
+func ParseWindow(name string) (time.Duration, error) {
+	if name == "" {
+		return 0, nil
+	}
+	window, ok := Windows[name]
+	if !ok {
+		return 0, fmt.Errorf("invalid window %q (must be one of: 1m, 5m, 15m)", name)
+	}
+	return window, nil
+}
+
+// latencyBounds are the upper bounds (ms) of the latency histogram bins used
+// for bucketed percentiles; the last bin is unbounded
+var latencyBounds = []float64{
+	1, 2, 3, 5, 7, 10, 15, 20, 30, 50, 75, 100, 150, 200, 300, 500, 750,
+	1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 20000, 30000, 60000,
+}
+
+// windowBucket aggregates the requests of one bucket interval
+type windowBucket struct {
+	start int64 // Unix time of the bucket start; 0 if unused
+
+	requests         int64
+	successful       int64
+	timeoutErrors    int64
+	dnsErrors        int64
+	connectionErrors int64
+	httpErrors       int64
+	otherErrors      int64
+	totalTimeMs      float64
+	totalDNSTimeMs   float64
+	totalConnectMs   float64
+	maxTimeMs        float64
+	apdexSatisfied   int64
+	apdexTolerating  int64
+	apdexFrustrated  int64
+	sloBreaches      int64
+	latencies        []int32 // Counts per latencyBounds bin, allocated on first use
+}
+
+// add merges the counts of another bucket
+func (b *windowBucket) add(other *windowBucket) {
+	b.requests += other.requests
+	b.successful += other.successful
+	b.timeoutErrors += other.timeoutErrors
+	b.dnsErrors += other.dnsErrors
+	b.connectionErrors += other.connectionErrors
+	b.httpErrors += other.httpErrors
+	b.otherErrors += other.otherErrors
+	b.totalTimeMs += other.totalTimeMs
+	b.totalDNSTimeMs += other.totalDNSTimeMs
+	b.totalConnectMs += other.totalConnectMs
+	b.apdexSatisfied += other.apdexSatisfied
+	b.apdexTolerating += other.apdexTolerating
+	b.apdexFrustrated += other.apdexFrustrated
+	b.sloBreaches += other.sloBreaches
+	if other.maxTimeMs > b.maxTimeMs {
+		b.maxTimeMs = other.maxTimeMs
+	}
+	if other.latencies == nil {
+		return
+	}
+	if b.latencies == nil {
+		b.latencies = make([]int32, len(latencyBounds)+1)
+	}
+	for bin, count := range other.latencies {
+		b.latencies[bin] += count
+	}
+}
+
+// percentile estimates the p-th response time percentile from the histogram:
+// the upper bound of the bin it falls in, capped at the maximum
+func (b *windowBucket) percentile(p float64) float64 {
+	rank := int64(float64(b.requests) * p / 100)
+	var seen int64
+	for bin, count := range b.latencies {
+		seen += int64(count)
+		if seen > rank {
+			if bin < len(latencyBounds) && latencyBounds[bin] < b.maxTimeMs {
+				return latencyBounds[bin]
+			}
+			break
+		}
+	}
+	return b.maxTimeMs
+}
+
+// windowRing keeps the buckets of the last BucketRetention
+type windowRing struct {
+	buckets []windowBucket // Allocated on first use
+}
+
+// bucket returns the bucket for a time, clearing it if it held an older interval
+func (w *windowRing) bucket(now time.Time) *windowBucket {
+	if w.buckets == nil {
+		w.buckets = make([]windowBucket, bucketCount)
+	}
+	start := now.Unix() - now.Unix()%BucketSeconds
+	b := &w.buckets[(start/BucketSeconds)%int64(bucketCount)]
+	if b.start != start {
+		latencies := b.latencies
+		*b = windowBucket{start: start}
+		if latencies != nil {
+			clear(latencies)
+			b.latencies = latencies
+		}
+	}
+	return b
+}
+
+// record adds a request to the current bucket
+func (w *windowRing) record(now time.Time, success bool, errorType string, totalTimeMs, dnsTimeMs, connectTimeMs float64) {
+	b := w.bucket(now)
+	b.requests++
+	if success {
+		b.successful++
+	} else {
+		switch errorType {
+		case "timeout":
+			b.timeoutErrors++
+		case "dns":
+			b.dnsErrors++
+		case "connection":
+			b.connectionErrors++
+		case "http":
+			b.httpErrors++
+		default:
+			b.otherErrors++
+		}
+	}
+	b.totalTimeMs += totalTimeMs
+	b.totalDNSTimeMs += dnsTimeMs
+	b.totalConnectMs += connectTimeMs
+	if totalTimeMs > b.maxTimeMs {
+		b.maxTimeMs = totalTimeMs
+	}
+
+	if b.latencies == nil {
+		b.latencies = make([]int32, len(latencyBounds)+1)
+	}
+	b.latencies[sort.SearchFloat64s(latencyBounds, totalTimeMs)]++
+}
+
+// each calls fn for every used bucket starting within [from, to] (Unix times)
+func (w *windowRing) each(from, to int64, fn func(b *windowBucket)) {
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if b.start != 0 && b.start >= from && b.start <= to {
+			fn(b)
+		}
+	}
+}
+
+// snapshot sums the buckets within window of now into an endpoint snapshot.
+// Percentiles are estimated from the histogram, so they are coarser than the
+// since-start ones.
+func (w *windowRing) snapshot(now time.Time, window time.Duration, percentiles ...float64) EndpointSnapshot {
+	var sum windowBucket
+	w.each(now.Unix()-int64(window.Seconds())-BucketSeconds+1, now.Unix(), sum.add)
+
+	snap := EndpointSnapshot{
+		TotalRequests:    sum.requests,
+		Successful:       sum.successful,
+		Failed:           sum.requests - sum.successful,
+		TimeoutErrors:    sum.timeoutErrors,
+		DNSErrors:        sum.dnsErrors,
+		ConnectionErrors: sum.connectionErrors,
+		HTTPErrors:       sum.httpErrors,
+		OtherErrors:      sum.otherErrors,
+		MaxTotalTimeMs:   sum.maxTimeMs,
+		Apdex:            apdexScore(sum.apdexSatisfied, sum.apdexTolerating, sum.apdexFrustrated),
+		ApdexSatisfied:   sum.apdexSatisfied,
+		ApdexTolerating:  sum.apdexTolerating,
+		ApdexFrustrated:  sum.apdexFrustrated,
+		SLOBreaches:      sum.sloBreaches,
+	}
+	if sum.requests > 0 {
+		snap.SuccessRate = float64(sum.successful) / float64(sum.requests) * 100
+		snap.AvgTotalTimeMs = sum.totalTimeMs / float64(sum.requests)
+		snap.AvgDNSTimeMs = sum.totalDNSTimeMs / float64(sum.requests)
+		snap.AvgConnectTimeMs = sum.totalConnectMs / float64(sum.requests)
+		snap.P95TotalTimeMs = sum.percentile(95)
+		snap.P99TotalTimeMs = sum.percentile(99)
+		snap.Percentiles = percentileValues(percentiles, sum.percentile)
+	}
+	return snap
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowRingSnapshot(t *testing.T) {
+	var ring windowRing
+	now := time.Unix(1_700_000_000, 0)
+
+	// An old failure burst outside the 1m window
+	for i := 0; i < 10; i++ {
+		ring.record(now.Add(-5*time.Minute), false, "timeout", 5000, 0, 0)
+	}
+	for i := 0; i < 100; i++ {
+		ring.record(now.Add(-time.Duration(i%30)*time.Second), true, "", float64(10+i), 1, 2)
+	}
+
+	recent := ring.snapshot(now, time.Minute)
+	if recent.TotalRequests != 100 || recent.Failed != 0 || recent.SuccessRate != 100 {
+		t.Errorf("1m window: got %d requests, %d failed, %.1f%% success", recent.TotalRequests, recent.Failed, recent.SuccessRate)
+	}
+	if recent.MaxTotalTimeMs != 109 || recent.P99TotalTimeMs > 109 || recent.P95TotalTimeMs < 100 {
+		t.Errorf("1m window: got p95 %.0f, p99 %.0f, max %.0f", recent.P95TotalTimeMs, recent.P99TotalTimeMs, recent.MaxTotalTimeMs)
+	}
+
+	wide := ring.snapshot(now, 15*time.Minute)
+	if wide.TotalRequests != 110 || wide.TimeoutErrors != 10 {
+		t.Errorf("15m window: got %d requests, %d timeouts", wide.TotalRequests, wide.TimeoutErrors)
+	}
+
+	// Buckets are reused once the ring wraps around
+	ring.record(now.Add(BucketRetention), true, "", 1, 0, 0)
+	later := ring.snapshot(now.Add(BucketRetention), 15*time.Minute)
+	if later.TotalRequests != 1 {
+		t.Errorf("after wrap: got %d requests, want 1", later.TotalRequests)
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	if window, err := ParseWindow("5m"); err != nil || window != 5*time.Minute {
+		t.Errorf("ParseWindow(5m) = %v, %v", window, err)
+	}
+	if window, err := ParseWindow(""); err != nil || window != 0 {
+		t.Errorf("ParseWindow(\"\") = %v, %v", window, err)
+	}
+	if _, err := ParseWindow("2m"); err == nil {
+		t.Error("ParseWindow(2m) should fail")
+	}
+}
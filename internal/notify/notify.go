@@ -0,0 +1,191 @@
+// Package notify posts run lifecycle notifications (run started and
+// completed, alerts, emergency stop) to Slack and Microsoft Teams webhooks
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+	"moxapp/internal/runs"
+	"moxapp/internal/webhook"
+)
+
+// Fact is a name/value line of a notification
+type Fact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Event is a notification about the load test
+type Event struct {
+	Type  string // One of config.NotificationEvents
+	Title string
+	Text  string // Optional description
+	Facts []Fact
+}
+
+// Notifier posts events to the configured webhooks in the background
+type Notifier struct {
+	configManager *config.Manager
+	httpClient    *http.Client
+	wg            sync.WaitGroup
+}
+
+// New creates a new notifier
+func New(configManager *config.Manager) *Notifier {
+	return &Notifier{
+		configManager: configManager,
+		httpClient:    webhook.NewClient(),
+	}
+}
+
+// Notify posts an event to the configured webhooks without blocking, if the
+// event is enabled
+func (n *Notifier) Notify(event Event) {
+	cfg := n.configManager.GetNotificationsConfig()
+	if !cfg.Wants(event.Type) {
+		return
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if cfg.SlackWebhookURL != "" {
+			if err := webhook.Post(context.Background(), n.httpClient, cfg.SlackWebhookURL, slackMessage(event)); err != nil {
+				log.Printf("notify: %s to Slack failed: %v", event.Type, err)
+			}
+		}
+		if cfg.TeamsWebhookURL != "" {
+			if err := webhook.Post(context.Background(), n.httpClient, cfg.TeamsWebhookURL, teamsMessage(event)); err != nil {
+				log.Printf("notify: %s to Teams failed: %v", event.Type, err)
+			}
+		}
+	}()
+}
+
+// Wait waits for pending notifications until ctx is done, so the last ones
+// are sent before the process exits
+func (n *Notifier) Wait(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// RunStarted notifies that a run started
+func (n *Notifier) RunStarted(run *runs.Run) {
+	facts := []Fact{{"Run", run.ID}}
+	if run.Config != nil {
+		facts = append(facts,
+			Fact{"Endpoints", fmt.Sprintf("%d", len(run.Config.Endpoints))},
+			Fact{"Global multiplier", fmt.Sprintf("%.2f", run.Config.GlobalMultiplier)},
+		)
+	}
+	n.Notify(Event{Type: config.NotifyRunStarted, Title: "Run started: " + run.Label, Facts: facts})
+}
+
+// RunCompleted notifies that a run completed, with its final metrics
+func (n *Notifier) RunCompleted(run *runs.Run) {
+	facts := []Fact{
+		{"Run", run.ID},
+		{"Duration", (time.Duration(run.DurationSeconds) * time.Second).String()},
+	}
+	n.Notify(Event{
+		Type:  config.NotifyRunCompleted,
+		Title: "Run completed: " + run.Label,
+		Facts: append(facts, Summary(run.Metrics)...),
+	})
+}
+
+// EmergencyStop notifies that all requests were stopped, with the metrics so far
+func (n *Notifier) EmergencyStop(snapshot *metrics.MetricsSnapshot) {
+	n.Notify(Event{
+		Type:  config.NotifyEmergencyStop,
+		Title: "Emergency stop",
+		Text:  "All scheduling stopped and in-flight requests were cancelled.",
+		Facts: Summary(snapshot),
+	})
+}
+
+// Alert notifies that an alert rule started firing or resolved
+func (n *Notifier) Alert(firing bool, message string, snapshot *metrics.MetricsSnapshot) {
+	event := Event{Type: config.NotifyAlertResolved, Title: "Alert resolved", Text: message}
+	if firing {
+		event = Event{Type: config.NotifyAlertFiring, Title: "Alert firing", Text: message}
+	}
+	event.Facts = Summary(snapshot)
+	n.Notify(event)
+}
+
+// Summary returns the key metrics of a snapshot as facts
+func Summary(snapshot *metrics.MetricsSnapshot) []Fact {
+	if snapshot == nil {
+		return nil
+	}
+
+	var dnsErrors, timeouts, connErrors, httpErrors int64
+	var p95 float64
+	for _, ep := range snapshot.Endpoints {
+		dnsErrors += ep.DNSErrors
+		timeouts += ep.TimeoutErrors
+		connErrors += ep.ConnectionErrors
+		httpErrors += ep.HTTPErrors
+		p95 = max(p95, ep.P95TotalTimeMs)
+	}
+
+	return []Fact{
+		{"Requests", fmt.Sprintf("%d (%.1f/s)", snapshot.TotalRequests, snapshot.RequestsPerSecond)},
+		{"Success rate", fmt.Sprintf("%.2f%%", snapshot.SuccessRate)},
+		{"Highest p95", fmt.Sprintf("%.0fms", p95)},
+		{"Errors", fmt.Sprintf("%d DNS, %d timeout, %d connection, %d HTTP", dnsErrors, timeouts, connErrors, httpErrors)},
+	}
+}
+
+// slackMessage formats an event for a Slack incoming webhook
+func slackMessage(event Event) map[string]interface{} {
+	var text bytes.Buffer
+	fmt.Fprintf(&text, "*%s*", event.Title)
+	if event.Text != "" {
+		fmt.Fprintf(&text, "\n%s", event.Text)
+	}
+	for _, fact := range event.Facts {
+		fmt.Fprintf(&text, "\n• %s: %s", fact.Name, fact.Value)
+	}
+	return map[string]interface{}{"text": text.String()}
+}
+
+// teamsMessage formats an event as a message card for a Teams incoming webhook
+func teamsMessage(event Event) map[string]interface{} {
+	color := "0076D7"
+	switch event.Type {
+	case config.NotifyAlertFiring, config.NotifyEmergencyStop:
+		color = "D70000"
+	case config.NotifyAlertResolved:
+		color = "2EB886"
+	}
+
+	section := map[string]interface{}{"facts": event.Facts}
+	if event.Text != "" {
+		section["text"] = event.Text
+	}
+	return map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": color,
+		"summary":    event.Title,
+		"title":      event.Title,
+		"sections":   []interface{}{section},
+	}
+}
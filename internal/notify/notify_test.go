@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+	"moxapp/internal/runs"
+)
+
+func TestNotifier_SlackAndTeams(t *testing.T) {
+	var mu sync.Mutex
+	bodies := make(map[string][]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cm := config.NewManager()
+	if err := cm.ReplaceConfig(&config.Config{Notifications: config.NotificationsConfig{
+		SlackWebhookURL: server.URL + "/slack",
+		TeamsWebhookURL: server.URL + "/teams",
+		Events:          []string{config.NotifyRunCompleted, config.NotifyEmergencyStop},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	n := New(cm)
+	n.RunStarted(&runs.Run{ID: "1", Label: "smoke"}) // Not in events
+	n.RunCompleted(&runs.Run{ID: "1", Label: "smoke", DurationSeconds: 90, Metrics: &metrics.MetricsSnapshot{
+		TotalRequests: 100, SuccessRate: 99,
+		Endpoints: map[string]metrics.EndpointSnapshot{"a": {DNSErrors: 1, P95TotalTimeMs: 250}},
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	n.Wait(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies["/slack"]) != 1 || len(bodies["/teams"]) != 1 {
+		t.Fatalf("expected one message per webhook, got %v", bodies)
+	}
+	text, _ := bodies["/slack"][0]["text"].(string)
+	if !strings.HasPrefix(text, "*Run completed: smoke*") || !strings.Contains(text, "Duration: 1m30s") || !strings.Contains(text, "1 DNS") {
+		t.Errorf("unexpected Slack text: %q", text)
+	}
+	card := bodies["/teams"][0]
+	if card["@type"] != "MessageCard" || card["title"] != "Run completed: smoke" {
+		t.Errorf("unexpected Teams card: %v", card)
+	}
+}
@@ -0,0 +1,85 @@
+// Package plugins loads custom template functions from Go plugins
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"text/template"
+
+	"moxapp/internal/config"
+)
+
+// TemplateFuncsSymbol is the exported variable a template plugin must define:
+//
+//	var TemplateFuncs = map[string]interface{}{
+//		"hmacSign": func(key, msg string) string { ... },
+//	}
+//
+// Build the plugin with: go build -buildmode=plugin -o hmac.so ./hmac
+const TemplateFuncsSymbol = "TemplateFuncs"
+
+// LoadTemplatePlugins opens every .so file in dir and registers the template
+// functions it exports. Returns the names of the registered functions.
+func LoadTemplatePlugins(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("template plugins directory: %w", err)
+	}
+	sort.Strings(paths)
+
+	var registered []string
+	for _, path := range paths {
+		names, err := loadTemplatePlugin(path)
+		if err != nil {
+			return registered, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+		}
+		registered = append(registered, names...)
+	}
+	return registered, nil
+}
+
+// loadTemplatePlugin registers the functions of a single plugin file
+func loadTemplatePlugin(path string) ([]string, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(TemplateFuncsSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var funcs map[string]interface{}
+	switch v := symbol.(type) {
+	case *map[string]interface{}:
+		funcs = *v
+	case *template.FuncMap:
+		funcs = *v
+	default:
+		return nil, fmt.Errorf("%s must be a map[string]interface{}, got %T", TemplateFuncsSymbol, symbol)
+	}
+
+	return RegisterAll(funcs)
+}
+
+// RegisterAll registers a set of template functions in name order
+func RegisterAll(funcs map[string]interface{}) ([]string, error) {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		if err := config.RegisterTemplateFunc(name, funcs[name]); err != nil {
+			return names[:i], err
+		}
+	}
+	return names, nil
+}
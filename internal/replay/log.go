@@ -0,0 +1,201 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Log formats understood by Parse
+const (
+	FormatAuto     = "auto"     // Detected per line
+	FormatCombined = "combined" // Apache/nginx combined or common log format
+	FormatJSON     = "json"     // One JSON object per line, as written by the access log
+	FormatSimple   = "simple"   // "<timestamp> <METHOD> <path>"
+)
+
+// Formats lists the accepted log formats
+var Formats = []string{FormatAuto, FormatCombined, FormatJSON, FormatSimple}
+
+// Entry is one recorded request
+type Entry struct {
+	Time   time.Time
+	Method string
+	Path   string // Path with query, as recorded
+}
+
+// Log holds the parsed entries sorted by time, and the lines that were skipped
+type Log struct {
+	Entries    []Entry
+	Skipped    int    // Lines that couldn't be parsed
+	FirstError string // Why the first skipped line couldn't be parsed
+}
+
+// Duration returns the time between the first and last entry
+func (l *Log) Duration() time.Duration {
+	if len(l.Entries) < 2 {
+		return 0
+	}
+	return l.Entries[len(l.Entries)-1].Time.Sub(l.Entries[0].Time)
+}
+
+// combinedLine matches the start of a combined or common log format line:
+// remote ident user [time] "METHOD URI PROTO"
+var combinedLine = regexp.MustCompile(`^\S+ \S+ \S+ \[([^\]]+)\] "(\S+) (\S+)(?: [^"]*)?"`)
+
+// combinedTime is the timestamp layout of the combined log format
+const combinedTime = "02/Jan/2006:15:04:05 -0700"
+
+// jsonLine is the part of a JSON access log line used for replay
+type jsonLine struct {
+	Time      string `json:"time"`
+	Timestamp string `json:"timestamp"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Query     string `json:"query"`
+	URI       string `json:"uri"`
+}
+
+// Parse reads an access log in the given format (FormatAuto if empty). Blank
+// lines and lines starting with # are ignored, other unparseable lines are
+// counted as skipped. Entries are sorted by time, keeping the log order for
+// equal timestamps.
+func Parse(r io.Reader, format string) (*Log, error) {
+	if format == "" {
+		format = FormatAuto
+	}
+	var parse func(string) (Entry, error)
+	switch format {
+	case FormatAuto:
+		parse = parseAuto
+	case FormatCombined:
+		parse = parseCombined
+	case FormatJSON:
+		parse = parseJSON
+	case FormatSimple:
+		parse = parseSimple
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected one of %s)", format, strings.Join(Formats, ", "))
+	}
+
+	log := &Log{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parse(line)
+		if err != nil {
+			if log.Skipped == 0 {
+				log.FirstError = fmt.Sprintf("line %d: %v", lineNo, err)
+			}
+			log.Skipped++
+			continue
+		}
+		log.Entries = append(log.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+
+	sort.SliceStable(log.Entries, func(i, j int) bool {
+		return log.Entries[i].Time.Before(log.Entries[j].Time)
+	})
+	return log, nil
+}
+
+// parseAuto detects the format of a line
+func parseAuto(line string) (Entry, error) {
+	if strings.HasPrefix(line, "{") {
+		return parseJSON(line)
+	}
+	if combinedLine.MatchString(line) {
+		return parseCombined(line)
+	}
+	return parseSimple(line)
+}
+
+func parseCombined(line string) (Entry, error) {
+	m := combinedLine.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, fmt.Errorf("not a combined log line")
+	}
+	ts, err := time.Parse(combinedTime, m[1])
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid timestamp %q", m[1])
+	}
+	return newEntry(ts, m[2], m[3])
+}
+
+func parseJSON(line string) (Entry, error) {
+	var jl jsonLine
+	if err := json.Unmarshal([]byte(line), &jl); err != nil {
+		return Entry{}, fmt.Errorf("invalid JSON: %v", err)
+	}
+	raw := jl.Time
+	if raw == "" {
+		raw = jl.Timestamp
+	}
+	ts, err := parseTimestamp(raw)
+	if err != nil {
+		return Entry{}, err
+	}
+	path := jl.URI
+	if path == "" {
+		path = jl.Path
+		if jl.Query != "" {
+			path += "?" + jl.Query
+		}
+	}
+	return newEntry(ts, jl.Method, path)
+}
+
+func parseSimple(line string) (Entry, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return Entry{}, fmt.Errorf("expected <timestamp> <method> <path>")
+	}
+	ts, err := parseTimestamp(fields[0])
+	if err != nil {
+		return Entry{}, err
+	}
+	return newEntry(ts, fields[1], fields[2])
+}
+
+// parseTimestamp accepts RFC 3339 timestamps and Unix seconds with an optional
+// fraction
+func parseTimestamp(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, fmt.Errorf("missing timestamp")
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return ts, nil
+	}
+	if secs, err := strconv.ParseFloat(raw, 64); err == nil && secs > 0 {
+		whole := int64(secs)
+		return time.Unix(whole, int64((secs-float64(whole))*1e9)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", raw)
+}
+
+// newEntry validates the method and path of a recorded request
+func newEntry(ts time.Time, method, path string) (Entry, error) {
+	method = strings.ToUpper(method)
+	if method == "" || strings.IndexFunc(method, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return Entry{}, fmt.Errorf("invalid method %q", method)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return Entry{}, fmt.Errorf("path %q must start with /", path)
+	}
+	return Entry{Time: ts, Method: method, Path: path}, nil
+}
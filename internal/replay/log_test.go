@@ -0,0 +1,97 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAuto(t *testing.T) {
+	input := `# exported from production
+10.0.0.1 - - [02/Jan/2026:10:00:02 +0000] "POST /api/orders HTTP/1.1" 201 512 "-" "curl/8.0"
+{"time":"2026-01-02T10:00:00Z","remote":"10.0.0.2","method":"GET","path":"/api/users/42","query":"expand=1","status":200}
+2026-01-02T10:00:01.5Z get /health
+
+not a log line
+10.0.0.1 - - [02/Jan/2026:10:00:02 +0000] "GET /api/orders HTTP/1.1" 200 128
+`
+	log, err := Parse(strings.NewReader(input), FormatAuto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(log.Entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d: %+v", len(log.Entries), log.Entries)
+	}
+	expected := []struct {
+		method, path string
+		offset       time.Duration
+	}{
+		{"GET", "/api/users/42?expand=1", 0},
+		{"GET", "/health", 1500 * time.Millisecond},
+		{"POST", "/api/orders", 2 * time.Second},
+		{"GET", "/api/orders", 2 * time.Second}, // Equal timestamps keep the log order
+	}
+	first := log.Entries[0].Time
+	for i, want := range expected {
+		got := log.Entries[i]
+		if got.Method != want.method || got.Path != want.path || got.Time.Sub(first) != want.offset {
+			t.Errorf("entry %d: expected %s %s at +%v, got %s %s at +%v",
+				i, want.method, want.path, want.offset, got.Method, got.Path, got.Time.Sub(first))
+		}
+	}
+
+	if log.Skipped != 1 || !strings.HasPrefix(log.FirstError, "line 6:") {
+		t.Errorf("expected line 6 skipped, got %d skipped: %s", log.Skipped, log.FirstError)
+	}
+	if log.Duration() != 2*time.Second {
+		t.Errorf("expected duration 2s, got %v", log.Duration())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	input := `1767348000 GET /a
+1767348000.25 DELETE /b?force=true
+10.0.0.1 - - [02/Jan/2026:10:00:02 +0000] "GET /c HTTP/1.1" 200 128
+`
+	log, err := Parse(strings.NewReader(input), FormatSimple)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log.Entries) != 2 || log.Skipped != 1 {
+		t.Fatalf("expected 2 entries and 1 skipped, got %+v", log)
+	}
+	if offset := log.Entries[1].Time.Sub(log.Entries[0].Time); offset != 250*time.Millisecond {
+		t.Errorf("expected Unix timestamps 250ms apart, got %v", offset)
+	}
+
+	if _, err := Parse(strings.NewReader(input), "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestParseInvalidEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"relative path", `2026-01-02T10:00:00Z GET api/users`},
+		{"invalid method", `2026-01-02T10:00:00Z G3T /users`},
+		{"invalid timestamp", `yesterday GET /users`},
+		{"missing path", `2026-01-02T10:00:00Z GET`},
+		{"json without time", `{"method":"GET","path":"/users"}`},
+		{"combined with bad time", `10.0.0.1 - - [2026-01-02 10:00:00] "GET /users HTTP/1.1" 200 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, err := Parse(strings.NewReader(tt.line), FormatAuto)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(log.Entries) != 0 || log.Skipped != 1 {
+				t.Errorf("expected line skipped, got %+v", log)
+			}
+		})
+	}
+}
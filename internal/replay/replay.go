@@ -0,0 +1,273 @@
+// Package replay sends the requests of an access log to a target, keeping
+// their relative timing, so production traffic shapes can be replayed
+// against another environment
+package replay
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+// Defaults of the player options
+const (
+	DefaultMaxInFlight  = 100
+	DefaultMaxEndpoints = 200
+)
+
+// LateThreshold is how long after it was due a request counts as late
+const LateThreshold = 100 * time.Millisecond
+
+// Executor sends the request of an endpoint, such as *client.Client
+type Executor interface {
+	Execute(ctx context.Context, endpoint *config.Endpoint) *client.RequestResult
+}
+
+// Options configures a replay
+type Options struct {
+	// Target is the base URL requests are sent to. Recorded paths are
+	// appended to its path.
+	Target string
+	// Speed multiplies the recorded pace: 2 replays twice as fast (default 1)
+	Speed float64
+	// MaxInFlight caps concurrent requests. Requests due while at the cap
+	// wait and are sent late. (default DefaultMaxInFlight)
+	MaxInFlight int
+	// MaxEndpoints caps the distinct endpoint names metrics are recorded
+	// under. Further paths are grouped per method. (default DefaultMaxEndpoints)
+	MaxEndpoints int
+	// Headers are sent with every request
+	Headers map[string]string
+}
+
+// Stats summarizes a replay
+type Stats struct {
+	Sent     int64
+	Late     int64 // Sent more than LateThreshold after they were due
+	AvgLagMs float64
+	MaxLagMs float64
+	Duration time.Duration
+	Stopped  bool // Cancelled before all entries were sent
+}
+
+// Player replays log entries through an executor
+type Player struct {
+	exec   Executor
+	record func(*client.RequestResult)
+	opts   Options
+	target *url.URL
+
+	names map[string]string // Endpoint name per method and normalized path
+	mu    sync.Mutex
+}
+
+// New creates a player sending requests to opts.Target. record is called with
+// every result, such as metrics.Collector.Record.
+func New(exec Executor, record func(*client.RequestResult), opts Options) (*Player, error) {
+	target, err := url.Parse(strings.TrimRight(opts.Target, "/"))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("target %q must be an http or https base URL", opts.Target)
+	}
+	if opts.Speed < 0 {
+		return nil, fmt.Errorf("speed must be positive, got %v", opts.Speed)
+	}
+	if opts.Speed == 0 {
+		opts.Speed = 1
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = DefaultMaxInFlight
+	}
+	if opts.MaxEndpoints <= 0 {
+		opts.MaxEndpoints = DefaultMaxEndpoints
+	}
+	return &Player{
+		exec:   exec,
+		record: record,
+		opts:   opts,
+		target: target,
+		names:  make(map[string]string),
+	}, nil
+}
+
+// Run sends the entries, which must be sorted by time, each at its offset from
+// the first entry divided by the speed. It returns once all sent requests have
+// completed, or early when ctx is cancelled.
+func (p *Player) Run(ctx context.Context, entries []Entry) Stats {
+	var stats Stats
+	if len(entries) == 0 {
+		return stats
+	}
+
+	slots := make(chan struct{}, p.opts.MaxInFlight)
+	var wg sync.WaitGroup
+	var totalLag time.Duration
+	first := entries[0].Time
+	start := time.Now()
+
+	for _, entry := range entries {
+		due := start.Add(time.Duration(float64(entry.Time.Sub(first)) / p.opts.Speed))
+		if !sleepUntil(ctx, due) {
+			stats.Stopped = true
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			stats.Stopped = true
+		}
+		if stats.Stopped {
+			break
+		}
+
+		lag := time.Since(due)
+		totalLag += lag
+		if lagMs := float64(lag.Microseconds()) / 1000.0; lagMs > stats.MaxLagMs {
+			stats.MaxLagMs = lagMs
+		}
+		if lag > LateThreshold {
+			stats.Late++
+		}
+		stats.Sent++
+
+		endpoint := p.endpoint(entry)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result := p.exec.Execute(ctx, endpoint)
+			if p.record != nil {
+				p.record(result)
+			}
+		}()
+	}
+
+	wg.Wait()
+	stats.Duration = time.Since(start)
+	if stats.Sent > 0 {
+		stats.AvgLagMs = float64(totalLag.Microseconds()) / 1000.0 / float64(stats.Sent)
+	}
+	return stats
+}
+
+// sleepUntil waits until t, returning false if ctx is cancelled first
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// endpoint builds the endpoint sending one entry to the target
+func (p *Player) endpoint(entry Entry) *config.Endpoint {
+	path, query, _ := strings.Cut(entry.Path, "?")
+	target := p.target.String() + path
+	if query != "" {
+		target += "?" + query
+	}
+	if strings.Contains(target, "{{") {
+		// Recorded paths are sent as they are, not evaluated as a template
+		target = "{{" + strconv.Quote(target) + "}}"
+	}
+
+	return &config.Endpoint{
+		Name:        p.endpointName(entry.Method, path),
+		Method:      entry.Method,
+		URLTemplate: target,
+		Headers:     p.opts.Headers,
+		Tags:        []string{"replay"},
+		Enabled:     true,
+	}
+}
+
+// endpointName names the endpoint of a method and path, such as
+// get_users_id for GET /users/42. Once MaxEndpoints names are taken, new
+// paths share a name per method.
+func (p *Player) endpointName(method, path string) string {
+	key := method + " " + normalizePath(path)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if name, exists := p.names[key]; exists {
+		return name
+	}
+	if len(p.names) >= p.opts.MaxEndpoints {
+		return strings.ToLower(method) + "_other"
+	}
+	name := strings.ToLower(method)
+	for _, segment := range strings.Split(strings.Trim(normalizePath(path), "/"), "/") {
+		if segment = nameSegment(segment); segment != "" {
+			name += "_" + segment
+		}
+	}
+	if name == strings.ToLower(method) {
+		name += "_root"
+	}
+	p.names[key] = name
+	return name
+}
+
+// normalizePath replaces path segments that look like identifiers, such as
+// numbers, UUIDs and long hex strings, with "id"
+func normalizePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isIdentifier(segment) {
+			segments[i] = "id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isIdentifier(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+		return true
+	}
+	hex := strings.ReplaceAll(segment, "-", "")
+	if len(hex) < 16 {
+		return false
+	}
+	for _, r := range strings.ToLower(hex) {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// nameSegment keeps the lowercase letters and digits of a path segment,
+// joining runs of them with underscores
+func nameSegment(segment string) string {
+	var b strings.Builder
+	inWord := false
+	for _, r := range strings.ToLower(segment) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if !inWord && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			inWord = true
+			b.WriteRune(r)
+			continue
+		}
+		inWord = false
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+// fakeExecutor records when each endpoint was sent
+type fakeExecutor struct {
+	mu    sync.Mutex
+	start time.Time
+	sent  []sentRequest
+	delay time.Duration
+}
+
+type sentRequest struct {
+	endpoint *config.Endpoint
+	at       time.Duration
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, endpoint *config.Endpoint) *client.RequestResult {
+	f.mu.Lock()
+	f.sent = append(f.sent, sentRequest{endpoint: endpoint, at: time.Since(f.start)})
+	f.mu.Unlock()
+	time.Sleep(f.delay)
+	return &client.RequestResult{EndpointName: endpoint.Name, Success: true}
+}
+
+func entriesAt(offsets ...time.Duration) []Entry {
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	entries := make([]Entry, len(offsets))
+	for i, offset := range offsets {
+		entries[i] = Entry{Time: base.Add(offset), Method: "GET", Path: "/items"}
+	}
+	return entries
+}
+
+func TestPlayerKeepsRelativeTiming(t *testing.T) {
+	exec := &fakeExecutor{start: time.Now()}
+	var recorded int
+	var mu sync.Mutex
+	player, err := New(exec, func(*client.RequestResult) {
+		mu.Lock()
+		recorded++
+		mu.Unlock()
+	}, Options{Target: "http://staging.example.com", Speed: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 0s, 1s, 3s recorded; 0, 100ms, 300ms at 10x
+	stats := player.Run(context.Background(), entriesAt(0, time.Second, 3*time.Second))
+
+	if stats.Sent != 3 || recorded != 3 || stats.Stopped {
+		t.Fatalf("expected 3 sent and recorded, got %+v (recorded %d)", stats, recorded)
+	}
+	expected := []time.Duration{0, 100 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range expected {
+		got := exec.sent[i].at
+		if got < want || got > want+50*time.Millisecond {
+			t.Errorf("request %d: expected at %v, sent at %v", i, want, got)
+		}
+	}
+	if stats.Duration < 300*time.Millisecond {
+		t.Errorf("expected replay to take at least 300ms, took %v", stats.Duration)
+	}
+}
+
+func TestPlayerMaxInFlightDelays(t *testing.T) {
+	exec := &fakeExecutor{start: time.Now(), delay: 200 * time.Millisecond}
+	player, _ := New(exec, nil, Options{Target: "http://staging.example.com", MaxInFlight: 1})
+
+	stats := player.Run(context.Background(), entriesAt(0, 0))
+
+	if stats.Sent != 2 || stats.Late != 1 {
+		t.Errorf("expected second request late behind the first, got %+v", stats)
+	}
+	if stats.MaxLagMs < 150 {
+		t.Errorf("expected lag of about 200ms, got %.1fms", stats.MaxLagMs)
+	}
+}
+
+func TestPlayerStopsOnCancel(t *testing.T) {
+	exec := &fakeExecutor{start: time.Now()}
+	player, _ := New(exec, nil, Options{Target: "http://staging.example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stats := player.Run(ctx, entriesAt(0, time.Hour))
+
+	if stats.Sent != 1 || !stats.Stopped {
+		t.Errorf("expected replay stopped after the first request, got %+v", stats)
+	}
+}
+
+func TestPlayerEndpoint(t *testing.T) {
+	player, err := New(nil, nil, Options{
+		Target:       "https://staging.example.com/v2/",
+		MaxEndpoints: 3,
+		Headers:      map[string]string{"X-Replay": "1"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		name, url    string
+	}{
+		{"GET", "/users/42?expand=1", "get_users_id", "https://staging.example.com/v2/users/42?expand=1"},
+		{"GET", "/users/7", "get_users_id", "https://staging.example.com/v2/users/7"},
+		{"POST", "/orders/3f2b8c1e-9d4a-4b6e-8f1a-2c3d4e5f6a7b/line-items", "post_orders_id_line_items", "https://staging.example.com/v2/orders/3f2b8c1e-9d4a-4b6e-8f1a-2c3d4e5f6a7b/line-items"},
+		{"GET", "/", "get_root", "https://staging.example.com/v2/"},
+		{"GET", "/search?q={{x}}", "get_other", `{{"https://staging.example.com/v2/search?q={{x}}"}}`},
+	}
+
+	for _, tt := range tests {
+		endpoint := player.endpoint(Entry{Method: tt.method, Path: tt.path})
+		if endpoint.Name != tt.name || endpoint.URLTemplate != tt.url || endpoint.Method != tt.method {
+			t.Errorf("%s %s: expected %s %s, got %s %s", tt.method, tt.path, tt.name, tt.url, endpoint.Name, endpoint.URLTemplate)
+		}
+		if endpoint.Headers["X-Replay"] != "1" {
+			t.Errorf("%s %s: expected replay headers, got %v", tt.method, tt.path, endpoint.Headers)
+		}
+	}
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	for _, opts := range []Options{
+		{Target: ""},
+		{Target: "staging.example.com"},
+		{Target: "ftp://staging.example.com"},
+		{Target: "http://staging.example.com", Speed: -1},
+	} {
+		if _, err := New(nil, nil, opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+func TestPlayerAgainstServer(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	log := &Log{Entries: []Entry{
+		{Time: time.Unix(100, 0), Method: "GET", Path: "/a?x=1"},
+		{Time: time.Unix(100, 0), Method: "DELETE", Path: "/b/9"},
+	}}
+	var results []*client.RequestResult
+	player, _ := New(client.New(client.DefaultOptions()), func(result *client.RequestResult) {
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	}, Options{Target: server.URL, MaxInFlight: 1})
+
+	stats := player.Run(context.Background(), log.Entries)
+
+	if stats.Sent != 2 || len(requests) != 2 || requests[0] != "GET /a?x=1" || requests[1] != "DELETE /b/9" {
+		t.Fatalf("expected both requests in order, got %v (%+v)", requests, stats)
+	}
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("expected success, got %+v", result)
+		}
+	}
+}
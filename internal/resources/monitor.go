@@ -0,0 +1,152 @@
+package resources
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Sampling of the resource monitor: every MonitorInterval, keeping
+// monitorHistory samples (15 minutes) to report growth over time
+const (
+	MonitorInterval = 10 * time.Second
+	monitorHistory  = 90
+)
+
+// Process is a sample of the process's resource usage and Go runtime state
+type Process struct {
+	Usage
+	Goroutines int     `json:"goroutines"`
+	HeapMB     float64 `json:"heap_alloc_mb"`
+	GC         GCStats `json:"gc"`
+	Growth     *Growth `json:"growth,omitempty"` // Since the oldest sample kept, nil until there are two
+	SampledAt  string  `json:"sampled_at"`
+
+	at time.Time
+}
+
+// GCStats summarizes garbage collection since the process started
+type GCStats struct {
+	Cycles       uint32  `json:"cycles"`
+	PauseTotalMs float64 `json:"pause_total_ms"`
+	LastPauseMs  float64 `json:"last_pause_ms"`
+	MaxPauseMs   float64 `json:"max_pause_ms"` // Longest of the last 256 pauses
+	CPUFraction  float64 `json:"cpu_fraction"` // Of the available CPU time spent in GC
+}
+
+// Growth is how resource usage changed over the monitored window. Goroutines
+// or sockets that keep growing while the rate is steady point at a leak in
+// the generator rather than a slow target.
+type Growth struct {
+	WindowSeconds       float64 `json:"window_seconds"`
+	Goroutines          int     `json:"goroutines"`
+	GoroutinesPerMinute float64 `json:"goroutines_per_minute"`
+	OpenFDs             int     `json:"open_fds"`
+	OpenSockets         int     `json:"open_sockets"`
+	HeapMB              float64 `json:"heap_alloc_mb"`
+}
+
+// Monitor samples the process's resource usage periodically and keeps a short
+// history of the samples
+type Monitor struct {
+	sampler *Sampler
+
+	mu      sync.Mutex
+	history []Process // Oldest first
+}
+
+// NewMonitor creates a monitor without samples
+func NewMonitor() *Monitor {
+	return &Monitor{sampler: NewSampler()}
+}
+
+// Start samples immediately and then every MonitorInterval until ctx is
+// cancelled
+func (m *Monitor) Start(ctx context.Context) {
+	m.sample(time.Now())
+	go func() {
+		ticker := time.NewTicker(MonitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				m.sample(now)
+			}
+		}
+	}()
+}
+
+// sample records a sample, dropping the oldest beyond the history
+func (m *Monitor) sample(now time.Time) {
+	process := readProcess(m.sampler.Sample(), now)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.history = append(m.history, process)
+	if len(m.history) > monitorHistory {
+		m.history = m.history[len(m.history)-monitorHistory:]
+	}
+}
+
+// Snapshot returns the latest sample with its growth over the history. A
+// monitor that was never started samples on demand.
+func (m *Monitor) Snapshot() Process {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.history) == 0 {
+		return readProcess(m.sampler.Sample(), time.Now())
+	}
+	latest := m.history[len(m.history)-1]
+	if len(m.history) > 1 {
+		latest.Growth = growth(m.history[0], latest)
+	}
+	return latest
+}
+
+// growth returns the change between two samples
+func growth(oldest, latest Process) *Growth {
+	window := latest.at.Sub(oldest.at)
+	g := &Growth{
+		WindowSeconds: window.Seconds(),
+		Goroutines:    latest.Goroutines - oldest.Goroutines,
+		OpenFDs:       latest.OpenFDs - oldest.OpenFDs,
+		OpenSockets:   latest.OpenSockets - oldest.OpenSockets,
+		HeapMB:        latest.HeapMB - oldest.HeapMB,
+	}
+	if window > 0 {
+		g.GoroutinesPerMinute = float64(g.Goroutines) / window.Minutes()
+	}
+	return g
+}
+
+// readProcess completes a usage sample with the Go runtime state
+func readProcess(usage Usage, now time.Time) Process {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	gc := GCStats{
+		Cycles:       memStats.NumGC,
+		PauseTotalMs: float64(memStats.PauseTotalNs) / 1e6,
+		CPUFraction:  memStats.GCCPUFraction,
+	}
+	if memStats.NumGC > 0 {
+		gc.LastPauseMs = float64(memStats.PauseNs[(memStats.NumGC+255)%256]) / 1e6
+	}
+	for _, pause := range memStats.PauseNs {
+		gc.MaxPauseMs = max(gc.MaxPauseMs, float64(pause)/1e6)
+	}
+
+	return Process{
+		Usage:      usage,
+		Goroutines: runtime.NumGoroutine(),
+		HeapMB:     float64(memStats.HeapAlloc) / 1024 / 1024,
+		GC:         gc,
+		SampledAt:  now.Format(time.RFC3339),
+		at:         now,
+	}
+}
@@ -0,0 +1,71 @@
+// Package resources samples the CPU, memory, file descriptor and Go runtime
+// usage of the moxapp process itself, so the load generator can tell when it
+// is becoming the bottleneck
+package resources
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Usage is the resource usage of the process at one point in time
+type Usage struct {
+	CPUPercent  float64 `json:"cpu_percent"`       // Of all cores, averaged since the previous sample
+	MemoryMB    float64 `json:"memory_mb"`         // Resident set size, or memory obtained from the OS where unavailable
+	OpenFDs     int     `json:"open_fds"`          // 0 where unavailable
+	MaxFDs      int     `json:"max_fds,omitempty"` // Soft limit of open files, 0 where unavailable
+	OpenSockets int     `json:"open_sockets"`      // Open FDs that are sockets, 0 where unavailable
+}
+
+// FDPercent returns the open file descriptors in percent of the limit, 0 if
+// the limit is unknown
+func (u Usage) FDPercent() float64 {
+	if u.MaxFDs <= 0 {
+		return 0
+	}
+	return float64(u.OpenFDs) / float64(u.MaxFDs) * 100
+}
+
+// Sampler measures resource usage. CPU usage is averaged between consecutive
+// samples, so the first sample reports none.
+type Sampler struct {
+	mu      sync.Mutex
+	lastCPU time.Duration
+	lastAt  time.Time
+}
+
+// NewSampler creates a sampler
+func NewSampler() *Sampler {
+	return &Sampler{}
+}
+
+// Sample measures the current resource usage
+func (s *Sampler) Sample() Usage {
+	now := time.Now()
+	cpu := cpuTime()
+
+	s.mu.Lock()
+	var cpuPercent float64
+	if elapsed := now.Sub(s.lastAt); !s.lastAt.IsZero() && elapsed > 0 {
+		cpuPercent = float64(cpu-s.lastCPU) / float64(elapsed) / float64(runtime.NumCPU()) * 100
+	}
+	s.lastCPU, s.lastAt = cpu, now
+	s.mu.Unlock()
+
+	openFDs, sockets, maxFDs := fileDescriptors()
+	return Usage{
+		CPUPercent:  cpuPercent,
+		MemoryMB:    float64(residentMemory()) / 1024 / 1024,
+		OpenFDs:     openFDs,
+		MaxFDs:      maxFDs,
+		OpenSockets: sockets,
+	}
+}
+
+// goMemory returns the memory obtained from the OS by the Go runtime
+func goMemory() uint64 {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return memStats.Sys
+}
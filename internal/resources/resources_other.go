@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package resources
+
+import "time"
+
+// cpuTime returns 0; CPU time is only measured on Linux and macOS
+func cpuTime() time.Duration {
+	return 0
+}
+
+// residentMemory returns the Go runtime's memory
+func residentMemory() uint64 {
+	return goMemory()
+}
+
+// fileDescriptors returns zeros; file descriptors are only counted on Linux
+// and macOS
+func fileDescriptors() (int, int, int) {
+	return 0, 0, 0
+}
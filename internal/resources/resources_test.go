@@ -0,0 +1,51 @@
+package resources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler_Sample(t *testing.T) {
+	sampler := NewSampler()
+	first := sampler.Sample()
+	if first.MemoryMB <= 0 {
+		t.Errorf("expected memory usage, got %+v", first)
+	}
+	if first.CPUPercent != 0 {
+		t.Errorf("expected no CPU usage from the first sample, got %v", first.CPUPercent)
+	}
+
+	if second := sampler.Sample(); second.CPUPercent < 0 {
+		t.Errorf("expected non-negative CPU usage, got %v", second.CPUPercent)
+	}
+}
+
+func TestUsage_FDPercent(t *testing.T) {
+	if got := (Usage{OpenFDs: 256, MaxFDs: 1024}).FDPercent(); got != 25 {
+		t.Errorf("expected 25%%, got %v", got)
+	}
+	if got := (Usage{OpenFDs: 256}).FDPercent(); got != 0 {
+		t.Errorf("expected 0 without a limit, got %v", got)
+	}
+}
+
+func TestMonitor_Growth(t *testing.T) {
+	monitor := NewMonitor()
+	if process := monitor.Snapshot(); process.Goroutines == 0 || process.Growth != nil {
+		t.Errorf("expected an on-demand sample without growth, got %+v", process)
+	}
+
+	start := time.Now()
+	monitor.sample(start)
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < 10; i++ {
+		go func() { <-done }()
+	}
+	monitor.sample(start.Add(time.Minute))
+
+	growth := monitor.Snapshot().Growth
+	if growth == nil || growth.WindowSeconds != 60 || growth.Goroutines < 10 || growth.GoroutinesPerMinute != float64(growth.Goroutines) {
+		t.Errorf("expected the goroutines started to show as growth, got %+v", growth)
+	}
+}
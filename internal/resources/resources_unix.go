@@ -0,0 +1,61 @@
+//go:build linux || darwin
+
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cpuTime returns the user and system CPU time used by the process
+func cpuTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// residentMemory returns the resident set size in bytes. It is read from
+// /proc on Linux; elsewhere the Go runtime's memory is used.
+func residentMemory() uint64 {
+	if runtime.GOOS == "linux" {
+		if data, err := os.ReadFile("/proc/self/statm"); err == nil {
+			if fields := strings.Fields(string(data)); len(fields) > 1 {
+				if pages, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+					return pages * uint64(os.Getpagesize())
+				}
+			}
+		}
+	}
+	return goMemory()
+}
+
+// fileDescriptors returns the open file descriptors, how many of them are
+// sockets, and the soft limit of open files
+func fileDescriptors() (int, int, int) {
+	dir := "/dev/fd"
+	if runtime.GOOS == "linux" {
+		dir = "/proc/self/fd"
+	}
+	var open, sockets int
+	if entries, err := os.ReadDir(dir); err == nil {
+		open = len(entries)
+		for _, entry := range entries {
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil && info.Mode()&os.ModeSocket != 0 {
+				sockets++
+			}
+		}
+	}
+
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return open, sockets, 0
+	}
+	return open, sockets, int(limit.Cur)
+}
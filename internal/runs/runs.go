@@ -0,0 +1,236 @@
+// Package runs tracks named load test runs with their config and metrics
+package runs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+)
+
+// Run status values
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+)
+
+// Listener is called when a run starts or completes, with StatusRunning or
+// StatusCompleted as event. It is called outside the store's lock.
+type Listener func(event string, run *Run)
+
+// DefaultMaxRuns is the number of runs kept in history
+const DefaultMaxRuns = 100
+
+// Run is a single load test execution
+type Run struct {
+	ID              string                   `json:"id"`
+	Label           string                   `json:"label"`
+	Status          string                   `json:"status"`
+	StartedAt       time.Time                `json:"started_at"`
+	EndedAt         *time.Time               `json:"ended_at,omitempty"`
+	DurationSeconds float64                  `json:"duration_seconds"`
+	Config          *config.Config           `json:"config"`
+	ConfigSource    *config.Provenance       `json:"config_source,omitempty"` // Config file revision the run started with
+	Metrics         *metrics.MetricsSnapshot `json:"metrics,omitempty"`       // Final metrics, set when the run is finalized
+}
+
+// RunSummary is a compact view of a run used in listings
+type RunSummary struct {
+	ID              string     `json:"id"`
+	Label           string     `json:"label"`
+	Status          string     `json:"status"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	TotalRequests   int64      `json:"total_requests"`
+	SuccessRate     float64    `json:"success_rate"`
+}
+
+// Store keeps run history and owns the lifecycle of the current run.
+// Starting a run resets the metrics collector so its metrics cover only that run.
+type Store struct {
+	collector *metrics.Collector
+	runs      []*Run // Oldest first
+	current   *Run
+	maxRuns   int
+	seq       int
+	listener  Listener
+	source    func() *config.Provenance
+	mu        sync.RWMutex
+}
+
+// NewStore creates a run store that snapshots metrics from the given collector
+func NewStore(collector *metrics.Collector, maxRuns int) *Store {
+	if maxRuns <= 0 {
+		maxRuns = DefaultMaxRuns
+	}
+	return &Store{
+		collector: collector,
+		maxRuns:   maxRuns,
+	}
+}
+
+// SetListener sets the function notified when runs start and complete
+func (s *Store) SetListener(listener Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listener = listener
+}
+
+// SetConfigSource sets the function returning the config file revision
+// recorded with each new run
+func (s *Store) SetConfigSource(source func() *config.Provenance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.source = source
+}
+
+// Start finalizes the current run (if any), resets metrics and starts a new run
+func (s *Store) Start(label string, cfg *config.Config) *Run {
+	s.mu.Lock()
+	finished := s.finishLocked()
+
+	now := time.Now()
+	s.seq++
+	run := &Run{
+		ID:        fmt.Sprintf("%s-%03d", now.Format("20060102-150405"), s.seq),
+		Label:     label,
+		Status:    StatusRunning,
+		StartedAt: now,
+		Config:    cfg,
+	}
+	if run.Label == "" {
+		run.Label = "run " + run.ID
+	}
+	if s.source != nil {
+		run.ConfigSource = s.source()
+	}
+
+	s.collector.Reset()
+	s.current = run
+	s.runs = append(s.runs, run)
+	if len(s.runs) > s.maxRuns {
+		s.runs = s.runs[len(s.runs)-s.maxRuns:]
+	}
+	started := run.copy()
+	if finished != nil {
+		finished = finished.copy()
+	}
+	listener := s.listener
+	s.mu.Unlock()
+
+	if listener != nil {
+		if finished != nil {
+			listener(StatusCompleted, finished)
+		}
+		listener(StatusRunning, started.copy())
+	}
+	return started
+}
+
+// Finish finalizes the current run, capturing its final metrics
+func (s *Store) Finish() (*Run, error) {
+	s.mu.Lock()
+	run := s.finishLocked()
+	if run != nil {
+		run = run.copy()
+	}
+	listener := s.listener
+	s.mu.Unlock()
+
+	if run == nil {
+		return nil, fmt.Errorf("no run in progress")
+	}
+	if listener != nil {
+		listener(StatusCompleted, run.copy())
+	}
+	return run, nil
+}
+
+// Current returns the run in progress with live metrics, or nil
+func (s *Store) Current() *Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.current == nil {
+		return nil
+	}
+	return s.withLiveMetrics(s.current)
+}
+
+// Get returns a run by ID. A run in progress includes live metrics.
+func (s *Store) Get(id string) (*Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, run := range s.runs {
+		if run.ID == id {
+			if run == s.current {
+				return s.withLiveMetrics(run), nil
+			}
+			return run.copy(), nil
+		}
+	}
+	return nil, fmt.Errorf("run not found: %s", id)
+}
+
+// List returns summaries of all runs, newest first
+func (s *Store) List() []RunSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make([]RunSummary, 0, len(s.runs))
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		run := s.runs[i]
+		if run == s.current {
+			run = s.withLiveMetrics(run)
+		}
+		summary := RunSummary{
+			ID:              run.ID,
+			Label:           run.Label,
+			Status:          run.Status,
+			StartedAt:       run.StartedAt,
+			EndedAt:         run.EndedAt,
+			DurationSeconds: run.DurationSeconds,
+		}
+		if run.Metrics != nil {
+			summary.TotalRequests = run.Metrics.TotalRequests
+			summary.SuccessRate = run.Metrics.SuccessRate
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// finishLocked finalizes the current run and returns it (caller holds lock)
+func (s *Store) finishLocked() *Run {
+	run := s.current
+	if run == nil {
+		return nil
+	}
+
+	now := time.Now()
+	run.Status = StatusCompleted
+	run.EndedAt = &now
+	run.DurationSeconds = now.Sub(run.StartedAt).Seconds()
+	run.Metrics = s.collector.Snapshot()
+	s.current = nil
+
+	return run
+}
+
+// withLiveMetrics returns a copy of a running run with current metrics attached (caller holds lock)
+func (s *Store) withLiveMetrics(run *Run) *Run {
+	live := run.copy()
+	live.DurationSeconds = time.Since(run.StartedAt).Seconds()
+	live.Metrics = s.collector.Snapshot()
+	return live
+}
+
+// copy returns a shallow copy of the run; config and metrics snapshots are never mutated
+func (r *Run) copy() *Run {
+	runCopy := *r
+	return &runCopy
+}
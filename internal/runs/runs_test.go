@@ -0,0 +1,71 @@
+package runs
+
+import (
+	"testing"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+	"moxapp/internal/metrics"
+)
+
+func TestStore_Lifecycle(t *testing.T) {
+	collector := metrics.NewCollector()
+	store := NewStore(collector, 2)
+
+	first := store.Start("baseline", &config.Config{})
+	collector.Record(&client.RequestResult{EndpointName: "a", Success: true})
+
+	second := store.Start("", &config.Config{})
+	if second.Label == "" {
+		t.Error("expected default label for unlabeled run")
+	}
+
+	finished, err := store.Get(first.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finished.Status != StatusCompleted {
+		t.Errorf("expected first run to be completed, got %s", finished.Status)
+	}
+	if finished.Metrics == nil || finished.Metrics.TotalRequests != 1 {
+		t.Error("expected first run to keep its final metrics")
+	}
+
+	if current := store.Current(); current == nil || current.Metrics.TotalRequests != 0 {
+		t.Error("expected metrics to be reset for the new run")
+	}
+
+	if _, err := store.Finish(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.Finish(); err == nil {
+		t.Error("expected error finishing with no run in progress")
+	}
+
+	store.Start("third", &config.Config{})
+	if runs := store.List(); len(runs) != 2 || runs[0].Label != "third" {
+		t.Errorf("expected history capped at 2 runs, newest first, got %+v", runs)
+	}
+}
+
+func TestStore_Listener(t *testing.T) {
+	store := NewStore(metrics.NewCollector(), 0)
+	var events []string
+	store.SetListener(func(event string, run *Run) {
+		events = append(events, event+" "+run.Label)
+	})
+
+	store.Start("a", &config.Config{})
+	store.Start("b", &config.Config{})
+	store.Finish()
+
+	want := []string{"running a", "completed a", "running b", "completed b"}
+	if len(events) != len(want) {
+		t.Fatalf("expected %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d: expected %q, got %q", i, want[i], events[i])
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+// Adaptive mode states
+const (
+	AdaptiveStateDisabled   = "disabled"
+	AdaptiveStateWarmingUp  = "warming_up"  // Waiting for enough samples in the period
+	AdaptiveStateRamping    = "ramping"     // Healthy, multiplier increased
+	AdaptiveStateBackingOff = "backing_off" // Threshold crossed, multiplier reduced
+	AdaptiveStateHolding    = "holding"     // Healthy at the highest safe multiplier
+)
+
+// AdaptiveStatus reports the state of adaptive mode
+type AdaptiveStatus struct {
+	Enabled              bool    `json:"enabled"`
+	State                string  `json:"state"`
+	Multiplier           float64 `json:"multiplier"`
+	MaxHealthyMultiplier float64 `json:"max_healthy_multiplier"` // Highest multiplier that stayed within thresholds
+	BreachMultiplier     float64 `json:"breach_multiplier"`      // Lowest multiplier that crossed a threshold, 0 if none
+	LastP95Ms            float64 `json:"last_p95_ms"`
+	LastErrorRate        float64 `json:"last_error_rate"`
+	LastSamples          int     `json:"last_samples"`
+	Adjustments          int64   `json:"adjustments"`
+	LastEvaluatedAt      string  `json:"last_evaluated_at,omitempty"`
+	LastReason           string  `json:"last_reason,omitempty"`
+}
+
+// adaptiveController adjusts the global multiplier based on latency and errors
+// observed during each evaluation period
+type adaptiveController struct {
+	configManager *config.Manager
+
+	// Observations for the current period
+	latencies []float64
+	failures  int
+
+	status AdaptiveStatus
+	mu     sync.Mutex
+}
+
+// newAdaptiveController creates a controller in the disabled state
+func newAdaptiveController(configManager *config.Manager) *adaptiveController {
+	return &adaptiveController{
+		configManager: configManager,
+		status:        AdaptiveStatus{State: AdaptiveStateDisabled},
+	}
+}
+
+// observe records a request result for the current period (discarded at the
+// end of the period while adaptive mode is disabled)
+func (a *adaptiveController) observe(result *client.RequestResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.latencies = append(a.latencies, result.TotalTimeMs)
+	if !result.Success {
+		a.failures++
+	}
+}
+
+// run evaluates each period until ctx is cancelled. paused reports whether
+// scheduling is currently paused, in which case periods are discarded.
+func (a *adaptiveController) run(ctx context.Context, paused func() bool) {
+	for {
+		interval := time.Duration(a.configManager.GetAdaptiveConfig().IntervalSeconds) * time.Second
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			a.evaluate(paused())
+		}
+	}
+}
+
+// evaluate closes the current period and adjusts the multiplier
+func (a *adaptiveController) evaluate(paused bool) {
+	adaptiveCfg := a.configManager.GetAdaptiveConfig()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	latencies, failures := a.latencies, a.failures
+	a.latencies, a.failures = nil, 0
+
+	if !adaptiveCfg.Enabled {
+		if a.status.Enabled {
+			a.status = AdaptiveStatus{State: AdaptiveStateDisabled}
+		}
+		return
+	}
+	if !a.status.Enabled {
+		// Freshly enabled: start capacity finding from scratch
+		a.status = AdaptiveStatus{Enabled: true, State: AdaptiveStateWarmingUp}
+	}
+	if paused {
+		return
+	}
+
+	multiplier := a.configManager.GetConfig().GlobalMultiplier
+	a.status.Multiplier = multiplier
+	a.status.LastSamples = len(latencies)
+	a.status.LastEvaluatedAt = time.Now().Format(time.RFC3339)
+
+	if len(latencies) < adaptiveCfg.MinSamples {
+		a.status.State = AdaptiveStateWarmingUp
+		a.status.LastReason = "not enough samples"
+		return
+	}
+
+	sort.Float64s(latencies)
+	p95 := latencies[int(math.Min(float64(len(latencies))*0.95, float64(len(latencies)-1)))]
+	errorRate := float64(failures) / float64(len(latencies)) * 100
+	a.status.LastP95Ms = p95
+	a.status.LastErrorRate = errorRate
+
+	breached := false
+	switch {
+	case adaptiveCfg.MaxP95Ms > 0 && p95 > adaptiveCfg.MaxP95Ms:
+		breached = true
+		a.status.LastReason = "p95 latency above threshold"
+	case adaptiveCfg.MaxErrorRate > 0 && errorRate > adaptiveCfg.MaxErrorRate:
+		breached = true
+		a.status.LastReason = "error rate above threshold"
+	}
+
+	if breached {
+		if a.status.BreachMultiplier == 0 || multiplier < a.status.BreachMultiplier {
+			a.status.BreachMultiplier = multiplier
+		}
+		next := math.Max(adaptiveCfg.MinMultiplier, multiplier*adaptiveCfg.BackoffFactor)
+		a.setMultiplier(next)
+		a.status.State = AdaptiveStateBackingOff
+		return
+	}
+
+	if multiplier > a.status.MaxHealthyMultiplier {
+		a.status.MaxHealthyMultiplier = multiplier
+	}
+
+	// Never ramp back up to a multiplier that already crossed a threshold
+	ceiling := adaptiveCfg.MaxMultiplier
+	if a.status.BreachMultiplier > 0 {
+		ceiling = math.Min(ceiling, a.status.BreachMultiplier-adaptiveCfg.Step)
+	}
+
+	next := math.Min(multiplier+adaptiveCfg.Step, ceiling)
+	if next <= multiplier {
+		a.status.State = AdaptiveStateHolding
+		a.status.LastReason = "at highest safe multiplier"
+		return
+	}
+	if !a.setMultiplier(next) {
+		a.status.State = AdaptiveStateHolding
+		a.status.LastReason = "at guardrails ceiling"
+		return
+	}
+	a.status.State = AdaptiveStateRamping
+	a.status.LastReason = "within thresholds"
+}
+
+// setMultiplier applies a new global multiplier (caller holds lock). It
+// returns false if the guardrails don't allow it.
+func (a *adaptiveController) setMultiplier(multiplier float64) bool {
+	multiplier = math.Round(multiplier*1000) / 1000
+	if err := a.configManager.SetGlobalMultiplier(multiplier); err != nil {
+		return false
+	}
+	a.status.Multiplier = multiplier
+	a.status.Adjustments++
+	return true
+}
+
+// getStatus returns a copy of the current status
+func (a *adaptiveController) getStatus() AdaptiveStatus {
+	enabled := a.configManager.GetAdaptiveConfig().Enabled
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if enabled && !a.status.Enabled {
+		// Enabled since the last evaluation
+		return AdaptiveStatus{
+			Enabled:    true,
+			State:      AdaptiveStateWarmingUp,
+			Multiplier: a.configManager.GetConfig().GlobalMultiplier,
+		}
+	}
+	return a.status
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"testing"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+func TestAdaptiveController_RampAndBackoff(t *testing.T) {
+	manager := config.NewManager()
+	if err := manager.ReplaceConfig(&config.Config{
+		GlobalMultiplier: 1.0,
+		Adaptive: config.AdaptiveConfig{
+			Enabled:    true,
+			Step:       0.5,
+			MaxP95Ms:   100,
+			MinSamples: 10,
+		},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	controller := newAdaptiveController(manager)
+	observe := func(latencyMs float64) {
+		for i := 0; i < 20; i++ {
+			controller.observe(&client.RequestResult{TotalTimeMs: latencyMs, Success: true})
+		}
+	}
+
+	observe(50)
+	controller.evaluate(false)
+	if got := manager.GetConfig().GlobalMultiplier; got != 1.5 {
+		t.Errorf("expected multiplier 1.5 after healthy period, got %.2f", got)
+	}
+
+	observe(200)
+	controller.evaluate(false)
+	status := controller.getStatus()
+	if status.State != AdaptiveStateBackingOff {
+		t.Errorf("expected backing_off state, got %s", status.State)
+	}
+	if status.BreachMultiplier != 1.5 {
+		t.Errorf("expected breach at 1.5, got %.2f", status.BreachMultiplier)
+	}
+	if got := manager.GetConfig().GlobalMultiplier; got != 0.75 {
+		t.Errorf("expected multiplier 0.75 after backoff, got %.2f", got)
+	}
+
+	// Ramping stops one step below the breach multiplier
+	observe(50)
+	controller.evaluate(false)
+	observe(50)
+	controller.evaluate(false)
+	if got := manager.GetConfig().GlobalMultiplier; got != 1.0 {
+		t.Errorf("expected multiplier capped at 1.0, got %.2f", got)
+	}
+	if status := controller.getStatus(); status.State != AdaptiveStateHolding {
+		t.Errorf("expected holding state, got %s", status.State)
+	}
+}
@@ -0,0 +1,99 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"sort"
+	"time"
+)
+
+// endpointLag tracks how far an endpoint's requests fall behind schedule
+type endpointLag struct {
+	scheduled       int64
+	missedIntervals int64
+	totalLag        time.Duration
+	maxLag          time.Duration
+
+	waits     int64
+	totalWait time.Duration
+	maxWait   time.Duration
+	lastWait  time.Duration
+}
+
+// EndpointBackpressure shows whether an endpoint gets the load it is
+// configured for: schedule lag is how late requests were started by the
+// scheduler loop, queue wait how long they then waited for a worker
+type EndpointBackpressure struct {
+	Name            string  `json:"name"`
+	Scheduled       int64   `json:"scheduled"`
+	MissedIntervals int64   `json:"missed_intervals"` // Whole intervals skipped because the scheduler was late
+	AvgLagMs        float64 `json:"avg_lag_ms"`
+	MaxLagMs        float64 `json:"max_lag_ms"`
+	AvgQueueWaitMs  float64 `json:"avg_queue_wait_ms"`
+	MaxQueueWaitMs  float64 `json:"max_queue_wait_ms"`
+	LastQueueWaitMs float64 `json:"last_queue_wait_ms"`
+	Starved         int64   `json:"starved"` // Waited longer than StarvationWait
+}
+
+// recordSchedule records a request started lag after its scheduled time,
+// given the interval to the endpoint's next request (caller holds mu)
+func (s *Scheduler) recordSchedule(name string, lag, interval time.Duration) {
+	stats := s.lagStats(name)
+	stats.scheduled++
+	stats.totalLag += lag
+	stats.maxLag = max(stats.maxLag, lag)
+	if interval > 0 && lag >= interval {
+		stats.missedIntervals += int64(lag / interval)
+	}
+}
+
+// recordQueueWait records how long a request waited for a worker (caller holds mu)
+func (s *Scheduler) recordQueueWait(name string, wait time.Duration) {
+	stats := s.lagStats(name)
+	stats.waits++
+	stats.totalWait += wait
+	stats.maxWait = max(stats.maxWait, wait)
+	stats.lastWait = wait
+}
+
+// lagStats returns the lag stats of an endpoint, creating them if needed (caller holds mu)
+func (s *Scheduler) lagStats(name string) *endpointLag {
+	stats, exists := s.lag[name]
+	if !exists {
+		stats = &endpointLag{}
+		s.lag[name] = stats
+	}
+	return stats
+}
+
+// GetBackpressure returns schedule lag and queue wait per endpoint, sorted by name
+func (s *Scheduler) GetBackpressure() []EndpointBackpressure {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]EndpointBackpressure, 0, len(s.lag))
+	for name, stats := range s.lag {
+		bp := EndpointBackpressure{
+			Name:            name,
+			Scheduled:       stats.scheduled,
+			MissedIntervals: stats.missedIntervals,
+			MaxLagMs:        durationMs(stats.maxLag),
+			MaxQueueWaitMs:  durationMs(stats.maxWait),
+			LastQueueWaitMs: durationMs(stats.lastWait),
+			Starved:         s.starved[name],
+		}
+		if stats.scheduled > 0 {
+			bp.AvgLagMs = durationMs(stats.totalLag) / float64(stats.scheduled)
+		}
+		if stats.waits > 0 {
+			bp.AvgQueueWaitMs = durationMs(stats.totalWait) / float64(stats.waits)
+		}
+		result = append(result, bp)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
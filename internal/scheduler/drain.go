@@ -0,0 +1,120 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Drain states
+const (
+	DrainStateDraining = "draining"
+	DrainStateDrained  = "drained"
+	DrainStateAborted  = "aborted" // Resumed before in-flight requests finished
+)
+
+// drainPollInterval is how often a drain checks for remaining requests
+const drainPollInterval = 50 * time.Millisecond
+
+// DrainStatus reports the progress of the latest drain
+type DrainStatus struct {
+	State          string     `json:"state"`
+	StartedAt      time.Time  `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	PendingAtStart int        `json:"pending_at_start"` // Requests queued or running when the drain started
+	Pending        int        `json:"pending"`
+	DurationMs     float64    `json:"duration_ms"`
+}
+
+// Drain stops scheduling new requests and lets queued and running requests
+// finish, unlike EmergencyStop which cancels them, and Pause which drops
+// queued ones. The scheduler stays paused afterwards; progress is reported
+// by GetDrainStatus.
+func (s *Scheduler) Drain() DrainStatus {
+	// Queued requests must not see the pause without the drain
+	atomic.StoreInt32(&s.draining, 1)
+	s.Pause()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.drain != nil && s.drain.State == DrainStateDraining {
+		return s.drainStatus()
+	}
+	s.drain = &DrainStatus{
+		State:          DrainStateDraining,
+		StartedAt:      time.Now(),
+		PendingAtStart: s.pendingRequests(),
+	}
+	go s.waitDrained(s.drain)
+	return s.drainStatus()
+}
+
+// waitDrained completes a drain once no requests are pending
+func (s *Scheduler) waitDrained(drain *DrainStatus) {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.mu.Lock()
+		if s.drain != drain {
+			s.mu.Unlock()
+			return
+		}
+		state := ""
+		if !s.IsPaused() {
+			state = DrainStateAborted
+		} else if s.pendingRequests() == 0 {
+			state = DrainStateDrained
+		}
+		if state == "" {
+			s.mu.Unlock()
+			continue
+		}
+		now := time.Now()
+		drain.State = state
+		drain.CompletedAt = &now
+		atomic.StoreInt32(&s.draining, 0)
+		s.mu.Unlock()
+
+		fmt.Printf("[scheduler] drain %s after %s\n", state, now.Sub(drain.StartedAt).Round(time.Millisecond))
+		return
+	}
+}
+
+// GetDrainStatus returns the latest drain, or nil if none was started
+func (s *Scheduler) GetDrainStatus() *DrainStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.drain == nil {
+		return nil
+	}
+	status := s.drainStatus()
+	return &status
+}
+
+// drainStatus returns a copy of the latest drain with current progress
+// (caller holds mu)
+func (s *Scheduler) drainStatus() DrainStatus {
+	status := *s.drain
+	end := time.Now()
+	if status.CompletedAt != nil {
+		end = *status.CompletedAt
+	}
+	if status.State == DrainStateDraining {
+		status.Pending = s.pendingRequests()
+	}
+	status.DurationMs = durationMs(end.Sub(status.StartedAt))
+	return status
+}
+
+// pendingRequests returns the number of requests queued or running (caller holds mu)
+func (s *Scheduler) pendingRequests() int {
+	pending := 0
+	for _, n := range s.inFlight {
+		pending += n
+	}
+	return pending
+}
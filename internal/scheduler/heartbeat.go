@@ -0,0 +1,116 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// heartbeatCheckInterval is how often the dead-man switch checks for a
+// missed heartbeat
+const heartbeatCheckInterval = time.Second
+
+// HeartbeatStatus reports the state of the dead-man switch
+type HeartbeatStatus struct {
+	Enabled          bool    `json:"enabled"`
+	TimeoutMinutes   int     `json:"timeout_minutes,omitempty"`
+	LastHeartbeat    string  `json:"last_heartbeat,omitempty"`
+	PausesAt         string  `json:"pauses_at,omitempty"`         // When the scheduler pauses without another heartbeat
+	RemainingSeconds float64 `json:"remaining_seconds,omitempty"` // Until then
+	Tripped          bool    `json:"tripped"`                     // The scheduler was paused for a missed heartbeat
+	TrippedAt        string  `json:"tripped_at,omitempty"`
+}
+
+// heartbeatWatch tracks heartbeats for the dead-man switch
+type heartbeatWatch struct {
+	mu        sync.Mutex
+	last      time.Time // Last heartbeat, or when watching started
+	trippedAt time.Time // Zero unless paused for a missed heartbeat since the last one
+}
+
+// beat records a heartbeat
+func (h *heartbeatWatch) beat(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.last = now
+	h.trippedAt = time.Time{}
+}
+
+// start begins the countdown from now. Heartbeats from before a stop don't
+// count, so the switch doesn't trip right after the scheduler starts again.
+func (h *heartbeatWatch) start(now time.Time) {
+	h.beat(now)
+}
+
+// expired returns true, once, when the timeout passed without a heartbeat
+func (h *heartbeatWatch) expired(timeout time.Duration, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last.IsZero() || !h.trippedAt.IsZero() || now.Sub(h.last) < timeout {
+		return false
+	}
+	h.trippedAt = now
+	return true
+}
+
+// Heartbeat records a heartbeat, postponing the pause of the dead-man switch
+// by its timeout. It doesn't resume a scheduler the switch already paused.
+func (s *Scheduler) Heartbeat() HeartbeatStatus {
+	s.heartbeat.beat(time.Now())
+	return s.GetHeartbeatStatus()
+}
+
+// GetHeartbeatStatus returns the state of the dead-man switch
+func (s *Scheduler) GetHeartbeatStatus() HeartbeatStatus {
+	cfg := s.configManager.GetHeartbeatConfig()
+
+	s.heartbeat.mu.Lock()
+	last, trippedAt := s.heartbeat.last, s.heartbeat.trippedAt
+	s.heartbeat.mu.Unlock()
+
+	status := HeartbeatStatus{Enabled: cfg.Enabled(), TimeoutMinutes: cfg.Timeout, Tripped: !trippedAt.IsZero()}
+	if !last.IsZero() {
+		status.LastHeartbeat = last.Format(time.RFC3339)
+	}
+	if status.Tripped {
+		status.TrippedAt = trippedAt.Format(time.RFC3339)
+	} else if status.Enabled && !last.IsZero() {
+		pausesAt := last.Add(cfg.TimeoutDuration())
+		status.PausesAt = pausesAt.Format(time.RFC3339)
+		status.RemainingSeconds = max(0, time.Until(pausesAt).Seconds())
+	}
+	return status
+}
+
+// watchHeartbeat pauses scheduling when the heartbeat timeout passes without
+// a heartbeat, until ctx is cancelled
+func (s *Scheduler) watchHeartbeat(ctx context.Context) {
+	s.heartbeat.start(time.Now())
+
+	ticker := time.NewTicker(heartbeatCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.checkHeartbeat(now)
+		}
+	}
+}
+
+// checkHeartbeat pauses scheduling if the heartbeat timeout has passed
+func (s *Scheduler) checkHeartbeat(now time.Time) {
+	cfg := s.configManager.GetHeartbeatConfig()
+	if !cfg.Enabled() || s.IsPaused() {
+		return
+	}
+	if s.heartbeat.expired(cfg.TimeoutDuration(), now) {
+		fmt.Printf("[heartbeat] No heartbeat for %d minutes, pausing the scheduler; resume with action resume\n", cfg.Timeout)
+		s.Pause()
+	}
+}
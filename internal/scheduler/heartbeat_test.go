@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+func TestHeartbeat_PausesWithoutHeartbeat(t *testing.T) {
+	manager := config.NewManager()
+	manager.SetHeartbeatTimeout(10)
+	s := New(manager, nil, nil)
+
+	start := time.Now()
+	s.heartbeat.start(start)
+	s.checkHeartbeat(start.Add(9 * time.Minute))
+	if s.IsPaused() {
+		t.Fatal("expected the scheduler to run before the timeout")
+	}
+
+	// A heartbeat postpones the pause
+	s.heartbeat.beat(start.Add(9 * time.Minute))
+	s.checkHeartbeat(start.Add(15 * time.Minute))
+	if s.IsPaused() {
+		t.Fatal("expected the heartbeat to postpone the pause")
+	}
+
+	s.checkHeartbeat(start.Add(19 * time.Minute))
+	if !s.IsPaused() || manager.IsEnabled() {
+		t.Fatal("expected the scheduler to pause without a heartbeat")
+	}
+	if status := s.GetHeartbeatStatus(); !status.Tripped || status.TrippedAt == "" {
+		t.Errorf("expected the status to report the pause, got %+v", status)
+	}
+
+	// Resuming counts as a heartbeat
+	s.Resume()
+	s.checkHeartbeat(time.Now().Add(time.Minute))
+	if s.IsPaused() {
+		t.Error("expected the scheduler to keep running after resuming")
+	}
+	if status := s.GetHeartbeatStatus(); status.Tripped || status.RemainingSeconds <= 0 {
+		t.Errorf("expected a fresh countdown after resuming, got %+v", status)
+	}
+}
+
+func TestHeartbeat_Disabled(t *testing.T) {
+	s := New(config.NewManager(), nil, nil)
+	start := time.Now()
+	s.heartbeat.start(start)
+	s.checkHeartbeat(start.Add(24 * time.Hour))
+	if s.IsPaused() {
+		t.Error("expected no pause without a heartbeat timeout")
+	}
+}
+
+func TestHeartbeat_RestartsCountdown(t *testing.T) {
+	manager := config.NewManager()
+	manager.SetHeartbeatTimeout(10)
+	s := New(manager, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx, false)
+
+	waitFor(t, s.IsRunning)
+	if !s.Stop() {
+		t.Fatal("Stop should succeed while running")
+	}
+	waitFor(t, func() bool { return !s.IsRunning() })
+
+	// Stopped for longer than the timeout
+	stale := time.Now().Add(-time.Hour)
+	s.heartbeat.beat(stale)
+
+	if err := s.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		s.heartbeat.mu.Lock()
+		defer s.heartbeat.mu.Unlock()
+		return s.heartbeat.last.After(stale)
+	})
+	s.checkHeartbeat(time.Now().Add(time.Second))
+	if s.IsPaused() {
+		t.Error("expected a fresh countdown after restarting, not a pause for the time stopped")
+	}
+}
@@ -0,0 +1,65 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import "moxapp/internal/config"
+
+// HostInFlight is the in-flight gauge of a hostname
+type HostInFlight struct {
+	InFlight int   `json:"in_flight"`       // Queued and running requests
+	Limit    int   `json:"limit,omitempty"` // host_limits cap, 0 if unlimited
+	Capped   int64 `json:"capped"`          // Not sent because the hostname was at its cap
+}
+
+// hostAvailable reports whether a hostname is below its in-flight cap,
+// counting a capped request if not (caller holds mu)
+func (s *Scheduler) hostAvailable(host string, limit int) bool {
+	if limit <= 0 || s.hostInFlight[host] < limit {
+		return true
+	}
+	s.hostCapped[host]++
+	return false
+}
+
+// hostStarted counts requests to a hostname as in flight (caller holds mu)
+func (s *Scheduler) hostStarted(host string, n int) {
+	if host != "" && n > 0 {
+		s.hostInFlight[host] += n
+	}
+}
+
+// hostDone counts a request to a hostname as finished (caller holds mu)
+func (s *Scheduler) hostDone(host string) {
+	if host == "" {
+		return
+	}
+	if s.hostInFlight[host]--; s.hostInFlight[host] <= 0 {
+		delete(s.hostInFlight, host)
+	}
+}
+
+// hostStats returns the in-flight gauges of the given hostnames and of any
+// other hostname with requests in flight or capped (caller holds mu)
+func (s *Scheduler) hostStats(limits config.HostLimitsConfig, hosts []string) map[string]HostInFlight {
+	stats := make(map[string]HostInFlight)
+	add := func(host string) {
+		if _, ok := stats[host]; ok || host == "" {
+			return
+		}
+		stats[host] = HostInFlight{
+			InFlight: s.hostInFlight[host],
+			Limit:    limits.Limit(host),
+			Capped:   s.hostCapped[host],
+		}
+	}
+
+	for _, host := range hosts {
+		add(host)
+	}
+	for host := range s.hostInFlight {
+		add(host)
+	}
+	for host := range s.hostCapped {
+		add(host)
+	}
+	return stats
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+func TestHostLimits(t *testing.T) {
+	var mu sync.Mutex
+	concurrent, maxConcurrent := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		concurrent++
+		maxConcurrent = max(maxConcurrent, concurrent)
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		concurrent--
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cm := config.NewManager()
+	if err := cm.ReplaceConfig(&config.Config{
+		Enabled:    true,
+		HostLimits: config.HostLimitsConfig{MaxInFlight: 2},
+		Endpoints: []config.Endpoint{
+			{Name: "a", Method: "GET", URLTemplate: server.URL, FrequencyPerMin: 3000, Timeout: 5, Enabled: true, EnabledSet: true},
+			{Name: "b", Method: "GET", URLTemplate: server.URL, FrequencyPerMin: 3000, Timeout: 5, Enabled: true, EnabledSet: true},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(cm, client.New(client.DefaultOptions()), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx, false) }()
+
+	time.Sleep(300 * time.Millisecond)
+	host := s.GetStats().Hosts["127.0.0.1"]
+	cancel()
+	<-done
+
+	if host.Limit != 2 || host.Capped == 0 || host.InFlight > 2 {
+		t.Errorf("expected capped requests with at most 2 in flight, got %+v", host)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if maxConcurrent > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", maxConcurrent)
+	}
+}
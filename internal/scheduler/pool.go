@@ -0,0 +1,187 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"context"
+	"sync"
+)
+
+// workerPool limits concurrency with worker IDs 1..size. Requests waiting
+// for a worker are queued per endpoint. Endpoints with a higher priority are
+// served first, and endpoints of the same priority round-robin, so a
+// high-frequency endpoint can't take every freed worker while others wait.
+// The pool can be resized while in use.
+type workerPool struct {
+	mu       sync.Mutex
+	size     int
+	idle     []int                 // IDs of idle workers
+	busy     map[int]bool          // IDs handed out
+	queues   map[string][]chan int // Waiting requests per endpoint, oldest first
+	priority map[string]int        // Priority of endpoints with waiting requests
+	order    []string              // Endpoints with waiting requests, in serving order
+	waiting  int                   // Total waiting requests
+}
+
+// newWorkerPool returns a pool holding worker IDs 1..size
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{
+		busy:     make(map[int]bool),
+		queues:   make(map[string][]chan int),
+		priority: make(map[string]int),
+	}
+	p.resize(size)
+	return p
+}
+
+// acquire waits for a worker for a request of an endpoint with a priority,
+// returning its ID, or false if ctx is done first
+func (p *workerPool) acquire(ctx context.Context, endpoint string, priority int) (int, bool) {
+	p.mu.Lock()
+	if len(p.idle) > 0 && p.waiting == 0 {
+		id := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.busy[id] = true
+		p.mu.Unlock()
+		return id, true
+	}
+
+	ready := make(chan int, 1)
+	if len(p.queues[endpoint]) == 0 {
+		p.order = append(p.order, endpoint)
+	}
+	p.priority[endpoint] = priority
+	p.queues[endpoint] = append(p.queues[endpoint], ready)
+	p.waiting++
+	p.mu.Unlock()
+
+	select {
+	case id := <-ready:
+		return id, true
+	case <-ctx.Done():
+		p.mu.Lock()
+		removed := p.removeWaiter(endpoint, ready)
+		p.mu.Unlock()
+		if !removed {
+			// A worker was handed over while cancelling; pass it on
+			p.release(<-ready)
+		}
+		return 0, false
+	}
+}
+
+// release returns a worker, handing it to the next endpoint in turn with a
+// waiting request. Workers beyond the pool size are retired.
+func (p *workerPool) release(id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id > p.size {
+		delete(p.busy, id)
+		return
+	}
+	p.handOver(id)
+}
+
+// resize changes the number of workers. Added workers serve waiting requests
+// right away; removed workers that are busy finish their request first.
+func (p *workerPool) resize(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if size == p.size {
+		return
+	}
+	if size < p.size {
+		idle := p.idle[:0]
+		for _, id := range p.idle {
+			if id <= size {
+				idle = append(idle, id)
+			}
+		}
+		p.idle = idle
+		p.size = size
+		return
+	}
+
+	oldSize := p.size
+	p.size = size
+	for id := oldSize + 1; id <= size; id++ {
+		// Still busy from before a shrink: it comes back on release
+		if !p.busy[id] {
+			p.handOver(id)
+		}
+	}
+}
+
+// handOver gives a worker to the next waiting request, or marks it idle
+// (caller holds lock)
+func (p *workerPool) handOver(id int) {
+	if len(p.order) == 0 {
+		delete(p.busy, id)
+		p.idle = append(p.idle, id)
+		return
+	}
+	p.busy[id] = true
+
+	// The first endpoint in line with the highest priority
+	next := 0
+	for i, name := range p.order {
+		if p.priority[name] > p.priority[p.order[next]] {
+			next = i
+		}
+	}
+	endpoint := p.order[next]
+	queue := p.queues[endpoint]
+	ready := queue[0]
+	p.waiting--
+	p.order = append(p.order[:next], p.order[next+1:]...)
+	if len(queue) == 1 {
+		delete(p.queues, endpoint)
+		delete(p.priority, endpoint)
+	} else {
+		p.queues[endpoint] = queue[1:]
+		// Move the endpoint to the back of the line
+		p.order = append(p.order, endpoint)
+	}
+	ready <- id
+}
+
+// removeWaiter drops a waiting request, returning false if it was already
+// served (caller holds lock)
+func (p *workerPool) removeWaiter(endpoint string, ready chan int) bool {
+	queue := p.queues[endpoint]
+	for i, waiter := range queue {
+		if waiter != ready {
+			continue
+		}
+		p.waiting--
+		if len(queue) == 1 {
+			delete(p.queues, endpoint)
+			delete(p.priority, endpoint)
+			for j, name := range p.order {
+				if name == endpoint {
+					p.order = append(p.order[:j], p.order[j+1:]...)
+					break
+				}
+			}
+		} else {
+			p.queues[endpoint] = append(queue[:i], queue[i+1:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// workers returns the pool size
+func (p *workerPool) workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// waitingRequests returns the number of requests waiting for a worker
+func (p *workerPool) waitingRequests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.waiting
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_RoundRobin(t *testing.T) {
+	pool := newWorkerPool(1)
+	ctx := context.Background()
+
+	id, ok := pool.acquire(ctx, "hot", 0)
+	if !ok || id != 1 {
+		t.Fatalf("acquire = %d, %v", id, ok)
+	}
+
+	served := make(chan string, 4)
+	for i, endpoint := range []string{"hot", "hot", "hot", "cold"} {
+		go func() {
+			id, _ := pool.acquire(ctx, endpoint, 0)
+			served <- endpoint
+			pool.release(id)
+		}()
+		waitFor(t, func() bool { return pool.waitingRequests() == i+1 })
+	}
+
+	// The cold endpoint is served second although three hot requests queued first
+	pool.release(id)
+	want := []string{"hot", "cold", "hot", "hot"}
+	for i, endpoint := range want {
+		if got := <-served; got != endpoint {
+			t.Fatalf("request %d: got %s, want %s", i, got, endpoint)
+		}
+	}
+}
+
+func TestWorkerPool_Priority(t *testing.T) {
+	pool := newWorkerPool(1)
+	ctx := context.Background()
+
+	id, _ := pool.acquire(ctx, "bulk", 0)
+
+	served := make(chan string, 4)
+	waiters := []struct {
+		endpoint string
+		priority int
+	}{{"bulk", 0}, {"report", -1}, {"checkout", 10}, {"bulk", 0}}
+	for i, w := range waiters {
+		go func() {
+			id, _ := pool.acquire(ctx, w.endpoint, w.priority)
+			served <- w.endpoint
+			pool.release(id)
+		}()
+		waitFor(t, func() bool { return pool.waitingRequests() == i+1 })
+	}
+
+	// Checkout queued last but is served first, the low priority report last
+	pool.release(id)
+	want := []string{"checkout", "bulk", "bulk", "report"}
+	for i, endpoint := range want {
+		if got := <-served; got != endpoint {
+			t.Fatalf("request %d: got %s, want %s", i, got, endpoint)
+		}
+	}
+}
+
+func TestWorkerPool_CancelWhileWaiting(t *testing.T) {
+	pool := newWorkerPool(1)
+	id, _ := pool.acquire(context.Background(), "a", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() {
+		_, ok := pool.acquire(ctx, "b", 0)
+		done <- ok
+	}()
+	waitFor(t, func() bool { return pool.waitingRequests() == 1 })
+	cancel()
+	if <-done {
+		t.Fatal("acquire should fail once cancelled")
+	}
+	if pool.waitingRequests() != 0 {
+		t.Errorf("cancelled request still waiting")
+	}
+
+	pool.release(id)
+	if id, ok := pool.acquire(context.Background(), "c", 0); !ok || id != 1 {
+		t.Errorf("worker was not returned: got %d, %v", id, ok)
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWorkerPool_Resize(t *testing.T) {
+	pool := newWorkerPool(2)
+	ctx := context.Background()
+	first, _ := pool.acquire(ctx, "a", 0)
+	second, _ := pool.acquire(ctx, "a", 0)
+
+	// Growing serves a waiting request right away
+	served := make(chan int, 1)
+	go func() {
+		id, _ := pool.acquire(ctx, "a", 0)
+		served <- id
+	}()
+	waitFor(t, func() bool { return pool.waitingRequests() == 1 })
+	pool.resize(3)
+	if id := <-served; id != 3 {
+		t.Errorf("got worker %d, want 3", id)
+	}
+
+	// Shrinking retires busy workers beyond the size when they are released
+	pool.resize(1)
+	pool.release(3)
+	pool.release(second)
+	pool.release(first)
+	if pool.workers() != 1 || len(pool.idle) != 1 || len(pool.busy) != 0 {
+		t.Errorf("after shrink: %d workers, idle %v, busy %v", pool.workers(), pool.idle, pool.busy)
+	}
+
+	// A worker still busy from before a shrink isn't handed out twice
+	pool.resize(2)
+	first, _ = pool.acquire(ctx, "a", 0)
+	second, _ = pool.acquire(ctx, "a", 0)
+	pool.resize(1)
+	pool.resize(2)
+	if len(pool.idle) != 0 {
+		t.Errorf("busy worker was made idle: %v", pool.idle)
+	}
+	pool.release(first)
+	pool.release(second)
+	if len(pool.idle) != 2 || pool.idle[0] == pool.idle[1] {
+		t.Errorf("after regrow: idle %v, want workers 1 and 2", pool.idle)
+	}
+}
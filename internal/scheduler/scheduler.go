@@ -4,6 +4,7 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,14 +25,29 @@ type Scheduler struct {
 	nextRequestTime map[string]time.Time
 	mu              sync.RWMutex
 
-	semaphore chan struct{} // Limits concurrency
-	stopChan  chan struct{}
-	wg        sync.WaitGroup
+	semaphore   *workerPool // Limits concurrency, serving endpoints round-robin
+	stopChan    chan struct{}
+	restartChan chan struct{} // Signals Run to start the loop again after Stop
+	wg          sync.WaitGroup
 
 	// Statistics
 	requestsScheduled int64
 	requestsInFlight  int64
+	maxInFlight       int64 // Peak of requestsInFlight
 	requestsSkipped   int64 // Skipped due to disabled state
+	requestsCapped    int64 // Not sent because the endpoint was at max_in_flight
+	requestsStarved   int64 // Waited longer than StarvationWait for a worker
+
+	inFlight map[string]int          // Queued and running requests per endpoint (guarded by mu)
+	starved  map[string]int64        // Starved requests per endpoint (guarded by mu)
+	lag      map[string]*endpointLag // Schedule lag and queue wait per endpoint (guarded by mu)
+	lastTick time.Time               // Previous tick that scheduled requests (zero after a pause)
+	drain    *DrainStatus            // Latest drain, nil if none (guarded by mu)
+	loops    map[string]*userLoop    // Virtual users of closed-loop endpoints (guarded by mu)
+	stages   stageClock              // Progress of staged ramps (guarded by mu)
+
+	hostInFlight map[string]int   // Queued and running requests per hostname (guarded by mu)
+	hostCapped   map[string]int64 // Requests not sent because of host_limits (guarded by mu)
 
 	// State
 	running   bool
@@ -41,21 +57,46 @@ type Scheduler struct {
 	// 0 = running (enabled), 1 = paused (disabled)
 	paused int32
 
+	// 1 while a drain lets requests queued before it run despite the pause
+	draining int32
+
+	// Adaptive multiplier control (capacity finding)
+	adaptive *adaptiveController
+
+	// Dead-man switch pausing scheduling without heartbeats
+	heartbeat heartbeatWatch
+
+	// Rate reduction keeping the generator within its resource limits
+	throttle *resourceThrottle
+
 	// Context for cancelling in-flight requests on emergency stop
 	baseCtx    context.Context
 	cancelFunc context.CancelFunc
 	ctx        context.Context
 }
 
+// StarvationWait is how long a request may wait for a worker before it is
+// counted as starved
+const StarvationWait = time.Second
+
 // SchedulerStats holds scheduler statistics
 type SchedulerStats struct {
 	RequestsScheduled int64
 	RequestsInFlight  int64
+	MaxInFlight       int64 // Peak of RequestsInFlight since the scheduler was created
 	RequestsSkipped   int64
+	RequestsCapped    int64            // Not sent because the endpoint was at max_in_flight or its hostname at host_limits
+	RequestsWaiting   int              // Waiting for a worker
+	Workers           int              // Current concurrency limit
+	RequestsStarved   int64            // Waited longer than StarvationWait for a worker
+	StarvedEndpoints  map[string]int64 // Starved requests per endpoint
 	ActiveEndpoints   int
 	EnabledEndpoints  int
 	Paused            bool
 	GlobalEnabled     bool
+	WindowPaused      []string                // Enabled endpoints currently inside a pause window
+	VirtualUsers      map[string]int          // Active virtual users per closed-loop endpoint
+	Hosts             map[string]HostInFlight // In-flight requests per hostname
 }
 
 // New creates a new scheduler with config manager
@@ -67,9 +108,18 @@ func New(configManager *config.Manager, httpClient *client.Client, handler Resul
 		client:          httpClient,
 		resultHandler:   handler,
 		nextRequestTime: make(map[string]time.Time),
-		semaphore:       make(chan struct{}, cfg.ConcurrentRequests),
+		inFlight:        make(map[string]int),
+		starved:         make(map[string]int64),
+		lag:             make(map[string]*endpointLag),
+		loops:           make(map[string]*userLoop),
+		hostInFlight:    make(map[string]int),
+		hostCapped:      make(map[string]int64),
+		semaphore:       newWorkerPool(cfg.ConcurrentRequests),
 		stopChan:        make(chan struct{}),
+		restartChan:     make(chan struct{}, 1),
 		paused:          0, // Start in running state
+		adaptive:        newAdaptiveController(configManager),
+		throttle:        newResourceThrottle(configManager),
 	}
 
 	// Initialize next request times (all start now)
@@ -100,6 +150,31 @@ func NewWithConfig(cfg *config.Config, httpClient *client.Client, handler Result
 	return New(manager, httpClient, handler)
 }
 
+// Run runs the scheduling loop until ctx is cancelled. In between, the loop
+// can be stopped with Stop and started again with Restart. When idle, the
+// loop only starts once Restart is called.
+func (s *Scheduler) Run(ctx context.Context, idle bool) error {
+	for {
+		if idle {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.restartChan:
+				s.resetSchedule()
+			}
+		}
+		idle = true
+
+		err := s.Start(ctx)
+		if ctx.Err() != nil {
+			return err
+		}
+		if err != nil {
+			fmt.Printf("[scheduler] %v\n", err)
+		}
+	}
+}
+
 // Start begins the load test scheduling loop
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.runningMu.Lock()
@@ -108,12 +183,22 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		return fmt.Errorf("scheduler already running")
 	}
 	s.running = true
+	s.stopChan = make(chan struct{})
+	stop := s.stopChan
 
 	// Create cancellable context for emergency stop
 	s.baseCtx = ctx
 	s.ctx, s.cancelFunc = context.WithCancel(ctx)
 	s.runningMu.Unlock()
 
+	loopCtx, loopCancel := context.WithCancel(ctx)
+	defer loopCancel()
+	go s.adaptive.run(loopCtx, func() bool {
+		return s.IsPaused() || !s.configManager.IsEnabled()
+	})
+	go s.watchHeartbeat(loopCtx)
+	go s.throttle.run(loopCtx)
+
 	ticker := time.NewTicker(10 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -121,7 +206,7 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return s.shutdown()
-		case <-s.stopChan:
+		case <-stop:
 			return s.shutdown()
 		case <-ticker.C:
 			s.tick()
@@ -129,20 +214,76 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	}
 }
 
+// Restart starts the loop after Stop, or for the first time when Run was
+// started idle, scheduling every endpoint from scratch with the current
+// config. It requires the scheduler to be driven by Run.
+func (s *Scheduler) Restart() error {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("scheduler already running")
+	}
+	select {
+	case s.restartChan <- struct{}{}:
+		return nil
+	default:
+		return fmt.Errorf("scheduler restart already pending")
+	}
+}
+
+// resetSchedule forgets per-endpoint schedule state, so all endpoints are due
+// right away when the loop starts again
+func (s *Scheduler) resetSchedule() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextRequestTime = make(map[string]time.Time)
+	s.lag = make(map[string]*endpointLag)
+	s.starved = make(map[string]int64)
+	s.hostCapped = make(map[string]int64)
+	s.loops = make(map[string]*userLoop)
+	s.stages = stageClock{}
+	s.lastTick = time.Time{}
+}
+
 // tick checks all endpoints and spawns requests for those that are due
 func (s *Scheduler) tick() {
 	// Check global pause state first (atomic - very fast)
 	if s.IsPaused() {
+		s.lastTick = time.Time{}
 		return
 	}
 
 	// Check if globally enabled via config manager
 	if !s.configManager.IsEnabled() {
+		s.lastTick = time.Time{}
 		return
 	}
 
 	now := time.Now()
+	prevTick := s.lastTick
+	s.lastTick = now
+	if !prevTick.IsZero() {
+		s.advanceStages(now.Sub(prevTick))
+	}
 	cfg := s.configManager.GetConfig()
+	freqs := cfg.EffectiveFrequencies()
+	// Open-loop rates shrink while the generator exceeds its resource limits
+	throttle := s.throttle.factor()
+
+	// Apply concurrency changes made since the last tick
+	if cfg.ConcurrentRequests > 0 {
+		s.semaphore.resize(cfg.ConcurrentRequests)
+	}
+
+	closedLoop := make(map[string]bool)
+	var users []*config.Endpoint
+	defer func() {
+		if len(s.loops) > len(closedLoop) {
+			s.retireUsers(closedLoop)
+		}
+	}()
 
 	for i := range cfg.Endpoints {
 		endpoint := &cfg.Endpoints[i]
@@ -152,6 +293,30 @@ func (s *Scheduler) tick() {
 			continue
 		}
 
+		// Skip endpoints inside a scheduled pause window
+		if endpoint.IsPausedAt(now) {
+			continue
+		}
+
+		// Closed-loop endpoints are paced by their virtual users
+		if endpoint.IsClosedLoop() {
+			closedLoop[endpoint.Name] = true
+			users = append(users, endpoint)
+			continue
+		}
+
+		// Grouped endpoints run at their share of the group budget
+		if endpoint.Group != "" {
+			endpoint.FrequencyPerMin = freqs[endpoint.Name]
+			if endpoint.FrequencyPerMin <= 0 {
+				// Start fresh once the group has budget again
+				s.mu.Lock()
+				delete(s.nextRequestTime, endpoint.Name)
+				s.mu.Unlock()
+				continue
+			}
+		}
+
 		s.mu.RLock()
 		nextTime, exists := s.nextRequestTime[endpoint.Name]
 		s.mu.RUnlock()
@@ -166,46 +331,88 @@ func (s *Scheduler) tick() {
 
 		if now.After(nextTime) || now.Equal(nextTime) {
 			// Calculate next request time BEFORE spawning to avoid drift
-			interval := s.calculateInterval(endpoint.FrequencyPerMin, cfg.GlobalMultiplier)
+			interval := s.nextInterval(endpoint, cfg.Multiplier(endpoint)*throttle)
 
 			s.mu.Lock()
 			s.nextRequestTime[endpoint.Name] = now.Add(interval)
+			s.recordSchedule(endpoint.Name, scheduleLag(now, nextTime, prevTick), interval)
+			host := endpoint.GetHostname()
+			capped := (endpoint.MaxInFlight > 0 && s.inFlight[endpoint.Name] >= endpoint.MaxInFlight) ||
+				!s.hostAvailable(host, cfg.HostLimits.Limit(host))
+			if !capped {
+				s.inFlight[endpoint.Name]++
+				s.hostStarted(host, 1)
+			}
 			s.mu.Unlock()
 
+			if capped {
+				atomic.AddInt64(&s.requestsCapped, 1)
+				continue
+			}
+
 			// Spawn goroutine for request (non-blocking)
 			s.wg.Add(1)
 			atomic.AddInt64(&s.requestsScheduled, 1)
 
 			// Make a copy of endpoint for the goroutine
 			epCopy := *endpoint
-			go s.executeRequest(&epCopy)
+			go s.executeRequest(&epCopy, nil)
+		}
+	}
+
+	// Closed-loop endpoints share the global stages, so their targets are
+	// computed together
+	if len(users) > 0 {
+		targets := s.userTargets(cfg, users)
+		for _, endpoint := range users {
+			s.tickUsers(endpoint, targets[endpoint.Name], now)
 		}
 	}
 }
 
-// executeRequest executes a single HTTP request
-func (s *Scheduler) executeRequest(endpoint *config.Endpoint) {
+// executeRequest executes a single HTTP request. loop is the closed-loop
+// endpoint's users the request was sent for, nil for frequency scheduling.
+func (s *Scheduler) executeRequest(endpoint *config.Endpoint, loop *userLoop) {
 	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		if s.inFlight[endpoint.Name]--; s.inFlight[endpoint.Name] <= 0 {
+			delete(s.inFlight, endpoint.Name)
+		}
+		s.hostDone(endpoint.GetHostname())
+		if loop != nil {
+			s.userDone(endpoint, loop)
+		}
+		s.mu.Unlock()
+	}()
 
 	// Check pause state before acquiring semaphore
-	if s.IsPaused() || !s.configManager.IsEnabled() {
+	if s.skipQueued() {
 		atomic.AddInt64(&s.requestsSkipped, 1)
 		return
 	}
 
-	// Acquire semaphore (blocks if at capacity)
-	select {
-	case s.semaphore <- struct{}{}:
-		// Acquired
-	case <-s.ctx.Done():
+	// Acquire a worker (blocks if at capacity)
+	waitStart := time.Now()
+	workerID, ok := s.semaphore.acquire(s.ctx, endpoint.Name, endpoint.Priority)
+	if !ok {
 		// Context cancelled while waiting (emergency stop)
 		atomic.AddInt64(&s.requestsSkipped, 1)
 		return
 	}
-	defer func() { <-s.semaphore }()
+	defer s.semaphore.release(workerID)
+
+	wait := time.Since(waitStart)
+	s.mu.Lock()
+	s.recordQueueWait(endpoint.Name, wait)
+	if wait > StarvationWait {
+		atomic.AddInt64(&s.requestsStarved, 1)
+		s.starved[endpoint.Name]++
+	}
+	s.mu.Unlock()
 
 	// Double-check pause state after acquiring semaphore
-	if s.IsPaused() || !s.configManager.IsEnabled() {
+	if s.skipQueued() {
 		atomic.AddInt64(&s.requestsSkipped, 1)
 		return
 	}
@@ -217,7 +424,7 @@ func (s *Scheduler) executeRequest(endpoint *config.Endpoint) {
 		return
 	}
 
-	atomic.AddInt64(&s.requestsInFlight, 1)
+	s.recordInFlight(atomic.AddInt64(&s.requestsInFlight, 1))
 	defer atomic.AddInt64(&s.requestsInFlight, -1)
 
 	// Create timeout context for this specific request
@@ -225,21 +432,39 @@ func (s *Scheduler) executeRequest(endpoint *config.Endpoint) {
 	defer cancel()
 
 	// Execute the request
-	result := s.client.Execute(reqCtx, endpoint)
+	result := s.client.Execute(client.WithWorkerID(reqCtx, workerID), endpoint)
 	if result != nil && result.ErrorType == "cancelled" && !s.IsPaused() && s.configManager.IsEnabled() {
 		result.ErrorType = "timeout"
 		result.Error = "Request timeout"
 	}
 
+	if result != nil {
+		s.adaptive.observe(result)
+	}
+
 	// Report result (non-blocking)
 	if s.resultHandler != nil {
 		s.resultHandler(result)
 	}
 }
 
+// scheduleLag returns how late a request due at nextTime is started at now.
+// Requests that were due before the previous tick (endpoint disabled or in a
+// pause window) count from that tick, and nothing counts as late right after
+// the scheduler was paused.
+func scheduleLag(now, nextTime, prevTick time.Time) time.Duration {
+	if prevTick.IsZero() {
+		return 0
+	}
+	if prevTick.After(nextTime) {
+		nextTime = prevTick
+	}
+	return now.Sub(nextTime)
+}
+
 // calculateInterval calculates the time between requests for an endpoint
-func (s *Scheduler) calculateInterval(freqPerMin float64, globalMultiplier float64) time.Duration {
-	adjustedFreq := freqPerMin * globalMultiplier
+func (s *Scheduler) calculateInterval(freqPerMin float64, multiplier float64) time.Duration {
+	adjustedFreq := freqPerMin * multiplier
 	if adjustedFreq <= 0 {
 		return 24 * time.Hour // Very long interval for disabled endpoints
 	}
@@ -247,16 +472,53 @@ func (s *Scheduler) calculateInterval(freqPerMin float64, globalMultiplier float
 	return time.Duration(secondsBetween * float64(time.Second))
 }
 
-// Stop signals the scheduler to stop gracefully
-func (s *Scheduler) Stop() {
+// recordInFlight raises the in-flight peak to the current count
+func (s *Scheduler) recordInFlight(current int64) {
+	for {
+		peak := atomic.LoadInt64(&s.maxInFlight)
+		if current <= peak || atomic.CompareAndSwapInt64(&s.maxInFlight, peak, current) {
+			return
+		}
+	}
+}
+
+// nextInterval calculates the time until an endpoint's next request, applying
+// its arrival mode and jitter to the base interval. The multiplier is the
+// global one times that of the endpoint's group.
+func (s *Scheduler) nextInterval(endpoint *config.Endpoint, multiplier float64) time.Duration {
+	interval := s.calculateInterval(endpoint.FrequencyPerMin, multiplier)
+	if endpoint.FrequencyPerMin*multiplier <= 0 {
+		return interval
+	}
+
+	switch {
+	case endpoint.Arrival == config.ArrivalPoisson:
+		// Exponential inter-arrival times with the same mean give a Poisson process
+		return time.Duration(float64(interval) * rand.ExpFloat64())
+	case endpoint.Jitter > 0:
+		factor := 1 + (rand.Float64()*2-1)*endpoint.Jitter/100
+		return time.Duration(float64(interval) * factor)
+	}
+	return interval
+}
+
+// Stop signals the scheduling loop to stop; in-flight requests are cancelled
+// and waited for. It returns false if the loop is not running.
+func (s *Scheduler) Stop() bool {
 	s.runningMu.Lock()
 	defer s.runningMu.Unlock()
 
 	if !s.running {
-		return
+		return false
 	}
 
-	close(s.stopChan)
+	select {
+	case <-s.stopChan:
+		// Already stopping
+	default:
+		close(s.stopChan)
+	}
+	return true
 }
 
 // EmergencyStop immediately stops all scheduling and cancels in-flight requests
@@ -264,6 +526,7 @@ func (s *Scheduler) Stop() {
 func (s *Scheduler) EmergencyStop() {
 	// Set pause state immediately (atomic)
 	atomic.StoreInt32(&s.paused, 1)
+	atomic.StoreInt32(&s.draining, 0)
 
 	// Cancel the context to abort all in-flight requests
 	s.runningMu.Lock()
@@ -282,11 +545,16 @@ func (s *Scheduler) Pause() {
 	s.configManager.SetEnabled(false)
 }
 
-// Resume resumes scheduling after a pause
+// Resume resumes scheduling after a pause. Resuming counts as a heartbeat,
+// so a scheduler paused by the dead-man switch isn't paused again right away.
 func (s *Scheduler) Resume() {
+	s.heartbeat.beat(time.Now())
+
 	s.runningMu.Lock()
 	if s.ctx == nil || s.ctx.Err() != nil {
-		fmt.Printf("[scheduler] recreating request context (err=%v)\n", s.ctx.Err())
+		if s.ctx != nil {
+			fmt.Printf("[scheduler] recreating request context (err=%v)\n", s.ctx.Err())
+		}
 		parent := s.baseCtx
 		if parent == nil {
 			parent = context.Background()
@@ -297,6 +565,17 @@ func (s *Scheduler) Resume() {
 
 	s.configManager.SetEnabled(true)
 	atomic.StoreInt32(&s.paused, 0)
+	atomic.StoreInt32(&s.draining, 0)
+}
+
+// skipQueued reports whether a queued request is dropped instead of sent:
+// scheduling is paused or disabled, and no drain is letting queued requests
+// finish
+func (s *Scheduler) skipQueued() bool {
+	if atomic.LoadInt32(&s.draining) == 1 {
+		return false
+	}
+	return s.IsPaused() || !s.configManager.IsEnabled()
 }
 
 // IsPaused returns true if the scheduler is paused
@@ -333,24 +612,54 @@ func (s *Scheduler) GetStats() SchedulerStats {
 	cfg := s.configManager.GetConfig()
 
 	// Count enabled endpoints
+	now := time.Now()
 	enabledCount := 0
+	windowPaused := []string{}
+	var hosts []string
 	for _, ep := range cfg.Endpoints {
 		if ep.Enabled {
 			enabledCount++
+			hosts = append(hosts, ep.GetHostname())
+			if ep.IsPausedAt(now) {
+				windowPaused = append(windowPaused, ep.Name)
+			}
 		}
 	}
 
+	s.mu.RLock()
+	starved := make(map[string]int64, len(s.starved))
+	for name, count := range s.starved {
+		starved[name] = count
+	}
+	users := s.activeUsers()
+	hostStats := s.hostStats(cfg.HostLimits, hosts)
+	s.mu.RUnlock()
+
 	return SchedulerStats{
 		RequestsScheduled: atomic.LoadInt64(&s.requestsScheduled),
 		RequestsInFlight:  atomic.LoadInt64(&s.requestsInFlight),
+		MaxInFlight:       atomic.LoadInt64(&s.maxInFlight),
 		RequestsSkipped:   atomic.LoadInt64(&s.requestsSkipped),
+		RequestsCapped:    atomic.LoadInt64(&s.requestsCapped),
+		RequestsWaiting:   s.semaphore.waitingRequests(),
+		Workers:           s.semaphore.workers(),
+		RequestsStarved:   atomic.LoadInt64(&s.requestsStarved),
+		StarvedEndpoints:  starved,
 		ActiveEndpoints:   len(cfg.Endpoints),
 		EnabledEndpoints:  enabledCount,
 		Paused:            s.IsPaused(),
 		GlobalEnabled:     s.configManager.IsEnabled(),
+		WindowPaused:      windowPaused,
+		VirtualUsers:      users,
+		Hosts:             hostStats,
 	}
 }
 
+// GetAdaptiveStatus returns the state of adaptive multiplier control
+func (s *Scheduler) GetAdaptiveStatus() AdaptiveStatus {
+	return s.adaptive.getStatus()
+}
+
 // IsRunning returns true if the scheduler is currently running
 func (s *Scheduler) IsRunning() bool {
 	s.runningMu.Lock()
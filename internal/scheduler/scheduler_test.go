@@ -0,0 +1,203 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+func TestNextInterval_JitterAndPoisson(t *testing.T) {
+	s := &Scheduler{}
+
+	fixed := &config.Endpoint{FrequencyPerMin: 60}
+	if got := s.nextInterval(fixed, 1.0); got != time.Second {
+		t.Errorf("expected fixed interval of 1s, got %s", got)
+	}
+
+	jittered := &config.Endpoint{FrequencyPerMin: 60, Jitter: 20}
+	for i := 0; i < 100; i++ {
+		got := s.nextInterval(jittered, 1.0)
+		if got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("expected jittered interval within ±20%%, got %s", got)
+		}
+	}
+
+	poisson := &config.Endpoint{FrequencyPerMin: 60, Arrival: config.ArrivalPoisson}
+	var total time.Duration
+	const samples = 20000
+	for i := 0; i < samples; i++ {
+		total += s.nextInterval(poisson, 1.0)
+	}
+	if mean := total / samples; mean < 900*time.Millisecond || mean > 1100*time.Millisecond {
+		t.Errorf("expected poisson mean interval near 1s, got %s", mean)
+	}
+}
+
+func TestScheduleLag(t *testing.T) {
+	now := time.Now()
+	if lag := scheduleLag(now, now.Add(-3*time.Second), now.Add(-10*time.Millisecond)); lag != 10*time.Millisecond {
+		t.Errorf("request due before the previous tick: got %s, want 10ms", lag)
+	}
+	if lag := scheduleLag(now, now.Add(-2*time.Second), now.Add(-5*time.Second)); lag != 2*time.Second {
+		t.Errorf("slow tick: got %s, want 2s", lag)
+	}
+	if lag := scheduleLag(now, now.Add(-time.Minute), time.Time{}); lag != 0 {
+		t.Errorf("first tick after a pause: got %s, want 0", lag)
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	s := &Scheduler{lag: make(map[string]*endpointLag), starved: map[string]int64{"a": 1}}
+	s.recordSchedule("a", 2500*time.Millisecond, time.Second)
+	s.recordSchedule("a", 500*time.Millisecond, time.Second)
+	s.recordQueueWait("a", 2*time.Second)
+	s.recordQueueWait("a", 0)
+
+	bp := s.GetBackpressure()
+	if len(bp) != 1 {
+		t.Fatalf("got %d endpoints, want 1", len(bp))
+	}
+	got := bp[0]
+	if got.Scheduled != 2 || got.MissedIntervals != 2 || got.AvgLagMs != 1500 || got.MaxLagMs != 2500 {
+		t.Errorf("schedule lag: got %+v", got)
+	}
+	if got.AvgQueueWaitMs != 1000 || got.MaxQueueWaitMs != 2000 || got.LastQueueWaitMs != 0 || got.Starved != 1 {
+		t.Errorf("queue wait: got %+v", got)
+	}
+}
+
+func TestRun_StopAndRestart(t *testing.T) {
+	s := New(config.NewManager(), nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx, false) }()
+
+	waitFor(t, s.IsRunning)
+	if err := s.Restart(); err == nil {
+		t.Error("Restart should fail while running")
+	}
+	if !s.Stop() {
+		t.Fatal("Stop should succeed while running")
+	}
+	waitFor(t, func() bool { return !s.IsRunning() })
+	if s.Stop() {
+		t.Error("Stop should fail once stopped")
+	}
+
+	if err := s.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, s.IsRunning)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run returned %v", err)
+	}
+}
+
+func TestRun_Idle(t *testing.T) {
+	s := New(config.NewManager(), nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx, true)
+
+	time.Sleep(50 * time.Millisecond)
+	if s.IsRunning() {
+		t.Fatal("idle scheduler should not run before Restart")
+	}
+	if err := s.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, s.IsRunning)
+}
+
+func TestDrain(t *testing.T) {
+	s := New(config.NewManager(), nil, nil)
+	if s.GetDrainStatus() != nil {
+		t.Fatal("no drain should be reported before Drain")
+	}
+
+	s.mu.Lock()
+	s.inFlight["a"] = 2
+	s.mu.Unlock()
+
+	drain := s.Drain()
+	if !s.IsPaused() || drain.State != DrainStateDraining || drain.PendingAtStart != 2 || drain.Pending != 2 {
+		t.Fatalf("after Drain: paused=%v, got %+v", s.IsPaused(), drain)
+	}
+
+	s.mu.Lock()
+	delete(s.inFlight, "a")
+	s.mu.Unlock()
+	waitFor(t, func() bool { return s.GetDrainStatus().State == DrainStateDrained })
+	if got := s.GetDrainStatus(); got.CompletedAt == nil || got.Pending != 0 || !s.IsPaused() {
+		t.Errorf("after drain: paused=%v, got %+v", s.IsPaused(), got)
+	}
+
+	// Resuming before requests finish aborts the drain
+	s.mu.Lock()
+	s.inFlight["a"] = 1
+	s.mu.Unlock()
+	s.Drain()
+	s.Resume()
+	waitFor(t, func() bool { return s.GetDrainStatus().State == DrainStateAborted })
+}
+
+func TestDrain_QueuedRequests(t *testing.T) {
+	var served atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		served.Add(1)
+	}))
+	defer server.Close()
+
+	cm := config.NewManager()
+	if err := cm.ReplaceConfig(&config.Config{
+		Enabled:            true,
+		ConcurrentRequests: 1,
+		Endpoints: []config.Endpoint{
+			{Name: "a", Method: "GET", URLTemplate: server.URL, FrequencyPerMin: 6000, Timeout: 5, Enabled: true, EnabledSet: true},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(cm, client.New(client.DefaultOptions()), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx, false)
+
+	// One request runs on the only worker, the others queue behind it
+	waitFor(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.inFlight["a"] >= 3
+	})
+	drain := s.Drain()
+	if drain.PendingAtStart < 3 {
+		t.Fatalf("expected queued requests pending, got %+v", drain)
+	}
+
+	close(release)
+	waitFor(t, func() bool { return s.GetDrainStatus().State == DrainStateDrained })
+	stats := s.GetStats()
+	if served.Load() != stats.RequestsScheduled || stats.RequestsSkipped != 0 {
+		t.Errorf("expected all %d scheduled requests sent, got %d sent and %d skipped",
+			stats.RequestsScheduled, served.Load(), stats.RequestsSkipped)
+	}
+	if int64(drain.PendingAtStart) > served.Load() {
+		t.Errorf("expected the %d pending requests sent, got %d", drain.PendingAtStart, served.Load())
+	}
+
+	// Once drained, the pause drops queued requests again
+	if atomic.LoadInt32(&s.draining) != 0 || !s.skipQueued() {
+		t.Error("expected the drain to end with the scheduler paused")
+	}
+}
@@ -0,0 +1,145 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/resources"
+)
+
+// Resource throttling steps: the rate is cut by throttleBackoff, down to
+// throttleMinFactor, while usage is above throttleTrigger of a limit, and
+// raised by throttleRecover once usage is below throttleRelease of every limit
+const (
+	throttleTrigger   = 0.9
+	throttleRelease   = 0.75
+	throttleBackoff   = 0.75
+	throttleRecover   = 1.25
+	throttleMinFactor = 0.05
+)
+
+// ThrottleStatus reports whether scheduling is throttled because the
+// generator is close to its own resource limits
+type ThrottleStatus struct {
+	Enabled       bool             `json:"enabled"`
+	SelfLimited   bool             `json:"self_limited"` // The rate is reduced to stay below the limits
+	Factor        float64          `json:"factor"`       // Applied to the rate of open-loop endpoints
+	Reasons       []string         `json:"reasons,omitempty"`
+	Usage         *resources.Usage `json:"usage,omitempty"`
+	LastCheckedAt string           `json:"last_checked_at,omitempty"`
+}
+
+// resourceThrottle reduces the scheduling rate while the process approaches
+// its resource limits and restores it as usage drops
+type resourceThrottle struct {
+	configManager *config.Manager
+	sampler       *resources.Sampler
+
+	status ThrottleStatus
+	mu     sync.Mutex
+}
+
+// newResourceThrottle creates a throttle that doesn't reduce the rate
+func newResourceThrottle(configManager *config.Manager) *resourceThrottle {
+	return &resourceThrottle{
+		configManager: configManager,
+		sampler:       resources.NewSampler(),
+		status:        ThrottleStatus{Factor: 1},
+	}
+}
+
+// run checks resource usage each interval until ctx is cancelled
+func (t *resourceThrottle) run(ctx context.Context) {
+	for {
+		limits := t.configManager.GetResourceLimitsConfig()
+		timer := time.NewTimer(limits.CheckInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			// Sample even while disabled, so CPU usage covers a whole interval
+			usage := t.sampler.Sample()
+			t.evaluate(t.configManager.GetResourceLimitsConfig(), usage, time.Now())
+		}
+	}
+}
+
+// evaluate adjusts the throttle factor to a resource usage sample
+func (t *resourceThrottle) evaluate(limits config.ResourceLimitsConfig, usage resources.Usage, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !limits.Enabled() {
+		t.status = ThrottleStatus{Factor: 1}
+		return
+	}
+
+	var reasons []string
+	released := true
+	check := func(name string, value, limit float64, unit string) {
+		if limit <= 0 {
+			return
+		}
+		if value > limit*throttleTrigger {
+			reasons = append(reasons, fmt.Sprintf("%s %.1f%s near limit of %.1f%s", name, value, unit, limit, unit))
+		}
+		if value > limit*throttleRelease {
+			released = false
+		}
+	}
+	check("cpu", usage.CPUPercent, limits.MaxCPUPercent, "%")
+	check("memory", usage.MemoryMB, limits.MaxMemoryMB, "MB")
+	if usage.MaxFDs > 0 {
+		check("open files", usage.FDPercent(), limits.MaxFDPercent, "%")
+	}
+
+	factor := t.status.Factor
+	switch {
+	case len(reasons) > 0:
+		factor = max(factor*throttleBackoff, throttleMinFactor)
+	case released:
+		factor = min(factor*throttleRecover, 1)
+	}
+	if factor < 1 && t.status.Factor == 1 {
+		fmt.Printf("[resources] Throttling scheduling: %s\n", reasons[0])
+	} else if factor == 1 && t.status.Factor < 1 {
+		fmt.Println("[resources] Resource usage back under limits, throttling lifted")
+	}
+
+	t.status = ThrottleStatus{
+		Enabled:       true,
+		SelfLimited:   factor < 1,
+		Factor:        factor,
+		Reasons:       reasons,
+		Usage:         &usage,
+		LastCheckedAt: now.Format(time.RFC3339),
+	}
+}
+
+// factor returns the factor applied to the scheduling rate
+func (t *resourceThrottle) factor() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status.Factor
+}
+
+// getStatus returns the throttle state
+func (t *resourceThrottle) getStatus() ThrottleStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.status
+	status.Reasons = append([]string(nil), t.status.Reasons...)
+	return status
+}
+
+// GetThrottleStatus returns whether scheduling is throttled to stay within the
+// generator's resource limits
+func (s *Scheduler) GetThrottleStatus() ThrottleStatus {
+	return s.throttle.getStatus()
+}
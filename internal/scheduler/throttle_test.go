@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"moxapp/internal/config"
+	"moxapp/internal/resources"
+)
+
+func TestResourceThrottle_BacksOffAndRecovers(t *testing.T) {
+	throttle := newResourceThrottle(config.NewManager())
+	limits := config.ResourceLimitsConfig{MaxCPUPercent: 80, MaxMemoryMB: 1000}
+	now := time.Now()
+
+	throttle.evaluate(limits, resources.Usage{CPUPercent: 40, MemoryMB: 100}, now)
+	if status := throttle.getStatus(); status.SelfLimited || status.Factor != 1 {
+		t.Fatalf("expected no throttling under the limits, got %+v", status)
+	}
+
+	// Approaching the memory limit cuts the rate
+	throttle.evaluate(limits, resources.Usage{CPUPercent: 40, MemoryMB: 950}, now)
+	status := throttle.getStatus()
+	if !status.SelfLimited || status.Factor != throttleBackoff || len(status.Reasons) != 1 {
+		t.Fatalf("expected throttling near the memory limit, got %+v", status)
+	}
+
+	// Between the release and trigger thresholds the rate holds
+	throttle.evaluate(limits, resources.Usage{CPUPercent: 70, MemoryMB: 100}, now)
+	if factor := throttle.factor(); factor != throttleBackoff {
+		t.Errorf("expected the factor to hold at %v, got %v", throttleBackoff, factor)
+	}
+
+	// Well under the limits the rate recovers step by step
+	throttle.evaluate(limits, resources.Usage{CPUPercent: 10, MemoryMB: 100}, now)
+	if status := throttle.getStatus(); !status.SelfLimited || status.Factor <= throttleBackoff {
+		t.Errorf("expected the rate to recover partially, got %+v", status)
+	}
+	throttle.evaluate(limits, resources.Usage{CPUPercent: 10, MemoryMB: 100}, now)
+	if status := throttle.getStatus(); status.SelfLimited || status.Factor != 1 {
+		t.Errorf("expected throttling lifted, got %+v", status)
+	}
+}
+
+func TestResourceThrottle_Floor(t *testing.T) {
+	throttle := newResourceThrottle(config.NewManager())
+	limits := config.ResourceLimitsConfig{MaxFDPercent: 50}
+	for i := 0; i < 50; i++ {
+		throttle.evaluate(limits, resources.Usage{OpenFDs: 90, MaxFDs: 100}, time.Now())
+	}
+	if factor := throttle.factor(); factor != throttleMinFactor {
+		t.Errorf("expected the factor to stop at %v, got %v", throttleMinFactor, factor)
+	}
+
+	// Without limits nothing is throttled
+	throttle.evaluate(config.ResourceLimitsConfig{}, resources.Usage{OpenFDs: 90, MaxFDs: 100}, time.Now())
+	if status := throttle.getStatus(); status.Enabled || status.Factor != 1 {
+		t.Errorf("expected no throttling without limits, got %+v", status)
+	}
+}
@@ -0,0 +1,228 @@
+// Package scheduler provides the request scheduling logic
+package scheduler
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// userLoop tracks the virtual users of a closed-loop endpoint. Each user
+// sends a request, waits for the response and its think time, then sends the
+// next one.
+type userLoop struct {
+	users   int         // Users started, in a request or thinking
+	wakeups []time.Time // When thinking users send their next request
+}
+
+// stageClock is the run time of staged ramps. It only advances while requests
+// are scheduled, so pausing the scheduler also pauses the ramps.
+type stageClock struct {
+	elapsed time.Duration
+	held    bool // Stay in the current stage until resumed
+}
+
+// StageStatus is the progress of the staged virtual user ramps
+type StageStatus struct {
+	ElapsedSec float64                         `json:"elapsed_sec"`
+	Held       bool                            `json:"held"`
+	Global     *config.StagePosition           `json:"global,omitempty"`    // Ramp shared by closed-loop endpoints without their own stages
+	Endpoints  map[string]config.StagePosition `json:"endpoints,omitempty"` // Ramps of endpoints with their own stages
+}
+
+// advanceStages moves the stage clock on by the time since the previous tick
+func (s *Scheduler) advanceStages(delta time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stages.held {
+		s.stages.elapsed += delta
+	}
+}
+
+// userTargets returns the number of virtual users each closed-loop endpoint
+// should run. Endpoints with their own stages follow them; the others share
+// the global stages in proportion to their virtual_users, or run their
+// virtual_users when there are no global stages.
+func (s *Scheduler) userTargets(cfg *config.Config, endpoints []*config.Endpoint) map[string]int {
+	s.mu.RLock()
+	elapsed := s.stages.elapsed
+	s.mu.RUnlock()
+
+	targets := make(map[string]int, len(endpoints))
+	var shared []string
+	weights := make(map[string]float64)
+	for _, endpoint := range endpoints {
+		switch {
+		case len(endpoint.Stages) > 0:
+			users := config.StageAt(endpoint.Stages, elapsed).VirtualUsers
+			targets[endpoint.Name] = config.ScaleUsers(users, cfg.GlobalMultiplier)
+		case len(cfg.Stages) > 0:
+			shared = append(shared, endpoint.Name)
+			weights[endpoint.Name] = float64(max(1, endpoint.VirtualUsers))
+		default:
+			targets[endpoint.Name] = config.ScaleUsers(endpoint.VirtualUsers, cfg.GlobalMultiplier)
+		}
+	}
+
+	if len(shared) > 0 {
+		total := config.ScaleUsers(config.StageAt(cfg.Stages, elapsed).VirtualUsers, cfg.GlobalMultiplier)
+		for name, users := range config.DistributeUsers(total, shared, weights) {
+			targets[name] = users
+		}
+	}
+	return targets
+}
+
+// tickUsers starts the requests of a closed-loop endpoint's users whose think
+// time is over, and starts or retires users to match the target count
+func (s *Scheduler) tickUsers(endpoint *config.Endpoint, target int, now time.Time) {
+	s.mu.Lock()
+	loop := s.loops[endpoint.Name]
+	if loop == nil {
+		loop = &userLoop{}
+		s.loops[endpoint.Name] = loop
+	}
+
+	due := 0
+	thinking := loop.wakeups[:0]
+	for _, wakeup := range loop.wakeups {
+		if wakeup.After(now) {
+			thinking = append(thinking, wakeup)
+		} else {
+			due++
+		}
+	}
+	loop.wakeups = thinking
+
+	// Retire users above the target, due ones first
+	for loop.users > target && due > 0 {
+		loop.users--
+		due--
+	}
+	for loop.users > target && len(loop.wakeups) > 0 {
+		loop.users--
+		loop.wakeups = loop.wakeups[:len(loop.wakeups)-1]
+	}
+	for loop.users < target {
+		loop.users++
+		due++
+	}
+	s.inFlight[endpoint.Name] += due
+	s.hostStarted(endpoint.GetHostname(), due)
+	s.mu.Unlock()
+
+	for i := 0; i < due; i++ {
+		s.wg.Add(1)
+		atomic.AddInt64(&s.requestsScheduled, 1)
+		epCopy := *endpoint
+		go s.executeRequest(&epCopy, loop)
+	}
+}
+
+// userDone puts a closed-loop endpoint's user to think after its request
+// (caller holds mu)
+func (s *Scheduler) userDone(endpoint *config.Endpoint, loop *userLoop) {
+	if s.loops[endpoint.Name] != loop {
+		return // Users were retired while the request ran
+	}
+	loop.wakeups = append(loop.wakeups, time.Now().Add(endpoint.ThinkTime()))
+}
+
+// retireUsers stops the users of closed-loop endpoints that aren't running,
+// such as disabled or deleted ones; requests already sent still complete
+func (s *Scheduler) retireUsers(running map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.loops {
+		if !running[name] {
+			delete(s.loops, name)
+		}
+	}
+}
+
+// activeUsers returns the number of virtual users per closed-loop endpoint
+// (caller holds mu)
+func (s *Scheduler) activeUsers() map[string]int {
+	users := make(map[string]int, len(s.loops))
+	for name, loop := range s.loops {
+		if loop.users > 0 {
+			users[name] = loop.users
+		}
+	}
+	return users
+}
+
+// GetStageStatus returns the progress of the staged virtual user ramps
+func (s *Scheduler) GetStageStatus() StageStatus {
+	cfg := s.configManager.GetConfig()
+
+	s.mu.RLock()
+	clock := s.stages
+	s.mu.RUnlock()
+
+	status := StageStatus{ElapsedSec: clock.elapsed.Seconds(), Held: clock.held}
+	if len(cfg.Stages) > 0 {
+		pos := config.StageAt(cfg.Stages, clock.elapsed)
+		status.Global = &pos
+	}
+	for _, endpoint := range cfg.Endpoints {
+		if len(endpoint.Stages) > 0 {
+			if status.Endpoints == nil {
+				status.Endpoints = make(map[string]config.StagePosition)
+			}
+			status.Endpoints[endpoint.Name] = config.StageAt(endpoint.Stages, clock.elapsed)
+		}
+	}
+	return status
+}
+
+// HoldStages keeps the ramps in their current stage until ResumeStages
+func (s *Scheduler) HoldStages() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stages.held = true
+}
+
+// ResumeStages lets held ramps continue
+func (s *Scheduler) ResumeStages() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stages.held = false
+}
+
+// RestartStages starts the ramps from their first stage
+func (s *Scheduler) RestartStages() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stages.elapsed = 0
+}
+
+// NextStage skips to the earliest upcoming stage of any ramp
+func (s *Scheduler) NextStage() error {
+	cfg := s.configManager.GetConfig()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ramps := [][]config.Stage{cfg.Stages}
+	for _, endpoint := range cfg.Endpoints {
+		ramps = append(ramps, endpoint.Stages)
+	}
+
+	var next time.Duration
+	found := false
+	for _, stages := range ramps {
+		if start, ok := config.NextStageStart(stages, s.stages.elapsed); ok && (!found || start < next) {
+			next, found = start, true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no upcoming stage")
+	}
+	s.stages.elapsed = next
+	return nil
+}
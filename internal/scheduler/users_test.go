@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+func TestClosedLoop_VirtualUsers(t *testing.T) {
+	var mu sync.Mutex
+	concurrent, maxConcurrent, total := 0, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		concurrent++
+		total++
+		maxConcurrent = max(maxConcurrent, concurrent)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		concurrent--
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	cm := config.NewManager()
+	if err := cm.AddEndpoint(config.Endpoint{
+		Name: "users", Method: "GET", URLTemplate: server.URL, Timeout: 5, Enabled: true, EnabledSet: true,
+		VirtualUsers: 3, ThinkTimeMs: 10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(cm, client.New(client.DefaultOptions()), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx, false) }()
+
+	time.Sleep(300 * time.Millisecond)
+	if users := s.GetStats().VirtualUsers["users"]; users != 3 {
+		t.Errorf("expected 3 virtual users, got %d", users)
+	}
+
+	// Lowering the multiplier retires users as they finish
+	cm.SetGlobalMultiplier(0.34)
+	time.Sleep(100 * time.Millisecond)
+	if users := s.GetStats().VirtualUsers["users"]; users != 1 {
+		t.Errorf("expected 1 virtual user at multiplier 0.34, got %d", users)
+	}
+
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxConcurrent != 3 {
+		t.Errorf("expected at most 3 concurrent requests, got %d", maxConcurrent)
+	}
+	// Each user loops about every 30ms; open-loop scheduling would send one request
+	if total < 15 {
+		t.Errorf("expected users to loop, got %d requests", total)
+	}
+}
@@ -0,0 +1,129 @@
+package sinks
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+// queueSize is how many encoded results a streaming sink buffers before it
+// drops new ones, so a slow broker never holds up requests
+const queueSize = 10000
+
+// closeTimeout bounds how long Close waits for the last batch to be sent
+const closeTimeout = 10 * time.Second
+
+// errorLogInterval limits how often a sink logs failed batches
+const errorLogInterval = 10 * time.Second
+
+// message is an encoded result, keyed by its endpoint name
+type message struct {
+	key  string
+	data []byte
+}
+
+// batcher encodes results and sends them in batches from a background goroutine
+type batcher struct {
+	name     string
+	queue    chan message
+	done     chan struct{}
+	send     func(batch []message) error
+	dropped  int64 // Results dropped because the queue was full
+	failed   int64 // Results lost in batches that failed to send
+	lastLog  time.Time
+	stopOnce sync.Once
+}
+
+// newBatcher starts a batcher that calls send with up to size results,
+// at least every interval while results are queued
+func newBatcher(name string, size int, interval time.Duration, send func(batch []message) error) *batcher {
+	b := &batcher{
+		name:  name,
+		queue: make(chan message, queueSize),
+		done:  make(chan struct{}),
+		send:  send,
+	}
+	go b.run(size, interval)
+	return b
+}
+
+// Record queues a result, dropping it if the queue is full
+func (b *batcher) Record(result *client.RequestResult) {
+	data, err := encodeResult(result)
+	if err != nil {
+		return
+	}
+	select {
+	case b.queue <- message{key: result.EndpointName, data: data}:
+	default:
+		atomic.AddInt64(&b.dropped, 1)
+	}
+}
+
+// Close sends the queued results and stops the batcher
+func (b *batcher) Close() error {
+	b.stopOnce.Do(func() { close(b.queue) })
+
+	select {
+	case <-b.done:
+	case <-time.After(closeTimeout):
+		log.Printf("sinks: %s: gave up waiting for the last batch", b.name)
+	}
+	if dropped, failed := atomic.LoadInt64(&b.dropped), atomic.LoadInt64(&b.failed); dropped > 0 || failed > 0 {
+		log.Printf("sinks: %s: %d results dropped (queue full), %d lost in failed batches", b.name, dropped, failed)
+	}
+	return nil
+}
+
+func (b *batcher) run(size int, interval time.Duration) {
+	defer close(b.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]message, 0, size)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.send(batch); err != nil {
+			atomic.AddInt64(&b.failed, int64(len(batch)))
+			if time.Since(b.lastLog) >= errorLogInterval {
+				b.lastLog = time.Now()
+				log.Printf("sinks: %s: failed to send %d results: %v", b.name, len(batch), err)
+			}
+		}
+		batch = make([]message, 0, size)
+	}
+
+	for {
+		select {
+		case msg, ok := <-b.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, msg)
+			if len(batch) >= size {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// encodeResult encodes a result as JSON, masking secrets in its URLs
+func encodeResult(result *client.RequestResult) ([]byte, error) {
+	redacted := *result
+	redacted.URL = config.RedactURL(result.URL)
+	if result.FinalURL != "" {
+		redacted.FinalURL = config.RedactURL(result.FinalURL)
+	}
+	return json.Marshal(&redacted)
+}
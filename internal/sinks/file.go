@@ -0,0 +1,230 @@
+package sinks
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// rotatedTimeFormat is the timestamp inserted into rotated file names
+const rotatedTimeFormat = "20060102T150405.000"
+
+// File writes results as NDJSON to a file, rotating it when it reaches a size
+// or an age. Rotated files are renamed with a timestamp, e.g.
+// results-20260102T150405.000.ndjson, and optionally gzipped.
+type File struct {
+	*batcher
+	path     string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	compress bool
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	rotated  time.Time      // Timestamp of the last rotated file
+	pending  sync.WaitGroup // Rotated files being compressed and pruned
+	rotateMu sync.Mutex     // Compresses and prunes one rotated file at a time
+}
+
+// NewFile creates a file sink, appending to the file if it exists
+func NewFile(cfg config.FileSinkConfig) (*File, error) {
+	f := &File{
+		path:     cfg.Path,
+		maxSize:  cfg.GetMaxSize(),
+		maxAge:   cfg.GetMaxAge(),
+		maxFiles: cfg.MaxFiles,
+		compress: cfg.Compress,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.batcher = newBatcher("file", config.DefaultSinkBatchSize, time.Second, f.write)
+	return f, nil
+}
+
+// open opens the file for appending
+func (f *File) open() error {
+	if dir := filepath.Dir(f.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size, f.openedAt = file, info.Size(), time.Now()
+	return nil
+}
+
+// write appends a batch of encoded results, rotating the file as needed
+func (f *File) write(batch []message) error {
+	if f.file == nil {
+		// A previous rotation failed to reopen the file
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+
+	var buf []byte
+	for _, msg := range batch {
+		written := f.size + int64(len(buf))
+		if written > 0 && (written+int64(len(msg.data)+1) > f.maxSize ||
+			(f.maxAge > 0 && time.Since(f.openedAt) >= f.maxAge)) {
+			if err := f.flush(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+			if err := f.rotate(); err != nil {
+				return err
+			}
+		}
+		buf = append(buf, msg.data...)
+		buf = append(buf, '\n')
+	}
+	return f.flush(buf)
+}
+
+// flush writes buffered lines to the file
+func (f *File) flush(buf []byte) error {
+	if len(buf) == 0 {
+		return nil
+	}
+	n, err := f.file.Write(buf)
+	f.size += int64(n)
+	return err
+}
+
+// rotate renames the current file and opens a new one
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		log.Printf("sinks: file: closing %s: %v", f.path, err)
+	}
+	f.file = nil
+
+	// Keep timestamps unique and increasing, even for rotations within a millisecond
+	stamp := time.Now().Truncate(time.Millisecond)
+	if !stamp.After(f.rotated) {
+		stamp = f.rotated.Add(time.Millisecond)
+	}
+	f.rotated = stamp
+
+	ext := filepath.Ext(f.path)
+	rotated := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), stamp.Format(rotatedTimeFormat), ext)
+	if err := os.Rename(f.path, rotated); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		f.rotateMu.Lock()
+		defer f.rotateMu.Unlock()
+
+		if f.compress {
+			if err := compressFile(rotated); err != nil {
+				log.Printf("sinks: file: compressing %s: %v", rotated, err)
+			}
+		}
+		f.prune()
+	}()
+	return nil
+}
+
+// prune deletes the oldest rotated files beyond maxFiles
+func (f *File) prune() {
+	if f.maxFiles <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext + "*")
+	if err != nil {
+		return
+	}
+
+	// A file left half-compressed by a crash has both names: count it once
+	names := make(map[string][]string)
+	for _, match := range matches {
+		base := strings.TrimSuffix(match, ".gz")
+		if strings.HasSuffix(base, ext) {
+			names[base] = append(names[base], match)
+		}
+	}
+	rotated := make([]string, 0, len(names))
+	for base := range names {
+		rotated = append(rotated, base)
+	}
+
+	// The timestamp makes names sort oldest first
+	sort.Strings(rotated)
+	for _, base := range rotated[:max(len(rotated)-f.maxFiles, 0)] {
+		for _, name := range names[base] {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				log.Printf("sinks: file: removing %s: %v", name, err)
+			}
+		}
+	}
+}
+
+// compressFile gzips a file, replacing it with path.gz
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Close writes the queued results, closes the file and waits for rotated
+// files to be compressed
+func (f *File) Close() error {
+	err := f.batcher.Close()
+	f.pending.Wait()
+	if f.file != nil {
+		if closeErr := f.file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		f.file = nil
+	}
+	return err
+}
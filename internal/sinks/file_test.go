@@ -0,0 +1,95 @@
+package sinks
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+func TestFile_WritesNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "results.ndjson")
+	f, err := NewFile(config.FileSinkConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Record(&client.RequestResult{EndpointName: "a", StatusCode: 200})
+	f.Record(&client.RequestResult{EndpointName: "b", StatusCode: 500})
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var result client.RequestResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		names = append(names, result.EndpointName)
+	}
+	if strings.Join(names, ",") != "a,b" {
+		t.Errorf("expected one line per result in order, got %v", names)
+	}
+}
+
+func TestFile_RotatesCompressesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "results.ndjson")
+	f, err := NewFile(config.FileSinkConfig{Path: path, MaxFiles: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line, _ := encodeResult(&client.RequestResult{EndpointName: "ep"})
+	f.maxSize = int64(len(line)+1) * 2 // Two results per file
+	for i := 0; i < 8; i++ {
+		f.Record(&client.RequestResult{EndpointName: "ep"})
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "results-*.ndjson.gz"))
+	if len(rotated) != 2 {
+		entries, _ := os.ReadDir(dir)
+		t.Fatalf("expected 2 compressed rotated files, got %d (%v)", len(rotated), entries)
+	}
+	if plain, _ := filepath.Glob(filepath.Join(dir, "results-*.ndjson")); len(plain) != 0 {
+		t.Errorf("expected no uncompressed rotated files, got %v", plain)
+	}
+
+	gzFile, err := os.Open(rotated[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gzFile.Close()
+	gz, err := gzip.NewReader(gzFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := 0
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 results in a rotated file, got %d", lines)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Size() != int64(len(line)+1)*2 {
+		t.Errorf("expected the last 2 results in the current file, got %v", err)
+	}
+}
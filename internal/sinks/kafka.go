@@ -0,0 +1,84 @@
+package sinks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// kafkaContentType is the REST Proxy v2 content type for JSON records
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// Kafka publishes results as JSON records to a Kafka topic through a Kafka
+// REST Proxy, keyed by endpoint name so each endpoint's results stay ordered
+type Kafka struct {
+	*batcher
+	endpoint   string // Topic URL on the proxy
+	headers    map[string]string
+	httpClient *http.Client
+}
+
+// kafkaRecord is a record in a REST Proxy produce request
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// NewKafka creates a Kafka sink
+func NewKafka(cfg config.KafkaSinkConfig) *Kafka {
+	size, intervalMs := cfg.Batching()
+	k := &Kafka{
+		endpoint:   strings.TrimRight(cfg.RestProxyURL, "/") + "/topics/" + url.PathEscape(cfg.Topic),
+		headers:    cfg.Headers,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	k.batcher = newBatcher("kafka", size, time.Duration(intervalMs)*time.Millisecond, k.send)
+	return k
+}
+
+// send produces a batch of encoded results in one request
+func (k *Kafka) send(batch []message) error {
+	records := make([]kafkaRecord, len(batch))
+	for i, msg := range batch {
+		records[i] = kafkaRecord{Key: msg.key, Value: msg.data}
+	}
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	for name, value := range k.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		// Leave out the URL, which may embed credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
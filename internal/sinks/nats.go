@@ -0,0 +1,199 @@
+package sinks
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"moxapp/internal/config"
+)
+
+// natsDefaultPort is used when the NATS URL has no port
+const natsDefaultPort = "4222"
+
+// natsTimeout bounds connecting and each batch write
+const natsTimeout = 10 * time.Second
+
+// NATS publishes results as JSON messages to a NATS subject. It speaks the
+// NATS client protocol directly and reconnects on the next batch after an
+// error.
+type NATS struct {
+	*batcher
+	cfg config.NATSSinkConfig
+
+	conn   net.Conn
+	writer *bufio.Writer
+	mu     sync.Mutex // Guards conn and writer, shared with the PING handler
+}
+
+// natsInfo is the part of the server's INFO message the sink needs
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// NewNATS creates a NATS sink. It connects when the first batch is sent.
+func NewNATS(cfg config.NATSSinkConfig) *NATS {
+	size, intervalMs := cfg.Batching()
+	n := &NATS{cfg: cfg}
+	n.batcher = newBatcher("nats", size, time.Duration(intervalMs)*time.Millisecond, n.send)
+	return n
+}
+
+// send publishes a batch of encoded results, connecting first if needed
+func (n *NATS) send(batch []message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.conn == nil {
+		if err := n.connect(); err != nil {
+			return err
+		}
+	}
+
+	n.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	for _, msg := range batch {
+		fmt.Fprintf(n.writer, "PUB %s %d\r\n", n.cfg.Subject, len(msg.data))
+		n.writer.Write(msg.data)
+		n.writer.WriteString("\r\n")
+	}
+	if err := n.writer.Flush(); err != nil {
+		n.disconnect()
+		return err
+	}
+	return nil
+}
+
+// connect opens a connection and performs the handshake. Called with mu held.
+func (n *NATS) connect() error {
+	u, err := url.Parse(n.cfg.URL)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", host, natsTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(natsTimeout))
+
+	// The server greets with INFO, then the client may upgrade to TLS
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("reading INFO: %w", err)
+	}
+	var info natsInfo
+	if !strings.HasPrefix(line, "INFO ") || json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info) != nil {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	if u.Scheme == "tls" || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return fmt.Errorf("TLS handshake: %w", err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	connectOpts := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "moxapp",
+		"lang":     "go",
+		"protocol": 0,
+	}
+	user, password, token := n.cfg.User, n.cfg.Password, n.cfg.Token
+	if u.User != nil {
+		user = u.User.Username()
+		if p, ok := u.User.Password(); ok {
+			password = p
+		} else if user != "" && password == "" {
+			token, user = user, ""
+		}
+	}
+	if user != "" {
+		connectOpts["user"] = user
+		connectOpts["pass"] = password
+	}
+	if token != "" {
+		connectOpts["auth_token"] = token
+	}
+	opts, _ := json.Marshal(connectOpts)
+
+	// PING after CONNECT: the server answers PONG, or -ERR if it refused us
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", opts); err != nil {
+		conn.Close()
+		return err
+	}
+	line, err = reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("reading handshake reply: %w", err)
+	}
+	if reply := strings.TrimSpace(line); reply != "PONG" {
+		conn.Close()
+		return errors.New(reply)
+	}
+	conn.SetDeadline(time.Time{})
+
+	n.conn = conn
+	n.writer = bufio.NewWriter(conn)
+	go n.readLoop(conn, reader)
+	return nil
+}
+
+// readLoop answers the server's keepalive PINGs until the connection closes
+func (n *NATS) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.disconnect()
+			}
+			n.mu.Unlock()
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.writer.WriteString("PONG\r\n")
+				n.writer.Flush()
+			}
+			n.mu.Unlock()
+		}
+	}
+}
+
+// disconnect closes the connection. Called with mu held.
+func (n *NATS) disconnect() {
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+		n.writer = nil
+	}
+}
+
+// Close sends the queued results and closes the connection
+func (n *NATS) Close() error {
+	err := n.batcher.Close()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.disconnect()
+	return err
+}
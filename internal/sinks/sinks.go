@@ -0,0 +1,168 @@
+// Package sinks fans request results out to every subscribed output, such as
+// the metrics collector, the console logger or a file writer
+package sinks
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+// ResultSink receives the result of every outgoing request. Record is called
+// from the request goroutines, so implementations must be safe for concurrent
+// use and should not block.
+type ResultSink interface {
+	Record(result *client.RequestResult)
+}
+
+// SinkFunc adapts a function to a ResultSink
+type SinkFunc func(result *client.RequestResult)
+
+// Record calls f(result)
+func (f SinkFunc) Record(result *client.RequestResult) {
+	f(result)
+}
+
+// namedSink is a sink registered under a name
+type namedSink struct {
+	name string
+	sink ResultSink
+}
+
+// Registry is a set of named sinks that all receive every result, in the
+// order they were added
+type Registry struct {
+	sinks  []namedSink
+	labels func() map[string]string // Run labels attached to every result
+	mu     sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add subscribes a sink under a unique name
+func (r *Registry) Add(name string, sink ResultSink) error {
+	if sink == nil {
+		return fmt.Errorf("sink %s is nil", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.sinks {
+		if s.name == name {
+			return fmt.Errorf("sink %s already registered", name)
+		}
+	}
+	r.sinks = append(r.sinks, namedSink{name: name, sink: sink})
+	return nil
+}
+
+// Remove unsubscribes a sink, closing it if it implements io.Closer. It
+// returns false if no sink has that name.
+func (r *Registry) Remove(name string) (bool, error) {
+	r.mu.Lock()
+	var removed ResultSink
+	for i, s := range r.sinks {
+		if s.name == name {
+			removed = s.sink
+			r.sinks = append(r.sinks[:i:i], r.sinks[i+1:]...)
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if removed == nil {
+		return false, nil
+	}
+	if closer, ok := removed.(io.Closer); ok {
+		return true, closer.Close()
+	}
+	return true, nil
+}
+
+// AddConfigured subscribes the sinks enabled in the result sinks config
+func (r *Registry) AddConfigured(cfg config.ResultSinksConfig) error {
+	if cfg.Kafka != nil {
+		if err := r.Add("kafka", NewKafka(*cfg.Kafka)); err != nil {
+			return err
+		}
+	}
+	if cfg.NATS != nil {
+		if err := r.Add("nats", NewNATS(*cfg.NATS)); err != nil {
+			return err
+		}
+	}
+	if cfg.File != nil {
+		file, err := NewFile(*cfg.File)
+		if err != nil {
+			return fmt.Errorf("results file: %w", err)
+		}
+		if err := r.Add("file", file); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLabelSource sets the function returning the run labels attached to
+// every result before it is passed to the sinks
+func (r *Registry) SetLabelSource(labels func() map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.labels = labels
+}
+
+// Names returns the names of the registered sinks, in order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.sinks))
+	for i, s := range r.sinks {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Record passes a result to every registered sink
+func (r *Registry) Record(result *client.RequestResult) {
+	if result == nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.labels != nil {
+		result.Labels = r.labels()
+	}
+	for _, s := range r.sinks {
+		s.sink.Record(result)
+	}
+}
+
+// Close closes every sink that implements io.Closer and empties the registry
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	sinks := r.sinks
+	r.sinks = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for _, s := range sinks {
+		if closer, ok := s.sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("sink %s: %w", s.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
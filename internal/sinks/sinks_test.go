@@ -0,0 +1,84 @@
+package sinks
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"moxapp/internal/client"
+)
+
+type closingSink struct {
+	records int
+	closed  bool
+	err     error
+}
+
+func (c *closingSink) Record(result *client.RequestResult) { c.records++ }
+
+func (c *closingSink) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func TestRegistry_FanOut(t *testing.T) {
+	r := NewRegistry()
+	var order []string
+	if err := r.Add("a", SinkFunc(func(*client.RequestResult) { order = append(order, "a") })); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add("b", SinkFunc(func(*client.RequestResult) { order = append(order, "b") })); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add("a", SinkFunc(func(*client.RequestResult) {})); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+
+	r.Record(&client.RequestResult{})
+	r.Record(nil)
+	if !reflect.DeepEqual(order, []string{"a", "b"}) {
+		t.Errorf("expected each sink once in order, got %v", order)
+	}
+	if !reflect.DeepEqual(r.Names(), []string{"a", "b"}) {
+		t.Errorf("unexpected names %v", r.Names())
+	}
+}
+
+func TestRegistry_RemoveAndClose(t *testing.T) {
+	r := NewRegistry()
+	removed := &closingSink{}
+	kept := &closingSink{err: errors.New("disk full")}
+	r.Add("removed", removed)
+	r.Add("kept", kept)
+
+	if ok, err := r.Remove("removed"); !ok || err != nil || !removed.closed {
+		t.Errorf("expected the sink to be removed and closed, got %v, %v", ok, err)
+	}
+	if ok, _ := r.Remove("missing"); ok {
+		t.Error("expected false for an unknown sink")
+	}
+
+	r.Record(&client.RequestResult{})
+	if removed.records != 0 || kept.records != 1 {
+		t.Errorf("unexpected records: removed %d, kept %d", removed.records, kept.records)
+	}
+
+	if err := r.Close(); err == nil || !kept.closed {
+		t.Errorf("expected the close error to be returned, got %v", err)
+	}
+	if len(r.Names()) != 0 {
+		t.Error("expected an empty registry after Close")
+	}
+}
+
+func TestRegistry_Labels(t *testing.T) {
+	r := NewRegistry()
+	r.SetLabelSource(func() map[string]string { return map[string]string{"env": "staging"} })
+	var got map[string]string
+	r.Add("capture", SinkFunc(func(result *client.RequestResult) { got = result.Labels }))
+
+	r.Record(&client.RequestResult{})
+	if got["env"] != "staging" {
+		t.Errorf("expected the run labels on the result, got %v", got)
+	}
+}
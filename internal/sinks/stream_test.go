@@ -0,0 +1,109 @@
+package sinks
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"moxapp/internal/client"
+	"moxapp/internal/config"
+)
+
+func TestKafka_ProducesBatches(t *testing.T) {
+	var requests []map[string][]kafkaRecord
+	var contentType, auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, auth, path = r.Header.Get("Content-Type"), r.Header.Get("Authorization"), r.URL.Path
+		var body map[string][]kafkaRecord
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+	}))
+	defer server.Close()
+
+	k := NewKafka(config.KafkaSinkConfig{
+		RestProxyURL: server.URL + "/",
+		Topic:        "results",
+		Headers:      map[string]string{"Authorization": "Bearer secret"},
+		BatchSize:    2,
+	})
+	for i := 0; i < 3; i++ {
+		k.Record(&client.RequestResult{EndpointName: fmt.Sprintf("ep%d", i), URL: "https://api.example.com/?token=abc"})
+	}
+	k.Close()
+
+	if len(requests) != 2 || len(requests[0]["records"]) != 2 || len(requests[1]["records"]) != 1 {
+		t.Fatalf("expected batches of 2 and 1, got %v", requests)
+	}
+	if path != "/topics/results" || contentType != kafkaContentType || auth != "Bearer secret" {
+		t.Errorf("unexpected request: path %q, content type %q, auth %q", path, contentType, auth)
+	}
+
+	record := requests[0]["records"][0]
+	var result client.RequestResult
+	if err := json.Unmarshal(record.Value, &result); err != nil {
+		t.Fatal(err)
+	}
+	if record.Key != "ep0" || result.EndpointName != "ep0" {
+		t.Errorf("expected the record keyed by endpoint, got %q", record.Key)
+	}
+	if strings.Contains(result.URL, "abc") {
+		t.Errorf("expected the token to be masked, got %s", result.URL)
+	}
+}
+
+func TestNATS_Publishes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"test\"}\r\n")
+
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				received <- strings.Join(lines, "")
+				return
+			}
+			lines = append(lines, line)
+			if strings.HasPrefix(line, "PING") {
+				fmt.Fprintf(conn, "PONG\r\n")
+			}
+			if strings.HasPrefix(line, "PUB ") {
+				var size int
+				fmt.Sscanf(line, "PUB results.raw %d", &size)
+				payload := make([]byte, size+2)
+				io.ReadFull(reader, payload)
+				lines = append(lines, string(payload))
+			}
+		}
+	}()
+
+	n := NewNATS(config.NATSSinkConfig{URL: "nats://s3cret@" + listener.Addr().String(), Subject: "results.raw"})
+	n.Record(&client.RequestResult{EndpointName: "ep"})
+	n.Close()
+
+	got := <-received
+	if !strings.Contains(got, `"auth_token":"s3cret"`) {
+		t.Errorf("expected the URL token in CONNECT, got %q", got)
+	}
+	if !strings.Contains(got, "PUB results.raw ") || !strings.Contains(got, `"endpoint_name":"ep"`) {
+		t.Errorf("expected the result to be published, got %q", got)
+	}
+}
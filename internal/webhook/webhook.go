@@ -0,0 +1,59 @@
+// Package webhook posts JSON notifications to webhook URLs, for alert rules
+// and run lifecycle notifications
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Timeout bounds each webhook request
+const Timeout = 10 * time.Second
+
+// NewClient creates an HTTP client for posting to webhooks
+func NewClient() *http.Client {
+	return &http.Client{Timeout: Timeout}
+}
+
+// Post sends a JSON body to a webhook, failing on non-2xx responses
+func Post(ctx context.Context, client *http.Client, webhookURL string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return withoutURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return withoutURL(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// withoutURL leaves the URL out of a request error, as it usually embeds a
+// secret token
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
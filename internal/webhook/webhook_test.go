@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPost(t *testing.T) {
+	var got map[string]string
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	if err := Post(context.Background(), NewClient(), server.URL, map[string]string{"text": "hello"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["text"] != "hello" || contentType != "application/json" {
+		t.Errorf("expected a JSON body, got %v with %q", got, contentType)
+	}
+}
+
+func TestPost_Status(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+
+	err := Post(context.Background(), NewClient(), server.URL, nil)
+	if err == nil || err.Error() != "status 410" {
+		t.Errorf("expected status 410, got %v", err)
+	}
+}
+
+func TestPost_ErrorWithoutURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	for _, webhookURL := range []string{server.URL + "/hooks/secret-token", "http://[::1/hooks/secret-token"} {
+		err := Post(context.Background(), NewClient(), webhookURL, nil)
+		if err == nil {
+			t.Fatalf("%s: expected an error", webhookURL)
+		}
+		if strings.Contains(err.Error(), "secret-token") {
+			t.Errorf("expected the URL left out, got %v", err)
+		}
+	}
+}